@@ -0,0 +1,280 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/spec"
+	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/proxy"
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type applyFlags struct {
+	File              string
+	DryRun            bool
+	PlanOutput        string                `mapstructure:"plan-output"`
+	ConnectionDetails api.ConnectionDetails `mapstructure:"api"`
+}
+
+// Paths of the server CA used to generate the proxy certificates.
+const (
+	serverCaCertPath = "/root/ssl-build/RHN-ORG-TRUSTED-SSL-CERT"
+	serverCaKeyPath  = "/root/ssl-build/RHN-ORG-PRIVATE-SSL-KEY"
+)
+
+// NewCommand reconciles the deployment with a declarative file.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: L("Apply a declarative deployment file"),
+		Long: L(`Apply a declarative deployment file
+
+The deployment file describes the desired server: backend, FQDN, image, SSL certificates,
+volumes, monitoring and proxies. The server is installed if needed, or the changes are
+applied to it. The proxies configuration archives are then generated using the API.
+
+The proxies with an ssh entry are then installed on their hosts: the configuration is copied
+there and mgrpxy install is run, one host after the other or all at the same time if parallel
+is set. The SSH connections must not need a password, using an SSH agent for instance, and
+non-root users need to be able to run sudo without password.
+
+Use mgradm export to generate the deployment file of an existing server.
+
+With --dry-run, --plan-output writes a JSON description of the systemd units, volumes, helm
+releases, secrets and proxies to be created or updated. Its schema is identified by the
+formatVersion field and the resources are sorted to ease comparing plans.
+
+Example of deployment file:
+
+  backend: podman
+  fqdn: uyuni.example.com
+  image: registry.opensuse.org/uyuni/server:latest
+  timezone: Europe/Berlin
+  ssl:
+    root: /root/certs/ca.crt
+    cert: /root/certs/uyuni.crt
+    key: /root/certs/uyuni.key
+  volumes:
+    mirror: /srv/mirror
+  monitoring: true
+  parallel: true
+  proxies:
+    - fqdn: proxy1.example.com
+      maxCache: 102400
+      email: admin@example.com
+      caPassword: secret
+      ssh:
+        user: admin
+    - fqdn: proxy2.example.com
+      email: admin@example.com
+      caPassword: secret
+      ssh:
+        host: 192.168.1.12
+        port: 2222
+        proxyJump: bastion.example.com
+  flags:
+    admin-login: admin
+    organization: Example
+`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags applyFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, apply)
+		},
+	}
+	cmd.Flags().StringP("file", "f", "", L("Path to the deployment file"))
+	_ = cmd.MarkFlagRequired("file")
+	cmd.Flags().Bool("dry-run", false, L("Only print the install command to run, with the secrets redacted"))
+	cmd.Flags().String("plan-output", "",
+		L("With --dry-run, path of the JSON file describing the resources to be created or changed, - for the standard output"))
+	if err := api.AddAPIFlags(cmd, true); err != nil {
+		log.Warn().Err(err).Send()
+	}
+	return cmd
+}
+
+func apply(globalFlags *types.GlobalFlags, flags *applyFlags, cmd *cobra.Command, args []string) error {
+	deployment, err := spec.Read(flags.File)
+	if err != nil {
+		return err
+	}
+	if installCmd, _, err := cmd.Root().Find([]string{"install", deployment.Backend}); err != nil || installCmd.Name() != deployment.Backend {
+		return fmt.Errorf(L("unsupported backend %s"), deployment.Backend)
+	}
+
+	if flags.PlanOutput != "" && !flags.DryRun {
+		return utils.UsageError(errors.New(L("--plan-output can only be used with --dry-run")))
+	}
+
+	installArgs := deployment.InstallArgs()
+	if globalFlags.LogLevel != "" {
+		installArgs = append(installArgs, "--logLevel", globalFlags.LogLevel)
+	}
+	if flags.DryRun {
+		utils.SetMachineChanged(false)
+		if flags.PlanOutput != "" {
+			plan := deployment.Plan(probeResource(deployment))
+			if flags.PlanOutput == "-" {
+				return plan.Write(flags.PlanOutput)
+			}
+			if err := plan.Write(flags.PlanOutput); err != nil {
+				return err
+			}
+		}
+		commandLine := append(deployment.InstallEnv(true), "mgradm")
+		fmt.Println(strings.Join(append(commandLine, installArgs...), " "))
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return utils.Errorf(err, L("failed to find the mgradm executable: %s"))
+	}
+	log.Info().Msgf(L("Applying the deployment of %[1]s on %[2]s"), deployment.Fqdn, deployment.Backend)
+	installCmd := exec.Command(executable, installArgs...)
+	// The secrets are passed in the environment as the arguments are visible to all the users
+	installCmd.Env = append(os.Environ(), deployment.InstallEnv(false)...)
+	installCmd.Stdin = os.Stdin
+	installCmd.Stdout = os.Stdout
+	installCmd.Stderr = os.Stderr
+	if err := installCmd.Run(); err != nil {
+		return utils.Errorf(err, L("failed to apply the server deployment: %s"))
+	}
+
+	cnx := shared.NewConnection("", podman.ServerContainerName, kubernetes.ServerFilter, "")
+	if deployment.Monitoring != nil {
+		if err := applyMonitoring(cnx, *deployment.Monitoring); err != nil {
+			return err
+		}
+	}
+
+	if len(deployment.Proxies) > 0 {
+		if err := applyProxies(cnx, deployment, &flags.ConnectionDetails); err != nil {
+			return err
+		}
+	}
+	return installProxies(deployment)
+}
+
+func applyMonitoring(cnx *shared.Connection, enabled bool) error {
+	action := "disable"
+	if enabled {
+		action = "enable"
+	}
+	log.Info().Msgf(L("Setting the server monitoring to %s"), action)
+	if err := cmd_utils.ExecCommand(zerolog.DebugLevel, cnx, "mgr-monitoring-ctl", action); err != nil {
+		return utils.Errorf(err, L("failed to %[1]s the server monitoring: %[2]s"), action)
+	}
+	return nil
+}
+
+func applyProxies(cnx *shared.Connection, deployment *spec.Deployment, connection *api.ConnectionDetails) error {
+	if connection.Server == "" {
+		connection.Server = deployment.Fqdn
+	}
+	if connection.User == "" {
+		return errors.New(L("--api-user is required to generate the proxies configuration"))
+	}
+	client, err := api.Init(connection)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	for _, proxySpec := range deployment.Proxies {
+		if !proxySpec.Ssl.IsEmpty() && proxySpec.Ssl.Root == "" {
+			proxySpec.Ssl.Root = deployment.Ssl.Root
+		}
+		request, err := proxyConfigRequest(cnx, deployment.Fqdn, &proxySpec)
+		if err != nil {
+			return err
+		}
+		log.Info().Msgf(L("Generating the configuration of proxy %s"), proxySpec.Fqdn)
+		config, err := proxy.ContainerConfig(client, request)
+		if err != nil {
+			return err
+		}
+		output := proxySpec.GetOutput()
+		if err := os.WriteFile(output, config, 0600); err != nil {
+			return utils.Errorf(err, L("failed to write the proxy configuration %[1]s: %[2]s"), output)
+		}
+		log.Info().Msgf(L("Configuration of proxy %[1]s written to %[2]s"), proxySpec.Fqdn, output)
+	}
+	return nil
+}
+
+// proxyConfigRequest computes the API parameters to generate the configuration of a proxy.
+func proxyConfigRequest(cnx *shared.Connection, server string, proxySpec *spec.Proxy) (*apiTypes.ProxyConfigRequest, error) {
+	request := apiTypes.ProxyConfigRequest{
+		ProxyName: proxySpec.Fqdn,
+		ProxyPort: proxySpec.Port,
+		Server:    server,
+		MaxCache:  proxySpec.MaxCache,
+		Email:     proxySpec.Email,
+	}
+	if request.ProxyPort == 0 {
+		request.ProxyPort = 8022
+	}
+	if request.MaxCache == 0 {
+		request.MaxCache = 102400
+	}
+
+	if !proxySpec.Ssl.IsEmpty() {
+		files := []struct {
+			path  string
+			value *string
+		}{
+			{proxySpec.Ssl.Root, &request.RootCA},
+			{proxySpec.Ssl.Cert, &request.ProxyCrt},
+			{proxySpec.Ssl.Key, &request.ProxyKey},
+		}
+		for _, file := range files {
+			if file.path == "" {
+				continue
+			}
+			content, err := os.ReadFile(file.path)
+			if err != nil {
+				return nil, utils.Errorf(err, L("failed to read %[1]s: %[2]s"), file.path)
+			}
+			*file.value = string(content)
+		}
+		for _, path := range proxySpec.Ssl.Intermediate {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, utils.Errorf(err, L("failed to read %[1]s: %[2]s"), path)
+			}
+			request.IntermediateCAs = append(request.IntermediateCAs, string(content))
+		}
+		return &request, nil
+	}
+
+	caCrt, err := cnx.Exec("cat", serverCaCertPath)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to read the server CA certificate: %s"))
+	}
+	caKey, err := cnx.Exec("cat", serverCaKeyPath)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to read the server CA key: %s"))
+	}
+	request.CaCrt = string(caCrt)
+	request.CaKey = string(caKey)
+	request.CaPassword = proxySpec.CaPassword
+	return &request, nil
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/spec"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/templates"
+	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// installProxies copies the generated configurations to the proxies hosts and installs the proxies.
+//
+// Only the proxies with an SSH connection are installed.
+func installProxies(deployment *spec.Deployment) error {
+	proxies := []spec.Proxy{}
+	for _, proxy := range deployment.Proxies {
+		if proxy.Ssh != nil {
+			proxies = append(proxies, proxy)
+		}
+	}
+	if len(proxies) == 0 {
+		return nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "mgradm-*")
+	if err != nil {
+		return utils.Errorf(err, L("failed to create temporary directory: %s"))
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if !deployment.Parallel {
+		for i, proxy := range proxies {
+			log.Info().Msgf(L("[%[1]d/%[2]d] Installing proxy %[3]s"), i+1, len(proxies), proxy.Fqdn)
+			if err := installProxy(tmpDir, &proxy); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	done := 0
+	failures := []string{}
+
+	for _, proxy := range proxies {
+		wg.Add(1)
+		go func(proxy spec.Proxy) {
+			defer wg.Done()
+			err := installProxy(tmpDir, &proxy)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			done++
+			if err != nil {
+				log.Error().Err(err).Msgf(L("[%[1]d/%[2]d] Failed to install proxy %[3]s"), done, len(proxies), proxy.Fqdn)
+				failures = append(failures, proxy.Fqdn)
+			} else {
+				log.Info().Msgf(L("[%[1]d/%[2]d] Proxy %[3]s is installed"), done, len(proxies), proxy.Fqdn)
+			}
+		}(proxy)
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return fmt.Errorf(L("failed to install proxies: %s"), strings.Join(failures, ", "))
+	}
+	return nil
+}
+
+// installProxy copies the proxy configuration to its host and runs mgrpxy install there.
+func installProxy(tmpDir string, proxy *spec.Proxy) error {
+	host := proxy.GetHost()
+	configPath := path.Join(tmpDir, proxy.Fqdn+"-ssh_config")
+	userConfigPath := ""
+	if homedir, err := os.UserHomeDir(); err == nil && utils.FileExists(filepath.Join(homedir, ".ssh", "config")) {
+		userConfigPath = filepath.Join(homedir, ".ssh", "config")
+	}
+	sshConfig := templates.SshConfigTemplateData{
+		SourceFqdn:  host,
+		User:        proxy.Ssh.GetUser(),
+		Port:        proxy.Ssh.Port,
+		ProxyJump:   proxy.Ssh.ProxyJump,
+		IncludePath: userConfigPath,
+	}
+	if err := adm_utils.GenerateSshConfig(sshConfig, configPath); err != nil {
+		return err
+	}
+
+	remoteConfig := "/tmp/" + path.Base(proxy.GetOutput())
+	log.Debug().Msgf("Copying %[1]s to %[2]s:%[3]s", proxy.GetOutput(), host, remoteConfig)
+	if _, err := utils.RunCmdOutput(zerolog.DebugLevel, "scp", "-F", configPath, "-o", "BatchMode=yes",
+		proxy.GetOutput(), host+":"+remoteConfig); err != nil {
+		return utils.Errorf(err, L("failed to copy the configuration to %[1]s: %[2]s"), host)
+	}
+
+	sshArgs := []string{"-F", configPath, "-o", "BatchMode=yes", host}
+	if proxy.Ssh.GetUser() != "root" {
+		sshArgs = append(sshArgs, "sudo", "-n")
+	}
+	installArgs := append(sshArgs, "mgrpxy", "install", proxy.Ssh.GetBackend(), remoteConfig)
+	log.Debug().Msgf("Installing proxy %[1]s on %[2]s", proxy.Fqdn, host)
+	_, err := utils.RunCmdOutput(zerolog.DebugLevel, "ssh", installArgs...)
+
+	// The configuration contains the proxy key: do not leave it behind
+	if _, rmErr := utils.RunCmdOutput(zerolog.DebugLevel, "ssh", append(sshArgs, "rm", "-f", remoteConfig)...); rmErr != nil {
+		log.Warn().Err(rmErr).Msgf(L("Failed to remove %[1]s from %[2]s"), remoteConfig, host)
+	}
+	if err != nil {
+		return utils.Errorf(err, L("failed to install proxy on %[1]s: %[2]s"), host)
+	}
+	return nil
+}
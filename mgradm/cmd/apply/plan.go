@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/spec"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// probeResource checks whether a planned resource already exists on this machine.
+//
+// The proxies hosts are not contacted: their installation is always planned.
+func probeResource(deployment *spec.Deployment) spec.ResourceProbe {
+	outputs := map[string]string{}
+	for _, proxy := range deployment.Proxies {
+		outputs[proxy.Fqdn] = proxy.GetOutput()
+	}
+	return func(resourceType string, namespace string, name string) bool {
+		switch resourceType {
+		case spec.SystemdUnitResource:
+			return podman.HasService(strings.TrimSuffix(name, ".service"))
+		case spec.PodmanNetworkResource:
+			return podman.IsNetworkPresent(name)
+		case spec.PodmanVolumeResource:
+			return utils.RunCmd("podman", "volume", "exists", name) == nil
+		case spec.HelmReleaseResource:
+			return kubernetes.HasHelmRelease(name, deployment.Flags["kubeconfig"])
+		case spec.KubernetesSecretResource:
+			return kubectlHas(namespace, "secret", name)
+		case spec.KubernetesPvcResource:
+			return kubectlHas(namespace, "pvc", name)
+		case spec.ProxyConfigResource:
+			_, err := os.Stat(outputs[name])
+			return err == nil
+		}
+		return false
+	}
+}
+
+func kubectlHas(namespace string, kind string, name string) bool {
+	_, err := utils.RunCmdOutput(zerolog.TraceLevel, "kubectl", kubernetes.KubectlArgs("-n", namespace, "get", kind, name)...)
+	return err == nil
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package approve
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type approveFlags struct {
+	Validity time.Duration
+}
+
+// NewCommand generates an approval token for a destructive operation.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "approve operation",
+		Short: L("Approve a destructive operation run by another user"),
+		Long: fmt.Sprintf(L(`Approve a destructive operation run by another user
+
+When the %[2]s policy file requires it, the destructive operations
+need an approval token generated by another user with this command. The token can only be used once.
+
+The policy file has to be owned by root and only writable by its owner.
+The policy cannot be set in the user configuration files.
+
+Policy example:
+  required: true
+  operations: [%[1]s]
+  validity: 1h
+
+Possible operations: %[1]s
+Only the uninstall with volumes removal is covered: there is no database restore command.`),
+			strings.Join(utils.ApprovalOperations, ", "), utils.ApprovalPolicyPath),
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: utils.ApprovalOperations,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags approveFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, utils.WithAudit(approve))
+		},
+	}
+	cmd.Flags().Duration("validity", 0, L("Duration during which the token can be used, defaults to the configured one"))
+	return cmd
+}
+
+func approve(globalFlags *types.GlobalFlags, flags *approveFlags, cmd *cobra.Command, args []string) error {
+	validity := flags.Validity
+	if validity <= 0 {
+		validity = utils.ApprovalValidity()
+	}
+	token, err := utils.Approve(args[0], validity)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Pass the following token to the %[1]s operation within %[2]s:"), args[0], validity)
+	fmt.Println(token)
+	utils.AddMachineData("token", token)
+	return nil
+}
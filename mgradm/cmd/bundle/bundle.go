@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle
+
+import (
+	"github.com/spf13/cobra"
+	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type createFlags struct {
+	Output string
+	Image  types.ImageFlags `mapstructure:",squash"`
+	Proxy  struct {
+		Images bool
+		Tag    string
+	}
+	Extra struct {
+		Images []string
+	}
+	Helm struct {
+		Charts  []string `mapstructure:"chart"`
+		Version string
+	}
+	Rpms []string `mapstructure:"rpm"`
+}
+
+type installFlags struct {
+	Charts struct {
+		Dir string
+	}
+}
+
+// NewCommand manages the offline installation bundles.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	bundleCmd := &cobra.Command{
+		Use:   "bundle",
+		Short: L("Manage offline installation bundles"),
+		Long: L(`Manage offline installation bundles
+
+A bundle is a tar archive containing the container images, helm charts and RPM packages
+needed to install or upgrade a server on a host without access to the registries and repositories.
+It is created on a connected host and installed on the air-gapped one before running the
+install or upgrade command.`),
+	}
+	bundleCmd.SetUsageTemplate(bundleCmd.UsageTemplate())
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: L("Create an offline installation bundle"),
+		Long: L(`Create an offline installation bundle
+
+The server image is always added to the bundle. The proxy images, additional images, helm charts
+and RPM packages with their missing dependencies are added on request.`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags createFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, create)
+		},
+	}
+	createCmd.Flags().StringP("output", "o", "uyuni-bundle.tar", L("Path to the bundle file to write"))
+	cmd_utils.AddImageFlag(createCmd)
+	createCmd.Flags().Bool("proxy-images", false, L("Add the proxy images from the same registry as the server image"))
+	createCmd.Flags().String("proxy-tag", "", L("Tag of the proxy images, defaults to the server image tag"))
+	createCmd.Flags().StringSlice("extra-images", []string{}, L("Additional images to add, like the coco or saline ones"))
+	createCmd.Flags().StringSlice("helm-chart", []string{},
+		L("Helm chart to add, like oci://registry.opensuse.org/uyuni/server-helm. Can be repeated"))
+	createCmd.Flags().String("helm-version", "", L("Version of the helm charts to add, defaults to the latest one"))
+	createCmd.Flags().StringSlice("rpm", []string{}, L("RPM package to add with its dependencies. Can be repeated"))
+
+	installCmd := &cobra.Command{
+		Use:   "install bundle-file",
+		Short: L("Install an offline installation bundle"),
+		Long: L(`Install an offline installation bundle
+
+The checksums of the bundle files are verified, then the RPM packages are installed,
+the images are loaded in podman and the helm charts are copied to a local folder.
+Those charts can then be passed to the --helm-uyuni-chart flag of the kubernetes commands.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags installFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, utils.WithLock(install))
+		},
+	}
+	installCmd.Flags().String("charts-dir", "/var/lib/uyuni-tools/charts", L("Folder where to copy the helm charts"))
+	utils.AddLockFlag(installCmd)
+
+	bundleCmd.AddCommand(createCmd)
+	bundleCmd.AddCommand(installCmd)
+	return bundleCmd
+}
@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+var proxyImages = []string{"httpd", "salt-broker", "squid", "ssh", "tftpd"}
+
+func create(globalFlags *types.GlobalFlags, flags *createFlags, cmd *cobra.Command, args []string) error {
+	if _, err := exec.LookPath("podman"); err != nil {
+		return errors.New(L("install podman before running this command"))
+	}
+	if len(flags.Helm.Charts) > 0 {
+		if _, err := exec.LookPath("helm"); err != nil {
+			return errors.New(L("install helm to add charts to the bundle"))
+		}
+	}
+
+	images, err := flags.getImages()
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "mgradm-bundle-*")
+	if err != nil {
+		return utils.Errorf(err, L("failed to create temporary directory: %s"))
+	}
+	defer os.RemoveAll(dir)
+
+	m := manifest{
+		Version:     manifestVersion,
+		CreatedAt:   time.Now().Format(time.RFC3339),
+		ToolVersion: utils.Version,
+		Images:      images,
+	}
+
+	if err := saveImages(images, flags.Image.PullPolicy, filepath.Join(dir, imagesFile)); err != nil {
+		return err
+	}
+	if m.Charts, err = pullCharts(flags.Helm.Charts, flags.Helm.Version, filepath.Join(dir, chartsDir)); err != nil {
+		return err
+	}
+	if m.Rpms, err = downloadRpms(flags.Rpms, filepath.Join(dir, rpmsDir)); err != nil {
+		return err
+	}
+
+	if err := m.computeChecksums(dir); err != nil {
+		return utils.Errorf(err, L("failed to compute the checksums: %s"))
+	}
+	if err := m.write(dir); err != nil {
+		return err
+	}
+
+	log.Info().Msgf(L("Writing bundle %s"), flags.Output)
+	if err := writeTar(dir, flags.Output); err != nil {
+		return utils.Errorf(err, L("failed to write %[1]s: %[2]s"), flags.Output)
+	}
+	return nil
+}
+
+// getImages computes the URLs of all the images to add to the bundle.
+func (flags *createFlags) getImages() ([]string, error) {
+	serverImage, err := utils.ComputeImage(flags.Image.Name, flags.Image.Tag)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to compute image URL: %s"))
+	}
+	images := []string{serverImage}
+
+	if flags.Proxy.Images {
+		tag := flags.Proxy.Tag
+		if tag == "" {
+			tag = flags.Image.Tag
+		}
+		for _, name := range proxyImages {
+			image, err := utils.ComputeImage(path.Join(path.Dir(flags.Image.Name), "proxy-"+name), tag)
+			if err != nil {
+				return nil, utils.Errorf(err, L("failed to compute image URL: %s"))
+			}
+			images = append(images, image)
+		}
+	}
+
+	for _, extra := range flags.Extra.Images {
+		image, err := utils.ComputeImage(extra, flags.Image.Tag)
+		if err != nil {
+			return nil, utils.Errorf(err, L("failed to compute image URL: %s"))
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+func saveImages(images []string, pullPolicy string, output string) error {
+	inspectedHostValues, err := utils.InspectHost()
+	if err != nil {
+		return utils.Errorf(err, L("cannot inspect host values: %s"))
+	}
+	log.Info().Msgf(L("Pulling %d images"), len(images))
+	if err := podman.PullImages(images, pullPolicy, podman.GetPullArgs(inspectedHostValues)...); err != nil {
+		return err
+	}
+
+	log.Info().Msg(L("Saving the images"))
+	args := append([]string{"save", "--multi-image-archive", "--output", output}, images...)
+	if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "podman", args...); err != nil {
+		return utils.Errorf(err, L("failed to save the images: %s"))
+	}
+	return nil
+}
+
+func pullCharts(charts []string, version string, dir string) ([]string, error) {
+	if len(charts) == 0 {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	for _, chart := range charts {
+		log.Info().Msgf(L("Pulling helm chart %s"), chart)
+		args := []string{"pull", chart, "--destination", dir}
+		if version != "" {
+			args = append(args, "--version", version)
+		}
+		if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "helm", args...); err != nil {
+			return nil, utils.Errorf(err, L("failed to pull helm chart %[1]s: %[2]s"), chart)
+		}
+	}
+	return listFiles(dir, "*.tgz")
+}
+
+func downloadRpms(packages []string, dir string) ([]string, error) {
+	if len(packages) == 0 {
+		return nil, nil
+	}
+	if _, err := exec.LookPath("zypper"); err != nil {
+		return nil, errors.New(L("zypper is required to add RPM packages to the bundle"))
+	}
+
+	cacheDir := dir + ".cache"
+	defer os.RemoveAll(cacheDir)
+
+	// The packages already installed on this host are not downloaded: the bundle is expected
+	// to be installed on a host with the same distribution.
+	log.Info().Msg(L("Downloading the RPM packages"))
+	args := append([]string{"--non-interactive", "--pkg-cache-dir", cacheDir, "install", "--download-only"}, packages...)
+	if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "zypper", args...); err != nil {
+		return nil, utils.Errorf(err, L("failed to download the RPM packages: %s"))
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	err := filepath.Walk(cacheDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".rpm" {
+			return err
+		}
+		return os.Rename(path, filepath.Join(dir, filepath.Base(path)))
+	})
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to collect the downloaded RPM packages: %s"))
+	}
+	return listFiles(dir, "*.rpm")
+}
+
+// listFiles returns the base names of the files matching a pattern in a folder.
+func listFiles(dir string, pattern string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, path := range paths {
+		names = append(names, filepath.Base(path))
+	}
+	return names, nil
+}
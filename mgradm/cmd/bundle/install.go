@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func install(globalFlags *types.GlobalFlags, flags *installFlags, cmd *cobra.Command, args []string) error {
+	if _, err := exec.LookPath("podman"); err != nil {
+		return errors.New(L("install podman before running this command"))
+	}
+
+	dir, err := os.MkdirTemp("", "mgradm-bundle-*")
+	if err != nil {
+		return utils.Errorf(err, L("failed to create temporary directory: %s"))
+	}
+	defer os.RemoveAll(dir)
+
+	log.Info().Msgf(L("Extracting bundle %s"), args[0])
+	if err := extractTar(args[0], dir); err != nil {
+		return utils.Errorf(err, L("failed to extract %[1]s: %[2]s"), args[0])
+	}
+	m, err := readManifest(dir)
+	if err != nil {
+		return err
+	}
+	if err := m.verify(dir); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Installing bundle created on %[1]s with uyuni-tools %[2]s"), m.CreatedAt, m.ToolVersion)
+
+	if len(m.Rpms) > 0 {
+		log.Info().Msg(L("Installing the RPM packages"))
+		rpmArgs := []string{"--non-interactive", "install"}
+		for _, rpm := range m.Rpms {
+			rpmArgs = append(rpmArgs, filepath.Join(dir, rpmsDir, rpm))
+		}
+		if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "zypper", rpmArgs...); err != nil {
+			return utils.Errorf(err, L("failed to install the RPM packages: %s"))
+		}
+	}
+
+	log.Info().Msgf(L("Loading %d images"), len(m.Images))
+	if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "podman", "load", "--input", filepath.Join(dir, imagesFile)); err != nil {
+		return utils.Errorf(err, L("failed to load the images: %s"))
+	}
+
+	if len(m.Charts) > 0 {
+		if err := os.MkdirAll(flags.Charts.Dir, 0755); err != nil {
+			return utils.Errorf(err, L("failed to create %[1]s folder: %[2]s"), flags.Charts.Dir)
+		}
+		for _, chart := range m.Charts {
+			target := filepath.Join(flags.Charts.Dir, chart)
+			if err := os.Rename(filepath.Join(dir, chartsDir, chart), target); err != nil {
+				return utils.Errorf(err, L("failed to copy the %[1]s helm chart: %[2]s"), chart)
+			}
+			log.Info().Msgf(L("Helm chart available as %s"), target)
+		}
+	}
+	return nil
+}
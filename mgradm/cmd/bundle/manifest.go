@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"gopkg.in/yaml.v2"
+)
+
+const manifestFile = "manifest.yaml"
+const imagesFile = "images.tar"
+const chartsDir = "charts"
+const rpmsDir = "rpms"
+
+// manifestVersion is increased when the bundle layout changes in an incompatible way.
+const manifestVersion = 1
+
+// manifest describes the content of a bundle.
+type manifest struct {
+	Version     int      `yaml:"version"`
+	CreatedAt   string   `yaml:"createdAt"`
+	ToolVersion string   `yaml:"toolVersion"`
+	Images      []string `yaml:"images"`
+	Charts      []string `yaml:"charts,omitempty"`
+	Rpms        []string `yaml:"rpms,omitempty"`
+	// Checksums are the SHA-256 of the bundle files, indexed by their path in the bundle.
+	Checksums map[string]string `yaml:"checksums"`
+}
+
+// computeChecksums sets the checksums of all the files of the bundle folder in the manifest.
+func (m *manifest) computeChecksums(dir string) error {
+	m.Checksums = map[string]string{}
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Base(path) == manifestFile {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		checksum, err := fileChecksum(path)
+		if err != nil {
+			return err
+		}
+		m.Checksums[filepath.ToSlash(relPath)] = checksum
+		return nil
+	})
+}
+
+// verify checks the version of the manifest and the checksums of the extracted bundle files.
+func (m *manifest) verify(dir string) error {
+	if m.Version != manifestVersion {
+		return fmt.Errorf(L("unsupported bundle version %[1]d, version %[2]d is expected"), m.Version, manifestVersion)
+	}
+	if len(m.Checksums) == 0 {
+		return errors.New(L("the bundle manifest has no checksum"))
+	}
+
+	names := make([]string, 0, len(m.Checksums))
+	for name := range m.Checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		checksum, err := fileChecksum(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return utils.Errorf(err, L("cannot verify %[1]s: %[2]s"), name)
+		}
+		if checksum != m.Checksums[name] {
+			return fmt.Errorf(L("invalid checksum for %s: the bundle is corrupted"), name)
+		}
+	}
+	return nil
+}
+
+func readManifest(dir string) (*manifest, error) {
+	path := filepath.Join(dir, manifestFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to read the bundle manifest: %s"))
+	}
+	var m manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, utils.Errorf(err, L("failed to parse the bundle manifest: %s"))
+	}
+	return &m, nil
+}
+
+func (m *manifest) write(dir string) error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return utils.Errorf(err, L("failed to write the bundle manifest: %s"))
+	}
+	return os.WriteFile(filepath.Join(dir, manifestFile), data, 0644)
+}
+
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writeTar writes the files of a folder in a tar archive, the manifest first.
+func writeTar(dir string, output string) error {
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := tar.NewWriter(file)
+
+	files := []string{filepath.Join(dir, manifestFile)}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && filepath.Base(path) != manifestFile {
+			files = append(files, path)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		if err := addTarFile(writer, dir, path); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+func addTarFile(writer *tar.Writer, dir string, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	relPath, err := filepath.Rel(dir, path)
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(relPath)
+	if err := writer.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, file)
+	return err
+}
+
+// extractTar extracts the regular files of a tar archive in a folder.
+func extractTar(archive string, dir string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := tar.NewReader(file)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf(L("invalid path in the bundle: %s"), header.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+		if err := extractTarFile(reader, path); err != nil {
+			return err
+		}
+	}
+}
+
+func extractTarFile(reader io.Reader, path string) error {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, reader)
+	return err
+}
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, file string, content string) {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		t.Fatalf("failed to create folder: %s", err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %s", file, err)
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	srcDir := t.TempDir()
+	writeTestFile(t, filepath.Join(srcDir, imagesFile), "images")
+	writeTestFile(t, filepath.Join(srcDir, chartsDir, "server-helm-1.0.tgz"), "chart")
+
+	m := manifest{Version: manifestVersion, Images: []string{"server:latest"}, Charts: []string{"server-helm-1.0.tgz"}}
+	if err := m.computeChecksums(srcDir); err != nil {
+		t.Fatalf("failed to compute checksums: %s", err)
+	}
+	if len(m.Checksums) != 2 {
+		t.Errorf("expected 2 checksums, got %v", m.Checksums)
+	}
+	if err := m.write(srcDir); err != nil {
+		t.Fatalf("failed to write manifest: %s", err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "bundle.tar")
+	if err := writeTar(srcDir, archive); err != nil {
+		t.Fatalf("failed to write the bundle: %s", err)
+	}
+
+	dstDir := t.TempDir()
+	if err := extractTar(archive, dstDir); err != nil {
+		t.Fatalf("failed to extract the bundle: %s", err)
+	}
+	actual, err := readManifest(dstDir)
+	if err != nil {
+		t.Fatalf("failed to read the manifest: %s", err)
+	}
+	if !reflect.DeepEqual(*actual, m) {
+		t.Errorf("expected %v, got %v", m, *actual)
+	}
+	if err := actual.verify(dstDir); err != nil {
+		t.Errorf("unexpected verification error: %s", err)
+	}
+
+	writeTestFile(t, filepath.Join(dstDir, imagesFile), "corrupted")
+	if err := actual.verify(dstDir); err == nil {
+		t.Error("expected a checksum error")
+	}
+
+	actual.Version = 2
+	if err := actual.verify(dstDir); err == nil {
+		t.Error("expected a version error")
+	}
+}
+
+func TestExtractTarTraversal(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "bundle.tar")
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("failed to create archive: %s", err)
+	}
+	writer := tar.NewWriter(file)
+	content := []byte("evil")
+	header := tar.Header{Name: "../evil", Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}
+	if err := writer.WriteHeader(&header); err != nil {
+		t.Fatalf("failed to write header: %s", err)
+	}
+	if _, err := writer.Write(content); err != nil {
+		t.Fatalf("failed to write content: %s", err)
+	}
+	writer.Close()
+	file.Close()
+
+	if err := extractTar(archive, t.TempDir()); err == nil {
+		t.Error("expected an invalid path error")
+	}
+}
@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import (
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// NewCommand to run diagnostics on the server environment.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: L("Diagnose the server environment"),
+		Long:  L("Diagnose the server environment"),
+	}
+	checkCmd.AddCommand(newNetworkCommand(globalFlags))
+	checkCmd.AddCommand(newSecurityCommand(globalFlags))
+	checkCmd.AddCommand(newDriftCommand(globalFlags))
+	return checkCmd
+}
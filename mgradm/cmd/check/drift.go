@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	install_podman "github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/podman"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type driftFlags struct {
+	Fix bool
+}
+
+// artifact is a file generated by the tool with its expected content.
+type artifact struct {
+	path     string
+	expected []byte
+	perm     os.FileMode
+}
+
+func newDriftCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "drift",
+		Short: L("Check the generated files for manual changes"),
+		Long: L(`Check the generated files for manual changes
+
+The server systemd service files are compared with what this version of the tool generates
+from the deployment state recorded at installation. The files modified manually or missing are reported.
+Use --fix to regenerate them: the changes to keep need to be added as overrides in ` + utils.TemplateOverridesDir + `.
+
+Only the podman deployments are supported.`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags driftFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, checkDrift)
+		},
+	}
+	cmd.Flags().Bool("fix", false, L("Regenerate the modified and missing files"))
+	return cmd
+}
+
+// serverArtifacts returns the files generated for the server with their expected content.
+func serverArtifacts(state *types.DeploymentState) ([]artifact, error) {
+	service, err := install_podman.RenderServerServiceFromState(state)
+	if err != nil {
+		return nil, err
+	}
+	return []artifact{
+		{podman.GetServicePath(podman.ServerService), service, 0555},
+		{
+			podman.GetConfFilePath(podman.ServerService, "Service"),
+			podman.ConfFileContent("Service", "Environment=UYUNI_IMAGE="+state.Image),
+			0644,
+		},
+	}, nil
+}
+
+// compareArtifact checks whether the file on disk has the expected content.
+func compareArtifact(file artifact) utils.CheckResult {
+	check := utils.CheckResult{Name: file.path}
+	content, err := os.ReadFile(file.path)
+	if errors.Is(err, os.ErrNotExist) {
+		check.Detail = L("missing")
+	} else if err != nil {
+		check.Detail = err.Error()
+	} else if !bytes.Equal(content, file.expected) {
+		check.Detail = L("differs from the generated content")
+	} else {
+		check.OK = true
+		check.Detail = L("up to date")
+	}
+	return check
+}
+
+func checkDrift(globalFlags *types.GlobalFlags, flags *driftFlags, cmd *cobra.Command, args []string) error {
+	state, err := podman.ReadState()
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return errors.New(L("no deployment state found: only the podman deployments installed by this tool can be checked"))
+	}
+
+	artifacts, err := serverArtifacts(state)
+	if err != nil {
+		return err
+	}
+
+	checks := []utils.CheckResult{}
+	for _, file := range artifacts {
+		checks = append(checks, compareArtifact(file))
+	}
+	if !flags.Fix {
+		return utils.ReportChecks(os.Stdout, checks, L("some generated files have drifted"))
+	}
+
+	utils.PrintChecks(os.Stdout, checks)
+	fixed := false
+	for i, check := range checks {
+		if check.OK {
+			continue
+		}
+		file := artifacts[i]
+		log.Info().Msgf(L("Regenerating %s"), file.path)
+		if err := os.WriteFile(file.path, file.expected, file.perm); err != nil {
+			return utils.Errorf(err, L("cannot write %[1]s file: %[2]s"), file.path)
+		}
+		fixed = true
+	}
+	if fixed {
+		if err := podman.ReloadDaemon(false); err != nil {
+			return err
+		}
+		log.Info().Msg(L("Restart the server to apply the regenerated files"))
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestCompareArtifact(t *testing.T) {
+	dir := t.TempDir()
+	filePath := path.Join(dir, "uyuni-server.service")
+
+	if check := compareArtifact(artifact{filePath, []byte("expected"), 0644}); check.OK || check.Detail != "missing" {
+		t.Errorf("expected a missing file, got %v", check)
+	}
+
+	if err := os.WriteFile(filePath, []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if check := compareArtifact(artifact{filePath, []byte("expected"), 0644}); check.OK {
+		t.Errorf("expected a modified file, got %v", check)
+	}
+
+	if err := os.WriteFile(filePath, []byte("expected"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if check := compareArtifact(artifact{filePath, []byte("expected"), 0644}); !check.OK {
+		t.Errorf("expected an up to date file, got %v", check)
+	}
+}
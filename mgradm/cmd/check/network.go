@@ -0,0 +1,236 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type networkFlags struct {
+	Backend   string
+	Namespace string
+	Fqdn      string
+	Timeout   time.Duration
+}
+
+const sysconfigProxyPath = "/etc/sysconfig/proxy"
+
+// clientPorts are the server ports the clients connect to.
+var clientPorts = []int{80, 443, 4505, 4506}
+
+// Network functions, replaced in the tests.
+var (
+	lookupHost = net.LookupHost
+	lookupAddr = net.LookupAddr
+)
+
+func newNetworkCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: L("Check the network connectivity of the server"),
+		Long: L(`Check the network connectivity of the server
+
+The following checks are performed:
+  - resolution of the server FQDN from the host and the container,
+  - reverse resolution of the server addresses,
+  - reachability of SUSE Customer Center and the container registry, using the configured proxy,
+  - ports the clients connect to, as seen from the host,
+  - time synchronization with NTP.
+
+The FQDN is read from the server configuration if not provided.`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags networkFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, checkNetwork)
+		},
+	}
+	cmd.Flags().String("fqdn", "", L("Fully qualified domain name of the server"))
+	cmd.Flags().Duration("timeout", 10*time.Second, L("Timeout of each connection attempt"))
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(cmd)
+		utils.AddNamespaceFlag(cmd)
+	}
+	return cmd
+}
+
+func checkNetwork(globalFlags *types.GlobalFlags, flags *networkFlags, cmd *cobra.Command, args []string) error {
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
+	fqdn := flags.Fqdn
+	if fqdn == "" {
+		fqdn = guessFqdn(cnx)
+	}
+	if fqdn == "" {
+		return utils.UsageError(fmt.Errorf(L("cannot find the server FQDN, use the --fqdn parameter")))
+	}
+
+	proxy := proxyFunc(readSysconfigProxy())
+	hostCheck, addresses := hostDnsCheck(fqdn)
+	checks := []utils.CheckResult{
+		hostCheck,
+		containerDnsCheck(cnx, fqdn),
+		reverseDnsCheck(fqdn, addresses),
+		endpointCheck(L("SUSE Customer Center"), "https://scc.suse.com", proxy, flags.Timeout),
+		endpointCheck(L("Container registry"), "https://"+strings.Split(utils.DefaultNamespace, "/")[0],
+			proxy, flags.Timeout),
+		utils.PortsCheck(L("Client ports"), fqdn, clientPorts, flags.Timeout),
+		ntpCheck(),
+	}
+	return utils.ReportChecks(os.Stdout, checks, L("some of the network checks failed"))
+}
+
+// guessFqdn reads the FQDN from the running server or returns the one of the host.
+func guessFqdn(cnx *shared.Connection) string {
+	out, err := cnx.Exec("sh", "-c", `sed -n 's/^\s*java\.hostname\s*=\s*\([^ ]*\)\s*$/\1/p' /etc/rhn/rhn.conf`)
+	if fqdn := strings.TrimSpace(string(out)); err == nil && fqdn != "" {
+		return fqdn
+	}
+	log.Debug().Err(err).Msg("Failed to read the FQDN from the server configuration, using the host one")
+	out, err = utils.RunCmdOutput(zerolog.DebugLevel, "hostname", "-f")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func hostDnsCheck(fqdn string) (utils.CheckResult, []string) {
+	check := utils.CheckResult{Name: L("Host DNS")}
+	addresses, err := lookupHost(fqdn)
+	if err != nil || len(addresses) == 0 {
+		check.Detail = fmt.Sprintf(L("%[1]s cannot be resolved: %[2]v"), fqdn, err)
+		return check, nil
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("%[1]s resolves to %[2]s"), fqdn, strings.Join(addresses, ", "))
+	return check, addresses
+}
+
+func containerDnsCheck(cnx *shared.Connection, fqdn string) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Container DNS")}
+	out, err := cnx.Exec("getent", "hosts", fqdn)
+	fields := strings.Fields(string(out))
+	if err != nil || len(fields) == 0 {
+		check.Detail = fmt.Sprintf(L("%[1]s cannot be resolved in the container: %[2]v"), fqdn, err)
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("%[1]s resolves to %[2]s"), fqdn, fields[0])
+	return check
+}
+
+func reverseDnsCheck(fqdn string, addresses []string) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Reverse DNS")}
+	if len(addresses) == 0 {
+		check.Detail = L("no address to resolve")
+		return check
+	}
+	wrong := []string{}
+	for _, address := range addresses {
+		names, err := lookupAddr(address)
+		if err != nil || !utils.Contains(names, fqdn) && !utils.Contains(names, fqdn+".") {
+			wrong = append(wrong, address)
+		}
+	}
+	if len(wrong) > 0 {
+		check.Detail = fmt.Sprintf(L("%[1]s do not resolve to %[2]s"), strings.Join(wrong, ", "), fqdn)
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("all addresses resolve to %s"), fqdn)
+	return check
+}
+
+// endpointCheck verifies that an HTTP endpoint answers, whatever the status.
+func endpointCheck(name string, endpoint string, proxy func(*http.Request) (*url.URL, error),
+	timeout time.Duration) utils.CheckResult {
+	check := utils.CheckResult{Name: name}
+	client := http.Client{
+		Transport: &http.Transport{Proxy: proxy},
+		Timeout:   timeout,
+	}
+	res, err := client.Head(endpoint)
+	if err != nil {
+		check.Detail = fmt.Sprintf(L("%[1]s is not reachable: %[2]s"), endpoint, err)
+		return check
+	}
+	res.Body.Close()
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("%[1]s answered with status %[2]d"), endpoint, res.StatusCode)
+	return check
+}
+
+func ntpCheck() utils.CheckResult {
+	check := utils.CheckResult{Name: L("NTP")}
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "timedatectl", "show", "-p", "NTPSynchronized", "--value")
+	if err != nil {
+		check.Detail = fmt.Sprintf(L("failed to get the time synchronization status: %s"), err)
+		return check
+	}
+	if strings.TrimSpace(string(out)) != "yes" {
+		check.Detail = L("the system clock is not synchronized")
+		return check
+	}
+	check.OK = true
+	check.Detail = L("the system clock is synchronized")
+	return check
+}
+
+// readSysconfigProxy reads the system proxy configuration, if any.
+func readSysconfigProxy() map[string]string {
+	file, err := os.Open(sysconfigProxyPath)
+	if err != nil {
+		return map[string]string{}
+	}
+	defer file.Close()
+	return parseSysconfig(bufio.NewScanner(file))
+}
+
+// parseSysconfig parses the KEY="value" lines of a sysconfig file.
+func parseSysconfig(scanner *bufio.Scanner) map[string]string {
+	values := map[string]string{}
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, found := strings.Cut(line, "="); found {
+			values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return values
+}
+
+// proxyFunc returns the proxy to use: the environment variables have precedence over the system configuration.
+func proxyFunc(sysconfig map[string]string) func(*http.Request) (*url.URL, error) {
+	if os.Getenv("HTTPS_PROXY") != "" || os.Getenv("https_proxy") != "" {
+		return http.ProxyFromEnvironment
+	}
+	if strings.ToLower(sysconfig["PROXY_ENABLED"]) != "yes" || sysconfig["HTTPS_PROXY"] == "" {
+		return nil
+	}
+	proxyURL, err := url.Parse(sysconfig["HTTPS_PROXY"])
+	if err != nil {
+		log.Warn().Err(err).Msgf(L("Ignoring invalid proxy in %s"), sysconfigProxyPath)
+		return nil
+	}
+	return http.ProxyURL(proxyURL)
+}
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReverseDnsCheck(t *testing.T) {
+	defer func(orig func(string) ([]string, error)) { lookupAddr = orig }(lookupAddr)
+	lookupAddr = func(addr string) ([]string, error) {
+		switch addr {
+		case "192.168.1.2":
+			return []string{"uyuni.example.com."}, nil
+		case "192.168.1.3":
+			return []string{"other.example.com."}, nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	if check := reverseDnsCheck("uyuni.example.com", []string{"192.168.1.2"}); !check.OK {
+		t.Errorf("expected a successful check: %s", check.Detail)
+	}
+	check := reverseDnsCheck("uyuni.example.com", []string{"192.168.1.2", "192.168.1.3", "192.168.1.4"})
+	if check.OK || !strings.Contains(check.Detail, "192.168.1.3, 192.168.1.4") {
+		t.Errorf("expected the wrong addresses to be reported: %s", check.Detail)
+	}
+	if check := reverseDnsCheck("uyuni.example.com", nil); check.OK {
+		t.Error("expected a failure without addresses")
+	}
+}
+
+func TestEndpointCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	if check := endpointCheck("test", server.URL, nil, time.Second); !check.OK {
+		t.Errorf("any HTTP answer should be fine: %s", check.Detail)
+	}
+	server.Close()
+	if check := endpointCheck("test", server.URL, nil, time.Second); check.OK {
+		t.Error("expected a failure for a stopped server")
+	}
+}
+
+func TestParseSysconfig(t *testing.T) {
+	content := `## Path: Network/Proxy
+PROXY_ENABLED="yes"
+HTTPS_PROXY="http://proxy.example.com:3128"
+NO_PROXY='localhost, 127.0.0.1'
+`
+	values := parseSysconfig(bufio.NewScanner(strings.NewReader(content)))
+	if values["PROXY_ENABLED"] != "yes" || values["HTTPS_PROXY"] != "http://proxy.example.com:3128" ||
+		values["NO_PROXY"] != "localhost, 127.0.0.1" {
+		t.Errorf("unexpected values: %v", values)
+	}
+}
+
+func TestProxyFunc(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("https_proxy", "")
+
+	if proxyFunc(map[string]string{"PROXY_ENABLED": "no", "HTTPS_PROXY": "http://proxy:3128"}) != nil {
+		t.Error("the disabled proxy should not be used")
+	}
+	proxy := proxyFunc(map[string]string{"PROXY_ENABLED": "yes", "HTTPS_PROXY": "http://proxy:3128"})
+	if proxy == nil {
+		t.Fatal("expected the system proxy to be used")
+	}
+	req, _ := http.NewRequest("HEAD", "https://scc.suse.com", nil)
+	if proxyURL, err := proxy(req); err != nil || proxyURL.String() != "http://proxy:3128" {
+		t.Errorf("unexpected proxy: %v, %v", proxyURL, err)
+	}
+}
@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type securityFlags struct {
+	MaxExposure float64
+}
+
+func newSecurityCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "security",
+		Short: L("Check the exposure level of the server systemd services"),
+		Long: L(`Check the exposure level of the server systemd services
+
+systemd-analyze security is run on the installed server services and their exposure scores are reported.
+Use the --podman-hardening flag of the install or upgrade commands to lower them.`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags securityFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, checkSecurity)
+		},
+	}
+	cmd.Flags().Float64("max-exposure", 7.0, L("Highest acceptable exposure score, from 0 to 10"))
+	return cmd
+}
+
+func checkSecurity(globalFlags *types.GlobalFlags, flags *securityFlags, cmd *cobra.Command, args []string) error {
+	checks := podman.SecurityChecks(podman.ServerHardenedServices, flags.MaxExposure)
+	return utils.ReportChecks(os.Stdout, checks, L("some services are exposed more than accepted"))
+}
@@ -11,28 +11,55 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/shared/completion"
+	"github.com/uyuni-project/uyuni-tools/shared/docs"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/apply"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/approve"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/bundle"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/check"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/component"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/config"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/credentials"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/db"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/debug"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/distro"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/export"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/generate"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/gpg"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/history"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/hub"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/images"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/inspect"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/install"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/logs"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/migrate"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/profile"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/proxy"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/rename"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/restart"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/saline"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/serve"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/shell"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/start"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/status"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/stop"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/support"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/tftp"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/uninstall"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/upgrade"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
 )
 
 // NewCommand returns a new cobra.Command implementing the root command for kinder.
 func NewUyuniadmCommand() (*cobra.Command, error) {
 	globalFlags := &types.GlobalFlags{}
+	var remoteFlags podman.RemoteFlags
+	var verifyFlags podman.VerifyFlags
+	var clusterFlags kubernetes.ClusterFlags
 	name := path.Base(os.Args[0])
 	rootCmd := &cobra.Command{
 		Use:          name,
@@ -42,11 +69,32 @@ func NewUyuniadmCommand() (*cobra.Command, error) {
 		SilenceUsage: true, // Don't show usage help on errors
 	}
 
+	utils.SetEnvPrefix("MGRADM")
 	rootCmd.SetUsageTemplate(utils.GetLocalizedUsageTemplate())
 
 	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
-		utils.LogInit(true)
+		if err := utils.ApplyEnvToGlobalFlags(cmd); err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to read the global flags from the environment"))
+		}
+		if err := utils.ApplyProfileToGlobalFlags(cmd); err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to apply the configuration profile"))
+		}
+		utils.LogInit(!globalFlags.Machine, globalFlags.LogFile)
 		utils.SetLogLevel(globalFlags.LogLevel)
+		if globalFlags.Machine {
+			utils.EnableMachineOutput(cmd)
+		}
+		if err := utils.EnableProgress(globalFlags.Progress); err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to configure the progress events"))
+		}
+
+		if err := podman.SetRemote(cmd, &remoteFlags); err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to configure the remote podman host"))
+		}
+		if err := podman.SetVerify(&verifyFlags); err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to configure the image signature verification"))
+		}
+		kubernetes.SetCluster(&clusterFlags)
 
 		// do not log if running the completion cmd as the output is redirected to create a file to source
 		if cmd.Name() != "completion" {
@@ -57,6 +105,16 @@ func NewUyuniadmCommand() (*cobra.Command, error) {
 
 	rootCmd.PersistentFlags().StringVarP(&globalFlags.ConfigPath, "config", "c", "", L("configuration file path"))
 	rootCmd.PersistentFlags().StringVar(&globalFlags.LogLevel, "logLevel", "", L("application log level")+"(trace|debug|info|warn|error|fatal|panic)")
+	rootCmd.PersistentFlags().StringVar(&globalFlags.LogFile, "logfile", "", L("path to the log file recording the executed commands. Defaults to /var/log/uyuni-tools.log or $HOME/uyuni-tools.log"))
+	utils.AddMachineFlag(rootCmd, &globalFlags.Machine)
+	utils.AddLangFlag(rootCmd)
+	utils.AddProfileFlag(rootCmd)
+	utils.AddProgressFlag(rootCmd, &globalFlags.Progress)
+	podman.AddRemoteFlags(rootCmd, &remoteFlags)
+	podman.AddVerifyFlags(rootCmd, &verifyFlags)
+	if utils.KubernetesBuilt {
+		kubernetes.AddClusterFlags(rootCmd, &clusterFlags)
+	}
 
 	migrateCmd := migrate.NewCommand(globalFlags)
 	rootCmd.AddCommand(migrateCmd)
@@ -71,6 +129,7 @@ func NewUyuniadmCommand() (*cobra.Command, error) {
 	}
 	rootCmd.AddCommand(distroCmd)
 	rootCmd.AddCommand(completion.NewCommand(globalFlags))
+	rootCmd.AddCommand(docs.NewCommand(globalFlags))
 	rootCmd.AddCommand(support.NewCommand(globalFlags))
 	rootCmd.AddCommand(start.NewCommand(globalFlags))
 	rootCmd.AddCommand(hub.NewCommand(globalFlags))
@@ -80,6 +139,27 @@ func NewUyuniadmCommand() (*cobra.Command, error) {
 	rootCmd.AddCommand(inspect.NewCommand(globalFlags))
 	rootCmd.AddCommand(upgrade.NewCommand(globalFlags))
 	rootCmd.AddCommand(gpg.NewCommand(globalFlags))
+	rootCmd.AddCommand(images.NewCommand(globalFlags))
+	rootCmd.AddCommand(debug.NewCommand(globalFlags))
+	rootCmd.AddCommand(config.NewCommand(globalFlags))
+	rootCmd.AddCommand(logs.NewCommand(globalFlags))
+	rootCmd.AddCommand(rename.NewCommand(globalFlags))
+	rootCmd.AddCommand(check.NewCommand(globalFlags))
+	rootCmd.AddCommand(apply.NewCommand(globalFlags))
+	rootCmd.AddCommand(export.NewCommand(globalFlags))
+	rootCmd.AddCommand(generate.NewCommand(globalFlags))
+	rootCmd.AddCommand(profile.NewCommand(globalFlags))
+	rootCmd.AddCommand(credentials.NewCommand(globalFlags))
+	rootCmd.AddCommand(bundle.NewCommand(globalFlags))
+	rootCmd.AddCommand(history.NewCommand(globalFlags))
+	rootCmd.AddCommand(approve.NewCommand(globalFlags))
+	rootCmd.AddCommand(serve.NewCommand(globalFlags))
+	rootCmd.AddCommand(proxy.NewCommand(globalFlags))
+	rootCmd.AddCommand(tftp.NewCommand(globalFlags))
+	rootCmd.AddCommand(shell.NewCommand(globalFlags))
+	rootCmd.AddCommand(db.NewCommand(globalFlags))
+	rootCmd.AddCommand(saline.NewCommand(globalFlags))
+	rootCmd.AddCommand(component.NewCommand(globalFlags))
 
 	rootCmd.AddCommand(utils.GetConfigHelpCommand())
 
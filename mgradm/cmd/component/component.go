@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package component
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/component"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type listFlags struct {
+	utils.OutputFlags `mapstructure:",squash"`
+}
+
+type enableFlags struct {
+	Image  types.ImageFlags `mapstructure:",squash"`
+	Memory string
+	Cpus   string
+}
+
+type disableFlags struct{}
+
+// NewCommand manages the optional components running next to the server.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	componentCmd := &cobra.Command{
+		Use:   "component",
+		Short: L("Manage the optional server components"),
+		Long: L(`Manage the optional server components
+
+Components are additional containers running next to the server. They are only supported with podman.
+Additional components can be defined by writing manifest files in `) + component.ManifestsDir + L(`
+
+Example of manifest:
+
+  name: mycomponent
+  description: My component
+  image: registry.example.com/mycomponent
+  tag: latest
+  env:
+    LOG_LEVEL: debug
+  volumes:
+    - name: etc-salt
+      path: /etc/salt
+  ports:
+    - port: 8080
+      exposed: 8080
+      protocol: tcp
+  requires:
+    - saline
+  database: false
+
+The database entry passes the server database connection to the component in the database_connection,
+database_user and database_password environment variables.
+The image defaults to the server one with a -<name> suffix and the tag defaults to the server one.`),
+	}
+	componentCmd.SetUsageTemplate(componentCmd.UsageTemplate())
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: L("List the available components"),
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags listFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, list)
+		},
+	}
+	utils.AddOutputFlag(listCmd)
+
+	enableCmd := &cobra.Command{
+		Use:   "enable name",
+		Short: L("Enable and start a component"),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags enableFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, utils.WithLock(enable))
+		},
+	}
+	enableCmd.Flags().String("image", "", L("Image of the component, overrides the manifest value"))
+	enableCmd.Flags().String("tag", "", L("Tag of the component image, overrides the manifest value"))
+	enableCmd.Flags().String("memory", "", L("Memory limit of the component container, for example 1g"))
+	enableCmd.Flags().String("cpus", "", L("Number of CPUs of the component container, for example 0.5"))
+	utils.AddLockFlag(enableCmd)
+
+	disableCmd := &cobra.Command{
+		Use:   "disable name",
+		Short: L("Stop and remove a component"),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags disableFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, utils.WithLock(disable))
+		},
+	}
+	utils.AddLockFlag(disableCmd)
+
+	componentCmd.AddCommand(listCmd)
+	componentCmd.AddCommand(enableCmd)
+	componentCmd.AddCommand(disableCmd)
+	return componentCmd
+}
+
+type componentInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Enabled     bool     `json:"enabled"`
+	Requires    []string `json:"requires,omitempty"`
+}
+
+func list(globalFlags *types.GlobalFlags, flags *listFlags, cmd *cobra.Command, args []string) error {
+	manifests, err := component.List()
+	if err != nil {
+		return err
+	}
+
+	table := utils.Table{Headers: []string{L("NAME"), L("ENABLED"), L("REQUIRES"), L("DESCRIPTION")}}
+	infos := []componentInfo{}
+	for _, manifest := range manifests {
+		info := componentInfo{
+			Name:        manifest.Name,
+			Description: manifest.Description,
+			Enabled:     manifest.IsEnabled(),
+			Requires:    manifest.Requires,
+		}
+		infos = append(infos, info)
+
+		enabled := L("no")
+		if info.Enabled {
+			enabled = L("yes")
+		}
+		table.Rows = append(table.Rows, []string{info.Name, enabled, strings.Join(info.Requires, ","), info.Description})
+	}
+	table.Data = infos
+	return table.Print(flags.Output)
+}
+
+func enable(globalFlags *types.GlobalFlags, flags *enableFlags, cmd *cobra.Command, args []string) error {
+	if err := podman.CheckLimits(flags.Memory, flags.Cpus); err != nil {
+		return utils.UsageError(err)
+	}
+	if !podman.HasService(podman.ServerService) {
+		return errors.New(L("no server is deployed with podman"))
+	}
+
+	manifest, err := component.Find(args[0])
+	if err != nil {
+		return err
+	}
+	if flags.Image.Name != "" {
+		manifest.Image = flags.Image.Name
+	}
+	if flags.Image.Tag != "" {
+		manifest.Tag = flags.Image.Tag
+	}
+	manifest.Args = append(manifest.Args, podman.LimitsArgs(flags.Memory, flags.Cpus)...)
+
+	state, err := podman.ReadState()
+	if err != nil {
+		return utils.Errorf(err, L("failed to read the deployment state: %s"))
+	}
+	if state == nil || state.Image == "" {
+		return errors.New(L("the server image is missing in the deployment state"))
+	}
+	image, err := manifest.ImageURL(state.Image)
+	if err != nil {
+		return utils.Errorf(err, L("failed to compute image URL: %s"))
+	}
+
+	inspectedHostValues, err := utils.InspectHost()
+	if err != nil {
+		return utils.Errorf(err, L("cannot inspect host values: %s"))
+	}
+	preparedImage, err := podman.PrepareImage(image, "IfNotPresent", podman.GetPullArgs(inspectedHostValues)...)
+	if err != nil {
+		return err
+	}
+
+	tz := state.Timezone
+	if tz == "" {
+		tz = utils.GetLocalTimezone()
+	}
+	var db *component.Database
+	if manifest.Database {
+		cnx := shared.NewConnection("podman", podman.ServerContainerName, "", "")
+		if db, err = component.ServerDatabase(cnx); err != nil {
+			return err
+		}
+	}
+	if err := component.Enable(manifest, preparedImage, tz, db, true); err != nil {
+		return err
+	}
+	checkServerVolumes(manifest)
+	return nil
+}
+
+// checkServerVolumes warns if the component needs a volume that is not shared by the server container.
+func checkServerVolumes(manifest *component.Manifest) {
+	serverUnit, err := os.ReadFile(podman.GetServicePath(podman.ServerService))
+	if err != nil {
+		return
+	}
+	volume := utils.RunSaltMasterVolumeMount
+	for _, componentVolume := range manifest.Volumes {
+		if componentVolume.Name == volume.Name && !strings.Contains(string(serverUnit), volume.Name+":") {
+			log.Warn().Msgf(L("The server container doesn't mount the %s volume: run mgradm install podman with --saline-replicas 1 to add it"), volume.Name)
+		}
+	}
+}
+
+func disable(globalFlags *types.GlobalFlags, flags *disableFlags, cmd *cobra.Command, args []string) error {
+	return component.Disable(args[0])
+}
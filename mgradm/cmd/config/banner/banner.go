@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package banner
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type bannerFlags struct {
+	Backend   string
+	Namespace string
+	Clear     bool
+}
+
+// bannerProperty is the web UI configuration displaying a message at the top of the pages.
+const bannerProperty = "java.custom_header"
+
+// NewCommand sets the banner displayed in the web UI.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "banner [message]",
+		Short: L("Set the banner displayed in the web UI"),
+		Long: L(`Set the banner displayed in the web UI
+
+The message is displayed at the top of all the web UI pages, for instance to inform the users
+of an ongoing maintenance. The web UI is restarted to apply the change.
+
+The XML-RPC API has no method to change this banner: the web UI configuration is changed instead.
+
+Example:
+  mgradm config banner "Maintenance in progress until 23:00, the server may be unavailable"
+  mgradm config banner --clear
+`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags bannerFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, setBanner)
+		},
+	}
+	cmd.Flags().Bool("clear", false, L("Remove the banner"))
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(cmd)
+		utils.AddNamespaceFlag(cmd)
+	}
+	return cmd
+}
+
+func setBanner(globalFlags *types.GlobalFlags, flags *bannerFlags, cmd *cobra.Command, args []string) error {
+	message := ""
+	if len(args) > 0 {
+		message = args[0]
+	}
+	if flags.Clear == (message != "") {
+		return utils.UsageError(errors.New(L("either a message or --clear is required")))
+	}
+
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
+	if flags.Clear {
+		log.Info().Msg(L("Removing the web UI banner"))
+	} else {
+		log.Info().Msgf(L("Setting the web UI banner to: %s"), message)
+	}
+	if err := cmd_utils.ExecCommand(zerolog.DebugLevel, cnx, bannerScript(message)); err != nil {
+		return utils.Errorf(err, L("failed to set the web UI banner: %s"))
+	}
+	return nil
+}
+
+// bannerScript generates the script changing the banner configuration and restarting the web UI.
+//
+// An empty message removes the banner.
+func bannerScript(message string) string {
+	lines := []string{
+		"set -e",
+		fmt.Sprintf(`sed -i '/^\s*%s\s*=/d' /etc/rhn/rhn.conf`, strings.ReplaceAll(bannerProperty, ".", `\.`)),
+	}
+	if message != "" {
+		value := `<div class="alert alert-warning">` + html.EscapeString(message) + "</div>"
+		lines = append(lines, fmt.Sprintf("echo %s >> /etc/rhn/rhn.conf", utils.ShellQuote(bannerProperty+" = "+value)))
+	}
+	lines = append(lines, "systemctl restart tomcat")
+	return strings.Join(lines, "\n")
+}
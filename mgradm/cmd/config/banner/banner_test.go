@@ -0,0 +1,26 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package banner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBannerScript(t *testing.T) {
+	script := bannerScript("Maintenance <tonight>, don't log in")
+	if !strings.Contains(script, `sed -i '/^\s*java\.custom_header\s*=/d' /etc/rhn/rhn.conf`) {
+		t.Errorf("expected the previous banner to be removed:\n%s", script)
+	}
+	expected := `echo 'java.custom_header = <div class="alert alert-warning">Maintenance &lt;tonight&gt;, don&#39;t log in</div>' >> /etc/rhn/rhn.conf`
+	if !strings.Contains(script, expected) {
+		t.Errorf("expected the escaped banner to be added:\n%s", script)
+	}
+
+	script = bannerScript("")
+	if strings.Contains(script, "echo") || !strings.HasSuffix(script, "systemctl restart tomcat") {
+		t.Errorf("expected only the banner removal and restart:\n%s", script)
+	}
+}
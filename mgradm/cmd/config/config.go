@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/config/banner"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/config/loglevel"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/config/timezone"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// NewCommand to change the configuration of the server services.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: L("Change the configuration of the server services"),
+		Long:  L("Change the configuration of the server services"),
+	}
+	configCmd.AddCommand(banner.NewCommand(globalFlags))
+	configCmd.AddCommand(loglevel.NewCommand(globalFlags))
+	configCmd.AddCommand(timezone.NewCommand(globalFlags))
+	return configCmd
+}
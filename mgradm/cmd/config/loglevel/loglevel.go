@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package loglevel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type logLevelFlags struct {
+	Backend   string
+	Namespace string
+	Component string
+	Level     string
+	Revert    struct {
+		After time.Duration
+	}
+	Follow bool
+}
+
+// defaultLevel restores the log level shipped with the server.
+const defaultLevel = "default"
+
+// levels are the accepted log levels, from the most verbose to the least one.
+var levels = []string{"trace", "debug", "info", "warning", "error", defaultLevel}
+
+// component describes how to change the log level of a server service.
+type component struct {
+	// Service is the systemd service to restart in the container.
+	Service string
+	// LogPath is the log file to follow.
+	LogPath string
+	// setScript returns the script changing the configuration to the level.
+	setScript func(level string) string
+	// revertScript is the script restoring the shipped configuration.
+	revertScript string
+}
+
+// log4jComponent changes the root logger level of a log4j2 configuration.
+//
+// The original file is kept aside to be restored.
+func log4jComponent(service string, configPath string, logPath string) component {
+	return component{
+		Service: service,
+		LogPath: logPath,
+		setScript: func(level string) string {
+			if level == "warning" {
+				level = "warn"
+			}
+			return fmt.Sprintf(`[ -f %[1]s.orig ] || cp -p %[1]s %[1]s.orig
+sed -i -E 's/(<Root +level=")[^"]*/\1%[2]s/I' %[1]s`, configPath, level)
+		},
+		revertScript: fmt.Sprintf("if [ -f %[1]s.orig ]; then mv %[1]s.orig %[1]s; fi", configPath),
+	}
+}
+
+const saltLogLevelPath = "/etc/salt/master.d/zz-mgradm-loglevel.conf"
+
+var components = map[string]component{
+	"tomcat": log4jComponent("tomcat", "/srv/tomcat/webapps/rhn/WEB-INF/classes/log4j2.xml",
+		"/var/log/rhn/rhn_web_ui.log"),
+	"taskomatic": log4jComponent("taskomatic", "/usr/share/rhn/classes/log4j2.xml",
+		"/var/log/rhn/rhn_taskomatic_daemon.log"),
+	"salt": {
+		Service: "salt-master",
+		LogPath: "/var/log/salt/master",
+		setScript: func(level string) string {
+			return fmt.Sprintf("echo 'log_level_logfile: %s' > %s", level, saltLogLevelPath)
+		},
+		revertScript: "rm -f " + saltLogLevelPath,
+	},
+}
+
+// NewCommand changes the log level of a server service.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "loglevel",
+		Short: L("Change the log level of a server service"),
+		Long: L(`Change the log level of a server service
+
+The configuration of the service is changed inside the container and the service is restarted.
+Use the default level to restore the shipped configuration. The default level can also be
+restored automatically after a while with --revert-after.
+
+Example:
+  mgradm config loglevel --component taskomatic --level debug --revert-after 1h --follow
+`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags logLevelFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, setLogLevel)
+		},
+	}
+
+	cmd.Flags().String("component", "", L("Service to configure: ")+strings.Join(componentNames(), ", "))
+	cmd.Flags().String("level", "", L("Log level: ")+strings.Join(levels, ", "))
+	cmd.Flags().Duration("revert-after", 0,
+		L("Restore the default level after this duration, for instance 30m. Never restored if 0"))
+	cmd.Flags().BoolP("follow", "f", false, L("Follow the service log after changing the level"))
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(cmd)
+		utils.AddNamespaceFlag(cmd)
+	}
+	return cmd
+}
+
+// componentNames returns the sorted names of the components.
+func componentNames() []string {
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func setLogLevel(globalFlags *types.GlobalFlags, flags *logLevelFlags, cmd *cobra.Command, args []string) error {
+	script, err := logLevelScript(flags)
+	if err != nil {
+		return err
+	}
+
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
+	log.Info().Msgf(L("Setting %[1]s log level to %[2]s"), flags.Component, flags.Level)
+	if err := cmd_utils.ExecCommand(zerolog.DebugLevel, cnx, script); err != nil {
+		return utils.Errorf(err, L("failed to change the log level of %[1]s: %[2]s"), flags.Component)
+	}
+	if flags.Revert.After > 0 && flags.Level != defaultLevel {
+		log.Info().Msgf(L("The default log level will be restored in %s"), flags.Revert.After)
+	}
+
+	if !flags.Follow {
+		return nil
+	}
+	return cnx.ExecInteractive(shared.ExecOptions{}, "tail", "-n", "0", "-F", components[flags.Component].LogPath)
+}
+
+// logLevelScript generates the script to run in the container to change the log level.
+func logLevelScript(flags *logLevelFlags) (string, error) {
+	comp, ok := components[flags.Component]
+	if !ok {
+		return "", utils.UsageError(fmt.Errorf(L("invalid component %[1]s, possible values are: %[2]s"),
+			flags.Component, strings.Join(componentNames(), ", ")))
+	}
+	if !utils.Contains(levels, flags.Level) {
+		return "", utils.UsageError(fmt.Errorf(L("invalid level %[1]s, possible values are: %[2]s"),
+			flags.Level, strings.Join(levels, ", ")))
+	}
+
+	revert := comp.revertScript + "\nsystemctl restart " + comp.Service
+	timer := "mgradm-loglevel-" + flags.Component
+	lines := []string{
+		"set -e",
+		// Cancel any pending revert of a previous change
+		fmt.Sprintf("systemctl stop %[1]s.timer %[1]s.service 2>/dev/null || true", timer),
+	}
+	if flags.Level == defaultLevel {
+		lines = append(lines, revert)
+		return strings.Join(lines, "\n"), nil
+	}
+
+	lines = append(lines, comp.setScript(flags.Level), "systemctl restart "+comp.Service)
+	if flags.Revert.After > 0 {
+		lines = append(lines, fmt.Sprintf("systemd-run --unit %s --on-active=%ds /bin/sh -c %s",
+			timer, int(flags.Revert.After.Seconds()), utils.ShellQuote(revert)))
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package loglevel
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogLevelScript(t *testing.T) {
+	data := []struct {
+		component   string
+		level       string
+		revertAfter time.Duration
+		expected    []string
+		unexpected  []string
+	}{
+		{"tomcat", "warning", 0,
+			[]string{
+				"cp -p /srv/tomcat/webapps/rhn/WEB-INF/classes/log4j2.xml /srv/tomcat/webapps/rhn/WEB-INF/classes/log4j2.xml.orig",
+				`\1warn/I'`,
+				"systemctl restart tomcat",
+			},
+			[]string{"systemd-run"},
+		},
+		{"salt", "debug", 30 * time.Minute,
+			[]string{
+				"echo 'log_level_logfile: debug' > /etc/salt/master.d/zz-mgradm-loglevel.conf",
+				"systemctl restart salt-master",
+				"systemd-run --unit mgradm-loglevel-salt --on-active=1800s /bin/sh -c " +
+					"'rm -f /etc/salt/master.d/zz-mgradm-loglevel.conf\nsystemctl restart salt-master'",
+			},
+			[]string{},
+		},
+		{"taskomatic", "default", time.Hour,
+			[]string{
+				"systemctl stop mgradm-loglevel-taskomatic.timer",
+				"mv /usr/share/rhn/classes/log4j2.xml.orig /usr/share/rhn/classes/log4j2.xml",
+				"systemctl restart taskomatic",
+			},
+			[]string{"sed", "systemd-run"},
+		},
+	}
+
+	for _, test := range data {
+		flags := logLevelFlags{Component: test.component, Level: test.level}
+		flags.Revert.After = test.revertAfter
+		script, err := logLevelScript(&flags)
+		if err != nil {
+			t.Errorf("%s %s: unexpected error: %s", test.component, test.level, err)
+			continue
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(script, expected) {
+				t.Errorf("%s %s: missing %q in script:\n%s", test.component, test.level, expected, script)
+			}
+		}
+		for _, unexpected := range test.unexpected {
+			if strings.Contains(script, unexpected) {
+				t.Errorf("%s %s: unexpected %q in script:\n%s", test.component, test.level, unexpected, script)
+			}
+		}
+	}
+}
+
+func TestLogLevelScriptInvalid(t *testing.T) {
+	for _, flags := range []logLevelFlags{
+		{Component: "apache", Level: "debug"},
+		{Component: "tomcat", Level: "verbose"},
+	} {
+		if _, err := logLevelScript(&flags); err == nil {
+			t.Errorf("expected an error for %s %s", flags.Component, flags.Level)
+		}
+	}
+}
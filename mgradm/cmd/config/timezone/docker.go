@@ -0,0 +1,14 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build docker
+
+package timezone
+
+import "github.com/uyuni-project/uyuni-tools/shared"
+
+func init() {
+	// The docker server container runs in the same systemd service and records the same state as with podman
+	backendFuncs[shared.DockerBackend] = timezoneForPodman
+}
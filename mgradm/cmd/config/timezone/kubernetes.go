@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !nok8s
+
+package timezone
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	adm_kubernetes "github.com/uyuni-project/uyuni-tools/mgradm/shared/kubernetes"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func timezoneForKubernetes(
+	globalFlags *types.GlobalFlags,
+	flags *timezoneFlags,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	timezone := args[0]
+	clusterInfos, err := kubernetes.CheckCluster()
+	if err != nil {
+		return err
+	}
+	namespace, err := kubernetes.GetNamespace(flags.Namespace, kubernetes.ServerFilter)
+	if err != nil {
+		return err
+	}
+
+	// The pod is recreated by the deployment when its environment changes
+	if err := adm_kubernetes.UpdateValues(&flags.Helm, clusterInfos.GetKubeconfig(), namespace,
+		"timezone="+timezone); err != nil {
+		return err
+	}
+	if err := kubernetes.WaitForDeployment(namespace, "uyuni", "uyuni"); err != nil {
+		return err
+	}
+
+	state, err := kubernetes.ReadState(namespace)
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Failed to read the deployment state"))
+	}
+	if state != nil {
+		state.Timezone = timezone
+		if err := kubernetes.WriteState(namespace, state); err != nil {
+			log.Warn().Err(err).Msg(L("Failed to save the deployment state"))
+		}
+	}
+
+	log.Info().Msgf(L("Server timezone set to %s"), timezone)
+	return nil
+}
@@ -2,19 +2,20 @@
 //
 // SPDX-License-Identifier: Apache-2.0
 
-package stop
+//go:build nok8s
+
+package timezone
 
 import (
 	"github.com/spf13/cobra"
-	"github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 )
 
-func podmanStop(
+func timezoneForKubernetes(
 	globalFlags *types.GlobalFlags,
-	flags *stopFlags,
+	flags *timezoneFlags,
 	cmd *cobra.Command,
 	args []string,
 ) error {
-	return podman.StopService(podman.ProxyService)
+	return nil
 }
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package timezone
+
+import (
+	"errors"
+	"os"
+	"regexp"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+var timezoneRegex = regexp.MustCompile(`(?m)^Environment=TZ=.*$`)
+
+func timezoneForPodman(globalFlags *types.GlobalFlags, flags *timezoneFlags, cmd *cobra.Command, args []string) error {
+	timezone := args[0]
+
+	servicePath := podman.GetServicePath(podman.ServerService)
+	content, err := os.ReadFile(servicePath)
+	if err != nil {
+		return utils.Errorf(err, L("failed to read file %s: %s"), servicePath)
+	}
+	newContent, err := setUnitTimezone(string(content), timezone)
+	if err != nil {
+		return err
+	}
+
+	if newContent != string(content) {
+		if err := os.WriteFile(servicePath, []byte(newContent), 0555); err != nil {
+			return utils.Errorf(err, L("cannot write %s file: %s"), servicePath)
+		}
+		if err := podman.ReloadDaemon(false); err != nil {
+			return err
+		}
+		log.Info().Msg(L("Restarting the server"))
+		if err := podman.RestartService(podman.ServerService); err != nil {
+			return err
+		}
+	} else {
+		utils.SetMachineChanged(false)
+	}
+
+	state, err := podman.ReadState()
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Failed to read the deployment state"))
+	}
+	if state != nil {
+		state.Timezone = timezone
+		if err := podman.WriteState(state); err != nil {
+			log.Warn().Err(err).Msg(L("Failed to save the deployment state"))
+		}
+	}
+
+	log.Info().Msgf(L("Server timezone set to %s"), timezone)
+	return nil
+}
+
+// setUnitTimezone changes the timezone in the server systemd service.
+func setUnitTimezone(unit string, timezone string) (string, error) {
+	if !timezoneRegex.MatchString(unit) {
+		return "", errors.New(L("no timezone found in the server systemd service"))
+	}
+	return timezoneRegex.ReplaceAllLiteralString(unit, "Environment=TZ="+timezone), nil
+}
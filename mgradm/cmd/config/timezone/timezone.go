@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package timezone
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// backendFuncs are the implementations of the command for each backend.
+var backendFuncs = map[string]utils.CommandFunc[timezoneFlags]{
+	shared.PodmanBackend:     timezoneForPodman,
+	shared.KubernetesBackend: timezoneForKubernetes,
+}
+
+type timezoneFlags struct {
+	Backend   string
+	Namespace string
+	Helm      cmd_utils.HelmFlags
+}
+
+// NewCommand changes the timezone of the server.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "timezone <timezone>",
+		Short: L("Change the timezone of the server"),
+		Long: L(`Change the timezone of the server
+
+The timezone is a name from the tz database like Europe/Berlin. The server is restarted to apply it.
+The new timezone is recorded in the deployment state to be kept during the upgrades.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags timezoneFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, setTimezone)
+		},
+	}
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(cmd)
+		utils.AddNamespaceFlag(cmd)
+		cmd_utils.AddHelmChartFlags(cmd)
+	}
+	return cmd
+}
+
+func setTimezone(globalFlags *types.GlobalFlags, flags *timezoneFlags, cmd *cobra.Command, args []string) error {
+	if _, err := time.LoadLocation(args[0]); err != nil {
+		return utils.UsageError(utils.Errorf(err, L("invalid timezone %[1]s: %[2]s"), args[0]))
+	}
+
+	fn, err := shared.ChooseBackendFunc(cmd.Flags(), shared.ServerApp, backendFuncs)
+	if err != nil {
+		return err
+	}
+	return fn(globalFlags, flags, cmd, args)
+}
@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package timezone
+
+import "testing"
+
+func TestSetUnitTimezone(t *testing.T) {
+	unit := "[Service]\nEnvironment=PODMAN_SYSTEMD_UNIT=%n\nEnvironment=TZ=Europe/Berlin\nRestart=on-failure\n"
+	expected := "[Service]\nEnvironment=PODMAN_SYSTEMD_UNIT=%n\nEnvironment=TZ=America/New_York\nRestart=on-failure\n"
+
+	actual, err := setUnitTimezone(unit, "America/New_York")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if actual != expected {
+		t.Errorf("unexpected unit:\n%s", actual)
+	}
+
+	if _, err := setUnitTimezone("[Service]\nRestart=on-failure\n", "UTC"); err == nil {
+		t.Error("expected an error without any timezone in the unit")
+	}
+}
@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials
+
+import (
+	"errors"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type setFlags struct {
+	User     string
+	Password string
+}
+
+type deleteFlags struct{}
+
+// NewCommand manages the stored credentials.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	credentialsCmd := &cobra.Command{
+		Use:   "credentials",
+		Short: L("Manage the stored credentials"),
+		Long: L(`Manage the stored credentials
+
+The SUSE Customer Center credentials, the container registries credentials and the API sessions
+are stored in the Secret Service keyring if secret-tool is installed and a desktop session is running,
+or in the kernel user keyring if keyctl is installed. They are stored in a file only readable
+by the user in the configuration directory otherwise.
+
+The SUSE Customer Center credentials passed to install are stored and used to pull the images
+when they are not found on the host. The credentials stored for a registry are used to pull
+its images. The API sessions are stored after a login and reused until they expire.
+
+Credentials:
+  scc                          SUSE Customer Center credentials
+  registry <host>              credentials of a container registry, like registry.example.com:5000
+  api-session <server> <user>  API session of a user, only to be deleted`),
+	}
+	credentialsCmd.SetUsageTemplate(credentialsCmd.UsageTemplate())
+
+	setCmd := &cobra.Command{
+		Use:   "set scc|registry <host>",
+		Short: L("Store credentials"),
+		Long: L(`Store credentials
+
+The password is asked if not passed.`),
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags setFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, set)
+		},
+	}
+	setCmd.Flags().String("user", "", L("User name"))
+	setCmd.Flags().String("password", "", L("Password"))
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete scc|registry <host>|api-session <server> <user>",
+		Short: L("Remove stored credentials"),
+		Args:  cobra.RangeArgs(1, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags deleteFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, remove)
+		},
+	}
+
+	credentialsCmd.AddCommand(setCmd)
+	credentialsCmd.AddCommand(deleteCmd)
+	return credentialsCmd
+}
+
+// credentialsKey returns the key in the credentials store of the credentials described by the arguments.
+func credentialsKey(args []string, allowSession bool) (string, error) {
+	switch {
+	case len(args) == 1 && args[0] == "scc":
+		return utils.SccCredentialsKey, nil
+	case len(args) == 2 && args[0] == "registry" && args[1] != "":
+		return utils.RegistryCredentialsKey(args[1]), nil
+	case allowSession && len(args) == 3 && args[0] == "api-session" && args[1] != "" && args[2] != "":
+		return utils.APISessionKey(args[1], args[2]), nil
+	}
+	return "", utils.UsageError(errors.New(L("unknown credentials, see the help for the supported ones")))
+}
+
+func set(globalFlags *types.GlobalFlags, flags *setFlags, cmd *cobra.Command, args []string) error {
+	key, err := credentialsKey(args, false)
+	if err != nil {
+		return err
+	}
+	utils.AskIfMissing(&flags.User, L("User name"), 1, 0, nil)
+	utils.AskPasswordIfMissing(&flags.Password, L("Password"), 1, 0, nil)
+
+	credentials := utils.Credentials{User: flags.User, Password: flags.Password}
+	if err := utils.StoreCredentials(key, credentials); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Credentials stored in %s"), utils.GetCredentialsProvider().Name())
+	return nil
+}
+
+func remove(globalFlags *types.GlobalFlags, flags *deleteFlags, cmd *cobra.Command, args []string) error {
+	key, err := credentialsKey(args, true)
+	if err != nil {
+		return err
+	}
+	if err := utils.DeleteCredentials(key); err != nil {
+		return err
+	}
+	log.Info().Msg(L("Credentials removed"))
+	return nil
+}
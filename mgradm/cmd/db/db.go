@@ -0,0 +1,23 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/db/replication"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// NewCommand to manage the server database.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	dbCmd := &cobra.Command{
+		Use:   "db",
+		Short: L("Manage the server database"),
+		Long:  L("Manage the server database"),
+	}
+	dbCmd.AddCommand(replication.NewCommand(globalFlags))
+	return dbCmd
+}
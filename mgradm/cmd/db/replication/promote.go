@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package replication
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type promoteFlags struct {
+	Standby string
+	Ssh     sshFlags
+}
+
+func newPromoteCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "promote",
+		Short: L("Promote the standby database to primary"),
+		Long: L(`Promote the standby database to primary
+
+The standby database stops following the server database and accepts write operations.
+Only run this command when the server is definitely down: the two databases diverge after
+the promotion and the replication has to be set up again from scratch.
+
+The command runs on the standby host, or connects to it with SSH if --standby is set.
+`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags promoteFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, promote)
+		},
+	}
+	cmd.Flags().String("standby", "", L("FQDN of the standby host. The local host is used if not set"))
+	addSshFlags(cmd)
+	return cmd
+}
+
+func promote(globalFlags *types.GlobalFlags, flags *promoteFlags, cmd *cobra.Command, args []string) error {
+	promoteArgs := []string{"exec", standbyContainer,
+		"su", "-s", "/bin/bash", "-", "postgres", "-c", "pg_ctl promote -D /var/lib/pgsql/data"}
+
+	var err error
+	if flags.Standby == "" {
+		log.Info().Msg(L("Promoting the local standby database"))
+		_, err = utils.RunCmdOutput(zerolog.InfoLevel, "podman", promoteArgs...)
+	} else {
+		log.Info().Msgf(L("Promoting the standby database on %s"), flags.Standby)
+		script := "podman"
+		for _, arg := range promoteArgs {
+			script += " " + utils.ShellQuote(arg)
+		}
+		err = runOnStandby(flags.Standby, &flags.Ssh, script)
+	}
+	if err != nil {
+		return utils.Errorf(err, L("failed to promote the standby database: %s"))
+	}
+
+	log.Info().Msg(L("The standby database is now the primary one. Set up a server using it as external database."))
+	return nil
+}
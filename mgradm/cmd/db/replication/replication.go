@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package replication
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// Names of the standby database container and volume on the standby host.
+const (
+	standbyContainer = "uyuni-db-standby"
+	standbyVolume    = "var-pgsql"
+)
+
+type sshFlags struct {
+	User string
+	Port int
+}
+
+// NewCommand to manage the replication of the server database to a standby host.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	replicationCmd := &cobra.Command{
+		Use:   "replication",
+		Short: L("Manage the replication of the database to a standby host"),
+		Long: L(`Manage the replication of the database to a standby host
+
+The server database is continuously copied to a PostgreSQL container on a standby host
+using streaming replication. In case of failure of the server host, the standby database
+can be promoted to become the primary one.
+
+Limitations:
+  - only podman deployments are supported, for both the server and the standby host.
+  - only the database is replicated: the other server volumes, like the packages or
+    the configuration, need to be backed up separately.
+  - after promotion, a server has to be set up using the promoted database as external one.
+`),
+	}
+	replicationCmd.AddCommand(newSetupCommand(globalFlags))
+	replicationCmd.AddCommand(newPromoteCommand(globalFlags))
+	return replicationCmd
+}
+
+func addSshFlags(cmd *cobra.Command) {
+	cmd.Flags().String("ssh-user", "root", L("User to connect to the standby host with SSH"))
+	cmd.Flags().Int("ssh-port", 22, L("SSH port of the standby host"))
+}
+
+// runOnStandby runs a shell script on the standby host using SSH.
+//
+// The connection must not require a password, using an SSH agent for instance.
+func runOnStandby(host string, ssh *sshFlags, script string) error {
+	target := host
+	if ssh.User != "" {
+		target = ssh.User + "@" + host
+	}
+	args := []string{"-o", "BatchMode=yes", "-p", fmt.Sprint(ssh.Port), target}
+	command := "bash -c " + utils.ShellQuote(script)
+	if ssh.User != "" && ssh.User != "root" {
+		command = "sudo -n " + command
+	}
+	_, err := utils.RunCmdOutput(zerolog.InfoLevel, "ssh", append(args, command)...)
+	return err
+}
+
+// slotName computes a replication slot name from a host name.
+//
+// Slot names can only contain lower case letters, numbers and underscores.
+func slotName(host string) string {
+	if net.ParseIP(host) == nil {
+		host = strings.Split(host, ".")[0]
+	}
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(host))
+	return "standby_" + name
+}
@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package replication
+
+import "testing"
+
+func TestSlotName(t *testing.T) {
+	data := map[string]string{
+		"standby.example.com": "standby_standby",
+		"DB-Standby2.lan":     "standby_db_standby2",
+		"192.168.1.12":        "standby_192_168_1_12",
+	}
+	for host, expected := range data {
+		if actual := slotName(host); actual != expected {
+			t.Errorf("%s: expected %s got %s", host, expected, actual)
+		}
+	}
+}
+
+func TestPasswordRegex(t *testing.T) {
+	data := map[string]bool{
+		"aGVsbG8gd29ybGQ+/=": true,
+		"my.pass_word-1":     true,
+		"pass word":          false,
+		"pass'word":          false,
+		"pass$word":          false,
+	}
+	for password, expected := range data {
+		if actual := passwordRegex.MatchString(password); actual != expected {
+			t.Errorf("%s: expected %v got %v", password, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package replication
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/templates"
+	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type setupFlags struct {
+	Standby     string
+	Primary     string
+	Slot        string
+	Image       string
+	Replication struct {
+		User     string
+		Password string
+	}
+	Ssh sshFlags
+}
+
+// passwordRegex matches the passwords which can be used in the replication scripts without quoting.
+var passwordRegex = regexp.MustCompile(`^[[:alnum:]+/=._-]+$`)
+
+func newSetupCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "setup",
+		Short: L("Set up the replication of the database to a standby host"),
+		Long: L(`Set up the replication of the database to a standby host
+
+The server database is configured for streaming replication: a replication user and slot
+are created, the WAL settings are adjusted and PostgreSQL is restarted. The database is then
+cloned on the standby host and a uyuni-db-standby container is started there, following
+the changes of the server database.
+
+The standby host needs podman and to be reachable with SSH without password, using an SSH
+agent for instance. The PostgreSQL port 5432 of the server has to be reachable from the
+standby host, for instance by running on the server host:
+
+  firewall-cmd --permanent --add-port=5432/tcp && firewall-cmd --reload
+`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags setupFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, utils.WithLock(setup))
+		},
+	}
+	cmd.Flags().String("standby", "", L("FQDN of the standby host"))
+	_ = cmd.MarkFlagRequired("standby")
+	cmd.Flags().String("primary", "", L("Server address to use from the standby host. Defaults to the server FQDN"))
+	cmd.Flags().String("slot", "", L("Name of the replication slot. Computed from the standby host name by default"))
+	cmd.Flags().String("image", "", L("Image of the standby database. Defaults to the running server image"))
+	cmd.Flags().String("replication-user", "replicator", L("PostgreSQL user for the replication"))
+	cmd.Flags().String("replication-password", "", L("Password of the replication user. Generated if not set"))
+	addSshFlags(cmd)
+	utils.AddLockFlag(cmd)
+	return cmd
+}
+
+func setup(globalFlags *types.GlobalFlags, flags *setupFlags, cmd *cobra.Command, args []string) error {
+	if flags.Replication.Password == "" {
+		flags.Replication.Password = utils.GetRandomBase64(30)
+	} else if !passwordRegex.MatchString(flags.Replication.Password) {
+		return utils.UsageError(errors.New(L("the replication password can only contain letters, digits and +/=._- characters")))
+	}
+	if flags.Slot == "" {
+		flags.Slot = slotName(flags.Standby)
+	}
+
+	cnx := shared.NewConnection("podman", podman.ServerContainerName, "", "")
+	if flags.Primary == "" {
+		fqdn, err := adm_utils.GetRhnConfValue(cnx, "java.hostname")
+		if err != nil || fqdn == "" {
+			return utils.Errorf(err, L("failed to read the server FQDN: %s"))
+		}
+		flags.Primary = fqdn
+	}
+	if flags.Image == "" {
+		image, err := adm_utils.RunningImage(cnx, podman.ServerContainerName)
+		if err != nil {
+			return utils.Errorf(err, L("failed to find the running server image: %s"))
+		}
+		flags.Image = image
+	}
+
+	log.Info().Msgf(L("Preparing the server database for the replication to %s"), flags.Standby)
+	primaryScript, err := renderScript(templates.ReplicationPrimaryTemplateData{
+		User:           flags.Replication.User,
+		Password:       flags.Replication.Password,
+		Slot:           flags.Slot,
+		StandbyAddress: standbyAddress(flags.Standby),
+	})
+	if err != nil {
+		return err
+	}
+	if err := adm_utils.ExecCommand(zerolog.InfoLevel, cnx, primaryScript); err != nil {
+		return utils.Errorf(err, L("failed to prepare the server database: %s"))
+	}
+
+	log.Info().Msgf(L("Setting up the standby database on %s"), flags.Standby)
+	standbyScript, err := renderScript(templates.ReplicationStandbyTemplateData{
+		Image:     flags.Image,
+		Container: standbyContainer,
+		Volume:    standbyVolume,
+		Primary:   flags.Primary,
+		Port:      5432,
+		User:      flags.Replication.User,
+		Password:  flags.Replication.Password,
+		Slot:      flags.Slot,
+	})
+	if err != nil {
+		return err
+	}
+	if err := runOnStandby(flags.Standby, &flags.Ssh, standbyScript); err != nil {
+		return utils.Errorf(err, L("failed to set up the standby database: %s"))
+	}
+
+	log.Info().Msgf(L("The database is replicated to %[1]s using the %[2]s slot"), flags.Standby, flags.Slot)
+	log.Info().Msgf(L("Remove the slot with pg_drop_replication_slot('%s') if the standby is decommissioned: the server keeps the WAL files for it"), flags.Slot)
+	return nil
+}
+
+// standbyAddress computes the pg_hba.conf address of the standby host.
+//
+// The host name is used if it cannot be resolved.
+func standbyAddress(host string) string {
+	ips, err := net.LookupIP(host)
+	if err != nil || len(ips) == 0 {
+		log.Warn().Err(err).Msgf(L("Failed to resolve %s, using the name in the PostgreSQL access configuration"), host)
+		return host
+	}
+	if ip := ips[0].To4(); ip != nil {
+		return fmt.Sprintf("%s/32", ip)
+	}
+	return fmt.Sprintf("%s/128", ips[0])
+}
+
+func renderScript(template utils.Template) (string, error) {
+	var buf bytes.Buffer
+	if err := template.Render(&buf); err != nil {
+		return "", utils.Errorf(err, L("failed to generate the replication script: %s"))
+	}
+	return buf.String(), nil
+}
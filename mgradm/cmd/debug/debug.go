@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package debug
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type debugFlags struct {
+	Backend   string
+	Namespace string
+	Helm      cmd_utils.HelmFlags
+}
+
+// javaDebugService is a java service which can be remotely debugged.
+type javaDebugService struct {
+	// Service is the systemd service name in the container.
+	Service string
+	// ConfigPath is the file setting the JAVA_OPTS of the service.
+	ConfigPath string
+	// PortName is the name of the port in utils.DEBUG_PORTS.
+	PortName string
+}
+
+var javaDebugServices = []javaDebugService{
+	{"tomcat", "/etc/tomcat/conf.d/remote_debug.conf", "tomcat-debug"},
+	{"taskomatic", "/etc/rhn/taskomatic.conf", "tasko-debug"},
+	{"rhn-search", "/usr/share/rhn/config-defaults/rhn_search_daemon.conf", "search-debug"},
+}
+
+// NewCommand toggles the java remote debugging.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	debugCmd := &cobra.Command{
+		Use:   "debug",
+		Short: L("Manage the java remote debugging"),
+		Long: L(`Manage the java remote debugging of a running server
+
+This exposes or hides the debug ports and sets the JVM flags of tomcat, taskomatic and the search
+daemon without having to reinstall with --debug-java. Only the services that need it are restarted.`),
+		Args: cobra.ExactArgs(0),
+	}
+
+	debugCmd.AddCommand(newToggleCommand(globalFlags, "enable", L("Enable the java remote debugging"), true))
+	debugCmd.AddCommand(newToggleCommand(globalFlags, "disable", L("Disable the java remote debugging"), false))
+	return debugCmd
+}
+
+func newToggleCommand(globalFlags *types.GlobalFlags, name string, short string, enable bool) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   name,
+		Short: short,
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags debugFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags,
+				func(globalFlags *types.GlobalFlags, flags *debugFlags, cmd *cobra.Command, args []string) error {
+					return toggleDebug(flags, cmd, enable)
+				})
+		},
+	}
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(cmd)
+		utils.AddNamespaceFlag(cmd)
+		cmd_utils.AddHelmChartFlags(cmd)
+	}
+	return cmd
+}
+
+func toggleDebug(flags *debugFlags, cmd *cobra.Command, enable bool) error {
+	backend, err := shared.FindBackend(cmd.Flags(), shared.ServerApp)
+	if err != nil {
+		return err
+	}
+	if backend.Name() == shared.KubernetesBackend {
+		return debugForKubernetes(flags, enable)
+	}
+	return debugForPodman(flags, enable)
+}
+
+// debugPort returns the debug port with the given name.
+func debugPort(name string) types.PortMap {
+	for _, port := range utils.DEBUG_PORTS {
+		if port.Name == name {
+			return port
+		}
+	}
+	panic(fmt.Sprintf("no %s debug port", name))
+}
+
+// javaDebugScript generates the script setting the JVM flags and restarting the java services.
+func javaDebugScript(enable bool) string {
+	lines := []string{"set -e"}
+	services := []string{}
+	for _, service := range javaDebugServices {
+		lines = append(lines, fmt.Sprintf("if [ -f %[1]s ]; then sed -i '/-Xrunjdwp/d' %[1]s; fi", service.ConfigPath))
+		if enable {
+			lines = append(lines, fmt.Sprintf(
+				`echo 'JAVA_OPTS=" $JAVA_OPTS -Xdebug -Xrunjdwp:transport=dt_socket,address=*:%d,server=y,suspend=n" ' >> %s`,
+				debugPort(service.PortName).Port, service.ConfigPath))
+		}
+		services = append(services, service.Service)
+	}
+	lines = append(lines, "systemctl restart "+strings.Join(services, " "))
+	return strings.Join(lines, "\n")
+}
+
+// setJavaDebug sets the JVM flags in the container and restarts the java services.
+func setJavaDebug(cnx *shared.Connection, enable bool) error {
+	log.Info().Msg(L("Setting the JVM flags and restarting the java services"))
+	script := javaDebugScript(enable)
+	if err := cmd_utils.ExecCommand(zerolog.DebugLevel, cnx, script); err != nil {
+		return utils.Errorf(err, L("failed to set the java debugging flags: %s"))
+	}
+	if enable {
+		log.Info().Msg(L("Java remote debugging enabled"))
+	} else {
+		log.Info().Msg(L("Java remote debugging disabled"))
+	}
+	return nil
+}
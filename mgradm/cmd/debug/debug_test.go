@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package debug
+
+import (
+	"strings"
+	"testing"
+)
+
+const testUnit = `ExecStart=/usr/bin/podman run \
+	--name uyuni-server \
+	-p 443:443 \
+	-p 80:80 \
+	-p 69:69/udp \
+	-v etc-rhn:/etc/rhn \
+	${UYUNI_IMAGE}`
+
+const testDebugUnit = `ExecStart=/usr/bin/podman run \
+	--name uyuni-server \
+	-p 443:443 \
+	-p 80:80 \
+	-p 69:69/udp \
+	-p 8003:8003 \
+	-p 8001:8001 \
+	-p 8002:8002 \
+	-v etc-rhn:/etc/rhn \
+	${UYUNI_IMAGE}`
+
+func TestSetDebugPorts(t *testing.T) {
+	data := []struct {
+		unit     string
+		enable   bool
+		expected string
+	}{
+		{testUnit, true, testDebugUnit},
+		{testDebugUnit, true, testDebugUnit},
+		{testDebugUnit, false, testUnit},
+		{testUnit, false, testUnit},
+	}
+	for i, test := range data {
+		actual, err := setDebugPorts(test.unit, test.enable)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %s", i, err)
+		} else if actual != test.expected {
+			t.Errorf("case %d: expected:\n%s\ngot:\n%s", i, test.expected, actual)
+		}
+	}
+
+	if _, err := setDebugPorts("ExecStart=/usr/bin/podman run", true); err == nil {
+		t.Error("expected an error without any published port")
+	}
+}
+
+func TestJavaDebugScript(t *testing.T) {
+	enabled := javaDebugScript(true)
+	for _, expected := range []string{
+		"sed -i '/-Xrunjdwp/d' /etc/rhn/taskomatic.conf",
+		"address=*:8003,server=y,suspend=n\" ' >> /etc/tomcat/conf.d/remote_debug.conf",
+		"address=*:8001,server=y,suspend=n\" ' >> /etc/rhn/taskomatic.conf",
+		"systemctl restart tomcat taskomatic rhn-search",
+	} {
+		if !strings.Contains(enabled, expected) {
+			t.Errorf("missing %q in script:\n%s", expected, enabled)
+		}
+	}
+
+	disabled := javaDebugScript(false)
+	if strings.Contains(disabled, "JAVA_OPTS") {
+		t.Errorf("no JVM flag should be set when disabling:\n%s", disabled)
+	}
+	if !strings.Contains(disabled, "systemctl restart tomcat taskomatic rhn-search") {
+		t.Errorf("the java services need to be restarted:\n%s", disabled)
+	}
+}
@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !nok8s
+
+package debug
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	adm_kubernetes "github.com/uyuni-project/uyuni-tools/mgradm/shared/kubernetes"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+)
+
+func debugForKubernetes(flags *debugFlags, enable bool) error {
+	clusterInfos, err := kubernetes.CheckCluster()
+	if err != nil {
+		return err
+	}
+
+	cnx := shared.NewConnection("kubectl", podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
+	namespace, err := cnx.GetNamespace()
+	if err != nil {
+		return err
+	}
+
+	if clusterInfos.IsK3s() {
+		adm_kubernetes.InstallK3sTraefikConfig(enable)
+	}
+
+	// Only the service exposing the ports changes, the pod is not restarted.
+	log.Info().Msg(L("Updating the exposed ports"))
+	if err := adm_kubernetes.UpdateValues(&flags.Helm, clusterInfos.GetKubeconfig(), namespace,
+		fmt.Sprintf("exposeJavaDebug=%t", enable)); err != nil {
+		return err
+	}
+
+	return setJavaDebug(cnx, enable)
+}
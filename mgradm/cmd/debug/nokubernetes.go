@@ -0,0 +1,11 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build nok8s
+
+package debug
+
+func debugForKubernetes(flags *debugFlags, enable bool) error {
+	return nil
+}
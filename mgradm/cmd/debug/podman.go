@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package debug
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func debugForPodman(flags *debugFlags, enable bool) error {
+	servicePath := podman.GetServicePath(podman.ServerService)
+	content, err := os.ReadFile(servicePath)
+	if err != nil {
+		return utils.Errorf(err, L("failed to read file %s: %s"), servicePath)
+	}
+	newContent, err := setDebugPorts(string(content), enable)
+	if err != nil {
+		return err
+	}
+
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, "")
+
+	// Changing the exposed ports requires to recreate the container.
+	// The JVM flags are set after to avoid losing those stored outside of the volumes.
+	if newContent != string(content) {
+		log.Info().Msg(L("Updating the exposed ports and restarting the server"))
+		if err := os.WriteFile(servicePath, []byte(newContent), 0555); err != nil {
+			return utils.Errorf(err, L("cannot write %s file: %s"), servicePath)
+		}
+		if err := podman.ReloadDaemon(false); err != nil {
+			return err
+		}
+		if err := podman.RestartService(podman.ServerService); err != nil {
+			return err
+		}
+		if err := cnx.WaitForServer(); err != nil {
+			return err
+		}
+	}
+
+	return setJavaDebug(cnx, enable)
+}
+
+// setDebugPorts adds or removes the debug ports of the server systemd service.
+//
+// The debug ports are added after the last published port.
+func setDebugPorts(unit string, enable bool) (string, error) {
+	debugLines := []string{}
+	for _, port := range utils.DEBUG_PORTS {
+		debugLines = append(debugLines, fmt.Sprintf("-p %d:%d \\", port.Exposed, port.Port))
+	}
+
+	lines := []string{}
+	lastPort := -1
+	for _, line := range strings.Split(unit, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if utils.Contains(debugLines, trimmed) {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "-p ") {
+			lastPort = len(lines)
+		}
+		lines = append(lines, line)
+	}
+
+	if !enable {
+		return strings.Join(lines, "\n"), nil
+	}
+	if lastPort < 0 {
+		return "", errors.New(L("no published port found in the server systemd service"))
+	}
+
+	indent := lines[lastPort][:strings.Index(lines[lastPort], "-p ")]
+	result := append([]string{}, lines[:lastPort+1]...)
+	for _, line := range debugLines {
+		result = append(result, indent+line)
+	}
+	result = append(result, lines[lastPort+1:]...)
+	return strings.Join(result, "\n"), nil
+}
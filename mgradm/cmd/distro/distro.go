@@ -19,6 +19,7 @@ import (
 
 type flagpole struct {
 	Backend           string
+	Namespace         string
 	ChannelLabel      string `mapstructure:"channel"`
 	ProductMap        map[string]map[string]map[types.Arch]types.Distribution
 	ConnectionDetails api.ConnectionDetails `mapstructure:"api"`
@@ -86,6 +87,7 @@ Note: API details are required for auto registration.`),
 		},
 	}
 	cpCmd.Flags().String("channel", "", L("Set parent channel for the distribution."))
+	utils.AddNamespaceFlag(cpCmd)
 
 	cpCmdHelp := &cobra.Command{
 		Use:   "productmap",
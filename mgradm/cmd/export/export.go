@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/spec"
+	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type exportFlags struct {
+	Backend   string
+	Namespace string
+	Output    string
+}
+
+// NewCommand writes the deployment file of the installed server.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: L("Export the deployment of the server as a declarative file"),
+		Long: L(`Export the deployment of the server as a declarative file
+
+The deployment file is computed from the state recorded at installation time and the server
+configuration. It can be edited and used with mgradm apply. Passwords are not recorded and
+have to be added to the file if needed.
+`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags exportFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, export)
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", L("Path to the deployment file to write. Defaults to the standard output"))
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(cmd)
+		utils.AddNamespaceFlag(cmd)
+	}
+	return cmd
+}
+
+func export(globalFlags *types.GlobalFlags, flags *exportFlags, cmd *cobra.Command, args []string) error {
+	utils.SetMachineChanged(false)
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
+	command, err := cnx.GetCommand()
+	if err != nil {
+		return err
+	}
+
+	var state *types.DeploymentState
+	backend := command
+	if command == "kubectl" {
+		backend = "kubernetes"
+		namespace, err := cnx.GetNamespace()
+		if err != nil {
+			return err
+		}
+		state, err = kubernetes.ReadState(namespace)
+		if err != nil {
+			return err
+		}
+	} else {
+		if state, err = podman.ReadState(); err != nil {
+			return err
+		}
+	}
+	if state == nil {
+		return errors.New(L("no deployment state recorded for the server, it may have been installed by an older version"))
+	}
+
+	fqdn, err := adm_utils.GetRhnConfValue(cnx, "java.hostname")
+	if err != nil {
+		return utils.Errorf(err, L("failed to read the server FQDN: %s"))
+	}
+
+	deployment := spec.FromState(state, backend, fqdn)
+	if monitoring, err := adm_utils.GetRhnConfValue(cnx, "prometheus_monitoring_enabled"); err != nil {
+		log.Warn().Err(err).Msg(L("Failed to read the monitoring configuration"))
+	} else {
+		enabled := monitoring == "1" || strings.EqualFold(monitoring, "true")
+		deployment.Monitoring = &enabled
+	}
+
+	return deployment.Write(flags.Output)
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	install_shared "github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/shared"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/templates"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	cloudInitFormat  = "cloud-init"
+	combustionFormat = "combustion"
+)
+
+type cloudInitFlags struct {
+	Format     string
+	Fqdn       string
+	Repository string
+	Packages   []string `mapstructure:"package"`
+	ConfigPath string   `mapstructure:"config-path"`
+	Output     string
+}
+
+func newCloudInitCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cloud-init config-file",
+		Short: L("Generate a snippet installing the server on the first boot of a new host"),
+		Long: L(`Generate a snippet installing the server on the first boot of a new host
+
+The snippet installs the mgradm package, writes the mgradm configuration file
+and runs mgradm install podman with it. The cloud-init format generates a user data
+file while the combustion one generates the script used by the image-based
+SUSE distributions like SLE Micro or Leap Micro.
+
+The configuration file is embedded in the snippet: protect it as it may contain passwords.
+`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags cloudInitFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, generateCloudInit)
+		},
+	}
+	cmd.Flags().String("format", cloudInitFormat, L("Format of the snippet. Possible values: cloud-init, combustion"))
+	cmd.Flags().String("fqdn", "", L("Fully qualified domain name of the server. Defaults to the host name of the new host"))
+	cmd.Flags().String("repository", "", L("URL of a repository providing the mgradm package to add on the new host"))
+	cmd.Flags().StringSlice("package", []string{"podman", "mgradm"}, L("Packages to install on the new host. Can be repeated"))
+	cmd.Flags().String("config-path", "/etc/uyuni/mgradm.yaml", L("Path of the mgradm configuration file on the new host"))
+	cmd.Flags().StringP("output", "o", "", L("Path to the snippet file to write. Defaults to the standard output"))
+	return cmd
+}
+
+func generateCloudInit(globalFlags *types.GlobalFlags, flags *cloudInitFlags, cmd *cobra.Command, args []string) error {
+	utils.SetMachineChanged(false)
+	content, err := render(flags, args[0])
+	if err != nil {
+		return err
+	}
+	log.Warn().Msg(L("The generated snippet contains the configuration file, including its passwords"))
+
+	if flags.Output == "" || flags.Output == "-" {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+	if err := os.WriteFile(flags.Output, content, 0600); err != nil {
+		return utils.Errorf(err, L("cannot write %[1]s file: %[2]s"), flags.Output)
+	}
+	return nil
+}
+
+// render generates the snippet for the mgradm configuration file.
+func render(flags *cloudInitFlags, configFile string) ([]byte, error) {
+	if flags.Fqdn != "" && !install_shared.IsFqdn(flags.Fqdn) {
+		return nil, utils.UsageError(fmt.Errorf(L("%s is not a valid fully qualified domain name"), flags.Fqdn))
+	}
+	if len(flags.Packages) == 0 {
+		return nil, utils.UsageError(errors.New(L("at least one package to install is required")))
+	}
+
+	config, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to read %[1]s: %[2]s"), configFile)
+	}
+	// Catch the configuration errors now rather than on the new host
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(config, &values); err != nil {
+		return nil, utils.Errorf(err, L("failed to parse %[1]s: %[2]s"), configFile)
+	}
+
+	installArgs := []string{"/usr/bin/mgradm", "install", "podman", "--config", flags.ConfigPath}
+	if flags.Fqdn != "" {
+		installArgs = append(installArgs, flags.Fqdn)
+	}
+	data := templates.ProvisioningTemplateData{
+		Config:      base64.StdEncoding.EncodeToString(config),
+		ConfigPath:  flags.ConfigPath,
+		Repository:  flags.Repository,
+		Packages:    flags.Packages,
+		InstallArgs: installArgs,
+	}
+
+	var template utils.Template
+	switch flags.Format {
+	case cloudInitFormat:
+		template = templates.CloudInitTemplateData{ProvisioningTemplateData: data}
+	case combustionFormat:
+		template = templates.CombustionTemplateData{ProvisioningTemplateData: data}
+	default:
+		return nil, utils.UsageError(fmt.Errorf(L("unsupported snippet format: %s"), flags.Format))
+	}
+
+	var buf bytes.Buffer
+	if err := template.Render(&buf); err != nil {
+		return nil, utils.Errorf(err, L("failed to generate the snippet: %s"))
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generate
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+const testConfig = "scc:\n  user: admin\n"
+
+func writeConfig(t *testing.T, content string) string {
+	configFile := filepath.Join(t.TempDir(), "mgradm.yaml")
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write the configuration file: %s", err)
+	}
+	return configFile
+}
+
+func testFlags(format string) *cloudInitFlags {
+	return &cloudInitFlags{
+		Format:     format,
+		Fqdn:       "uyuni.example.com",
+		Packages:   []string{"podman", "mgradm"},
+		ConfigPath: "/etc/uyuni/mgradm.yaml",
+	}
+}
+
+func TestRenderCloudInit(t *testing.T) {
+	content, err := render(testFlags(cloudInitFormat), writeConfig(t, testConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.ValidateCloudConfig(t, content)
+	if encoded := base64.StdEncoding.EncodeToString([]byte(testConfig)); !strings.Contains(string(content), encoded) {
+		t.Errorf("the configuration file is not embedded in:\n%s", content)
+	}
+	expected := `["/usr/bin/mgradm", "install", "podman", "--config", "/etc/uyuni/mgradm.yaml", "uyuni.example.com"]`
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("missing install command in:\n%s", content)
+	}
+}
+
+func TestRenderCombustion(t *testing.T) {
+	content, err := render(testFlags(combustionFormat), writeConfig(t, testConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.ValidateShellScript(t, content)
+	if !strings.HasPrefix(string(content), "#!/bin/bash\n# combustion: network\n") {
+		t.Errorf("unexpected combustion script header in:\n%s", content)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	validConfig := writeConfig(t, testConfig)
+
+	flags := testFlags("ignition")
+	if _, err := render(flags, validConfig); utils.ExitCode(err) != utils.ExitUsage {
+		t.Errorf("expected a usage error for an unsupported format, got %v", err)
+	}
+
+	flags = testFlags(cloudInitFormat)
+	flags.Fqdn = "not a fqdn"
+	if _, err := render(flags, validConfig); utils.ExitCode(err) != utils.ExitUsage {
+		t.Errorf("expected a usage error for an invalid FQDN, got %v", err)
+	}
+
+	if _, err := render(testFlags(cloudInitFormat), writeConfig(t, "scc: [invalid")); err == nil {
+		t.Error("expected an error for an invalid configuration file")
+	}
+
+	if _, err := render(testFlags(cloudInitFormat), filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing configuration file")
+	}
+}
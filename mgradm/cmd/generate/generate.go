@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generate
+
+import (
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// NewCommand generates files helping to deploy servers.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: L("Generate files helping to deploy servers"),
+		Long:  L("Generate files helping to deploy servers"),
+	}
+	generateCmd.AddCommand(newCloudInitCommand(globalFlags))
+	return generateCmd
+}
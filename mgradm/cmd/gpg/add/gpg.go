@@ -5,7 +5,6 @@
 package gpgadd
 
 import (
-	"fmt"
 	"net/url"
 	"os"
 	"path"
@@ -27,8 +26,9 @@ import (
 const customKeyringPath = "/var/spacewalk/gpg/customer-build-keys.gpg"
 
 type gpgAddFlags struct {
-	Backend string
-	Force   bool
+	Backend   string
+	Namespace string
+	Force     bool
 }
 
 // NewCommand import gpg keys from 3rd party repository.
@@ -45,17 +45,18 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 
 	gpgAddKeyCmd.Flags().BoolP("force", "f", false, L("Import without asking confirmation"))
 	utils.AddBackendFlag(gpgAddKeyCmd)
+	utils.AddNamespaceFlag(gpgAddKeyCmd)
 	return gpgAddKeyCmd
 }
 
 func gpgAddKeys(globalFlags *types.GlobalFlags, flags *gpgAddFlags, cmd *cobra.Command, args []string) error {
-	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter)
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
 	if !utils.FileExists(customKeyringPath) {
 		if err := adm_utils.ExecCommand(zerolog.InfoLevel, cnx, "mkdir", "-m", "700", "-p", filepath.Dir(customKeyringPath)); err != nil {
-			return fmt.Errorf(L("failed to create folder %s: %s"), filepath.Dir(customKeyringPath), err)
+			return utils.Errorf(err, L("failed to create folder %s: %s"), filepath.Dir(customKeyringPath))
 		}
 		if err := adm_utils.ExecCommand(zerolog.InfoLevel, cnx, "gpg", "--no-default-keyring", "--keyring", customKeyringPath, "--fingerprint"); err != nil {
-			return fmt.Errorf(L("failed to create keyring %s: %s"), customKeyringPath, err)
+			return utils.Errorf(err, L("failed to create keyring %s: %s"), customKeyringPath)
 		}
 	}
 	gpgAddCmd := []string{"gpg", "--no-default-keyring", "--import", "--import-options", "import-minimal"}
@@ -65,7 +66,7 @@ func gpgAddKeys(globalFlags *types.GlobalFlags, flags *gpgAddFlags, cmd *cobra.C
 	scriptDir, err := os.MkdirTemp("", "mgradm-*")
 	defer os.RemoveAll(scriptDir)
 	if err != nil {
-		return fmt.Errorf(L("failed to create temporary directory %s"), err)
+		return utils.Errorf(err, L("failed to create temporary directory %s"))
 	}
 
 	for _, keyURL := range args {
@@ -112,14 +113,14 @@ func gpgAddKeys(globalFlags *types.GlobalFlags, flags *gpgAddFlags, cmd *cobra.C
 
 	log.Info().Msgf(L("Running: %s"), strings.Join(gpgAddCmd, " "))
 	if err := adm_utils.ExecCommand(zerolog.InfoLevel, cnx, gpgAddCmd...); err != nil {
-		return fmt.Errorf(L("failed to run import key: %s"), err)
+		return utils.Errorf(err, L("failed to run import key: %s"))
 	}
 
 	//this is for running import-suma-build-keys, who import customer-build-keys.gpg
 	uyuniUpdateCmd := []string{"systemctl", "restart", "uyuni-update-config"}
 	log.Info().Msgf(L("Running: %s"), strings.Join(uyuniUpdateCmd, " "))
 	if err := adm_utils.ExecCommand(zerolog.InfoLevel, cnx, uyuniUpdateCmd...); err != nil {
-		return fmt.Errorf(L("failed to restart uyuni-update-config: %s"), err)
+		return utils.Errorf(err, L("failed to restart uyuni-update-config: %s"))
 	}
 	return err
 }
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type historyFlags struct {
+	utils.OutputFlags `mapstructure:",squash"`
+	Limit             int
+	Failed            bool
+}
+
+// NewCommand shows the audit journal of the commands changing the deployment.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: L("Show the commands which changed the deployment"),
+		Long: fmt.Sprintf(L(`Show the commands which changed the deployment
+
+The commands changing the server or proxy deployments are recorded with the user running them,
+the flags set with their secrets redacted and their result in the %s journal.`), utils.AuditJournalPath),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags historyFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, history)
+		},
+	}
+	cmd.Flags().Int("limit", 20, L("Number of the most recent commands to show, 0 to show all of them"))
+	cmd.Flags().Bool("failed", false, L("Only show the failed commands"))
+	utils.AddOutputFlag(cmd)
+	return cmd
+}
+
+func history(globalFlags *types.GlobalFlags, flags *historyFlags, cmd *cobra.Command, args []string) error {
+	records, err := utils.ReadAuditJournal()
+	if err != nil {
+		return err
+	}
+	records = filterRecords(records, flags.Failed, flags.Limit)
+
+	table := utils.Table{Headers: []string{L("TIME"), L("USER"), L("COMMAND"), L("RESULT")}, Data: records}
+	for _, record := range records {
+		command := record.Command
+		names := make([]string, 0, len(record.Flags))
+		for name := range record.Flags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			command += " --" + name + "=" + record.Flags[name]
+		}
+		result := record.Result
+		if record.Error != "" {
+			result += ": " + record.Error
+		}
+		table.Rows = append(table.Rows, []string{
+			record.Time.Local().Format(time.DateTime), record.User, strings.TrimSpace(command), result,
+		})
+	}
+	return table.Print(flags.Output)
+}
+
+// filterRecords keeps the last limit records, only the failed ones if requested.
+func filterRecords(records []utils.AuditRecord, failed bool, limit int) []utils.AuditRecord {
+	filtered := []utils.AuditRecord{}
+	for _, record := range records {
+		if !failed || record.Result != "success" {
+			filtered = append(filtered, record)
+		}
+	}
+	if limit > 0 && len(filtered) > limit {
+		filtered = filtered[len(filtered)-limit:]
+	}
+	return filtered
+}
@@ -21,6 +21,7 @@ import (
 
 type configFlags struct {
 	Backend           string
+	Namespace         string
 	ConnectionDetails api.ConnectionDetails `mapstructure:"api"`
 }
 
@@ -41,6 +42,7 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 
 	if utils.KubernetesBuilt {
 		utils.AddBackendFlag(registerCmd)
+		utils.AddNamespaceFlag(registerCmd)
 	}
 
 	if err := api.AddAPIFlags(registerCmd, false); err != nil {
@@ -51,7 +53,7 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 }
 
 func register(globalFlags *types.GlobalFlags, flags *configFlags, cmd *cobra.Command, args []string) error {
-	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter)
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
 	config, err := getRhnConfig(cnx)
 	if err != nil {
 		return err
@@ -96,7 +98,7 @@ func registerToHub(config map[string]string, cnxDetails *api.ConnectionDetails)
 	log.Info().Msgf(L("Hub API server: %s"), cnxDetails.Server)
 	client, err := api.Init(cnxDetails)
 	if err != nil {
-		return fmt.Errorf(L("failed to connect to the Hub server: %s"), err)
+		return utils.Errorf(err, L("failed to connect to the Hub server: %s"))
 	}
 	data := map[string]interface{}{
 		"fqdn": config["java.hostname"],
@@ -104,7 +106,7 @@ func registerToHub(config map[string]string, cnxDetails *api.ConnectionDetails)
 
 	ret, err := api.Post[int](client, "system/registerPeripheralServer", data)
 	if err != nil {
-		return fmt.Errorf(L("failed to register this peripheral server: %s"), err)
+		return utils.Errorf(err, L("failed to register this peripheral server: %s"))
 	}
 	if !ret.Success {
 		return fmt.Errorf(L("failed to register this peripheral server: %s"), ret.Message)
@@ -121,7 +123,7 @@ func registerToHub(config map[string]string, cnxDetails *api.ConnectionDetails)
 	}
 	ret, err = api.Post[int](client, "system/updatePeripheralServerInfo", data)
 	if err != nil {
-		return fmt.Errorf(L("failed to update peripheral server info: %s"), err)
+		return utils.Errorf(err, L("failed to update peripheral server info: %s"))
 	}
 
 	if !ret.Success {
@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package images
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/component"
+	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type pullFlags struct {
+	Image          types.ImageFlags `mapstructure:",squash"`
+	MigrationImage types.ImageFlags `mapstructure:"migration"`
+	Coco           struct {
+		Image types.ImageFlags `mapstructure:",squash"`
+	}
+	HubXmlrpc struct {
+		Image types.ImageFlags `mapstructure:",squash"`
+	}
+}
+
+func newPullCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pull",
+		Short: L("Pull the container images"),
+		Long: L(`Pull all the container images needed to install or upgrade the server in parallel.
+
+Pulling the images before the actual install or upgrade shortens the maintenance window.
+The images of the enabled confidential computing attestation, hub XML-RPC API and components
+are pulled too.
+The same registry credentials as the install or upgrade are used.
+This command is only relevant on podman: kubernetes nodes pull the images themselves.`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags pullFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, pull)
+		},
+	}
+
+	cmd_utils.AddImageFlag(cmd)
+
+	cmd.Flags().String("migration-image", "", L("Migration image. Not pulled if empty"))
+	cmd.Flags().String("migration-tag", "", L("Migration image tag, defaults to the server image tag"))
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "migration-image", Title: L("Migration Image Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "migration-image", "migration-image")
+	_ = utils.AddFlagToHelpGroupID(cmd, "migration-tag", "migration-image")
+
+	cmd_utils.AddContainerImageFlags(cmd, "coco")
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "coco-container", Title: L("Confidential Computing Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "coco-image", "coco-container")
+	_ = utils.AddFlagToHelpGroupID(cmd, "coco-tag", "coco-container")
+
+	cmd_utils.AddContainerImageFlags(cmd, "hubxmlrpc")
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "hubxmlrpc-container", Title: L("Hub XML-RPC API Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "hubxmlrpc-image", "hubxmlrpc-container")
+	_ = utils.AddFlagToHelpGroupID(cmd, "hubxmlrpc-tag", "hubxmlrpc-container")
+
+	return cmd
+}
+
+func pull(globalFlags *types.GlobalFlags, flags *pullFlags, cmd *cobra.Command, args []string) error {
+	images, err := flags.getImages(podman.HasService)
+	if err != nil {
+		return err
+	}
+
+	inspectedHostValues, err := utils.InspectHost()
+	if err != nil {
+		return utils.Errorf(err, L("cannot inspect host values: %s"))
+	}
+	pullArgs := podman.GetPullArgs(inspectedHostValues)
+
+	log.Info().Msgf(L("Pulling %d images"), len(images))
+	return podman.PullImages(images, flags.Image.PullPolicy, pullArgs...)
+}
+
+// getImages computes the URLs of all the images to pull.
+//
+// hasService tells whether a systemd service is installed to find the enabled components.
+func (flags *pullFlags) getImages(hasService func(name string) bool) ([]string, error) {
+	serverImage, err := utils.ComputeImage(flags.Image.Name, flags.Image.Tag)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to compute image URL: %s"))
+	}
+	images := []string{serverImage}
+
+	if flags.MigrationImage.Name != "" {
+		tag := flags.MigrationImage.Tag
+		if tag == "" {
+			tag = flags.Image.Tag
+		}
+		migrationImage, err := utils.ComputeImage(flags.MigrationImage.Name, tag)
+		if err != nil {
+			return nil, utils.Errorf(err, L("failed to compute image URL: %s"))
+		}
+		images = append(images, migrationImage)
+	}
+
+	manifests, err := component.List()
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the components: %s"))
+	}
+	for _, manifest := range manifests {
+		if !hasService(manifest.Service()) {
+			continue
+		}
+
+		var componentImage string
+		// Those images are computed from the flags
+		switch manifest.Name {
+		case "attestation":
+			componentImage, err = cmd_utils.ComputeContainerImage(flags.Image, flags.Coco.Image, cmd_utils.CocoImageSuffix)
+		case "hub-xmlrpc-api":
+			componentImage, err = cmd_utils.ComputeContainerImage(
+				flags.Image, flags.HubXmlrpc.Image, cmd_utils.HubXmlrpcImageSuffix,
+			)
+		default:
+			componentImage, err = manifest.ImageURL(serverImage)
+		}
+		if err != nil {
+			return nil, utils.Errorf(err, L("failed to compute image URL: %s"))
+		}
+		if !utils.Contains(images, componentImage) {
+			images = append(images, componentImage)
+		}
+	}
+
+	return images, nil
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package images
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func TestGetImages(t *testing.T) {
+	flags := pullFlags{
+		Image:          types.ImageFlags{Name: "registry/uyuni/server", Tag: "2024.10"},
+		MigrationImage: types.ImageFlags{Name: "registry/uyuni/migration"},
+	}
+	flags.HubXmlrpc.Image = types.ImageFlags{Name: "mirror/hub", Tag: "1.0"}
+
+	enabled := map[string]bool{
+		"uyuni-server-attestation":    true,
+		"uyuni-server-hub-xmlrpc-api": true,
+	}
+	images, err := flags.getImages(func(name string) bool { return enabled[name] })
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{
+		"registry/uyuni/server:2024.10",
+		"registry/uyuni/migration:2024.10",
+		"registry/uyuni/server-attestation:2024.10",
+		"mirror/hub:1.0",
+	}
+	if !reflect.DeepEqual(images, expected) {
+		t.Errorf("expected %v, got %v", expected, images)
+	}
+
+	images, err = flags.getImages(func(string) bool { return false })
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(images) != 2 {
+		t.Errorf("expected no component image if none is enabled, got %v", images)
+	}
+}
@@ -5,6 +5,10 @@
 package inspect
 
 import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/shared"
 
@@ -17,6 +21,9 @@ type inspectFlags struct {
 	Image      string
 	Tag        string
 	PullPolicy string
+	Namespace  string
+	Refresh    bool
+	Schema     bool
 }
 
 // NewCommand for extracting information from image and deployment.
@@ -24,8 +31,11 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	inspectCmd := &cobra.Command{
 		Use:   "inspect",
 		Short: L("Inspect"),
-		Long:  L("Extract information from image and deployment"),
-		Args:  cobra.MaximumNArgs(0),
+		Long: L(`Extract information from image and deployment
+
+The result of the last inspection of each image is cached until the server is installed or upgraded again.`),
+		Args:        cobra.MaximumNArgs(0),
+		Annotations: map[string]string{utils.ReadOnlyAnnotation: "true"},
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags inspectFlags
@@ -37,18 +47,61 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	inspectCmd.Flags().String("image", "", L("Image URL. Leave it empty to analyze the current deployment"))
 	inspectCmd.Flags().String("tag", "", L("Image Tag. Leave it empty to analyze the current deployment"))
 	utils.AddPullPolicyFlag(inspectCmd)
+	inspectCmd.Flags().Bool("refresh", false, L("Inspect the image again instead of using the cached result"))
+	inspectCmd.Flags().Bool("schema", false, L("Print the JSON schema of the inspection result and exit"))
 
 	if utils.KubernetesBuilt {
 		utils.AddBackendFlag(inspectCmd)
+		utils.AddNamespaceFlag(inspectCmd)
 	}
 
 	return inspectCmd
 }
 
 func inspect(globalFlags *types.GlobalFlags, flags *inspectFlags, cmd *cobra.Command, args []string) error {
-	fn, err := shared.ChoosePodmanOrKubernetes(cmd.Flags(), podmanInspect, kuberneteInspect)
+	if flags.Schema {
+		return printSchema()
+	}
+	backend, err := shared.FindBackend(cmd.Flags(), shared.ServerApp)
 	if err != nil {
 		return err
 	}
-	return fn(globalFlags, flags, cmd, args)
+
+	serverImage, err := utils.ComputeImage(flags.Image, flags.Tag)
+	if err != nil && len(serverImage) > 0 {
+		return utils.Errorf(err, L("failed to determine image: %s"))
+	}
+
+	if len(serverImage) <= 0 {
+		log.Debug().Msg("Use deployed image")
+		serverImage, err = backend.ServerImage(flags.Namespace)
+		if err != nil {
+			return utils.Errorf(err, L("failed to find the image of the currently running server container: %s"))
+		}
+	}
+
+	inspectResult, err := backend.Inspect(serverImage, flags.PullPolicy, flags.Namespace, flags.Refresh)
+	if err != nil {
+		return utils.Errorf(err, L("inspect command failed: %s"))
+	}
+
+	utils.AddMachineData("inspect", inspectResult)
+	prettyInspectOutput, err := json.MarshalIndent(inspectResult, "", "  ")
+	if err != nil {
+		return utils.Errorf(err, L("cannot print inspect result: %s"))
+	}
+
+	outputString := "\n" + string(prettyInspectOutput)
+	log.Info().Msgf(outputString)
+
+	return nil
+}
+
+func printSchema() error {
+	prettyOutput, err := json.MarshalIndent(utils.InspectResultSchema(), "", "  ")
+	if err != nil {
+		return utils.Errorf(err, L("cannot print inspect result schema: %s"))
+	}
+	fmt.Println(string(prettyOutput))
+	return nil
 }
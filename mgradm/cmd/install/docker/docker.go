@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build docker
+
+package docker
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type dockerFlags struct {
+	Args []string `mapstructure:"arg"`
+}
+
+type dockerInstallFlags struct {
+	shared.InstallFlags `mapstructure:",squash"`
+	Docker              dockerFlags
+}
+
+// NewCommand for docker installation.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	dockerCmd := &cobra.Command{
+		Use:   "docker [fqdn]",
+		Short: L("Install a new server on docker (experimental)"),
+		Long: L(`Install a new server on docker (experimental)
+
+The install docker command assumes docker is installed and running locally.
+The server container is managed by the same uyuni-server systemd service as with podman.
+
+NOTE: the docker backend is experimental: the third party SSL certificates and the
+confidential computing attestation are not supported yet.
+`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags dockerInstallFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags,
+				utils.WithLock(utils.WithHooks("", utils.PostInstallHook, "docker", installForDocker)))
+		},
+	}
+
+	shared.AddInstallFlags(dockerCmd)
+	dockerCmd.Flags().StringSlice("docker-arg", []string{}, L("Extra arguments to pass to docker"))
+
+	return dockerCmd
+}
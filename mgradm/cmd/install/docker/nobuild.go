@@ -0,0 +1,17 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !docker
+
+package docker
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// NewCommand returns nil as the docker backend is not built.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	return nil
+}
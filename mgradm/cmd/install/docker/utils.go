@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build docker
+
+package docker
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	install_shared "github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/shared"
+	adm_docker "github.com/uyuni-project/uyuni-tools/mgradm/shared/docker"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/docker"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func installForDocker(
+	globalFlags *types.GlobalFlags,
+	flags *dockerInstallFlags,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	flags.CheckParameters(cmd, "docker")
+	if flags.Ssl.UseExisting() {
+		return errors.New(L("third party SSL certificates are not supported with docker yet"))
+	}
+	if flags.Coco.Replicas > 0 {
+		return errors.New(L("confidential computing attestation is not supported with docker yet"))
+	}
+	if flags.Saline.Replicas > 0 {
+		return errors.New(L("saline is not supported with docker yet"))
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		return errors.New(L("install docker before running this command"))
+	}
+
+	fqdn, err := getFqdn(args)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Setting up the server with the FQDN '%s'"), fqdn)
+
+	image, err := utils.ComputeImage(flags.Image.Name, flags.Image.Tag)
+	if err != nil {
+		return utils.Errorf(err, L("failed to compute image URL: %s"))
+	}
+
+	if err := docker.PrepareImage(image, flags.Image.PullPolicy); err != nil {
+		return err
+	}
+
+	dockerArgs := append(flags.Docker.Args, flags.ContainerArgs()...)
+	if flags.MirrorPath != "" {
+		dockerArgs = append(dockerArgs, "-v", flags.MirrorPath+":/mirror")
+	}
+	if err := adm_docker.GenerateSystemdService(flags.TZ, image, flags.Debug.Java, dockerArgs); err != nil {
+		return err
+	}
+
+	log.Info().Msg(L("Waiting for the server to start..."))
+	if err := podman.EnableService(podman.ServerService); err != nil {
+		return utils.Errorf(err, L("cannot enable service: %s"))
+	}
+
+	cnx := shared.NewConnection("docker", docker.ServerContainerName, "", "")
+	if err := cnx.WaitForServer(); err != nil {
+		return utils.Errorf(err, L("cannot wait for system start: %s"))
+	}
+
+	env := map[string]string{
+		"CERT_O":       flags.Ssl.Org,
+		"CERT_OU":      flags.Ssl.OU,
+		"CERT_CITY":    flags.Ssl.City,
+		"CERT_STATE":   flags.Ssl.State,
+		"CERT_COUNTRY": flags.Ssl.Country,
+		"CERT_EMAIL":   flags.Ssl.Email,
+		"CERT_CNAMES":  strings.Join(append([]string{fqdn}, flags.Ssl.Cnames...), ","),
+		"CERT_PASS":    flags.Ssl.Password,
+	}
+
+	log.Info().Msg(L("Run setup command in the container"))
+
+	if err := install_shared.RunSetup(cnx, &flags.InstallFlags, fqdn, env); err != nil {
+		if stopErr := podman.StopService(podman.ServerService); stopErr != nil {
+			log.Error().Msgf(L("Failed to stop service: %v"), stopErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func getFqdn(args []string) (string, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	fqdn, err := utils.RunCmdOutput(zerolog.DebugLevel, "hostname", "-f")
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to compute server FQDN: %s"))
+	}
+	return strings.TrimSpace(string(fqdn)), nil
+}
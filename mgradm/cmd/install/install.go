@@ -6,8 +6,10 @@ package install
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/docker"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/kubernetes"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/podman"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/wizard"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 )
@@ -26,5 +28,15 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 		installCmd.AddCommand(kubernetesCmd)
 	}
 
+	if dockerCmd := docker.NewCommand(globalFlags); dockerCmd != nil {
+		installCmd.AddCommand(dockerCmd)
+	}
+
+	backends := []string{}
+	for _, backendCmd := range installCmd.Commands() {
+		backends = append(backends, backendCmd.Name())
+	}
+	installCmd.AddCommand(wizard.NewCommand(globalFlags, backends))
+
 	return installCmd
 }
@@ -16,8 +16,12 @@ import (
 )
 
 type kubernetesInstallFlags struct {
-	shared.InstallFlags `mapstructure:",squash"`
-	Helm                cmd_utils.HelmFlags
+	shared.InstallFlags       `mapstructure:",squash"`
+	Helm                      cmd_utils.HelmFlags
+	cmd_utils.KubernetesFlags `mapstructure:",squash"`
+	Render                    struct {
+		Only string
+	}
 }
 
 // NewCommand for kubernetes installation.
@@ -44,6 +48,9 @@ NOTE: installing on a remote cluster is not supported yet!
 
 	shared.AddInstallFlags(kubernetesCmd)
 	cmd_utils.AddHelmInstallFlag(kubernetesCmd)
+	cmd_utils.AddKubernetesFlags(kubernetesCmd)
+	kubernetesCmd.Flags().String("render-only", "",
+		L("Write the helm values and the additional manifests to this directory instead of deploying them"))
 
 	return kubernetesCmd
 }
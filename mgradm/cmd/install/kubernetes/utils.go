@@ -7,8 +7,10 @@
 package kubernetes
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
+	"strings"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -21,6 +23,7 @@ import (
 	shared_kubernetes "github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 func installForKubernetes(globalFlags *types.GlobalFlags,
@@ -28,44 +31,69 @@ func installForKubernetes(globalFlags *types.GlobalFlags,
 	cmd *cobra.Command,
 	args []string,
 ) error {
-	for _, binary := range []string{"kubectl", "helm"} {
-		if _, err := exec.LookPath(binary); err != nil {
-			return fmt.Errorf(L("install %s before running this command"), binary)
-		}
+	if flags.Render.Only != "" {
+		return renderForKubernetes(flags, args[0])
 	}
+	// Nothing is installed when only rendering: the hooks are not run in such a case
+	return utils.WithLock(shared_kubernetes.WithLease(uyuniNamespace,
+		utils.WithHooks("", utils.PostInstallHook, "kubernetes", deployForKubernetes)))(globalFlags, flags, cmd, args)
+}
 
-	flags.CheckParameters(cmd, "kubectl")
-	cnx := shared.NewConnection("kubectl", "", shared_kubernetes.ServerFilter)
-
-	fqdn := args[0]
+// uyuniNamespace returns the namespace of the server deployment to lock.
+func uyuniNamespace(flags *kubernetesInstallFlags) string {
+	return flags.Helm.Uyuni.Namespace
+}
 
-	helmArgs := []string{"--set", "timezone=" + flags.TZ}
-	if flags.MirrorPath != "" {
-		// TODO Handle claims for multi-node clusters
-		helmArgs = append(helmArgs, "--set", "mirror.hostPath="+flags.MirrorPath)
-	}
-	if flags.Debug.Java {
-		helmArgs = append(helmArgs, "--set", "exposeJavaDebug=true")
+func deployForKubernetes(globalFlags *types.GlobalFlags,
+	flags *kubernetesInstallFlags,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return fmt.Errorf(L("install %s before running this command"), "kubectl")
 	}
 
+	progress := utils.NewProgress("install", 5)
+	progress.Step(L("Checking the cluster"))
 	// Check the kubernetes cluster setup
 	clusterInfos, err := shared_kubernetes.CheckCluster()
 	if err != nil {
 		return err
 	}
 
+	if shared_kubernetes.HasHelmRelease("uyuni", clusterInfos.GetKubeconfig()) {
+		if flags.No.Converge {
+			return errors.New(L("a server is already deployed, uninstall it or run the command without --no-converge"))
+		}
+		log.Info().Msg(L("A server is already deployed, applying the changes to it"))
+		return kubernetes.Upgrade(globalFlags, &flags.Image, &types.ImageFlags{}, false, adm_utils.DefaultPgsqlUpgradeFlags,
+			flags.Helm, &flags.KubernetesFlags, false, cmd, args)
+	}
+
+	flags.CheckParameters(cmd, "kubectl")
+	cnx := shared.NewConnection("kubectl", "", shared_kubernetes.ServerFilter, flags.Helm.Uyuni.Namespace)
+
+	fqdn := args[0]
+
+	helmArgs, err := installHelmArgs(flags)
+	if err != nil {
+		return err
+	}
+
+	progress.Step(L("Deploying the certificates"))
 	// Deploy the SSL CA or server certificate
 	ca := ssl.SslPair{}
 	sslArgs, err := kubernetes.DeployCertificate(&flags.Helm, &flags.Ssl, "", &ca, clusterInfos.GetKubeconfig(), fqdn,
 		flags.Image.PullPolicy)
 	if err != nil {
-		return fmt.Errorf(L("cannot deploy certificate: %s"), err)
+		return utils.Errorf(err, L("cannot deploy certificate: %s"))
 	}
 	helmArgs = append(helmArgs, sslArgs...)
 
+	progress.Step(L("Starting the server"))
 	// Deploy Uyuni and wait for it to be up
-	if err := kubernetes.Deploy(cnx, &flags.Image, &flags.Helm, &flags.Ssl, clusterInfos, fqdn, flags.Debug.Java, helmArgs...); err != nil {
-		return fmt.Errorf(L("cannot deploy uyuni: %s"), err)
+	if err := kubernetes.Deploy(cnx, &flags.Image, &flags.Helm, &flags.Ssl, &flags.Expose, clusterInfos, fqdn, flags.Debug.Java, helmArgs...); err != nil {
+		return utils.Errorf(err, L("cannot deploy uyuni: %s"))
 	}
 
 	// Create setup script + env variables and copy it to the container
@@ -73,18 +101,82 @@ func installForKubernetes(globalFlags *types.GlobalFlags,
 		"NO_SSL": "Y",
 	}
 
+	progress.Step(L("Running the server setup"))
 	if err := install_shared.RunSetup(cnx, &flags.InstallFlags, args[0], envs); err != nil {
-		if stopErr := shared_kubernetes.Stop(shared_kubernetes.ServerFilter); stopErr != nil {
+		if stopErr := shared_kubernetes.Stop(flags.Helm.Uyuni.Namespace, shared_kubernetes.ServerFilter); stopErr != nil {
 			log.Error().Msgf(L("Failed to stop service: %v"), stopErr)
 		}
 		return err
 	}
 
+	progress.Step(L("Storing the certificate authority"))
 	// The CA needs to be added to the database for Kickstart use.
 	err = adm_utils.ExecCommand(zerolog.DebugLevel, cnx,
 		"/usr/bin/rhn-ssl-dbstore", "--ca-cert=/etc/pki/trust/anchors/LOCAL-RHN-ORG-TRUSTED-SSL-CERT")
 	if err != nil {
-		return fmt.Errorf(L("error storing the SSL CA certificate in database: %s"), err)
+		return utils.Errorf(err, L("error storing the SSL CA certificate in database: %s"))
 	}
+
+	if serverImage, err := utils.ComputeImage(flags.Image.Name, flags.Image.Tag); err == nil {
+		kubernetes.SaveInstallState(flags.Helm.Uyuni.Namespace, serverImage, flags.TZ,
+			utils.GetUserFlags(utils.CommandConfig(cmd), cmd))
+	}
+	progress.Done(L("Server installed"))
 	return nil
 }
+
+// installHelmArgs computes the helm arguments from the install flags.
+func installHelmArgs(flags *kubernetesInstallFlags) ([]string, error) {
+	helmArgs := []string{"--set", "timezone=" + flags.TZ}
+	if flags.MirrorPath != "" {
+		// TODO Handle claims for multi-node clusters
+		helmArgs = append(helmArgs, "--set", "mirror.hostPath="+flags.MirrorPath)
+	}
+	if flags.Debug.Java {
+		helmArgs = append(helmArgs, "--set", "exposeJavaDebug=true")
+	}
+	if len(flags.Devices) > 0 {
+		helmArgs = append(helmArgs, "--set", "devices={"+strings.Join(flags.Devices, ",")+"}")
+	}
+	if len(flags.Capabilities) > 0 {
+		helmArgs = append(helmArgs, "--set", "capabilities={"+strings.Join(flags.Capabilities, ",")+"}")
+	}
+	if flags.Fips {
+		helmArgs = append(helmArgs, "--set", "fips=true")
+	}
+	if flags.Saline.Replicas > 0 {
+		salineImage, err := flags.SalineImage()
+		if err != nil {
+			return nil, utils.Errorf(err, L("failed to compute image URL, %s"))
+		}
+		helmArgs = append(helmArgs,
+			"--set", "saline.enabled=true",
+			"--set", "images.saline="+salineImage,
+			"--set", fmt.Sprintf("saline.port=%d", flags.Saline.Port),
+		)
+	}
+	kubernetesArgs, err := flags.KubernetesFlags.HelmArgs(kubernetes.ServerPorts(flags.Debug.Java))
+	if err != nil {
+		return nil, err
+	}
+	return append(helmArgs, kubernetesArgs...), nil
+}
+
+// renderForKubernetes writes the deployment files instead of installing the server.
+func renderForKubernetes(flags *kubernetesInstallFlags, fqdn string) error {
+	flags.Ssl.CheckParameters()
+	helmArgs, err := installHelmArgs(flags)
+	if err != nil {
+		return err
+	}
+
+	// The cluster may not be reachable when rendering: the ingress has to be set in the values in such a case
+	ingress := ""
+	if clusterInfos, err := shared_kubernetes.CheckCluster(); err != nil {
+		log.Warn().Err(err).Msg(L("Cannot guess the cluster ingress, set the ingress value in the rendered values"))
+	} else {
+		ingress = clusterInfos.Ingress
+	}
+
+	return kubernetes.Render(flags.Render.Only, &flags.Image, &flags.Helm, &flags.Ssl, fqdn, ingress, helmArgs...)
+}
@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	install_shared "github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/shared"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	shared_podman "github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// convergedFlags are the install flags which can be applied to an already deployed server.
+var convergedFlags = []string{
+	"tz", "mirrorPath", "debug-java", "image", "tag", "pullPolicy",
+	"coco-replicas", "coco-image", "coco-tag", "podman-arg", "podman-env", "podman-hardening",
+	"saline-replicas", "saline-image", "saline-tag", "saline-port",
+	"device", "capability", "podman-memory", "podman-cpus",
+	"coco-memory", "coco-cpus", "saline-memory", "saline-cpus",
+}
+
+// convergeForPodman applies the install flags to an already deployed server.
+func convergeForPodman(flags *podmanInstallFlags, cmd *cobra.Command, args []string) error {
+	log.Info().Msg(L("A server is already deployed, applying the changes to it"))
+
+	state, err := shared_podman.ReadState()
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Failed to read the deployment state"))
+	}
+
+	current := utils.GetUserFlags(utils.CommandConfig(cmd), cmd)
+	previous := map[string]string{}
+	if state != nil && state.Flags != nil {
+		previous = state.Flags
+	}
+	if unconverged := install_shared.UnconvergedFlags(previous, current, convergedFlags); len(unconverged) > 0 {
+		log.Warn().Msgf(L("The following flags cannot be changed on a deployed server and will be ignored: %s"),
+			strings.Join(unconverged, ", "))
+	}
+
+	if err := shared_podman.CheckLimits(flags.Podman.Memory, flags.Podman.Cpus); err != nil {
+		return utils.UsageError(err)
+	}
+
+	image, err := utils.ComputeImage(flags.Image.Name, flags.Image.Tag)
+	if err != nil {
+		return utils.Errorf(err, L("failed to compute image URL: %s"))
+	}
+	inspectedHostValues, err := utils.InspectHost()
+	if err != nil {
+		return utils.Errorf(err, L("cannot inspect host values: %s"))
+	}
+	preparedImage, err := shared_podman.PrepareImage(image, flags.Image.PullPolicy, shared_podman.GetPullArgs(inspectedHostValues)...)
+	if err != nil {
+		return err
+	}
+
+	if state == nil || state.Image != preparedImage || state.Digest != shared_podman.GetImageDigest(preparedImage) {
+		log.Info().Msgf(L("Upgrading the server to image %s"), preparedImage)
+		if err := podman.Upgrade(flags.Image, types.ImageFlags{}, false, cmd_utils.DefaultPgsqlUpgradeFlags, args); err != nil {
+			return utils.Errorf(err, L("cannot upgrade the server: %s"))
+		}
+		// Upgrade may have changed the state
+		if state, err = shared_podman.ReadState(); err != nil {
+			log.Warn().Err(err).Msg(L("Failed to read the deployment state"))
+		}
+	}
+
+	tz := flags.TZ
+	if !cmd.Flags().Changed("tz") && state != nil && state.Timezone != "" {
+		tz = state.Timezone
+	}
+	if tz == "" {
+		tz = utils.GetLocalTimezone()
+	}
+
+	podmanArgs := serverPodmanArgs(flags)
+
+	before := readServerServiceFiles()
+	if err := shared_podman.UpdateExtraArgsConf(shared_podman.ServerService, &flags.Podman, cmd); err != nil {
+		return err
+	}
+	err = shared_podman.UpdateHardeningConf(shared_podman.ServerHardenedServices, &flags.Podman, cmd, flags.Capabilities...)
+	if err != nil {
+		return err
+	}
+	if err := podman.GenerateSystemdService(tz, preparedImage, flags.Debug.Java, podmanArgs, true); err != nil {
+		return err
+	}
+	if before != readServerServiceFiles() {
+		log.Info().Msg(L("Restarting the server to apply the changes..."))
+		if err := shared_podman.RestartService(shared_podman.ServerService); err != nil {
+			return utils.Errorf(err, L("cannot restart service: %s"))
+		}
+	} else {
+		log.Debug().Msg("Server service unchanged, not restarting it")
+	}
+
+	if err := setupCocoContainer(flags, tz, true); err != nil {
+		return err
+	}
+
+	if err := setupSalineContainer(flags, tz, true); err != nil {
+		return err
+	}
+
+	state = utils.UpdateDeploymentState(state, "podman", preparedImage, shared_podman.GetImageDigest(preparedImage))
+	state.Timezone = tz
+	state.Flags = install_shared.MergeConvergedFlags(previous, current, convergedFlags)
+	if err := shared_podman.WriteState(state); err != nil {
+		log.Warn().Err(err).Msg(L("Failed to save the deployment state"))
+	}
+	return nil
+}
+
+// readServerServiceFiles returns the content of the server systemd service and of its configuration files.
+func readServerServiceFiles() string {
+	servicePath := shared_podman.GetServicePath(shared_podman.ServerService)
+	files, _ := filepath.Glob(servicePath + ".d/*.conf")
+	content := ""
+	for _, file := range append([]string{servicePath}, files...) {
+		data, _ := os.ReadFile(file)
+		content += string(data)
+	}
+	return content
+}
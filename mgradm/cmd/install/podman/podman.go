@@ -32,7 +32,8 @@ NOTE: installing on a remote podman is not supported yet!
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags podmanInstallFlags
-			return utils.CommandHelper(globalFlags, cmd, args, &flags, installForPodman)
+			return utils.CommandHelper(globalFlags, cmd, args, &flags,
+				utils.WithLock(utils.WithHooks("", utils.PostInstallHook, "podman", installForPodman)))
 		},
 	}
 
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// flagsFromState reads the install flags recorded in a deployment state.
+//
+// The secrets are redacted in the state and are left to their default values.
+func flagsFromState(state *types.DeploymentState) (*podmanInstallFlags, error) {
+	cmd := NewCommand(&types.GlobalFlags{})
+	for name, value := range state.Flags {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || strings.Contains(value, utils.RedactedValue) {
+			log.Debug().Msgf("Ignoring the %s flag of the deployment state", name)
+			continue
+		}
+		// The list values are recorded as [a,b]
+		if strings.HasSuffix(flag.Value.Type(), "Slice") {
+			value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return nil, utils.Errorf(err, L("invalid value of the %[1]s flag in the deployment state: %[2]s"), name)
+		}
+	}
+
+	v, err := utils.ReadConfig("", cmd)
+	if err != nil {
+		return nil, err
+	}
+	var flags podmanInstallFlags
+	if err := v.Unmarshal(&flags); err != nil {
+		return nil, utils.Errorf(err, L("failed to unmarshall configuration")+": %s")
+	}
+	return &flags, nil
+}
+
+// RenderServerServiceFromState returns the server systemd service this version of the tool would generate
+// with the flags recorded in the deployment state.
+func RenderServerServiceFromState(state *types.DeploymentState) ([]byte, error) {
+	flags, err := flagsFromState(state)
+	if err != nil {
+		return nil, err
+	}
+	tz := flags.TZ
+	if state.Timezone != "" {
+		tz = state.Timezone
+	}
+	return podman.RenderServerService(tz, flags.Debug.Java, serverPodmanArgs(flags))
+}
@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func TestFlagsFromState(t *testing.T) {
+	state := types.DeploymentState{
+		Flags: map[string]string{
+			"tz":             "Europe/Berlin",
+			"podman-arg":     "[--label=a,--label=b]",
+			"admin-password": utils.RedactedValue,
+			"unknown":        "ignored",
+		},
+	}
+	flags, err := flagsFromState(&state)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if flags.TZ != "Europe/Berlin" {
+		t.Errorf("expected the Europe/Berlin timezone, got %s", flags.TZ)
+	}
+	if actual := strings.Join(flags.Podman.Args, " "); actual != "--label=a --label=b" {
+		t.Errorf("unexpected podman arguments: %s", actual)
+	}
+	if flags.Admin.Password != "" {
+		t.Errorf("the redacted password should not be set")
+	}
+}
@@ -6,7 +6,6 @@ package podman
 
 import (
 	"errors"
-	"fmt"
 	"os/exec"
 	"strings"
 
@@ -14,7 +13,9 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	install_shared "github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/shared"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/component"
 	"github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
 	"github.com/uyuni-project/uyuni-tools/shared"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	shared_podman "github.com/uyuni-project/uyuni-tools/shared/podman"
@@ -22,64 +23,145 @@ import (
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
-func setupCocoContainer(flags *podmanInstallFlags) error {
+// setupCocoContainer enables the confidential computing attestation component if needed.
+//
+// The existing service unit is replaced only if overwrite is true.
+func setupCocoContainer(flags *podmanInstallFlags, tz string, overwrite bool) error {
 	if flags.Coco.Replicas > 0 {
 		if flags.Coco.Replicas > 1 {
 			log.Warn().Msgf(L("Currently only one replica is supported, starting just one instead of %d"), flags.Coco.Replicas)
 		}
 
-		tag := flags.Coco.Image.Tag
-		if tag == "" {
-			tag = flags.Image.Tag
+		cocoImage, err := cmd_utils.ComputeContainerImage(flags.Image, flags.Coco.Image, cmd_utils.CocoImageSuffix)
+		if err != nil {
+			return utils.Errorf(err, L("failed to compute image URL, %s"))
+		}
+
+		attestation, err := component.Find("attestation")
+		if err != nil {
+			return err
+		}
+		attestation.Args = append(attestation.Args, shared_podman.LimitsArgs(flags.Coco.Memory, flags.Coco.Cpus)...)
+
+		db := component.Database{
+			Host:     flags.Db.Host,
+			Port:     flags.Db.Port,
+			Name:     flags.Db.Name,
+			User:     flags.Db.User,
+			Password: flags.Db.Password,
+		}
+		if !flags.Db.IsExternal("") {
+			db.Host = component.BundledDbHost
+		}
+		if err := component.Enable(attestation, cocoImage, tz, &db, overwrite); err != nil {
+			return utils.Errorf(err, L("cannot enable the attestation: %s"))
+		}
+	}
+	return nil
+}
+
+func setupSalineContainer(flags *podmanInstallFlags, tz string, overwrite bool) error {
+	if flags.Saline.Replicas > 0 {
+		if flags.Saline.Replicas > 1 {
+			log.Warn().Msgf(L("Currently only one replica is supported, starting just one instead of %d"), flags.Saline.Replicas)
 		}
-		cocoImage, err := utils.ComputeImage(flags.Coco.Image.Name, tag)
+
+		salineImage, err := flags.SalineImage()
 		if err != nil {
-			return fmt.Errorf(L("failed to compute image URL, %s"), err)
+			return utils.Errorf(err, L("failed to compute image URL, %s"))
 		}
 
-		if err := podman.GenerateAttestationSystemdService(cocoImage, flags.Db); err != nil {
-			return fmt.Errorf(L("cannot generate systemd service: %s"), err)
+		saline, err := component.Find("saline")
+		if err != nil {
+			return err
 		}
+		saline.Ports[0].Exposed = flags.Saline.Port
+		saline.Args = append(saline.Args, shared_podman.LimitsArgs(flags.Saline.Memory, flags.Saline.Cpus)...)
 
-		if err := shared_podman.EnableService(shared_podman.ServerAttestationService); err != nil {
-			return fmt.Errorf(L("cannot enable service: %s"), err)
+		if err := component.Enable(saline, salineImage, tz, nil, overwrite); err != nil {
+			return utils.Errorf(err, L("cannot enable saline: %s"))
 		}
 	}
 	return nil
 }
 
-func waitForSystemStart(cnx *shared.Connection, image string, flags *podmanInstallFlags) error {
-	podmanArgs := flags.Podman.Args
+// serverPodmanArgs computes the podman arguments of the server container.
+//
+// The user podman arguments are not part of them as they are stored in a separate systemd configuration file.
+func serverPodmanArgs(flags *podmanInstallFlags) []string {
+	podmanArgs := append(flags.ContainerArgs(), shared_podman.LimitsArgs(flags.Podman.Memory, flags.Podman.Cpus)...)
 	if flags.MirrorPath != "" {
 		podmanArgs = append(podmanArgs, "-v", flags.MirrorPath+":/mirror")
 	}
+	// Saline needs to access the salt master event bus
+	if flags.Saline.Replicas > 0 || shared_podman.HasService(shared_podman.ServerSalineService) {
+		volume := utils.RunSaltMasterVolumeMount
+		podmanArgs = append(podmanArgs, "-v", volume.Name+":"+volume.MountPath)
+	}
+	// The server needs the cloud instance metadata to check the billing of pay-as-you-go instances
+	if utils.IsPaygHost() {
+		podmanArgs = append(podmanArgs, podman.PaygArgs()...)
+	}
+	return podmanArgs
+}
 
-	if err := podman.GenerateSystemdService(flags.TZ, image, flags.Debug.Java, podmanArgs); err != nil {
+func waitForSystemStart(cnx *shared.Connection, image string, flags *podmanInstallFlags) error {
+	podmanArgs := serverPodmanArgs(flags)
+
+	if err := podman.GenerateSystemdService(flags.TZ, image, flags.Debug.Java, podmanArgs, false); err != nil {
 		return err
 	}
 
 	log.Info().Msg(L("Waiting for the server to start..."))
 	if err := shared_podman.EnableService(shared_podman.ServerService); err != nil {
-		return fmt.Errorf(L("cannot enable service: %s"), err)
+		return utils.Errorf(err, L("cannot enable service: %s"))
 	}
 
 	return cnx.WaitForServer()
 }
 
+func setupBillingAdapter(flags *podmanInstallFlags) error {
+	image, err := flags.BillingAdapterImage()
+	if err != nil {
+		return utils.Errorf(err, L("failed to compute image URL, %s"))
+	}
+	if err := podman.SetupBillingAdapter(image); err != nil {
+		return utils.Errorf(err, L("cannot set up the billing adapter: %s"))
+	}
+	return nil
+}
+
 func installForPodman(
 	globalFlags *types.GlobalFlags,
 	flags *podmanInstallFlags,
 	cmd *cobra.Command,
 	args []string,
 ) error {
-	flags.CheckParameters(cmd, "podman")
 	if _, err := exec.LookPath("podman"); err != nil {
 		return errors.New(L("install podman before running this command"))
 	}
 
+	if shared_podman.HasService(shared_podman.ServerService) {
+		if flags.No.Converge {
+			return errors.New(L("a server is already deployed, uninstall it or run the command without --no-converge"))
+		}
+		return convergeForPodman(flags, cmd, args)
+	}
+	flags.CheckParameters(cmd, "podman")
+	if err := shared_podman.CheckLimits(flags.Podman.Memory, flags.Podman.Cpus); err != nil {
+		return utils.UsageError(err)
+	}
+
+	progress := utils.NewProgress("install", 5)
+	progress.Step(L("Inspecting the host"))
 	inspectedHostValues, err := utils.InspectHost()
 	if err != nil {
-		return fmt.Errorf(L("cannot inspect host values: %s"), err)
+		return utils.Errorf(err, L("cannot inspect host values: %s"))
+	}
+
+	payg := utils.IsPayg(inspectedHostValues)
+	if payg {
+		log.Info().Msg(L("Pay-as-you-go cloud instance detected: the billing adapter will be deployed"))
 	}
 
 	fqdn, err := getFqdn(args)
@@ -90,14 +172,11 @@ func installForPodman(
 
 	image, err := utils.ComputeImage(flags.Image.Name, flags.Image.Tag)
 	if err != nil {
-		return fmt.Errorf(L("failed to compute image URL: %s"), err)
-	}
-	pullArgs := []string{}
-	_, scc_user_exist := inspectedHostValues["host_scc_username"]
-	_, scc_user_password := inspectedHostValues["host_scc_password"]
-	if scc_user_exist && scc_user_password {
-		pullArgs = append(pullArgs, "--creds", inspectedHostValues["host_scc_username"]+":"+inspectedHostValues["host_scc_password"])
+		return utils.Errorf(err, L("failed to compute image URL: %s"))
 	}
+	pullArgs := shared_podman.GetPullArgs(inspectedHostValues)
+
+	progress.Step(L("Preparing the server image"))
 
 	preparedImage, err := shared_podman.PrepareImage(image, flags.Image.PullPolicy, pullArgs...)
 	if err != nil {
@@ -108,9 +187,25 @@ func installForPodman(
 		return err
 	}
 
-	cnx := shared.NewConnection("podman", shared_podman.ServerContainerName, "")
+	if flags.ReportDb.Container {
+		flags.ReportDb.Host = podman.ReportDbHost
+		if err := podman.SetupReportDb(preparedImage, flags.ReportDb); err != nil {
+			return err
+		}
+	}
+
+	if err := shared_podman.UpdateExtraArgsConf(shared_podman.ServerService, &flags.Podman, cmd); err != nil {
+		return err
+	}
+	err = shared_podman.UpdateHardeningConf(shared_podman.ServerHardenedServices, &flags.Podman, cmd, flags.Capabilities...)
+	if err != nil {
+		return err
+	}
+
+	progress.Step(L("Starting the server"))
+	cnx := shared.NewConnection("podman", shared_podman.ServerContainerName, "", "")
 	if err := waitForSystemStart(cnx, preparedImage, flags); err != nil {
-		return fmt.Errorf(L("cannot wait for system start: %s"), err)
+		return utils.Errorf(err, L("cannot wait for system start: %s"))
 	}
 
 	caPassword := flags.Ssl.Password
@@ -130,6 +225,7 @@ func installForPodman(
 		"CERT_PASS":    caPassword,
 	}
 
+	progress.Step(L("Running the server setup"))
 	log.Info().Msg(L("Run setup command in the container"))
 
 	if err := install_shared.RunSetup(cnx, &flags.InstallFlags, fqdn, env); err != nil {
@@ -139,19 +235,33 @@ func installForPodman(
 		return err
 	}
 
-	if err := setupCocoContainer(flags); err != nil {
+	progress.Step(L("Setting up the additional services"))
+	if err := setupCocoContainer(flags, flags.TZ, false); err != nil {
 		return err
 	}
 
+	if err := setupSalineContainer(flags, flags.TZ, false); err != nil {
+		return err
+	}
+
+	if payg {
+		if err := setupBillingAdapter(flags); err != nil {
+			return err
+		}
+	}
+
 	if flags.Ssl.UseExisting() {
 		if err := podman.UpdateSslCertificate(cnx, &flags.Ssl.Ca, &flags.Ssl.Server); err != nil {
-			return fmt.Errorf(L("cannot update SSL certificate: %s"), err)
+			return utils.Errorf(err, L("cannot update SSL certificate: %s"))
 		}
 	}
 
 	if err := shared_podman.EnablePodmanSocket(); err != nil {
-		return fmt.Errorf(L("cannot enable podman socket: %s"), err)
+		return utils.Errorf(err, L("cannot enable podman socket: %s"))
 	}
+
+	podman.SaveInstallState(preparedImage, flags.TZ, utils.GetUserFlags(utils.CommandConfig(cmd), cmd))
+	progress.Done(L("Server installed"))
 	return nil
 }
 
@@ -161,7 +271,7 @@ func getFqdn(args []string) (string, error) {
 	} else {
 		fqdn_b, err := utils.RunCmdOutput(zerolog.DebugLevel, "hostname", "-f")
 		if err != nil {
-			return "", fmt.Errorf(L("failed to compute server FQDN: %s"), err)
+			return "", utils.Errorf(err, L("failed to compute server FQDN: %s"))
 		}
 		return strings.TrimSpace(string(fqdn_b)), nil
 	}
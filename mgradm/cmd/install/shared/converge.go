@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"sort"
+
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// notDeploymentFlags are the flags changing how the command runs rather than the deployment.
+var notDeploymentFlags = []string{"config", "logLevel", "logfile", "wait", "no-converge", "generate-password", "password-output"}
+
+// UnconvergedFlags returns the sorted names of the flags changed since the installation which cannot be
+// applied to the deployed server.
+//
+// previous and current are the flags set by the user, like returned by utils.GetUserFlags.
+func UnconvergedFlags(previous map[string]string, current map[string]string, converged []string) []string {
+	names := map[string]bool{}
+	for name := range previous {
+		names[name] = true
+	}
+	for name := range current {
+		names[name] = true
+	}
+
+	unconverged := []string{}
+	for name := range names {
+		if utils.Contains(converged, name) || utils.Contains(notDeploymentFlags, name) {
+			continue
+		}
+		previousValue, wasSet := previous[name]
+		currentValue, isSet := current[name]
+		if wasSet != isSet || previousValue != currentValue {
+			unconverged = append(unconverged, name)
+		}
+	}
+	sort.Strings(unconverged)
+	return unconverged
+}
+
+// MergeConvergedFlags returns the flags to record in the deployment state after applying the converged flags.
+func MergeConvergedFlags(previous map[string]string, current map[string]string, converged []string) map[string]string {
+	merged := map[string]string{}
+	for name, value := range previous {
+		if !utils.Contains(converged, name) {
+			merged[name] = value
+		}
+	}
+	for _, name := range converged {
+		if value, isSet := current[name]; isSet {
+			merged[name] = value
+		}
+	}
+	return merged
+}
@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnconvergedFlags(t *testing.T) {
+	previous := map[string]string{"tz": "Europe/Berlin", "db-name": "susemanager", "ssl-city": "Nuernberg"}
+	current := map[string]string{"tz": "UTC", "db-name": "uyuni", "logLevel": "debug", "email": "admin@foo.com"}
+
+	actual := UnconvergedFlags(previous, current, []string{"tz"})
+	expected := []string{"db-name", "email", "ssl-city"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v got %v", expected, actual)
+	}
+
+	if actual := UnconvergedFlags(previous, previous, nil); len(actual) != 0 {
+		t.Errorf("expected no flag for unchanged values, got %v", actual)
+	}
+}
+
+func TestMergeConvergedFlags(t *testing.T) {
+	previous := map[string]string{"tz": "Europe/Berlin", "db-name": "susemanager", "tag": "2024.05"}
+	current := map[string]string{"tz": "UTC", "db-name": "uyuni"}
+
+	actual := MergeConvergedFlags(previous, current, []string{"tz", "tag"})
+	expected := map[string]string{"tz": "UTC", "db-name": "susemanager"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v got %v", expected, actual)
+	}
+}
@@ -7,15 +7,20 @@ package shared
 import (
 	"fmt"
 	"net/mail"
+	"os"
 	"regexp"
 	"strings"
 
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/ssl"
 	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
 	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"gopkg.in/yaml.v2"
 )
 
 // DbFlags can store all values required to connect to a database.
@@ -27,18 +32,52 @@ type DbFlags struct {
 	Password string
 	Protocol string
 	Provider string
+	External bool
 	Admin    struct {
 		User     string
 		Password string
 	}
 }
 
+// IsExternal returns whether the database is not the one bundled in the server container.
+func (db DbFlags) IsExternal(fqdn string) bool {
+	return db.External || !cmd_utils.IsLocalDb(db.Host, fqdn)
+}
+
+// ReportDbFlags can store the values of the reporting database.
+type ReportDbFlags struct {
+	DbFlags   `mapstructure:",squash"`
+	Container bool
+	Memory    string
+	Cpus      string
+}
+
 // SccFlags can store SCC Credentials.
 type SccFlags struct {
 	User     string
 	Password string
 }
 
+// useStoredCredentials reads the credentials from the credentials store if none is passed,
+// or stores the passed ones for the next image pulls and upgrades.
+func (flags *SccFlags) useStoredCredentials() {
+	if flags.User == "" && flags.Password == "" {
+		if stored := utils.GetCredentials(utils.SccCredentialsKey); stored != nil {
+			log.Info().Msg(L("Using the stored SUSE Customer Center credentials"))
+			flags.User = stored.User
+			flags.Password = stored.Password
+		}
+		return
+	}
+	if flags.User == "" || flags.Password == "" {
+		return
+	}
+	credentials := utils.Credentials{User: flags.User, Password: flags.Password}
+	if err := utils.StoreCredentials(utils.SccCredentialsKey, credentials); err != nil {
+		log.Warn().Err(err).Msg(L("Failed to store the SUSE Customer Center credentials"))
+	}
+}
+
 // DebugFlags contains information about enabled/disabled debug.
 type DebugFlags struct {
 	Java bool
@@ -48,6 +87,67 @@ type DebugFlags struct {
 type CocoFlags struct {
 	Replicas int
 	Image    types.ImageFlags `mapstructure:",squash"`
+	Memory   string
+	Cpus     string
+}
+
+// SalineFlags contains settings for the saline salt event processor container.
+type SalineFlags struct {
+	Replicas int
+	Port     int
+	Image    types.ImageFlags `mapstructure:",squash"`
+	Memory   string
+	Cpus     string
+}
+
+// PaygFlags contains settings for the pay-as-you-go public cloud instances.
+type PaygFlags struct {
+	Image types.ImageFlags `mapstructure:",squash"`
+}
+
+// PasswordFlags configures the complexity required for the passwords and the output of the generated ones.
+type PasswordFlags struct {
+	Length     int
+	Classes    int
+	Denylist   string
+	DenyCommon bool `mapstructure:"deny-common"`
+	Output     string
+}
+
+// adminPasswordMaxLength is the maximum length of the administrator password accepted by the server.
+const adminPasswordMaxLength = 48
+
+// The default password policy only enforces the administrator password length required by the server.
+const (
+	defaultPasswordLength  = 5
+	defaultPasswordClasses = 1
+)
+
+// isStrict returns whether a stricter policy than the default one is requested.
+//
+// The passed passwords are only checked against a strict policy to keep the existing installations working.
+func (flags *PasswordFlags) isStrict() bool {
+	return flags.Length > defaultPasswordLength || flags.Classes > defaultPasswordClasses ||
+		flags.Denylist != "" || flags.DenyCommon
+}
+
+// generatedPasswordLength is the length of the generated administrator password.
+const generatedPasswordLength = 24
+
+// Policy computes the password policy from the flags.
+func (flags *PasswordFlags) Policy() (*utils.PasswordPolicy, error) {
+	policy := utils.PasswordPolicy{MinLength: flags.Length, MinClasses: flags.Classes, DenyCommon: flags.DenyCommon}
+	if flags.Classes < 1 || flags.Classes > 4 {
+		return nil, fmt.Errorf(L("invalid --password-classes value %d: expected a number between 1 and 4"), flags.Classes)
+	}
+	if flags.Denylist != "" {
+		denied, err := utils.ReadDenyList(flags.Denylist)
+		if err != nil {
+			return nil, err
+		}
+		policy.DenyList = denied
+	}
+	return &policy, nil
 }
 
 // InstallFlags stores all the flags used by install command.
@@ -59,18 +159,86 @@ type InstallFlags struct {
 	MirrorPath   string
 	Tftp         bool
 	Db           DbFlags
-	ReportDb     DbFlags
+	ReportDb     ReportDbFlags
 	Ssl          cmd_utils.SslCertFlags
 	Scc          SccFlags
 	Debug        DebugFlags
 	Image        types.ImageFlags `mapstructure:",squash"`
 	Coco         CocoFlags
+	Saline       SalineFlags
+	Payg         PaygFlags
+	Devices      []string `mapstructure:"device"`
+	Capabilities []string `mapstructure:"capability"`
+	Fips         bool
 	Admin        apiTypes.User
 	Organization string
+	No           struct {
+		Converge bool
+	}
+	Password PasswordFlags
+	Generate struct {
+		Password bool
+	}
 }
 
-// idChecker verifies that the value is a valid identifier.
-func idChecker(value string) bool {
+// SalineImage computes the saline image URL, defaulting to the server image name with a -saline suffix.
+func (flags *InstallFlags) SalineImage() (string, error) {
+	name := flags.Saline.Image.Name
+	if name == "" {
+		name = flags.Image.Name + "-saline"
+	}
+	tag := flags.Saline.Image.Tag
+	if tag == "" {
+		tag = flags.Image.Tag
+	}
+	return utils.ComputeImage(name, tag)
+}
+
+// BillingAdapterImage computes the billing adapter image URL of the pay-as-you-go cloud instances.
+// It defaults to the server image name with a -billing-adapter suffix.
+func (flags *InstallFlags) BillingAdapterImage() (string, error) {
+	name := flags.Payg.Image.Name
+	if name == "" {
+		name = flags.Image.Name + "-billing-adapter"
+	}
+	tag := flags.Payg.Image.Tag
+	if tag == "" {
+		tag = flags.Image.Tag
+	}
+	return utils.ComputeImage(name, tag)
+}
+
+// ContainerArgs computes the container engine arguments passing the devices and capabilities to the server.
+func (flags *InstallFlags) ContainerArgs() []string {
+	args := []string{}
+	limits := []struct{ memory, cpus string }{
+		{flags.ReportDb.Memory, flags.ReportDb.Cpus},
+		{flags.Coco.Memory, flags.Coco.Cpus},
+		{flags.Saline.Memory, flags.Saline.Cpus},
+	}
+	for _, limit := range limits {
+		if err := podman.CheckLimits(limit.memory, limit.cpus); err != nil {
+			log.Fatal().Err(err).Msg(L("invalid container limits"))
+		}
+	}
+
+	for _, device := range flags.Devices {
+		args = append(args, "--device", device)
+	}
+	for _, capability := range flags.Capabilities {
+		args = append(args, "--cap-add", capability)
+	}
+	if flags.Fips {
+		args = append(args, utils.FipsContainerArgs()...)
+	}
+	return args
+}
+
+var deviceRegex = regexp.MustCompile(`^/[^\s:]+(:/[^\s:]+)?(:[rwm]+)?$`)
+var capabilityRegex = regexp.MustCompile(`^(CAP_)?[A-Z_]+$`)
+
+// IdChecker verifies that the value is a valid identifier.
+func IdChecker(value string) bool {
 	r := regexp.MustCompile(`^([[:alnum:]]|[._-])+$`)
 	if r.MatchString(value) {
 		return true
@@ -79,8 +247,8 @@ func idChecker(value string) bool {
 	return false
 }
 
-// emailChecker verifies that the value is a valid email address.
-func emailChecker(value string) bool {
+// EmailChecker verifies that the value is a valid email address.
+func EmailChecker(value string) bool {
 	address, err := mail.ParseAddress(value)
 	if err != nil || address.Name != "" || strings.ContainsAny(value, "<>") {
 		fmt.Println(L("Not a valid email address"))
@@ -89,22 +257,86 @@ func emailChecker(value string) bool {
 	return true
 }
 
+var fqdnRegex = regexp.MustCompile(`^[[:alnum:]]([[:alnum:]-]*[[:alnum:]])?(\.[[:alnum:]]([[:alnum:]-]*[[:alnum:]])?)+$`)
+
+// IsFqdn returns whether the value is a fully qualified domain name.
+func IsFqdn(value string) bool {
+	return fqdnRegex.MatchString(value)
+}
+
 // CheckParameters checks parameters for install command.
 func (flags *InstallFlags) CheckParameters(cmd *cobra.Command, command string) {
+	flags.Scc.useStoredCredentials()
+
+	policy, err := flags.Password.Policy()
+	if err != nil {
+		log.Fatal().Err(err).Msg(L("invalid password policy"))
+	}
+	if flags.Generate.Password && flags.Password.Output == "" {
+		log.Fatal().Msg(L("--generate-password requires --password-output to store the generated passwords"))
+	}
+	// The passwords of the external databases are not chosen by the user
+	if flags.Password.isStrict() && flags.Db.Password != "" && !flags.Db.IsExternal("") {
+		if err := policy.Check(flags.Db.Password); err != nil {
+			log.Fatal().Msgf(L("invalid --db-password: %s"), err)
+		}
+	}
+	if flags.Password.isStrict() && flags.ReportDb.Password != "" && !flags.ReportDb.IsExternal("") {
+		if err := policy.Check(flags.ReportDb.Password); err != nil {
+			log.Fatal().Msgf(L("invalid --reportdb-password: %s"), err)
+		}
+	}
+
+	generated := []generatedPassword{}
 	if flags.Db.Password == "" {
 		flags.Db.Password = utils.GetRandomBase64(30)
+		generated = append(generated, generatedPassword{"db-password", L("database"), flags.Db.Password})
 	}
 
 	if flags.ReportDb.Password == "" {
 		flags.ReportDb.Password = utils.GetRandomBase64(30)
+		generated = append(generated, generatedPassword{"reportdb-password", L("report database"), flags.ReportDb.Password})
+	}
+
+	if flags.Db.External && cmd_utils.IsLocalDb(flags.Db.Host, "") {
+		log.Fatal().Msg(L("--db-host is required with an external database"))
+	}
+
+	if flags.ReportDb.Container {
+		if command != "podman" {
+			log.Fatal().Msg(L("--reportdb-container is only supported with podman"))
+		}
+		// The server container already publishes the PostgreSQL port
+		if flags.ReportDb.Port == 5432 {
+			log.Fatal().Msg(L("--reportdb-port needs to be different from 5432 with --reportdb-container"))
+		}
+		if !IdChecker(flags.ReportDb.Name) || !IdChecker(flags.ReportDb.User) {
+			log.Fatal().Msg(L("invalid report database name or user"))
+		}
+	}
+
+	for _, device := range flags.Devices {
+		if !deviceRegex.MatchString(device) {
+			log.Fatal().Msgf(L("invalid device %s: expected format is /host/path[:/container/path][:permissions]"), device)
+		}
+	}
+	for i, capability := range flags.Capabilities {
+		flags.Capabilities[i] = strings.ToUpper(capability)
+		if !capabilityRegex.MatchString(flags.Capabilities[i]) {
+			log.Fatal().Msgf(L("invalid capability: %s"), capability)
+		}
 	}
 
 	// Make sure we have all the required 3rd party flags or none
 	flags.Ssl.CheckParameters()
 
+	if flags.Fips {
+		flags.checkFips(command)
+	}
+
 	// Since we use cert-manager for self-signed certificates on kubernetes we don't need password for it
-	if !flags.Ssl.UseExisting() && command == "podman" {
-		utils.AskPasswordIfMissing(&flags.Ssl.Password, cmd.Flag("ssl-password").Usage, 0, 0)
+	if !flags.Ssl.UseExisting() && command != "kubectl" {
+		utils.AskPasswordIfMissing(&flags.Ssl.Password, cmd.Flag("ssl-password").Usage, 0, 0, nil)
 	}
 
 	// Use the host timezone if the user didn't define one
@@ -112,15 +344,50 @@ func (flags *InstallFlags) CheckParameters(cmd *cobra.Command, command string) {
 		flags.TZ = utils.GetLocalTimezone()
 	}
 
-	utils.AskIfMissing(&flags.Email, cmd.Flag("email").Usage, 0, 0, emailChecker)
-	utils.AskIfMissing(&flags.EmailFrom, cmd.Flag("emailfrom").Usage, 0, 0, emailChecker)
+	utils.AskIfMissing(&flags.Email, cmd.Flag("email").Usage, 0, 0, EmailChecker)
+	utils.AskIfMissing(&flags.EmailFrom, cmd.Flag("emailfrom").Usage, 0, 0, EmailChecker)
 
-	utils.AskIfMissing(&flags.Admin.Login, cmd.Flag("admin-login").Usage, 1, 64, idChecker)
-	utils.AskPasswordIfMissing(&flags.Admin.Password, cmd.Flag("admin-password").Usage, 5, 48)
-	utils.AskIfMissing(&flags.Admin.Email, cmd.Flag("admin-email").Usage, 1, 128, emailChecker)
+	utils.AskIfMissing(&flags.Admin.Login, cmd.Flag("admin-login").Usage, 1, 64, IdChecker)
+	adminPolicy := *policy
+	adminPolicy.MaxLength = adminPasswordMaxLength
+	if flags.Password.DenyCommon {
+		adminPolicy.DenyList = append([]string{flags.Admin.Login}, policy.DenyList...)
+	}
+	if flags.Admin.Password != "" && flags.Password.isStrict() {
+		if err := adminPolicy.Check(flags.Admin.Password); err != nil {
+			log.Fatal().Msgf(L("invalid --admin-password: %s"), err)
+		}
+	} else if flags.Generate.Password {
+		flags.Admin.Password = utils.GeneratePassword(generatedPasswordLength)
+		generated = append(generated, generatedPassword{"admin-password", L("administrator"), flags.Admin.Password})
+	}
+	utils.AskPasswordIfMissing(&flags.Admin.Password, cmd.Flag("admin-password").Usage, 0, 0, adminPolicy.Checker())
+	if flags.Password.Output != "" {
+		if err := writeGeneratedPasswords(generated, flags.Password.Output); err != nil {
+			log.Fatal().Err(err).Send()
+		}
+	}
+	utils.AskIfMissing(&flags.Admin.Email, cmd.Flag("admin-email").Usage, 1, 128, EmailChecker)
 	utils.AskIfMissing(&flags.Organization, cmd.Flag("organization").Usage, 3, 128, nil)
 }
 
+// checkFips ensures the host and third party certificates are FIPS compliant and selects the FIPS-enabled images.
+func (flags *InstallFlags) checkFips(command string) {
+	// The kubernetes nodes cannot be checked from here
+	if command != "kubectl" && !utils.IsHostFips() {
+		log.Fatal().Msg(L("--fips requires the host to run in FIPS mode"))
+	}
+	if flags.Ssl.UseExisting() {
+		if err := ssl.CheckFipsCertificates(&flags.Ssl.Ca, &flags.Ssl.Server); err != nil {
+			log.Fatal().Err(err).Msg(L("third party SSL certificates cannot be used in FIPS mode"))
+		}
+	}
+	flags.Image.Tag = utils.FipsTag(flags.Image.Tag)
+	flags.Coco.Image.Tag = utils.FipsTag(flags.Coco.Image.Tag)
+	flags.Saline.Image.Tag = utils.FipsTag(flags.Saline.Image.Tag)
+	flags.Payg.Image.Tag = utils.FipsTag(flags.Payg.Image.Tag)
+}
+
 // AddInstallFlags add flags to installa command.
 func AddInstallFlags(cmd *cobra.Command) {
 	cmd.Flags().String("tz", "", L("Time zone to set on the server. Defaults to the host timezone"))
@@ -128,6 +395,8 @@ func AddInstallFlags(cmd *cobra.Command) {
 	cmd.Flags().String("emailfrom", "admin@example.com", L("E-Mail sending the notifications"))
 	cmd.Flags().String("mirrorPath", "", L("Path to mirrored packages mounted on the host"))
 	cmd.Flags().String("issParent", "", L("InterServerSync v1 parent FQDN"))
+	utils.AddLockFlag(cmd)
+	cmd.Flags().Bool("no-converge", false, L("Fail if a server is already deployed instead of applying the changes to it"))
 
 	cmd.Flags().String("db-user", "spacewalk", L("Database user"))
 	cmd.Flags().String("db-password", "", L("Database password. Randomly generated by default"))
@@ -138,6 +407,7 @@ func AddInstallFlags(cmd *cobra.Command) {
 	cmd.Flags().String("db-admin-user", "", L("External database admin user name"))
 	cmd.Flags().String("db-admin-password", "", L("External database admin password"))
 	cmd.Flags().String("db-provider", "", L("External database provider. Possible values 'aws'"))
+	cmd.Flags().Bool("db-external", false, L("Use an external database, like a managed PostgreSQL, instead of the bundled one"))
 
 	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "db", Title: L("Database Flags")})
 	_ = utils.AddFlagToHelpGroupID(cmd, "db-user", "db")
@@ -149,6 +419,7 @@ func AddInstallFlags(cmd *cobra.Command) {
 	_ = utils.AddFlagToHelpGroupID(cmd, "db-admin-user", "db")
 	_ = utils.AddFlagToHelpGroupID(cmd, "db-admin-password", "db")
 	_ = utils.AddFlagToHelpGroupID(cmd, "db-provider", "db")
+	_ = utils.AddFlagToHelpGroupID(cmd, "db-external", "db")
 
 	cmd.Flags().Bool("tftp", true, L("Enable TFTP"))
 	cmd.Flags().String("reportdb-name", "reportdb", L("Report database name"))
@@ -156,6 +427,9 @@ func AddInstallFlags(cmd *cobra.Command) {
 	cmd.Flags().Int("reportdb-port", 5432, L("Report database port"))
 	cmd.Flags().String("reportdb-user", "pythia_susemanager", L("Report Database username"))
 	cmd.Flags().String("reportdb-password", "", L("Report database password. Randomly generated by default"))
+	cmd.Flags().Bool("reportdb-container", false, L("Run the report database in its own container. Requires a --reportdb-port other than 5432"))
+	cmd.Flags().String("reportdb-memory", "", L("Memory limit of the report database container, for example 4g"))
+	cmd.Flags().String("reportdb-cpus", "", L("Number of CPUs of the report database container, for example 1.5"))
 
 	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "reportdb", Title: L("Report DB Flags")})
 	_ = utils.AddFlagToHelpGroupID(cmd, "reportdb-name", "reportdb")
@@ -163,38 +437,11 @@ func AddInstallFlags(cmd *cobra.Command) {
 	_ = utils.AddFlagToHelpGroupID(cmd, "reportdb-port", "reportdb")
 	_ = utils.AddFlagToHelpGroupID(cmd, "reportdb-user", "reportdb")
 	_ = utils.AddFlagToHelpGroupID(cmd, "reportdb-password", "reportdb")
+	_ = utils.AddFlagToHelpGroupID(cmd, "reportdb-container", "reportdb")
+	_ = utils.AddFlagToHelpGroupID(cmd, "reportdb-memory", "reportdb")
+	_ = utils.AddFlagToHelpGroupID(cmd, "reportdb-cpus", "reportdb")
 
-	// For generated CA and certificate
-	cmd.Flags().StringSlice("ssl-cname", []string{}, L("SSL certificate cnames separated by commas"))
-	cmd.Flags().String("ssl-country", "DE", L("SSL certificate country"))
-	cmd.Flags().String("ssl-state", "Bayern", L("SSL certificate state"))
-	cmd.Flags().String("ssl-city", "Nuernberg", L("SSL certificate city"))
-	cmd.Flags().String("ssl-org", "SUSE", L("SSL certificate organization"))
-	cmd.Flags().String("ssl-ou", "SUSE", L("SSL certificate organization unit"))
-	cmd.Flags().String("ssl-password", "", L("Password for the CA key to generate"))
-	cmd.Flags().String("ssl-email", "ca-admin@example.com", L("SSL certificate E-Mail"))
-
-	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "ssl", Title: L("SSL Certificate Flags")})
-	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-cname", "ssl")
-	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-country", "ssl")
-	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-state", "ssl")
-	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-city", "ssl")
-	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-org", "ssl")
-	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-ou", "ssl")
-	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-password", "ssl")
-	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-email", "ssl")
-
-	// For SSL 3rd party certificates
-	cmd.Flags().StringSlice("ssl-ca-intermediate", []string{}, L("Intermediate CA certificate path"))
-	cmd.Flags().String("ssl-ca-root", "", L("Root CA certificate path"))
-	cmd.Flags().String("ssl-server-cert", "", L("Server certificate path"))
-	cmd.Flags().String("ssl-server-key", "", L("Server key path"))
-
-	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "ssl3rd", Title: L("3rd Party SSL Certificate Flags")})
-	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-ca-intermediate", "ssl3rd")
-	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-ca-root", "ssl3rd")
-	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-server-cert", "ssl3rd")
-	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-server-key", "ssl3rd")
+	AddSslFlags(cmd)
 
 	cmd.Flags().String("scc-user", "", L("SUSE Customer Center username"))
 	cmd.Flags().String("scc-password", "", L("SUSE Customer Center password"))
@@ -208,14 +455,50 @@ func AddInstallFlags(cmd *cobra.Command) {
 
 	cmd_utils.AddContainerImageFlags(cmd, "coco")
 	cmd.Flags().Int("coco-replicas", 0, L("How many replicas of the confidential computing container should be started. (only 0 or 1 supported for now)"))
+	cmd.Flags().String("coco-memory", "", L("Memory limit of the confidential computing container, for example 1g"))
+	cmd.Flags().String("coco-cpus", "", L("Number of CPUs of the confidential computing container, for example 0.5"))
 
 	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "coco-container", Title: L("Confidential Computing Flags")})
 	_ = utils.AddFlagToHelpGroupID(cmd, "coco-replicas", "coco-container")
+	_ = utils.AddFlagToHelpGroupID(cmd, "coco-memory", "coco-container")
+	_ = utils.AddFlagToHelpGroupID(cmd, "coco-cpus", "coco-container")
 	_ = utils.AddFlagToHelpGroupID(cmd, "coco-image", "coco-container")
 	_ = utils.AddFlagToHelpGroupID(cmd, "coco-tag", "coco-container")
 
+	cmd_utils.AddContainerImageFlags(cmd, "saline")
+	cmd.Flags().Int("saline-replicas", 0, L("How many replicas of the saline salt event processor container should be started. (only 0 or 1 supported for now)"))
+	cmd.Flags().Int("saline-port", 8216, L("Port exposed by the saline container"))
+	cmd.Flags().String("saline-memory", "", L("Memory limit of the saline container, for example 1g"))
+	cmd.Flags().String("saline-cpus", "", L("Number of CPUs of the saline container, for example 0.5"))
+
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "saline-container", Title: L("Saline Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "saline-replicas", "saline-container")
+	_ = utils.AddFlagToHelpGroupID(cmd, "saline-port", "saline-container")
+	_ = utils.AddFlagToHelpGroupID(cmd, "saline-memory", "saline-container")
+	_ = utils.AddFlagToHelpGroupID(cmd, "saline-cpus", "saline-container")
+	_ = utils.AddFlagToHelpGroupID(cmd, "saline-image", "saline-container")
+	_ = utils.AddFlagToHelpGroupID(cmd, "saline-tag", "saline-container")
+
+	// The billing adapter is only deployed on pay-as-you-go public cloud instances
+	cmd_utils.AddContainerImageFlags(cmd, "payg")
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "payg", Title: L("Pay-as-you-go Cloud Instance Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "payg-image", "payg")
+	_ = utils.AddFlagToHelpGroupID(cmd, "payg-tag", "payg")
+
+	cmd.Flags().StringSlice("device", []string{},
+		L("Host device to pass to the server container, for example /dev/kvm. Can be repeated"))
+	cmd.Flags().StringSlice("capability", []string{},
+		L("Additional kernel capability to grant to the server container, for example SYS_ADMIN. Can be repeated"))
+
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "devices", Title: L("Devices Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "device", "devices")
+	_ = utils.AddFlagToHelpGroupID(cmd, "capability", "devices")
+
+	cmd.Flags().Bool("fips", false, L(`Deploy in FIPS mode: the host needs to run in FIPS mode,
+the FIPS-enabled images are used and the third party certificates need to use approved algorithms`))
+
 	cmd.Flags().String("admin-login", "admin", L("Administrator user name"))
-	cmd.Flags().String("admin-password", "", L("Administrator password"))
+	cmd.Flags().String("admin-password", "", L("Administrator password. Prefer passing it using an environment variable"))
 	cmd.Flags().String("admin-firstName", "Administrator", L("First name of the administrator"))
 	cmd.Flags().String("admin-lastName", "McAdmin", L("Last name of the administrator"))
 	cmd.Flags().String("admin-email", "", L("Administrator's email"))
@@ -228,4 +511,100 @@ func AddInstallFlags(cmd *cobra.Command) {
 	_ = utils.AddFlagToHelpGroupID(cmd, "admin-lastName", "first-user")
 	_ = utils.AddFlagToHelpGroupID(cmd, "admin-email", "first-user")
 	_ = utils.AddFlagToHelpGroupID(cmd, "organization", "first-user")
+
+	cmd.Flags().Int("password-length", defaultPasswordLength,
+		L("Minimum length of the administrator and database passwords"))
+	cmd.Flags().Int("password-classes", defaultPasswordClasses,
+		L("Minimum number of character classes of the passwords among lower case letters, upper case letters, digits and symbols"))
+	cmd.Flags().String("password-denylist", "", L("File containing forbidden passwords, one per line"))
+	cmd.Flags().Bool("password-deny-common", false,
+		L("Forbid the common weak passwords and the administrator login as password"))
+	cmd.Flags().Bool("generate-password", false,
+		L("Generate a strong administrator password if none is passed, requires --password-output"))
+	cmd.Flags().String("password-output", "",
+		L("File to write the generated passwords to, only readable by its owner"))
+
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "password", Title: L("Password Policy Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "password-length", "password")
+	_ = utils.AddFlagToHelpGroupID(cmd, "password-classes", "password")
+	_ = utils.AddFlagToHelpGroupID(cmd, "password-denylist", "password")
+	_ = utils.AddFlagToHelpGroupID(cmd, "password-deny-common", "password")
+	_ = utils.AddFlagToHelpGroupID(cmd, "generate-password", "password")
+	_ = utils.AddFlagToHelpGroupID(cmd, "password-output", "password")
+}
+
+// generatedPassword is a password generated during the installation.
+type generatedPassword struct {
+	// flag is the name of the flag to pass the password.
+	flag string
+	// account is the description of the account using the password.
+	account string
+	value   string
+}
+
+// writeGeneratedPasswords writes the generated passwords to a configuration file only readable by its owner.
+//
+// The passwords are never printed to not leave them in the terminal or the logs.
+func writeGeneratedPasswords(passwords []generatedPassword, output string) error {
+	// Use the configuration file format to allow reusing the file
+	config := map[string]map[string]string{}
+	for _, password := range passwords {
+		key, subKey, _ := strings.Cut(password.flag, "-")
+		config[key] = map[string]string{subKey: password.value}
+	}
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return utils.Errorf(err, L("failed to serialize the generated passwords: %s"))
+	}
+	file, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return utils.Errorf(err, L("failed to write the generated passwords to %[1]s: %[2]s"), output)
+	}
+	defer file.Close()
+	// An existing file keeps its permissions when opened
+	if err := file.Chmod(0600); err != nil {
+		return utils.Errorf(err, L("failed to write the generated passwords to %[1]s: %[2]s"), output)
+	}
+	if _, err := file.Write(data); err != nil {
+		return utils.Errorf(err, L("failed to write the generated passwords to %[1]s: %[2]s"), output)
+	}
+	for _, password := range passwords {
+		log.Info().Msgf(L("Generated %[1]s password written to %[2]s"), password.account, output)
+	}
+	return nil
+}
+
+// AddSslFlags adds the flags to generate the server certificate or to use third party ones.
+func AddSslFlags(cmd *cobra.Command) {
+	// For generated CA and certificate
+	cmd.Flags().StringSlice("ssl-cname", []string{}, L("SSL certificate cnames separated by commas"))
+	cmd.Flags().String("ssl-country", "DE", L("SSL certificate country"))
+	cmd.Flags().String("ssl-state", "Bayern", L("SSL certificate state"))
+	cmd.Flags().String("ssl-city", "Nuernberg", L("SSL certificate city"))
+	cmd.Flags().String("ssl-org", "SUSE", L("SSL certificate organization"))
+	cmd.Flags().String("ssl-ou", "SUSE", L("SSL certificate organization unit"))
+	cmd.Flags().String("ssl-password", "", L("Password for the CA key to generate"))
+	cmd.Flags().String("ssl-email", "ca-admin@example.com", L("SSL certificate E-Mail"))
+
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "ssl", Title: L("SSL Certificate Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-cname", "ssl")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-country", "ssl")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-state", "ssl")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-city", "ssl")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-org", "ssl")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-ou", "ssl")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-password", "ssl")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-email", "ssl")
+
+	// For SSL 3rd party certificates
+	cmd.Flags().StringSlice("ssl-ca-intermediate", []string{}, L("Intermediate CA certificate path"))
+	cmd.Flags().String("ssl-ca-root", "", L("Root CA certificate path"))
+	cmd.Flags().String("ssl-server-cert", "", L("Server certificate path"))
+	cmd.Flags().String("ssl-server-key", "", L("Server key path"))
+
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "ssl3rd", Title: L("3rd Party SSL Certificate Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-ca-intermediate", "ssl3rd")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-ca-root", "ssl3rd")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-server-cert", "ssl3rd")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-server-key", "ssl3rd")
 }
@@ -4,7 +4,12 @@
 
 package shared
 
-import "testing"
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
 
 func TestIdChecker(t *testing.T) {
 	data := map[string]bool{
@@ -21,7 +26,7 @@ func TestIdChecker(t *testing.T) {
 		"foo#":      false,
 	}
 	for value, expected := range data {
-		actual := idChecker(value)
+		actual := IdChecker(value)
 		if actual != expected {
 			t.Errorf("%s: expected %v got %v", value, expected, actual)
 		}
@@ -36,9 +41,165 @@ func TestEmailChecker(t *testing.T) {
 		"fooo":                     false,
 	}
 	for value, expected := range data {
-		actual := emailChecker(value)
+		actual := EmailChecker(value)
 		if actual != expected {
 			t.Errorf("%s: expected %v got %v", value, expected, actual)
 		}
 	}
 }
+
+func TestSalineImage(t *testing.T) {
+	var flags InstallFlags
+	flags.Image.Name = "registry.opensuse.org/uyuni/server"
+	flags.Image.Tag = "2024.07"
+
+	image, err := flags.SalineImage()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := "registry.opensuse.org/uyuni/server-saline:2024.07"; image != expected {
+		t.Errorf("expected %s, got %s", expected, image)
+	}
+
+	flags.Saline.Image.Name = "myregistry.example.com/saline"
+	flags.Saline.Image.Tag = "latest"
+	image, err = flags.SalineImage()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := "myregistry.example.com/saline:latest"; image != expected {
+		t.Errorf("expected %s, got %s", expected, image)
+	}
+}
+
+func TestBillingAdapterImage(t *testing.T) {
+	var flags InstallFlags
+	flags.Image.Name = "registry.suse.com/suse/manager/5.0/x86_64/server"
+	flags.Image.Tag = "5.0.1"
+
+	image, err := flags.BillingAdapterImage()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := "registry.suse.com/suse/manager/5.0/x86_64/server-billing-adapter:5.0.1"; image != expected {
+		t.Errorf("expected %s, got %s", expected, image)
+	}
+
+	flags.Payg.Image.Name = "myregistry.example.com/billing-adapter"
+	image, err = flags.BillingAdapterImage()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := "myregistry.example.com/billing-adapter:5.0.1"; image != expected {
+		t.Errorf("expected %s, got %s", expected, image)
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	flags := PasswordFlags{Length: 10, Classes: 2}
+	policy, err := flags.Policy()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if policy.MinLength != 10 || policy.MinClasses != 2 || len(policy.DenyList) != 0 {
+		t.Errorf("unexpected policy: %v", policy)
+	}
+
+	flags.Classes = 5
+	if _, err := flags.Policy(); err == nil {
+		t.Error("more than 4 character classes should be rejected")
+	}
+
+	flags.Classes = 3
+	flags.Denylist = path.Join(t.TempDir(), "denylist")
+	if err := os.WriteFile(flags.Denylist, []byte("Example-2024\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err = flags.Policy()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := policy.Check("Example-2024"); err == nil {
+		t.Error("the password of the deny list should be rejected")
+	}
+}
+
+func TestDefaultPasswordPolicy(t *testing.T) {
+	flags := PasswordFlags{Length: defaultPasswordLength, Classes: defaultPasswordClasses}
+	if flags.isStrict() {
+		t.Error("the default policy should not be strict")
+	}
+	policy, err := flags.Policy()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// The passwords accepted before the policy was introduced are still valid
+	for _, password := range []string{"admin", "password", "secret123"} {
+		if err := policy.Check(password); err != nil {
+			t.Errorf("%s should be accepted by the default policy: %s", password, err)
+		}
+	}
+	if err := policy.Check("adm"); err == nil {
+		t.Error("passwords shorter than 5 characters should still be rejected")
+	}
+
+	for _, strict := range []PasswordFlags{
+		{Length: 8, Classes: defaultPasswordClasses},
+		{Length: defaultPasswordLength, Classes: 3},
+		{Length: defaultPasswordLength, Classes: defaultPasswordClasses, DenyCommon: true},
+	} {
+		if !strict.isStrict() {
+			t.Errorf("%v should be a strict policy", strict)
+		}
+	}
+}
+
+func TestWriteGeneratedPasswords(t *testing.T) {
+	output := path.Join(t.TempDir(), "passwords.yaml")
+	// An existing file readable by the others has to be restricted
+	if err := os.WriteFile(output, []byte("old content\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	passwords := []generatedPassword{
+		{"db-password", "database", "dbsecret"},
+		{"admin-password", "administrator", "adminsecret"},
+	}
+	if err := writeGeneratedPasswords(passwords, output); err != nil {
+		t.Fatalf("failed to write the passwords: %s", err)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "admin:\n  password: adminsecret\ndb:\n  password: dbsecret\n"
+	if string(content) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, content)
+	}
+	if info, err := os.Stat(output); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("the passwords file should only be readable by its owner")
+	}
+}
+
+func TestContainerArgs(t *testing.T) {
+	flags := InstallFlags{
+		Devices:      []string{"/dev/kvm", "/dev/dri/renderD128:/dev/dri/renderD128:rw"},
+		Capabilities: []string{"SYS_ADMIN"},
+	}
+	expected := "--device /dev/kvm --device /dev/dri/renderD128:/dev/dri/renderD128:rw --cap-add SYS_ADMIN"
+	if actual := strings.Join(flags.ContainerArgs(), " "); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	for value, expected := range map[string]bool{
+		"/dev/kvm":                true,
+		"/dev/kvm:/dev/kvm:rwm":   true,
+		"dev/kvm":                 false,
+		"/dev/kvm:relative":       false,
+		"/dev/kvm /dev/fuse":      false,
+		"/dev/kvm:/dev/kvm:bogus": false,
+	} {
+		if actual := deviceRegex.MatchString(value); actual != expected {
+			t.Errorf("%s: expected %v got %v", value, expected, actual)
+		}
+	}
+}
@@ -5,7 +5,6 @@
 package shared
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -29,12 +28,12 @@ func RunSetup(cnx *shared.Connection, flags *InstallFlags, fqdn string, env map[
 	defer os.RemoveAll(tmpFolder)
 
 	if err := cnx.Copy(filepath.Join(tmpFolder, setup_name), "server:/tmp/setup.sh", "root", "root"); err != nil {
-		return fmt.Errorf(L("cannot copy /tmp/setup.sh: %s"), err)
+		return utils.Errorf(err, L("cannot copy /tmp/setup.sh: %s"))
 	}
 
 	err := adm_utils.ExecCommand(zerolog.InfoLevel, cnx, "/tmp/setup.sh")
 	if err != nil {
-		return fmt.Errorf(L("error running the setup script: %s"), err)
+		return utils.Errorf(err, L("error running the setup script: %s"))
 	}
 
 	// Call the org.createFirst api if flags are passed
@@ -58,14 +57,7 @@ func RunSetup(cnx *shared.Connection, flags *InstallFlags, fqdn string, env map[
 // The script exports all the needed environment variables and calls uyuni's mgr-setup.
 // Podman or kubernetes-specific variables can be passed using extraEnv parameter.
 func generateSetupScript(flags *InstallFlags, fqdn string, extraEnv map[string]string) string {
-	localHostValues := []string{
-		"localhost",
-		"127.0.0.1",
-		"::1",
-		fqdn,
-	}
-
-	localDb := utils.Contains(localHostValues, flags.Db.Host)
+	localDb := !flags.Db.IsExternal(fqdn)
 
 	dbHost := flags.Db.Host
 	reportdbHost := flags.ReportDb.Host
@@ -116,8 +108,9 @@ func generateSetupScript(flags *InstallFlags, fqdn string, extraEnv map[string]s
 	}
 
 	dataTemplate := templates.MgrSetupScriptTemplateData{
-		Env:       env,
-		DebugJava: flags.Debug.Java,
+		Env:        env,
+		DebugJava:  flags.Debug.Java,
+		ExternalDb: !localDb,
 	}
 
 	scriptPath := filepath.Join(scriptDir, setup_name)
@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wizard
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// prompter asks the wizard questions and reads the answers.
+type prompter struct {
+	reader *bufio.Reader
+	out    io.Writer
+	// askPassword reads a password without echoing it.
+	askPassword func(prompt string, min int, max int) string
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{
+		reader: bufio.NewReader(in),
+		out:    out,
+		askPassword: func(prompt string, min int, max int) string {
+			var value string
+			utils.AskPasswordIfMissing(&value, prompt, min, max, nil)
+			return value
+		},
+	}
+}
+
+// section prints the title of a group of questions.
+func (p *prompter) section(title string) {
+	fmt.Fprintf(p.out, "\n== %s ==\n", title)
+}
+
+// readLine reads the next answer without the surrounding spaces.
+func (p *prompter) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", utils.Errorf(err, L("failed to read the answer: %s"))
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// ask asks a question until the checker accepts the answer.
+//
+// An empty answer selects the default value. A nil checker accepts any answer, even an empty one.
+func (p *prompter) ask(prompt string, defaultValue string, checker func(string) bool) (string, error) {
+	for {
+		if defaultValue != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", prompt, defaultValue)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", prompt)
+		}
+		value, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if value == "" {
+			value = defaultValue
+		}
+		if checker == nil || checker(value) {
+			return value, nil
+		}
+	}
+}
+
+// askInt asks for a positive number.
+func (p *prompter) askInt(prompt string, defaultValue int) (int, error) {
+	value, err := p.ask(prompt, strconv.Itoa(defaultValue), func(value string) bool {
+		if number, err := strconv.Atoi(value); err != nil || number <= 0 {
+			fmt.Fprintln(p.out, L("A positive number is required"))
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(value)
+}
+
+// choose asks to pick one of the choices.
+func (p *prompter) choose(prompt string, choices []string, defaultValue string) (string, error) {
+	return p.ask(fmt.Sprintf("%s (%s)", prompt, strings.Join(choices, ", ")), defaultValue, func(value string) bool {
+		if !utils.Contains(choices, value) {
+			fmt.Fprintf(p.out, L("Possible values are: %s")+"\n", strings.Join(choices, ", "))
+			return false
+		}
+		return true
+	})
+}
+
+// confirm asks a yes or no question.
+func (p *prompter) confirm(prompt string, defaultValue bool) (bool, error) {
+	defaultAnswer := "n"
+	if defaultValue {
+		defaultAnswer = "y"
+	}
+	value, err := p.choose(prompt, []string{"y", "n"}, defaultAnswer)
+	return value == "y", err
+}
+
+// required rejects the empty values.
+func (p *prompter) required(value string) bool {
+	if value == "" {
+		fmt.Fprintln(p.out, L("A value is required"))
+		return false
+	}
+	return true
+}
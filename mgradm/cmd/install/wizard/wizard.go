@@ -0,0 +1,329 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wizard
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"gopkg.in/yaml.v2"
+)
+
+type wizardFlags struct {
+	Output struct {
+		File string
+	}
+}
+
+// answers are the installation parameters collected by the wizard.
+type answers struct {
+	Backend string
+	Fqdn    string
+	// Config is the content of the configuration file to write.
+	Config map[string]interface{}
+}
+
+// NewCommand for the guided installation.
+//
+// The backends are the install subcommands available in this build.
+func NewCommand(globalFlags *types.GlobalFlags, backends []string) *cobra.Command {
+	wizardCmd := &cobra.Command{
+		Use:   "wizard",
+		Short: L("Guided installation of a new server"),
+		Long: L(`Guided installation of a new server
+
+The wizard asks for the backend, FQDN, SSL certificates, database, SUSE Customer Center
+credentials and first user. The answers are written to a configuration file which can be
+reviewed and reused with the install command. The installation can then be started
+immediately or later.
+
+The configuration file contains passwords: it is only readable by its owner.
+`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags wizardFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags,
+				func(globalFlags *types.GlobalFlags, flags *wizardFlags, cmd *cobra.Command, args []string) error {
+					return runWizard(flags, backends)
+				})
+		},
+	}
+	wizardCmd.Flags().String("output-file", "mgradm.yaml", L("Path of the configuration file to write"))
+	return wizardCmd
+}
+
+func runWizard(flags *wizardFlags, backends []string) error {
+	p := newPrompter(os.Stdin, os.Stdout)
+
+	defaultFqdn := ""
+	if out, err := utils.RunCmdOutput(zerolog.DebugLevel, "hostname", "-f"); err == nil {
+		defaultFqdn = strings.TrimSpace(string(out))
+	}
+
+	result, err := ask(p, backends, defaultFqdn)
+	if err != nil {
+		return err
+	}
+
+	path := flags.Output.File
+	if utils.FileExists(path) {
+		overwrite, err := p.confirm(fmt.Sprintf(L("%s already exists, overwrite it?"), path), false)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			utils.SetMachineChanged(false)
+			return nil
+		}
+	}
+	if err := writeConfig(path, result.Config); err != nil {
+		return err
+	}
+
+	installArgs := []string{"install", result.Backend, result.Fqdn, "--config", path}
+	fmt.Fprintf(p.out, "\n"+L("Configuration written to %s. The installation command is:")+"\n\n", path)
+	fmt.Fprintf(p.out, "    mgradm %s\n\n", strings.Join(installArgs, " "))
+
+	install, err := p.confirm(L("Run the installation now?"), false)
+	if err != nil || !install {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return utils.Errorf(err, L("failed to find the mgradm executable: %s"))
+	}
+	log.Info().Msgf(L("Installing the server on %[1]s for %[2]s"), result.Backend, result.Fqdn)
+	installCmd := exec.Command(executable, installArgs...)
+	installCmd.Stdin = os.Stdin
+	installCmd.Stdout = os.Stdout
+	installCmd.Stderr = os.Stderr
+	if err := installCmd.Run(); err != nil {
+		return utils.Errorf(err, L("installation failed: %s"))
+	}
+	return nil
+}
+
+// ask runs the wizard questions.
+func ask(p *prompter, backends []string, defaultFqdn string) (*answers, error) {
+	result := answers{Config: map[string]interface{}{}}
+	var err error
+
+	p.section(L("Backend"))
+	if result.Backend, err = p.choose(L("Container backend"), backends, backends[0]); err != nil {
+		return nil, err
+	}
+
+	p.section(L("Server"))
+	if result.Fqdn, err = p.ask(L("Fully qualified domain name of the server"), defaultFqdn,
+		func(value string) bool { return fqdnChecker(p, value) }); err != nil {
+		return nil, err
+	}
+
+	steps := []func(*prompter, *answers) error{askSsl, askDb, askScc, askAdmin}
+	for _, step := range steps {
+		if err := step(p, &result); err != nil {
+			return nil, err
+		}
+	}
+	return &result, nil
+}
+
+func askSsl(p *prompter, result *answers) error {
+	p.section(L("SSL certificates"))
+	mode := "self-signed"
+	if result.Backend == "docker" {
+		fmt.Fprintln(p.out, L("Third party certificates are not supported on docker: using self-signed certificates"))
+	} else {
+		var err error
+		mode, err = p.choose(L("SSL certificates"), []string{"self-signed", "third-party"}, mode)
+		if err != nil {
+			return err
+		}
+	}
+
+	if mode == "third-party" {
+		files := map[string]string{
+			"ssl.ca.root":     L("Root CA certificate path"),
+			"ssl.server.cert": L("Server certificate path"),
+			"ssl.server.key":  L("Server key path"),
+		}
+		for _, key := range []string{"ssl.ca.root", "ssl.server.cert", "ssl.server.key"} {
+			value, err := p.ask(files[key], "", func(value string) bool { return fileChecker(p, value) })
+			if err != nil {
+				return err
+			}
+			setValue(result.Config, key, value)
+		}
+		intermediate, err := p.ask(L("Intermediate CA certificate paths separated by commas, if any"), "",
+			func(value string) bool {
+				for _, path := range splitList(value) {
+					if !fileChecker(p, path) {
+						return false
+					}
+				}
+				return true
+			})
+		if err != nil {
+			return err
+		}
+		if paths := splitList(intermediate); len(paths) > 0 {
+			setValue(result.Config, "ssl.ca.intermediate", paths)
+		}
+		return nil
+	}
+
+	// cert-manager generates the certificates on kubernetes
+	if result.Backend != "kubernetes" {
+		setValue(result.Config, "ssl.password", p.askPassword(L("Password for the CA key to generate"), 0, 0))
+	}
+	return nil
+}
+
+func askDb(p *prompter, result *answers) error {
+	p.section(L("Database"))
+	location, err := p.choose(L("Database"), []string{"internal", "external"}, "internal")
+	if err != nil || location == "internal" {
+		return err
+	}
+
+	host, err := p.ask(L("Database host"), "", p.required)
+	if err != nil {
+		return err
+	}
+	setValue(result.Config, "db.host", host)
+
+	port, err := p.askInt(L("Database port"), 5432)
+	if err != nil {
+		return err
+	}
+	setValue(result.Config, "db.port", port)
+
+	values := []struct {
+		key          string
+		prompt       string
+		defaultValue string
+	}{
+		{"db.name", L("Database name"), "susemanager"},
+		{"db.user", L("Database user"), "spacewalk"},
+		{"db.admin.user", L("External database admin user name"), ""},
+	}
+	for _, value := range values {
+		answer, err := p.ask(value.prompt, value.defaultValue, p.required)
+		if err != nil {
+			return err
+		}
+		setValue(result.Config, value.key, answer)
+	}
+	setValue(result.Config, "db.password", p.askPassword(L("Database password"), 0, 0))
+	setValue(result.Config, "db.admin.password", p.askPassword(L("External database admin password"), 0, 0))
+	return nil
+}
+
+func askScc(p *prompter, result *answers) error {
+	p.section(L("SUSE Customer Center"))
+	user, err := p.ask(L("SUSE Customer Center username, empty to skip"), "", nil)
+	if err != nil || user == "" {
+		return err
+	}
+	setValue(result.Config, "scc.user", user)
+	setValue(result.Config, "scc.password", p.askPassword(L("SUSE Customer Center password"), 0, 0))
+	return nil
+}
+
+func askAdmin(p *prompter, result *answers) error {
+	p.section(L("First user"))
+	login, err := p.ask(L("Administrator user name"), "admin", shared.IdChecker)
+	if err != nil {
+		return err
+	}
+	setValue(result.Config, "admin.login", login)
+	setValue(result.Config, "admin.password", p.askPassword(L("Administrator password"), 5, 48))
+
+	email, err := p.ask(L("Administrator's email"), "", shared.EmailChecker)
+	if err != nil {
+		return err
+	}
+	setValue(result.Config, "admin.email", email)
+	setValue(result.Config, "email", email)
+	setValue(result.Config, "emailfrom", email)
+
+	organization, err := p.ask(L("First organization name"), "Organization", func(value string) bool {
+		if len(value) < 3 || len(value) > 128 {
+			fmt.Fprintln(p.out, L("Has to be between 3 and 128 characters long"))
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	setValue(result.Config, "organization", organization)
+	return nil
+}
+
+// fqdnChecker verifies that the value is a fully qualified domain name.
+func fqdnChecker(p *prompter, value string) bool {
+	if !shared.IsFqdn(value) {
+		fmt.Fprintln(p.out, L("Not a valid fully qualified domain name"))
+		return false
+	}
+	return true
+}
+
+// fileChecker verifies that the value is the path of an existing file.
+func fileChecker(p *prompter, value string) bool {
+	if value == "" || !utils.FileExists(value) {
+		fmt.Fprintf(p.out, L("%s file is not accessible")+"\n", value)
+		return false
+	}
+	return true
+}
+
+// splitList splits a comma-separated list, ignoring the empty items.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// setValue sets a value in the configuration, creating the parent entries of the dotted key.
+func setValue(config map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := config[part].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			config[part] = child
+		}
+		config = child
+	}
+	config[parts[len(parts)-1]] = value
+}
+
+// writeConfig writes the configuration file, only readable by its owner as it contains passwords.
+func writeConfig(path string, config map[string]interface{}) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return utils.Errorf(err, L("failed to generate the configuration: %s"))
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return utils.Errorf(err, L("failed to write configuration file %[1]s: %[2]s"), path)
+	}
+	return nil
+}
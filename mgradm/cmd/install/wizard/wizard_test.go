@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wizard
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestPrompter(input string, out *bytes.Buffer) *prompter {
+	p := newPrompter(strings.NewReader(input), out)
+	p.askPassword = func(prompt string, min int, max int) string {
+		return "secret-" + strings.Split(prompt, " ")[0]
+	}
+	return p
+}
+
+func TestAsk(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"ca.pem", "server.pem", "server.key"} {
+		if err := os.WriteFile(path.Join(dir, name), []byte{}, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	input := strings.Join([]string{
+		"lxc",                         // invalid backend
+		"",                            // default podman backend
+		"uyuni",                       // not a FQDN
+		"uyuni.example.com",           // FQDN
+		"third-party",                 // SSL mode
+		path.Join(dir, "missing.pem"), // missing root CA
+		path.Join(dir, "ca.pem"),      // root CA
+		path.Join(dir, "server.pem"),  // server certificate
+		path.Join(dir, "server.key"),  // server key
+		"",                            // no intermediate CA
+		"external",                    // database
+		"db.example.com",              // database host
+		"0",                           // invalid port
+		"",                            // default port
+		"",                            // default database name
+		"",                            // default database user
+		"postgres",                    // database admin
+		"",                            // no SCC credentials
+		"",                            // default admin login
+		"not an email",                // invalid admin email
+		"admin@example.com",           // admin email
+		"ACME",                        // organization
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	result, err := ask(newTestPrompter(input, &out), []string{"podman", "kubernetes"}, "server.local")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if result.Backend != "podman" || result.Fqdn != "uyuni.example.com" {
+		t.Errorf("unexpected backend %s or FQDN %s", result.Backend, result.Fqdn)
+	}
+
+	expected := map[string]interface{}{
+		"ssl": map[string]interface{}{
+			"ca":     map[string]interface{}{"root": path.Join(dir, "ca.pem")},
+			"server": map[string]interface{}{"cert": path.Join(dir, "server.pem"), "key": path.Join(dir, "server.key")},
+		},
+		"db": map[string]interface{}{
+			"host":     "db.example.com",
+			"port":     5432,
+			"name":     "susemanager",
+			"user":     "spacewalk",
+			"password": "secret-Database",
+			"admin":    map[string]interface{}{"user": "postgres", "password": "secret-External"},
+		},
+		"admin": map[string]interface{}{
+			"login":    "admin",
+			"password": "secret-Administrator",
+			"email":    "admin@example.com",
+		},
+		"email":        "admin@example.com",
+		"emailfrom":    "admin@example.com",
+		"organization": "ACME",
+	}
+	if !reflect.DeepEqual(result.Config, expected) {
+		t.Errorf("unexpected configuration:\n%v\nexpected:\n%v", result.Config, expected)
+	}
+
+	for _, message := range []string{"Possible values are", "Not a valid fully qualified domain name",
+		"file is not accessible", "A positive number is required"} {
+		if !strings.Contains(out.String(), message) {
+			t.Errorf("missing validation message %q in output:\n%s", message, out.String())
+		}
+	}
+}
+
+func TestAskKubernetesSelfSigned(t *testing.T) {
+	input := "kubernetes\n\n\n\nsccuser\n\nadmin@example.com\n\n"
+	var out bytes.Buffer
+	result, err := ask(newTestPrompter(input, &out), []string{"podman", "kubernetes"}, "uyuni.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := result.Config["ssl"]; ok {
+		t.Errorf("no SSL password expected on kubernetes: %v", result.Config["ssl"])
+	}
+	if _, ok := result.Config["db"]; ok {
+		t.Errorf("no database configuration expected for the internal one: %v", result.Config["db"])
+	}
+	scc := map[string]interface{}{"user": "sccuser", "password": "secret-SUSE"}
+	if !reflect.DeepEqual(result.Config["scc"], scc) {
+		t.Errorf("unexpected SCC configuration: %v", result.Config["scc"])
+	}
+}
+
+func TestAskEndOfInput(t *testing.T) {
+	var out bytes.Buffer
+	if _, err := ask(newTestPrompter("podman\n", &out), []string{"podman"}, ""); err == nil {
+		t.Error("expected an error when the input ends before the last question")
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	actual := splitList(" a.pem, ,b.pem,")
+	if !reflect.DeepEqual(actual, []string{"a.pem", "b.pem"}) {
+		t.Errorf("unexpected list: %v", actual)
+	}
+}
@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"golang.org/x/term"
+)
+
+type logsFlags struct {
+	Backend   string
+	Namespace string
+	Since     string
+	Grep      string
+	Follow    bool
+	Lines     int
+	Sources   []string
+}
+
+// containerLogs are the log files of the server container to show, by source name.
+var containerLogs = map[string]string{
+	"rhn_web_ui": "/var/log/rhn/rhn_web_ui.log",
+	"taskomatic": "/var/log/rhn/rhn_taskomatic_daemon.log",
+	// The PostgreSQL log file changes every day: use the most recent one
+	"postgresql": "$(ls -t /var/lib/pgsql/data/log/*.log | head -n 1)",
+}
+
+// sourceNames are all the log sources in display order.
+var sourceNames = []string{"journal", "container", "rhn_web_ui", "taskomatic", "postgresql"}
+
+// NewCommand shows the merged logs of the server.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: L("Show the server logs"),
+		Long: L(`Show the server logs
+
+The logs of the systemd service, the container output and the main log files inside the
+container are merged, each line being prefixed by its source.
+The systemd service logs are only available for a local podman server.
+The --since parameter does not apply to the log files inside the container.
+`) + fmt.Sprintf(L("The available sources are: %s"), strings.Join(sourceNames, ", ")),
+		Args:        cobra.ExactArgs(0),
+		Annotations: map[string]string{podman.RemoteAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags logsFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, showLogs)
+		},
+	}
+
+	cmd.Flags().String("since", "", L("Only show the logs since a duration like 1h or a timestamp"))
+	cmd.Flags().String("grep", "", L("Only show the lines matching this regular expression"))
+	cmd.Flags().BoolP("follow", "f", false, L("Follow the logs"))
+	cmd.Flags().IntP("lines", "n", 50, L("Number of last lines to show for each source when not using --since"))
+	cmd.Flags().StringSlice("sources", sourceNames, L("Log sources to show"))
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(cmd)
+		utils.AddNamespaceFlag(cmd)
+	}
+	return cmd
+}
+
+func showLogs(globalFlags *types.GlobalFlags, flags *logsFlags, cmd *cobra.Command, args []string) error {
+	var filter *regexp.Regexp
+	if flags.Grep != "" {
+		var err error
+		if filter, err = regexp.Compile(flags.Grep); err != nil {
+			return utils.UsageError(utils.Errorf(err, L("invalid regular expression %[1]s: %[2]s"), flags.Grep))
+		}
+	}
+	for _, source := range flags.Sources {
+		if !utils.Contains(sourceNames, source) {
+			return utils.UsageError(fmt.Errorf(L("invalid log source %[1]s, possible values are: %[2]s"),
+				source, strings.Join(sourceNames, ", ")))
+		}
+	}
+
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
+	command, err := cnx.GetCommand()
+	if err != nil {
+		return err
+	}
+	podName, err := cnx.GetPodName()
+	if err != nil {
+		return err
+	}
+	namespace := ""
+	if command == "kubectl" {
+		if namespace, err = cnx.GetNamespace(); err != nil {
+			return err
+		}
+	}
+
+	sources := logSources(flags, command, podName, namespace, podman.IsRemote())
+	return utils.RunLogSources(os.Stdout, sources, filter, term.IsTerminal(int(os.Stdout.Fd())))
+}
+
+// logSources computes the commands printing the logs of the selected sources.
+func logSources(flags *logsFlags, command string, podName string, namespace string, remote bool) []utils.LogSource {
+	sources := []utils.LogSource{}
+	for _, name := range sourceNames {
+		if !utils.Contains(flags.Sources, name) {
+			continue
+		}
+		switch name {
+		case "journal":
+			if command == "kubectl" || remote {
+				log.Debug().Msg("Skipping the systemd journal as the server is not on a local podman host")
+				continue
+			}
+			args := []string{"-u", podman.ServerService, "--no-pager", "-o", "short-iso"}
+			if flags.Since != "" {
+				args = append(args, "--since", utils.JournalSince(flags.Since))
+			} else {
+				args = append(args, "-n", fmt.Sprint(flags.Lines))
+			}
+			if flags.Follow {
+				args = append(args, "-f")
+			}
+			sources = append(sources, utils.LogSource{Name: name, Command: "journalctl", Args: args})
+		case "container":
+			args := []string{"logs"}
+			if command == "kubectl" {
+				args = append(args, "-n", namespace, "-c", "uyuni")
+			}
+			if flags.Since != "" {
+				args = append(args, utils.ContainerSince(command, flags.Since)...)
+			} else {
+				args = append(args, "--tail", fmt.Sprint(flags.Lines))
+			}
+			if flags.Follow {
+				args = append(args, "-f")
+			}
+			args = append(args, podName)
+			sources = append(sources, newSource(name, command, args))
+		default:
+			tail := fmt.Sprintf("tail -n %d", flags.Lines)
+			if flags.Follow {
+				tail += " -F"
+			}
+			args := []string{"exec", podName}
+			if command == "kubectl" {
+				args = append(args, "-n", namespace, "-c", "uyuni", "--")
+			}
+			args = append(args, "sh", "-c", tail+" "+containerLogs[name])
+			sources = append(sources, newSource(name, command, args))
+		}
+	}
+	return sources
+}
+
+func newSource(name string, command string, args []string) utils.LogSource {
+	if command == "kubectl" {
+		args = kubernetes.KubectlArgs(args...)
+	}
+	return utils.LogSource{Name: name, Command: command, Args: args}
+}
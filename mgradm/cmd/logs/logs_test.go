@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func TestLogSourcesPodman(t *testing.T) {
+	flags := logsFlags{Since: "2h", Follow: true, Lines: 10, Sources: sourceNames}
+	sources := logSources(&flags, "podman", "uyuni-server", "", false)
+
+	expected := []string{
+		"journal: journalctl -u uyuni-server --no-pager -o short-iso --since -7200s -f",
+		"container: podman logs --since 2h -f uyuni-server",
+		"rhn_web_ui: podman exec uyuni-server sh -c tail -n 10 -F /var/log/rhn/rhn_web_ui.log",
+		"taskomatic: podman exec uyuni-server sh -c tail -n 10 -F /var/log/rhn/rhn_taskomatic_daemon.log",
+		"postgresql: podman exec uyuni-server sh -c tail -n 10 -F $(ls -t /var/lib/pgsql/data/log/*.log | head -n 1)",
+	}
+	checkSources(t, sources, expected)
+
+	// No journal for remote podman hosts
+	flags = logsFlags{Lines: 5, Sources: []string{"journal", "container"}}
+	sources = logSources(&flags, "podman", "uyuni-server", "", true)
+	checkSources(t, sources, []string{"container: podman logs --tail 5 uyuni-server"})
+}
+
+func TestLogSourcesKubernetes(t *testing.T) {
+	flags := logsFlags{Since: "2024-05-01T10:00:00Z", Lines: 5, Sources: []string{"journal", "container", "taskomatic"}}
+	sources := logSources(&flags, "kubectl", "uyuni-1234", "uyuni", false)
+
+	expected := []string{
+		"container: kubectl logs -n uyuni -c uyuni --since-time 2024-05-01T10:00:00Z uyuni-1234",
+		"taskomatic: kubectl exec uyuni-1234 -n uyuni -c uyuni -- sh -c tail -n 5 /var/log/rhn/rhn_taskomatic_daemon.log",
+	}
+	checkSources(t, sources, expected)
+}
+
+func checkSources(t *testing.T, sources []utils.LogSource, expected []string) {
+	actual := []string{}
+	for _, source := range sources {
+		actual = append(actual, source.Name+": "+source.Command+" "+strings.Join(source.Args, " "))
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected sources:\n%s\nexpected:\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+	}
+}
@@ -10,15 +10,17 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/migrate/shared"
 	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 type kubernetesMigrateFlags struct {
-	shared.MigrateFlags `mapstructure:",squash"`
-	Helm                cmd_utils.HelmFlags
-	Ssl                 cmd_utils.SslCertFlags
+	shared.MigrateFlags       `mapstructure:",squash"`
+	Helm                      cmd_utils.HelmFlags
+	Ssl                       cmd_utils.SslCertFlags
+	cmd_utils.KubernetesFlags `mapstructure:",squash"`
 }
 
 // NewCommand for kubernetes migration.
@@ -30,11 +32,16 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 
 This migration command assumes a few things:
   * the SSH configuration for the source server is complete, including user and
-    all needed options to connect to the machine,
+    all needed options to connect to the machine. The user, port and jump hosts
+    can also be set using flags. Use migrate test-ssh to check the connection,
   * an SSH agent is started and the key to use to connect to the server is added to it,
   * kubectl and helm are installed locally,
   * a working kubectl configuration should be set to connect to the cluster to deploy to
 
+The data are synchronized by a uyuni-migration job mounting the server persistent volumes.
+Its logs are shown during the migration. If the migration is interrupted, running it again
+resumes the synchronization.
+
 When migrating a server with a automatically generated SSL Root CA certificate, the private key
 password will be required to convert it to RSA in a kubernetes secret.
 This is not needed if the source server does not have a generated SSL CA certificate.
@@ -44,13 +51,21 @@ NOTE: migrating to a remote cluster is not supported yet!
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags kubernetesMigrateFlags
-			return utils.CommandHelper(globalFlags, cmd, args, &flags, migrateToKubernetes)
+			return utils.CommandHelper(globalFlags, cmd, args, &flags,
+				utils.WithNotification(utils.WithLock(kubernetes.WithLease(uyuniNamespace,
+					utils.WithHooks(utils.PreMigrateHook, "", "kubernetes", migrateToKubernetes)))))
 		},
 	}
 
 	shared.AddMigrateFlags(migrateCmd)
 	cmd_utils.AddHelmInstallFlag(migrateCmd)
+	cmd_utils.AddKubernetesFlags(migrateCmd)
 	migrateCmd.Flags().String("ssl-password", "", L("SSL CA generated private key password"))
 
 	return migrateCmd
 }
+
+// uyuniNamespace returns the namespace of the server deployment to lock.
+func uyuniNamespace(flags *kubernetesMigrateFlags) string {
+	return flags.Helm.Uyuni.Namespace
+}
@@ -14,6 +14,7 @@ import (
 	"path"
 
 	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	migration_shared "github.com/uyuni-project/uyuni-tools/mgradm/cmd/migrate/shared"
 	"github.com/uyuni-project/uyuni-tools/mgradm/shared/kubernetes"
@@ -32,28 +33,43 @@ func migrateToKubernetes(
 	cmd *cobra.Command,
 	args []string,
 ) error {
-	for _, binary := range []string{"kubectl", "helm"} {
-		if _, err := exec.LookPath(binary); err != nil {
-			return fmt.Errorf(L("install %s before running this command"), binary)
-		}
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return fmt.Errorf(L("install %s before running this command"), "kubectl")
 	}
-	cnx := shared.NewConnection("kubectl", "", shared_kubernetes.ServerFilter)
+	namespace := flags.Helm.Uyuni.Namespace
+	cnx := shared.NewConnection("kubectl", "", shared_kubernetes.ServerFilter, namespace)
 
 	serverImage, err := utils.ComputeImage(flags.Image.Name, flags.Image.Tag)
 	if err != nil {
-		return fmt.Errorf(L("failed to compute image URL: %s"), err)
+		return utils.Errorf(err, L("failed to compute image URL: %s"))
 	}
 
+	if err := flags.CheckParameters(); err != nil {
+		return err
+	}
+	if err := flags.CheckMaintenanceWindow(); err != nil {
+		return err
+	}
+	kubernetesArgs, err := flags.KubernetesFlags.HelmArgs(kubernetes.ServerPorts(false))
+	if err != nil {
+		return err
+	}
 	fqdn := args[0]
 
+	// Check the source server before deploying anything
+	if err := migration_shared.CheckSshConnection(fqdn, flags.User, flags.Ssh); err != nil {
+		return err
+	}
+	report := adm_utils.NewMigrationReport(fqdn)
+
 	// Find the SSH Socket and paths for the migration
 	sshAuthSocket := migration_shared.GetSshAuthSocket()
 	sshConfigPath, sshKnownhostsPath := migration_shared.GetSshPaths()
 
 	// Prepare the migration script and folder
-	scriptDir, err := adm_utils.GenerateMigrationScript(fqdn, flags.User, true)
+	scriptDir, err := adm_utils.GenerateMigrationScript(fqdn, flags.User, flags.Ssh, true, flags.Prepare, flags.Final)
 	if err != nil {
-		return fmt.Errorf(L("failed to generate migration script: %s"), err)
+		return utils.Errorf(err, L("failed to generate migration script: %s"))
 	}
 
 	defer os.RemoveAll(scriptDir)
@@ -69,91 +85,109 @@ func migrateToKubernetes(
 	// Install Uyuni with generated CA cert: an empty struct means no 3rd party cert
 	var sslFlags adm_utils.SslCertFlags
 
-	// Deploy for running migration command
-	if err := kubernetes.Deploy(cnx, &flags.Image, &flags.Helm, &sslFlags, clusterInfos, fqdn, false,
-		"--set", "migration.ssh.agentSocket="+sshAuthSocket,
-		"--set", "migration.ssh.configPath="+sshConfigPath,
-		"--set", "migration.ssh.knownHostsPath="+sshKnownhostsPath,
-		"--set", "migration.dataPath="+scriptDir); err != nil {
-		return fmt.Errorf(L("cannot run deploy: %s"), err)
+	// Deploy to create the persistent volumes. Running it again after a prepared migration only updates it.
+	if err := kubernetes.Deploy(cnx, &flags.Image, &flags.Helm, &sslFlags, &flags.Expose, clusterInfos, fqdn, false,
+		kubernetesArgs...); err != nil {
+		return utils.Errorf(err, L("cannot run deploy: %s"))
 	}
 
 	//this is needed because folder with script needs to be mounted
 	//check the node before scaling down
-	nodeName, err := shared_kubernetes.GetNode("uyuni")
+	nodeName, err := shared_kubernetes.GetNode(namespace, shared_kubernetes.ServerFilter)
 	if err != nil {
-		return fmt.Errorf(L("cannot find node running uyuni: %s"), err)
+		return utils.Errorf(err, L("cannot find node running uyuni: %s"))
 	}
+
+	// The migration job needs the volumes: after each command we want to scale to 0
+	err = shared_kubernetes.ReplicasTo(namespace, shared_kubernetes.ServerFilter, 0)
+	if err != nil {
+		return utils.Errorf(err, L("cannot set replicas to 0: %s"))
+	}
+
 	// Run the actual migration
-	if err := adm_utils.RunMigration(cnx, scriptDir, "migrate.sh"); err != nil {
-		return fmt.Errorf(L("cannot run migration: %s"), err)
+	if err := kubernetes.RunMigrationJob(serverImage, flags.Image.PullPolicy, namespace, nodeName, scriptDir,
+		sshAuthSocket, sshConfigPath, sshKnownhostsPath); err != nil {
+		return utils.Errorf(err, L("cannot run migration: %s"))
 	}
+	report.EndStage(L("Data synchronization"))
 
-	tz, oldPgVersion, newPgVersion, err := adm_utils.ReadContainerData(scriptDir)
-	if err != nil {
-		return fmt.Errorf(L("cannot read data from container: %s"), err)
+	if flags.Prepare {
+		log.Info().Msg(L("Data pre-synchronized, run the migration again without --prepare to finish it"))
+		return nil
 	}
 
-	// After each command we want to scale to 0
-	err = shared_kubernetes.ReplicasTo(shared_kubernetes.ServerFilter, 0)
-	if err != nil {
-		return fmt.Errorf(L("cannot set replicas to 0: %s"), err)
+	if err := report.ReadScriptData(scriptDir); err != nil {
+		return utils.Errorf(err, L("cannot read data from container: %s"))
 	}
+	oldPgVersion := report.OldPgVersion
+	newPgVersion := report.NewPgVersion
 
 	defer func() {
 		// if something is running, we don't need to set replicas to 1
-		if _, err = shared_kubernetes.GetNode("uyuni"); err != nil {
-			err = shared_kubernetes.ReplicasTo(shared_kubernetes.ServerFilter, 1)
+		if _, err = shared_kubernetes.GetNode(namespace, shared_kubernetes.ServerFilter); err != nil {
+			err = shared_kubernetes.ReplicasTo(namespace, shared_kubernetes.ServerFilter, 1)
 		}
 	}()
 
 	setupSslArray, err := setupSsl(&flags.Helm, kubeconfig, scriptDir, flags.Ssl.Password, flags.Image.PullPolicy)
 	if err != nil {
-		return fmt.Errorf(L("cannot setup SSL: %s"), err)
+		return utils.Errorf(err, L("cannot setup SSL: %s"))
 	}
 
 	helmArgs := []string{
 		"--reset-values",
-		"--set", "timezone=" + tz,
+		"--set", "timezone=" + report.Timezone,
 	}
 	helmArgs = append(helmArgs, setupSslArray...)
+	helmArgs = append(helmArgs, kubernetesArgs...)
 
 	// Run uyuni upgrade using the new ssl certificate
 	err = kubernetes.UyuniUpgrade(serverImage, flags.Image.PullPolicy, &flags.Helm, kubeconfig, fqdn, clusterInfos.Ingress, helmArgs...)
 	if err != nil {
-		return fmt.Errorf(L("cannot upgrade helm chart to image %s using new SSL certificate: %s"), serverImage, err)
+		return utils.Errorf(err, L("cannot upgrade helm chart to image %s using new SSL certificate: %s"), serverImage)
 	}
 
-	if err := shared_kubernetes.WaitForDeployment(flags.Helm.Uyuni.Namespace, "uyuni", "uyuni"); err != nil {
-		return fmt.Errorf(L("cannot wait for deployment of %s: %s"), serverImage, err)
+	if err := shared_kubernetes.WaitForDeployment(namespace, "uyuni", "uyuni"); err != nil {
+		return utils.Errorf(err, L("cannot wait for deployment of %s: %s"), serverImage)
 	}
 
-	err = shared_kubernetes.ReplicasTo(shared_kubernetes.ServerFilter, 0)
+	err = shared_kubernetes.ReplicasTo(namespace, shared_kubernetes.ServerFilter, 0)
 	if err != nil {
-		return fmt.Errorf(L("cannot set replicas to 0: %s"), err)
+		return utils.Errorf(err, L("cannot set replicas to 0: %s"))
 	}
 
 	if oldPgVersion != newPgVersion {
-		if err := kubernetes.RunPgsqlVersionUpgrade(flags.Image, flags.MigrationImage, nodeName, oldPgVersion, newPgVersion); err != nil {
-			return fmt.Errorf(L("cannot run PostgreSQL version upgrade script: %s"), err)
+		if err := kubernetes.RunPgsqlVersionUpgrade(namespace, flags.Image, flags.MigrationImage, nodeName, oldPgVersion, newPgVersion, adm_utils.DefaultPgsqlUpgradeFlags); err != nil {
+			return utils.WithCode(utils.CodeDBUpgrade, utils.Errorf(err, L("cannot run PostgreSQL version upgrade script: %s")))
 		}
+		report.EndStage(L("PostgreSQL version upgrade"))
 	}
 
 	schemaUpdateRequired := oldPgVersion != newPgVersion
-	if err := kubernetes.RunPgsqlFinalizeScript(serverImage, flags.Image.PullPolicy, nodeName, schemaUpdateRequired); err != nil {
-		return fmt.Errorf(L("cannot run PostgreSQL version upgrade script: %s"), err)
+	if err := kubernetes.RunPgsqlFinalizeScript(namespace, serverImage, flags.Image.PullPolicy, nodeName, schemaUpdateRequired, adm_utils.DefaultPgsqlUpgradeFlags); err != nil {
+		return utils.WithCode(utils.CodeDBUpgrade, utils.Errorf(err, L("cannot run PostgreSQL version upgrade script: %s")))
 	}
+	report.EndStage(L("PostgreSQL finalization"))
 
-	if err := kubernetes.RunPostUpgradeScript(serverImage, flags.Image.PullPolicy, nodeName); err != nil {
-		return fmt.Errorf(L("cannot run post upgrade script: %s"), err)
+	if err := kubernetes.RunPostUpgradeScript(namespace, serverImage, flags.Image.PullPolicy, nodeName); err != nil {
+		return utils.Errorf(err, L("cannot run post upgrade script: %s"))
 	}
+	report.EndStage(L("Post upgrade"))
 
 	err = kubernetes.UyuniUpgrade(serverImage, flags.Image.PullPolicy, &flags.Helm, kubeconfig, fqdn, clusterInfos.Ingress, helmArgs...)
 	if err != nil {
-		return fmt.Errorf(L("cannot upgrade to image %s: %s"), serverImage, err)
+		return utils.Errorf(err, L("cannot upgrade to image %s: %s"), serverImage)
+	}
+
+	if err := shared_kubernetes.WaitForDeployment(namespace, "uyuni", "uyuni"); err != nil {
+		return err
 	}
+	report.EndStage(L("Server start"))
 
-	return shared_kubernetes.WaitForDeployment(flags.Helm.Uyuni.Namespace, "uyuni", "uyuni")
+	kubernetes.SaveInstallState(namespace, serverImage, report.Timezone,
+		utils.GetUserFlags(utils.CommandConfig(cmd), cmd))
+	report.Finish()
+	return nil
 }
 
 // updateIssuer replaces the temporary SSL certificate issuer with the source server CA.
@@ -168,7 +202,7 @@ func setupSsl(helm *adm_utils.HelmFlags, kubeconfig string, scriptDir string, pa
 		// Strip down the certificate text part
 		out, err := utils.RunCmdOutput(zerolog.DebugLevel, "openssl", "x509", "-in", caCert)
 		if err != nil {
-			return []string{}, fmt.Errorf(L("failed to strip text part from CA certificate: %s"), err)
+			return []string{}, utils.Errorf(err, L("failed to strip text part from CA certificate: %s"))
 		}
 		cert := base64.StdEncoding.EncodeToString(out)
 		ca := ssl.SslPair{Cert: cert, Key: key}
@@ -177,7 +211,7 @@ func setupSsl(helm *adm_utils.HelmFlags, kubeconfig string, scriptDir string, pa
 		sslFlags := adm_utils.SslCertFlags{}
 		ret, err := kubernetes.DeployCertificate(helm, &sslFlags, cert, &ca, kubeconfig, "", pullPolicy)
 		if err != nil {
-			return []string{}, fmt.Errorf(L("cannot deploy certificate: %s"), err)
+			return []string{}, utils.Errorf(err, L("cannot deploy certificate: %s"))
 		}
 		return ret, nil
 	} else {
@@ -21,6 +21,7 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	}
 
 	migrateCmd.AddCommand(podman.NewCommand(globalFlags))
+	migrateCmd.AddCommand(newTestSshCommand(globalFlags))
 
 	if kubernetesCmd := kubernetes.NewCommand(globalFlags); kubernetesCmd != nil {
 		migrateCmd.AddCommand(kubernetesCmd)
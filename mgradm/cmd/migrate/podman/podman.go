@@ -27,7 +27,8 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 
 This migration command assumes a few things:
   * the SSH configuration for the source server is complete, including user and
-    all needed options to connect to the machine,
+    all needed options to connect to the machine. The user, port and jump hosts
+    can also be set using flags. Use migrate test-ssh to check the connection,
   * an SSH agent is started and the key to use to connect to the server is added to it,
   * podman is installed locally
 
@@ -36,7 +37,8 @@ NOTE: migrating to a remote podman is not supported yet!
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags podmanMigrateFlags
-			return utils.CommandHelper(globalFlags, cmd, args, &flags, migrateToPodman)
+			return utils.CommandHelper(globalFlags, cmd, args, &flags,
+				utils.WithNotification(utils.WithLock(utils.WithHooks(utils.PreMigrateHook, "", "podman", migrateToPodman))))
 		},
 	}
 
@@ -10,9 +10,9 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	migration_shared "github.com/uyuni-project/uyuni-tools/mgradm/cmd/migrate/shared"
 	"github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
 	podman_utils "github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 
@@ -24,38 +24,65 @@ func migrateToPodman(globalFlags *types.GlobalFlags, flags *podmanMigrateFlags,
 	if _, err := exec.LookPath("podman"); err != nil {
 		return fmt.Errorf(L("install podman before running this command"))
 	}
+	if err := flags.CheckParameters(); err != nil {
+		return err
+	}
+	if err := flags.CheckMaintenanceWindow(); err != nil {
+		return err
+	}
 	sourceFqdn := args[0]
+
+	// Check the source server before pulling and running anything
+	if err := migration_shared.CheckSshConnection(sourceFqdn, flags.User, flags.Ssh); err != nil {
+		return err
+	}
+
 	serverImage, err := utils.ComputeImage(flags.Image.Name, flags.Image.Tag)
 	if err != nil {
-		return fmt.Errorf(L("cannot compute image: %s"), err)
+		return utils.Errorf(err, L("cannot compute image: %s"))
 	}
 
 	// Find the SSH Socket and paths for the migration
 	sshAuthSocket := migration_shared.GetSshAuthSocket()
 	sshConfigPath, sshKnownhostsPath := migration_shared.GetSshPaths()
 
-	tz, oldPgVersion, newPgVersion, err := podman.RunMigration(serverImage, flags.Image.PullPolicy, sshAuthSocket, sshConfigPath, sshKnownhostsPath, sourceFqdn, flags.User)
+	report, err := podman.RunMigration(serverImage, flags.Image.PullPolicy, sshAuthSocket, sshConfigPath, sshKnownhostsPath, sourceFqdn, flags.User, flags.Ssh, flags.Prepare, flags.Final)
 	if err != nil {
-		return fmt.Errorf(L("cannot run migration script: %s"), err)
+		return utils.Errorf(err, L("cannot run migration script: %s"))
 	}
 
-	if oldPgVersion != newPgVersion {
-		if err := podman.RunPgsqlVersionUpgrade(flags.Image, flags.MigrationImage, oldPgVersion, newPgVersion); err != nil {
-			return fmt.Errorf(L("cannot run PostgreSQL version upgrade script: %s"), err)
+	if flags.Prepare {
+		log.Info().Msg(L("Data pre-synchronized, run the migration again without --prepare to finish it"))
+		return nil
+	}
+
+	if report.OldPgVersion != report.NewPgVersion {
+		if err := podman.RunPgsqlVersionUpgrade(flags.Image, flags.MigrationImage, report.OldPgVersion, report.NewPgVersion, adm_utils.DefaultPgsqlUpgradeFlags); err != nil {
+			return utils.WithCode(utils.CodeDBUpgrade, utils.Errorf(err, L("cannot run PostgreSQL version upgrade script: %s")))
 		}
+		report.EndStage(L("PostgreSQL version upgrade"))
 	}
 
-	schemaUpdateRequired := oldPgVersion != newPgVersion
-	if err := podman.RunPgsqlFinalizeScript(serverImage, schemaUpdateRequired); err != nil {
-		return fmt.Errorf(L("cannot run PostgreSQL finalize script: %s"), err)
+	schemaUpdateRequired := report.OldPgVersion != report.NewPgVersion
+	if err := podman.RunPgsqlFinalizeScript(serverImage, schemaUpdateRequired, adm_utils.DefaultPgsqlUpgradeFlags); err != nil {
+		return utils.WithCode(utils.CodeDBUpgrade, utils.Errorf(err, L("cannot run PostgreSQL finalize script: %s")))
 	}
+	report.EndStage(L("PostgreSQL finalization"))
 
 	if err := podman.RunPostUpgradeScript(serverImage); err != nil {
-		return fmt.Errorf(L("cannot run post upgrade script: %s"), err)
+		return utils.Errorf(err, L("cannot run post upgrade script: %s"))
 	}
+	report.EndStage(L("Post upgrade"))
 
-	if err := podman.GenerateSystemdService(tz, serverImage, false, viper.GetStringSlice("podman.arg")); err != nil {
-		return fmt.Errorf(L("cannot generate systemd service file: %s"), err)
+	if err := podman_utils.UpdateExtraArgsConf(podman_utils.ServerService, &flags.Podman, cmd); err != nil {
+		return err
+	}
+	if err := podman_utils.UpdateHardeningConf(podman_utils.ServerHardenedServices, &flags.Podman, cmd); err != nil {
+		return err
+	}
+	limitsArgs := podman_utils.LimitsArgs(flags.Podman.Memory, flags.Podman.Cpus)
+	if err := podman.GenerateSystemdService(report.Timezone, serverImage, false, limitsArgs, false); err != nil {
+		return utils.Errorf(err, L("cannot generate systemd service file: %s"))
 	}
 
 	// Start the service
@@ -63,10 +90,14 @@ func migrateToPodman(globalFlags *types.GlobalFlags, flags *podmanMigrateFlags,
 		return err
 	}
 
+	report.EndStage(L("Server start"))
+
 	log.Info().Msg(L("Server migrated"))
+	podman.SaveInstallState(serverImage, report.Timezone, utils.GetUserFlags(utils.CommandConfig(cmd), cmd))
+	report.Finish()
 
 	if err := podman_utils.EnablePodmanSocket(); err != nil {
-		return fmt.Errorf(L("cannot enable podman socket: %s"), err)
+		return utils.Errorf(err, L("cannot enable podman socket: %s"))
 	}
 
 	return nil
@@ -5,10 +5,14 @@
 package shared
 
 import (
+	"errors"
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
+	shared_utils "github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 // MigrateFlags represents flag required by migration command.
@@ -16,6 +20,29 @@ type MigrateFlags struct {
 	Image          types.ImageFlags `mapstructure:",squash"`
 	MigrationImage types.ImageFlags `mapstructure:"migration"`
 	User           string
+	Ssh            utils.SshFlags
+	Prepare        bool
+	Final          bool
+	Force          bool
+	Maintenance    utils.MaintenanceFlags
+}
+
+// CheckParameters checks the migration flags.
+func (flags *MigrateFlags) CheckParameters() error {
+	if flags.Prepare && flags.Final {
+		return errors.New(L("prepare and final flags cannot be set simultaneously"))
+	}
+	return nil
+}
+
+// CheckMaintenanceWindow checks that the migration is run in the maintenance window.
+//
+// Preparing the migration doesn't stop the source server and can be run at any time.
+func (flags *MigrateFlags) CheckMaintenanceWindow() error {
+	if flags.Prepare {
+		return nil
+	}
+	return flags.Maintenance.Check(time.Now(), flags.Force)
 }
 
 // AddMigrateFlags add migration flags to a command.
@@ -23,4 +50,22 @@ func AddMigrateFlags(cmd *cobra.Command) {
 	utils.AddImageFlag(cmd)
 	utils.AddMigrationImageFlag(cmd)
 	cmd.Flags().String("user", "root", L("User on the source server. Non-root user must have passwordless sudo privileges (NOPASSWD tag in /etc/sudoers)."))
+	AddSshFlags(cmd)
+
+	cmd.Flags().Bool("prepare", false, L("Only synchronize the data without stopping the source server. Can be run several times before the final migration"))
+	cmd.Flags().Bool("final", false, L("Stop and disable the services on the source server before the last synchronization"))
+	utils.AddMaintenanceWindowFlag(cmd)
+	shared_utils.AddLockFlag(cmd)
+	cmd.Flags().Bool("force", false, L("Migrate even outside of the maintenance window"))
+}
+
+// AddSshFlags add the flags to connect to the source server to a command.
+func AddSshFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("ssh-port", 0, L("SSH port of the source server. Defaults to the SSH configuration or 22"))
+	cmd.Flags().String("ssh-proxyJump", "", L("Jump hosts to connect to the source server, using the ssh ProxyJump syntax"))
+
+	_ = shared_utils.AddFlagHelpGroup(cmd, &shared_utils.Group{ID: "ssh", Title: L("SSH Connection Flags")})
+	_ = shared_utils.AddFlagToHelpGroupID(cmd, "user", "ssh")
+	_ = shared_utils.AddFlagToHelpGroupID(cmd, "ssh-port", "ssh")
+	_ = shared_utils.AddFlagToHelpGroupID(cmd, "ssh-proxyJump", "ssh")
 }
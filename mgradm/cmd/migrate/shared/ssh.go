@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"os"
+	"path"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/templates"
+	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// CheckSshConnection checks the migration source server can be reached with the migration SSH configuration.
+//
+// The user needs to be able to run sudo without password if it is not root and rsync needs to be installed.
+func CheckSshConnection(sourceFqdn string, user string, ssh adm_utils.SshFlags) error {
+	// Fails if there is no agent as the migration would fail too
+	GetSshAuthSocket()
+	userConfigPath, _ := GetSshPaths()
+
+	tmpDir, err := os.MkdirTemp("", "mgradm-*")
+	if err != nil {
+		return utils.Errorf(err, L("failed to create temporary directory: %s"))
+	}
+	defer os.RemoveAll(tmpDir)
+
+	configPath := path.Join(tmpDir, "ssh_config")
+	sshConfig := templates.SshConfigTemplateData{
+		SourceFqdn:  sourceFqdn,
+		User:        user,
+		Port:        ssh.Port,
+		ProxyJump:   ssh.ProxyJump,
+		IncludePath: userConfigPath,
+	}
+	if err := adm_utils.GenerateSshConfig(sshConfig, configPath); err != nil {
+		return err
+	}
+
+	sshArgs := []string{"-F", configPath, "-o", "BatchMode=yes", "-o", "ConnectTimeout=10", sourceFqdn}
+
+	log.Info().Msgf(L("Connecting to %s"), sourceFqdn)
+	if _, err := utils.RunCmdOutput(zerolog.DebugLevel, "ssh", append(sshArgs, "true")...); err != nil {
+		return utils.Errorf(err, L("failed to connect to %[1]s: %[2]s"), sourceFqdn)
+	}
+
+	if user != "root" {
+		log.Info().Msgf(L("Checking passwordless sudo for %s"), user)
+		if _, err := utils.RunCmdOutput(zerolog.DebugLevel, "ssh", append(sshArgs, "sudo", "-n", "true")...); err != nil {
+			return utils.Errorf(err, L("user %[1]s cannot run sudo without password: %[2]s"), user)
+		}
+	}
+
+	log.Info().Msg(L("Checking rsync is installed on the source server"))
+	if _, err := utils.RunCmdOutput(zerolog.DebugLevel, "ssh", append(sshArgs, "command", "-v", "rsync")...); err != nil {
+		return utils.Errorf(err, L("rsync is not installed on %[1]s: %[2]s"), sourceFqdn)
+	}
+	return nil
+}
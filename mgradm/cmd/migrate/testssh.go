@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/migrate/shared"
+	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type testSshFlags struct {
+	User string
+	Ssh  adm_utils.SshFlags
+}
+
+func newTestSshCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test-ssh [source server FQDN]",
+		Short: L("Test the SSH connection to the migration source server"),
+		Long: L(`Test the SSH connection to the migration source server
+
+The connection uses the same SSH configuration as the migration.
+The user must be able to run sudo without password if it is not root and rsync needs to be installed
+on the source server.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags testSshFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, testSsh)
+		},
+	}
+
+	cmd.Flags().String("user", "root", L("User on the source server. Non-root user must have passwordless sudo privileges (NOPASSWD tag in /etc/sudoers)."))
+	shared.AddSshFlags(cmd)
+
+	return cmd
+}
+
+func testSsh(globalFlags *types.GlobalFlags, flags *testSshFlags, cmd *cobra.Command, args []string) error {
+	sourceFqdn := args[0]
+	if err := shared.CheckSshConnection(sourceFqdn, flags.User, flags.Ssh); err != nil {
+		return err
+	}
+
+	log.Info().Msgf(L("SSH connection to %s is ready for the migration"), sourceFqdn)
+	return nil
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package profile
+
+import (
+	"errors"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type listFlags struct {
+	utils.OutputFlags `mapstructure:",squash"`
+}
+
+type useFlags struct {
+	None bool
+}
+
+// NewCommand manages the configuration profiles.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: L("Manage the configuration profiles"),
+		Long: L(`Manage the configuration profiles
+
+Profiles are named configurations used to manage several servers from the same machine,
+for instance a production and a test one deployed on remote podman hosts or kubernetes
+clusters. A profile is a configuration file stored as `) + utils.ProfilePath("<name>") + L(`
+and is read on top of the configuration file. The global flags can be set in profiles too.
+
+Example of profile:
+
+  ssh:
+    host: admin@uyuni-test.example.com
+  organization: Test
+
+The profile is selected using the --profile flag or the default one set using the use command.`),
+	}
+	profileCmd.SetUsageTemplate(profileCmd.UsageTemplate())
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: L("List the configuration profiles"),
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags listFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, list)
+		},
+	}
+	utils.AddOutputFlag(listCmd)
+
+	useCmd := &cobra.Command{
+		Use:   "use [name]",
+		Short: L("Set the profile to use when --profile is not passed"),
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags useFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, use)
+		},
+	}
+	useCmd.Flags().Bool("none", false, L("Unset the default profile"))
+
+	profileCmd.AddCommand(listCmd)
+	profileCmd.AddCommand(useCmd)
+	return profileCmd
+}
+
+type profileInfo struct {
+	Name    string `json:"name"`
+	Default bool   `json:"default"`
+	Path    string `json:"path"`
+}
+
+func list(globalFlags *types.GlobalFlags, flags *listFlags, cmd *cobra.Command, args []string) error {
+	utils.SetMachineChanged(false)
+	names, err := utils.ListProfiles()
+	if err != nil {
+		return err
+	}
+
+	defaultProfile := utils.DefaultProfile()
+	table := utils.Table{Headers: []string{L("NAME"), L("DEFAULT"), L("PATH")}}
+	infos := []profileInfo{}
+	for _, name := range names {
+		info := profileInfo{Name: name, Default: name == defaultProfile, Path: utils.ProfilePath(name)}
+		infos = append(infos, info)
+
+		isDefault := ""
+		if info.Default {
+			isDefault = "*"
+		}
+		table.Rows = append(table.Rows, []string{info.Name, isDefault, info.Path})
+	}
+	table.Data = infos
+	return table.Print(flags.Output)
+}
+
+func use(globalFlags *types.GlobalFlags, flags *useFlags, cmd *cobra.Command, args []string) error {
+	if flags.None == (len(args) == 1) {
+		return utils.UsageError(errors.New(L("pass either a profile name or --none")))
+	}
+	if flags.None {
+		if err := utils.SetDefaultProfile(""); err != nil {
+			return err
+		}
+		log.Info().Msg(L("No default profile is used anymore"))
+		return nil
+	}
+
+	if err := utils.SetDefaultProfile(args[0]); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Profile %s is now used by default"), args[0])
+	return nil
+}
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	proxyAPI "github.com/uyuni-project/uyuni-tools/shared/api/proxy"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// containerStatus is the state of a proxy container.
+type containerStatus struct {
+	Name   string `json:"name"`
+	Image  string `json:"image"`
+	State  string `json:"state"`
+	Status string `json:"status"`
+}
+
+// proxyStatus is the state of a registered proxy.
+type proxyStatus struct {
+	ID          int               `json:"id"`
+	Name        string            `json:"name"`
+	LastCheckin string            `json:"lastCheckin"`
+	Reachable   bool              `json:"reachable"`
+	Error       string            `json:"error,omitempty"`
+	Containers  []containerStatus `json:"containers"`
+}
+
+// podmanContainer is an entry of the podman ps JSON output.
+type podmanContainer struct {
+	Names  []string
+	Image  string
+	State  string
+	Status string
+}
+
+func list(globalFlags *types.GlobalFlags, flags *listFlags, cmd *cobra.Command, args []string) error {
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+	proxies, err := proxyAPI.List(client)
+	if err != nil {
+		return err
+	}
+
+	statuses := make([]proxyStatus, len(proxies))
+	var wg sync.WaitGroup
+	for i, system := range proxies {
+		statuses[i] = proxyStatus{ID: system.Id, Name: system.Name, LastCheckin: system.LastCheckin}
+		wg.Add(1)
+		go func(status *proxyStatus) {
+			defer wg.Done()
+			containers, err := proxyContainers(flags.SshUser + "@" + status.Name)
+			if err != nil {
+				log.Debug().Err(err).Msgf("Failed to list the containers of proxy %s", status.Name)
+				status.Error = err.Error()
+				return
+			}
+			status.Reachable = true
+			status.Containers = containers
+		}(&statuses[i])
+	}
+	wg.Wait()
+
+	return fleetTable(statuses).Print(flags.Output)
+}
+
+// proxyContainers lists the proxy containers running on a remote host.
+func proxyContainers(sshHost string) ([]containerStatus, error) {
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", "--url", podman.RemoteURL(sshHost),
+		"ps", "--all", "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+	return parseContainers(out)
+}
+
+// parseContainers extracts the proxy containers from the podman ps JSON output.
+func parseContainers(out []byte) ([]containerStatus, error) {
+	var containers []podmanContainer
+	if err := json.Unmarshal(out, &containers); err != nil {
+		return nil, utils.Errorf(err, L("failed to parse the containers list: %s"))
+	}
+	statuses := []containerStatus{}
+	for _, container := range containers {
+		for _, name := range container.Names {
+			if utils.Contains(podman.ProxyContainerNames, name) {
+				statuses = append(statuses, containerStatus{
+					Name: name, Image: container.Image, State: container.State, Status: container.Status,
+				})
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// proxyVersion returns the tag of the proxy httpd image.
+func proxyVersion(containers []containerStatus) string {
+	for _, container := range containers {
+		if container.Name == "uyuni-proxy-httpd" {
+			if index := strings.LastIndex(container.Image, ":"); index >= 0 &&
+				!strings.Contains(container.Image[index:], "/") {
+				return container.Image[index+1:]
+			}
+			return container.Image
+		}
+	}
+	return "-"
+}
+
+// proxyHealth summarizes the state of the proxy containers.
+func proxyHealth(status proxyStatus) string {
+	if !status.Reachable {
+		return L("unreachable")
+	}
+	problems := []string{}
+	for _, name := range podman.ProxyContainerNames {
+		found := false
+		for _, container := range status.Containers {
+			if container.Name != name {
+				continue
+			}
+			found = true
+			if container.State != "running" {
+				problems = append(problems, name+" "+container.State)
+			} else if strings.Contains(container.Status, "(unhealthy)") {
+				problems = append(problems, fmt.Sprintf(L("%s unhealthy"), name))
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf(L("%s missing"), name))
+		}
+	}
+	if len(problems) > 0 {
+		return strings.Join(problems, ", ")
+	}
+	return L("ok")
+}
+
+func fleetTable(statuses []proxyStatus) *utils.Table {
+	table := utils.Table{
+		Headers: []string{L("ID"), L("NAME"), L("LAST CHECKIN"), L("VERSION"), L("RUNNING"), L("HEALTH")},
+		Data:    statuses,
+	}
+	for _, status := range statuses {
+		running := 0
+		for _, container := range status.Containers {
+			if container.State == "running" {
+				running++
+			}
+		}
+		table.Rows = append(table.Rows, []string{
+			strconv.Itoa(status.ID), status.Name, status.LastCheckin, proxyVersion(status.Containers),
+			fmt.Sprintf("%d/%d", running, len(podman.ProxyContainerNames)), proxyHealth(status),
+		})
+	}
+	return &table
+}
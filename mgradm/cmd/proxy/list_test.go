@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package proxy
+
+import (
+	"testing"
+)
+
+const psOutput = `[
+  {"Names": ["uyuni-proxy-httpd"], "Image": "registry.opensuse.org/uyuni/proxy-httpd:2024.07", "State": "running",
+   "Status": "Up 2 hours (healthy)"},
+  {"Names": ["uyuni-proxy-salt-broker"], "Image": "registry.opensuse.org/uyuni/proxy-salt-broker:2024.07",
+   "State": "running", "Status": "Up 2 hours"},
+  {"Names": ["uyuni-proxy-squid"], "Image": "registry.opensuse.org/uyuni/proxy-squid:2024.07", "State": "running",
+   "Status": "Up 2 hours (unhealthy)"},
+  {"Names": ["uyuni-proxy-ssh"], "Image": "registry.opensuse.org/uyuni/proxy-ssh:2024.07", "State": "exited",
+   "Status": "Exited (1) 5 minutes ago"},
+  {"Names": ["other"], "Image": "registry.opensuse.org/other:latest", "State": "running", "Status": "Up 1 hour"}
+]`
+
+func TestParseContainers(t *testing.T) {
+	containers, err := parseContainers([]byte(psOutput))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(containers) != 4 {
+		t.Fatalf("expected 4 proxy containers, got %d", len(containers))
+	}
+	if version := proxyVersion(containers); version != "2024.07" {
+		t.Errorf("expected version 2024.07, got %s", version)
+	}
+
+	status := proxyStatus{Reachable: true, Containers: containers}
+	expected := "uyuni-proxy-squid unhealthy, uyuni-proxy-ssh exited, uyuni-proxy-tftpd missing"
+	if health := proxyHealth(status); health != expected {
+		t.Errorf("expected health %q, got %q", expected, health)
+	}
+
+	table := fleetTable([]proxyStatus{status, {Name: "down"}})
+	if table.Rows[0][4] != "3/5" {
+		t.Errorf("expected 3/5 running containers, got %s", table.Rows[0][4])
+	}
+	if table.Rows[1][5] != "unreachable" || table.Rows[1][3] != "-" {
+		t.Errorf("unexpected unreachable proxy row: %v", table.Rows[1])
+	}
+}
@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package proxy
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type listFlags struct {
+	api.ConnectionDetails `mapstructure:"api"`
+	utils.OutputFlags     `mapstructure:",squash"`
+	SshUser               string `mapstructure:"ssh-user"`
+}
+
+// NewCommand for the commands handling the proxies attached to the server.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	proxyCmd := &cobra.Command{
+		Use:   "proxy",
+		Short: L("Manage the proxies attached to the server"),
+		Long:  L("Manage the proxies attached to the server"),
+	}
+
+	if err := api.AddAPIFlags(proxyCmd, false); err != nil {
+		log.Fatal().Err(err).Msg(L("failed to add the API flags"))
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: L("List the registered proxies and the state of their containers"),
+		Long: L(`List the registered proxies and the state of their containers
+
+The proxies are queried from the server API. The containers of each proxy are then listed
+using podman over SSH: the podman socket needs to be enabled on the proxy hosts and the SSH
+user needs to be able to connect without password.`),
+		Args:        cobra.ExactArgs(0),
+		Annotations: map[string]string{utils.ReadOnlyAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags listFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, list)
+		},
+	}
+	listCmd.Flags().String("ssh-user", "root", L("User to connect to the proxy hosts with SSH"))
+	utils.AddOutputFlag(listCmd)
+
+	proxyCmd.AddCommand(listCmd)
+	return proxyCmd
+}
@@ -0,0 +1,23 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build docker
+
+package rename
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/docker"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func init() {
+	backendFuncs[shared.DockerBackend] = renameForDocker
+}
+
+func renameForDocker(globalFlags *types.GlobalFlags, flags *renameFlags, cmd *cobra.Command, args []string) error {
+	cnx := shared.NewConnection("docker", docker.ServerContainerName, "", "")
+	return renameInContainer(cnx, flags)
+}
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !nok8s
+
+package rename
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	adm_kubernetes "github.com/uyuni-project/uyuni-tools/mgradm/shared/kubernetes"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func renameForKubernetes(
+	globalFlags *types.GlobalFlags,
+	flags *renameFlags,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	clusterInfos, err := kubernetes.CheckCluster()
+	if err != nil {
+		return err
+	}
+	kubeconfig := clusterInfos.GetKubeconfig()
+
+	cnx := shared.NewConnection("kubectl", "", kubernetes.ServerFilter, flags.Namespace)
+	namespace, err := cnx.GetNamespace()
+	if err != nil {
+		return err
+	}
+
+	if flags.Ssl.UseExisting() {
+		flags.Helm.Uyuni.Namespace = namespace
+		adm_kubernetes.DeployExistingCertificate(&flags.Helm, &flags.Ssl, kubeconfig)
+	}
+
+	// cert-manager generates the new certificate from the helm values
+	if err := runRenameScript(cnx, flags, false); err != nil {
+		return err
+	}
+
+	log.Info().Msg(L("Updating the helm release"))
+	if err := adm_kubernetes.UpdateValues(&flags.Helm, kubeconfig, namespace, "fqdn="+flags.Fqdn); err != nil {
+		return err
+	}
+	if err := kubernetes.WaitForDeployment(namespace, "uyuni", "uyuni"); err != nil {
+		return err
+	}
+
+	log.Info().Msgf(L("Server renamed to %s"), flags.Fqdn)
+	return nil
+}
@@ -4,16 +4,16 @@
 
 //go:build nok8s
 
-package inspect
+package rename
 
 import (
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 )
 
-func kuberneteInspect(
+func renameForKubernetes(
 	globalFlags *types.GlobalFlags,
-	flags *inspectFlags,
+	flags *renameFlags,
 	cmd *cobra.Command,
 	args []string,
 ) error {
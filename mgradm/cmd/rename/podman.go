@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rename
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	adm_podman "github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func renameForPodman(globalFlags *types.GlobalFlags, flags *renameFlags, cmd *cobra.Command, args []string) error {
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, "")
+	return renameInContainer(cnx, flags)
+}
+
+// renameInContainer changes the FQDN of a server container managed by a systemd service.
+func renameInContainer(cnx *shared.Connection, flags *renameFlags) error {
+	if flags.Ssl.UseExisting() {
+		// Deploying the certificates checks them before changing anything else
+		if err := adm_podman.UpdateSslCertificate(cnx, &flags.Ssl.Ca, &flags.Ssl.Server); err != nil {
+			return utils.Errorf(err, L("cannot update SSL certificate: %s"))
+		}
+	} else {
+		utils.AskPasswordIfMissing(&flags.Ssl.Password, L("Password of the existing CA key"), 0, 0, nil)
+	}
+
+	if err := runRenameScript(cnx, flags, !flags.Ssl.UseExisting()); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Server renamed to %s"), flags.Fqdn)
+	return nil
+}
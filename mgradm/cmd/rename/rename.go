@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rename
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	install_shared "github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/shared"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/ssl"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/templates"
+	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+const renameScriptName = "rename.sh"
+
+// backendFuncs are the implementations of the command for each backend.
+var backendFuncs = map[string]utils.CommandFunc[renameFlags]{
+	shared.PodmanBackend:     renameForPodman,
+	shared.KubernetesBackend: kubernetes.WithLease(deploymentNamespace, renameForKubernetes),
+}
+
+// deploymentNamespace returns the namespace of the server deployment to lock on kubernetes.
+func deploymentNamespace(flags *renameFlags) string {
+	return flags.Namespace
+}
+
+type renameFlags struct {
+	Backend   string
+	Namespace string
+	Fqdn      string
+	Ssl       cmd_utils.SslCertFlags
+	Helm      cmd_utils.HelmFlags
+}
+
+// NewCommand changes the FQDN of the server.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename",
+		Short: L("Change the FQDN of the server"),
+		Long: L(`Change the fully qualified domain name of the server
+
+The server configuration, cobbler and the database entries referring to the old name are updated
+and the services restarted.
+
+A new server certificate is generated using the existing CA unless third party certificates
+are provided: these are validated before changing anything.
+On kubernetes, cert-manager generates the new certificate and the helm release is updated.
+
+The DNS entries and the configuration of the registered clients are not changed.`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags renameFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, utils.WithLock(rename))
+		},
+	}
+
+	cmd.Flags().String("fqdn", "", L("New fully qualified domain name of the server"))
+	install_shared.AddSslFlags(cmd)
+	utils.AddLockFlag(cmd)
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(cmd)
+		utils.AddNamespaceFlag(cmd)
+		cmd_utils.AddHelmChartFlags(cmd)
+	}
+	return cmd
+}
+
+func rename(globalFlags *types.GlobalFlags, flags *renameFlags, cmd *cobra.Command, args []string) error {
+	if !install_shared.IsFqdn(flags.Fqdn) {
+		return utils.UsageError(fmt.Errorf(L("%s is not a valid fully qualified domain name"), flags.Fqdn))
+	}
+	flags.Ssl.CheckParameters()
+	if flags.Ssl.UseExisting() {
+		ssl.CheckPaths(&flags.Ssl.Ca, &flags.Ssl.Server)
+	}
+
+	fn, err := shared.ChooseBackendFunc(cmd.Flags(), shared.ServerApp, backendFuncs)
+	if err != nil {
+		return err
+	}
+	return fn(globalFlags, flags, cmd, args)
+}
+
+// runRenameScript updates the configuration and database inside the container and restarts the services.
+//
+// If generateCertificate is true, a new server certificate is generated with the CA in /root/ssl-build.
+func runRenameScript(cnx *shared.Connection, flags *renameFlags, generateCertificate bool) error {
+	scriptDir, err := generateRenameScript(flags, generateCertificate)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(scriptDir)
+
+	if err := cnx.Copy(filepath.Join(scriptDir, renameScriptName), "server:/tmp/"+renameScriptName,
+		"root", "root"); err != nil {
+		return utils.Errorf(err, L("cannot copy %s: %s"), "/tmp/"+renameScriptName)
+	}
+	if err := cmd_utils.ExecCommand(zerolog.InfoLevel, cnx, "/tmp/"+renameScriptName); err != nil {
+		return utils.Errorf(err, L("error running the rename script: %s"))
+	}
+	return nil
+}
+
+// generateRenameScript creates a temporary folder with the script changing the FQDN.
+func generateRenameScript(flags *renameFlags, generateCertificate bool) (string, error) {
+	scriptDir, err := os.MkdirTemp("", "mgradm-*")
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to create temporary directory: %s"))
+	}
+
+	data := templates.RenameScriptTemplateData{
+		Fqdn:                flags.Fqdn,
+		Cnames:              flags.Ssl.Cnames,
+		GenerateCertificate: generateCertificate,
+		Ssl: templates.RenameScriptSslData{
+			Country:  flags.Ssl.Country,
+			State:    flags.Ssl.State,
+			City:     flags.Ssl.City,
+			Org:      flags.Ssl.Org,
+			OU:       flags.Ssl.OU,
+			Email:    flags.Ssl.Email,
+			Password: flags.Ssl.Password,
+		},
+	}
+	scriptPath := filepath.Join(scriptDir, renameScriptName)
+	if err := utils.WriteTemplateToFile(data, scriptPath, 0500, true); err != nil {
+		os.RemoveAll(scriptDir)
+		return "", utils.Errorf(err, L("failed to generate the rename script: %s"))
+	}
+	return scriptDir, nil
+}
@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rename
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readRenameScript(t *testing.T, flags *renameFlags, generateCertificate bool) string {
+	scriptDir, err := generateRenameScript(flags, generateCertificate)
+	if err != nil {
+		t.Fatalf("failed to generate the script: %s", err)
+	}
+	defer os.RemoveAll(scriptDir)
+	content, err := os.ReadFile(filepath.Join(scriptDir, renameScriptName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestGenerateRenameScript(t *testing.T) {
+	flags := renameFlags{Fqdn: "new.example.com"}
+	flags.Ssl.Cnames = []string{"alias.example.com"}
+	flags.Ssl.Country = "DE"
+	flags.Ssl.Password = "secret"
+
+	script := readRenameScript(t, &flags, true)
+	for _, expected := range []string{
+		`NEW_FQDN="new.example.com"`,
+		"/etc/rhn/rhn.conf",
+		"/etc/cobbler/settings.yaml",
+		"UPDATE rhnContentSource",
+		`--set-hostname="${NEW_FQDN}"`,
+		`--set-cname="alias.example.com"`,
+		`--set-country="DE"`,
+		`--password="secret"`,
+		"mgr-ssl-cert-setup",
+		"spacewalk-service restart",
+	} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("missing %q in script:\n%s", expected, script)
+		}
+	}
+
+	script = readRenameScript(t, &flags, false)
+	if strings.Contains(script, "rhn-ssl-tool") || strings.Contains(script, "secret") {
+		t.Errorf("no certificate should be generated:\n%s", script)
+	}
+}
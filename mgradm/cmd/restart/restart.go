@@ -13,7 +13,8 @@ import (
 )
 
 type restartFlags struct {
-	Backend string
+	Backend   string
+	Namespace string
 }
 
 // NewCommand to restart server.
@@ -32,16 +33,17 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 
 	if utils.KubernetesBuilt {
 		utils.AddBackendFlag(restartCmd)
+		utils.AddNamespaceFlag(restartCmd)
 	}
 
 	return restartCmd
 }
 
 func restart(globalFlags *types.GlobalFlags, flags *restartFlags, cmd *cobra.Command, args []string) error {
-	fn, err := shared.ChoosePodmanOrKubernetes(cmd.Flags(), podmanRestart, kubernetesRestart)
+	backend, err := shared.FindBackend(cmd.Flags(), shared.ServerApp)
 	if err != nil {
 		return err
 	}
 
-	return fn(globalFlags, flags, cmd, args)
+	return backend.Restart(shared.ServerApp, flags.Namespace)
 }
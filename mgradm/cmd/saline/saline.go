@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package saline
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type salineFlags struct {
+	Backend   string
+	Namespace string
+}
+
+// NewCommand manages the saline container independently of the server.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	salineCmd := &cobra.Command{
+		Use:   "saline",
+		Short: L("Manage the saline salt event processor"),
+		Long: L(`Manage the saline salt event processor
+
+Saline is installed using the --saline-replicas flag of the install command.
+It is started and stopped with the server, these commands only act on the saline container.`),
+	}
+
+	salineCmd.AddCommand(newActionCommand(globalFlags, "start", L("Start the saline container"), start))
+	salineCmd.AddCommand(newActionCommand(globalFlags, "stop", L("Stop the saline container"), stop))
+	salineCmd.AddCommand(newActionCommand(globalFlags, "restart", L("Restart the saline container"), restart))
+
+	return salineCmd
+}
+
+func newActionCommand(
+	globalFlags *types.GlobalFlags,
+	use string,
+	short string,
+	fn utils.CommandFunc[salineFlags],
+) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long:  short,
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags salineFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, fn)
+		},
+	}
+	cmd.SetUsageTemplate(cmd.UsageTemplate())
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(cmd)
+		utils.AddNamespaceFlag(cmd)
+	}
+	return cmd
+}
+
+func start(globalFlags *types.GlobalFlags, flags *salineFlags, cmd *cobra.Command, args []string) error {
+	backend, err := shared.FindBackend(cmd.Flags(), shared.SalineApp)
+	if err != nil {
+		return err
+	}
+	return backend.Start(shared.SalineApp, flags.Namespace)
+}
+
+func stop(globalFlags *types.GlobalFlags, flags *salineFlags, cmd *cobra.Command, args []string) error {
+	backend, err := shared.FindBackend(cmd.Flags(), shared.SalineApp)
+	if err != nil {
+		return err
+	}
+	return backend.Stop(shared.SalineApp, flags.Namespace)
+}
+
+func restart(globalFlags *types.GlobalFlags, flags *salineFlags, cmd *cobra.Command, args []string) error {
+	backend, err := shared.FindBackend(cmd.Flags(), shared.SalineApp)
+	if err != nil {
+		return err
+	}
+	return backend.Restart(shared.SalineApp, flags.Namespace)
+}
@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package serve
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// apiServer runs the commands requested through the REST API.
+type apiServer struct {
+	token      string
+	configPath string
+	// running is locked while a changing operation runs.
+	running sync.Mutex
+	// run executes the tool with the arguments and returns its machine output, overridden in the tests.
+	run func(args ...string) []byte
+}
+
+type upgradeRequest struct {
+	Tag string `json:"tag"`
+}
+
+// maxRequestSize is the maximum size of the request bodies: they only hold a few small values.
+const maxRequestSize = 4096
+
+// tagRegex matches the valid image tags.
+var tagRegex = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+// newHTTPServer creates the HTTP server with timeouts to not keep the connections of slow clients open.
+//
+// There is no write timeout as the upgrade responses are only sent once the upgrade is done.
+func newHTTPServer(listen string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              listen,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+}
+
+func serveAPI(globalFlags *types.GlobalFlags, flags *apiFlags, cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(flags.TokenFile)
+	if err != nil {
+		return utils.Errorf(err, L("failed to read the token file: %s"))
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return errors.New(L("the token file is empty"))
+	}
+
+	server := &apiServer{token: token, configPath: globalFlags.ConfigPath, run: runSelf}
+	httpServer := newHTTPServer(flags.Listen, server.handler())
+	if flags.TLS.Cert == "" || flags.TLS.Key == "" {
+		if err := checkInsecure(flags); err != nil {
+			return err
+		}
+		log.Warn().Msg(L("No TLS certificate and key provided: the API token will be sent in clear text"))
+		log.Info().Msgf(L("Serving the API on http://%s/api/v1"), flags.Listen)
+		return httpServer.ListenAndServe()
+	}
+	log.Info().Msgf(L("Serving the API on https://%s/api/v1"), flags.Listen)
+	return httpServer.ListenAndServeTLS(flags.TLS.Cert, flags.TLS.Key)
+}
+
+// checkInsecure verifies that serving the API without TLS is explicitly requested on a loopback address.
+func checkInsecure(flags *apiFlags) error {
+	if !flags.Insecure {
+		return utils.UsageError(errors.New(L("--tls-cert and --tls-key are required to serve the API, unless --insecure is passed")))
+	}
+	host, _, err := net.SplitHostPort(flags.Listen)
+	if err != nil {
+		return utils.UsageError(utils.Errorf(err, L("invalid listen address: %s")))
+	}
+	ip := net.ParseIP(host)
+	if host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return utils.UsageError(fmt.Errorf(L("the API can only be served without TLS on a loopback address, not on %s"), flags.Listen))
+	}
+	return nil
+}
+
+func (s *apiServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/status", s.authenticated(http.MethodGet, s.status))
+	mux.HandleFunc("/api/v1/upgrade", s.authenticated(http.MethodPost, s.upgrade))
+	return mux
+}
+
+// authenticated checks the method and bearer token of the requests before calling the handler.
+func (s *apiServer) authenticated(method string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			log.Warn().Msgf(L("Rejected unauthenticated %[1]s request from %[2]s"), r.URL.Path, r.RemoteAddr)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Info().Msgf(L("Handling %[1]s %[2]s from %[3]s"), r.Method, r.URL.Path, r.RemoteAddr)
+		handler(w, r)
+	}
+}
+
+func (s *apiServer) status(w http.ResponseWriter, r *http.Request) {
+	s.respond(w, s.command("status"))
+}
+
+func (s *apiServer) upgrade(w http.ResponseWriter, r *http.Request) {
+	var request upgradeRequest
+	if r.ContentLength != 0 {
+		body := http.MaxBytesReader(w, r.Body, maxRequestSize)
+		if err := json.NewDecoder(body).Decode(&request); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if request.Tag != "" && !tagRegex.MatchString(request.Tag) {
+		http.Error(w, "invalid tag", http.StatusBadRequest)
+		return
+	}
+
+	if !s.running.TryLock() {
+		http.Error(w, "another operation is running", http.StatusConflict)
+		return
+	}
+	defer s.running.Unlock()
+
+	backend := "kubernetes"
+	if podman.HasService(podman.ServerService) {
+		backend = "podman"
+	}
+	args := []string{"upgrade", backend}
+	if request.Tag != "" {
+		// Passed as a single argument so the value can't be read as another flag
+		args = append(args, "--tag="+request.Tag)
+	}
+	s.respond(w, s.command(args...))
+}
+
+// command runs the tool in machine mode with the same configuration as the API server.
+func (s *apiServer) command(args ...string) []byte {
+	cmdArgs := []string{"--machine"}
+	if s.configPath != "" {
+		cmdArgs = append(cmdArgs, "--config", s.configPath)
+	}
+	return s.run(append(cmdArgs, args...)...)
+}
+
+// respond writes the machine result of a command with a status code matching its success.
+func (s *apiServer) respond(w http.ResponseWriter, out []byte) {
+	var result utils.MachineResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		log.Error().Err(err).Msg(L("Failed to parse the command result"))
+		http.Error(w, "invalid command result", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case result.Success:
+		w.WriteHeader(http.StatusOK)
+	case result.Rc == utils.ExitUsage:
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	if _, err := w.Write(out); err != nil {
+		log.Error().Err(err).Msg(L("Failed to write the response"))
+	}
+}
+
+// runSelf executes the running tool to go through the same code paths as the command line.
+func runSelf(args ...string) []byte {
+	executable, err := os.Executable()
+	if err != nil {
+		return machineError(err)
+	}
+	var stdout bytes.Buffer
+	cmd := exec.Command(executable, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	// The machine result already reports the failures
+	_ = cmd.Run()
+	if stdout.Len() == 0 {
+		return machineError(errors.New(L("the command returned no result")))
+	}
+	return bytes.TrimSpace(stdout.Bytes())
+}
+
+func machineError(err error) []byte {
+	data, _ := json.Marshal(utils.MachineResult{Rc: utils.ExitFailure, Error: err.Error()})
+	return data
+}
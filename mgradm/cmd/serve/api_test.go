@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package serve
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIAuthentication(t *testing.T) {
+	server := &apiServer{token: "secret", run: func(args ...string) []byte {
+		return []byte(`{"success": true, "rc": 0}`)
+	}}
+	handler := server.handler()
+
+	data := []struct {
+		method   string
+		token    string
+		expected int
+	}{
+		{http.MethodGet, "", http.StatusUnauthorized},
+		{http.MethodGet, "wrong", http.StatusUnauthorized},
+		{http.MethodPost, "secret", http.StatusMethodNotAllowed},
+		{http.MethodGet, "secret", http.StatusOK},
+	}
+	for i, test := range data {
+		request := httptest.NewRequest(test.method, "/api/v1/status", nil)
+		if test.token != "" {
+			request.Header.Set("Authorization", "Bearer "+test.token)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != test.expected {
+			t.Errorf("case %d: expected status %d, got %d", i, test.expected, recorder.Code)
+		}
+	}
+}
+
+func TestAPIUpgrade(t *testing.T) {
+	var calledArgs []string
+	server := &apiServer{token: "secret", configPath: "/etc/uyuni/mgradm.yaml", run: func(args ...string) []byte {
+		calledArgs = args
+		return []byte(`{"success": false, "rc": 1, "error": "failed"}`)
+	}}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/upgrade", strings.NewReader(`{"tag": "5.1.0"}`))
+	request.Header.Set("Authorization", "Bearer secret")
+	recorder := httptest.NewRecorder()
+	server.handler().ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, recorder.Code)
+	}
+	expected := "--machine --config /etc/uyuni/mgradm.yaml upgrade"
+	if actual := strings.Join(calledArgs, " "); !strings.HasPrefix(actual, expected) ||
+		!strings.HasSuffix(actual, "--tag=5.1.0") {
+		t.Errorf("unexpected command arguments: %s", actual)
+	}
+	if !strings.Contains(recorder.Body.String(), `"error": "failed"`) {
+		t.Errorf("the command result should be returned, got %s", recorder.Body.String())
+	}
+}
+
+func TestCheckInsecure(t *testing.T) {
+	data := []struct {
+		listen   string
+		insecure bool
+		valid    bool
+	}{
+		{"127.0.0.1:9443", false, false},
+		{"127.0.0.1:9443", true, true},
+		{"localhost:9443", true, true},
+		{"[::1]:9443", true, true},
+		{":9443", true, false},
+		{"0.0.0.0:9443", true, false},
+		{"192.168.1.10:9443", true, false},
+		{"invalid", true, false},
+	}
+	for i, test := range data {
+		flags := apiFlags{Listen: test.listen, Insecure: test.insecure}
+		if err := checkInsecure(&flags); (err == nil) != test.valid {
+			t.Errorf("case %d: expected valid to be %v, got error %v", i, test.valid, err)
+		}
+	}
+}
+
+func TestAPIUpgradeInvalidRequest(t *testing.T) {
+	server := &apiServer{token: "secret", run: func(args ...string) []byte {
+		t.Errorf("no command should be run, got %v", args)
+		return []byte(`{"success": true, "rc": 0}`)
+	}}
+
+	bodies := []string{
+		`{"tag": "--force"}`,
+		`{"tag": "5.1.0 --force"}`,
+		`{"tag": "../latest"}`,
+		`{"tag": "` + strings.Repeat("a", 129) + `"}`,
+		`{"tag": "5.1.0", "padding": "` + strings.Repeat("a", maxRequestSize) + `"}`,
+		`not json`,
+	}
+	for i, body := range bodies {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/upgrade", strings.NewReader(body))
+		request.Header.Set("Authorization", "Bearer secret")
+		recorder := httptest.NewRecorder()
+		server.handler().ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("case %d: expected status %d, got %d", i, http.StatusBadRequest, recorder.Code)
+		}
+	}
+}
+
+func TestNewHTTPServer(t *testing.T) {
+	server := newHTTPServer("127.0.0.1:9443", http.NewServeMux())
+	if server.ReadHeaderTimeout == 0 || server.ReadTimeout == 0 {
+		t.Errorf("expected read timeouts, got %s and %s", server.ReadHeaderTimeout, server.ReadTimeout)
+	}
+}
@@ -0,0 +1,260 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package serve
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// metric is a Prometheus gauge sample.
+type metric struct {
+	name   string
+	help   string
+	labels map[string]string
+	value  float64
+}
+
+// certificates are the server certificates to report the expiry of, as volume and path in it.
+var certificates = map[string]struct{ volume, file string }{
+	"server": {"etc-tls", "certs/spacewalk.crt"},
+	"ca":     {"ca-cert", "LOCAL-RHN-ORG-TRUSTED-SSL-CERT"},
+}
+
+func serveMetrics(globalFlags *types.GlobalFlags, flags *metricsFlags, cmd *cobra.Command, args []string) error {
+	if flags.Textfile != "" {
+		return writeTextfile(flags.Textfile, collectMetrics(flags.BackupDir))
+	}
+
+	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := writeMetrics(w, collectMetrics(flags.BackupDir)); err != nil {
+			log.Error().Err(err).Msg(L("Failed to write the metrics"))
+		}
+	})
+	log.Info().Msgf(L("Serving the metrics on http://%s/metrics"), flags.Listen)
+	return newHTTPServer(flags.Listen, nil).ListenAndServe()
+}
+
+// writeTextfile writes the metrics file for the node exporter textfile collector.
+//
+// The file is written atomically as the collector may read it at any time.
+func writeTextfile(file string, metrics []metric) error {
+	tmpFile := file + ".tmp"
+	out, err := os.Create(tmpFile)
+	if err != nil {
+		return utils.Errorf(err, L("failed to write %[1]s: %[2]s"), tmpFile)
+	}
+	if err := writeMetrics(out, metrics); err != nil {
+		out.Close()
+		return utils.Errorf(err, L("failed to write %[1]s: %[2]s"), tmpFile)
+	}
+	out.Close()
+	return os.Rename(tmpFile, file)
+}
+
+// writeMetrics writes the metrics in the Prometheus text format.
+func writeMetrics(out io.Writer, metrics []metric) error {
+	described := map[string]bool{}
+	for _, m := range metrics {
+		if !described[m.name] {
+			if _, err := fmt.Fprintf(out, "# HELP %[1]s %[2]s\n# TYPE %[1]s gauge\n", m.name, m.help); err != nil {
+				return err
+			}
+			described[m.name] = true
+		}
+		if _, err := fmt.Fprintf(out, "%s%s %s\n", m.name, formatLabels(m.labels), strconv.FormatFloat(m.value, 'g', -1, 64)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := []string{}
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, labels[name]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// collectMetrics computes the metrics of the podman deployment.
+//
+// The values which cannot be computed are skipped and logged.
+func collectMetrics(backupDir string) []metric {
+	up := 0.0
+	if podman.IsServiceRunning(podman.ServerService) {
+		up = 1
+	}
+	metrics := []metric{{name: "uyuni_server_up", help: "Whether the server service is running.", value: up}}
+
+	if age, err := imageAge(); err != nil {
+		log.Debug().Err(err).Msg("Cannot compute the server image age")
+	} else {
+		metrics = append(metrics, metric{
+			name:  "uyuni_server_image_age_seconds",
+			help:  "Age of the image of the running server container.",
+			value: age.Seconds(),
+		})
+	}
+
+	if backupDir != "" {
+		if last, err := lastBackup(backupDir); err != nil {
+			log.Debug().Err(err).Msgf("Cannot find the last backup in %s", backupDir)
+		} else {
+			metrics = append(metrics, metric{
+				name:  "uyuni_last_backup_timestamp_seconds",
+				help:  "Modification time of the most recent backup.",
+				value: float64(last.Unix()),
+			})
+		}
+	}
+
+	mountPoints, err := podman.VolumeMountPoints()
+	if err != nil {
+		log.Debug().Err(err).Msg("Cannot list the volumes")
+		return metrics
+	}
+	metrics = append(metrics, certificateMetrics(mountPoints, time.Now())...)
+	metrics = append(metrics, volumeMetrics(mountPoints)...)
+	return metrics
+}
+
+func imageAge() (time.Duration, error) {
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", "inspect", "--format", "{{.Image}}",
+		podman.ServerContainerName)
+	if err != nil {
+		return 0, err
+	}
+	out, err = utils.RunCmdOutput(zerolog.DebugLevel, "podman", "image", "inspect", "--format", "{{.Created.Unix}}",
+		strings.TrimSpace(string(out)))
+	if err != nil {
+		return 0, err
+	}
+	created, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(time.Unix(created, 0)), nil
+}
+
+// lastBackup returns the modification time of the most recent entry of the backup folder.
+func lastBackup(dir string) (time.Time, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var last time.Time
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err == nil && info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+	if last.IsZero() {
+		return last, fmt.Errorf(L("no backup in %s"), dir)
+	}
+	return last, nil
+}
+
+func certificateMetrics(mountPoints map[string]string, now time.Time) []metric {
+	metrics := []metric{}
+	names := []string{"ca", "server"}
+	for _, name := range names {
+		certificate := certificates[name]
+		mountPoint, ok := mountPoints[certificate.volume]
+		if !ok {
+			continue
+		}
+		expiry, err := certificateExpiry(path.Join(mountPoint, certificate.file))
+		if err != nil {
+			log.Debug().Err(err).Msgf("Cannot read the %s certificate", name)
+			continue
+		}
+		metrics = append(metrics, metric{
+			name:   "uyuni_certificate_expiry_days",
+			help:   "Number of days before the certificate expires.",
+			labels: map[string]string{"certificate": name},
+			value:  float64(int(expiry.Sub(now).Hours() / 24)),
+		})
+	}
+	return metrics
+}
+
+func certificateExpiry(file string) (time.Time, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return time.Time{}, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return time.Time{}, fmt.Errorf(L("no certificate found in %s"), file)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
+
+// volumeMetrics reports the usage of the file systems hosting the server volumes.
+func volumeMetrics(mountPoints map[string]string) []metric {
+	metrics := []metric{}
+	for _, volume := range utils.ServerVolumeMounts {
+		mountPoint, ok := mountPoints[volume.Name]
+		if !ok {
+			continue
+		}
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(mountPoint, &stat); err != nil {
+			log.Debug().Err(err).Msgf("Cannot get the file system usage of %s", mountPoint)
+			continue
+		}
+		labels := map[string]string{"volume": volume.Name}
+		metrics = append(metrics,
+			metric{
+				name:   "uyuni_volume_size_bytes",
+				help:   "Size of the file system hosting the volume.",
+				labels: labels,
+				value:  float64(stat.Blocks) * float64(stat.Bsize),
+			},
+			metric{
+				name:   "uyuni_volume_available_bytes",
+				help:   "Available space on the file system hosting the volume.",
+				labels: labels,
+				value:  float64(stat.Bavail) * float64(stat.Bsize),
+			},
+		)
+	}
+	// Group the samples of each metric as required by the text format
+	sort.SliceStable(metrics, func(i, j int) bool { return metrics[i].name > metrics[j].name })
+	return metrics
+}
@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package serve
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	metrics := []metric{
+		{name: "uyuni_server_up", help: "Up.", value: 1},
+		{name: "uyuni_volume_size_bytes", help: "Size.", labels: map[string]string{"volume": "var-pgsql"}, value: 1024},
+		{name: "uyuni_volume_size_bytes", help: "Size.", labels: map[string]string{"volume": "etc-tls"}, value: 2048},
+	}
+	expected := `# HELP uyuni_server_up Up.
+# TYPE uyuni_server_up gauge
+uyuni_server_up 1
+# HELP uyuni_volume_size_bytes Size.
+# TYPE uyuni_volume_size_bytes gauge
+uyuni_volume_size_bytes{volume="var-pgsql"} 1024
+uyuni_volume_size_bytes{volume="etc-tls"} 2048
+`
+	var out bytes.Buffer
+	if err := writeMetrics(&out, metrics); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestLastBackup(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := lastBackup(dir); err == nil {
+		t.Error("expected an error for an empty backup folder")
+	}
+
+	expected := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i, name := range []string{"old", "new"} {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte{}, 0600); err != nil {
+			t.Fatalf("failed to write %s: %s", file, err)
+		}
+		mtime := expected.Add(time.Duration(i-1) * time.Hour)
+		if err := os.Chtimes(file, mtime, mtime); err != nil {
+			t.Fatalf("failed to set %s time: %s", file, err)
+		}
+	}
+
+	last, err := lastBackup(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !last.Equal(expected) {
+		t.Errorf("expected %s, got %s", expected, last)
+	}
+}
+
+func TestWriteTextfile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "uyuni.prom")
+	if err := writeTextfile(file, []metric{{name: "uyuni_server_up", help: "Up.", value: 0}}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := os.Stat(file + ".tmp"); !os.IsNotExist(err) {
+		t.Error("the temporary file should have been renamed")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read %s: %s", file, err)
+	}
+	if !bytes.Contains(data, []byte("uyuni_server_up 0\n")) {
+		t.Errorf("unexpected content: %s", data)
+	}
+}
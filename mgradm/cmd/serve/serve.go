@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package serve
+
+import (
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type metricsFlags struct {
+	Listen    string
+	Textfile  string
+	BackupDir string `mapstructure:"backup-dir"`
+}
+
+type apiFlags struct {
+	Listen    string
+	TokenFile string `mapstructure:"token-file"`
+	TLS       struct {
+		Cert string
+		Key  string
+	}
+	Insecure bool
+}
+
+// NewCommand runs long-lived services exposing the deployment state.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: L("Serve information on the deployment"),
+		Long:  L("Serve information on the deployment"),
+	}
+
+	metricsCmd := &cobra.Command{
+		Use:   "metrics",
+		Short: L("Expose the deployment metrics to Prometheus"),
+		Long: L(`Expose the deployment metrics to Prometheus
+
+The following metrics of the podman deployment are exposed:
+  - whether the server service is running,
+  - age of the running server image,
+  - modification time of the most recent backup in the --backup-dir folder,
+  - number of days before the server and CA certificates expire,
+  - size and available space of the file systems hosting the server volumes.
+
+The metrics are served over HTTP unless --textfile is set: the metrics are then written once
+to the file for the node exporter textfile collector.`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags metricsFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, serveMetrics)
+		},
+	}
+	metricsCmd.Flags().String("listen", "127.0.0.1:9917", L("Address and port to listen on"))
+	metricsCmd.Flags().String("textfile", "", L("Path to the .prom file to write instead of serving the metrics"))
+	metricsCmd.Flags().String("backup-dir", "", L("Folder containing the server backups"))
+
+	apiCmd := &cobra.Command{
+		Use:   "api",
+		Short: L("Expose a REST API to manage the server remotely"),
+		Long: L(`Expose a REST API to manage the server remotely
+
+The requests need to provide the content of the token file as bearer token in the Authorization header.
+The operations run the same commands as the command line and return their machine-readable result:
+  GET /api/v1/status: get the server status,
+  POST /api/v1/upgrade: upgrade the server, optionally to the tag passed as {"tag": "..."}.
+
+Only one upgrade can run at a time.
+
+The API is served over TLS using --tls-cert and --tls-key. To not send the token in clear text
+over the network, serving without TLS requires --insecure and a loopback listen address.`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags apiFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, serveAPI)
+		},
+	}
+	apiCmd.Flags().String("listen", ":9443", L("Address and port to listen on"))
+	apiCmd.Flags().String("token-file", "", L("Path to the file containing the token authenticating the requests"))
+	apiCmd.Flags().String("tls-cert", "", L("Path to the TLS certificate to serve the API with"))
+	apiCmd.Flags().String("tls-key", "", L("Path to the TLS private key to serve the API with"))
+	apiCmd.Flags().Bool("insecure", false,
+		L("Serve the API without TLS, only allowed on a loopback address like 127.0.0.1:9443"))
+	_ = apiCmd.MarkFlagRequired("token-file")
+
+	serveCmd.AddCommand(metricsCmd)
+	serveCmd.AddCommand(apiCmd)
+	return serveCmd
+}
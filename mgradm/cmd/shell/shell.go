@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shell
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type shellFlags struct {
+	Backend   string
+	Namespace string
+}
+
+// rcPath is the bash startup file of the shell sessions inside the container.
+const rcPath = "/root/.mgradm-shellrc"
+
+// historyPath is the file recording the commands of the shell sessions inside the container.
+const historyPath = "/var/log/mgradm-shell-history"
+
+// shellRc sets up the session audit, the aliases and prints the tools available in the container.
+const shellRc = `[ -f ~/.bashrc ] && . ~/.bashrc
+
+# Record every command with its time as soon as it is run
+export HISTFILE=` + historyPath + `
+export HISTTIMEFORMAT="%F %T "
+export HISTFILESIZE=-1
+export HISTSIZE=10000
+shopt -s histappend
+PROMPT_COMMAND="history -a${PROMPT_COMMAND:+; $PROMPT_COMMAND}"
+readonly HISTFILE PROMPT_COMMAND
+printf '#%s\n# session of %s\n' "$(date +%s)" "${MGRADM_SHELL_USER:-unknown}" >> "$HISTFILE"
+
+alias sql='spacewalk-sql -i'
+alias reportdb='spacewalk-sql --reportdb -i'
+alias services='spacewalk-service status'
+alias rhnlogs='cd /var/log/rhn'
+
+cat <<'EOF'
+Uyuni server container shell. Commonly used tools:
+  spacewalk-service {status|start|stop|restart}  manage the Uyuni services
+  mgr-ssl-cert-setup                              deploy new SSL certificates
+  spacewalk-sql                                   run SQL queries on the database
+  salt, salt-run, salt-key                        manage the Salt minions
+  cobbler                                         manage the autoinstallation profiles
+Aliases:
+  sql        interactive session on the server database
+  reportdb   interactive session on the reporting database
+  services   status of the Uyuni services
+  rhnlogs    go to the Uyuni logs folder
+EOF
+echo "The commands of this session are recorded in $HISTFILE"
+`
+
+// NewCommand opens a shell in the server container.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shell",
+		Short: L("Open a root shell in the server container"),
+		Long: L(`Open a root shell in the server container
+
+The shell lists the commonly used tools and provides aliases for them.
+The commands run in the shell are recorded with their time in the container, and the
+session is recorded in the audit journal of the host.`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags shellFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, utils.WithAudit(shell))
+		},
+	}
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(cmd)
+		utils.AddNamespaceFlag(cmd)
+	}
+	return cmd
+}
+
+func shell(globalFlags *types.GlobalFlags, flags *shellFlags, cmd *cobra.Command, args []string) error {
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
+
+	// The startup file is written at each session to get the latest version
+	if _, err := cnx.Exec("sh", "-c", `printf '%s' "$1" > "$0"`, rcPath, shellRc); err != nil {
+		return utils.Errorf(err, L("failed to write the shell startup file: %s"))
+	}
+
+	options := shared.ExecOptions{
+		Interactive: true,
+		Tty:         true,
+		Envs:        []string{"MGRADM_SHELL_USER=" + utils.CurrentUser()},
+	}
+	return cnx.ExecInteractive(options, "bash", "--rcfile", rcPath, "-i")
+}
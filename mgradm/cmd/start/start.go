@@ -5,15 +5,21 @@
 package start
 
 import (
+	"strings"
+
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	shared_podman "github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 type startFlags struct {
-	Backend string
+	Backend   string
+	Namespace string
 }
 
 // NewCommand starts the server.
@@ -21,8 +27,12 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	startCmd := &cobra.Command{
 		Use:   "start",
 		Short: L("Start the server"),
-		Long:  L("Start the server"),
-		Args:  cobra.ExactArgs(0),
+		Long: L(`Start the server
+
+With podman, an unclean shutdown of the server is detected from the leftover database lock files.
+The server is then checked before being declared up: the database volumes are verified, the end of the
+database recovery is awaited and the taskomatic runs interrupted by the shutdown are marked as such.`),
+		Args: cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags startFlags
 			return utils.CommandHelper(globalFlags, cmd, args, &flags, start)
@@ -32,16 +42,37 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 
 	if utils.KubernetesBuilt {
 		utils.AddBackendFlag(startCmd)
+		utils.AddNamespaceFlag(startCmd)
 	}
 
 	return startCmd
 }
 
 func start(globalFlags *types.GlobalFlags, flags *startFlags, cmd *cobra.Command, args []string) error {
-	fn, err := shared.ChoosePodmanOrKubernetes(cmd.Flags(), podmanStart, kubernetesStart)
+	backend, err := shared.FindBackend(cmd.Flags(), shared.ServerApp)
 	if err != nil {
 		return err
 	}
 
-	return fn(globalFlags, flags, cmd, args)
+	if backend.Name() != shared.PodmanBackend {
+		return backend.Start(shared.ServerApp, flags.Namespace)
+	}
+
+	markers := podman.DetectUncleanShutdown()
+	if len(markers) > 0 {
+		log.Warn().Msgf(L("The server was not stopped cleanly, found: %s"), strings.Join(markers, ", "))
+		if err := podman.CheckVolumes(); err != nil {
+			return err
+		}
+	}
+
+	if err := backend.Start(shared.ServerApp, flags.Namespace); err != nil {
+		return err
+	}
+
+	if len(markers) > 0 {
+		cnx := shared.NewConnection("podman", shared_podman.ServerContainerName, "", "")
+		return podman.RecoverServer(cnx)
+	}
+	return nil
 }
@@ -0,0 +1,190 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package status
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+const taskQueueQuery = "SELECT status, COUNT(*) FROM rhnTaskoRun WHERE status IN ('READY', 'RUNNING') GROUP BY status;"
+
+const reposyncQuery = `SELECT r.status, r.end_time FROM rhnTaskoRun r
+JOIN rhnTaskoTemplate t ON r.template_id = t.id
+JOIN rhnTaskoBunch b ON t.bunch_id = b.id
+WHERE b.name = 'repo-sync-bunch' ORDER BY r.start_time DESC LIMIT 1;`
+
+// deepStatus checks the services running inside the server container and prints a report
+// merged with the status of the container itself.
+func deepStatus(cnx *shared.Connection, flags *statusFlags, containerCheck utils.CheckResult) error {
+	checks := []utils.CheckResult{containerCheck}
+	if containerCheck.OK {
+		checks = append(checks,
+			servicesCheck(cnx),
+			databaseCheck(cnx),
+			taskQueueCheck(cnx),
+			reposyncCheck(cnx),
+		)
+		if flags.ConnectionDetails.User != "" {
+			checks = append(checks, lastSyncCheck(&flags.ConnectionDetails))
+		}
+	}
+
+	return utils.ReportChecks(os.Stdout, checks, L("some of the server checks failed"))
+}
+
+// servicesCheck checks that all the services handled by spacewalk-service are active.
+func servicesCheck(cnx *shared.Connection) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Services")}
+	out, err := cnx.Exec("spacewalk-service", "list")
+	if err != nil {
+		check.Detail = fmt.Sprintf(L("failed to list the services: %s"), err)
+		return check
+	}
+	services := strings.Fields(string(out))
+	if len(services) == 0 {
+		check.Detail = L("no service found")
+		return check
+	}
+
+	// systemctl is-active fails if one of the services is not active, but still prints all the states
+	out, _ = cnx.Exec("systemctl", append([]string{"is-active"}, services...)...)
+	states := strings.Fields(string(out))
+	inactive := []string{}
+	for i, service := range services {
+		if i >= len(states) || states[i] != "active" {
+			inactive = append(inactive, service)
+		}
+	}
+	if len(inactive) > 0 {
+		check.Detail = fmt.Sprintf(L("inactive: %s"), strings.Join(inactive, ", "))
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("%d services active"), len(services))
+	return check
+}
+
+func databaseCheck(cnx *shared.Connection) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Database")}
+	if _, err := adm_utils.RunQuery(cnx, "SELECT 1;"); err != nil {
+		check.Detail = fmt.Sprintf(L("database not reachable: %s"), err)
+		return check
+	}
+	check.OK = true
+	check.Detail = L("reachable")
+	return check
+}
+
+func taskQueueCheck(cnx *shared.Connection) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Taskomatic queue")}
+	rows, err := adm_utils.RunQuery(cnx, taskQueueQuery)
+	if err != nil {
+		check.Detail = fmt.Sprintf(L("failed to read the task queue: %s"), err)
+		return check
+	}
+	counts := map[string]string{"READY": "0", "RUNNING": "0"}
+	for _, row := range rows {
+		if len(row) == 2 {
+			counts[row[0]] = row[1]
+		}
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("%[1]s queued, %[2]s running"), counts["READY"], counts["RUNNING"])
+	return check
+}
+
+func reposyncCheck(cnx *shared.Connection) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Last reposync")}
+	rows, err := adm_utils.RunQuery(cnx, reposyncQuery)
+	if err != nil {
+		check.Detail = fmt.Sprintf(L("failed to read the last reposync run: %s"), err)
+		return check
+	}
+	if len(rows) == 0 || len(rows[0]) != 2 {
+		check.OK = true
+		check.Detail = L("never run")
+		return check
+	}
+	check.OK = rows[0][0] != "FAILED" && rows[0][0] != "INTERRUPTED"
+	check.Detail = fmt.Sprintf(L("%[1]s at %[2]s"), rows[0][0], rows[0][1])
+	return check
+}
+
+type channelDetails struct {
+	Label      string `json:"label"`
+	LastSynced string `json:"last_synced"`
+}
+
+// lastSyncCheck uses the API to find the most recently synchronized channel.
+func lastSyncCheck(cnxDetails *api.ConnectionDetails) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Last channel sync")}
+	client, err := api.Init(cnxDetails)
+	if err != nil {
+		check.Detail = fmt.Sprintf(L("failed to connect to the API: %s"), err)
+		return check
+	}
+	channels, err := api.Get[[]channelDetails](client, "channel/listSoftwareChannels")
+	if err != nil || !channels.Success {
+		check.Detail = fmt.Sprintf(L("failed to list the channels: %s"), apiError(err, channels))
+		return check
+	}
+
+	details := []channelDetails{}
+	for _, channel := range channels.Result {
+		res, err := api.Get[channelDetails](client, "channel/software/getDetails?channelLabel="+url.QueryEscape(channel.Label))
+		if err != nil || !res.Success {
+			log.Debug().Msgf("Failed to get %s channel details: %s", channel.Label, apiError(err, res))
+			continue
+		}
+		details = append(details, res.Result)
+	}
+
+	check.OK = true
+	if latest := latestSync(details); latest != nil {
+		check.Detail = fmt.Sprintf(L("%[1]s at %[2]s"), latest.Label, latest.LastSynced)
+	} else {
+		check.Detail = L("no channel synchronized")
+	}
+	return check
+}
+
+func apiError[T interface{}](err error, res *api.ApiResponse[T]) string {
+	if err != nil {
+		return err.Error()
+	}
+	return res.Message
+}
+
+// latestSync returns the most recently synchronized channel or nil if none has been synchronized.
+func latestSync(channels []channelDetails) *channelDetails {
+	var latest *channelDetails
+	var latestTime time.Time
+	for i, channel := range channels {
+		if channel.LastSynced == "" {
+			continue
+		}
+		synced, err := time.Parse(time.RFC3339, channel.LastSynced)
+		if err != nil {
+			log.Debug().Msgf("Failed to parse %s channel sync date %s: %s", channel.Label, channel.LastSynced, err)
+			continue
+		}
+		if latest == nil || synced.After(latestTime) {
+			latest = &channels[i]
+			latestTime = synced
+		}
+	}
+	return latest
+}
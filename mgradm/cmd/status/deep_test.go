@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package status
+
+import (
+	"testing"
+)
+
+func TestLatestSync(t *testing.T) {
+	channels := []channelDetails{
+		{Label: "never-synced"},
+		{Label: "old", LastSynced: "2024-03-01T10:00:00Z"},
+		{Label: "recent", LastSynced: "2024-04-01T10:00:00Z"},
+		{Label: "invalid", LastSynced: "yesterday"},
+	}
+	if latest := latestSync(channels); latest == nil || latest.Label != "recent" {
+		t.Errorf("expected recent channel, got %v", latest)
+	}
+	if latest := latestSync(channels[:1]); latest != nil {
+		t.Errorf("expected no channel, got %v", latest)
+	}
+}
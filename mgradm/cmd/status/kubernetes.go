@@ -18,6 +18,7 @@ import (
 	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 func kubernetesStatus(
@@ -29,7 +30,7 @@ func kubernetesStatus(
 	// Do we have an uyuni helm release?
 	clusterInfos, err := kubernetes.CheckCluster()
 	if err != nil {
-		return fmt.Errorf(L("failed to discover the cluster type: %s"), err)
+		return utils.Errorf(err, L("failed to discover the cluster type: %s"))
 	}
 
 	kubeconfig := clusterInfos.GetKubeconfig()
@@ -37,28 +38,83 @@ func kubernetesStatus(
 		return errors.New(L("no uyuni helm release installed on the cluster"))
 	}
 
-	namespace, err := kubernetes.FindNamespace("uyuni", kubeconfig)
+	namespace := flags.Namespace
+	if namespace == "" {
+		namespace, err = kubernetes.FindNamespace("uyuni", kubeconfig)
+	}
 	if err != nil {
-		return fmt.Errorf(L("failed to find the uyuni deployment namespace: %s"), err)
+		return utils.Errorf(err, L("failed to find the uyuni deployment namespace: %s"))
+	}
+
+	printHelmRelease(kubeconfig, namespace)
+
+	if state, err := kubernetes.ReadState(namespace); err != nil {
+		log.Warn().Err(err).Msg(L("Failed to read the deployment state"))
+	} else {
+		utils.PrintDeploymentState(state)
 	}
 
 	// Is the pod running? Do we have all the replicas?
 	status, err := kubernetes.GetDeploymentStatus(namespace, "uyuni")
 	if err != nil {
-		return fmt.Errorf(L("failed to get deployment status: %s"), err)
+		return utils.Errorf(err, L("failed to get deployment status: %s"))
 	}
 	if status.Replicas != status.ReadyReplicas {
 		log.Warn().Msgf(L("Some replicas are not ready: %d / %d"), status.ReadyReplicas, status.Replicas)
 	}
 
+	cnx := shared.NewConnection("kubectl", "", kubernetes.ServerFilter, namespace)
+	if flags.Deep {
+		return deepStatus(cnx, flags, utils.CheckResult{
+			Name:   L("Pod"),
+			OK:     status.AvailableReplicas > 0,
+			Detail: fmt.Sprintf(L("%[1]d / %[2]d replicas ready"), status.ReadyReplicas, status.Replicas),
+		})
+	}
+
 	if status.AvailableReplicas == 0 {
 		return errors.New(L("the pod is not running"))
 	}
 
 	// Are the services running in the container?
-	cnx := shared.NewConnection("kubectl", "", kubernetes.ServerFilter)
 	if err := adm_utils.ExecCommand(zerolog.InfoLevel, cnx, "spacewalk-service", "status"); err != nil {
-		return fmt.Errorf(L("failed to run spacewalk-service status: %s"), err)
+		return utils.Errorf(err, L("failed to run spacewalk-service status: %s"))
 	}
 	return nil
 }
+
+// printHelmRelease shows the uyuni helm release revision and the values changed since the previous one.
+func printHelmRelease(kubeconfig string, namespace string) {
+	release, err := kubernetes.GetHelmRelease(kubeconfig, namespace, "uyuni")
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Failed to get the helm release"))
+		return
+	}
+	fmt.Printf(L("Helm chart: %s")+"\n", release.Chart)
+	fmt.Printf(L("Helm release revision: %[1]d (%[2]s)")+"\n", release.Revision, release.Status)
+	fmt.Printf(L("Helm release updated at: %s")+"\n", release.Updated)
+
+	revision := release.Revision
+	if revision < 2 {
+		return
+	}
+	previousValues, err := kubernetes.GetHelmValues(kubeconfig, namespace, "uyuni", revision-1)
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Failed to get the previous helm release values"))
+		return
+	}
+	values, err := kubernetes.GetHelmValues(kubeconfig, namespace, "uyuni", revision)
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Failed to get the helm release values"))
+		return
+	}
+	changes := kubernetes.DiffHelmValues(previousValues, values)
+	if len(changes) == 0 {
+		fmt.Println(L("No helm value changed since the previous revision"))
+		return
+	}
+	fmt.Println(L("Helm values changed since the previous revision:"))
+	for _, change := range changes {
+		fmt.Println("  " + change)
+	}
+}
@@ -5,9 +5,8 @@
 package status
 
 import (
-	"fmt"
-
 	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
 	"github.com/uyuni-project/uyuni-tools/shared"
@@ -25,21 +24,43 @@ func podmanStatus(
 ) error {
 	// Show the status and that's it if the service is not running
 	if !podman.IsServiceRunning(podman.ServerService) {
-		if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "systemctl", "status", "--no-pager", podman.ServerService); err != nil {
-			return fmt.Errorf(L("failed to get status of the server service: %s"), err)
+		if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "systemctl", podman.SystemctlArgs("status", "--no-pager", podman.ServerService)...); err != nil {
+			return utils.Errorf(err, L("failed to get status of the server service: %s"))
 		}
 		return nil
 	}
 
+	// The deployment state file is on the server host: it can't be read from a remote one
+	if podman.IsRemote() {
+		log.Debug().Msg("Not reading the deployment state on a remote podman host")
+	} else if state, err := podman.ReadState(); err != nil {
+		log.Warn().Err(err).Msg(L("Failed to read the deployment state"))
+	} else {
+		utils.PrintDeploymentState(state)
+	}
+
+	cnx := shared.NewConnection("podman", podman.ServerContainerName, "", "")
+	if flags.Deep {
+		return deepStatus(cnx, flags, utils.CheckResult{Name: L("Systemd service"), OK: true, Detail: L("running")})
+	}
+
 	// Run spacewalk-service status in the container
-	cnx := shared.NewConnection("podman", podman.ServerContainerName, "")
 	if err := adm_utils.ExecCommand(zerolog.InfoLevel, cnx, "spacewalk-service", "status"); err != nil {
-		return fmt.Errorf(L("failed to run spacewalk-service status: %s"), err)
+		return utils.Errorf(err, L("failed to run spacewalk-service status: %s"))
+	}
+
+	for _, service := range podman.ComponentServices() {
+		if !podman.IsServiceRunning(service) {
+			if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "systemctl", podman.SystemctlArgs("status", service)...); err != nil {
+				return utils.Errorf(err, L("failed to get status of the %[1]s service: %[2]s"), service)
+			}
+			return nil
+		}
 	}
 
-	if !podman.IsServiceRunning(podman.ServerAttestationService) {
-		if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "systemctl", "status", podman.ServerAttestationService); err != nil {
-			return fmt.Errorf(L("failed to get status of the server service: %s"), err)
+	if podman.HasService(podman.ServerReportDbService) && !podman.IsServiceRunning(podman.ServerReportDbService) {
+		if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "systemctl", podman.SystemctlArgs("status", podman.ServerReportDbService)...); err != nil {
+			return utils.Errorf(err, L("failed to get status of the report database service: %s"))
 		}
 		return nil
 	}
@@ -7,7 +7,9 @@ package status
 import (
 	"errors"
 
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
@@ -15,15 +17,19 @@ import (
 )
 
 type statusFlags struct {
+	Namespace         string
+	Deep              bool
+	ConnectionDetails api.ConnectionDetails `mapstructure:"api"`
 }
 
 // NewCommand to get the status of the server.
 func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "status",
-		Short: L("Get the server status"),
-		Long:  L("Get the server status"),
-		Args:  cobra.ExactArgs(0),
+		Use:         "status",
+		Short:       L("Get the server status"),
+		Long:        L("Get the server status"),
+		Args:        cobra.ExactArgs(0),
+		Annotations: map[string]string{utils.ReadOnlyAnnotation: "true", podman.RemoteAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags statusFlags
 			return utils.CommandHelper(globalFlags, cmd, args, &flags, status)
@@ -31,6 +37,16 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	}
 	cmd.SetUsageTemplate(cmd.UsageTemplate())
 
+	if utils.KubernetesBuilt {
+		utils.AddNamespaceFlag(cmd)
+	}
+
+	cmd.Flags().Bool("deep", false,
+		L("also check the services, database, task queue and last reposync inside the server container"))
+	if err := api.AddAPIFlags(cmd, true); err != nil {
+		log.Fatal().Err(err).Msg(L("failed to add the API flags"))
+	}
+
 	return cmd
 }
 
@@ -39,7 +55,7 @@ func status(globalFlags *types.GlobalFlags, flags *statusFlags, cmd *cobra.Comma
 		return podmanStatus(globalFlags, flags, cmd, args)
 	}
 
-	if utils.IsInstalled("kubectl") && utils.IsInstalled("helm") {
+	if utils.IsInstalled("kubectl") {
 		return kubernetesStatus(globalFlags, flags, cmd, args)
 	}
 
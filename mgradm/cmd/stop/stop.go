@@ -6,14 +6,20 @@ package stop
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
 	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 type stopFlags struct {
-	Backend string
+	Backend   string
+	Namespace string
+	Force     bool
+	Timeout   int
 }
 
 // NewCommand to stop server.
@@ -21,8 +27,13 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	stopCmd := &cobra.Command{
 		Use:   "stop",
 		Short: L("Stop the server"),
-		Long:  L("Stop the server"),
-		Args:  cobra.ExactArgs(0),
+		Long: L(`Stop the server
+
+With podman, the services of the server are stopped in order before the container
+to ensure a clean shutdown of the database.
+On Kubernetes, the server is not stopped while repository synchronizations or actions
+are running unless --force is passed.`),
+		Args: cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags stopFlags
 			return utils.CommandHelper(globalFlags, cmd, args, &flags, stop)
@@ -31,18 +42,33 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 
 	stopCmd.SetUsageTemplate(stopCmd.UsageTemplate())
 
+	stopCmd.Flags().Int("timeout", 120, L("Maximum time in seconds to wait for each service of the server to stop"))
+
 	if utils.KubernetesBuilt {
 		utils.AddBackendFlag(stopCmd)
+		utils.AddNamespaceFlag(stopCmd)
+		stopCmd.Flags().Bool("force", false, L("Stop the server even if operations are running on it"))
 	}
 
 	return stopCmd
 }
 
 func stop(globalFlags *types.GlobalFlags, flags *stopFlags, cmd *cobra.Command, args []string) error {
-	fn, err := shared.ChoosePodmanOrKubernetes(cmd.Flags(), podmanStop, kubernetesStop)
+	backend, err := shared.FindBackend(cmd.Flags(), shared.ServerApp)
 	if err != nil {
 		return err
 	}
 
-	return fn(globalFlags, flags, cmd, args)
+	if backend.Name() == shared.KubernetesBackend {
+		cnx := shared.NewConnection("kubectl", "", kubernetes.ServerFilter, flags.Namespace)
+		if err := adm_utils.CheckServerActivity(cnx, flags.Force); err != nil {
+			return err
+		}
+	}
+
+	if backend.Name() == shared.PodmanBackend {
+		podman.StopServerServices(flags.Timeout)
+	}
+
+	return backend.Stop(shared.ServerApp, flags.Namespace)
 }
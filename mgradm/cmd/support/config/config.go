@@ -12,8 +12,9 @@ import (
 )
 
 type configFlags struct {
-	Output  string
-	Backend string
+	Output    string
+	Backend   string
+	Namespace string
 }
 
 // NewCommand is the command for creates supportconfig.
@@ -31,6 +32,7 @@ the containers for support to help debugging.`),
 
 	configCmd.Flags().StringP("output", "o", "supportconfig.tar.gz", L("path where to extract the data"))
 	utils.AddBackendFlag(configCmd)
+	utils.AddNamespaceFlag(configCmd)
 
 	return configCmd
 }
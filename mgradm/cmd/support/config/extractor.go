@@ -24,12 +24,12 @@ import (
 )
 
 func extract(globalFlags *types.GlobalFlags, flags *configFlags, cmd *cobra.Command, args []string) error {
-	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter)
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
 
 	// Copy the generated file locally
 	tmpDir, err := os.MkdirTemp("", "mgradm-*")
 	if err != nil {
-		return fmt.Errorf(L("failed to create temporary directory: %s"), err)
+		return utils.Errorf(err, L("failed to create temporary directory: %s"))
 	}
 	defer os.RemoveAll(tmpDir)
 
@@ -51,13 +51,13 @@ func extract(globalFlags *types.GlobalFlags, flags *configFlags, cmd *cobra.Comm
 		for _, ext := range extensions {
 			containerTarball := path.Join(tmpDir, "container-supportconfig.txz"+ext)
 			if err := cnx.Copy("server:"+tarballPath+ext, containerTarball, "", ""); err != nil {
-				return fmt.Errorf(L("cannot copy tarball: %s"), err)
+				return utils.Errorf(err, L("cannot copy tarball: %s"))
 			}
 			files = append(files, containerTarball)
 
 			// Remove the generated file in the container
 			if _, err := cnx.Exec("rm", tarballPath+ext); err != nil {
-				return fmt.Errorf(L("failed to remove %s%s file in the container: %s"), tarballPath, ext, err)
+				return utils.Errorf(err, L("failed to remove %s%s file in the container: %s"), tarballPath, ext)
 			}
 		}
 	}
@@ -66,7 +66,7 @@ func extract(globalFlags *types.GlobalFlags, flags *configFlags, cmd *cobra.Comm
 	if _, err := exec.LookPath("supportconfig"); err == nil {
 		out, err := utils.RunCmdOutput(zerolog.DebugLevel, "supportconfig")
 		if err != nil {
-			return fmt.Errorf(L("failed to run supportconfig on the host: %s"), err)
+			return utils.Errorf(err, L("failed to run supportconfig on the host: %s"))
 		}
 		tarballPath := getSupportConfigPath(out)
 
@@ -82,6 +82,22 @@ func extract(globalFlags *types.GlobalFlags, flags *configFlags, cmd *cobra.Comm
 		log.Warn().Msg(L("supportconfig is not available on the host, skipping it"))
 	}
 
+	// Add the tool logs with the transcript of the executed commands
+	if logFile := utils.GetLogFilePath(); logFile != "" && utils.FileExists(logFile) {
+		files = append(files, logFile)
+	}
+
+	// Add the deployment state
+	if command, _ := cnx.GetCommand(); command == "kubectl" {
+		if stateFile, err := dumpKubernetesState(cnx, tmpDir); err != nil {
+			log.Warn().Err(err).Msg(L("Failed to read the deployment state"))
+		} else if stateFile != "" {
+			files = append(files, stateFile)
+		}
+	} else if utils.FileExists(utils.StateFilePath) {
+		files = append(files, utils.StateFilePath)
+	}
+
 	// TODO Get cluster infos in case of kubernetes
 
 	// Pack it all into a tarball
@@ -93,7 +109,7 @@ func extract(globalFlags *types.GlobalFlags, flags *configFlags, cmd *cobra.Comm
 
 	for _, file := range files {
 		if err := tarball.AddFile(file, path.Base(file)); err != nil {
-			return fmt.Errorf(L("failed to add %s to tarball: %s"), path.Base(file), err)
+			return utils.Errorf(err, L("failed to add %s to tarball: %s"), path.Base(file))
 		}
 	}
 	tarball.Close()
@@ -101,6 +117,25 @@ func extract(globalFlags *types.GlobalFlags, flags *configFlags, cmd *cobra.Comm
 	return nil
 }
 
+// dumpKubernetesState writes the deployment state ConfigMap content in a file.
+//
+// Returns the path to the written file or an empty string if there is no state.
+func dumpKubernetesState(cnx *shared.Connection, tmpDir string) (string, error) {
+	namespace, err := cnx.GetNamespace()
+	if err != nil {
+		return "", err
+	}
+	state, err := kubernetes.ReadState(namespace)
+	if err != nil || state == nil {
+		return "", err
+	}
+	stateFile := path.Join(tmpDir, path.Base(utils.StateFilePath))
+	if err := utils.WriteStateFile(stateFile, state); err != nil {
+		return "", err
+	}
+	return stateFile, nil
+}
+
 func getSupportConfigPath(out []byte) string {
 	re := regexp.MustCompile(`/var/log/scc_[^.]+\.txz`)
 	return re.FindString(string(out))
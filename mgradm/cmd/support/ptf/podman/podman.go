@@ -20,6 +20,10 @@ type podmanPTFFlags struct {
 	CustomerId string           `mapstructure:"user"`
 }
 
+type podmanPTFRemoveFlags struct {
+	Image types.ImageFlags `mapstructure:",squash"`
+}
+
 // NewCommand for podman installation.
 func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	podmanCmd := &cobra.Command{
@@ -31,17 +35,34 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 The support ptf podman command assumes podman is installed locally and
 the host machine is register to SCC.
 
+The image of the running server is recorded before installing the first PTF
+to be able to go back to it using the support ptf podman remove command.
+
 NOTE: for now installing on a remote podman is not supported!
 `),
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags podmanPTFFlags
-			return utils.CommandHelper(globalFlags, cmd, args, &flags, ptfForPodman)
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, utils.WithLock(ptfForPodman))
 		},
 	}
 
 	mgradm_utils.AddImagePTFlag(podmanCmd)
 	utils.AddPTFFlag(podmanCmd)
+	utils.AddLockFlag(podmanCmd)
+
+	removeCmd := &cobra.Command{
+		Use:   "remove",
+		Short: L("Revert the server to the image used before installing the PTF"),
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags podmanPTFRemoveFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, utils.WithLock(ptfRemoveForPodman))
+		},
+	}
+	utils.AddPullPolicyFlag(removeCmd)
+	utils.AddLockFlag(removeCmd)
+	podmanCmd.AddCommand(removeCmd)
 
 	return podmanCmd
 }
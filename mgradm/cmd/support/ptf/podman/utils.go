@@ -7,16 +7,24 @@ package podman
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	podman_shared "github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
+// originalImagePath is the file recording the server image used before installing a PTF.
+var originalImagePath = "/etc/uyuni/ptf-original-image"
+
 func ptfForPodman(
 	globalFlags *types.GlobalFlags,
 	flags *podmanPTFFlags,
@@ -26,13 +34,46 @@ func ptfForPodman(
 	//we don't want to perform a postgres version upgrade when installing a PTF.
 	//in that case, we can use the upgrade command.
 	dummyMigration := types.ImageFlags{}
-	if err := flags.checkParameters(); err != nil {
+	serverImage, err := podman_shared.GetRunningImage(podman_shared.ServerContainerName)
+	if err != nil {
+		return err
+	}
+	serverImage = strings.Trim(serverImage, "'")
+	if serverImage == "" {
+		return errors.New(L("the server container is not running"))
+	}
+	if err := flags.checkParameters(serverImage); err != nil {
+		return err
+	}
+	if err := recordOriginalImage(serverImage); err != nil {
 		return err
 	}
-	return podman.Upgrade(flags.Image, dummyMigration, args)
+	return podman.Upgrade(flags.Image, dummyMigration, false, adm_utils.DefaultPgsqlUpgradeFlags, args)
 }
 
-func (flags *podmanPTFFlags) checkParameters() error {
+func ptfRemoveForPodman(
+	globalFlags *types.GlobalFlags,
+	flags *podmanPTFRemoveFlags,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	image, err := readOriginalImage()
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Reverting the server to image %s"), image)
+
+	flags.Image.Name = image
+	if err := podman.Upgrade(flags.Image, types.ImageFlags{}, false, adm_utils.DefaultPgsqlUpgradeFlags, args); err != nil {
+		return err
+	}
+	if err := os.Remove(originalImagePath); err != nil {
+		return utils.Errorf(err, L("failed to remove %s: %s"), originalImagePath)
+	}
+	return nil
+}
+
+func (flags *podmanPTFFlags) checkParameters(serverImage string) error {
 	if flags.TestId != "" && flags.PTFId != "" {
 		return errors.New(L("ptf and test flags cannot be set simultaneously "))
 	}
@@ -42,19 +83,47 @@ func (flags *podmanPTFFlags) checkParameters() error {
 	if flags.CustomerId == "" {
 		return errors.New(L("user flag cannot be empty"))
 	}
-	serverImage, err := podman_shared.GetRunningImage(podman_shared.ServerContainerName)
-	if err != nil {
-		return err
-	}
 
 	suffix := "ptf"
+	id := flags.PTFId
 	if flags.TestId != "" {
 		suffix = "test"
+		id = flags.TestId
 	}
-	flags.Image.Name, err = utils.ComputePTF(flags.CustomerId, flags.PTFId, serverImage, suffix)
+	var err error
+	flags.Image.Name, err = utils.ComputePTF(flags.CustomerId, id, serverImage, suffix)
 	if err != nil {
 		return err
 	}
 	log.Info().Msgf(L("The image computed is: %s"), flags.Image.Name)
 	return nil
 }
+
+// recordOriginalImage writes the image to go back to when removing the PTF.
+//
+// The first recorded image is kept if a PTF is installed on top of another one.
+func recordOriginalImage(image string) error {
+	if utils.FileExists(originalImagePath) {
+		return nil
+	}
+	if err := os.MkdirAll(path.Dir(originalImagePath), 0755); err != nil {
+		return utils.Errorf(err, L("failed to create %s folder: %s"), path.Dir(originalImagePath))
+	}
+	if err := os.WriteFile(originalImagePath, []byte(image+"\n"), 0644); err != nil {
+		return utils.Errorf(err, L("cannot write %s file: %s"), originalImagePath)
+	}
+	log.Info().Msgf(L("Original image %s recorded in %s"), image, originalImagePath)
+	return nil
+}
+
+// readOriginalImage returns the image recorded before installing the PTF.
+func readOriginalImage() (string, error) {
+	if !utils.FileExists(originalImagePath) {
+		return "", errors.New(L("no PTF installed: original image record not found"))
+	}
+	image := strings.TrimSpace(string(utils.ReadFile(originalImagePath)))
+	if image == "" {
+		return "", fmt.Errorf(L("no image recorded in %s"), originalImagePath)
+	}
+	return image, nil
+}
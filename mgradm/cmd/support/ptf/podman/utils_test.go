@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+//go:build ptf
+
+package podman
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+const serverImage = "registry.suse.com/suse/manager/5.0/x86_64/server:5.0.0"
+
+func TestCheckParameters(t *testing.T) {
+	data := []struct {
+		flags    podmanPTFFlags
+		expected string
+	}{
+		{podmanPTFFlags{PTFId: "27977", CustomerId: "200000"},
+			"registry.suse.com/a/200000/27977/suse/manager/5.0/x86_64/server:latest-ptf-27977"},
+		{podmanPTFFlags{TestId: "1234", CustomerId: "200000"},
+			"registry.suse.com/a/200000/1234/suse/manager/5.0/x86_64/server:latest-test-1234"},
+	}
+	for i, test := range data {
+		if err := test.flags.checkParameters(serverImage); err != nil {
+			t.Errorf("case %d: unexpected error: %s", i, err)
+		} else if test.flags.Image.Name != test.expected {
+			t.Errorf("case %d: expected %s image, got %s", i, test.expected, test.flags.Image.Name)
+		}
+	}
+
+	invalid := []podmanPTFFlags{
+		{PTFId: "27977", TestId: "1234", CustomerId: "200000"},
+		{CustomerId: "200000"},
+		{PTFId: "27977"},
+	}
+	for i, flags := range invalid {
+		if err := flags.checkParameters(serverImage); err == nil {
+			t.Errorf("case %d: expected an error", i)
+		}
+	}
+}
+
+func TestOriginalImage(t *testing.T) {
+	previous := originalImagePath
+	originalImagePath = path.Join(t.TempDir(), "uyuni", "ptf-original-image")
+	t.Cleanup(func() {
+		originalImagePath = previous
+	})
+
+	if _, err := readOriginalImage(); err == nil {
+		t.Error("expected an error without recorded image")
+	}
+
+	if err := recordOriginalImage(serverImage); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// A PTF installed on top of another one keeps the first image
+	if err := recordOriginalImage("registry.suse.com/a/200000/27977/suse/manager/5.0/x86_64/server:latest-ptf-27977"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if image, err := readOriginalImage(); err != nil || image != serverImage {
+		t.Errorf("expected %s image, got %s: %v", serverImage, image, err)
+	}
+
+	if err := os.WriteFile(originalImagePath, []byte("\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readOriginalImage(); err == nil {
+		t.Error("expected an error for an empty record")
+	}
+}
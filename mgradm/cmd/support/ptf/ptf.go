@@ -21,6 +21,7 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	}
 
 	utils.AddBackendFlag(ptfCmd)
+	utils.AddNamespaceFlag(ptfCmd)
 
 	ptfCmd.AddCommand(podman.NewCommand(globalFlags))
 
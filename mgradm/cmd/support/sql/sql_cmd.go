@@ -17,6 +17,7 @@ type configFlags struct {
 	ForceOverwrite bool   `mapstructure:"force"`
 	OutputFile     string `mapstructure:"output"`
 	Backend        string
+	Namespace      string
 }
 
 // Add support sql command.
@@ -52,6 +53,7 @@ Examples:
 	configCmd.Flags().BoolP("force", "f", false, L("Force overwrite of output file if already exists"))
 	configCmd.Flags().StringP("output", "o", "", L("Write output to the file instead of standard output"))
 	utils.AddBackendFlag(configCmd)
+	utils.AddNamespaceFlag(configCmd)
 
 	return configCmd
 }
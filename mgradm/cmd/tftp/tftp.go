@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tftp
+
+import (
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type pushFlags struct {
+	Backend           string
+	Namespace         string
+	SshUser           string `mapstructure:"ssh-user"`
+	VerifyOnly        bool   `mapstructure:"verify-only"`
+	utils.OutputFlags `mapstructure:",squash"`
+}
+
+// NewCommand for the commands handling the TFTP boot files of the proxies.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	tftpCmd := &cobra.Command{
+		Use:   "tftp",
+		Short: L("Manage the TFTP boot files of the proxies"),
+		Long:  L("Manage the TFTP boot files of the proxies"),
+	}
+
+	pushCmd := &cobra.Command{
+		Use:   "push proxy...",
+		Short: L("Synchronize the TFTP boot files to proxies"),
+		Long: L(`Synchronize the TFTP boot files to proxies
+
+The synchronization is triggered on the server for all the proxies configured for TFTP synchronization.
+The boot files of the given proxies are then compared with the server ones using podman over SSH:
+the podman socket needs to be enabled on the proxy hosts and the SSH user needs to be able to connect
+without password.`),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags pushFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, utils.WithAudit(push))
+		},
+	}
+	pushCmd.Flags().String("ssh-user", "root", L("User to connect to the proxy hosts with SSH"))
+	pushCmd.Flags().Bool("verify-only", false, L("Only compare the boot files without triggering the synchronization"))
+	utils.AddOutputFlag(pushCmd)
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(pushCmd)
+		utils.AddNamespaceFlag(pushCmd)
+	}
+
+	tftpCmd.AddCommand(pushCmd)
+	return tftpCmd
+}
+
+func push(globalFlags *types.GlobalFlags, flags *pushFlags, cmd *cobra.Command, args []string) error {
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
+	if !flags.VerifyOnly {
+		log.Info().Msg(L("Synchronizing the TFTP boot files to the proxies"))
+		if _, err := cnx.Exec("cobbler", "sync"); err != nil {
+			return utils.Errorf(err, L("failed to synchronize the TFTP boot files: %s"))
+		}
+	}
+
+	out, err := cnx.Exec(utils.TftpChecksumsArgs[0], utils.TftpChecksumsArgs[1:]...)
+	if err != nil {
+		return utils.Errorf(err, L("failed to compute the checksums of the server TFTP boot files: %s"))
+	}
+	serverChecksums := utils.ParseChecksums(out)
+
+	reports := []*utils.TftpReport{}
+	for _, proxy := range args {
+		sshHost := proxy
+		if !strings.Contains(proxy, "@") {
+			sshHost = flags.SshUser + "@" + proxy
+		}
+		cmdArgs := append([]string{"--url", podman.RemoteURL(sshHost), "exec", "uyuni-proxy-httpd"},
+			utils.TftpChecksumsArgs...)
+		out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", cmdArgs...)
+		if err != nil {
+			return utils.Errorf(err, L("failed to compute the checksums of the TFTP boot files on %[1]s: %[2]s"), proxy)
+		}
+		report := utils.CompareTftpChecksums(serverChecksums, utils.ParseChecksums(out))
+		report.Proxy = proxy
+		reports = append(reports, report)
+	}
+	return utils.PrintTftpReports(reports, flags.Output)
+}
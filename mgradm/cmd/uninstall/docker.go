@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build docker
+
+package uninstall
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/docker"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func init() {
+	backendFuncs[shared.DockerBackend] = uninstallForDocker
+}
+
+func uninstallForDocker(
+	globalFlags *types.GlobalFlags,
+	flags *uninstallFlags,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	// The docker server container runs in the same systemd service as with podman
+	podman.UninstallService(podman.ServerService, !flags.Force)
+	docker.DeleteContainer(docker.ServerContainerName, !flags.Force)
+
+	if flags.Purge.Volumes {
+		for _, volume := range utils.ServerVolumeMounts {
+			if err := docker.DeleteVolume(volume.Name, !flags.Force); err != nil {
+				return utils.Errorf(err, L("cannot delete volume %s: %s"), volume.Name)
+			}
+		}
+		log.Info().Msg(L("All volumes removed"))
+	}
+
+	docker.DeleteNetwork(!flags.Force)
+
+	return podman.ReloadDaemon(!flags.Force)
+}
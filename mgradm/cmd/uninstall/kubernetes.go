@@ -33,14 +33,14 @@ func uninstallForKubernetes(
 	// TODO Find all the PVs related to the server if we want to delete them
 
 	// Uninstall uyuni
-	namespace, err := kubernetes.HelmUninstall(kubeconfig, "uyuni", "", !flags.Force)
+	namespace, err := kubernetes.HelmUninstall(kubeconfig, flags.Namespace, "uyuni", "", !flags.Force)
 	if err != nil {
 		return err
 	}
 
 	// Remove the remaining configmap and secrets
 	if namespace != "" {
-		_, err := utils.RunCmdOutput(zerolog.TraceLevel, "kubectl", "-n", namespace, "get", "secret", "uyuni-ca")
+		_, err := utils.RunCmdOutput(zerolog.TraceLevel, "kubectl", kubernetes.KubectlArgs("-n", namespace, "get", "secret", "uyuni-ca")...)
 		caSecret := "uyuni-ca"
 		if err != nil {
 			caSecret = ""
@@ -51,7 +51,7 @@ func uninstallForKubernetes(
 			log.Info().Msgf(L("Would run %s"), fmt.Sprintf("kubectl delete -n %s secret uyuni-cert %s", namespace, caSecret))
 		} else {
 			log.Info().Msgf(L("Running %s"), fmt.Sprintf("kubectl delete -n %s configmap uyuni-ca", namespace))
-			if err := utils.RunCmd("kubectl", "delete", "-n", namespace, "configmap", "uyuni-ca"); err != nil {
+			if err := utils.RunCmd("kubectl", kubernetes.KubectlArgs("delete", "-n", namespace, "configmap", "uyuni-ca")...); err != nil {
 				log.Info().Err(err).Msgf(L("Failed deleting config map"))
 			}
 
@@ -61,7 +61,7 @@ func uninstallForKubernetes(
 			if caSecret != "" {
 				args = append(args, caSecret)
 			}
-			err := utils.RunCmd("kubectl", args...)
+			err := utils.RunCmd("kubectl", kubernetes.KubectlArgs(args...)...)
 			if err != nil {
 				log.Info().Err(err).Msgf(L("Failed deleting secret"))
 			}
@@ -73,7 +73,7 @@ func uninstallForKubernetes(
 	// Since some storage plugins don't handle Delete policy, we may need to check for error events to avoid infinite loop
 
 	// Uninstall cert-manager if we installed it
-	if _, err := kubernetes.HelmUninstall(kubeconfig, "cert-manager", "-linstalledby=mgradm", !flags.Force); err != nil {
+	if _, err := kubernetes.HelmUninstall(kubeconfig, "", "cert-manager", "-linstalledby=mgradm", !flags.Force); err != nil {
 		return err
 	}
 
@@ -5,8 +5,6 @@
 package uninstall
 
 import (
-	"fmt"
-
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
@@ -26,20 +24,39 @@ func uninstallForPodman(
 	// Force stop the pod
 	podman.DeleteContainer(podman.ServerContainerName, !flags.Force)
 
+	// The attestation services generated by older versions are not marked as components
 	if podman.HasService(podman.ServerAttestationService) {
 		podman.UninstallService(podman.ServerAttestationService, !flags.Force)
 		podman.DeleteContainer(podman.ServerAttestationService, !flags.Force)
 	}
 
+	if podman.HasService(podman.ServerBillingAdapterService) {
+		podman.UninstallService(podman.ServerBillingAdapterService, !flags.Force)
+		podman.DeleteContainer(podman.ServerBillingAdapterService, !flags.Force)
+	}
+
+	for _, service := range podman.ComponentServices() {
+		podman.UninstallService(service, !flags.Force)
+		podman.DeleteContainer(service, !flags.Force)
+	}
+
+	if podman.HasService(podman.ServerReportDbService) {
+		podman.UninstallService(podman.ServerReportDbService, !flags.Force)
+		podman.DeleteContainer(podman.ServerReportDbService, !flags.Force)
+	}
+
 	// Remove the volumes
-	if flags.PurgeVolumes {
-		volumes := []string{"cgroup"}
+	if flags.Purge.Volumes {
+		volumes := []string{
+			"cgroup", utils.ReportDbVolumeMount.Name, utils.RunSaltMasterVolumeMount.Name,
+			utils.BillingAdapterVolumeMount.Name,
+		}
 		for _, volume := range utils.ServerVolumeMounts {
 			volumes = append(volumes, volume.Name)
 		}
 		for _, volume := range volumes {
 			if err := podman.DeleteVolume(volume, !flags.Force); err != nil {
-				return fmt.Errorf(L("cannot delete volume %s: %s"), volume, err)
+				return utils.Errorf(err, L("cannot delete volume %s: %s"), volume)
 			}
 		}
 		log.Info().Msg(L("All volumes removed"))
@@ -13,10 +13,27 @@ import (
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
+// backendFuncs are the implementations of the command for each backend.
+var backendFuncs = map[string]utils.CommandFunc[uninstallFlags]{
+	shared.PodmanBackend:     uninstallForPodman,
+	shared.KubernetesBackend: kubernetes.WithLease(deploymentNamespace, uninstallForKubernetes),
+}
+
+// deploymentNamespace returns the namespace of the server deployment to lock on kubernetes.
+func deploymentNamespace(flags *uninstallFlags) string {
+	return flags.Namespace
+}
+
 type uninstallFlags struct {
-	Backend      string
-	Force        bool
-	PurgeVolumes bool
+	Backend   string
+	Namespace string
+	Force     bool
+	Purge     struct {
+		Volumes bool
+	}
+	Approval struct {
+		Token string
+	}
 }
 
 // NewCommand uninstall a server and optionally the corresponding volumes.
@@ -29,14 +46,18 @@ By default it will only print what would be done, use --force to actually remove
 		Args: cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags uninstallFlags
-			return utils.CommandHelper(globalFlags, cmd, args, &flags, uninstall)
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, utils.WithLock(uninstall))
 		},
 	}
 	uninstallCmd.Flags().BoolP("force", "f", false, L("Actually remove the server"))
-	uninstallCmd.Flags().Bool("purgeVolumes", false, L("Also remove the volumes"))
+	uninstallCmd.Flags().Bool("purge-volumes", false, L("Also remove the volumes"))
+	utils.RenameFlag(uninstallCmd, "purgeVolumes", "purge-volumes")
+	utils.AddLockFlag(uninstallCmd)
+	utils.AddApprovalFlag(uninstallCmd)
 
 	if utils.KubernetesBuilt {
 		utils.AddBackendFlag(uninstallCmd)
+		utils.AddNamespaceFlag(uninstallCmd)
 	}
 
 	return uninstallCmd
@@ -48,7 +69,13 @@ func uninstall(
 	cmd *cobra.Command,
 	args []string,
 ) error {
-	fn, err := shared.ChoosePodmanOrKubernetes(cmd.Flags(), uninstallForPodman, uninstallForKubernetes)
+	if flags.Force && flags.Purge.Volumes {
+		if err := utils.CheckApproval(utils.ApprovalUninstallPurge, flags.Approval.Token); err != nil {
+			return err
+		}
+	}
+
+	fn, err := shared.ChooseBackendFunc(cmd.Flags(), shared.ServerApp, backendFuncs)
 	if err != nil {
 		return err
 	}
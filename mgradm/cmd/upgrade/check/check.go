@@ -0,0 +1,273 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	adm_podman "github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type checkFlags struct {
+	Image     types.ImageFlags `mapstructure:",squash"`
+	Pgsql     adm_utils.PgsqlUpgradeFlags
+	Backend   string
+	Namespace string
+}
+
+const taskQueueQuery = "SELECT status, COUNT(*) FROM rhnTaskoRun WHERE status IN ('READY', 'RUNNING') GROUP BY status;"
+
+// The server actions with status 0 are queued and the ones with status 1 are picked up by the clients.
+const actionsQuery = "SELECT status, COUNT(*) FROM rhnServerAction WHERE status IN (0, 1) GROUP BY status;"
+
+const pgsqlDataPath = "/var/lib/pgsql/data"
+
+// NewCommand to check if the server can be upgraded.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: L("Check if the server can be upgraded"),
+		Long: L(`Check if the server can be upgraded
+
+The image is inspected and the following checks are performed without changing anything:
+  - the server release can be upgraded to the image one,
+  - the image release is compatible with this tool,
+  - the PostgreSQL version of the image is not older than the deployed one,
+  - there is enough disk space for the database upgrade,
+  - no Taskomatic job is running,
+  - no action is being executed by the clients.`),
+		Args:        cobra.ExactArgs(0),
+		Annotations: map[string]string{utils.ReadOnlyAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags checkFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, check)
+		},
+	}
+	adm_utils.AddImageUpgradeFlag(cmd)
+	cmd.Flags().Bool("pgsql-link", adm_utils.DefaultPgsqlUpgradeFlags.Link,
+		L("Whether the PostgreSQL major version upgrade will use hard links instead of copying the files"))
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(cmd)
+		utils.AddNamespaceFlag(cmd)
+	}
+	return cmd
+}
+
+func check(globalFlags *types.GlobalFlags, flags *checkFlags, cmd *cobra.Command, args []string) error {
+	serverImage, err := utils.ComputeImage(flags.Image.Name, flags.Image.Tag)
+	if err != nil {
+		return utils.Errorf(err, L("failed to compute image URL: %s"))
+	}
+
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
+	inspectedValues, err := inspect(cnx, serverImage, flags.Image.PullPolicy)
+	if err != nil {
+		return utils.ReportChecks(os.Stdout, []utils.CheckResult{
+			{Name: L("Image"), Detail: fmt.Sprintf(L("failed to inspect %[1]s: %[2]s"), serverImage, err)},
+		}, L("the server cannot be upgraded"))
+	}
+
+	checks := []utils.CheckResult{
+		releaseCheck(cnx, inspectedValues, serverImage),
+		compatibilityCheck(inspectedValues),
+		pgsqlCheck(inspectedValues),
+		diskSpaceCheck(cnx, inspectedValues, flags.Pgsql.Link),
+		taskomaticCheck(cnx),
+		actionsCheck(cnx),
+	}
+	return utils.ReportChecks(os.Stdout, checks, L("the server cannot be upgraded"))
+}
+
+// inspect reads the values of the image and the deployed server.
+func inspect(cnx *shared.Connection, serverImage string, pullPolicy string) (map[string]string, error) {
+	command, err := cnx.GetCommand()
+	if err != nil {
+		return nil, err
+	}
+	if command == "kubectl" {
+		namespace, err := cnx.GetNamespace()
+		if err != nil {
+			return nil, err
+		}
+		return kubernetes.InspectKubernetes(namespace, serverImage, pullPolicy)
+	}
+	return adm_podman.Inspect(serverImage, pullPolicy)
+}
+
+func releaseCheck(cnx *shared.Connection, inspectedValues map[string]string, serverImage string) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Release")}
+	if err := adm_utils.SanityCheck(cnx, inspectedValues, serverImage); err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("upgrading to %s"), imageRelease(inspectedValues))
+	return check
+}
+
+func compatibilityCheck(inspectedValues map[string]string) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Tool compatibility")}
+	if err := adm_utils.CheckVersionSkew(inspectedValues, false); err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("uyuni-tools %[1]s supports %[2]s"), utils.Version, imageRelease(inspectedValues))
+	return check
+}
+
+func imageRelease(inspectedValues map[string]string) string {
+	if release := inspectedValues["uyuni_release"]; release != "" {
+		return "Uyuni " + release
+	}
+	return "SUSE Manager " + inspectedValues["suse_manager_release"]
+}
+
+func pgsqlCheck(inspectedValues map[string]string) utils.CheckResult {
+	check := utils.CheckResult{Name: L("PostgreSQL")}
+	current := inspectedValues["current_pg_version"]
+	target := inspectedValues["image_pg_version"]
+	switch {
+	case adm_utils.IsExternalDb(inspectedValues):
+		check.OK = true
+		check.Detail = fmt.Sprintf(L("external database on %s is not upgraded"), inspectedValues["db_host"])
+	case current == "" || target == "":
+		check.Detail = L("cannot read the PostgreSQL versions")
+	case target < current:
+		check.Detail = fmt.Sprintf(L("cannot downgrade PostgreSQL from %[1]s to %[2]s"), current, target)
+	case target > current:
+		check.OK = true
+		check.Detail = fmt.Sprintf(L("major version upgrade from %[1]s to %[2]s"), current, target)
+	default:
+		check.OK = true
+		check.Detail = fmt.Sprintf(L("version %s is kept"), current)
+	}
+	return check
+}
+
+func diskSpaceCheck(cnx *shared.Connection, inspectedValues map[string]string, link bool) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Disk space")}
+	if adm_utils.IsExternalDb(inspectedValues) || inspectedValues["image_pg_version"] <= inspectedValues["current_pg_version"] {
+		check.OK = true
+		check.Detail = L("no database upgrade needed")
+		return check
+	}
+
+	script := fmt.Sprintf("du -sk %[1]s | cut -f1; df -Pk %[1]s | tail -n 1 | awk '{print $4}'", pgsqlDataPath)
+	out, err := cnx.Exec("sh", "-c", script)
+	if err != nil {
+		check.Detail = fmt.Sprintf(L("failed to compute the database size: %s"), err)
+		return check
+	}
+	dataSize, available, err := parseDiskUsage(string(out))
+	if err != nil {
+		check.Detail = fmt.Sprintf(L("failed to compute the database size: %s"), err)
+		return check
+	}
+	return evaluateDiskSpace(dataSize, available, link)
+}
+
+// parseDiskUsage reads the database size and the available space, in KiB.
+func parseDiskUsage(out string) (int64, int64, error) {
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf(L("unexpected output: %s"), out)
+	}
+	dataSize, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	available, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return dataSize, available, nil
+}
+
+// evaluateDiskSpace checks that a copy of the database fits in the available space, unless hard links are used.
+func evaluateDiskSpace(dataSize int64, available int64, link bool) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Disk space")}
+	if link {
+		check.OK = true
+		check.Detail = fmt.Sprintf(L("database of %[1]d MiB upgraded with hard links, %[2]d MiB available"),
+			dataSize/1024, available/1024)
+		return check
+	}
+	if available < dataSize {
+		check.Detail = fmt.Sprintf(L("%[1]d MiB needed to copy the database, only %[2]d MiB available"),
+			dataSize/1024, available/1024)
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("%[1]d MiB needed to copy the database, %[2]d MiB available"),
+		dataSize/1024, available/1024)
+	return check
+}
+
+// countByStatus maps the status of the rows returned by a status count query to their count.
+func countByStatus(rows [][]string) map[string]string {
+	counts := map[string]string{}
+	for _, row := range rows {
+		if len(row) == 2 {
+			counts[row[0]] = row[1]
+		}
+	}
+	return counts
+}
+
+func taskomaticCheck(cnx *shared.Connection) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Taskomatic jobs")}
+	rows, err := adm_utils.RunQuery(cnx, taskQueueQuery)
+	if err != nil {
+		check.Detail = fmt.Sprintf(L("failed to read the task queue: %s"), err)
+		return check
+	}
+	counts := countByStatus(rows)
+	queued, running := counts["READY"], counts["RUNNING"]
+	if queued == "" {
+		queued = "0"
+	}
+	if running != "" && running != "0" {
+		check.Detail = fmt.Sprintf(L("%[1]s queued, %[2]s running: wait for the running jobs to finish"), queued, running)
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("%s queued, none running"), queued)
+	return check
+}
+
+func actionsCheck(cnx *shared.Connection) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Actions")}
+	rows, err := adm_utils.RunQuery(cnx, actionsQuery)
+	if err != nil {
+		check.Detail = fmt.Sprintf(L("failed to read the actions: %s"), err)
+		return check
+	}
+	counts := countByStatus(rows)
+	queued, pickedUp := counts["0"], counts["1"]
+	if queued == "" {
+		queued = "0"
+	}
+	if pickedUp != "" && pickedUp != "0" {
+		check.Detail = fmt.Sprintf(L("%[1]s queued, %[2]s in progress: wait for the clients to report their results"),
+			queued, pickedUp)
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("%s queued, none in progress"), queued)
+	return check
+}
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import "testing"
+
+func TestPgsqlCheck(t *testing.T) {
+	data := []struct {
+		current string
+		image   string
+		dbHost  string
+		ok      bool
+	}{
+		{"14", "16", "localhost", true},
+		{"16", "16", "localhost", true},
+		{"16", "14", "localhost", false},
+		{"", "16", "localhost", false},
+		{"", "16", "db.example.com", true},
+	}
+	for i, testCase := range data {
+		values := map[string]string{
+			"current_pg_version": testCase.current,
+			"image_pg_version":   testCase.image,
+			"db_host":            testCase.dbHost,
+		}
+		if check := pgsqlCheck(values); check.OK != testCase.ok {
+			t.Errorf("case %d: expected OK to be %v: %s", i, testCase.ok, check.Detail)
+		}
+	}
+}
+
+func TestParseDiskUsage(t *testing.T) {
+	dataSize, available, err := parseDiskUsage("2048\n10240\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if dataSize != 2048 || available != 10240 {
+		t.Errorf("unexpected values: %d, %d", dataSize, available)
+	}
+	if _, _, err := parseDiskUsage("du: cannot access\n"); err == nil {
+		t.Error("expected an error for an invalid output")
+	}
+}
+
+func TestEvaluateDiskSpace(t *testing.T) {
+	if check := evaluateDiskSpace(4096, 1024, true); !check.OK {
+		t.Errorf("expected hard links to need no additional space: %s", check.Detail)
+	}
+	if check := evaluateDiskSpace(4096, 1024, false); check.OK {
+		t.Error("expected a failure without enough space for the copy")
+	}
+	if check := evaluateDiskSpace(1024, 4096, false); !check.OK {
+		t.Errorf("expected enough space for the copy: %s", check.Detail)
+	}
+}
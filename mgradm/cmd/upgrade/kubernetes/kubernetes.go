@@ -10,14 +10,17 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/upgrade/shared"
 	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 type kubernetesUpgradeFlags struct {
-	shared.UpgradeFlags `mapstructure:",squash"`
-	Helm                cmd_utils.HelmFlags
+	shared.UpgradeFlags       `mapstructure:",squash"`
+	Helm                      cmd_utils.HelmFlags
+	Atomic                    bool
+	cmd_utils.KubernetesFlags `mapstructure:",squash"`
 }
 
 // NewCommand to upgrade a kubernetes server.
@@ -29,12 +32,23 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 		Args:  cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags kubernetesUpgradeFlags
-			return utils.CommandHelper(globalFlags, cmd, args, &flags, upgradeKubernetes)
+			return utils.CommandHelper(globalFlags, cmd, args, &flags,
+				utils.WithNotification(utils.WithLock(kubernetes.WithLease(uyuniNamespace,
+					utils.WithHooks(utils.PreUpgradeHook, utils.PostUpgradeHook, "kubernetes", upgradeKubernetes)))))
 		},
 	}
 
 	shared.AddUpgradeFlags(upgradeCmd)
 	cmd_utils.AddHelmInstallFlag(upgradeCmd)
+	cmd_utils.AddKubernetesFlags(upgradeCmd)
+	upgradeCmd.Flags().Bool("atomic", false,
+		L("Roll the helm release back to its previous revision if the chart upgrade or one of its hooks fails"))
+	_ = utils.AddFlagToHelpGroupID(upgradeCmd, "atomic", "helm")
 
 	return upgradeCmd
 }
+
+// uyuniNamespace returns the namespace of the server deployment to lock.
+func uyuniNamespace(flags *kubernetesUpgradeFlags) string {
+	return flags.Helm.Uyuni.Namespace
+}
@@ -7,16 +7,46 @@
 package kubernetes
 
 import (
+	"time"
+
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/mgradm/shared/kubernetes"
+	shared_kubernetes "github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
+// stateFlags are the flags recorded at install time to reuse if not set for the upgrade.
+//
+// The helm values are not reused by the upgrade and need to be passed again.
+var stateFlags = []string{
+	"image", "migration-image", "helm-uyuni-values",
+	"ingress-class", "ingress-annotation", "ingress-tls-secret",
+	"expose-mode", "expose-annotation", "expose-port",
+	"resources-requests-cpu", "resources-requests-memory", "resources-limits-cpu", "resources-limits-memory",
+	"resources-priority-class", "scheduling-toleration", "scheduling-affinity", "dedicated-node",
+}
+
 func upgradeKubernetes(
 	globalFlags *types.GlobalFlags,
 	flags *kubernetesUpgradeFlags,
 	cmd *cobra.Command,
 	args []string,
 ) error {
-	return kubernetes.Upgrade(globalFlags, &flags.Image, &flags.MigrationImage, flags.Helm, cmd, args)
+	if err := flags.Pgsql.CheckParameters(); err != nil {
+		return utils.UsageError(err)
+	}
+	if err := flags.Maintenance.Check(time.Now(), flags.Force); err != nil {
+		return err
+	}
+	state, err := shared_kubernetes.ReadState(flags.Helm.Uyuni.Namespace)
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Failed to read the deployment state"))
+	}
+	if err := utils.ReuseDeploymentState(utils.CommandConfig(cmd), cmd, state, flags, stateFlags...); err != nil {
+		return err
+	}
+	return kubernetes.Upgrade(globalFlags, &flags.Image, &flags.MigrationImage, flags.Force, flags.Pgsql, flags.Helm, &flags.KubernetesFlags, flags.Atomic, cmd, args)
 }
@@ -28,7 +28,9 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 		Args:  cobra.RangeArgs(0, 1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags podmanUpgradeFlags
-			return utils.CommandHelper(globalFlags, cmd, args, &flags, upgradePodman)
+			return utils.CommandHelper(globalFlags, cmd, args, &flags,
+				utils.WithNotification(utils.WithLock(
+					utils.WithHooks(utils.PreUpgradeHook, utils.PostUpgradeHook, "podman", upgradePodman))))
 		},
 	}
 	listCmd := &cobra.Command{
@@ -5,11 +5,37 @@
 package podman
 
 import (
+	"time"
+
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	shared_podman "github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 func upgradePodman(globalFlags *types.GlobalFlags, flags *podmanUpgradeFlags, cmd *cobra.Command, args []string) error {
-	return podman.Upgrade(flags.Image, flags.MigrationImage, args)
+	if err := flags.Pgsql.CheckParameters(); err != nil {
+		return utils.UsageError(err)
+	}
+	if err := flags.Maintenance.Check(time.Now(), flags.Force); err != nil {
+		return err
+	}
+	state, err := shared_podman.ReadState()
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Failed to read the deployment state"))
+	}
+	if err := utils.ReuseDeploymentState(utils.CommandConfig(cmd), cmd, state, flags, "image", "migration-image"); err != nil {
+		return err
+	}
+	// Upgrade reloads the systemd configuration and restarts the server
+	if err := shared_podman.UpdateExtraArgsConf(shared_podman.ServerService, &flags.Podman, cmd); err != nil {
+		return err
+	}
+	if err := shared_podman.UpdateHardeningConf(shared_podman.ServerHardenedServices, &flags.Podman, cmd); err != nil {
+		return err
+	}
+	return podman.Upgrade(flags.Image, flags.MigrationImage, flags.Force, flags.Pgsql, args)
 }
@@ -7,19 +7,28 @@ package shared
 import (
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
+	shared_utils "github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 // UpgradeFlags represents flags used for upgrading a server.
 type UpgradeFlags struct {
 	Image          types.ImageFlags `mapstructure:",squash"`
 	MigrationImage types.ImageFlags `mapstructure:"migration"`
+	Force          bool
+	Maintenance    utils.MaintenanceFlags
+	Pgsql          utils.PgsqlUpgradeFlags
 }
 
 // AddUpgradeFlags add upgrade flags to a command.
 func AddUpgradeFlags(cmd *cobra.Command) {
 	utils.AddImageUpgradeFlag(cmd)
 	utils.AddMigrationImageFlag(cmd)
+	cmd.Flags().Bool("force", false, L("Upgrade even if the tool version is not compatible with the image version, outside of the maintenance window or while operations are running on the server"))
+	utils.AddMaintenanceWindowFlag(cmd)
+	shared_utils.AddLockFlag(cmd)
+	utils.AddPgsqlUpgradeFlags(cmd)
 }
 
 // AddUpgradeListFlags add upgrade list flags to a command.
@@ -33,7 +33,7 @@ func isUyuni(cnx *shared.Connection) (bool, error) {
 func SanityCheck(cnx *shared.Connection, inspectedValues map[string]string, serverImage string) error {
 	isUyuni, err := isUyuni(cnx)
 	if err != nil {
-		return fmt.Errorf(L("cannot check server release: %s"), err)
+		return utils.Errorf(err, L("cannot check server release: %s"))
 	}
 	_, isCurrentUyuni := inspectedValues["uyuni_release"]
 	_, isCurrentSuma := inspectedValues["suse_manager_release"]
@@ -50,7 +50,7 @@ func SanityCheck(cnx *shared.Connection, inspectedValues map[string]string, serv
 		cnx_args := []string{"s/Uyuni release //g", "/etc/uyuni-release"}
 		current_uyuni_release, err := cnx.Exec("sed", cnx_args...)
 		if err != nil {
-			return fmt.Errorf(L("failed to read current uyuni release: %s"), err)
+			return utils.Errorf(err, L("failed to read current uyuni release: %s"))
 		}
 		log.Debug().Msgf("Current release is %s", string(current_uyuni_release))
 		if (len(inspectedValues["uyuni_release"])) <= 0 {
@@ -64,7 +64,7 @@ func SanityCheck(cnx *shared.Connection, inspectedValues map[string]string, serv
 		cnx_args := []string{"s/SUSE Manager release //g", "/etc/susemanager-release"}
 		current_suse_manager_release, err := cnx.Exec("sed", cnx_args...)
 		if err != nil {
-			return fmt.Errorf(L("failed to read current susemanager release: %s"), err)
+			return utils.Errorf(err, L("failed to read current susemanager release: %s"))
 		}
 		log.Debug().Msgf("Current release is %s", string(current_suse_manager_release))
 		if (len(inspectedValues["suse_manager_release"])) <= 0 {
@@ -5,6 +5,7 @@ package upgrade
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/upgrade/check"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/upgrade/kubernetes"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/upgrade/podman"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
@@ -20,6 +21,7 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	}
 
 	upgradeCmd.AddCommand(podman.NewCommand(globalFlags))
+	upgradeCmd.AddCommand(check.NewCommand(globalFlags))
 
 	if kubernetesCmd := kubernetes.NewCommand(globalFlags); kubernetesCmd != nil {
 		upgradeCmd.AddCommand(kubernetesCmd)
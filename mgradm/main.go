@@ -16,6 +16,7 @@ import (
 // Run runs the `mgradm` root command.
 func Run() error {
 	gettext.BindLocale(gettext.New("mgradm", utils.LocaleRoot, l10n_utils.New(utils.LocaleRoot)))
+	utils.SetLanguageFromArgs(os.Args[1:])
 	run, err := cmd.NewUyuniadmCommand()
 	if err != nil {
 		return err
@@ -24,7 +25,5 @@ func Run() error {
 }
 
 func main() {
-	if err := Run(); err != nil {
-		os.Exit(1)
-	}
+	os.Exit(utils.FinishCommand(Run()))
 }
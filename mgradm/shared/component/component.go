@@ -0,0 +1,384 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package component
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/templates"
+	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// ManifestsDir is the folder containing the manifests of the additional components.
+const ManifestsDir = "/etc/uyuni/components"
+
+// Volume is a volume mounted in the component container.
+type Volume struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+}
+
+// Port is a port published by the component container.
+type Port struct {
+	// Exposed is the port on the host, defaults to the container port.
+	Exposed  int    `yaml:"exposed,omitempty"`
+	Port     int    `yaml:"port"`
+	Protocol string `yaml:"protocol,omitempty"`
+}
+
+// Manifest describes an optional container running next to the server.
+type Manifest struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	// Image is the image of the component.
+	// If empty, the image is computed from the server one with a -<name> suffix.
+	Image string `yaml:"image,omitempty"`
+	// Tag is the tag of the image, defaults to the server image one.
+	Tag      string            `yaml:"tag,omitempty"`
+	Env      map[string]string `yaml:"env,omitempty"`
+	Volumes  []Volume          `yaml:"volumes,omitempty"`
+	Ports    []Port            `yaml:"ports,omitempty"`
+	Requires []string          `yaml:"requires,omitempty"`
+	Args     []string          `yaml:"args,omitempty"`
+	Command  []string          `yaml:"command,omitempty"`
+	// Database is whether the component connects to the server database.
+	// The connection is passed in the database_connection, database_user and database_password environment variables.
+	Database bool `yaml:"database,omitempty"`
+}
+
+// Database is the connection to the server database passed to the components.
+type Database struct {
+	Host     string
+	Port     int
+	Name     string
+	User     string
+	Password string
+}
+
+// BundledDbHost is the host of the database bundled in the server container on the podman network.
+const BundledDbHost = "uyuni-server.mgr.internal"
+
+var builtins = []Manifest{
+	{
+		Name:        "saline",
+		Description: "Uyuni server saline salt event processor",
+		Volumes:     toVolumes(utils.SalineVolumeMounts),
+		Ports:       []Port{{Exposed: 8216, Port: 8216}},
+	},
+	{
+		Name:        "attestation",
+		Description: "Uyuni server confidential computing attestation",
+		Database:    true,
+	},
+	{
+		Name:        "hub-xmlrpc-api",
+		Description: "Uyuni server hub XML-RPC API",
+		Env: map[string]string{
+			"HUB_API_URL":           "http://uyuni-server.mgr.internal:80/rpc/api",
+			"HUB_CONNECT_TLS":       "false",
+			"HUB_CONNECT_USING_SSL": "false",
+		},
+		Ports: []Port{{Port: 2830}},
+	},
+}
+
+// Those components are handled by dedicated code.
+var reservedNames = []string{"server", "reportdb"}
+
+var nameRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+var envRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func toVolumes(mounts []types.VolumeMount) []Volume {
+	volumes := []Volume{}
+	for _, mount := range mounts {
+		volumes = append(volumes, Volume{Name: mount.Name, Path: mount.MountPath})
+	}
+	return volumes
+}
+
+// Service returns the name of the systemd service of the component.
+func (m *Manifest) Service() string {
+	return podman.ComponentService(m.Name)
+}
+
+// Validate checks the manifest values.
+func (m *Manifest) Validate() error {
+	if !nameRegex.MatchString(m.Name) {
+		return fmt.Errorf(L("invalid component name %s: only lower case letters, digits and - are allowed"), m.Name)
+	}
+	if utils.Contains(reservedNames, m.Name) {
+		return fmt.Errorf(L("%s is a reserved component name"), m.Name)
+	}
+	for name := range m.Env {
+		if !envRegex.MatchString(name) {
+			return fmt.Errorf(L("invalid environment variable name %[1]s in %[2]s component"), name, m.Name)
+		}
+	}
+	for _, volume := range m.Volumes {
+		if volume.Name == "" || !path.IsAbs(volume.Path) {
+			return fmt.Errorf(L("%s component volumes need a name and an absolute path"), m.Name)
+		}
+	}
+	for _, port := range m.Ports {
+		if port.Port <= 0 || port.Port > 65535 || port.Exposed < 0 || port.Exposed > 65535 {
+			return fmt.Errorf(L("invalid port in %s component"), m.Name)
+		}
+		if port.Protocol != "" && port.Protocol != "tcp" && port.Protocol != "udp" {
+			return fmt.Errorf(L("invalid protocol %[1]s in %[2]s component: possible values are tcp and udp"), port.Protocol, m.Name)
+		}
+	}
+	for _, required := range m.Requires {
+		if required == m.Name || !nameRegex.MatchString(required) {
+			return fmt.Errorf(L("invalid requirement %[1]s in %[2]s component"), required, m.Name)
+		}
+	}
+	return nil
+}
+
+// ReadManifest reads and validates a component manifest file.
+func ReadManifest(file string) (*Manifest, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to read %[1]s: %[2]s"), file)
+	}
+	var manifest Manifest
+	if err := yaml.UnmarshalStrict(data, &manifest); err != nil {
+		return nil, utils.Errorf(err, L("failed to parse %[1]s: %[2]s"), file)
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// List returns the built-in components and the ones from the manifests directory, sorted by name.
+//
+// A manifest with the name of a built-in component overrides it.
+func List() ([]Manifest, error) {
+	return list(ManifestsDir)
+}
+
+func list(dir string) ([]Manifest, error) {
+	manifests := map[string]Manifest{}
+	for _, manifest := range builtins {
+		manifests[manifest.Name] = manifest
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		manifest, err := ReadManifest(file)
+		if err != nil {
+			return nil, err
+		}
+		manifests[manifest.Name] = *manifest
+	}
+
+	result := make([]Manifest, 0, len(manifests))
+	for _, manifest := range manifests {
+		result = append(result, manifest)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// Find returns the component with the given name.
+func Find(name string) (*Manifest, error) {
+	manifests, err := List()
+	if err != nil {
+		return nil, err
+	}
+	for _, manifest := range manifests {
+		if manifest.Name == name {
+			return &manifest, nil
+		}
+	}
+	return nil, fmt.Errorf(L("no %s component, use mgradm component list to see the available ones"), name)
+}
+
+// ServerDatabase reads the connection to the server database from the server configuration.
+func ServerDatabase(cnx *shared.Connection) (*Database, error) {
+	out, err := cnx.Exec("/bin/cat", "/etc/rhn/rhn.conf")
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to read the server configuration: %s"))
+	}
+	config := utils.ParseConfValues(out)
+	db := Database{
+		Host:     config["db_host"],
+		Port:     5432,
+		Name:     config["db_name"],
+		User:     config["db_user"],
+		Password: config["db_password"],
+	}
+	if db.Name == "" || db.User == "" {
+		return nil, errors.New(L("the database configuration is missing in the server configuration"))
+	}
+	if port, err := strconv.Atoi(config["db_port"]); err == nil {
+		db.Port = port
+	}
+	// The bundled database is reached through the podman network
+	if adm_utils.IsLocalDb(db.Host, config["java.hostname"]) {
+		db.Host = BundledDbHost
+	}
+	return &db, nil
+}
+
+// IsEnabled returns whether the component service is installed.
+func (m *Manifest) IsEnabled() bool {
+	return podman.HasService(m.Service())
+}
+
+// ImageURL computes the image of the component using the server image for the missing parts.
+func (m *Manifest) ImageURL(serverImage string) (string, error) {
+	name := serverImage
+	tag := ""
+	if i := strings.LastIndex(serverImage, ":"); i > strings.LastIndex(serverImage, "/") {
+		name = serverImage[:i]
+		tag = serverImage[i+1:]
+	}
+
+	if m.Image != "" {
+		name = m.Image
+	} else {
+		name += "-" + m.Name
+	}
+	if m.Tag != "" {
+		tag = m.Tag
+	}
+	return utils.ComputeImage(name, tag)
+}
+
+// Enable generates the systemd service of the component and starts it.
+//
+// db is the connection to the server database, only needed by the components using it.
+// An existing service unit is only replaced if overwrite is true.
+func Enable(m *Manifest, image string, tz string, db *Database, overwrite bool) error {
+	requires := []string{}
+	for _, required := range m.Requires {
+		service := podman.ComponentService(required)
+		if !podman.HasService(service) {
+			return fmt.Errorf(L("%[1]s component requires %[2]s to be enabled first"), m.Name, required)
+		}
+		requires = append(requires, service+".service")
+	}
+
+	env, err := m.environment(db)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.WriteTemplateToFile(m.serviceData(tz, requires, env), podman.GetServicePath(m.Service()), 0555, overwrite); err != nil {
+		return utils.Errorf(err, L("failed to generate systemd service unit file: %s"))
+	}
+
+	environment := []string{"Environment=UYUNI_IMAGE=" + image}
+	for name, value := range env {
+		environment = append(environment, "Environment="+utils.SystemdQuote(name+"="+value, false))
+	}
+	sort.Strings(environment[1:])
+	if err := podman.GenerateSystemdConfFile(m.Service(), "Service", strings.Join(environment, "\n")); err != nil {
+		return utils.Errorf(err, L("cannot generate systemd conf file: %s"))
+	}
+
+	if err := podman.ReloadDaemon(false); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Enabling %s component"), m.Name)
+	return podman.EnableService(m.Service())
+}
+
+// environment returns the environment variables of the component container.
+func (m *Manifest) environment(db *Database) (map[string]string, error) {
+	env := map[string]string{}
+	for name, value := range m.Env {
+		env[name] = value
+	}
+	if m.Database {
+		if db == nil {
+			return nil, fmt.Errorf(L("%s component needs the server database connection"), m.Name)
+		}
+		env["database_connection"] = fmt.Sprintf("jdbc:postgresql://%s:%d/%s", db.Host, db.Port, db.Name)
+		env["database_user"] = db.User
+		env["database_password"] = db.Password
+	}
+	return env, nil
+}
+
+func (m *Manifest) serviceData(tz string, requires []string, env map[string]string) templates.ComponentServiceTemplateData {
+	data := templates.ComponentServiceTemplateData{
+		Name:        m.Name,
+		Description: m.Description,
+		Service:     m.Service(),
+		Network:     podman.UyuniNetwork,
+		Timezone:    tz,
+		Requires:    strings.Join(requires, " "),
+		Env:         env,
+		Args:        systemdQuoteAll(m.Args),
+		Command:     systemdQuoteAll(m.Command),
+	}
+	if data.Description == "" {
+		data.Description = fmt.Sprintf("Uyuni server %s container service", m.Name)
+	}
+	for _, volume := range m.Volumes {
+		data.Volumes = append(data.Volumes, types.VolumeMount{Name: volume.Name, MountPath: volume.Path})
+	}
+	for _, port := range m.Ports {
+		exposed := port.Exposed
+		if exposed == 0 {
+			exposed = port.Port
+		}
+		data.Ports = append(data.Ports, types.PortMap{Exposed: exposed, Port: port.Port, Protocol: port.Protocol})
+	}
+	return data
+}
+
+func systemdQuoteAll(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, utils.SystemdQuote(value, true))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Disable stops the component and removes its systemd service.
+func Disable(name string) error {
+	manifests, err := List()
+	if err != nil {
+		return err
+	}
+	for _, manifest := range manifests {
+		if utils.Contains(manifest.Requires, name) && manifest.IsEnabled() {
+			return fmt.Errorf(L("%[1]s component is required by %[2]s, disable it first"), name, manifest.Name)
+		}
+	}
+
+	service := podman.ComponentService(name)
+	if !podman.HasService(service) {
+		return errors.New(L("the component is not enabled"))
+	}
+	podman.UninstallService(service, false)
+	podman.DeleteContainer(service, false)
+	if err := os.RemoveAll(podman.GetServicePath(service) + ".d"); err != nil {
+		log.Warn().Err(err).Msgf(L("Failed to remove the %s service configuration"), service)
+	}
+	return podman.ReloadDaemon(false)
+}
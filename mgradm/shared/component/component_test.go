@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package component
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	data := map[string]bool{
+		"name: foo":                                       true,
+		"name: Foo":                                       false,
+		"name: reportdb":                                  false,
+		"name: foo\nenv:\n  FOO_1: bar":                   true,
+		"name: foo\nenv:\n  1FOO: bar":                    false,
+		"name: foo\nvolumes:\n- {name: v, path: /v}":      true,
+		"name: foo\nvolumes:\n- {name: v, path: v}":       false,
+		"name: foo\nports:\n- {port: 80}":                 true,
+		"name: foo\nports:\n- {port: 0}":                  false,
+		"name: foo\nports:\n- {port: 69, protocol: udp}":  true,
+		"name: foo\nports:\n- {port: 69, protocol: icmp}": false,
+		"name: foo\nrequires: [saline]":                   true,
+		"name: foo\nrequires: [foo]":                      false,
+		"name: foo\nunknown: value":                       false,
+	}
+
+	dir := t.TempDir()
+	for content, expected := range data {
+		file := filepath.Join(dir, "manifest.yaml")
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write manifest: %s", err)
+		}
+		_, err := ReadManifest(file)
+		if (err == nil) != expected {
+			t.Errorf("%q: expected valid %v, got error %v", content, expected, err)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	manifests := map[string]string{
+		"saline.yaml": "name: saline\ndescription: overridden",
+		"extra.yaml":  "name: extra\nrequires: [saline]",
+		"ignored.txt": "name: ignored",
+	}
+	for name, content := range manifests {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write manifest: %s", err)
+		}
+	}
+
+	actual, err := list(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	names := []string{}
+	for _, manifest := range actual {
+		names = append(names, manifest.Name)
+	}
+	if strings.Join(names, ",") != "attestation,extra,hub-xmlrpc-api,saline" {
+		t.Fatalf("unexpected components: %v", names)
+	}
+	if actual[3].Description != "overridden" {
+		t.Errorf("the saline manifest should override the built-in one, got %v", actual[3])
+	}
+}
+
+func TestImageURL(t *testing.T) {
+	server := "registry.opensuse.org/uyuni/server:2024.07"
+	data := []struct {
+		manifest Manifest
+		expected string
+	}{
+		{Manifest{Name: "saline"}, "registry.opensuse.org/uyuni/server-saline:2024.07"},
+		{Manifest{Name: "saline", Tag: "latest"}, "registry.opensuse.org/uyuni/server-saline:latest"},
+		{Manifest{Name: "foo", Image: "registry.example.com/foo"}, "registry.example.com/foo:2024.07"},
+		{Manifest{Name: "foo", Image: "registry.example.com/foo", Tag: "1.0"}, "registry.example.com/foo:1.0"},
+	}
+	for i, test := range data {
+		actual, err := test.manifest.ImageURL(server)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %s", i, err)
+		} else if actual != test.expected {
+			t.Errorf("case %d: expected %s, got %s", i, test.expected, actual)
+		}
+	}
+}
+
+func TestServiceData(t *testing.T) {
+	manifest := Manifest{
+		Name:    "foo",
+		Env:     map[string]string{"B": "2", "A": "1"},
+		Volumes: []Volume{{Name: "etc-salt", Path: "/etc/salt"}},
+		Ports:   []Port{{Port: 8080}, {Exposed: 6969, Port: 69, Protocol: "udp"}},
+		Args:    []string{"--memory", "1g"},
+		Command: []string{"run", "100% $HOME \"quoted\""},
+	}
+	var buf bytes.Buffer
+	if err := manifest.serviceData("Europe/Berlin", []string{"uyuni-server-bar.service"}, manifest.Env).Render(&buf); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	service := buf.String()
+
+	for _, expected := range []string{
+		"X-UyuniComponent=foo\n",
+		"Description=Uyuni server foo container service\n",
+		"Requires=uyuni-server-bar.service\n",
+		"\t-e TZ=Europe/Berlin \\\n\t-e A \\\n\t-e B \\\n",
+		"\t-p 8080:8080 \\\n\t-p 6969:69/udp \\\n",
+		"\t-v etc-salt:/etc/salt \\\n",
+		"\t\"--memory\" \"1g\" \\\n",
+		"\t--name uyuni-server-foo \\\n",
+		"${UYUNI_IMAGE} \"run\" \"100%% $$HOME \\\"quoted\\\"\"\n",
+	} {
+		if !strings.Contains(service, expected) {
+			t.Errorf("expected %q in service:\n%s", expected, service)
+		}
+	}
+}
+
+func TestDatabaseEnvironment(t *testing.T) {
+	manifest := Manifest{Name: "attestation", Env: map[string]string{"LOG": "debug"}, Database: true}
+	if _, err := manifest.environment(nil); err == nil {
+		t.Error("expected an error without the database connection")
+	}
+
+	db := Database{Host: BundledDbHost, Port: 5432, Name: "susemanager", User: "spacewalk", Password: "secret"}
+	env, err := manifest.environment(&db)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]string{
+		"LOG":                 "debug",
+		"database_connection": "jdbc:postgresql://uyuni-server.mgr.internal:5432/susemanager",
+		"database_user":       "spacewalk",
+		"database_password":   "secret",
+	}
+	if len(env) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, env)
+	}
+	for name, value := range expected {
+		if env[name] != value {
+			t.Errorf("expected %s=%s, got %s", name, value, env[name])
+		}
+	}
+	if _, found := manifest.Env["database_password"]; found {
+		t.Error("the manifest environment should not be changed")
+	}
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package docker
+
+import (
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	adm_podman "github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/templates"
+	"github.com/uyuni-project/uyuni-tools/shared/docker"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// GenerateSystemdService creates the systemd service running the server container with docker.
+func GenerateSystemdService(tz string, image string, debug bool, dockerArgs []string) error {
+	if err := docker.SetupNetwork(); err != nil {
+		return utils.Errorf(err, L("cannot setup network: %s"))
+	}
+
+	if err := docker.CreateVolumes(utils.ServerVolumeMounts); err != nil {
+		return err
+	}
+
+	log.Info().Msg(L("Enabling system service"))
+	args := append(docker.GetCommonParams(), dockerArgs...)
+
+	data := templates.DockerServiceTemplateData{
+		Volumes:    utils.ServerVolumeMounts,
+		NamePrefix: "uyuni",
+		Args:       strings.Join(args, " "),
+		Ports:      adm_podman.GetExposedPorts(debug),
+		Timezone:   tz,
+		Network:    docker.UyuniNetwork,
+	}
+	if err := utils.WriteTemplateToFile(data, podman.GetServicePath(podman.ServerService), 0555, false); err != nil {
+		return utils.Errorf(err, L("failed to generate systemd service unit file: %s"))
+	}
+
+	if err := podman.GenerateSystemdConfFile(podman.ServerService, "Service", "Environment=UYUNI_IMAGE="+image); err != nil {
+		return utils.Errorf(err, L("cannot generate systemd conf file: %s"))
+	}
+	return podman.ReloadDaemon(false)
+}
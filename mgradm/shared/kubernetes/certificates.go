@@ -6,7 +6,6 @@ package kubernetes
 
 import (
 	"encoding/base64"
-	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -30,6 +29,19 @@ func installTlsSecret(namespace string, serverCrt []byte, serverKey []byte, root
 
 	secretPath := filepath.Join(crdsDir, "secret.yaml")
 	log.Info().Msg(L("Creating SSL server certificate secret"))
+	if err = writeTlsSecret(secretPath, namespace, serverCrt, serverKey, rootCaCrt); err != nil {
+		log.Fatal().Err(err).Msg(L("Failed to generate uyuni-crt secret definition"))
+	}
+	err = utils.RunCmd("kubectl", kubernetes.KubectlArgs("apply", "-f", secretPath)...)
+	if err != nil {
+		log.Fatal().Err(err).Msg(L("Failed to create uyuni-crt TLS secret"))
+	}
+
+	createCaConfig(namespace, rootCaCrt)
+}
+
+// writeTlsSecret writes the uyuni-cert TLS secret definition.
+func writeTlsSecret(path string, namespace string, serverCrt []byte, serverKey []byte, rootCaCrt []byte) error {
 	tlsSecretData := templates.TlsSecretTemplateData{
 		Namespace:   namespace,
 		Name:        "uyuni-cert",
@@ -37,18 +49,31 @@ func installTlsSecret(namespace string, serverCrt []byte, serverKey []byte, root
 		Key:         base64.StdEncoding.EncodeToString(serverKey),
 		RootCa:      base64.StdEncoding.EncodeToString(rootCaCrt),
 	}
+	return utils.WriteTemplateToFile(tlsSecretData, path, 0600, true)
+}
 
-	if err = utils.WriteTemplateToFile(tlsSecretData, secretPath, 0500, true); err != nil {
-		log.Fatal().Err(err).Msg(L("Failed to generate uyuni-crt secret definition"))
-	}
-	err = utils.RunCmd("kubectl", "apply", "-f", secretPath)
-	if err != nil {
-		log.Fatal().Err(err).Msg(L("Failed to create uyuni-crt TLS secret"))
+// writeIssuer writes the self-signed CA and issuers definition.
+func writeIssuer(path string, namespace string, sslFlags *cmd_utils.SslCertFlags, rootCa string,
+	tlsCert *ssl.SslPair, fqdn string) error {
+	issuerData := templates.IssuerTemplateData{
+		Namespace:   namespace,
+		Country:     sslFlags.Country,
+		State:       sslFlags.State,
+		City:        sslFlags.City,
+		Org:         sslFlags.Org,
+		OrgUnit:     sslFlags.OU,
+		Email:       sslFlags.Email,
+		Fqdn:        fqdn,
+		RootCa:      rootCa,
+		Key:         tlsCert.Key,
+		Certificate: tlsCert.Cert,
 	}
-
-	createCaConfig(rootCaCrt)
+	return utils.WriteTemplateToFile(issuerData, path, 0600, true)
 }
 
+// issuerHelmArgs are the helm arguments to use the self-signed issuer.
+var issuerHelmArgs = []string{"--set-json", "ingressSslAnnotations={\"cert-manager.io/issuer\": \"uyuni-ca-issuer\"}"}
+
 // Install cert-manager and its CRDs using helm in the cert-manager namespace if needed
 // and then create a self-signed CA and issuers.
 // Returns helm arguments to be added to use the issuer.
@@ -56,47 +81,33 @@ func installSslIssuers(helmFlags *cmd_utils.HelmFlags, sslFlags *cmd_utils.SslCe
 	tlsCert *ssl.SslPair, kubeconfig, fqdn string, imagePullPolicy string) ([]string, error) {
 	// Install cert-manager if needed
 	if err := installCertManager(helmFlags, kubeconfig, imagePullPolicy); err != nil {
-		return []string{}, fmt.Errorf(L("cannot install cert manager: %s"), err)
+		return []string{}, utils.Errorf(err, L("cannot install cert manager: %s"))
 	}
 
 	log.Info().Msg(L("Creating SSL certificate issuer"))
 	crdsDir, err := os.MkdirTemp("", "mgradm-*")
 	if err != nil {
-		return []string{}, fmt.Errorf(L("failed to create temporary directory: %s"), err)
+		return []string{}, utils.Errorf(err, L("failed to create temporary directory: %s"))
 	}
 	defer os.RemoveAll(crdsDir)
 
 	issuerPath := filepath.Join(crdsDir, "issuer.yaml")
 
-	issuerData := templates.IssuerTemplateData{
-		Namespace:   helmFlags.Uyuni.Namespace,
-		Country:     sslFlags.Country,
-		State:       sslFlags.State,
-		City:        sslFlags.City,
-		Org:         sslFlags.Org,
-		OrgUnit:     sslFlags.OU,
-		Email:       sslFlags.Email,
-		Fqdn:        fqdn,
-		RootCa:      rootCa,
-		Key:         tlsCert.Key,
-		Certificate: tlsCert.Cert,
-	}
-
-	if err = utils.WriteTemplateToFile(issuerData, issuerPath, 0500, true); err != nil {
-		return []string{}, fmt.Errorf(L("failed to generate issuer definition: %s"), err)
+	if err = writeIssuer(issuerPath, helmFlags.Uyuni.Namespace, sslFlags, rootCa, tlsCert, fqdn); err != nil {
+		return []string{}, utils.Errorf(err, L("failed to generate issuer definition: %s"))
 	}
 
-	err = utils.RunCmd("kubectl", "apply", "-f", issuerPath)
+	err = utils.RunCmd("kubectl", kubernetes.KubectlArgs("apply", "-f", issuerPath)...)
 	if err != nil {
 		log.Fatal().Err(err).Msg(L("Failed to create issuer"))
 	}
 
 	// Wait for issuer to be ready
 	for i := 0; i < 60; i++ {
-		out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "get", "-o=jsonpath={.status.conditions[*].type}",
-			"issuer", "uyuni-ca-issuer")
+		out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", kubernetes.KubectlArgs("get", "-o=jsonpath={.status.conditions[*].type}",
+			"issuer", "uyuni-ca-issuer", "-n", helmFlags.Uyuni.Namespace)...)
 		if err == nil && string(out) == "Ready" {
-			return []string{"--set-json", "ingressSslAnnotations={\"cert-manager.io/issuer\": \"uyuni-ca-issuer\"}"}, nil
+			return issuerHelmArgs, nil
 		}
 		time.Sleep(1 * time.Second)
 	}
@@ -129,33 +140,33 @@ func installCertManager(helmFlags *cmd_utils.HelmFlags, kubeconfig string, image
 		}
 		// The installedby label will be used to only uninstall what we installed
 		if err := kubernetes.HelmUpgrade(kubeconfig, namespace, true, repo, "cert-manager", chart, version, args...); err != nil {
-			return fmt.Errorf(L("cannot run helm upgrade: %s"), err)
+			return utils.Errorf(err, L("cannot run helm upgrade: %s"))
 		}
 	}
 
 	// Wait for cert-manager to be ready
 	err := kubernetes.WaitForDeployment("", "cert-manager-webhook", "webhook")
 	if err != nil {
-		return fmt.Errorf(L("cannot deploy: %s"), err)
+		return utils.Errorf(err, L("cannot deploy: %s"))
 	}
 
 	return nil
 }
 
-func extractCaCertToConfig() {
+func extractCaCertToConfig(namespace string) {
 	// TODO Replace with [trust-manager](https://cert-manager.io/docs/projects/trust-manager/) to automate this
 	const jsonPath = "-o=jsonpath={.data.ca\\.crt}"
 
 	log.Info().Msg(L("Extracting CA certificate to a configmap"))
 	// Skip extracting if the configmap is already present
-	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "get", "configmap", "uyuni-ca", jsonPath)
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", kubernetes.KubectlArgs("get", "configmap", "uyuni-ca", jsonPath, "-n", namespace)...)
 	log.Info().Msgf(L("CA cert: %s"), string(out))
 	if err == nil && len(out) > 0 {
 		log.Info().Msg(L("uyuni-ca configmap already existing, skipping extraction"))
 		return
 	}
 
-	out, err = utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "get", "secret", "uyuni-ca", jsonPath)
+	out, err = utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", kubernetes.KubectlArgs("get", "secret", "uyuni-ca", jsonPath, "-n", namespace)...)
 	if err != nil {
 		log.Fatal().Err(err).Msgf(L("Failed to get uyuni-ca certificate"))
 	}
@@ -165,12 +176,12 @@ func extractCaCertToConfig() {
 		log.Fatal().Err(err).Msgf(L("Failed to base64 decode CA certificate"))
 	}
 
-	createCaConfig(decoded)
+	createCaConfig(namespace, decoded)
 }
 
-func createCaConfig(ca []byte) {
+func createCaConfig(namespace string, ca []byte) {
 	valueArg := "--from-literal=ca.crt=" + string(ca)
-	if err := utils.RunCmd("kubectl", "create", "configmap", "uyuni-ca", valueArg); err != nil {
+	if err := utils.RunCmd("kubectl", kubernetes.KubectlArgs("create", "configmap", "uyuni-ca", valueArg, "-n", namespace)...); err != nil {
 		log.Fatal().Err(err).Msg(L("Failed to create uyuni-ca config map from certificate"))
 	}
 }
@@ -25,12 +25,15 @@ const HELM_APP_NAME = "uyuni"
 
 // Deploy execute a deploy of a given image and helm to a cluster.
 func Deploy(cnx *shared.Connection, imageFlags *types.ImageFlags,
-	helmFlags *cmd_utils.HelmFlags, sslFlags *cmd_utils.SslCertFlags, clusterInfos *kubernetes.ClusterInfos,
-	fqdn string, debug bool, helmArgs ...string) error {
+	helmFlags *cmd_utils.HelmFlags, sslFlags *cmd_utils.SslCertFlags, exposeFlags *cmd_utils.ExposeFlags,
+	clusterInfos *kubernetes.ClusterInfos, fqdn string, debug bool, helmArgs ...string) error {
 	// If installing on k3s, install the traefik helm config in manifests
+	// This is only needed if the ports are exposed by the ingress controller.
 	isK3s := clusterInfos.IsK3s()
 	IsRke2 := clusterInfos.IsRke2()
-	if isK3s {
+	if !exposeFlags.UsesIngress() {
+		log.Debug().Msgf("Exposing the ports using %s, skipping the ingress controller configuration", exposeFlags.Mode)
+	} else if isK3s {
 		InstallK3sTraefikConfig(debug)
 	} else if IsRke2 {
 		kubernetes.InstallRke2NginxConfig(utils.TCP_PORTS, utils.UDP_PORTS, helmFlags.Uyuni.Namespace)
@@ -38,19 +41,22 @@ func Deploy(cnx *shared.Connection, imageFlags *types.ImageFlags,
 
 	serverImage, err := utils.ComputeImage(imageFlags.Name, imageFlags.Tag)
 	if err != nil {
-		return fmt.Errorf(L("failed to compute image URL: %s"), err)
+		return utils.Errorf(err, L("failed to compute image URL: %s"))
 	}
 
 	// Install the uyuni server helm chart
 	err = UyuniUpgrade(serverImage, imageFlags.PullPolicy, helmFlags, clusterInfos.GetKubeconfig(), fqdn, clusterInfos.Ingress, helmArgs...)
 	if err != nil {
-		return fmt.Errorf(L("cannot upgrade: %s"), err)
+		return utils.Errorf(err, L("cannot upgrade: %s"))
 	}
 
 	// Wait for the pod to be started
 	err = kubernetes.WaitForDeployment(helmFlags.Uyuni.Namespace, HELM_APP_NAME, "uyuni")
 	if err != nil {
-		return fmt.Errorf(L("cannot deploy: %s"), err)
+		return utils.Errorf(err, L("cannot deploy: %s"))
+	}
+	if err := kubernetes.EnsurePodDisruptionBudget(helmFlags.Uyuni.Namespace, "uyuni"); err != nil {
+		return err
 	}
 	return cnx.WaitForServer()
 }
@@ -65,12 +71,12 @@ func DeployCertificate(helmFlags *cmd_utils.HelmFlags, sslFlags *cmd_utils.SslCe
 		// Install cert-manager and a self-signed issuer ready for use
 		issuerArgs, err := installSslIssuers(helmFlags, sslFlags, rootCa, ca, kubeconfig, fqdn, imagePullPolicy)
 		if err != nil {
-			return []string{}, fmt.Errorf(L("cannot install cert-manager and self-sign issuer: %s"), err)
+			return []string{}, utils.Errorf(err, L("cannot install cert-manager and self-sign issuer: %s"))
 		}
 		helmArgs = append(helmArgs, issuerArgs...)
 
 		// Extract the CA cert into uyuni-ca config map as the container shouldn't have the CA secret
-		extractCaCertToConfig()
+		extractCaCertToConfig(helmFlags.Uyuni.Namespace)
 	}
 
 	return helmArgs, nil
@@ -84,7 +90,7 @@ func DeployExistingCertificate(helmFlags *cmd_utils.HelmFlags, sslFlags *cmd_uti
 	installTlsSecret(helmFlags.Uyuni.Namespace, serverCrt, serverKey, rootCaCrt)
 
 	// Extract the CA cert into uyuni-ca config map as the container shouldn't have the CA secret
-	extractCaCertToConfig()
+	extractCaCertToConfig(helmFlags.Uyuni.Namespace)
 }
 
 // UyuniUpgrade runs an helm upgrade using images and helm configuration as parameters.
@@ -92,6 +98,28 @@ func UyuniUpgrade(serverImage string, pullPolicy string, helmFlags *cmd_utils.He
 	fqdn string, ingress string, helmArgs ...string) error {
 	log.Info().Msg(L("Installing Uyuni"))
 
+	helmParams := uyuniHelmParams(serverImage, pullPolicy, helmFlags, fqdn, ingress, helmArgs...)
+	namespace := helmFlags.Uyuni.Namespace
+	chart := helmFlags.Uyuni.Chart
+	version := helmFlags.Uyuni.Version
+	return kubernetes.HelmUpgrade(kubeconfig, namespace, true, "", HELM_APP_NAME, chart, version, helmParams...)
+}
+
+// UpdateValues changes some values of the deployed uyuni helm release, keeping the other ones.
+//
+// The values are passed as key=value strings.
+func UpdateValues(helmFlags *cmd_utils.HelmFlags, kubeconfig string, namespace string, values ...string) error {
+	helmParams := []string{"--reuse-values"}
+	for _, value := range values {
+		helmParams = append(helmParams, "--set", value)
+	}
+	return kubernetes.HelmUpgrade(kubeconfig, namespace, false, "", HELM_APP_NAME,
+		helmFlags.Uyuni.Chart, helmFlags.Uyuni.Version, helmParams...)
+}
+
+// uyuniHelmParams computes the uyuni helm chart parameters.
+func uyuniHelmParams(serverImage string, pullPolicy string, helmFlags *cmd_utils.HelmFlags,
+	fqdn string, ingress string, helmArgs ...string) []string {
 	// The guessed ingress is passed before the user's value to let the user override it in case we got it wrong.
 	helmParams := []string{
 		"--set", "ingress=" + ingress,
@@ -108,12 +136,7 @@ func UyuniUpgrade(serverImage string, pullPolicy string, helmFlags *cmd_utils.He
 		"--set", "pullPolicy="+kubernetes.GetPullPolicy(pullPolicy),
 		"--set", "fqdn="+fqdn)
 
-	helmParams = append(helmParams, helmArgs...)
-
-	namespace := helmFlags.Uyuni.Namespace
-	chart := helmFlags.Uyuni.Chart
-	version := helmFlags.Uyuni.Version
-	return kubernetes.HelmUpgrade(kubeconfig, namespace, true, "", HELM_APP_NAME, chart, version, helmParams...)
+	return append(helmParams, helmArgs...)
 }
 
 // Upgrade will upgrade a server in a kubernetes cluster.
@@ -121,25 +144,44 @@ func Upgrade(
 	globalFlags *types.GlobalFlags,
 	image *types.ImageFlags,
 	migrationImage *types.ImageFlags,
+	force bool,
+	pgsqlFlags cmd_utils.PgsqlUpgradeFlags,
 	helm cmd_utils.HelmFlags,
+	kubernetesFlags *cmd_utils.KubernetesFlags,
+	atomic bool,
 	cmd *cobra.Command,
 	args []string,
 ) error {
-	for _, binary := range []string{"kubectl", "helm"} {
-		if _, err := exec.LookPath(binary); err != nil {
-			return fmt.Errorf(L("install %s before running this command"), binary)
-		}
+	// The commands run in the server pod still go through kubectl exec
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return fmt.Errorf(L("install %s before running this command"), "kubectl")
 	}
-	cnx := shared.NewConnection("kubectl", "", kubernetes.ServerFilter)
+	helmArgs, err := kubernetesFlags.HelmArgs(ServerPorts(false))
+	if err != nil {
+		return err
+	}
+
+	namespace := helm.Uyuni.Namespace
+	cnx := shared.NewConnection("kubectl", "", kubernetes.ServerFilter, namespace)
 
 	serverImage, err := utils.ComputeImage(image.Name, image.Tag)
 	if err != nil {
-		return fmt.Errorf(L("failed to compute image URL: %s"), err)
+		return utils.Errorf(err, L("failed to compute image URL: %s"))
 	}
 
-	inspectedValues, err := kubernetes.InspectKubernetes(serverImage, image.PullPolicy)
+	progress := utils.NewProgress("upgrade", 5)
+	progress.Step(L("Inspecting the new image"))
+	inspectedValues, err := kubernetes.InspectKubernetes(namespace, serverImage, image.PullPolicy)
 	if err != nil {
-		return fmt.Errorf(L("cannot inspect kubernetes values: %s"), err)
+		return utils.Errorf(err, L("cannot inspect kubernetes values: %s"))
+	}
+
+	if state, err := kubernetes.ReadState(namespace); err == nil && state != nil {
+		log.Info().Msgf(L("Upgrading from image %[1]s installed at %[2]s"), state.Image, state.InstalledAt)
+		// The helm values are not reused: keep the recorded timezone
+		if state.Timezone != "" {
+			helmArgs = append([]string{"--set", "timezone=" + state.Timezone}, helmArgs...)
+		}
 	}
 
 	err = cmd_utils.SanityCheck(cnx, inspectedValues, serverImage)
@@ -147,6 +189,10 @@ func Upgrade(
 		return err
 	}
 
+	if err := cmd_utils.CheckVersionSkew(inspectedValues, force); err != nil {
+		return err
+	}
+
 	fqdn, exist := inspectedValues["fqdn"]
 	if !exist {
 		return fmt.Errorf(L("inspect function did non return fqdn value"))
@@ -161,32 +207,41 @@ func Upgrade(
 	scriptDir, err := os.MkdirTemp("", "mgradm-*")
 	defer os.RemoveAll(scriptDir)
 	if err != nil {
-		return fmt.Errorf(L("failed to create temporary directory: %s"), err)
+		return utils.Errorf(err, L("failed to create temporary directory: %s"))
 	}
 
 	//this is needed because folder with script needs to be mounted
 	//check the node before scaling down
-	nodeName, err := kubernetes.GetNode("uyuni")
+	nodeName, err := kubernetes.GetNode(namespace, kubernetes.ServerFilter)
 	if err != nil {
-		return fmt.Errorf(L("cannot find node running uyuni: %s"), err)
+		return utils.Errorf(err, L("cannot find node running uyuni: %s"))
 	}
 
-	err = kubernetes.ReplicasTo(kubernetes.ServerFilter, 0)
+	if err := cmd_utils.CheckServerActivity(cnx, force); err != nil {
+		return err
+	}
+
+	progress.Step(L("Stopping the server"))
+	err = kubernetes.ReplicasTo(namespace, kubernetes.ServerFilter, 0)
 	if err != nil {
-		return fmt.Errorf(L("cannot set replica to 0: %s"), err)
+		return utils.Errorf(err, L("cannot set replica to 0: %s"))
 	}
 
 	defer func() {
 		// if something is running, we don't need to set replicas to 1
-		if _, err = kubernetes.GetNode("uyuni"); err != nil {
-			err = kubernetes.ReplicasTo(kubernetes.ServerFilter, 1)
+		if _, err = kubernetes.GetNode(namespace, kubernetes.ServerFilter); err != nil {
+			err = kubernetes.ReplicasTo(namespace, kubernetes.ServerFilter, 1)
 		}
 	}()
-	if inspectedValues["image_pg_version"] > inspectedValues["current_pg_version"] {
+	progress.Step(L("Upgrading the database"))
+	externalDb := cmd_utils.IsExternalDb(inspectedValues)
+	if externalDb {
+		log.Info().Msgf(L("The database is external on %s: skipping the PostgreSQL upgrade"), inspectedValues["db_host"])
+	} else if inspectedValues["image_pg_version"] > inspectedValues["current_pg_version"] {
 		log.Info().Msgf(L("Previous PostgreSQL is %s, new one is %s. Performing a DB version upgrade..."), inspectedValues["current_pg_version"], inspectedValues["image_pg_version"])
 
-		if err := RunPgsqlVersionUpgrade(*image, *migrationImage, nodeName, inspectedValues["current_pg_version"], inspectedValues["image_pg_version"]); err != nil {
-			return fmt.Errorf(L("cannot run PostgreSQL version upgrade script: %s"), err)
+		if err := RunPgsqlVersionUpgrade(namespace, *image, *migrationImage, nodeName, inspectedValues["current_pg_version"], inspectedValues["image_pg_version"], pgsqlFlags); err != nil {
+			return utils.WithCode(utils.CodeDBUpgrade, utils.Errorf(err, L("cannot run PostgreSQL version upgrade script: %s")))
 		}
 	} else if inspectedValues["image_pg_version"] == inspectedValues["current_pg_version"] {
 		log.Info().Msgf(L("Upgrading to %s without changing PostgreSQL version"), inspectedValues["uyuni_release"])
@@ -195,18 +250,40 @@ func Upgrade(
 	}
 
 	schemaUpdateRequired := inspectedValues["current_pg_version"] != inspectedValues["image_pg_version"]
-	if err := RunPgsqlFinalizeScript(serverImage, image.PullPolicy, nodeName, schemaUpdateRequired); err != nil {
-		return fmt.Errorf(L("cannot run PostgreSQL version upgrade script: %s"), err)
+	if externalDb {
+		// The schema is updated when the server starts
+		log.Debug().Msg("Skipping the PostgreSQL finalization script for the external database")
+	} else if err := RunPgsqlFinalizeScript(namespace, serverImage, image.PullPolicy, nodeName, schemaUpdateRequired, pgsqlFlags); err != nil {
+		return utils.WithCode(utils.CodeDBUpgrade, utils.Errorf(err, L("cannot run PostgreSQL version upgrade script: %s")))
 	}
 
-	if err := RunPostUpgradeScript(serverImage, image.PullPolicy, nodeName); err != nil {
-		return fmt.Errorf(L("cannot run post upgrade script: %s"), err)
+	progress.Step(L("Running the post upgrade script"))
+	if err := RunPostUpgradeScript(namespace, serverImage, image.PullPolicy, nodeName); err != nil {
+		return utils.Errorf(err, L("cannot run post upgrade script: %s"))
 	}
 
-	err = UyuniUpgrade(serverImage, image.PullPolicy, &helm, kubeconfig, fqdn, clusterInfos.Ingress)
+	progress.Step(L("Deploying the new server"))
+	if atomic {
+		// helm waits for the resources and rolls back to the previous revision if anything fails, hooks included
+		helmArgs = append(helmArgs, "--atomic")
+	}
+	err = UyuniUpgrade(serverImage, image.PullPolicy, &helm, kubeconfig, fqdn, clusterInfos.Ingress, helmArgs...)
 	if err != nil {
-		return fmt.Errorf(L("cannot upgrade to image %s: %s"), serverImage, err)
+		if atomic {
+			if release, err := kubernetes.GetHelmRelease(kubeconfig, namespace, HELM_APP_NAME); err == nil {
+				log.Warn().Msgf(L("Helm release rolled back to revision %d"), release.Revision)
+			}
+		}
+		return utils.Errorf(err, L("cannot upgrade to image %s: %s"), serverImage)
 	}
 
-	return kubernetes.WaitForDeployment(helm.Uyuni.Namespace, "uyuni", "uyuni")
+	if err := kubernetes.WaitForDeployment(namespace, "uyuni", "uyuni"); err != nil {
+		return err
+	}
+	if err := kubernetes.EnsurePodDisruptionBudget(namespace, "uyuni"); err != nil {
+		return err
+	}
+	saveUpgradeState(namespace, serverImage)
+	progress.Done(L("Server upgraded"))
+	return nil
 }
@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestUyuniHelmParams(t *testing.T) {
+	var helmFlags cmd_utils.HelmFlags
+	helmFlags.Uyuni.Namespace = "uyuni"
+	helmFlags.Uyuni.Values = "/root/values.yaml"
+
+	params := uyuniHelmParams("registry.opensuse.org/uyuni/server:2024.07", "IfNotPresent", &helmFlags,
+		"uyuni.example.com", "traefik", "--set", "timezone=Europe/Berlin", "--atomic")
+	testutils.AssertGolden(t, "helm-upgrade-params.txt", []byte(strings.Join(params, "\n")+"\n"))
+}
+
+func TestUyuniHelmValuesSchema(t *testing.T) {
+	schemaPath := os.Getenv(testutils.ChartSchemaEnv)
+	if schemaPath == "" {
+		t.Skipf("set %s to the values.schema.json file of the chart to validate the helm values", testutils.ChartSchemaEnv)
+	}
+
+	var helmFlags cmd_utils.HelmFlags
+	params := uyuniHelmParams("registry.opensuse.org/uyuni/server:2024.07", "IfNotPresent", &helmFlags,
+		"uyuni.example.com", "traefik", "--set", "timezone=Europe/Berlin")
+	values, err := kubernetes.HelmValues(params)
+	if err != nil {
+		t.Fatalf("failed to compute the helm values: %s", err)
+	}
+	testutils.ValidateValuesSchema(t, schemaPath, values)
+}
@@ -17,6 +17,16 @@ import (
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
+// ServerPorts returns the non-HTTP ports to expose for the server.
+func ServerPorts(debug bool) []types.PortMap {
+	ports := []types.PortMap{}
+	ports = append(ports, utils.TCP_PORTS...)
+	if debug {
+		ports = append(ports, utils.DEBUG_PORTS...)
+	}
+	return append(ports, utils.UDP_PORTS...)
+}
+
 // InstallK3sTraefikConfig installs the K3s Traefik configuration.
 func InstallK3sTraefikConfig(debug bool) {
 	tcpPorts := []types.PortMap{}
@@ -29,7 +39,15 @@ func InstallK3sTraefikConfig(debug bool) {
 }
 
 // RunPgsqlVersionUpgrade perform a PostgreSQL major upgrade.
-func RunPgsqlVersionUpgrade(image types.ImageFlags, migrationImage types.ImageFlags, nodeName string, oldPgsql string, newPgsql string) error {
+func RunPgsqlVersionUpgrade(
+	namespace string,
+	image types.ImageFlags,
+	migrationImage types.ImageFlags,
+	nodeName string,
+	oldPgsql string,
+	newPgsql string,
+	pgsqlFlags adm_utils.PgsqlUpgradeFlags,
+) error {
 	scriptDir, err := os.MkdirTemp("", "mgradm-*")
 	defer os.RemoveAll(scriptDir)
 	if err != nil {
@@ -44,24 +62,24 @@ func RunPgsqlVersionUpgrade(image types.ImageFlags, migrationImage types.ImageFl
 		if migrationImage.Name == "" {
 			migrationImageUrl, err = utils.ComputeImage(image.Name, image.Tag, fmt.Sprintf("-migration-%s-%s", oldPgsql, newPgsql))
 			if err != nil {
-				return fmt.Errorf(L("failed to compute image URL: %s"), err)
+				return utils.Errorf(err, L("failed to compute image URL: %s"))
 			}
 		} else {
 			migrationImageUrl, err = utils.ComputeImage(migrationImage.Name, image.Tag)
 			if err != nil {
-				return fmt.Errorf(L("failed to compute image URL: %s"), err)
+				return utils.Errorf(err, L("failed to compute image URL: %s"))
 			}
 		}
 
 		log.Info().Msgf(L("Using migration image %s"), migrationImageUrl)
-		pgsqlVersionUpgradeScriptName, err := adm_utils.GeneratePgsqlVersionUpgradeScript(scriptDir, oldPgsql, newPgsql, true)
+		pgsqlVersionUpgradeScriptName, err := adm_utils.GeneratePgsqlVersionUpgradeScript(scriptDir, oldPgsql, newPgsql, true, pgsqlFlags)
 		if err != nil {
-			return fmt.Errorf(L("cannot generate PostgreSQL database version upgrade script: %s"), err)
+			return utils.Errorf(err, L("cannot generate PostgreSQL database version upgrade script: %s"))
 		}
 
 		//delete pending pod and then check the node, because in presence of more than a pod GetNode return is wrong
-		if err := kubernetes.DeletePod(pgsqlVersionUpgradeContainer, kubernetes.ServerFilter); err != nil {
-			return fmt.Errorf(L("cannot delete %s: %s"), pgsqlVersionUpgradeContainer, err)
+		if err := kubernetes.DeletePod(namespace, pgsqlVersionUpgradeContainer, kubernetes.ServerFilter); err != nil {
+			return utils.Errorf(err, L("cannot delete %s: %s"), pgsqlVersionUpgradeContainer)
 		}
 
 		//generate deploy data
@@ -88,29 +106,29 @@ func RunPgsqlVersionUpgrade(image types.ImageFlags, migrationImage types.ImageFl
 			return err
 		}
 
-		err = kubernetes.RunPod(pgsqlVersionUpgradeContainer, kubernetes.ServerFilter, migrationImageUrl, image.PullPolicy, "/var/lib/uyuni-tools/"+pgsqlVersionUpgradeScriptName, overridePgsqlVersioUpgrade)
+		err = kubernetes.RunPod(namespace, pgsqlVersionUpgradeContainer, kubernetes.ServerFilter, migrationImageUrl, image.PullPolicy, "/var/lib/uyuni-tools/"+pgsqlVersionUpgradeScriptName, overridePgsqlVersioUpgrade)
 		if err != nil {
-			return fmt.Errorf(L("error running container %s: %s"), pgsqlVersionUpgradeContainer, err)
+			return utils.Errorf(err, L("error running container %s: %s"), pgsqlVersionUpgradeContainer)
 		}
 	}
 	return nil
 }
 
 // RunPgsqlFinalizeScript run the script with all the action required to a db after upgrade.
-func RunPgsqlFinalizeScript(serverImage string, pullPolicy string, nodeName string, schemaUpdateRequired bool) error {
+func RunPgsqlFinalizeScript(namespace string, serverImage string, pullPolicy string, nodeName string, schemaUpdateRequired bool, pgsqlFlags adm_utils.PgsqlUpgradeFlags) error {
 	scriptDir, err := os.MkdirTemp("", "mgradm-*")
 	defer os.RemoveAll(scriptDir)
 	if err != nil {
 		return fmt.Errorf(L("failed to create temporary directory: %s"))
 	}
 	pgsqlFinalizeContainer := "uyuni-finalize-pgsql"
-	pgsqlFinalizeScriptName, err := adm_utils.GenerateFinalizePostgresScript(scriptDir, true, schemaUpdateRequired, true, true, true)
+	pgsqlFinalizeScriptName, err := adm_utils.GenerateFinalizePostgresScript(scriptDir, true, schemaUpdateRequired, true, true, true, pgsqlFlags)
 	if err != nil {
-		return fmt.Errorf(L("cannot generate PostgreSQL finalization script %s"), err)
+		return utils.Errorf(err, L("cannot generate PostgreSQL finalization script %s"))
 	}
 	//delete pending pod and then check the node, because in presence of more than a pod GetNode return is wrong
-	if err := kubernetes.DeletePod(pgsqlFinalizeContainer, kubernetes.ServerFilter); err != nil {
-		return fmt.Errorf(L("cannot delete %s: %s"), pgsqlFinalizeContainer, err)
+	if err := kubernetes.DeletePod(namespace, pgsqlFinalizeContainer, kubernetes.ServerFilter); err != nil {
+		return utils.Errorf(err, L("cannot delete %s: %s"), pgsqlFinalizeContainer)
 	}
 	//generate deploy data
 	pgsqlFinalizeDeployData := types.Deployment{
@@ -134,15 +152,15 @@ func RunPgsqlFinalizeScript(serverImage string, pullPolicy string, nodeName stri
 	if err != nil {
 		return err
 	}
-	err = kubernetes.RunPod(pgsqlFinalizeContainer, kubernetes.ServerFilter, serverImage, pullPolicy, "/var/lib/uyuni-tools/"+pgsqlFinalizeScriptName, overridePgsqlFinalize)
+	err = kubernetes.RunPod(namespace, pgsqlFinalizeContainer, kubernetes.ServerFilter, serverImage, pullPolicy, "/var/lib/uyuni-tools/"+pgsqlFinalizeScriptName, overridePgsqlFinalize)
 	if err != nil {
-		return fmt.Errorf(L("error running container %s: %s"), pgsqlFinalizeContainer, err)
+		return utils.Errorf(err, L("error running container %s: %s"), pgsqlFinalizeContainer)
 	}
 	return nil
 }
 
 // RunPostUpgradeScript run the script with the changes to apply after the upgrade.
-func RunPostUpgradeScript(serverImage string, pullPolicy string, nodeName string) error {
+func RunPostUpgradeScript(namespace string, serverImage string, pullPolicy string, nodeName string) error {
 	scriptDir, err := os.MkdirTemp("", "mgradm-*")
 	defer os.RemoveAll(scriptDir)
 	if err != nil {
@@ -151,12 +169,12 @@ func RunPostUpgradeScript(serverImage string, pullPolicy string, nodeName string
 	postUpgradeContainer := "uyuni-post-upgrade"
 	postUpgradeScriptName, err := adm_utils.GeneratePostUpgradeScript(scriptDir, "localhost")
 	if err != nil {
-		return fmt.Errorf(L("cannot generate PostgreSQL finalization script %s"), err)
+		return utils.Errorf(err, L("cannot generate PostgreSQL finalization script %s"))
 	}
 
 	//delete pending pod and then check the node, because in presence of more than a pod GetNode return is wrong
-	if err := kubernetes.DeletePod(postUpgradeContainer, kubernetes.ServerFilter); err != nil {
-		return fmt.Errorf(L("cannot delete %s: %s"), postUpgradeContainer, err)
+	if err := kubernetes.DeletePod(namespace, postUpgradeContainer, kubernetes.ServerFilter); err != nil {
+		return utils.Errorf(err, L("cannot delete %s: %s"), postUpgradeContainer)
 	}
 	//generate deploy data
 	postUpgradeDeployData := types.Deployment{
@@ -181,9 +199,9 @@ func RunPostUpgradeScript(serverImage string, pullPolicy string, nodeName string
 		return err
 	}
 
-	err = kubernetes.RunPod(postUpgradeContainer, kubernetes.ServerFilter, serverImage, pullPolicy, "/var/lib/uyuni-tools/"+postUpgradeScriptName, overridePostUpgrade)
+	err = kubernetes.RunPod(namespace, postUpgradeContainer, kubernetes.ServerFilter, serverImage, pullPolicy, "/var/lib/uyuni-tools/"+postUpgradeScriptName, overridePostUpgrade)
 	if err != nil {
-		return fmt.Errorf(L("error running container %s: %s"), postUpgradeContainer, err)
+		return utils.Errorf(err, L("error running container %s: %s"), postUpgradeContainer)
 	}
 	return nil
 }
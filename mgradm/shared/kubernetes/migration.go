@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// MigrationJobName is the name of the kubernetes job running the migration script.
+const MigrationJobName = "uyuni-migration"
+
+// RunMigrationJob runs the migration script in a job mounting the server persistent volumes.
+//
+// The server needs to be scaled down before as the volumes may not be mounted by several pods.
+func RunMigrationJob(serverImage string, pullPolicy string, namespace string, nodeName string, scriptDir string,
+	sshAuthSocket string, sshConfigPath string, sshKnownhostsPath string) error {
+	mounts := append([]types.VolumeMount{
+		{MountPath: "/var/lib/uyuni-tools", Name: "var-lib-uyuni-tools"},
+		{MountPath: filepath.Dir(sshAuthSocket), Name: "ssh-auth-sock"},
+	}, utils.ServerVolumeMounts...)
+	volumes := append([]types.Volume{
+		{Name: "var-lib-uyuni-tools", HostPath: &types.HostPath{Path: scriptDir, Type: "Directory"}},
+		{Name: "ssh-auth-sock", HostPath: &types.HostPath{Path: filepath.Dir(sshAuthSocket), Type: "Directory"}},
+	}, utils.ServerVolumes...)
+
+	if sshConfigPath != "" {
+		mounts = append(mounts, types.VolumeMount{MountPath: "/tmp/ssh_config", Name: "ssh-config"})
+		volumes = append(volumes, types.Volume{Name: "ssh-config", HostPath: &types.HostPath{Path: sshConfigPath, Type: "File"}})
+	}
+
+	if sshKnownhostsPath != "" {
+		mounts = append(mounts, types.VolumeMount{MountPath: "/etc/ssh/ssh_known_hosts", Name: "ssh-known-hosts"})
+		volumes = append(volumes, types.Volume{Name: "ssh-known-hosts", HostPath: &types.HostPath{Path: sshKnownhostsPath, Type: "File"}})
+	}
+
+	job := kubernetes.NewJob(MigrationJobName, namespace, types.Spec{
+		NodeName: nodeName,
+		Containers: []types.Container{
+			{
+				Name:            MigrationJobName,
+				Image:           serverImage,
+				ImagePullPolicy: kubernetes.GetPullPolicy(pullPolicy),
+				Command:         []string{"/var/lib/uyuni-tools/migrate.sh"},
+				Env:             []types.EnvVar{{Name: "SSH_AUTH_SOCK", Value: sshAuthSocket}},
+				VolumeMounts:    mounts,
+			},
+		},
+		Volumes: volumes,
+	})
+
+	log.Info().Msg(L("Migrating server"))
+	if err := kubernetes.RunJob(job); err != nil {
+		return utils.Errorf(err, L("error running the migration job: %s"))
+	}
+	return nil
+}
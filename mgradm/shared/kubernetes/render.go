@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/ssl"
+	cmd_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// Render writes the uyuni helm chart values and the additional manifests into a directory without applying them.
+//
+// This is meant to commit the deployment to a git repository and apply it with a GitOps tool.
+func Render(dir string, imageFlags *types.ImageFlags, helmFlags *cmd_utils.HelmFlags,
+	sslFlags *cmd_utils.SslCertFlags, fqdn string, ingress string, helmArgs ...string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return utils.Errorf(err, L("failed to create %[1]s directory: %[2]s"), dir)
+	}
+
+	serverImage, err := utils.ComputeImage(imageFlags.Name, imageFlags.Tag)
+	if err != nil {
+		return utils.Errorf(err, L("failed to compute image URL: %s"))
+	}
+
+	namespace := helmFlags.Uyuni.Namespace
+	files := []string{}
+	if sslFlags.UseExisting() {
+		serverCrt, rootCaCrt := ssl.OrderCas(&sslFlags.Ca, &sslFlags.Server)
+		serverKey := utils.ReadFile(sslFlags.Server.Key)
+
+		secretPath := filepath.Join(dir, "uyuni-cert-secret.yaml")
+		if err := writeTlsSecret(secretPath, namespace, serverCrt, serverKey, rootCaCrt); err != nil {
+			return utils.Errorf(err, L("failed to generate uyuni-crt secret definition: %s"))
+		}
+		configPath := filepath.Join(dir, "uyuni-ca-configmap.yaml")
+		if err := writeCaConfig(configPath, namespace, rootCaCrt); err != nil {
+			return err
+		}
+		files = append(files, secretPath, configPath)
+	} else {
+		issuerPath := filepath.Join(dir, "issuer.yaml")
+		if err := writeIssuer(issuerPath, namespace, sslFlags, "", &ssl.SslPair{}, fqdn); err != nil {
+			return utils.Errorf(err, L("failed to generate issuer definition: %s"))
+		}
+		files = append(files, issuerPath)
+		helmArgs = append(helmArgs, issuerHelmArgs...)
+		log.Warn().Msg(L("cert-manager needs to be installed in the cluster and the uyuni-ca ConfigMap needs to be created from the uyuni-ca secret generated by the issuer"))
+	}
+
+	params := uyuniHelmParams(serverImage, imageFlags.PullPolicy, helmFlags, fqdn, ingress, helmArgs...)
+	values, err := kubernetes.HelmValues(params)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return utils.Errorf(err, L("failed to serialize the helm values: %s"))
+	}
+	valuesPath := filepath.Join(dir, "values.yaml")
+	if err := os.WriteFile(valuesPath, data, 0600); err != nil {
+		return utils.Errorf(err, L("cannot write %s file: %s"), valuesPath)
+	}
+	files = append(files, valuesPath)
+
+	log.Info().Msgf(L("Deploy the %[1]s chart in the %[2]s namespace using the rendered files: %[3]s"),
+		helmFlags.Uyuni.Chart, namespace, strings.Join(files, ", "))
+	log.Warn().Msg(L("The server setup is not part of the rendered files and needs to be run once the server is deployed"))
+	return nil
+}
+
+// writeCaConfig writes the uyuni-ca ConfigMap definition.
+//
+// The cluster flags are not passed to kubectl as rendering the ConfigMap doesn't need a cluster.
+func writeCaConfig(path string, namespace string, ca []byte) error {
+	args := []string{"create", "configmap", "uyuni-ca", "--from-literal=ca.crt=" + string(ca), "-n", namespace,
+		"--dry-run=client", "-o", "yaml"}
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", args...)
+	if err != nil {
+		return utils.Errorf(err, L("failed to generate the uyuni-ca ConfigMap: %s"))
+	}
+	if err := os.WriteFile(path, out, 0600); err != nil {
+		return utils.Errorf(err, L("cannot write %s file: %s"), path)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// SaveInstallState records the state of a newly deployed server with the flags set by the user in a ConfigMap.
+//
+// Failing to save the state only results in a warning as the server is deployed.
+func SaveInstallState(namespace string, image string, timezone string, userFlags map[string]string) {
+	state := utils.NewDeploymentState("kubectl", image, "", utils.GetServerVolumeNames(), userFlags)
+	state.Timezone = timezone
+	if err := kubernetes.WriteState(namespace, state); err != nil {
+		log.Warn().Err(err).Msg(L("Failed to save the deployment state"))
+	}
+}
+
+// saveUpgradeState records the new image in the state of the server.
+func saveUpgradeState(namespace string, image string) {
+	state, err := kubernetes.ReadState(namespace)
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Failed to read the deployment state"))
+	}
+	state = utils.UpdateDeploymentState(state, "kubectl", image, "")
+	if err := kubernetes.WriteState(namespace, state); err != nil {
+		log.Warn().Err(err).Msg(L("Failed to save the deployment state"))
+	}
+}
@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/templates"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// paygHostPaths returns the cloud instance files and folders existing on the host.
+func paygHostPaths() []string {
+	paths := []string{}
+	for _, hostPath := range utils.PaygHostPaths {
+		if _, err := os.Stat(hostPath); err != nil {
+			log.Debug().Msgf("Skipping missing %s cloud instance path", hostPath)
+			continue
+		}
+		paths = append(paths, hostPath)
+	}
+	return paths
+}
+
+// PaygArgs returns the podman arguments forwarding the cloud instance metadata to the server container.
+func PaygArgs() []string {
+	args := []string{}
+	for _, hostPath := range paygHostPaths() {
+		args = append(args, "-v", hostPath+":"+hostPath+":ro")
+	}
+	return args
+}
+
+// SetupBillingAdapter generates and enables the billing adapter service of the pay-as-you-go cloud instances.
+func SetupBillingAdapter(image string) error {
+	data := templates.BillingAdapterServiceTemplateData{
+		NamePrefix: "uyuni",
+		Network:    podman.UyuniNetwork,
+		Volume:     utils.BillingAdapterVolumeMount,
+		HostPaths:  paygHostPaths(),
+	}
+	servicePath := podman.GetServicePath(podman.ServerBillingAdapterService)
+	if err := utils.WriteTemplateToFile(data, servicePath, 0555, true); err != nil {
+		return utils.Errorf(err, L("failed to generate systemd service unit file: %s"))
+	}
+
+	err := podman.GenerateSystemdConfFile(podman.ServerBillingAdapterService, "Service", "Environment=UYUNI_IMAGE="+image)
+	if err != nil {
+		return utils.Errorf(err, L("cannot generate systemd conf file: %s"))
+	}
+
+	if err := podman.ReloadDaemon(false); err != nil {
+		return err
+	}
+	return podman.EnableService(podman.ServerBillingAdapterService)
+}
@@ -14,7 +14,6 @@ import (
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
-	install_shared "github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/shared"
 	"github.com/uyuni-project/uyuni-tools/mgradm/shared/ssl"
 	"github.com/uyuni-project/uyuni-tools/mgradm/shared/templates"
 	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
@@ -41,52 +40,41 @@ func GetExposedPorts(debug bool) []types.PortMap {
 	return ports
 }
 
-// GenerateAttestationSystemdService creates the coco attestation systemd files.
-func GenerateAttestationSystemdService(image string, db install_shared.DbFlags) error {
-	attestationData := templates.AttestationServiceTemplateData{
+// serverServiceData returns the data of the server systemd service template.
+func serverServiceData(tz string, debug bool, podmanArgs []string) templates.PodmanServiceTemplateData {
+	args := append(podman.GetCommonParams(), podmanArgs...)
+	return templates.PodmanServiceTemplateData{
+		Volumes:    utils.ServerVolumeMounts,
 		NamePrefix: "uyuni",
+		Args:       strings.Join(args, " "),
+		Ports:      GetExposedPorts(debug),
+		Timezone:   tz,
 		Network:    podman.UyuniNetwork,
-		Image:      image,
-	}
-	if err := utils.WriteTemplateToFile(attestationData, podman.GetServicePath(podman.ServerAttestationService), 0555, false); err != nil {
-		return fmt.Errorf(L("failed to generate systemd service unit file: %s"), err)
-	}
-
-	environment := fmt.Sprintf(`Environment=UYUNI_IMAGE=%s
-Environment=database_connection=jdbc:postgresql://uyuni-server.mgr.internal:%d/%s
-Environment=database_user=%s
-Environment=database_password=%s
-	`, image, db.Port, db.Name, db.User, db.Password)
-	if err := podman.GenerateSystemdConfFile(podman.ServerAttestationService, "Service", environment); err != nil {
-		return fmt.Errorf(L("cannot generate systemd conf file: %s"), err)
 	}
+}
 
-	return podman.ReloadDaemon(false)
+// RenderServerService returns the content of the server systemd service as it would be generated.
+func RenderServerService(tz string, debug bool, podmanArgs []string) ([]byte, error) {
+	data := serverServiceData(tz, debug, podmanArgs)
+	return utils.RenderTemplate(data, podman.GetServicePath(podman.ServerService))
 }
 
 // GenerateSystemdService creates a serverY systemd file.
-func GenerateSystemdService(tz string, image string, debug bool, podmanArgs []string) error {
+//
+// An existing service unit is only replaced if overwrite is true, like when converging a deployment.
+func GenerateSystemdService(tz string, image string, debug bool, podmanArgs []string, overwrite bool) error {
 	if err := podman.SetupNetwork(); err != nil {
-		return fmt.Errorf(L("cannot setup network: %s"), err)
+		return utils.Errorf(err, L("cannot setup network: %s"))
 	}
 
 	log.Info().Msg(L("Enabling system service"))
-	args := append(podman.GetCommonParams(), podmanArgs...)
-
-	data := templates.PodmanServiceTemplateData{
-		Volumes:    utils.ServerVolumeMounts,
-		NamePrefix: "uyuni",
-		Args:       strings.Join(args, " "),
-		Ports:      GetExposedPorts(debug),
-		Timezone:   tz,
-		Network:    podman.UyuniNetwork,
-	}
-	if err := utils.WriteTemplateToFile(data, podman.GetServicePath("uyuni-server"), 0555, false); err != nil {
-		return fmt.Errorf(L("failed to generate systemd service unit file: %s"), err)
+	data := serverServiceData(tz, debug, podmanArgs)
+	if err := utils.WriteTemplateToFile(data, podman.GetServicePath("uyuni-server"), 0555, overwrite); err != nil {
+		return utils.Errorf(err, L("failed to generate systemd service unit file: %s"))
 	}
 
 	if err := podman.GenerateSystemdConfFile("uyuni-server", "Service", "Environment=UYUNI_IMAGE="+image); err != nil {
-		return fmt.Errorf(L("cannot generate systemd conf file: %s"), err)
+		return utils.Errorf(err, L("cannot generate systemd conf file: %s"))
 	}
 	return podman.ReloadDaemon(false)
 }
@@ -118,13 +106,13 @@ func UpdateSslCertificate(cnx *shared.Connection, chain *ssl.CaChain, serverPair
 	}
 
 	if err := cnx.Copy(chain.Root, "server:"+rootCaPath, "root", "root"); err != nil {
-		return fmt.Errorf(L("cannot copy %s: %s"), rootCaPath, err)
+		return utils.Errorf(err, L("cannot copy %s: %s"), rootCaPath)
 	}
 	if err := cnx.Copy(serverPair.Cert, "server:"+serverCrtPath, "root", "root"); err != nil {
-		return fmt.Errorf(L("cannot copy %s: %s"), serverCrtPath, err)
+		return utils.Errorf(err, L("cannot copy %s: %s"), serverCrtPath)
 	}
 	if err := cnx.Copy(serverPair.Key, "server:"+serverKeyPath, "root", "root"); err != nil {
-		return fmt.Errorf(L("cannot copy %s: %s"), serverKeyPath, err)
+		return utils.Errorf(err, L("cannot copy %s: %s"), serverKeyPath)
 	}
 
 	for i, ca := range chain.Intermediate {
@@ -132,7 +120,7 @@ func UpdateSslCertificate(cnx *shared.Connection, chain *ssl.CaChain, serverPair
 		caPath := path.Join(certDir, caFilename)
 		args = append(args, "--intermediate-ca-file", caPath)
 		if err := cnx.Copy(ca, "server:"+caPath, "root", "root"); err != nil {
-			return fmt.Errorf(L("cannot copy %s: %s"), caPath, err)
+			return utils.Errorf(err, L("cannot copy %s: %s"), caPath)
 		}
 	}
 
@@ -159,10 +147,14 @@ func UpdateSslCertificate(cnx *shared.Connection, chain *ssl.CaChain, serverPair
 }
 
 // RunMigration migrate an existing remote server to a container.
-func RunMigration(serverImage string, pullPolicy string, sshAuthSocket string, sshConfigPath string, sshKnownhostsPath string, sourceFqdn string, user string) (string, string, string, error) {
-	scriptDir, err := adm_utils.GenerateMigrationScript(sourceFqdn, user, false)
+//
+// The returned report contains the data collected by the migration script.
+// In prepare mode, the data are only copied and the report has no data from the script.
+func RunMigration(serverImage string, pullPolicy string, sshAuthSocket string, sshConfigPath string, sshKnownhostsPath string, sourceFqdn string, user string, ssh adm_utils.SshFlags, prepare bool, final bool) (*adm_utils.MigrationReport, error) {
+	report := adm_utils.NewMigrationReport(sourceFqdn)
+	scriptDir, err := adm_utils.GenerateMigrationScript(sourceFqdn, user, ssh, false, prepare, final)
 	if err != nil {
-		return "", "", "", fmt.Errorf(L("cannot generate migration script: %s"), err)
+		return nil, utils.Errorf(err, L("cannot generate migration script: %s"))
 	}
 	defer os.RemoveAll(scriptDir)
 
@@ -183,43 +175,47 @@ func RunMigration(serverImage string, pullPolicy string, sshAuthSocket string, s
 
 	inspectedHostValues, err := utils.InspectHost()
 	if err != nil {
-		return "", "", "", fmt.Errorf(L("cannot inspect host values: %s"), err)
+		return nil, utils.Errorf(err, L("cannot inspect host values: %s"))
 	}
 
-	pullArgs := []string{}
-	_, scc_user_exist := inspectedHostValues["host_scc_username"]
-	_, scc_user_password := inspectedHostValues["host_scc_password"]
-	if scc_user_exist && scc_user_password {
-		pullArgs = append(pullArgs, "--creds", inspectedHostValues["host_scc_username"]+":"+inspectedHostValues["host_scc_password"])
-	}
+	pullArgs := podman.GetPullArgs(inspectedHostValues)
 
 	preparedImage, err := podman.PrepareImage(serverImage, pullPolicy, pullArgs...)
 	if err != nil {
-		return "", "", "", err
+		return nil, err
 	}
 
 	log.Info().Msg(L("Migrating server"))
 	if err := podman.RunContainer("uyuni-migration", preparedImage, extraArgs,
 		[]string{"/var/lib/uyuni-tools/migrate.sh"}); err != nil {
-		return "", "", "", fmt.Errorf(L("cannot run uyuni migration container: %s"), err)
+		return nil, utils.Errorf(err, L("cannot run uyuni migration container: %s"))
+	}
+	report.EndStage(L("Data synchronization"))
+	if prepare {
+		return report, nil
 	}
-	tz, oldPgVersion, newPgVersion, err := adm_utils.ReadContainerData(scriptDir)
 
-	if err != nil {
-		return "", "", "", fmt.Errorf(L("cannot read extracted data: %s"), err)
+	if err := report.ReadScriptData(scriptDir); err != nil {
+		return nil, utils.Errorf(err, L("cannot read extracted data: %s"))
 	}
 
-	return tz, oldPgVersion, newPgVersion, nil
+	return report, nil
 }
 
 // RunPgsqlVersionUpgrade perform a PostgreSQL major upgrade.
-func RunPgsqlVersionUpgrade(image types.ImageFlags, migrationImage types.ImageFlags, oldPgsql string, newPgsql string) error {
+func RunPgsqlVersionUpgrade(
+	image types.ImageFlags,
+	migrationImage types.ImageFlags,
+	oldPgsql string,
+	newPgsql string,
+	pgsqlFlags adm_utils.PgsqlUpgradeFlags,
+) error {
 	log.Info().Msgf(L("Previous PostgreSQL is %s, new one is %s. Performing a DB version upgrade..."), oldPgsql, newPgsql)
 
 	scriptDir, err := os.MkdirTemp("", "mgradm-*")
 	defer os.RemoveAll(scriptDir)
 	if err != nil {
-		return fmt.Errorf(L("failed to create temporary directory: %s"), err)
+		return utils.Errorf(err, L("failed to create temporary directory: %s"))
 	}
 	if newPgsql > oldPgsql {
 		pgsqlVersionUpgradeContainer := "uyuni-upgrade-pgsql"
@@ -232,26 +228,21 @@ func RunPgsqlVersionUpgrade(image types.ImageFlags, migrationImage types.ImageFl
 		if migrationImage.Name == "" {
 			migrationImageUrl, err = utils.ComputeImage(image.Name, image.Tag, fmt.Sprintf("-migration-%s-%s", oldPgsql, newPgsql))
 			if err != nil {
-				return fmt.Errorf(L("failed to compute image URL: %s"), err)
+				return utils.Errorf(err, L("failed to compute image URL: %s"))
 			}
 		} else {
 			migrationImageUrl, err = utils.ComputeImage(migrationImage.Name, image.Tag)
 			if err != nil {
-				return fmt.Errorf(L("failed to compute image URL: %s"), err)
+				return utils.Errorf(err, L("failed to compute image URL: %s"))
 			}
 		}
 
 		inspectedHostValues, err := utils.InspectHost()
 		if err != nil {
-			return fmt.Errorf(L("cannot inspect host values: %s"), err)
+			return utils.Errorf(err, L("cannot inspect host values: %s"))
 		}
 
-		pullArgs := []string{}
-		_, scc_user_exist := inspectedHostValues["host_scc_username"]
-		_, scc_user_password := inspectedHostValues["host_scc_password"]
-		if scc_user_exist && scc_user_password {
-			pullArgs = append(pullArgs, "--creds", inspectedHostValues["host_scc_username"]+":"+inspectedHostValues["host_scc_password"])
-		}
+		pullArgs := podman.GetPullArgs(inspectedHostValues)
 
 		preparedImage, err := podman.PrepareImage(migrationImageUrl, image.PullPolicy, pullArgs...)
 		if err != nil {
@@ -260,9 +251,9 @@ func RunPgsqlVersionUpgrade(image types.ImageFlags, migrationImage types.ImageFl
 
 		log.Info().Msgf(L("Using migration image %s"), preparedImage)
 
-		pgsqlVersionUpgradeScriptName, err := adm_utils.GeneratePgsqlVersionUpgradeScript(scriptDir, oldPgsql, newPgsql, false)
+		pgsqlVersionUpgradeScriptName, err := adm_utils.GeneratePgsqlVersionUpgradeScript(scriptDir, oldPgsql, newPgsql, false, pgsqlFlags)
 		if err != nil {
-			return fmt.Errorf(L("cannot generate PostgreSQL database version upgrade script %s"), err)
+			return utils.Errorf(err, L("cannot generate PostgreSQL database version upgrade script %s"))
 		}
 
 		err = podman.RunContainer(pgsqlVersionUpgradeContainer, preparedImage, extraArgs,
@@ -275,11 +266,11 @@ func RunPgsqlVersionUpgrade(image types.ImageFlags, migrationImage types.ImageFl
 }
 
 // RunPgsqlFinalizeScript run the script with all the action required to a db after upgrade.
-func RunPgsqlFinalizeScript(serverImage string, schemaUpdateRequired bool) error {
+func RunPgsqlFinalizeScript(serverImage string, schemaUpdateRequired bool, pgsqlFlags adm_utils.PgsqlUpgradeFlags) error {
 	scriptDir, err := os.MkdirTemp("", "mgradm-*")
 	defer os.RemoveAll(scriptDir)
 	if err != nil {
-		return fmt.Errorf(L("failed to create temporary directory: %s"), err)
+		return utils.Errorf(err, L("failed to create temporary directory: %s"))
 	}
 
 	extraArgs := []string{
@@ -287,9 +278,9 @@ func RunPgsqlFinalizeScript(serverImage string, schemaUpdateRequired bool) error
 		"--security-opt", "label:disable",
 	}
 	pgsqlFinalizeContainer := "uyuni-finalize-pgsql"
-	pgsqlFinalizeScriptName, err := adm_utils.GenerateFinalizePostgresScript(scriptDir, true, schemaUpdateRequired, true, true, false)
+	pgsqlFinalizeScriptName, err := adm_utils.GenerateFinalizePostgresScript(scriptDir, true, schemaUpdateRequired, true, true, false, pgsqlFlags)
 	if err != nil {
-		return fmt.Errorf(L("cannot generate PostgreSQL finalization script: %s"), err)
+		return utils.Errorf(err, L("cannot generate PostgreSQL finalization script: %s"))
 	}
 	err = podman.RunContainer(pgsqlFinalizeContainer, serverImage, extraArgs,
 		[]string{"/var/lib/uyuni-tools/" + pgsqlFinalizeScriptName})
@@ -304,7 +295,7 @@ func RunPostUpgradeScript(serverImage string) error {
 	scriptDir, err := os.MkdirTemp("", "mgradm-*")
 	defer os.RemoveAll(scriptDir)
 	if err != nil {
-		return fmt.Errorf(L("failed to create temporary directory: %s"), err)
+		return utils.Errorf(err, L("failed to create temporary directory: %s"))
 	}
 	postUpgradeContainer := "uyuni-post-upgrade"
 	extraArgs := []string{
@@ -313,7 +304,7 @@ func RunPostUpgradeScript(serverImage string) error {
 	}
 	postUpgradeScriptName, err := adm_utils.GeneratePostUpgradeScript(scriptDir, "localhost")
 	if err != nil {
-		return fmt.Errorf(L("cannot generate PostgreSQL finalization script: %s"), err)
+		return utils.Errorf(err, L("cannot generate PostgreSQL finalization script: %s"))
 	}
 	err = podman.RunContainer(postUpgradeContainer, serverImage, extraArgs,
 		[]string{"/var/lib/uyuni-tools/" + postUpgradeScriptName})
@@ -324,34 +315,51 @@ func RunPostUpgradeScript(serverImage string) error {
 }
 
 // Upgrade will upgrade server to the image given as attribute.
-func Upgrade(image types.ImageFlags, migrationImage types.ImageFlags, args []string) error {
+//
+// If force is true, the version skew between the tool and the image is only reported as a warning.
+func Upgrade(image types.ImageFlags, migrationImage types.ImageFlags, force bool, pgsqlFlags adm_utils.PgsqlUpgradeFlags, args []string) error {
 	serverImage, err := utils.ComputeImage(image.Name, image.Tag)
 	if err != nil {
 		return fmt.Errorf(L("failed to compute image URL"))
 	}
 
+	progress := utils.NewProgress("upgrade", 5)
+	progress.Step(L("Inspecting the new image"))
 	inspectedValues, err := Inspect(serverImage, image.PullPolicy)
 	if err != nil {
-		return fmt.Errorf(L("cannot inspect podman values: %s"), err)
+		return utils.Errorf(err, L("cannot inspect podman values: %s"))
+	}
+
+	if state, err := podman.ReadState(); err == nil && state != nil {
+		log.Info().Msgf(L("Upgrading from image %[1]s installed at %[2]s"), state.Image, state.InstalledAt)
 	}
 
-	cnx := shared.NewConnection("podman", podman.ServerContainerName, "")
+	cnx := shared.NewConnection("podman", podman.ServerContainerName, "", "")
 
 	if err := adm_utils.SanityCheck(cnx, inspectedValues, serverImage); err != nil {
 		return err
 	}
 
+	if err := adm_utils.CheckVersionSkew(inspectedValues, force); err != nil {
+		return err
+	}
+
+	progress.Step(L("Stopping the server"))
 	if err := podman.StopService(podman.ServerService); err != nil {
-		return fmt.Errorf(L("cannot stop service %s"), err)
+		return utils.Errorf(err, L("cannot stop service %s"))
 	}
 
 	defer func() {
 		err = podman.StartService(podman.ServerService)
 	}()
-	if inspectedValues["image_pg_version"] > inspectedValues["current_pg_version"] {
+	progress.Step(L("Upgrading the database"))
+	externalDb := adm_utils.IsExternalDb(inspectedValues)
+	if externalDb {
+		log.Info().Msgf(L("The database is external on %s: skipping the PostgreSQL upgrade"), inspectedValues["db_host"])
+	} else if inspectedValues["image_pg_version"] > inspectedValues["current_pg_version"] {
 		log.Info().Msgf(L("Previous postgresql is %s, instead new one is %s. Performing a DB version upgrade..."), inspectedValues["current_pg_version"], inspectedValues["image_pg_version"])
-		if err := RunPgsqlVersionUpgrade(image, migrationImage, inspectedValues["current_pg_version"], inspectedValues["image_pg_version"]); err != nil {
-			return fmt.Errorf(L("cannot run PostgreSQL version upgrade script: %s"), err)
+		if err := RunPgsqlVersionUpgrade(image, migrationImage, inspectedValues["current_pg_version"], inspectedValues["image_pg_version"], pgsqlFlags); err != nil {
+			return utils.WithCode(utils.CodeDBUpgrade, utils.Errorf(err, L("cannot run PostgreSQL version upgrade script: %s")))
 		}
 	} else if inspectedValues["image_pg_version"] == inspectedValues["current_pg_version"] {
 		log.Info().Msgf(L("Upgrading to %s without changing PostgreSQL version"), inspectedValues["uyuni_release"])
@@ -360,65 +368,38 @@ func Upgrade(image types.ImageFlags, migrationImage types.ImageFlags, args []str
 	}
 
 	schemaUpdateRequired := inspectedValues["current_pg_version"] != inspectedValues["image_pg_version"]
-	if err := RunPgsqlFinalizeScript(serverImage, schemaUpdateRequired); err != nil {
-		return fmt.Errorf(L("cannot run PostgreSQL version upgrade script: %s"), err)
+	if externalDb {
+		// The schema is updated when the server starts
+		log.Debug().Msg("Skipping the PostgreSQL finalization script for the external database")
+	} else if err := RunPgsqlFinalizeScript(serverImage, schemaUpdateRequired, pgsqlFlags); err != nil {
+		return utils.WithCode(utils.CodeDBUpgrade, utils.Errorf(err, L("cannot run PostgreSQL version upgrade script: %s")))
 	}
 
+	progress.Step(L("Running the post upgrade script"))
 	if err := RunPostUpgradeScript(serverImage); err != nil {
-		return fmt.Errorf(L("cannot run post upgrade script: %s"), err)
+		return utils.Errorf(err, L("cannot run post upgrade script: %s"))
+	}
+
+	if podman.HasService(podman.ServerReportDbService) {
+		if err := UpgradeReportDb(serverImage, inspectedValues["image_pg_version"]); err != nil {
+			return utils.Errorf(err, L("cannot upgrade the report database: %s"))
+		}
 	}
 
 	if err := podman.GenerateSystemdConfFile("uyuni-server", "Service", "Environment=UYUNI_IMAGE="+serverImage); err != nil {
 		return err
 	}
+	saveUpgradeState(serverImage)
+	progress.Step(L("Starting the server"))
 	log.Info().Msg(L("Waiting for the server to start..."))
-	return podman.ReloadDaemon(false)
+	if err := podman.ReloadDaemon(false); err != nil {
+		return err
+	}
+	progress.Done(L("Server upgraded"))
+	return nil
 }
 
 // Inspect check values on a given image and deploy.
 func Inspect(serverImage string, pullPolicy string) (map[string]string, error) {
-	scriptDir, err := os.MkdirTemp("", "mgradm-*")
-	defer os.RemoveAll(scriptDir)
-	if err != nil {
-		return map[string]string{}, fmt.Errorf(L("failed to create temporary directory %s"), err)
-	}
-
-	inspectedHostValues, err := utils.InspectHost()
-	if err != nil {
-		return map[string]string{}, fmt.Errorf(L("cannot inspect host values: %s"), err)
-	}
-
-	pullArgs := []string{}
-	_, scc_user_exist := inspectedHostValues["host_scc_username"]
-	_, scc_user_password := inspectedHostValues["host_scc_password"]
-	if scc_user_exist && scc_user_password {
-		pullArgs = append(pullArgs, "--creds", inspectedHostValues["host_scc_username"]+":"+inspectedHostValues["host_scc_password"])
-	}
-
-	preparedImage, err := podman.PrepareImage(serverImage, pullPolicy, pullArgs...)
-	if err != nil {
-		return map[string]string{}, err
-	}
-
-	if err := utils.GenerateInspectContainerScript(scriptDir); err != nil {
-		return map[string]string{}, err
-	}
-
-	podmanArgs := []string{
-		"-v", scriptDir + ":" + utils.InspectOutputFile.Directory,
-		"--security-opt", "label:disable",
-	}
-
-	err = podman.RunContainer("uyuni-inspect", preparedImage, podmanArgs,
-		[]string{utils.InspectOutputFile.Directory + "/" + utils.InspectScriptFilename})
-	if err != nil {
-		return map[string]string{}, err
-	}
-
-	inspectResult, err := utils.ReadInspectData(scriptDir)
-	if err != nil {
-		return map[string]string{}, fmt.Errorf(L("cannot inspect data. %s"), err)
-	}
-
-	return inspectResult, err
+	return podman.Inspect(serverImage, pullPolicy)
 }
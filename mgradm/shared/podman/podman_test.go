@@ -0,0 +1,29 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestRenderServerService(t *testing.T) {
+	content, err := RenderServerService("Europe/Berlin", false, []string{"--memory", "16g", "--cpus", "4"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertGolden(t, "uyuni-server.service", content)
+	testutils.ValidateSystemdUnit(t, "uyuni-server.service", content)
+}
+
+func TestRenderServerServiceDebug(t *testing.T) {
+	content, err := RenderServerService("Etc/UTC", true, []string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertGolden(t, "uyuni-server-debug.service", content)
+	testutils.ValidateSystemdUnit(t, "uyuni-server.service", content)
+}
@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	adm_utils "github.com/uyuni-project/uyuni-tools/mgradm/shared/utils"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// shutdownMarkers are the files left in the volumes when the server has not been stopped cleanly.
+var shutdownMarkers = []struct {
+	volume      string
+	path        string
+	description string
+}{
+	{"var-pgsql", "data/postmaster.pid", "PostgreSQL lock file"},
+	{"var-pgsql-reportdb", "data/postmaster.pid", "reporting database lock file"},
+}
+
+// recoveryTimeout is the maximum time to wait for the database to complete its recovery.
+const recoveryTimeout = 10 * time.Minute
+
+// uncleanMarkers returns the description of the shutdown markers found in the volumes.
+func uncleanMarkers(mountPoints map[string]string) []string {
+	found := []string{}
+	for _, marker := range shutdownMarkers {
+		mountPoint, exists := mountPoints[marker.volume]
+		if !exists {
+			continue
+		}
+		if utils.FileExists(path.Join(mountPoint, marker.path)) {
+			found = append(found, marker.description)
+		}
+	}
+	return found
+}
+
+// missingVolumes returns the names of the volumes required by the database which cannot be found.
+func missingVolumes(mountPoints map[string]string) []string {
+	missing := []string{}
+	for _, mount := range utils.PgsqlRequiredVolumeMounts {
+		// The TLS key volume is only a secret on kubernetes
+		if mount.Name == "tls-key" {
+			continue
+		}
+		mountPoint, exists := mountPoints[mount.Name]
+		if !exists {
+			missing = append(missing, mount.Name)
+		} else if _, err := os.Stat(mountPoint); err != nil {
+			missing = append(missing, mount.Name)
+		}
+	}
+	return missing
+}
+
+// DetectUncleanShutdown looks for the markers of a server which has not been stopped cleanly.
+//
+// The detection needs to run before starting the server as the markers are also present while it is running.
+func DetectUncleanShutdown() []string {
+	if podman.IsServiceRunning(podman.ServerService) {
+		return []string{}
+	}
+	mountPoints, err := podman.VolumeMountPoints()
+	if err != nil {
+		log.Debug().Err(err).Msg("Skipping the unclean shutdown detection")
+		return []string{}
+	}
+	return uncleanMarkers(mountPoints)
+}
+
+// CheckVolumes verifies that the volumes required by the database are present before starting the server.
+func CheckVolumes() error {
+	mountPoints, err := podman.VolumeMountPoints()
+	if err != nil {
+		return err
+	}
+	if missing := missingVolumes(mountPoints); len(missing) > 0 {
+		return fmt.Errorf(L("missing volumes: %s"), missing)
+	}
+	return nil
+}
+
+// waitForDatabase waits for the database to accept connections, that is after completing its recovery.
+func waitForDatabase(cnx *shared.Connection, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, err := cnx.Exec("pg_isready", "-q"); err == nil {
+			return nil
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return errors.New(L("the database did not complete its recovery in time"))
+}
+
+// resetTaskomaticRuns marks the taskomatic runs interrupted by the crash as such so that they do not
+// block the next schedules.
+func resetTaskomaticRuns(cnx *shared.Connection) error {
+	rows, err := adm_utils.RunQuery(cnx, "SELECT COUNT(*) FROM rhnTaskoRun WHERE status = 'RUNNING';")
+	if err != nil {
+		return err
+	}
+	if len(rows) != 1 || len(rows[0]) != 1 || rows[0][0] == "0" {
+		return nil
+	}
+	log.Info().Msgf(L("Marking %s interrupted taskomatic runs"), rows[0][0])
+	_, err = adm_utils.RunQuery(cnx,
+		"UPDATE rhnTaskoRun SET status = 'INTERRUPTED', end_time = current_timestamp WHERE status = 'RUNNING';")
+	return err
+}
+
+// RecoverServer checks the server started after an unclean shutdown before declaring it up.
+func RecoverServer(cnx *shared.Connection) error {
+	log.Info().Msg(L("Waiting for the database to complete its recovery"))
+	if err := waitForDatabase(cnx, recoveryTimeout); err != nil {
+		return err
+	}
+	log.Info().Msg(L("Database recovered"))
+
+	log.Info().Msg(L("Checking the taskomatic queue"))
+	if err := resetTaskomaticRuns(cnx); err != nil {
+		return utils.Errorf(err, L("failed to check the taskomatic queue: %s"))
+	}
+
+	if err := cnx.WaitForServer(); err != nil {
+		return err
+	}
+	log.Info().Msg(L("Server recovered from the unclean shutdown"))
+	return nil
+}
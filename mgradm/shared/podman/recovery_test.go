@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestUncleanMarkers(t *testing.T) {
+	pgsqlDir := t.TempDir()
+	reportDbDir := t.TempDir()
+	mountPoints := map[string]string{"var-pgsql": pgsqlDir, "var-pgsql-reportdb": reportDbDir}
+
+	if markers := uncleanMarkers(mountPoints); len(markers) != 0 {
+		t.Errorf("Expected no marker, got %v", markers)
+	}
+
+	if err := os.MkdirAll(path.Join(pgsqlDir, "data"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(pgsqlDir, "data", "postmaster.pid"), []byte("42\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	markers := uncleanMarkers(mountPoints)
+	if strings.Join(markers, ",") != "PostgreSQL lock file" {
+		t.Errorf("Expected the PostgreSQL lock file, got %v", markers)
+	}
+}
+
+func TestMissingVolumes(t *testing.T) {
+	dir := t.TempDir()
+	mountPoints := map[string]string{
+		"etc-tls":   dir,
+		"var-pgsql": path.Join(dir, "missing"),
+		"etc-rhn":   dir,
+	}
+	missing := missingVolumes(mountPoints)
+	if strings.Join(missing, ",") != "var-pgsql" {
+		t.Errorf("Expected var-pgsql to be missing, got %v", missing)
+	}
+}
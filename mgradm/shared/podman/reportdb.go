@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	install_shared "github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/shared"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/templates"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ReportDbHost is the host name of the reporting database container in the podman network.
+const ReportDbHost = podman.ServerReportDbService + ".mgr.internal"
+
+const reportDbDumpFile = "/var/lib/pgsql/reportdb.sql"
+
+// getReportDbArgs returns the podman arguments limiting the resources of the reporting database container.
+func getReportDbArgs(db install_shared.ReportDbFlags) []string {
+	return podman.LimitsArgs(db.Memory, db.Cpus)
+}
+
+// SetupReportDb initializes the reporting database volume and creates its systemd service.
+//
+// The service is started so that the server setup can create the schema in it.
+func SetupReportDb(image string, db install_shared.ReportDbFlags) error {
+	if err := podman.SetupNetwork(); err != nil {
+		return utils.Errorf(err, L("cannot setup network: %s"))
+	}
+
+	scriptDir, err := os.MkdirTemp("", "mgradm-*")
+	defer os.RemoveAll(scriptDir)
+	if err != nil {
+		return utils.Errorf(err, L("failed to create temporary directory: %s"))
+	}
+
+	// Pass the password in a file to keep it out of the command lines and logs
+	passwordFile := filepath.Join(scriptDir, "reportdb_password")
+	if err := os.WriteFile(passwordFile, []byte(db.Password), 0600); err != nil {
+		return utils.Errorf(err, L("failed to write the report database password: %s"))
+	}
+
+	log.Info().Msg(L("Initializing the report database container"))
+	data := templates.ReportDbSetupTemplateData{
+		DataDir:      utils.ReportDbVolumeMount.MountPath + "/data",
+		Name:         db.Name,
+		User:         db.User,
+		PasswordFile: "/var/lib/uyuni-tools/reportdb_password",
+	}
+	if err := runReportDbSetup(image, scriptDir, data); err != nil {
+		return err
+	}
+
+	if err := generateReportDbSystemdService(image, db); err != nil {
+		return err
+	}
+
+	return podman.EnableService(podman.ServerReportDbService)
+}
+
+// UpgradeReportDb points the reporting database service to the new image.
+//
+// If the PostgreSQL major version changes, the data are dumped and restored in a new data directory.
+func UpgradeReportDb(image string, imagePgVersion string) error {
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", "exec", podman.ServerReportDbService,
+		"cat", utils.ReportDbVolumeMount.MountPath+"/data/PG_VERSION")
+	if err != nil {
+		return utils.Errorf(err, L("failed to get the report database PostgreSQL version: %s"))
+	}
+	currentPgVersion := strings.TrimSpace(string(out))
+
+	if currentPgVersion != imagePgVersion {
+		log.Info().Msgf(L("Upgrading the report database from PostgreSQL %[1]s to %[2]s"), currentPgVersion, imagePgVersion)
+		if err := utils.RunCmd("podman", "exec", podman.ServerReportDbService,
+			"su", "-s", "/bin/bash", "-", "postgres", "-c", "pg_dumpall -f "+reportDbDumpFile); err != nil {
+			return utils.Errorf(err, L("failed to dump the report database: %s"))
+		}
+
+		if err := podman.StopService(podman.ServerReportDbService); err != nil {
+			return err
+		}
+
+		scriptDir, err := os.MkdirTemp("", "mgradm-*")
+		defer os.RemoveAll(scriptDir)
+		if err != nil {
+			return utils.Errorf(err, L("failed to create temporary directory: %s"))
+		}
+
+		data := templates.ReportDbSetupTemplateData{
+			DataDir:  utils.ReportDbVolumeMount.MountPath + "/data",
+			Restore:  true,
+			DumpFile: reportDbDumpFile,
+		}
+		if err := runReportDbSetup(image, scriptDir, data); err != nil {
+			return err
+		}
+		log.Info().Msgf(L("The previous report database data are kept in the %s volume and can be removed"), utils.ReportDbVolumeMount.Name)
+	}
+
+	if err := podman.GenerateSystemdConfFile(podman.ServerReportDbService, "Service", "Environment=UYUNI_IMAGE="+image); err != nil {
+		return utils.Errorf(err, L("cannot generate systemd conf file: %s"))
+	}
+	if err := podman.ReloadDaemon(false); err != nil {
+		return err
+	}
+	return podman.RestartService(podman.ServerReportDbService)
+}
+
+// runReportDbSetup runs the reporting database setup script in a container with only the report database volume.
+func runReportDbSetup(image string, scriptDir string, data templates.ReportDbSetupTemplateData) error {
+	scriptName := "reportdbSetup.sh"
+	if err := utils.WriteTemplateToFile(data, filepath.Join(scriptDir, scriptName), 0555, true); err != nil {
+		return utils.Errorf(err, L("failed to generate %s"), scriptName)
+	}
+
+	volume := utils.ReportDbVolumeMount
+	err := utils.RunCmdStdMapping(zerolog.DebugLevel, "podman", "run", "--rm",
+		"--name", "uyuni-reportdb-setup",
+		"-v", volume.Name+":"+volume.MountPath,
+		"-v", scriptDir+":/var/lib/uyuni-tools/",
+		"--security-opt", "label:disable",
+		image, "/var/lib/uyuni-tools/"+scriptName)
+	if err != nil {
+		return utils.Errorf(err, L("failed to run the report database setup script: %s"))
+	}
+	return nil
+}
+
+func generateReportDbSystemdService(image string, db install_shared.ReportDbFlags) error {
+	data := templates.ReportDbServiceTemplateData{
+		NamePrefix: "uyuni",
+		Network:    podman.UyuniNetwork,
+		Port:       db.Port,
+		Volume:     utils.ReportDbVolumeMount,
+		Args:       strings.Join(getReportDbArgs(db), " "),
+	}
+	if err := utils.WriteTemplateToFile(data, podman.GetServicePath(podman.ServerReportDbService), 0555, false); err != nil {
+		return utils.Errorf(err, L("failed to generate systemd service unit file: %s"))
+	}
+
+	if err := podman.GenerateSystemdConfFile(podman.ServerReportDbService, "Service", "Environment=UYUNI_IMAGE="+image); err != nil {
+		return utils.Errorf(err, L("cannot generate systemd conf file: %s"))
+	}
+	return podman.ReloadDaemon(false)
+}
@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	install_shared "github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/shared"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/templates"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func TestGetReportDbArgs(t *testing.T) {
+	db := install_shared.ReportDbFlags{}
+	if args := getReportDbArgs(db); len(args) != 0 {
+		t.Errorf("expected no argument, got %v", args)
+	}
+
+	db.Memory = "4g"
+	db.Cpus = "1.5"
+	expected := "--memory 4g --cpus 1.5"
+	if args := strings.Join(getReportDbArgs(db), " "); args != expected {
+		t.Errorf("expected '%s', got '%s'", expected, args)
+	}
+}
+
+func TestReportDbServiceTemplate(t *testing.T) {
+	data := templates.ReportDbServiceTemplateData{
+		NamePrefix: "uyuni",
+		Network:    "uyuni",
+		Port:       5433,
+		Volume:     utils.ReportDbVolumeMount,
+		Args:       "--memory 4g",
+	}
+	var buf bytes.Buffer
+	if err := data.Render(&buf); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	service := buf.String()
+
+	for _, expected := range []string{
+		"\t--replace \\\n\t--memory 4g \\\n\t-p 5433:5433 \\\n",
+		"-v var-pgsql-reportdb:/var/lib/pgsql \\\n",
+		"--hostname " + ReportDbHost + " \\\n",
+		"-c port=5433 ",
+	} {
+		if !strings.Contains(service, expected) {
+			t.Errorf("expected '%s' in service:\n%s", expected, service)
+		}
+	}
+}
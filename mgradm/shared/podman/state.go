@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// SaveInstallState records the state of a newly deployed server with the flags set by the user.
+//
+// Failing to save the state only results in a warning as the server is deployed.
+func SaveInstallState(image string, timezone string, userFlags map[string]string) {
+	state := utils.NewDeploymentState("podman", image, podman.GetImageDigest(image), utils.GetServerVolumeNames(), userFlags)
+	state.Timezone = timezone
+	if err := podman.WriteState(state); err != nil {
+		log.Warn().Err(err).Msg(L("Failed to save the deployment state"))
+	}
+}
+
+// saveUpgradeState records the new image in the state of the server.
+func saveUpgradeState(image string) {
+	state, err := podman.ReadState()
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Failed to read the deployment state"))
+	}
+	state = utils.UpdateDeploymentState(state, "podman", image, podman.GetImageDigest(image))
+	if err := podman.WriteState(state); err != nil {
+		log.Warn().Err(err).Msg(L("Failed to save the deployment state"))
+	}
+}
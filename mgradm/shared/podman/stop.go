@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// serverStopOrder lists the services of the server container in the order they need to be stopped.
+//
+// The database is stopped last so that the other services can finish their transactions.
+var serverStopOrder = []string{"taskomatic", "tomcat", "salt-api", "salt-master", "postgresql"}
+
+// stopServiceArgs returns the podman arguments stopping a service in the server container within timeout seconds.
+func stopServiceArgs(service string, timeout int) []string {
+	return []string{
+		"exec", podman.ServerContainerName,
+		"timeout", strconv.Itoa(timeout), "systemctl", "stop", service,
+	}
+}
+
+// StopServerServices stops the services running in the server container in order
+// before the container is stopped.
+//
+// Stopping the systemd unit only may kill PostgreSQL before it has completed its shutdown.
+// Failing to stop a service is not fatal as the container will be stopped anyway.
+func StopServerServices(timeout int) {
+	if !podman.IsServiceRunning(podman.ServerService) {
+		log.Debug().Msg("Server not running, skipping the services shutdown")
+		return
+	}
+	for _, service := range serverStopOrder {
+		log.Info().Msgf(L("Stopping %s"), service)
+		if err := utils.RunCmd("podman", stopServiceArgs(service, timeout)...); err != nil {
+			log.Warn().Err(err).Msgf(L("Failed to stop %s in the server container"), service)
+		}
+	}
+}
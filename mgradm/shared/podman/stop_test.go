@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStopServiceArgs(t *testing.T) {
+	expected := "exec uyuni-server timeout 60 systemctl stop postgresql"
+	actual := strings.Join(stopServiceArgs("postgresql", 60), " ")
+	if actual != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, actual)
+	}
+}
+
+func TestServerStopOrder(t *testing.T) {
+	if serverStopOrder[len(serverStopOrder)-1] != "postgresql" {
+		t.Error("PostgreSQL needs to be stopped last")
+	}
+}
@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spec
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// PlanFormatVersion is the version of the plan schema, increased on incompatible changes only.
+const PlanFormatVersion = 1
+
+// The actions planned for a resource.
+const (
+	PlanCreate = "create"
+	PlanUpdate = "update"
+	PlanNoOp   = "no-op"
+)
+
+// The types of the planned resources.
+const (
+	SystemdUnitResource      = "systemd_unit"
+	PodmanNetworkResource    = "podman_network"
+	PodmanVolumeResource     = "podman_volume"
+	HelmReleaseResource      = "helm_release"
+	KubernetesSecretResource = "kubernetes_secret"
+	KubernetesPvcResource    = "kubernetes_pvc"
+	ProxyConfigResource      = "proxy_config"
+	ProxyInstallResource     = "proxy_install"
+)
+
+// mutableResources are the resource types regenerated when applying a deployment to an existing server.
+// The other ones are kept as is once created.
+var mutableResources = []string{SystemdUnitResource, HelmReleaseResource, ProxyConfigResource, ProxyInstallResource}
+
+// PlannedResource is a resource to be created or changed by applying a deployment.
+type PlannedResource struct {
+	Type       string            `json:"type"`
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace,omitempty"`
+	Action     string            `json:"action"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Plan describes the resources to be created or changed by applying a deployment.
+//
+// The resources are sorted by type, namespace and name to ease comparing plans.
+type Plan struct {
+	FormatVersion int               `json:"formatVersion"`
+	Backend       string            `json:"backend"`
+	Fqdn          string            `json:"fqdn"`
+	Resources     []PlannedResource `json:"resources"`
+}
+
+// ResourceProbe tells whether a resource already exists.
+type ResourceProbe func(resourceType string, namespace string, name string) bool
+
+// Plan computes the resources to be created or changed by applying the deployment.
+func (d *Deployment) Plan(exists ResourceProbe) *Plan {
+	plan := Plan{FormatVersion: PlanFormatVersion, Backend: d.Backend, Fqdn: d.Fqdn, Resources: []PlannedResource{}}
+	// The volumes lists may mention the same volume several times
+	planned := map[string]bool{}
+	add := func(resourceType string, namespace string, name string, attributes map[string]string) {
+		key := resourceType + "/" + namespace + "/" + name
+		if planned[key] {
+			return
+		}
+		planned[key] = true
+
+		action := PlanCreate
+		if exists(resourceType, namespace, name) {
+			action = PlanNoOp
+			if utils.Contains(mutableResources, resourceType) {
+				action = PlanUpdate
+			}
+		}
+		for key, value := range attributes {
+			if value == "" {
+				delete(attributes, key)
+			}
+		}
+		plan.Resources = append(plan.Resources, PlannedResource{
+			Type: resourceType, Namespace: namespace, Name: name, Action: action, Attributes: attributes,
+		})
+	}
+
+	hostPaths := map[string]string{
+		"var-cache":     d.Volumes.Cache,
+		"var-pgsql":     d.Volumes.Postgresql,
+		"var-spacewalk": d.Volumes.Spacewalk,
+		"srv-www":       d.Volumes.Www,
+	}
+
+	if d.Backend == "kubernetes" {
+		namespace := d.Flags["helm-uyuni-namespace"]
+		if namespace == "" {
+			namespace = "default"
+		}
+		add(HelmReleaseResource, namespace, "uyuni", map[string]string{
+			"image": d.Image, "timezone": d.Timezone, "mirror": d.Volumes.Mirror,
+		})
+		secret := "uyuni-ca"
+		if !d.Ssl.IsEmpty() {
+			secret = "uyuni-cert"
+		}
+		add(KubernetesSecretResource, namespace, secret, nil)
+		for _, volume := range utils.ServerVolumeMounts {
+			add(KubernetesPvcResource, namespace, volume.Name, nil)
+		}
+	} else {
+		add(SystemdUnitResource, "", "uyuni-server.service", map[string]string{
+			"image": d.Image, "timezone": d.Timezone, "mirror": d.Volumes.Mirror,
+		})
+		replicatedServices := map[string]string{
+			"coco-replicas":   "uyuni-server-attestation.service",
+			"saline-replicas": "uyuni-server-saline.service",
+		}
+		for flag, service := range replicatedServices {
+			if replicas, err := strconv.Atoi(d.Flags[flag]); err == nil && replicas > 0 {
+				add(SystemdUnitResource, "", service, nil)
+			}
+		}
+		add(PodmanNetworkResource, "", "uyuni", nil)
+		for _, volume := range utils.ServerVolumeMounts {
+			add(PodmanVolumeResource, "", volume.Name, map[string]string{
+				"mountPath": volume.MountPath, "hostPath": hostPaths[volume.Name],
+			})
+		}
+	}
+
+	for _, proxy := range d.Proxies {
+		add(ProxyConfigResource, "", proxy.Fqdn, map[string]string{"output": proxy.GetOutput()})
+		if proxy.Ssh != nil {
+			add(ProxyInstallResource, "", proxy.Fqdn, map[string]string{
+				"host": proxy.GetHost(), "backend": proxy.Ssh.GetBackend(),
+			})
+		}
+	}
+
+	sort.SliceStable(plan.Resources, func(i, j int) bool {
+		a, b := plan.Resources[i], plan.Resources[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return &plan
+}
+
+// Write writes the plan as JSON to a file, or to the standard output if path is empty or -.
+func (p *Plan) Write(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return utils.Errorf(err, L("failed to generate the plan file: %s"))
+	}
+	data = append(data, '\n')
+	if path == "" || path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return utils.Errorf(err, L("failed to write plan file %[1]s: %[2]s"), path)
+	}
+	return nil
+}
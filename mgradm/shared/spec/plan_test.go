@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spec
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestPlanPodman(t *testing.T) {
+	deployment := Deployment{
+		Backend: "podman",
+		Fqdn:    "uyuni.example.com",
+		Image:   "registry.opensuse.org/uyuni/server:2024.05",
+		Volumes: Volumes{Mirror: "/srv/mirror", Postgresql: "/data/pgsql"},
+		Proxies: []Proxy{
+			{Fqdn: "proxy2.example.com", Ssh: &Ssh{}},
+			{Fqdn: "proxy1.example.com"},
+		},
+		Flags: map[string]string{"saline-replicas": "1"},
+	}
+	existing := map[string]bool{
+		SystemdUnitResource + "/uyuni-server.service": true,
+		PodmanVolumeResource + "/var-pgsql":           true,
+	}
+	plan := deployment.Plan(func(resourceType string, namespace string, name string) bool {
+		return existing[resourceType+"/"+name]
+	})
+
+	outputPath := path.Join(t.TempDir(), "plan.json")
+	if err := plan.Write(outputPath); err != nil {
+		t.Fatalf("failed to write the plan: %s", err)
+	}
+	actual, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read the plan: %s", err)
+	}
+	testutils.AssertGolden(t, "plan-podman.json", actual)
+}
+
+func TestPlanKubernetes(t *testing.T) {
+	deployment := Deployment{
+		Backend: "kubernetes",
+		Fqdn:    "uyuni.example.com",
+		Ssl:     Ssl{Root: "/certs/ca.crt", Cert: "/certs/uyuni.crt", Key: "/certs/uyuni.key"},
+		Flags:   map[string]string{"helm-uyuni-namespace": "uyuni"},
+	}
+	plan := deployment.Plan(func(resourceType string, namespace string, name string) bool {
+		return resourceType == HelmReleaseResource && namespace == "uyuni" && name == "uyuni"
+	})
+
+	actions := map[string]string{}
+	for _, resource := range plan.Resources {
+		if resource.Namespace != "uyuni" {
+			t.Errorf("unexpected namespace for %s %s: %s", resource.Type, resource.Name, resource.Namespace)
+		}
+		actions[resource.Type+"/"+resource.Name] = resource.Action
+	}
+	expected := map[string]string{
+		HelmReleaseResource + "/uyuni":           PlanUpdate,
+		KubernetesSecretResource + "/uyuni-cert": PlanCreate,
+		KubernetesPvcResource + "/var-pgsql":     PlanCreate,
+	}
+	for key, action := range expected {
+		if actions[key] != action {
+			t.Errorf("expected %s to be %s, got %q", key, action, actions[key])
+		}
+	}
+	if _, ok := actions[KubernetesSecretResource+"/uyuni-ca"]; ok {
+		t.Error("unexpected uyuni-ca secret with third party certificates")
+	}
+}
@@ -0,0 +1,308 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	install_shared "github.com/uyuni-project/uyuni-tools/mgradm/cmd/install/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// Deployment describes the desired state of a server deployment.
+type Deployment struct {
+	// Backend is the install subcommand to use: podman, kubernetes or docker.
+	Backend    string  `yaml:"backend"`
+	Fqdn       string  `yaml:"fqdn"`
+	Image      string  `yaml:"image,omitempty"`
+	PullPolicy string  `yaml:"pullPolicy,omitempty"`
+	Timezone   string  `yaml:"timezone,omitempty"`
+	Ssl        Ssl     `yaml:"ssl,omitempty"`
+	Volumes    Volumes `yaml:"volumes,omitempty"`
+	Proxies    []Proxy `yaml:"proxies,omitempty"`
+	// Parallel installs the proxies on their hosts at the same time rather than one after the other.
+	Parallel   bool  `yaml:"parallel,omitempty"`
+	Monitoring *bool `yaml:"monitoring,omitempty"`
+	// Flags are other install flags, using the flag names as keys.
+	Flags map[string]string `yaml:"flags,omitempty"`
+}
+
+// Ssl describes third party SSL certificates.
+//
+// Self-signed certificates are generated if empty.
+type Ssl struct {
+	Root         string   `yaml:"root,omitempty"`
+	Intermediate []string `yaml:"intermediate,omitempty"`
+	Cert         string   `yaml:"cert,omitempty"`
+	Key          string   `yaml:"key,omitempty"`
+}
+
+// Volumes describes where the server data are stored on the host.
+type Volumes struct {
+	Mirror     string `yaml:"mirror,omitempty"`
+	Cache      string `yaml:"cache,omitempty"`
+	Postgresql string `yaml:"postgresql,omitempty"`
+	Spacewalk  string `yaml:"spacewalk,omitempty"`
+	Www        string `yaml:"www,omitempty"`
+}
+
+// Proxy describes a proxy attached to the server.
+type Proxy struct {
+	Fqdn     string `yaml:"fqdn"`
+	Port     int    `yaml:"port,omitempty"`
+	MaxCache int    `yaml:"maxCache,omitempty"`
+	Email    string `yaml:"email,omitempty"`
+	// Output is the path of the proxy configuration archive to generate.
+	Output string `yaml:"output,omitempty"`
+	// Ssl are the third party certificates of the proxy.
+	// If empty, the certificates are generated using the server CA and CaPassword.
+	Ssl        Ssl    `yaml:"ssl,omitempty"`
+	CaPassword string `yaml:"caPassword,omitempty"`
+	// Ssh is the connection to the proxy host to install the proxy on.
+	// The proxy is not installed if not set.
+	Ssh *Ssh `yaml:"ssh,omitempty"`
+}
+
+// Ssh describes the SSH connection to a host.
+type Ssh struct {
+	// Host defaults to the proxy FQDN.
+	Host      string `yaml:"host,omitempty"`
+	User      string `yaml:"user,omitempty"`
+	Port      int    `yaml:"port,omitempty"`
+	ProxyJump string `yaml:"proxyJump,omitempty"`
+	// Backend is the mgrpxy install subcommand to use, podman by default.
+	Backend string `yaml:"backend,omitempty"`
+}
+
+// IsEmpty returns whether no certificate is defined.
+func (s Ssl) IsEmpty() bool {
+	return s.Root == "" && len(s.Intermediate) == 0 && s.Cert == "" && s.Key == ""
+}
+
+// GetOutput returns the path of the proxy configuration archive.
+func (p Proxy) GetOutput() string {
+	if p.Output != "" {
+		return p.Output
+	}
+	return p.Fqdn + "-config.tar.gz"
+}
+
+// GetHost returns the host to connect to with SSH.
+func (p Proxy) GetHost() string {
+	if p.Ssh != nil && p.Ssh.Host != "" {
+		return p.Ssh.Host
+	}
+	return p.Fqdn
+}
+
+// GetUser returns the SSH user, root by default.
+func (s Ssh) GetUser() string {
+	if s.User != "" {
+		return s.User
+	}
+	return "root"
+}
+
+// GetBackend returns the mgrpxy install subcommand to use.
+func (s Ssh) GetBackend() string {
+	if s.Backend != "" {
+		return s.Backend
+	}
+	return "podman"
+}
+
+// ignoredFlags are the flags changing how a command runs rather than the deployment.
+var ignoredFlags = []string{"config", "logLevel", "logfile", "wait", "no-converge", "generate-password", "password-output"}
+
+// Read parses and validates a deployment file.
+func Read(path string) (*Deployment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to read deployment file %[1]s: %[2]s"), path)
+	}
+	var deployment Deployment
+	if err := yaml.UnmarshalStrict(data, &deployment); err != nil {
+		return nil, utils.Errorf(err, L("failed to parse deployment file %[1]s: %[2]s"), path)
+	}
+	if err := deployment.Validate(); err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// Write writes the deployment to a file, or to the standard output if path is empty or -.
+func (d *Deployment) Write(path string) error {
+	data, err := yaml.Marshal(d)
+	if err != nil {
+		return utils.Errorf(err, L("failed to generate the deployment file: %s"))
+	}
+	if path == "" || path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	// The flags may contain sensitive data
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return utils.Errorf(err, L("failed to write deployment file %[1]s: %[2]s"), path)
+	}
+	return nil
+}
+
+// Validate checks the consistency of the deployment.
+func (d *Deployment) Validate() error {
+	if d.Backend == "" {
+		return errors.New(L("the deployment backend is required"))
+	}
+	if !install_shared.IsFqdn(d.Fqdn) {
+		return fmt.Errorf(L("%s is not a valid fully qualified domain name"), d.Fqdn)
+	}
+	if !d.Ssl.IsEmpty() && (d.Ssl.Root == "" || d.Ssl.Cert == "" || d.Ssl.Key == "") {
+		return errors.New(L("the third party SSL root CA, certificate and key are all required"))
+	}
+	fqdns := map[string]bool{d.Fqdn: true}
+	for _, proxy := range d.Proxies {
+		if !install_shared.IsFqdn(proxy.Fqdn) {
+			return fmt.Errorf(L("%s is not a valid fully qualified domain name"), proxy.Fqdn)
+		}
+		if fqdns[proxy.Fqdn] {
+			return fmt.Errorf(L("%s is defined more than once"), proxy.Fqdn)
+		}
+		fqdns[proxy.Fqdn] = true
+		if proxy.Ssl.IsEmpty() && proxy.CaPassword == "" {
+			return fmt.Errorf(L("proxy %s needs either third party SSL certificates or the server CA password"), proxy.Fqdn)
+		}
+		if !proxy.Ssl.IsEmpty() && (proxy.Ssl.Cert == "" || proxy.Ssl.Key == "") {
+			return fmt.Errorf(L("the SSL certificate and key of proxy %s are both required"), proxy.Fqdn)
+		}
+		if proxy.Ssh != nil && !utils.Contains([]string{"podman", "kubernetes"}, proxy.Ssh.GetBackend()) {
+			return fmt.Errorf(L("unsupported backend %[1]s for proxy %[2]s"), proxy.Ssh.Backend, proxy.Fqdn)
+		}
+	}
+	return nil
+}
+
+// InstallArgs returns the mgradm arguments installing or converging the deployment.
+//
+// The secret flags are not part of the arguments to not show them in the processes list:
+// they are passed as environment variables returned by InstallEnv.
+func (d *Deployment) InstallArgs() []string {
+	args := []string{"install", d.Backend, d.Fqdn}
+
+	options := map[string]string{}
+	for name, value := range d.Flags {
+		if !utils.IsSecretFlag(name) {
+			options[name] = value
+		}
+	}
+	values := map[string]string{
+		"image":                   d.Image,
+		"pullPolicy":              d.PullPolicy,
+		"tz":                      d.Timezone,
+		"ssl-ca-root":             d.Ssl.Root,
+		"ssl-ca-intermediate":     strings.Join(d.Ssl.Intermediate, ","),
+		"ssl-server-cert":         d.Ssl.Cert,
+		"ssl-server-key":          d.Ssl.Key,
+		"mirrorPath":              d.Volumes.Mirror,
+		"podman-mount-cache":      d.Volumes.Cache,
+		"podman-mount-postgresql": d.Volumes.Postgresql,
+		"podman-mount-spacewalk":  d.Volumes.Spacewalk,
+		"podman-mount-www":        d.Volumes.Www,
+	}
+	for name, value := range values {
+		if value != "" {
+			options[name] = value
+		}
+	}
+	if d.Image != "" {
+		// The image contains the tag
+		delete(options, "tag")
+	}
+
+	names := []string{}
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, fmt.Sprintf("--%s=%s", name, options[name]))
+	}
+	return args
+}
+
+// InstallEnv returns the environment variables passing the secret flags to the mgradm install command.
+//
+// If redact is true, the values are replaced to show the variables to the user.
+func (d *Deployment) InstallEnv(redact bool) []string {
+	names := []string{}
+	for name := range d.Flags {
+		if utils.IsSecretFlag(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	env := []string{}
+	for _, name := range names {
+		value := d.Flags[name]
+		if redact {
+			value = utils.RedactedValue
+		}
+		env = append(env, utils.EnvName(name)+"="+value)
+	}
+	return env
+}
+
+// FromState computes the deployment from the recorded state of a server.
+func FromState(state *types.DeploymentState, backend string, fqdn string) *Deployment {
+	deployment := Deployment{
+		Backend:  backend,
+		Fqdn:     fqdn,
+		Image:    state.Image,
+		Timezone: state.Timezone,
+		Flags:    map[string]string{},
+	}
+
+	fields := map[string]*string{
+		"pullPolicy":              &deployment.PullPolicy,
+		"ssl-ca-root":             &deployment.Ssl.Root,
+		"ssl-server-cert":         &deployment.Ssl.Cert,
+		"ssl-server-key":          &deployment.Ssl.Key,
+		"mirrorPath":              &deployment.Volumes.Mirror,
+		"podman-mount-cache":      &deployment.Volumes.Cache,
+		"podman-mount-postgresql": &deployment.Volumes.Postgresql,
+		"podman-mount-spacewalk":  &deployment.Volumes.Spacewalk,
+		"podman-mount-www":        &deployment.Volumes.Www,
+	}
+	for name, value := range state.Flags {
+		// Secrets are not recorded and image, tag and timezone are in the state
+		if value == utils.RedactedValue || utils.Contains(ignoredFlags, name) ||
+			name == "image" || name == "tag" || name == "tz" {
+			continue
+		}
+		value = flagValue(value)
+		if field, ok := fields[name]; ok {
+			*field = value
+		} else if name == "ssl-ca-intermediate" {
+			deployment.Ssl.Intermediate = strings.Split(value, ",")
+		} else {
+			deployment.Flags[name] = value
+		}
+	}
+	return &deployment
+}
+
+// flagValue converts a recorded slice flag value like [a,b] to the a,b command line value.
+func flagValue(value string) string {
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		return strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	}
+	return value
+}
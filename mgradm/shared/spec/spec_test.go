@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spec
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func TestInstallArgs(t *testing.T) {
+	deployment := Deployment{
+		Backend:  "podman",
+		Fqdn:     "uyuni.example.com",
+		Image:    "registry.opensuse.org/uyuni/server:2024.05",
+		Timezone: "Europe/Berlin",
+		Ssl:      Ssl{Root: "/certs/ca.crt", Intermediate: []string{"/certs/a.crt", "/certs/b.crt"}},
+		Volumes:  Volumes{Mirror: "/srv/mirror"},
+		Flags:    map[string]string{"tag": "latest", "organization": "Example", "admin-password": "secret"},
+	}
+	expected := []string{
+		"install", "podman", "uyuni.example.com",
+		"--image=registry.opensuse.org/uyuni/server:2024.05",
+		"--mirrorPath=/srv/mirror",
+		"--organization=Example",
+		"--ssl-ca-intermediate=/certs/a.crt,/certs/b.crt",
+		"--ssl-ca-root=/certs/ca.crt",
+		"--tz=Europe/Berlin",
+	}
+	if actual := deployment.InstallArgs(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v got %v", expected, actual)
+	}
+
+	utils.SetEnvPrefix("MGRADM")
+	defer utils.SetEnvPrefix("")
+	if actual := deployment.InstallEnv(false); !reflect.DeepEqual(actual, []string{"MGRADM_ADMIN_PASSWORD=secret"}) {
+		t.Errorf("expected the secret in the environment, got %v", actual)
+	}
+	expectedEnv := []string{"MGRADM_ADMIN_PASSWORD=" + utils.RedactedValue}
+	if actual := deployment.InstallEnv(true); !reflect.DeepEqual(actual, expectedEnv) {
+		t.Errorf("expected the redacted secret, got %v", actual)
+	}
+}
+
+func TestFromState(t *testing.T) {
+	state := types.DeploymentState{
+		Backend:  "podman",
+		Image:    "registry.opensuse.org/uyuni/server:2024.05",
+		Timezone: "UTC",
+		Flags: map[string]string{
+			"tag":                 "2024.05",
+			"admin-password":      utils.RedactedValue,
+			"logLevel":            "debug",
+			"ssl-ca-intermediate": "[/certs/a.crt,/certs/b.crt]",
+			"podman-mount-www":    "/srv/www",
+			"podman-arg":          "[--cpus=4]",
+		},
+	}
+	expected := Deployment{
+		Backend:  "podman",
+		Fqdn:     "uyuni.example.com",
+		Image:    "registry.opensuse.org/uyuni/server:2024.05",
+		Timezone: "UTC",
+		Ssl:      Ssl{Intermediate: []string{"/certs/a.crt", "/certs/b.crt"}},
+		Volumes:  Volumes{Www: "/srv/www"},
+		Flags:    map[string]string{"podman-arg": "--cpus=4"},
+	}
+	if actual := FromState(&state, "podman", "uyuni.example.com"); !reflect.DeepEqual(*actual, expected) {
+		t.Errorf("expected %v got %v", expected, *actual)
+	}
+}
+
+func TestRead(t *testing.T) {
+	data := map[string]string{
+		"backend: podman\nfqdn: uyuni.example.com\n":                                        "",
+		"backend: podman\nfqdn: uyuni\n":                                                    "uyuni is not a valid fully qualified domain name",
+		"fqdn: uyuni.example.com\n":                                                         "the deployment backend is required",
+		"backend: podman\nfqdn: uyuni.example.com\nssl:\n  root: /ca.crt\n":                 "the third party SSL root CA, certificate and key are all required",
+		"backend: podman\nfqdn: uyuni.example.com\nproxies:\n  - fqdn: proxy.example.com\n": "proxy proxy.example.com needs either third party SSL certificates or the server CA password",
+		"backend: podman\nfqdn: uyuni.example.com\nproxies:\n  - fqdn: proxy.example.com\n    caPassword: secret\n    ssh:\n      backend: docker\n":             "unsupported backend docker for proxy proxy.example.com",
+		"backend: podman\nfqdn: uyuni.example.com\nparallel: true\nproxies:\n  - fqdn: proxy.example.com\n    caPassword: secret\n    ssh:\n      user: admin\n": "",
+	}
+	dir := t.TempDir()
+	for content, expected := range data {
+		file := path.Join(dir, "deployment.yaml")
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write test file: %s", err)
+		}
+		_, err := Read(file)
+		if expected == "" && err != nil {
+			t.Errorf("%q: unexpected error %s", content, err)
+		} else if expected != "" && (err == nil || err.Error() != expected) {
+			t.Errorf("%q: expected error %q got %v", content, expected, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ssl
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// fipsSignatureAlgorithms are the certificate signature algorithms approved in FIPS mode.
+var fipsSignatureAlgorithms = map[x509.SignatureAlgorithm]bool{
+	x509.SHA256WithRSA:    true,
+	x509.SHA384WithRSA:    true,
+	x509.SHA512WithRSA:    true,
+	x509.SHA256WithRSAPSS: true,
+	x509.SHA384WithRSAPSS: true,
+	x509.SHA512WithRSAPSS: true,
+	x509.ECDSAWithSHA256:  true,
+	x509.ECDSAWithSHA384:  true,
+	x509.ECDSAWithSHA512:  true,
+}
+
+// fipsMinRsaBits is the minimum size of the RSA keys approved in FIPS mode.
+const fipsMinRsaBits = 2048
+
+// CheckFipsCertificates ensures all the certificates of the chain and the server use FIPS-approved algorithms.
+func CheckFipsCertificates(chain *CaChain, serverPair *SslPair) error {
+	paths := append([]string{chain.Root}, chain.Intermediate...)
+	paths = append(paths, serverPair.Cert)
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := checkFipsCertificateFile(path); err != nil {
+			return utils.Errorf(err, L("%[1]s is not FIPS compliant: %[2]s"), path)
+		}
+	}
+	return nil
+}
+
+func checkFipsCertificateFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return err
+		}
+		if err := checkFipsCertificate(cert); err != nil {
+			return err
+		}
+		found = true
+	}
+	if !found {
+		return errors.New(L("no certificate found"))
+	}
+	return nil
+}
+
+func checkFipsCertificate(cert *x509.Certificate) error {
+	if !fipsSignatureAlgorithms[cert.SignatureAlgorithm] {
+		return fmt.Errorf(L("signature algorithm %s is not approved"), cert.SignatureAlgorithm)
+	}
+
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < fipsMinRsaBits {
+			return fmt.Errorf(L("RSA key size %[1]d is lower than %[2]d bits"), key.N.BitLen(), fipsMinRsaBits)
+		}
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		default:
+			return fmt.Errorf(L("elliptic curve %s is not approved"), key.Curve.Params().Name)
+		}
+	default:
+		return fmt.Errorf(L("public key algorithm %s is not approved"), cert.PublicKeyAlgorithm)
+	}
+	return nil
+}
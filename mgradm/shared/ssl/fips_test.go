@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ssl
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckFipsCertificates(t *testing.T) {
+	chain := CaChain{Root: "testdata/chain1/root-ca.crt", Intermediate: []string{"testdata/chain1/intermediate-ca.crt"}}
+	server := SslPair{Cert: "testdata/chain1/server.crt", Key: "testdata/chain1/server.key"}
+	if err := CheckFipsCertificates(&chain, &server); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func writeTestCertificate(t *testing.T, bits int, algorithm x509.SignatureAlgorithm) string {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	template := x509.Certificate{
+		SerialNumber:       big.NewInt(1),
+		Subject:            pkix.Name{CommonName: "test"},
+		NotBefore:          time.Now(),
+		NotAfter:           time.Now().Add(time.Hour),
+		SignatureAlgorithm: algorithm,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "cert.crt")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write certificate: %s", err)
+	}
+	return path
+}
+
+func TestCheckFipsCertificatesWeak(t *testing.T) {
+	weakKey := writeTestCertificate(t, 1024, x509.SHA256WithRSA)
+	if err := CheckFipsCertificates(&CaChain{Root: weakKey}, &SslPair{}); err == nil {
+		t.Error("expected an error for a 1024 bits RSA key")
+	}
+
+	strong := writeTestCertificate(t, 2048, x509.SHA256WithRSA)
+	if err := CheckFipsCertificates(&CaChain{Root: strong}, &SslPair{}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	notCert := filepath.Join(t.TempDir(), "empty.crt")
+	if err := os.WriteFile(notCert, []byte("garbage"), 0600); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+	if err := CheckFipsCertificates(&CaChain{Root: strong}, &SslPair{Cert: notCert}); err == nil {
+		t.Error("expected an error for a file without certificate")
+	}
+}
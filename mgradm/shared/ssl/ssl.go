@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
@@ -23,6 +24,11 @@ type CaChain struct {
 	Intermediate []string
 }
 
+// sslFatal starts a fatal log event with the SSL validation error code.
+func sslFatal() *zerolog.Event {
+	return log.Fatal().Str(utils.ErrorCodeField, string(utils.CodeSSLValidation))
+}
+
 // SslPait is a type for SSL Cert and Key.
 type SslPair struct {
 	Cert string
@@ -44,7 +50,7 @@ func OrderCas(chain *CaChain, serverPair *SslPair) ([]byte, []byte) {
 
 	serverCert, err := findServerCert(certs)
 	if err != nil {
-		log.Fatal().Msg(L("Failed to find a non-CA certificate"))
+		sslFatal().Msg(L("Failed to find a non-CA certificate"))
 	}
 
 	// Map all certificates using their hashes
@@ -89,7 +95,7 @@ func findServerCert(certs []certificate) (*certificate, error) {
 func readCertificates(path string) []certificate {
 	fd, err := os.Open(path)
 	if err != nil {
-		log.Fatal().Err(err).Msgf(L("Failed to read certificate file %s"), path)
+		sslFatal().Err(err).Msgf(L("Failed to read certificate file %s"), path)
 	}
 
 	certs := []certificate{}
@@ -125,7 +131,7 @@ func extractCertificateData(content []byte) certificate {
 
 	out, err := cmd.Output()
 	if err != nil {
-		log.Fatal().Err(err).Msg(L("Failed to extract data from certificate"))
+		sslFatal().Err(err).Msg(L("Failed to extract data from certificate"))
 	}
 	lines := strings.Split(string(out), "\n")
 
@@ -146,13 +152,13 @@ func extractCertificateData(content []byte) certificate {
 			date := strings.SplitN(line, "=", 2)[1]
 			cert.startDate, err = time.Parse(timeLayout, date)
 			if err != nil {
-				log.Fatal().Err(err).Msgf(L("Failed to parse start date: %s\n"), date)
+				sslFatal().Err(err).Msgf(L("Failed to parse start date: %s\n"), date)
 			}
 		} else if strings.HasPrefix(line, "notAfter=") {
 			date := strings.SplitN(line, "=", 2)[1]
 			cert.endDate, err = time.Parse(timeLayout, date)
 			if err != nil {
-				log.Fatal().Err(err).Msgf(L("Failed to parse end date: %s\n"), date)
+				sslFatal().Err(err).Msgf(L("Failed to parse end date: %s\n"), date)
 			}
 		} else if strings.HasPrefix(line, "X509v3 Subject Key Identifier") {
 			nextVal = "subjectKeyId"
@@ -196,14 +202,14 @@ func extractCertificateData(content []byte) certificate {
 // Returns the certificate chain and the root CA.
 func sortCertificates(mapBySubjectHash map[string]certificate, serverCertHash string) ([]byte, []byte) {
 	if len(mapBySubjectHash) == 0 {
-		log.Fatal().Msg(L("No CA found"))
+		sslFatal().Msg(L("No CA found"))
 	}
 
 	cert := mapBySubjectHash[serverCertHash]
 	issuerHash := cert.issuerHash
 	_, found := mapBySubjectHash[issuerHash]
 	if issuerHash == "" || !found {
-		log.Fatal().Msg(L("No CA found for server certificate"))
+		sslFatal().Msg(L("No CA found for server certificate"))
 	}
 
 	sortedChain := bytes.NewBuffer(mapBySubjectHash[serverCertHash].content)
@@ -212,7 +218,7 @@ func sortCertificates(mapBySubjectHash map[string]certificate, serverCertHash st
 	for {
 		cert, found = mapBySubjectHash[issuerHash]
 		if !found {
-			log.Fatal().Msgf(L("Missing CA with subject hash %s"), issuerHash)
+			sslFatal().Msgf(L("Missing CA with subject hash %s"), issuerHash)
 		}
 
 		nextHash := cert.issuerHash
@@ -239,14 +245,14 @@ func CheckPaths(chain *CaChain, serverPair *SslPair) {
 
 func mandatoryFile(file string, msg string) {
 	if file == "" {
-		log.Fatal().Msgf(msg)
+		sslFatal().Msgf(msg)
 	}
 	optionalFile(file)
 }
 
 func optionalFile(file string) {
 	if file != "" && !utils.FileExists(file) {
-		log.Fatal().Msgf(L("%s file is not accessible"), file)
+		sslFatal().Msgf(L("%s file is not accessible"), file)
 	}
 }
 
@@ -254,14 +260,14 @@ func optionalFile(file string) {
 func GetRsaKey(keyPath string, password string) []byte {
 	// Kubernetes only handles RSA private TLS keys, convert and strip password
 	caPassword := password
-	utils.AskPasswordIfMissing(&caPassword, L("Source server SSL CA private key password"), 0, 0)
+	utils.AskPasswordIfMissing(&caPassword, L("Source server SSL CA private key password"), 0, 0, nil)
 
 	// Convert the key file to RSA format for kubectl to handle it
 	cmd := exec.Command("openssl", "rsa", "-in", keyPath, "-passin", "env:pass")
 	cmd.Env = append(cmd.Env, "pass="+caPassword)
 	out, err := cmd.Output()
 	if err != nil {
-		log.Fatal().Err(err).Msg(L("Failed to convert CA private key to RSA"))
+		sslFatal().Err(err).Msg(L("Failed to convert CA private key to RSA"))
 	}
 	return out
 }
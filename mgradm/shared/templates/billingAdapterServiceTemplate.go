@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"io"
+	"text/template"
+
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+const billingAdapterServiceTemplate = `# uyuni-server-billing-adapter.service, generated by mgradm
+# Use an uyuni-server-billing-adapter.service.d/local.conf file or the /etc/uyuni/templates/uyuni-server-billing-adapter.service.yaml file to override
+
+[Unit]
+Description=Uyuni server billing adapter container service for pay-as-you-go cloud instances
+Wants=network.target
+After=network-online.target
+{{- range .Override.After }}
+After={{ . }}
+{{- end }}
+{{- range .Override.Requires }}
+Requires={{ . }}
+{{- end }}
+
+[Service]
+Environment=PODMAN_SYSTEMD_UNIT=%n
+{{- range .Override.EnvironmentLines }}
+{{ . }}
+{{- end }}
+Restart=on-failure
+ExecStartPre=/bin/rm -f %t/uyuni-server-billing-adapter.pid %t/%n.ctr-id
+ExecStartPre=/usr/bin/podman rm --ignore --force -t 10 {{ .NamePrefix }}-server-billing-adapter
+ExecStart=/usr/bin/podman run \
+	--conmon-pidfile %t/uyuni-server-billing-adapter.pid \
+	--cidfile=%t/%n.ctr-id \
+	--cgroups=no-conmon \
+	--sdnotify=conmon \
+	-d \
+	--replace \
+	-v {{ .Volume.Name }}:{{ .Volume.MountPath }} \
+	{{- range .HostPaths }}
+	-v {{ . }}:{{ . }}:ro \
+	{{- end }}
+	{{- if .Override.PodmanArgs }}
+	{{ .Override.PodmanArgs }} \
+	{{- end }}
+	--name {{ .NamePrefix }}-server-billing-adapter \
+	--hostname {{ .NamePrefix }}-server-billing-adapter.mgr.internal \
+	--network {{ .Network }} \
+	${UYUNI_IMAGE}
+
+ExecStop=/usr/bin/podman stop --ignore -t 10 --cidfile=%t/%n.ctr-id
+ExecStopPost=/usr/bin/podman rm -f --ignore -t 10 --cidfile=%t/%n.ctr-id
+PIDFile=%t/uyuni-server-billing-adapter.pid
+TimeoutStopSec=60
+TimeoutStartSec=60
+Type=forking
+
+[Install]
+WantedBy=multi-user.target default.target
+`
+
+// BillingAdapterServiceTemplateData represents the information used to create the billing adapter systemd service.
+type BillingAdapterServiceTemplateData struct {
+	NamePrefix string
+	Network    string
+	Volume     types.VolumeMount
+	// HostPaths are the host paths mounted read-only to access the cloud instance metadata.
+	HostPaths []string
+	Override  utils.ServiceOverride
+}
+
+// WithOverride returns a copy of the template data with the user override.
+func (data BillingAdapterServiceTemplateData) WithOverride(override utils.ServiceOverride) utils.Template {
+	data.Override = override
+	return data
+}
+
+// Render will create the systemd configuration file.
+func (data BillingAdapterServiceTemplateData) Render(wr io.Writer) error {
+	t := template.Must(template.New("service").Parse(billingAdapterServiceTemplate))
+	return t.Execute(wr, data)
+}
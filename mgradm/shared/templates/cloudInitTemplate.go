@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"io"
+	"text/template"
+)
+
+const cloudInitTemplate = `#cloud-config
+# Generated by mgradm: installs an Uyuni server on podman on the first boot
+write_files:
+  - path: {{ .ConfigPath }}
+    owner: root:root
+    permissions: '0600'
+    encoding: b64
+    content: {{ .Config }}
+{{- if .Repository }}
+zypper:
+  repos:
+    - id: uyuni-container-utils
+      name: Uyuni container utilities
+      baseurl: {{ printf "%q" .Repository }}
+      enabled: 1
+      autorefresh: 1
+{{- end }}
+packages:
+{{- range .Packages }}
+  - {{ . }}
+{{- end }}
+runcmd:
+  - [{{ range $i, $arg := .InstallArgs }}{{ if $i }}, {{ end }}{{ printf "%q" $arg }}{{ end }}]
+`
+
+// ProvisioningTemplateData represents the information used to create the unattended installation snippets.
+type ProvisioningTemplateData struct {
+	// Config is the base64-encoded content of the mgradm configuration file.
+	Config     string
+	ConfigPath string
+	Repository string
+	Packages   []string
+	// InstallArgs is the mgradm command line installing the server.
+	InstallArgs []string
+}
+
+// CloudInitTemplateData represents the information used to create a cloud-init user data.
+type CloudInitTemplateData struct {
+	ProvisioningTemplateData
+}
+
+// Render will create the cloud-init user data.
+func (data CloudInitTemplateData) Render(wr io.Writer) error {
+	t := template.Must(template.New("cloud-init").Parse(cloudInitTemplate))
+	return t.Execute(wr, data)
+}
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"io"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+const combustionTemplate = `#!/bin/bash
+# combustion: network
+# Generated by mgradm: installs an Uyuni server on podman on the first boot
+set -e
+{{- if .Repository }}
+zypper --non-interactive addrepo --refresh {{ quote .Repository }} uyuni-container-utils
+zypper --non-interactive --gpg-auto-import-keys refresh
+{{- end }}
+zypper --non-interactive install{{ range .Packages }} {{ quote . }}{{ end }}
+
+mkdir -p {{ quote .ConfigDir }}
+echo {{ quote .Config }} | base64 -d > {{ quote .ConfigPath }}
+chmod 600 {{ quote .ConfigPath }}
+
+# Combustion runs before the system is booted: install the server once booted
+cat > /etc/systemd/system/uyuni-first-boot-install.service <<'UNIT'
+[Unit]
+Description=Install the Uyuni server on the first boot
+Wants=network-online.target
+After=network-online.target
+ConditionPathExists=!/etc/systemd/system/uyuni-server.service
+
+[Service]
+Type=oneshot
+ExecStart={{ .ExecStart }}
+
+[Install]
+WantedBy=multi-user.target
+UNIT
+systemctl enable uyuni-first-boot-install.service
+`
+
+// CombustionTemplateData represents the information used to create a combustion script.
+type CombustionTemplateData struct {
+	ProvisioningTemplateData
+}
+
+// ConfigDir returns the folder of the configuration file.
+func (data CombustionTemplateData) ConfigDir() string {
+	return path.Dir(data.ConfigPath)
+}
+
+// ExecStart returns the install command line quoted for systemd.
+// The first argument is the absolute path to the executable and is not quoted.
+func (data CombustionTemplateData) ExecStart() string {
+	if len(data.InstallArgs) == 0 {
+		return ""
+	}
+	args := []string{data.InstallArgs[0]}
+	for _, arg := range data.InstallArgs[1:] {
+		args = append(args, utils.SystemdQuote(arg, true))
+	}
+	return strings.Join(args, " ")
+}
+
+// Render will create the combustion script.
+func (data CombustionTemplateData) Render(wr io.Writer) error {
+	funcs := template.FuncMap{"quote": utils.ShellQuote}
+	t := template.Must(template.New("combustion").Funcs(funcs).Parse(combustionTemplate))
+	return t.Execute(wr, data)
+}
@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"io"
+	"text/template"
+
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+const componentServiceTemplate = `# {{ .Service }}.service, generated by mgradm from the {{ .Name }} component manifest
+# Use an {{ .Service }}.service.d/local.conf file or the /etc/uyuni/templates/{{ .Service }}.service.yaml file to override
+
+[Unit]
+Description={{ .Description }}
+X-UyuniComponent={{ .Name }}
+Wants=network.target
+After=network-online.target uyuni-server.service{{ if .Requires }} {{ .Requires }}{{ end }}
+{{- if .Requires }}
+Requires={{ .Requires }}
+{{- end }}
+{{- range .Override.After }}
+After={{ . }}
+{{- end }}
+{{- range .Override.Requires }}
+Requires={{ . }}
+{{- end }}
+
+[Service]
+Environment=PODMAN_SYSTEMD_UNIT=%n
+{{- range .Override.EnvironmentLines }}
+{{ . }}
+{{- end }}
+Restart=on-failure
+ExecStartPre=/bin/rm -f %t/{{ .Service }}.pid %t/%n.ctr-id
+ExecStartPre=/usr/bin/podman rm --ignore --force -t 10 {{ .Service }}
+ExecStart=/usr/bin/podman run \
+	--conmon-pidfile %t/{{ .Service }}.pid \
+	--cidfile=%t/%n.ctr-id \
+	--cgroups=no-conmon \
+	--sdnotify=conmon \
+	-d \
+	--replace \
+	-e TZ={{ .Timezone }} \
+	{{- range $name, $value := .Env }}
+	-e {{ $name }} \
+	{{- end }}
+	{{- range .Ports }}
+	-p {{ .Exposed }}:{{ .Port }}{{ if .Protocol }}/{{ .Protocol }}{{ end }} \
+	{{- end }}
+	{{- range .Volumes }}
+	-v {{ .Name }}:{{ .MountPath }} \
+	{{- end }}
+	{{- if .Args }}
+	{{ .Args }} \
+	{{- end }}
+	{{- if .Override.PodmanArgs }}
+	{{ .Override.PodmanArgs }} \
+	{{- end }}
+	--name {{ .Service }} \
+	--hostname {{ .Service }}.mgr.internal \
+	--network {{ .Network }} \
+	${UYUNI_IMAGE}{{ if .Command }} {{ .Command }}{{ end }}
+
+ExecStop=/usr/bin/podman stop --ignore -t 10 --cidfile=%t/%n.ctr-id
+ExecStopPost=/usr/bin/podman rm -f --ignore -t 10 --cidfile=%t/%n.ctr-id
+PIDFile=%t/{{ .Service }}.pid
+TimeoutStopSec=60
+TimeoutStartSec=60
+Type=forking
+
+[Install]
+WantedBy=multi-user.target default.target
+`
+
+// ComponentServiceTemplateData contains the information to create the systemd file of an optional component.
+type ComponentServiceTemplateData struct {
+	Name        string
+	Description string
+	Service     string
+	Network     string
+	Timezone    string
+	// Requires is the space-separated list of the services the component depends on.
+	Requires string
+	// Env contains the environment variables passed to the container.
+	// Only the names are in the service file, the values are set in the service configuration file.
+	Env     map[string]string
+	Ports   []types.PortMap
+	Volumes []types.VolumeMount
+	Args    string
+	Command string
+	// Override contains the user additions from the templates overrides folder.
+	Override utils.ServiceOverride
+}
+
+// WithOverride returns a copy of the template data with the user override.
+func (data ComponentServiceTemplateData) WithOverride(override utils.ServiceOverride) utils.Template {
+	data.Override = override
+	return data
+}
+
+// Render will create the systemd configuration file.
+func (data ComponentServiceTemplateData) Render(wr io.Writer) error {
+	t := template.Must(template.New("service").Parse(componentServiceTemplate))
+	return t.Execute(wr, data)
+}
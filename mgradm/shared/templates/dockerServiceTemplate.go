@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"io"
+	"text/template"
+
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+const dockerServiceTemplate = `# uyuni-server.service, generated by mgradm
+# Use an uyuni-server.service.d/local.conf file to override
+
+[Unit]
+Description=Uyuni server image container service
+Wants=network.target
+After=network-online.target docker.service
+Requires=docker.service
+
+[Service]
+Environment=TZ={{ .Timezone }}
+Restart=on-failure
+ExecStartPre=-/usr/bin/docker rm --force {{ .NamePrefix }}-server
+ExecStart=/usr/bin/docker run \
+	--name {{ .NamePrefix }}-server \
+	--hostname {{ .NamePrefix }}-server.mgr.internal \
+	{{ .Args }} \
+	{{- range .Ports }}
+	-p {{ .Exposed }}:{{ .Port }}{{if .Protocol}}/{{ .Protocol }}{{end}} \
+	{{- end }}
+	{{- range .Volumes }}
+	-v {{ .Name }}:{{ .MountPath }} \
+	{{- end }}
+	-e TZ=${TZ} \
+	--network {{ .Network }} \
+	${UYUNI_IMAGE}
+ExecStop=/usr/bin/docker exec \
+	{{ .NamePrefix }}-server \
+	/bin/bash -c 'spacewalk-service stop && systemctl stop postgresql'
+ExecStop=/usr/bin/docker stop -t 10 {{ .NamePrefix }}-server
+
+TimeoutStopSec=180
+TimeoutStartSec=900
+Type=simple
+
+[Install]
+WantedBy=multi-user.target default.target
+`
+
+// DockerServiceTemplateData contains the information to create the docker server systemd file.
+type DockerServiceTemplateData struct {
+	Volumes    []types.VolumeMount
+	NamePrefix string
+	Args       string
+	Ports      []types.PortMap
+	Timezone   string
+	Network    string
+}
+
+// Render will create the systemd configuration file.
+func (data DockerServiceTemplateData) Render(wr io.Writer) error {
+	t := template.Must(template.New("service").Parse(dockerServiceTemplate))
+	return t.Execute(wr, data)
+}
@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// shellQuoteReplacer escapes the characters interpreted by the shell in a double-quoted string.
+var shellQuoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+
+// scriptFuncs returns the template functions of the scripts printing localized messages.
+//
+// The msg function takes the key of the message in the messages map and its arguments.
+// The messages are localized in Go code so that they can be extracted and are escaped to be safely
+// inserted in the script, while the arguments are inserted as is to allow using shell variables.
+func scriptFuncs(messages map[string]string) template.FuncMap {
+	return template.FuncMap{
+		"msg": func(key string, args ...any) (string, error) {
+			message, ok := messages[key]
+			if !ok {
+				return "", fmt.Errorf(L("unknown script message: %s"), key)
+			}
+			return shellMessage(message, args...), nil
+		},
+	}
+}
+
+// shellMessage formats a message as a double-quoted shell string.
+//
+// Invalid UTF-8 sequences coming from the translations are replaced to keep the script valid UTF-8.
+func shellMessage(format string, args ...any) string {
+	message := shellQuoteReplacer.Replace(strings.ToValidUTF8(format, "?"))
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+	return `"` + message + `"`
+}
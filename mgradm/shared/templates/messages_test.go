@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+)
+
+func TestShellMessage(t *testing.T) {
+	data := []struct {
+		format   string
+		args     []any
+		expected string
+	}{
+		{"Schema update...", nil, `"Schema update..."`},
+		{"Copying %s...", []any{"$folder"}, `"Copying $folder..."`},
+		{"say \"$x\" `cmd` \\", nil, "\"say \\\"\\$x\\\" \\`cmd\\` \\\\\""},
+		{"Copie de %s en cours…", []any{"$folder"}, `"Copie de $folder en cours…"`},
+		{"Invalid \xff byte", nil, `"Invalid ? byte"`},
+	}
+
+	for i, test := range data {
+		if actual := shellMessage(test.format, test.args...); actual != test.expected {
+			t.Errorf("Testcase %d: expected %s, got %s", i, test.expected, actual)
+		}
+	}
+}
+
+func TestScriptFuncsUnknownMessage(t *testing.T) {
+	tmpl := template.Must(template.New("script").Funcs(scriptFuncs(map[string]string{})).Parse(`echo {{ msg "missing" }}`))
+	if err := tmpl.Execute(&bytes.Buffer{}, nil); err == nil {
+		t.Error("Expected an error for an unknown message")
+	}
+}
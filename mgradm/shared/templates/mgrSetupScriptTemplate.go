@@ -21,14 +21,20 @@ echo 'JAVA_OPTS=" $JAVA_OPTS -Xdebug -Xrunjdwp:transport=dt_socket,address=*:800
 {{- end }}
 
 /usr/lib/susemanager/bin/mgr-setup -s -n
+{{- if .ExternalDb }}
+
+# The bundled database is not used
+systemctl disable --now postgresql.service
+{{- end }}
 
 # clean before leaving
 rm $0`
 
 // MgrSetupScriptTemplateData represents information used to create setup script.
 type MgrSetupScriptTemplateData struct {
-	Env       map[string]string
-	DebugJava bool
+	Env        map[string]string
+	DebugJava  bool
+	ExternalDb bool
 }
 
 // Render will create setup script.
@@ -8,26 +8,41 @@ import (
 	"io"
 	"text/template"
 
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 )
 
 const migrationScriptTemplate = `#!/bin/bash
 set -e
-SSH_CONFIG=""
-if test -e /tmp/ssh_config; then
-  SSH_CONFIG="-F /tmp/ssh_config"
-fi
-SSH="ssh -o User={{ .User }} -A $SSH_CONFIG "
+SSH="ssh -A -F /var/lib/uyuni-tools/ssh_config "
+
+# Files collecting the data for the migration report
+rm -f /var/lib/uyuni-tools/sizes /var/lib/uyuni-tools/skipped /var/lib/uyuni-tools/warnings
+warn() {
+  echo {{ msg "warning" "$*" }} >&2
+  echo "$*" >> /var/lib/uyuni-tools/warnings
+}
 
-echo "Stopping spacewalk service..."
+{{ if .Prepare }}
+echo {{ msg "presync" }}
+{{ else }}
+echo {{ msg "stopSpacewalk" }}
 $SSH {{ .SourceFqdn }} "sudo spacewalk-service stop ; sudo systemctl start postgresql.service"
+{{ end }}
 
 $SSH {{ .SourceFqdn }} \
  "echo \"COPY (SELECT MIN(CONCAT(org_id, '-', label)) AS target, base_path FROM rhnKickstartableTree GROUP BY base_path) TO STDOUT WITH CSV;\" \
  |sudo spacewalk-sql --select-mode - " > distros
 
-echo "Stopping posgresql service..."
+{{ if not .Prepare }}
+echo {{ msg "stopPostgresql" }}
 $SSH {{ .SourceFqdn }} "sudo systemctl stop postgresql.service"
+{{ end }}
+
+{{ if .Final }}
+echo {{ msg "disable" }}
+$SSH {{ .SourceFqdn }} "sudo spacewalk-service disable ; sudo systemctl disable postgresql.service"
+{{ end }}
 
 while IFS="," read -r target path ; do
     echo "-/ $path"
@@ -49,44 +64,54 @@ echo "-/ /etc/sysconfig/rhn/schema-upgrade" >> exclude_list
 for folder in {{ range .Volumes }}{{ .MountPath }} {{ end }};
 do
   if $SSH {{ .SourceFqdn }} test -e $folder; then
-    echo "Copying $folder..."
+    echo {{ msg "copy" "$folder" }}
     rsync -e "$SSH" --rsync-path='sudo rsync' -avz -f "merge exclude_list" {{ .SourceFqdn }}:$folder/ $folder;
+    echo "$folder $(du -sb $folder | cut -f1)" >> /var/lib/uyuni-tools/sizes
   else
-    echo "Skipping missing $folder..."
+    echo {{ msg "skip" "$folder" }}
+    echo "$folder" >> /var/lib/uyuni-tools/skipped
   fi
 done;
 
 sed -i -e 's|appBase="webapps"|appBase="/usr/share/susemanager/www/tomcat/webapps"|' /etc/tomcat/server.xml
 sed -i -e 's|DocumentRoot\s*"/srv/www/htdocs"|DocumentRoot "/usr/share/susemanager/www/htdocs"|' /etc/apache2/vhosts.d/vhost-ssl.conf
 
-echo "Migrating auto-installable distributions..."
+echo {{ msg "distros" }}
 
 while IFS="," read -r target path ; do
   if $SSH -n {{ .SourceFqdn }} test -e $path ; then
-    echo "Copying distribution $target from $path"
+    echo {{ msg "copyDistro" "$target" "$path" }}
     mkdir -p "/srv/www/distributions/$target"
     rsync -e "$SSH" --rsync-path='sudo rsync' -avz "{{ .SourceFqdn }}:$path/" "/srv/www/distributions/$target"
+    echo "/srv/www/distributions/$target $(du -sb /srv/www/distributions/$target | cut -f1)" >> /var/lib/uyuni-tools/sizes
   else
-    echo "Skipping missing distribution $path..."
+    echo "$path" >> /var/lib/uyuni-tools/skipped
+    warn {{ msg "skipDistro" "$target" "$path" }}
   fi
 done < distros
 
+{{ if .Prepare }}
+echo {{ msg "presyncDone" }}
+echo "DONE"
+exit 0
+{{ end }}
+
 rm -f /srv/www/htdocs/pub/RHN-ORG-TRUSTED-SSL-CERT;
 ln -s /etc/pki/trust/anchors/LOCAL-RHN-ORG-TRUSTED-SSL-CERT /srv/www/htdocs/pub/RHN-ORG-TRUSTED-SSL-CERT;
 
-echo "Extracting time zone..."
+echo {{ msg "timezone" }}
 $SSH {{ .SourceFqdn }} timedatectl show -p Timezone >/var/lib/uyuni-tools/data
 
-echo "Extracting postgresql versions..."
+echo {{ msg "pgVersions" }}
 echo "new_pg_version=$(rpm -qa --qf '%{VERSION}\n' 'name=postgresql[0-8][0-9]-server'  | cut -d. -f1 | sort -n | tail -1)" >> /var/lib/uyuni-tools/data
 echo "old_pg_version=$(cat /var/lib/pgsql/data/PG_VERSION)" >> /var/lib/uyuni-tools/data
 
-echo "Altering configuration for domain resolution..."
+echo {{ msg "domain" }}
 sed 's/report_db_host = {{ .SourceFqdn }}/report_db_host = localhost/' -i /etc/rhn/rhn.conf;
 sed 's/server\.jabber_server/java\.hostname/' -i /etc/rhn/rhn.conf;
 sed 's/client_use_localhost: false/client_use_localhost: true/' -i /etc/cobbler/settings.yaml;
 
-echo "Altering configuration for container environment..."
+echo {{ msg "container" }}
 sed 's/address=[^:]*:/address=*:/' -i /etc/rhn/taskomatic.conf;
 
 if test ! -f /etc/tomcat/conf.d/remote_debug.conf -a -f /etc/sysconfig/tomcat; then
@@ -97,7 +122,7 @@ sed 's/address=[^:]*:/address=*:/' -i /etc/tomcat/conf.d/remote_debug.conf
 
 {{ if .Kubernetes }}
 echo 'server.no_ssl = 1' >> /etc/rhn/rhn.conf;
-echo "Extracting SSL certificate and authority"
+echo {{ msg "ssl" }}
 extractedSSL=
 if test -d /root/ssl-build; then
   # We may have an old unused ssl-build folder, check if the CA matches the deployed one
@@ -108,7 +133,7 @@ if test -d /root/ssl-build; then
   caFingerprint=$(openssl x509 -in /etc/pki/trust/anchors/LOCAL-RHN-ORG-TRUSTED-SSL-CERT -noout -fingerprint)
 
   if test "$buildCaFingerprint" == "$caFingerprint"; then
-    echo "Extracting SSL Root CA key..."
+    echo {{ msg "caKey" }}
     # Extract the SSL CA certificate and key.
     # The server certificate will be auto-generated by cert-manager using it, so no need to copy it.
     cp /root/ssl-build/RHN-ORG-PRIVATE-SSL-KEY /var/lib/uyuni-tools/
@@ -127,7 +152,7 @@ if test "extractedSSL" != "1"; then
 
 fi
 
-echo "Removing useless ssl-build folder..."
+echo {{ msg "sslBuild" }}
 rm -rf /root/ssl-build
 
 # The content of this folder will be a RO mount from a configmap
@@ -142,10 +167,37 @@ type MigrateScriptTemplateData struct {
 	SourceFqdn string
 	User       string
 	Kubernetes bool
+	Prepare    bool
+	Final      bool
+}
+
+// migrationMessages returns the localized messages of the migration script.
+func migrationMessages() map[string]string {
+	return map[string]string{
+		"warning":        L("WARNING: %s"),
+		"presync":        L("Pre-synchronizing the data while the source server is running..."),
+		"stopSpacewalk":  L("Stopping spacewalk service..."),
+		"stopPostgresql": L("Stopping posgresql service..."),
+		"disable":        L("Disabling the services on the source server..."),
+		"copy":           L("Copying %s..."),
+		"skip":           L("Skipping missing %s..."),
+		"distros":        L("Migrating auto-installable distributions..."),
+		"copyDistro":     L("Copying distribution %s from %s"),
+		"skipDistro":     L("Skipping missing distribution %s in %s"),
+		"presyncDone":    L("Data pre-synchronized, the source server is still running"),
+		"timezone":       L("Extracting time zone..."),
+		"pgVersions":     L("Extracting postgresql versions..."),
+		"domain":         L("Altering configuration for domain resolution..."),
+		"container":      L("Altering configuration for container environment..."),
+		"ssl":            L("Extracting SSL certificate and authority"),
+		"caKey":          L("Extracting SSL Root CA key..."),
+		"sslBuild":       L("Removing useless ssl-build folder..."),
+	}
 }
 
 // Render will create migration script.
 func (data MigrateScriptTemplateData) Render(wr io.Writer) error {
-	t := template.Must(template.New("script").Parse(migrationScriptTemplate))
+	funcs := scriptFuncs(migrationMessages())
+	t := template.Must(template.New("script").Funcs(funcs).Parse(migrationScriptTemplate))
 	return t.Execute(wr, data)
 }
@@ -7,30 +7,41 @@ package templates
 import (
 	"io"
 	"text/template"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 )
 
 const postgresFinalizeScriptTemplate = `#!/bin/bash
 set -e
 
 {{ if .RunAutotune }}
-echo "Running smdba system-check autotuning..."
+echo {{ msg "autotune" }}
 smdba system-check autotuning
 {{ end }}
-echo "Starting Postgresql..."
+echo {{ msg "start" }}
 su -s /bin/bash - postgres -c "/usr/share/postgresql/postgresql-script start"
+{{ if .RunTuning }}
+echo {{ msg "tuning" }}
+mem_mb=$(( $(sed -n 's/^MemTotal:\s*\([0-9]*\) kB$/\1/p' /proc/meminfo) / 1024 ))
+shared_buffers=$(( mem_mb / 4 ))
+effective_cache_size=$(( mem_mb * 3 / 4 ))
+echo {{ msg "tuningValues" "${shared_buffers}" "${effective_cache_size}" }}
+su -s /bin/bash - postgres -c "psql -c \"ALTER SYSTEM SET shared_buffers = '${shared_buffers}MB'\""
+su -s /bin/bash - postgres -c "psql -c \"ALTER SYSTEM SET effective_cache_size = '${effective_cache_size}MB'\""
+{{ end }}
 {{ if .RunReindex }}
-echo "Reindexing database. This may take a while, please do not cancel it!"
+echo {{ msg "reindex" }}
 database=$(sed -n "s/^\s*db_name\s*=\s*\([^ ]*\)\s*$/\1/p" /etc/rhn/rhn.conf)
 spacewalk-sql --select-mode - <<<"REINDEX DATABASE \"${database}\";"
 {{ end }}
 
 {{ if .RunSchemaUpdate }}
-echo "Schema update..."
+echo {{ msg "schemaUpdate" }}
 /usr/sbin/spacewalk-startup-helper check-database
 {{ end }}
 
 {{ if .RunDistroMigration }}
-echo "Updating auto-installable distributions..."
+echo {{ msg "distros" }}
 spacewalk-sql --select-mode - <<EOT
 SELECT MIN(CONCAT(org_id, '-', label)) AS target, base_path INTO TEMP TABLE dist_map FROM rhnKickstartableTree GROUP BY base_path;
 UPDATE rhnKickstartableTree SET base_path = CONCAT('/srv/www/distributions/', target)
@@ -39,7 +50,12 @@ DROP TABLE dist_map;
 EOT
 {{ end }}
 
-echo "Schedule a system list update task..."
+{{ if .RunAnalyze }}
+echo {{ msg "analyze" }}
+su -s /bin/bash - postgres -c "vacuumdb --all --analyze-in-stages"
+{{ end }}
+
+echo {{ msg "systemList" }}
 spacewalk-sql --select-mode - <<EOT
 insert into rhnTaskQueue (id, org_id, task_name, task_data)
 SELECT nextval('rhn_task_queue_id_seq'), 1, 'update_system_overview', s.id
@@ -49,7 +65,7 @@ join rhntaskobunch b on t.bunch_id = b.id where b.name='update-system-overview-b
 EOT
 
 
-echo "Stopping Postgresql..."
+echo {{ msg "stop" }}
 su -s /bin/bash - postgres -c "/usr/share/postgresql/postgresql-script stop"
 echo "DONE"
 `
@@ -60,11 +76,30 @@ type FinalizePostgresTemplateData struct {
 	RunReindex         bool
 	RunSchemaUpdate    bool
 	RunDistroMigration bool
+	RunTuning          bool
+	RunAnalyze         bool
 	Kubernetes         bool
 }
 
+// postgresFinalizeMessages returns the localized messages of the PostgreSQL finalization script.
+func postgresFinalizeMessages() map[string]string {
+	return map[string]string{
+		"autotune":     L("Running smdba system-check autotuning..."),
+		"start":        L("Starting Postgresql..."),
+		"tuning":       L("Tuning PostgreSQL memory settings..."),
+		"tuningValues": L("Setting shared_buffers to %sMB and effective_cache_size to %sMB"),
+		"reindex":      L("Reindexing database. This may take a while, please do not cancel it!"),
+		"schemaUpdate": L("Schema update..."),
+		"distros":      L("Updating auto-installable distributions..."),
+		"analyze":      L("Refreshing the database statistics. This may take a while..."),
+		"systemList":   L("Schedule a system list update task..."),
+		"stop":         L("Stopping Postgresql..."),
+	}
+}
+
 // Render will create script for finalizing PostgreSQL upgrade.
 func (data FinalizePostgresTemplateData) Render(wr io.Writer) error {
-	t := template.Must(template.New("script").Parse(postgresFinalizeScriptTemplate))
+	funcs := scriptFuncs(postgresFinalizeMessages())
+	t := template.Must(template.New("script").Funcs(funcs).Parse(postgresFinalizeScriptTemplate))
 	return t.Execute(wr, data)
 }
@@ -7,31 +7,39 @@ package templates
 import (
 	"io"
 	"text/template"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 )
 
 const postgreSQLVersionUpgradeScriptTemplate = `#!/bin/bash
 set -e
-echo "PostgreSQL version upgrade"
+echo {{ msg "upgrade" }}
 
 OLD_VERSION={{ .OldVersion }}
 NEW_VERSION={{ .NewVersion }}
-FAST_UPGRADE=--link
+UPGRADE_ARGS="{{ if .Link }}--link{{ end }}{{ if gt .Jobs 1 }} --jobs {{ .Jobs }}{{ end }}"
 
-echo "Testing presence of postgresql$NEW_VERSION..."
+echo {{ msg "testNew" "$NEW_VERSION" }}
 test -d /usr/lib/postgresql$NEW_VERSION/bin
-echo "Testing presence of postgresql$OLD_VERSION..."
+echo {{ msg "testOld" "$OLD_VERSION" }}
 test -d /usr/lib/postgresql$OLD_VERSION/bin
-
-echo "Create a backup at /var/lib/pgsql/data-pg$OLD_VERSION..."
+{{ if .Link }}
+echo {{ msg "checkFs" }}
+if [ "$(stat -c %d /var/lib/pgsql)" != "$(stat -c %d /var/lib/pgsql/data)" ]; then
+    echo {{ msg "notSameFs" }}
+    exit 1
+fi
+{{ end }}
+echo {{ msg "backup" "$OLD_VERSION" }}
 mv /var/lib/pgsql/data /var/lib/pgsql/data-pg$OLD_VERSION
-echo "Create new database directory..."
+echo {{ msg "newDir" }}
 mkdir -p /var/lib/pgsql/data
 chown -R postgres:postgres /var/lib/pgsql
-echo "Enforce key permission"
+echo {{ msg "keyPermission" }}
 chown -R postgres:postgres /etc/pki/tls/private/pg-spacewalk.key
 chown -R postgres:postgres /etc/pki/tls/certs/spacewalk.crt
 
-echo "Initialize new postgresql $NEW_VERSION database..."
+echo {{ msg "initDb" "$NEW_VERSION" }}
 . /etc/sysconfig/postgresql 2>/dev/null # Load locale for SUSE
 PGHOME=$(getent passwd postgres | cut -d ":" -f6)
 #. $PGHOME/.i18n 2>/dev/null # Load locale for Enterprise Linux
@@ -40,11 +48,11 @@ if [ -z $POSTGRES_LANG ]; then
     [ ! -z $LC_CTYPE ] && POSTGRES_LANG=$LC_CTYPE
 fi
 
-echo "Running initdb using postgres user"
-echo "Any suggested command from the console should be run using postgres user"
+echo {{ msg "runInitDb" }}
+echo {{ msg "postgresUser" }}
 su -s /bin/bash - postgres -c "initdb -D /var/lib/pgsql/data --locale=$POSTGRES_LANG"
-echo "Successfully initialized new postgresql $NEW_VERSION database."
-su -s /bin/bash - postgres -c "pg_upgrade --old-bindir=/usr/lib/postgresql$OLD_VERSION/bin --new-bindir=/usr/lib/postgresql$NEW_VERSION/bin --old-datadir=/var/lib/pgsql/data-pg$OLD_VERSION --new-datadir=/var/lib/pgsql/data $FAST_UPGRADE"
+echo {{ msg "initDbDone" "$NEW_VERSION" }}
+su -s /bin/bash - postgres -c "pg_upgrade --old-bindir=/usr/lib/postgresql$OLD_VERSION/bin --new-bindir=/usr/lib/postgresql$NEW_VERSION/bin --old-datadir=/var/lib/pgsql/data-pg$OLD_VERSION --new-datadir=/var/lib/pgsql/data $UPGRADE_ARGS"
 
 echo "DONE"`
 
@@ -52,11 +60,32 @@ echo "DONE"`
 type PostgreSQLVersionUpgradeTemplateData struct {
 	OldVersion string
 	NewVersion string
+	Link       bool
+	Jobs       int
 	Kubernetes bool
 }
 
+// postgreSQLVersionUpgradeMessages returns the localized messages of the PostgreSQL version upgrade script.
+func postgreSQLVersionUpgradeMessages() map[string]string {
+	return map[string]string{
+		"upgrade":       L("PostgreSQL version upgrade"),
+		"testNew":       L("Testing presence of postgresql%s..."),
+		"testOld":       L("Testing presence of postgresql%s..."),
+		"checkFs":       L("Checking that the old and new databases are on the same filesystem..."),
+		"notSameFs":     L("/var/lib/pgsql/data is not on the same filesystem than /var/lib/pgsql: hard links cannot be used, disable them to upgrade by copying the files"),
+		"backup":        L("Create a backup at /var/lib/pgsql/data-pg%s..."),
+		"newDir":        L("Create new database directory..."),
+		"keyPermission": L("Enforce key permission"),
+		"initDb":        L("Initialize new postgresql %s database..."),
+		"runInitDb":     L("Running initdb using postgres user"),
+		"postgresUser":  L("Any suggested command from the console should be run using postgres user"),
+		"initDbDone":    L("Successfully initialized new postgresql %s database."),
+	}
+}
+
 // Render will create PostgreSQL migration script.
 func (data PostgreSQLVersionUpgradeTemplateData) Render(wr io.Writer) error {
-	t := template.Must(template.New("script").Parse(postgreSQLVersionUpgradeScriptTemplate))
+	funcs := scriptFuncs(postgreSQLVersionUpgradeMessages())
+	t := template.Must(template.New("script").Funcs(funcs).Parse(postgreSQLVersionUpgradeScriptTemplate))
 	return t.Execute(wr, data)
 }
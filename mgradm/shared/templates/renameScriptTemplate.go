@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"io"
+	"text/template"
+)
+
+const renameScriptTemplate = `#!/bin/bash
+set -e
+
+NEW_FQDN="{{ .Fqdn }}"
+OLD_FQDN=$(sed -n 's/^\s*java\.hostname\s*=\s*\([^ ]*\)\s*$/\1/p' /etc/rhn/rhn.conf)
+echo "Renaming the server from ${OLD_FQDN} to ${NEW_FQDN}..."
+
+echo "Updating rhn.conf..."
+sed -i -E "s/^(\s*(java\.hostname|cobbler\.host|server\.jabber_server|osa-dispatcher\.jabber_server)\s*=\s*).*$/\1${NEW_FQDN}/" /etc/rhn/rhn.conf
+
+if test -f /etc/cobbler/settings.yaml; then
+  echo "Updating cobbler..."
+  sed -i -E "s/^(server|redhat_management_server):.*$/\1: ${NEW_FQDN}/" /etc/cobbler/settings.yaml
+fi
+
+if test -n "${OLD_FQDN}" -a "${OLD_FQDN}" != "${NEW_FQDN}"; then
+  echo "Updating the database..."
+  spacewalk-sql --select-mode - <<EOT
+UPDATE rhnContentSource SET source_url = REPLACE(source_url, '://${OLD_FQDN}/', '://${NEW_FQDN}/');
+UPDATE rhnKickstartableTree SET base_path = REPLACE(base_path, '://${OLD_FQDN}/', '://${NEW_FQDN}/');
+EOT
+fi
+{{ if .GenerateCertificate }}
+echo "Generating the server certificate..."
+SERVER_DIR=$(echo "${NEW_FQDN}" | cut -d. -f1)
+rhn-ssl-tool --gen-server --dir=/root/ssl-build --set-hostname="${NEW_FQDN}" \
+{{- range .Cnames }}
+  --set-cname="{{ . }}" \
+{{- end }}
+  --set-country="{{ .Ssl.Country }}" --set-state="{{ .Ssl.State }}" --set-city="{{ .Ssl.City }}" \
+  --set-org="{{ .Ssl.Org }}" --set-org-unit="{{ .Ssl.OU }}" --set-email="{{ .Ssl.Email }}" \
+  --password="{{ .Ssl.Password }}"
+mgr-ssl-cert-setup --root-ca-file=/root/ssl-build/RHN-ORG-TRUSTED-SSL-CERT \
+  --server-cert-file="/root/ssl-build/${SERVER_DIR}/server.crt" \
+  --server-key-file="/root/ssl-build/${SERVER_DIR}/server.key"
+{{ end }}
+echo "Restarting the services..."
+spacewalk-service restart
+
+# clean before leaving
+rm $0
+echo "DONE"
+`
+
+// RenameScriptSslData holds the values to generate a server certificate.
+type RenameScriptSslData struct {
+	Country  string
+	State    string
+	City     string
+	Org      string
+	OU       string
+	Email    string
+	Password string
+}
+
+// RenameScriptTemplateData represents information used to create the FQDN change script.
+type RenameScriptTemplateData struct {
+	Fqdn                string
+	Cnames              []string
+	GenerateCertificate bool
+	Ssl                 RenameScriptSslData
+}
+
+// Render will create the FQDN change script.
+func (data RenameScriptTemplateData) Render(wr io.Writer) error {
+	t := template.Must(template.New("script").Parse(renameScriptTemplate))
+	return t.Execute(wr, data)
+}
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"io"
+	"text/template"
+)
+
+// The password is stored in a variable named password to be redacted from the logs.
+// It cannot contain shell special characters.
+const replicationPrimaryScriptTemplate = `#!/bin/bash
+set -e
+
+password={{ .Password }}
+
+echo "Configuring the replication user and WAL settings..."
+su -s /bin/bash - postgres -c "psql -v ON_ERROR_STOP=1 -v user={{ .User }} -v slot={{ .Slot }} -v pw=$password" <<'EOT'
+SELECT 'CREATE ROLE ' || :'user' || ' WITH REPLICATION LOGIN'
+    WHERE NOT EXISTS (SELECT FROM pg_roles WHERE rolname = :'user') \gexec
+ALTER ROLE :"user" WITH PASSWORD :'pw';
+ALTER SYSTEM SET wal_level = 'replica';
+ALTER SYSTEM SET max_wal_senders = 10;
+ALTER SYSTEM SET max_replication_slots = 10;
+ALTER SYSTEM SET hot_standby = 'on';
+ALTER SYSTEM SET listen_addresses = '*';
+SELECT pg_create_physical_replication_slot(:'slot')
+    WHERE NOT EXISTS (SELECT FROM pg_replication_slots WHERE slot_name = :'slot');
+EOT
+
+echo "Allowing the standby to connect..."
+hba=/var/lib/pgsql/data/pg_hba.conf
+line="host replication {{ .User }} {{ .StandbyAddress }} scram-sha-256"
+grep -qxF "$line" $hba || echo "$line" >> $hba
+
+echo "Restarting PostgreSQL to apply the WAL settings..."
+systemctl restart postgresql
+`
+
+// ReplicationPrimaryTemplateData represents the data used to prepare the server database for replication.
+type ReplicationPrimaryTemplateData struct {
+	User           string
+	Password       string
+	Slot           string
+	StandbyAddress string
+}
+
+// Render will create the script preparing the server database for replication.
+func (data ReplicationPrimaryTemplateData) Render(wr io.Writer) error {
+	t := template.Must(template.New("script").Parse(replicationPrimaryScriptTemplate))
+	return t.Execute(wr, data)
+}
+
+// The standby database is cloned from the primary one and started in a container.
+const replicationStandbyScriptTemplate = `set -e
+
+password={{ .Password }}
+
+if podman container exists {{ .Container }}; then
+    echo "{{ .Container }} container already exists on the standby host"
+    exit 1
+fi
+if podman volume exists {{ .Volume }}; then
+    echo "{{ .Volume }} volume already exists on the standby host, remove it to set up the replication again"
+    exit 1
+fi
+
+echo "Pulling {{ .Image }}..."
+podman pull {{ .Image }}
+podman volume create {{ .Volume }}
+
+echo "Cloning the primary database..."
+podman run --rm -v {{ .Volume }}:/var/lib/pgsql {{ .Image }} chown postgres:postgres /var/lib/pgsql
+podman run --rm -e PGPASSWORD="$password" -v {{ .Volume }}:/var/lib/pgsql {{ .Image }} \
+    su -s /bin/bash -m postgres -c "pg_basebackup -h {{ .Primary }} -p {{ .Port }} -U {{ .User }} -D /var/lib/pgsql/data -R -S {{ .Slot }} -X stream"
+
+echo "Starting the standby database..."
+podman run -d --name {{ .Container }} --restart always -p {{ .Port }}:5432 -v {{ .Volume }}:/var/lib/pgsql {{ .Image }} \
+    su -s /bin/bash - postgres -c "postgres -D /var/lib/pgsql/data"
+`
+
+// ReplicationStandbyTemplateData represents the data used to set up the standby database.
+type ReplicationStandbyTemplateData struct {
+	Image     string
+	Container string
+	Volume    string
+	Primary   string
+	Port      int
+	User      string
+	Password  string
+	Slot      string
+}
+
+// Render will create the script setting up the standby database.
+func (data ReplicationStandbyTemplateData) Render(wr io.Writer) error {
+	t := template.Must(template.New("script").Parse(replicationStandbyScriptTemplate))
+	return t.Execute(wr, data)
+}
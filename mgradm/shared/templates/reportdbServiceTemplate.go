@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"io"
+	"text/template"
+
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+const reportdbServiceTemplate = `# uyuni-server-reportdb.service, generated by mgradm
+# Use an uyuni-server-reportdb.service.d/local.conf file or the /etc/uyuni/templates/uyuni-server-reportdb.service.yaml file to override
+
+[Unit]
+Description=Uyuni server reporting database container service
+Wants=network.target
+After=network-online.target
+Before=uyuni-server.service
+{{- range .Override.After }}
+After={{ . }}
+{{- end }}
+{{- range .Override.Requires }}
+Requires={{ . }}
+{{- end }}
+
+[Service]
+Environment=PODMAN_SYSTEMD_UNIT=%n
+{{- range .Override.EnvironmentLines }}
+{{ . }}
+{{- end }}
+Restart=on-failure
+ExecStartPre=/bin/rm -f %t/uyuni-server-reportdb.pid %t/%n.ctr-id
+ExecStartPre=/usr/bin/podman rm --ignore --force -t 10 {{ .NamePrefix }}-server-reportdb
+ExecStart=/usr/bin/podman run \
+	--conmon-pidfile %t/uyuni-server-reportdb.pid \
+	--cidfile=%t/%n.ctr-id \
+	--cgroups=no-conmon \
+	--sdnotify=conmon \
+	-d \
+	--replace \
+	{{- if .Args }}
+	{{ .Args }} \
+	{{- end }}
+	-p {{ .Port }}:{{ .Port }} \
+	-v {{ .Volume.Name }}:{{ .Volume.MountPath }} \
+	{{- if .Override.PodmanArgs }}
+	{{ .Override.PodmanArgs }} \
+	{{- end }}
+	--name {{ .NamePrefix }}-server-reportdb \
+	--hostname {{ .NamePrefix }}-server-reportdb.mgr.internal \
+	--network {{ .Network }} \
+	${UYUNI_IMAGE} \
+	su -s /bin/bash - postgres -c "exec postgres -D {{ .Volume.MountPath }}/data -c port={{ .Port }} -c listen_addresses=*"
+
+ExecStop=/usr/bin/podman stop --ignore -t 10 --cidfile=%t/%n.ctr-id
+ExecStopPost=/usr/bin/podman rm -f --ignore -t 10 --cidfile=%t/%n.ctr-id
+PIDFile=%t/uyuni-server-reportdb.pid
+TimeoutStopSec=60
+TimeoutStartSec=60
+Type=forking
+
+[Install]
+WantedBy=multi-user.target default.target
+`
+
+// ReportDbServiceTemplateData contains the information to create the reporting database systemd file.
+type ReportDbServiceTemplateData struct {
+	NamePrefix string
+	Network    string
+	Port       int
+	Volume     types.VolumeMount
+	Args       string
+	Override   utils.ServiceOverride
+}
+
+// WithOverride returns a copy of the template data with the user override.
+func (data ReportDbServiceTemplateData) WithOverride(override utils.ServiceOverride) utils.Template {
+	data.Override = override
+	return data
+}
+
+// Render will create the systemd configuration file.
+func (data ReportDbServiceTemplateData) Render(wr io.Writer) error {
+	t := template.Must(template.New("service").Parse(reportdbServiceTemplate))
+	return t.Execute(wr, data)
+}
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"io"
+	"text/template"
+)
+
+const reportdbSetupScriptTemplate = `#!/bin/bash
+set -e
+
+PGDATA={{ .DataDir }}
+chown postgres:postgres $(dirname $PGDATA)
+{{- if .Restore }}
+
+if [ -e $PGDATA/PG_VERSION ]; then
+    old_version=$(cat $PGDATA/PG_VERSION)
+    echo "Moving the PostgreSQL $old_version data to $PGDATA-pg$old_version..."
+    rm -rf $PGDATA-pg$old_version
+    mv $PGDATA $PGDATA-pg$old_version
+fi
+{{- end }}
+
+if [ ! -e $PGDATA/PG_VERSION ]; then
+    echo "Initializing the reporting database..."
+    su -s /bin/bash - postgres -c "initdb -D $PGDATA"
+    echo "host all all all scram-sha-256" >>$PGDATA/pg_hba.conf
+fi
+
+su -s /bin/bash - postgres -c "pg_ctl -D $PGDATA -w start"
+{{- if .Restore }}
+
+echo "Restoring the reporting database..."
+su -s /bin/bash - postgres -c "psql -q -f {{ .DumpFile }} postgres"
+rm {{ .DumpFile }}
+{{- else }}
+
+echo "Creating the reporting database..."
+runuser -u postgres -- psql -v ON_ERROR_STOP=1 -v user={{ .User }} -v name={{ .Name }} \
+    -v password="$(cat {{ .PasswordFile }})" postgres <<'EOT'
+SELECT format('CREATE ROLE %I LOGIN', :'user') WHERE NOT EXISTS (SELECT FROM pg_roles WHERE rolname = :'user') \gexec
+ALTER ROLE :"user" WITH PASSWORD :'password';
+SELECT format('CREATE DATABASE %I OWNER %I', :'name', :'user') WHERE NOT EXISTS (SELECT FROM pg_database WHERE datname = :'name') \gexec
+EOT
+{{- end }}
+
+su -s /bin/bash - postgres -c "pg_ctl -D $PGDATA -w stop"
+`
+
+// ReportDbSetupTemplateData represents the information to initialize or restore the reporting database container.
+type ReportDbSetupTemplateData struct {
+	DataDir      string
+	Name         string
+	User         string
+	PasswordFile string
+	Restore      bool
+	DumpFile     string
+}
+
+// Render will create the reporting database setup script.
+func (data ReportDbSetupTemplateData) Render(wr io.Writer) error {
+	t := template.Must(template.New("script").Parse(reportdbSetupScriptTemplate))
+	return t.Execute(wr, data)
+}
@@ -9,20 +9,30 @@ import (
 	"text/template"
 
 	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 const serviceTemplate = `# uyuni-server.service, generated by mgradm
-# Use an uyuni-server.service.d/local.conf file to override
+# Use an uyuni-server.service.d/local.conf file or the /etc/uyuni/templates/uyuni-server.service.yaml file to override
 
 [Unit]
 Description=Uyuni server image container service
 Wants=network.target
 After=network-online.target
 RequiresMountsFor=%t/containers
+{{- range .Override.After }}
+After={{ . }}
+{{- end }}
+{{- range .Override.Requires }}
+Requires={{ . }}
+{{- end }}
 
 [Service]
 Environment=PODMAN_SYSTEMD_UNIT=%n
 Environment=TZ={{ .Timezone }}
+{{- range .Override.EnvironmentLines }}
+{{ . }}
+{{- end }}
 Restart=on-failure
 ExecStartPre=/bin/rm -f %t/uyuni-server.pid %t/%n.ctr-id
 ExecStartPre=/usr/bin/podman rm --ignore --force -t 10 {{ .NamePrefix }}-server
@@ -44,6 +54,10 @@ ExecStart=/usr/bin/podman run \
 	-v {{ .Name }}:{{ .MountPath }} \
 	{{- end }}
 	-e TZ=${TZ} \
+	{{- if .Override.PodmanArgs }}
+	{{ .Override.PodmanArgs }} \
+	{{- end }}
+	$PODMAN_EXTRA_ARGS \
 	--network {{ .Network }} \
 	${UYUNI_IMAGE}
 ExecStop=/usr/bin/podman exec \
@@ -75,6 +89,13 @@ type PodmanServiceTemplateData struct {
 	Timezone   string
 	Image      string
 	Network    string
+	Override   utils.ServiceOverride
+}
+
+// WithOverride returns a copy of the template data with the user override.
+func (data PodmanServiceTemplateData) WithOverride(override utils.ServiceOverride) utils.Template {
+	data.Override = override
+	return data
 }
 
 // Render will create the systemd configuration file.
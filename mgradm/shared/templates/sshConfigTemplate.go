@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"io"
+	"text/template"
+)
+
+// The options set for the source server come first as the first obtained value is used by ssh.
+const sshConfigTemplate = `# Generated by uyuni-tools for the migration
+Host {{ .SourceFqdn }}
+    User {{ .User }}
+{{- if .Port }}
+    Port {{ .Port }}
+{{- end }}
+{{- if .ProxyJump }}
+    ProxyJump {{ .ProxyJump }}
+{{- end }}
+{{- if .ControlPath }}
+    ControlMaster auto
+    ControlPath {{ .ControlPath }}
+    ControlPersist 10m
+{{- end }}
+{{- if .IncludePath }}
+
+Match all
+Include {{ .IncludePath }}
+{{- end }}
+`
+
+// SshConfigTemplateData represents the SSH client configuration used to connect to the migration source server.
+type SshConfigTemplateData struct {
+	SourceFqdn  string
+	User        string
+	Port        int
+	ProxyJump   string
+	ControlPath string
+	IncludePath string
+}
+
+// Render will create the SSH configuration file.
+func (data SshConfigTemplateData) Render(wr io.Writer) error {
+	t := template.Must(template.New("sshConfig").Parse(sshConfigTemplate))
+	return t.Execute(wr, data)
+}
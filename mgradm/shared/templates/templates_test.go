@@ -0,0 +1,226 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"bytes"
+	"path"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+var testVolumes = []types.VolumeMount{
+	{MountPath: "/var/lib/pgsql", Name: "var-pgsql"},
+	{MountPath: "/etc/rhn", Name: "etc-rhn"},
+}
+
+var testPorts = []types.PortMap{
+	utils.NewPortMap("https", 443, 443),
+	{Name: "tftp", Exposed: 69, Port: 69, Protocol: "udp"},
+}
+
+var testOverride = utils.ServiceOverride{
+	Volumes:  []utils.OverrideVolume{{Name: "/srv/mirror", Path: "/mirror"}},
+	Env:      map[string]string{"JAVA_OPTS": "-Xmx4g"},
+	After:    []string{"remote-fs.target"},
+	Requires: []string{"remote-fs.target"},
+	Args:     []string{"--label", "backup=true"},
+}
+
+var testProvisioning = ProvisioningTemplateData{
+	Config:      "c2NjOgogIHVzZXI6IGFkbWluCg==",
+	ConfigPath:  "/etc/uyuni/mgradm.yaml",
+	Repository:  "https://download.opensuse.org/repositories/systemsmanagement:/Uyuni:/Stable:/ContainerUtils/openSUSE_Leap_Micro_5.5/",
+	Packages:    []string{"podman", "mgradm"},
+	InstallArgs: []string{"/usr/bin/mgradm", "install", "podman", "--config", "/etc/uyuni/mgradm.yaml", "uyuni.example.com"},
+}
+
+// renderedTemplates lists the templates of the package with representative data, by golden file name.
+var renderedTemplates = []struct {
+	name     string
+	template utils.Template
+}{
+	{"billing-adapter.service", BillingAdapterServiceTemplateData{
+		NamePrefix: "uyuni",
+		Network:    "uyuni",
+		Volume:     utils.BillingAdapterVolumeMount,
+		HostPaths:  utils.PaygHostPaths,
+		Override:   testOverride,
+	}},
+	{"cloud-init.cfg", CloudInitTemplateData{testProvisioning}},
+	{"combustion.sh", CombustionTemplateData{testProvisioning}},
+	{"component.service", ComponentServiceTemplateData{
+		Name:        "saline",
+		Description: "Uyuni saline container service",
+		Service:     "uyuni-saline",
+		Network:     "uyuni",
+		Timezone:    "Europe/Berlin",
+		Requires:    "uyuni-server.service",
+		Env:         map[string]string{"SALINE_PORT": "8216"},
+		Ports:       []types.PortMap{utils.NewPortMap("saline", 8216, 8216)},
+		Volumes:     testVolumes,
+		Args:        "--cpus 0.5",
+		Command:     "/usr/bin/saline",
+		Override:    testOverride,
+	}},
+	{"docker.service", DockerServiceTemplateData{
+		Volumes:    testVolumes,
+		NamePrefix: "uyuni",
+		Args:       "--cap-add NET_RAW",
+		Ports:      testPorts,
+		Timezone:   "Europe/Berlin",
+		Network:    "uyuni",
+	}},
+	{"issuer.yaml", IssuerTemplateData{
+		Namespace: "uyuni",
+		Country:   "DE",
+		State:     "Bayern",
+		City:      "Nuernberg",
+		Org:       "SUSE",
+		OrgUnit:   "Uyuni",
+		Email:     "admin@example.com",
+		Fqdn:      "uyuni.example.com",
+	}},
+	{"issuer-existing-ca.yaml", IssuerTemplateData{
+		Namespace:   "uyuni",
+		Fqdn:        "uyuni.example.com",
+		RootCa:      "Q0EK",
+		Certificate: "Q0VSVAo=",
+		Key:         "S0VZCg==",
+	}},
+	{"mgr-setup.sh", MgrSetupScriptTemplateData{
+		Env:       map[string]string{"MANAGER_USER": "spacewalk", "UYUNI_FQDN": "uyuni.example.com"},
+		DebugJava: true,
+	}},
+	{"migrate.sh", MigrateScriptTemplateData{
+		Volumes:    testVolumes,
+		SourceFqdn: "source.example.com",
+		User:       "root",
+		Prepare:    true,
+	}},
+	{"migrate-kubernetes.sh", MigrateScriptTemplateData{
+		Volumes:    testVolumes,
+		SourceFqdn: "source.example.com",
+		User:       "root",
+		Kubernetes: true,
+		Final:      true,
+	}},
+	{"pgsql-finalize.sh", FinalizePostgresTemplateData{
+		RunAutotune:        true,
+		RunReindex:         true,
+		RunSchemaUpdate:    true,
+		RunDistroMigration: true,
+		RunTuning:          true,
+		RunAnalyze:         true,
+	}},
+	{"pgsql-version-upgrade.sh", PostgreSQLVersionUpgradeTemplateData{
+		OldVersion: "14",
+		NewVersion: "16",
+		Link:       true,
+		Jobs:       4,
+	}},
+	{"post-upgrade.sh", PostUpgradeTemplateData{CobblerHost: "uyuni.example.com"}},
+	{"rename.sh", RenameScriptTemplateData{
+		Fqdn:                "new.example.com",
+		Cnames:              []string{"alias.example.com"},
+		GenerateCertificate: true,
+		Ssl: RenameScriptSslData{
+			Country:  "DE",
+			State:    "Bayern",
+			City:     "Nuernberg",
+			Org:      "SUSE",
+			OU:       "Uyuni",
+			Email:    "admin@example.com",
+			Password: "secret",
+		},
+	}},
+	{"replication-primary.sh", ReplicationPrimaryTemplateData{
+		User:           "replicator",
+		Password:       "secret",
+		Slot:           "standby1",
+		StandbyAddress: "192.168.1.10/32",
+	}},
+	{"replication-standby.sh", ReplicationStandbyTemplateData{
+		Image:     "registry.opensuse.org/uyuni/server:2024.07",
+		Container: "uyuni-db-standby",
+		Volume:    "var-pgsql-standby",
+		Primary:   "uyuni.example.com",
+		Port:      5432,
+		User:      "replicator",
+		Password:  "secret",
+		Slot:      "standby1",
+	}},
+	{"reportdb.service", ReportDbServiceTemplateData{
+		NamePrefix: "uyuni",
+		Network:    "uyuni",
+		Port:       5432,
+		Volume:     types.VolumeMount{MountPath: "/var/lib/pgsql", Name: "var-pgsql-reportdb"},
+		Args:       "--memory 4g",
+		Override:   testOverride,
+	}},
+	{"reportdb-setup.sh", ReportDbSetupTemplateData{
+		DataDir:      "/var/lib/pgsql/data",
+		Name:         "reportdb",
+		User:         "pythia_susemanager",
+		PasswordFile: "/run/secrets/reportdb-password",
+		Restore:      true,
+		DumpFile:     "/var/lib/pgsql/reportdb.dump",
+	}},
+	{"server.service", PodmanServiceTemplateData{
+		Volumes:    testVolumes,
+		NamePrefix: "uyuni",
+		Args:       "--cap-add NET_RAW",
+		Ports:      testPorts,
+		Timezone:   "Europe/Berlin",
+		Image:      "registry.opensuse.org/uyuni/server:2024.07",
+		Network:    "uyuni",
+		Override:   testOverride,
+	}},
+	{"ssh-config", SshConfigTemplateData{
+		SourceFqdn:  "source.example.com",
+		User:        "root",
+		Port:        2222,
+		ProxyJump:   "bastion.example.com",
+		ControlPath: "/tmp/mgradm-ssh-%C",
+		IncludePath: "/root/.ssh/config",
+	}},
+	{"tls-secret.yaml", TlsSecretTemplateData{
+		Name:        "uyuni-cert",
+		Namespace:   "uyuni",
+		RootCa:      "Q0EK",
+		Certificate: "Q0VSVAo=",
+		Key:         "S0VZCg==",
+	}},
+}
+
+func TestTemplates(t *testing.T) {
+	for _, rendered := range renderedTemplates {
+		t.Run(rendered.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := rendered.template.Render(&buf); err != nil {
+				t.Fatalf("failed to render: %s", err)
+			}
+			testutils.AssertGolden(t, rendered.name, buf.Bytes())
+			validateRendered(t, rendered.name, buf.Bytes())
+		})
+	}
+}
+
+// validateRendered checks the rendered content according to its type.
+func validateRendered(t *testing.T, name string, content []byte) {
+	switch path.Ext(name) {
+	case ".service":
+		testutils.ValidateSystemdUnit(t, "uyuni-"+name, content)
+	case ".sh":
+		testutils.ValidateShellScript(t, content)
+	case ".yaml":
+		testutils.ValidateKubernetesYAML(t, content)
+	case ".cfg":
+		testutils.ValidateCloudConfig(t, content)
+	}
+}
@@ -0,0 +1,28 @@
+#!/bin/bash
+# combustion: network
+# Generated by mgradm: installs an Uyuni server on podman on the first boot
+set -e
+zypper --non-interactive addrepo --refresh 'https://download.opensuse.org/repositories/systemsmanagement:/Uyuni:/Stable:/ContainerUtils/openSUSE_Leap_Micro_5.5/' uyuni-container-utils
+zypper --non-interactive --gpg-auto-import-keys refresh
+zypper --non-interactive install 'podman' 'mgradm'
+
+mkdir -p '/etc/uyuni'
+echo 'c2NjOgogIHVzZXI6IGFkbWluCg==' | base64 -d > '/etc/uyuni/mgradm.yaml'
+chmod 600 '/etc/uyuni/mgradm.yaml'
+
+# Combustion runs before the system is booted: install the server once booted
+cat > /etc/systemd/system/uyuni-first-boot-install.service <<'UNIT'
+[Unit]
+Description=Install the Uyuni server on the first boot
+Wants=network-online.target
+After=network-online.target
+ConditionPathExists=!/etc/systemd/system/uyuni-server.service
+
+[Service]
+Type=oneshot
+ExecStart=/usr/bin/mgradm "install" "podman" "--config" "/etc/uyuni/mgradm.yaml" "uyuni.example.com"
+
+[Install]
+WantedBy=multi-user.target
+UNIT
+systemctl enable uyuni-first-boot-install.service
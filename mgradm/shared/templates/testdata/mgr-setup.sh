@@ -0,0 +1,11 @@
+#!/bin/sh
+export MANAGER_USER=spacewalk
+export UYUNI_FQDN=uyuni.example.com
+echo 'JAVA_OPTS=" $JAVA_OPTS -Xdebug -Xrunjdwp:transport=dt_socket,address=*:8003,server=y,suspend=n" ' >> /etc/tomcat/conf.d/remote_debug.conf
+echo 'JAVA_OPTS=" $JAVA_OPTS -Xdebug -Xrunjdwp:transport=dt_socket,address=*:8001,server=y,suspend=n" ' >> /etc/rhn/taskomatic.conf
+echo 'JAVA_OPTS=" $JAVA_OPTS -Xdebug -Xrunjdwp:transport=dt_socket,address=*:8002,server=y,suspend=n" ' >> /usr/share/rhn/config-defaults/rhn_search_daemon.conf
+
+/usr/lib/susemanager/bin/mgr-setup -s -n
+
+# clean before leaving
+rm $0
\ No newline at end of file
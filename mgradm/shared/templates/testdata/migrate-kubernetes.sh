@@ -0,0 +1,142 @@
+#!/bin/bash
+set -e
+SSH="ssh -A -F /var/lib/uyuni-tools/ssh_config "
+
+# Files collecting the data for the migration report
+rm -f /var/lib/uyuni-tools/sizes /var/lib/uyuni-tools/skipped /var/lib/uyuni-tools/warnings
+warn() {
+  echo "WARNING: $*" >&2
+  echo "$*" >> /var/lib/uyuni-tools/warnings
+}
+
+
+echo "Stopping spacewalk service..."
+$SSH source.example.com "sudo spacewalk-service stop ; sudo systemctl start postgresql.service"
+
+
+$SSH source.example.com \
+ "echo \"COPY (SELECT MIN(CONCAT(org_id, '-', label)) AS target, base_path FROM rhnKickstartableTree GROUP BY base_path) TO STDOUT WITH CSV;\" \
+ |sudo spacewalk-sql --select-mode - " > distros
+
+
+echo "Stopping posgresql service..."
+$SSH source.example.com "sudo systemctl stop postgresql.service"
+
+
+
+echo "Disabling the services on the source server..."
+$SSH source.example.com "sudo spacewalk-service disable ; sudo systemctl disable postgresql.service"
+
+
+while IFS="," read -r target path ; do
+    echo "-/ $path"
+done < distros > exclude_list
+
+# exclude all config files which already exist and are not marked noreplace
+rpm -qa --qf '[%{fileflags},%{filenames}\n]' |grep ",/etc/" | while IFS="," read -r flags path ; do
+    # config(noreplace) is 1<<4 (from lib/rpmlib.h)
+    if [ $(( $flags & 16 )) -eq 0 -a -f "$path" ] ; then
+        echo "-/ $path" >> exclude_list
+    fi
+done
+
+# exclude schema migration files
+echo "-/ /etc/sysconfig/rhn/reportdb-schema-upgrade" >> exclude_list
+echo "-/ /etc/sysconfig/rhn/schema-upgrade" >> exclude_list
+
+
+for folder in /var/lib/pgsql /etc/rhn ;
+do
+  if $SSH source.example.com test -e $folder; then
+    echo "Copying $folder..."
+    rsync -e "$SSH" --rsync-path='sudo rsync' -avz -f "merge exclude_list" source.example.com:$folder/ $folder;
+    echo "$folder $(du -sb $folder | cut -f1)" >> /var/lib/uyuni-tools/sizes
+  else
+    echo "Skipping missing $folder..."
+    echo "$folder" >> /var/lib/uyuni-tools/skipped
+  fi
+done;
+
+sed -i -e 's|appBase="webapps"|appBase="/usr/share/susemanager/www/tomcat/webapps"|' /etc/tomcat/server.xml
+sed -i -e 's|DocumentRoot\s*"/srv/www/htdocs"|DocumentRoot "/usr/share/susemanager/www/htdocs"|' /etc/apache2/vhosts.d/vhost-ssl.conf
+
+echo "Migrating auto-installable distributions..."
+
+while IFS="," read -r target path ; do
+  if $SSH -n source.example.com test -e $path ; then
+    echo "Copying distribution $target from $path"
+    mkdir -p "/srv/www/distributions/$target"
+    rsync -e "$SSH" --rsync-path='sudo rsync' -avz "source.example.com:$path/" "/srv/www/distributions/$target"
+    echo "/srv/www/distributions/$target $(du -sb /srv/www/distributions/$target | cut -f1)" >> /var/lib/uyuni-tools/sizes
+  else
+    echo "$path" >> /var/lib/uyuni-tools/skipped
+    warn "Skipping missing distribution $target in $path"
+  fi
+done < distros
+
+
+
+rm -f /srv/www/htdocs/pub/RHN-ORG-TRUSTED-SSL-CERT;
+ln -s /etc/pki/trust/anchors/LOCAL-RHN-ORG-TRUSTED-SSL-CERT /srv/www/htdocs/pub/RHN-ORG-TRUSTED-SSL-CERT;
+
+echo "Extracting time zone..."
+$SSH source.example.com timedatectl show -p Timezone >/var/lib/uyuni-tools/data
+
+echo "Extracting postgresql versions..."
+echo "new_pg_version=$(rpm -qa --qf '%{VERSION}\n' 'name=postgresql[0-8][0-9]-server'  | cut -d. -f1 | sort -n | tail -1)" >> /var/lib/uyuni-tools/data
+echo "old_pg_version=$(cat /var/lib/pgsql/data/PG_VERSION)" >> /var/lib/uyuni-tools/data
+
+echo "Altering configuration for domain resolution..."
+sed 's/report_db_host = source.example.com/report_db_host = localhost/' -i /etc/rhn/rhn.conf;
+sed 's/server\.jabber_server/java\.hostname/' -i /etc/rhn/rhn.conf;
+sed 's/client_use_localhost: false/client_use_localhost: true/' -i /etc/cobbler/settings.yaml;
+
+echo "Altering configuration for container environment..."
+sed 's/address=[^:]*:/address=*:/' -i /etc/rhn/taskomatic.conf;
+
+if test ! -f /etc/tomcat/conf.d/remote_debug.conf -a -f /etc/sysconfig/tomcat; then
+  mv /etc/sysconfig/tomcat /etc/tomcat/conf.d/remote_debug.conf
+fi
+
+sed 's/address=[^:]*:/address=*:/' -i /etc/tomcat/conf.d/remote_debug.conf
+
+
+echo 'server.no_ssl = 1' >> /etc/rhn/rhn.conf;
+echo "Extracting SSL certificate and authority"
+extractedSSL=
+if test -d /root/ssl-build; then
+  # We may have an old unused ssl-build folder, check if the CA matches the deployed one
+  buildCaFingerprint=
+  if test -e /root/ssl-build/RHN-ORG-TRUSTED-SSL-CERT; then
+    buildCaFingerprint=$(openssl x509 -in /root/ssl-build/RHN-ORG-TRUSTED-SSL-CERT -noout -fingerprint)
+  fi
+  caFingerprint=$(openssl x509 -in /etc/pki/trust/anchors/LOCAL-RHN-ORG-TRUSTED-SSL-CERT -noout -fingerprint)
+
+  if test "$buildCaFingerprint" == "$caFingerprint"; then
+    echo "Extracting SSL Root CA key..."
+    # Extract the SSL CA certificate and key.
+    # The server certificate will be auto-generated by cert-manager using it, so no need to copy it.
+    cp /root/ssl-build/RHN-ORG-PRIVATE-SSL-KEY /var/lib/uyuni-tools/
+
+    extractedSSL="1"
+  fi
+fi
+
+# This Root CA file is common to both cases
+cp /etc/pki/trust/anchors/LOCAL-RHN-ORG-TRUSTED-SSL-CERT /var/lib/uyuni-tools/RHN-ORG-TRUSTED-SSL-CERT
+
+if test "extractedSSL" != "1"; then
+  # For third party certificates, the CA chain is in the certificate file.
+  rsync -e "$SSH" --rsync-path='sudo rsync' -avz source.example.com:/etc/pki/tls/private/spacewalk.key /var/lib/uyuni-tools/
+  rsync -e "$SSH" --rsync-path='sudo rsync' -avz source.example.com:/etc/pki/tls/certs/spacewalk.crt /var/lib/uyuni-tools/
+
+fi
+
+echo "Removing useless ssl-build folder..."
+rm -rf /root/ssl-build
+
+# The content of this folder will be a RO mount from a configmap
+rm /etc/pki/trust/anchors/*
+
+
+echo "DONE"
\ No newline at end of file
@@ -0,0 +1,102 @@
+#!/bin/bash
+set -e
+SSH="ssh -A -F /var/lib/uyuni-tools/ssh_config "
+
+# Files collecting the data for the migration report
+rm -f /var/lib/uyuni-tools/sizes /var/lib/uyuni-tools/skipped /var/lib/uyuni-tools/warnings
+warn() {
+  echo "WARNING: $*" >&2
+  echo "$*" >> /var/lib/uyuni-tools/warnings
+}
+
+
+echo "Pre-synchronizing the data while the source server is running..."
+
+
+$SSH source.example.com \
+ "echo \"COPY (SELECT MIN(CONCAT(org_id, '-', label)) AS target, base_path FROM rhnKickstartableTree GROUP BY base_path) TO STDOUT WITH CSV;\" \
+ |sudo spacewalk-sql --select-mode - " > distros
+
+
+
+
+
+while IFS="," read -r target path ; do
+    echo "-/ $path"
+done < distros > exclude_list
+
+# exclude all config files which already exist and are not marked noreplace
+rpm -qa --qf '[%{fileflags},%{filenames}\n]' |grep ",/etc/" | while IFS="," read -r flags path ; do
+    # config(noreplace) is 1<<4 (from lib/rpmlib.h)
+    if [ $(( $flags & 16 )) -eq 0 -a -f "$path" ] ; then
+        echo "-/ $path" >> exclude_list
+    fi
+done
+
+# exclude schema migration files
+echo "-/ /etc/sysconfig/rhn/reportdb-schema-upgrade" >> exclude_list
+echo "-/ /etc/sysconfig/rhn/schema-upgrade" >> exclude_list
+
+
+for folder in /var/lib/pgsql /etc/rhn ;
+do
+  if $SSH source.example.com test -e $folder; then
+    echo "Copying $folder..."
+    rsync -e "$SSH" --rsync-path='sudo rsync' -avz -f "merge exclude_list" source.example.com:$folder/ $folder;
+    echo "$folder $(du -sb $folder | cut -f1)" >> /var/lib/uyuni-tools/sizes
+  else
+    echo "Skipping missing $folder..."
+    echo "$folder" >> /var/lib/uyuni-tools/skipped
+  fi
+done;
+
+sed -i -e 's|appBase="webapps"|appBase="/usr/share/susemanager/www/tomcat/webapps"|' /etc/tomcat/server.xml
+sed -i -e 's|DocumentRoot\s*"/srv/www/htdocs"|DocumentRoot "/usr/share/susemanager/www/htdocs"|' /etc/apache2/vhosts.d/vhost-ssl.conf
+
+echo "Migrating auto-installable distributions..."
+
+while IFS="," read -r target path ; do
+  if $SSH -n source.example.com test -e $path ; then
+    echo "Copying distribution $target from $path"
+    mkdir -p "/srv/www/distributions/$target"
+    rsync -e "$SSH" --rsync-path='sudo rsync' -avz "source.example.com:$path/" "/srv/www/distributions/$target"
+    echo "/srv/www/distributions/$target $(du -sb /srv/www/distributions/$target | cut -f1)" >> /var/lib/uyuni-tools/sizes
+  else
+    echo "$path" >> /var/lib/uyuni-tools/skipped
+    warn "Skipping missing distribution $target in $path"
+  fi
+done < distros
+
+
+echo "Data pre-synchronized, the source server is still running"
+echo "DONE"
+exit 0
+
+
+rm -f /srv/www/htdocs/pub/RHN-ORG-TRUSTED-SSL-CERT;
+ln -s /etc/pki/trust/anchors/LOCAL-RHN-ORG-TRUSTED-SSL-CERT /srv/www/htdocs/pub/RHN-ORG-TRUSTED-SSL-CERT;
+
+echo "Extracting time zone..."
+$SSH source.example.com timedatectl show -p Timezone >/var/lib/uyuni-tools/data
+
+echo "Extracting postgresql versions..."
+echo "new_pg_version=$(rpm -qa --qf '%{VERSION}\n' 'name=postgresql[0-8][0-9]-server'  | cut -d. -f1 | sort -n | tail -1)" >> /var/lib/uyuni-tools/data
+echo "old_pg_version=$(cat /var/lib/pgsql/data/PG_VERSION)" >> /var/lib/uyuni-tools/data
+
+echo "Altering configuration for domain resolution..."
+sed 's/report_db_host = source.example.com/report_db_host = localhost/' -i /etc/rhn/rhn.conf;
+sed 's/server\.jabber_server/java\.hostname/' -i /etc/rhn/rhn.conf;
+sed 's/client_use_localhost: false/client_use_localhost: true/' -i /etc/cobbler/settings.yaml;
+
+echo "Altering configuration for container environment..."
+sed 's/address=[^:]*:/address=*:/' -i /etc/rhn/taskomatic.conf;
+
+if test ! -f /etc/tomcat/conf.d/remote_debug.conf -a -f /etc/sysconfig/tomcat; then
+  mv /etc/sysconfig/tomcat /etc/tomcat/conf.d/remote_debug.conf
+fi
+
+sed 's/address=[^:]*:/address=*:/' -i /etc/tomcat/conf.d/remote_debug.conf
+
+
+
+echo "DONE"
\ No newline at end of file
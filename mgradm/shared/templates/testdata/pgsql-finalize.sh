@@ -0,0 +1,57 @@
+#!/bin/bash
+set -e
+
+
+echo "Running smdba system-check autotuning..."
+smdba system-check autotuning
+
+echo "Starting Postgresql..."
+su -s /bin/bash - postgres -c "/usr/share/postgresql/postgresql-script start"
+
+echo "Tuning PostgreSQL memory settings..."
+mem_mb=$(( $(sed -n 's/^MemTotal:\s*\([0-9]*\) kB$/\1/p' /proc/meminfo) / 1024 ))
+shared_buffers=$(( mem_mb / 4 ))
+effective_cache_size=$(( mem_mb * 3 / 4 ))
+echo "Setting shared_buffers to ${shared_buffers}MB and effective_cache_size to ${effective_cache_size}MB"
+su -s /bin/bash - postgres -c "psql -c \"ALTER SYSTEM SET shared_buffers = '${shared_buffers}MB'\""
+su -s /bin/bash - postgres -c "psql -c \"ALTER SYSTEM SET effective_cache_size = '${effective_cache_size}MB'\""
+
+
+echo "Reindexing database. This may take a while, please do not cancel it!"
+database=$(sed -n "s/^\s*db_name\s*=\s*\([^ ]*\)\s*$/\1/p" /etc/rhn/rhn.conf)
+spacewalk-sql --select-mode - <<<"REINDEX DATABASE \"${database}\";"
+
+
+
+echo "Schema update..."
+/usr/sbin/spacewalk-startup-helper check-database
+
+
+
+echo "Updating auto-installable distributions..."
+spacewalk-sql --select-mode - <<EOT
+SELECT MIN(CONCAT(org_id, '-', label)) AS target, base_path INTO TEMP TABLE dist_map FROM rhnKickstartableTree GROUP BY base_path;
+UPDATE rhnKickstartableTree SET base_path = CONCAT('/srv/www/distributions/', target)
+    from dist_map WHERE dist_map.base_path = rhnKickstartableTree.base_path;
+DROP TABLE dist_map;
+EOT
+
+
+
+echo "Refreshing the database statistics. This may take a while..."
+su -s /bin/bash - postgres -c "vacuumdb --all --analyze-in-stages"
+
+
+echo "Schedule a system list update task..."
+spacewalk-sql --select-mode - <<EOT
+insert into rhnTaskQueue (id, org_id, task_name, task_data)
+SELECT nextval('rhn_task_queue_id_seq'), 1, 'update_system_overview', s.id
+from rhnserver s
+where not exists (select 1 from rhntaskorun r join rhntaskotemplate t on r.template_id = t.id
+join rhntaskobunch b on t.bunch_id = b.id where b.name='update-system-overview-bunch' limit 1);
+EOT
+
+
+echo "Stopping Postgresql..."
+su -s /bin/bash - postgres -c "/usr/share/postgresql/postgresql-script stop"
+echo "DONE"
@@ -0,0 +1,40 @@
+#!/bin/bash
+set -e
+
+NEW_FQDN="new.example.com"
+OLD_FQDN=$(sed -n 's/^\s*java\.hostname\s*=\s*\([^ ]*\)\s*$/\1/p' /etc/rhn/rhn.conf)
+echo "Renaming the server from ${OLD_FQDN} to ${NEW_FQDN}..."
+
+echo "Updating rhn.conf..."
+sed -i -E "s/^(\s*(java\.hostname|cobbler\.host|server\.jabber_server|osa-dispatcher\.jabber_server)\s*=\s*).*$/\1${NEW_FQDN}/" /etc/rhn/rhn.conf
+
+if test -f /etc/cobbler/settings.yaml; then
+  echo "Updating cobbler..."
+  sed -i -E "s/^(server|redhat_management_server):.*$/\1: ${NEW_FQDN}/" /etc/cobbler/settings.yaml
+fi
+
+if test -n "${OLD_FQDN}" -a "${OLD_FQDN}" != "${NEW_FQDN}"; then
+  echo "Updating the database..."
+  spacewalk-sql --select-mode - <<EOT
+UPDATE rhnContentSource SET source_url = REPLACE(source_url, '://${OLD_FQDN}/', '://${NEW_FQDN}/');
+UPDATE rhnKickstartableTree SET base_path = REPLACE(base_path, '://${OLD_FQDN}/', '://${NEW_FQDN}/');
+EOT
+fi
+
+echo "Generating the server certificate..."
+SERVER_DIR=$(echo "${NEW_FQDN}" | cut -d. -f1)
+rhn-ssl-tool --gen-server --dir=/root/ssl-build --set-hostname="${NEW_FQDN}" \
+  --set-cname="alias.example.com" \
+  --set-country="DE" --set-state="Bayern" --set-city="Nuernberg" \
+  --set-org="SUSE" --set-org-unit="Uyuni" --set-email="admin@example.com" \
+  --password="secret"
+mgr-ssl-cert-setup --root-ca-file=/root/ssl-build/RHN-ORG-TRUSTED-SSL-CERT \
+  --server-cert-file="/root/ssl-build/${SERVER_DIR}/server.crt" \
+  --server-key-file="/root/ssl-build/${SERVER_DIR}/server.key"
+
+echo "Restarting the services..."
+spacewalk-service restart
+
+# clean before leaving
+rm $0
+echo "DONE"
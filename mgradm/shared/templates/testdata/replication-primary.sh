@@ -0,0 +1,26 @@
+#!/bin/bash
+set -e
+
+password=secret
+
+echo "Configuring the replication user and WAL settings..."
+su -s /bin/bash - postgres -c "psql -v ON_ERROR_STOP=1 -v user=replicator -v slot=standby1 -v pw=$password" <<'EOT'
+SELECT 'CREATE ROLE ' || :'user' || ' WITH REPLICATION LOGIN'
+    WHERE NOT EXISTS (SELECT FROM pg_roles WHERE rolname = :'user') \gexec
+ALTER ROLE :"user" WITH PASSWORD :'pw';
+ALTER SYSTEM SET wal_level = 'replica';
+ALTER SYSTEM SET max_wal_senders = 10;
+ALTER SYSTEM SET max_replication_slots = 10;
+ALTER SYSTEM SET hot_standby = 'on';
+ALTER SYSTEM SET listen_addresses = '*';
+SELECT pg_create_physical_replication_slot(:'slot')
+    WHERE NOT EXISTS (SELECT FROM pg_replication_slots WHERE slot_name = :'slot');
+EOT
+
+echo "Allowing the standby to connect..."
+hba=/var/lib/pgsql/data/pg_hba.conf
+line="host replication replicator 192.168.1.10/32 scram-sha-256"
+grep -qxF "$line" $hba || echo "$line" >> $hba
+
+echo "Restarting PostgreSQL to apply the WAL settings..."
+systemctl restart postgresql
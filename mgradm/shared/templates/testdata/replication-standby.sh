@@ -0,0 +1,25 @@
+set -e
+
+password=secret
+
+if podman container exists uyuni-db-standby; then
+    echo "uyuni-db-standby container already exists on the standby host"
+    exit 1
+fi
+if podman volume exists var-pgsql-standby; then
+    echo "var-pgsql-standby volume already exists on the standby host, remove it to set up the replication again"
+    exit 1
+fi
+
+echo "Pulling registry.opensuse.org/uyuni/server:2024.07..."
+podman pull registry.opensuse.org/uyuni/server:2024.07
+podman volume create var-pgsql-standby
+
+echo "Cloning the primary database..."
+podman run --rm -v var-pgsql-standby:/var/lib/pgsql registry.opensuse.org/uyuni/server:2024.07 chown postgres:postgres /var/lib/pgsql
+podman run --rm -e PGPASSWORD="$password" -v var-pgsql-standby:/var/lib/pgsql registry.opensuse.org/uyuni/server:2024.07 \
+    su -s /bin/bash -m postgres -c "pg_basebackup -h uyuni.example.com -p 5432 -U replicator -D /var/lib/pgsql/data -R -S standby1 -X stream"
+
+echo "Starting the standby database..."
+podman run -d --name uyuni-db-standby --restart always -p 5432:5432 -v var-pgsql-standby:/var/lib/pgsql registry.opensuse.org/uyuni/server:2024.07 \
+    su -s /bin/bash - postgres -c "postgres -D /var/lib/pgsql/data"
@@ -0,0 +1,26 @@
+#!/bin/bash
+set -e
+
+PGDATA=/var/lib/pgsql/data
+chown postgres:postgres $(dirname $PGDATA)
+
+if [ -e $PGDATA/PG_VERSION ]; then
+    old_version=$(cat $PGDATA/PG_VERSION)
+    echo "Moving the PostgreSQL $old_version data to $PGDATA-pg$old_version..."
+    rm -rf $PGDATA-pg$old_version
+    mv $PGDATA $PGDATA-pg$old_version
+fi
+
+if [ ! -e $PGDATA/PG_VERSION ]; then
+    echo "Initializing the reporting database..."
+    su -s /bin/bash - postgres -c "initdb -D $PGDATA"
+    echo "host all all all scram-sha-256" >>$PGDATA/pg_hba.conf
+fi
+
+su -s /bin/bash - postgres -c "pg_ctl -D $PGDATA -w start"
+
+echo "Restoring the reporting database..."
+su -s /bin/bash - postgres -c "psql -q -f /var/lib/pgsql/reportdb.dump postgres"
+rm /var/lib/pgsql/reportdb.dump
+
+su -s /bin/bash - postgres -c "pg_ctl -D $PGDATA -w stop"
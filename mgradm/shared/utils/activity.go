@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// activityQueries count the operations interrupted if the server is stopped, by description.
+var activityQueries = []struct {
+	description string
+	query       string
+}{
+	{
+		"running repository synchronizations",
+		`SELECT COUNT(*) FROM rhnTaskoRun r
+JOIN rhnTaskoTemplate t ON r.template_id = t.id
+JOIN rhnTaskoBunch b ON t.bunch_id = b.id
+WHERE b.name = 'repo-sync-bunch' AND r.status = 'RUNNING';`,
+	},
+	// Status 1 is Picked Up: the systems are running the actions
+	{"actions in progress on the systems", "SELECT COUNT(*) FROM rhnServerAction WHERE status = 1;"},
+}
+
+// ServerActivity returns the operations running on the server which would be interrupted by stopping it.
+func ServerActivity(cnx *shared.Connection) ([]string, error) {
+	activity := []string{}
+	for _, check := range activityQueries {
+		rows, err := RunQuery(cnx, check.query)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 1 && len(rows[0]) == 1 && rows[0][0] != "0" {
+			activity = append(activity, fmt.Sprintf("%s %s", rows[0][0], L(check.description)))
+		}
+	}
+	return activity, nil
+}
+
+// CheckServerActivity fails if operations are running on the server unless forced.
+//
+// The check is skipped if the server cannot be queried, for instance when it is not running.
+func CheckServerActivity(cnx *shared.Connection, force bool) error {
+	activity, err := ServerActivity(cnx)
+	if err != nil {
+		log.Debug().Err(err).Msg("Skipping the server activity check")
+		return nil
+	}
+	if len(activity) == 0 {
+		return nil
+	}
+	if force {
+		log.Warn().Msgf(L("Interrupting the operations running on the server: %s"), strings.Join(activity, ", "))
+		return nil
+	}
+	return fmt.Errorf(L("operations are running on the server: %s. Wait for them to finish or use --force"),
+		strings.Join(activity, ", "))
+}
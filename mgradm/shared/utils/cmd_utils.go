@@ -5,8 +5,15 @@
 package utils
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -18,12 +25,366 @@ import (
 
 var defaultImage = path.Join(utils.DefaultNamespace, "server")
 
+// CocoImageSuffix is appended to the server image name to get the default attestation image.
+const CocoImageSuffix = "-attestation"
+
+// HubXmlrpcImageSuffix is appended to the server image name to get the default hub XML-RPC API image.
+const HubXmlrpcImageSuffix = "-hub-xmlrpc-api"
+
 // HelmFrags stores Uyuni and Cert Manager Helm information.
 type HelmFlags struct {
 	Uyuni       types.ChartFlags
 	CertManager types.ChartFlags
 }
 
+// KubernetesFlags stores the customization of the server deployment on kubernetes.
+type KubernetesFlags struct {
+	Ingress    IngressFlags
+	Expose     ExposeFlags
+	Resources  ResourcesFlags
+	Scheduling SchedulingFlags
+	Dedicated  struct {
+		Node string
+	}
+}
+
+// HelmArgs returns the helm parameters for the kubernetes customization flags.
+func (f *KubernetesFlags) HelmArgs(ports []types.PortMap) ([]string, error) {
+	args, err := f.Ingress.HelmArgs()
+	if err != nil {
+		return nil, err
+	}
+	exposeArgs, err := f.Expose.HelmArgs(ports)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, exposeArgs...)
+	resourcesArgs, err := f.Resources.HelmArgs()
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, resourcesArgs...)
+	schedulingArgs, err := f.Scheduling.HelmArgs(f.Dedicated.Node)
+	if err != nil {
+		return nil, err
+	}
+	return append(args, schedulingArgs...), nil
+}
+
+// SchedulingFlags stores where the server pod can be scheduled.
+type SchedulingFlags struct {
+	NodeSelector []string
+	Tolerations  []string `mapstructure:"toleration"`
+	Affinity     string
+}
+
+// dedicatedTaint is the taint tolerated by the server pod on a dedicated node.
+const dedicatedTaint = "dedicated=uyuni:NoSchedule"
+
+type toleration struct {
+	Key      string `json:"key"`
+	Operator string `json:"operator"`
+	Value    string `json:"value,omitempty"`
+	Effect   string `json:"effect,omitempty"`
+}
+
+// parseToleration converts a key[=value][:effect] string into a toleration.
+func parseToleration(value string) (toleration, error) {
+	result := toleration{Operator: "Exists"}
+	keyValue := value
+	if index := strings.LastIndex(value, ":"); index >= 0 {
+		keyValue = value[:index]
+		result.Effect = value[index+1:]
+		if !utils.Contains([]string{"NoSchedule", "PreferNoSchedule", "NoExecute"}, result.Effect) {
+			return result, fmt.Errorf(L("invalid toleration %s: the effect can be NoSchedule, PreferNoSchedule or NoExecute"), value)
+		}
+	}
+	parts := strings.SplitN(keyValue, "=", 2)
+	result.Key = parts[0]
+	if len(parts) == 2 {
+		result.Operator = "Equal"
+		result.Value = parts[1]
+	}
+	if result.Key == "" {
+		return result, fmt.Errorf(L("invalid toleration %s: expected key[=value][:effect]"), value)
+	}
+	return result, nil
+}
+
+// HelmArgs returns the helm parameters to set the node selector, tolerations and affinity.
+//
+// If dedicatedNode is not empty, the pod is bound to this node and tolerates the dedicated=uyuni:NoSchedule taint.
+func (f *SchedulingFlags) HelmArgs(dedicatedNode string) ([]string, error) {
+	nodeSelector := map[string]string{}
+	for _, selector := range f.NodeSelector {
+		parts := strings.SplitN(selector, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf(L("invalid node selector %s: expected key=value"), selector)
+		}
+		nodeSelector[parts[0]] = parts[1]
+	}
+
+	tolerationValues := f.Tolerations
+	var affinity interface{}
+	if f.Affinity != "" {
+		if err := json.Unmarshal([]byte(f.Affinity), &affinity); err != nil {
+			return nil, utils.Errorf(err, L("invalid affinity JSON definition: %s"))
+		}
+	}
+
+	if dedicatedNode != "" {
+		if affinity != nil {
+			return nil, errors.New(L("the dedicated node and affinity flags are mutually exclusive"))
+		}
+		nodeSelector["kubernetes.io/hostname"] = dedicatedNode
+		tolerationValues = append(tolerationValues, dedicatedTaint)
+		affinity = map[string]interface{}{
+			"nodeAffinity": map[string]interface{}{
+				"requiredDuringSchedulingIgnoredDuringExecution": map[string]interface{}{
+					"nodeSelectorTerms": []interface{}{
+						map[string]interface{}{
+							"matchExpressions": []interface{}{
+								map[string]interface{}{
+									"key":      "kubernetes.io/hostname",
+									"operator": "In",
+									"values":   []string{dedicatedNode},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tolerations := []toleration{}
+	for _, value := range tolerationValues {
+		parsed, err := parseToleration(value)
+		if err != nil {
+			return nil, err
+		}
+		tolerations = append(tolerations, parsed)
+	}
+
+	args := []string{}
+	values := []struct {
+		key   string
+		value interface{}
+		set   bool
+	}{
+		{"nodeSelector", nodeSelector, len(nodeSelector) > 0},
+		{"tolerations", tolerations, len(tolerations) > 0},
+		{"affinity", affinity, affinity != nil},
+	}
+	for _, value := range values {
+		if !value.set {
+			continue
+		}
+		encoded, err := json.Marshal(value.value)
+		if err != nil {
+			return nil, utils.Errorf(err, L("failed to encode the %[1]s value: %[2]s"), value.key)
+		}
+		args = append(args, "--set-json", value.key+"="+string(encoded))
+	}
+	return args, nil
+}
+
+// ResourcesFlags stores the server pod resources and priority class.
+type ResourcesFlags struct {
+	Requests struct {
+		CPU    string `mapstructure:"cpu"`
+		Memory string
+	}
+	Limits struct {
+		CPU    string `mapstructure:"cpu"`
+		Memory string
+	}
+	Priority struct {
+		Class string
+	}
+}
+
+// quantityRegex matches the kubernetes resource quantities like 500m, 2 or 4Gi.
+var quantityRegex = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(m|k|M|G|T|P|E|Ki|Mi|Gi|Ti|Pi|Ei)?$`)
+
+// HelmArgs returns the helm parameters to set the resources and priority class.
+func (f *ResourcesFlags) HelmArgs() ([]string, error) {
+	args := []string{}
+	values := []struct {
+		key   string
+		value string
+	}{
+		{"resources.requests.cpu", f.Requests.CPU},
+		{"resources.requests.memory", f.Requests.Memory},
+		{"resources.limits.cpu", f.Limits.CPU},
+		{"resources.limits.memory", f.Limits.Memory},
+	}
+	for _, value := range values {
+		if value.value == "" {
+			continue
+		}
+		if !quantityRegex.MatchString(value.value) {
+			return nil, fmt.Errorf(L("invalid %[1]s quantity: %[2]s"), value.key, value.value)
+		}
+		// Use --set-string to keep values like 2 as strings
+		args = append(args, "--set-string", value.key+"="+value.value)
+	}
+	if f.Priority.Class != "" {
+		args = append(args, "--set", "priorityClassName="+f.Priority.Class)
+	}
+	return args, nil
+}
+
+// IngressFlags stores the kubernetes ingress customization.
+type IngressFlags struct {
+	Class       string
+	Annotations []string `mapstructure:"annotation"`
+	Tls         struct {
+		Secret string
+	}
+}
+
+// HelmArgs returns the helm parameters to set the ingress values.
+func (f *IngressFlags) HelmArgs() ([]string, error) {
+	args := []string{}
+	if f.Class != "" {
+		args = append(args, "--set", "ingressClassName="+f.Class)
+	}
+	if f.Tls.Secret != "" {
+		args = append(args, "--set", "ingressSslSecret="+f.Tls.Secret)
+	}
+	if len(f.Annotations) > 0 {
+		annotations, err := annotationsJSON(f.Annotations)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--set-json", "ingressAnnotations="+annotations)
+	}
+	return args, nil
+}
+
+// ExposeFlags stores how the non-HTTP ports are exposed on kubernetes.
+type ExposeFlags struct {
+	Mode        string
+	Ports       []string `mapstructure:"port"`
+	Annotations []string `mapstructure:"annotation"`
+}
+
+// exposeModes are the supported ways to expose the ports, the ingress one relying on the ingress controller.
+var exposeModes = []string{"ingress", "hostPort", "NodePort", "LoadBalancer"}
+
+const (
+	minNodePort = 30000
+	maxNodePort = 32767
+)
+
+type exposedPort struct {
+	Name     string `json:"name"`
+	Port     int    `json:"port"`
+	Exposed  int    `json:"exposed"`
+	Protocol string `json:"protocol"`
+}
+
+// UsesIngress returns true if the ports are exposed by the ingress controller.
+func (f *ExposeFlags) UsesIngress() bool {
+	return f.Mode == "" || strings.EqualFold(f.Mode, "ingress")
+}
+
+// HelmArgs returns the helm parameters to expose the ports.
+//
+// The exposed ports are validated: they can't conflict and need to be in the NodePort range if needed.
+func (f *ExposeFlags) HelmArgs(ports []types.PortMap) ([]string, error) {
+	if f.UsesIngress() {
+		return []string{}, nil
+	}
+	mode := ""
+	for _, value := range exposeModes {
+		if strings.EqualFold(f.Mode, value) {
+			mode = value
+		}
+	}
+	if mode == "" {
+		return nil, fmt.Errorf(L("invalid expose mode %[1]s, possible values: %[2]s"),
+			f.Mode, strings.Join(exposeModes, ", "))
+	}
+
+	overrides := map[string]int{}
+	for _, override := range f.Ports {
+		parts := strings.SplitN(override, "=", 2)
+		port := 0
+		if len(parts) == 2 {
+			port, _ = strconv.Atoi(parts[1])
+		}
+		if port <= 0 || port > 65535 {
+			return nil, fmt.Errorf(L("invalid exposed port %s: expected name=port"), override)
+		}
+		overrides[parts[0]] = port
+	}
+
+	exposed := []exposedPort{}
+	used := map[string]string{}
+	for _, port := range ports {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		value := port.Exposed
+		if override, ok := overrides[port.Name]; ok {
+			value = override
+			delete(overrides, port.Name)
+		}
+		if mode == "NodePort" && (value < minNodePort || value > maxNodePort) {
+			return nil, fmt.Errorf(L("port %[1]d of %[2]s is outside the %[3]d-%[4]d NodePort range, use the expose-port flag to change it"),
+				value, port.Name, minNodePort, maxNodePort)
+		}
+		key := fmt.Sprintf("%d/%s", value, protocol)
+		if other, ok := used[key]; ok {
+			return nil, fmt.Errorf(L("%[1]s and %[2]s ports are both exposed on %[3]s"), other, port.Name, key)
+		}
+		used[key] = port.Name
+		exposed = append(exposed, exposedPort{Name: port.Name, Port: port.Port, Exposed: value, Protocol: protocol})
+	}
+	if len(overrides) > 0 {
+		unknown := []string{}
+		for name := range overrides {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf(L("unknown ports to expose: %s"), strings.Join(unknown, ", "))
+	}
+
+	encoded, err := json.Marshal(exposed)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to encode the exposed ports: %s"))
+	}
+	args := []string{"--set", "exposeMode=" + mode, "--set-json", "exposedPorts=" + string(encoded)}
+	if len(f.Annotations) > 0 {
+		annotations, err := annotationsJSON(f.Annotations)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--set-json", "exposeAnnotations="+annotations)
+	}
+	return args, nil
+}
+
+// annotationsJSON converts a list of key=value annotations into a JSON object.
+func annotationsJSON(values []string) (string, error) {
+	annotations := map[string]string{}
+	for _, annotation := range values {
+		parts := strings.SplitN(annotation, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return "", fmt.Errorf(L("invalid annotation %s: expected key=value"), annotation)
+		}
+		annotations[parts[0]] = parts[1]
+	}
+	encoded, err := json.Marshal(annotations)
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to encode the annotations: %s"))
+	}
+	return string(encoded), nil
+}
+
 // SslCertFlags can store SSL Certs information.
 type SslCertFlags struct {
 	Cnames   []string `mapstructure:"cname"`
@@ -38,6 +399,58 @@ type SslCertFlags struct {
 	Server   ssl.SslPair
 }
 
+// SshFlags stores the options to connect to the migration source server.
+type SshFlags struct {
+	Port      int
+	ProxyJump string
+}
+
+// PgsqlUpgradeFlags stores the options of the PostgreSQL database upgrade.
+type PgsqlUpgradeFlags struct {
+	Tune    bool
+	Analyze bool
+	Link    bool
+	Jobs    int
+}
+
+// DefaultPgsqlUpgradeFlags are the PostgreSQL database upgrade options for the commands not exposing them.
+var DefaultPgsqlUpgradeFlags = PgsqlUpgradeFlags{Link: true, Jobs: 1}
+
+// CheckParameters checks the PostgreSQL database upgrade options.
+func (f *PgsqlUpgradeFlags) CheckParameters() error {
+	if f.Jobs < 1 {
+		return fmt.Errorf(L("invalid number of PostgreSQL upgrade jobs: %d"), f.Jobs)
+	}
+	return nil
+}
+
+// MaintenanceFlags stores the maintenance window the disruptive commands are restricted to.
+type MaintenanceFlags struct {
+	Window string
+}
+
+// Check returns an error if the time is outside of the maintenance window.
+//
+// If force is true, running outside of the window is only reported as a warning.
+func (f *MaintenanceFlags) Check(now time.Time, force bool) error {
+	if f.Window == "" {
+		return nil
+	}
+	schedule, err := utils.ParseCron(f.Window)
+	if err != nil {
+		return utils.UsageError(err)
+	}
+	if schedule.Matches(now) {
+		return nil
+	}
+	if force {
+		log.Warn().Msgf(L("Running outside of the maintenance window %s"), f.Window)
+		return nil
+	}
+	return fmt.Errorf(L("%[1]s is outside of the maintenance window %[2]s, use --force to run anyway"),
+		now.Format(time.RFC1123), f.Window)
+}
+
 // UseExisting return true if existing SSL Cert can be used.
 func (f *SslCertFlags) UseExisting() bool {
 	return f.Server.Cert != "" && f.Server.Key != "" && f.Ca.Root != ""
@@ -74,6 +487,83 @@ func AddHelmInstallFlag(cmd *cobra.Command) {
 	_ = utils.AddFlagToHelpGroupID(cmd, "helm-certmanager-values", "helm")
 }
 
+// AddHelmChartFlags adds the flags locating the uyuni helm chart to a command changing a deployed server.
+func AddHelmChartFlags(cmd *cobra.Command) {
+	defaultChart := fmt.Sprintf("oci://%s/server-helm", utils.DefaultNamespace)
+	cmd.Flags().String("helm-uyuni-chart", defaultChart, L("URL to the uyuni helm chart"))
+	cmd.Flags().String("helm-uyuni-version", "", L("Version of the uyuni helm chart"))
+}
+
+// AddKubernetesFlags add the flags customizing the server deployment on kubernetes to a command.
+func AddKubernetesFlags(cmd *cobra.Command) {
+	AddIngressFlags(cmd)
+	AddExposeFlags(cmd)
+	AddResourcesFlags(cmd)
+	AddSchedulingFlags(cmd)
+}
+
+// AddSchedulingFlags add the server pod node selection flags to a command.
+func AddSchedulingFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("scheduling-nodeSelector", []string{},
+		L("Label the node running the server needs to have as a key=value pair. Can be repeated"))
+	cmd.Flags().StringSlice("scheduling-toleration", []string{},
+		L("Taint tolerated by the server pod as key[=value][:effect]. Can be repeated"))
+	cmd.Flags().String("scheduling-affinity", "", L("Affinity of the server pod as a JSON definition"))
+	cmd.Flags().String("dedicated-node", "",
+		L("Name of the node dedicated to the server: sets the node selector and affinity and tolerates the dedicated=uyuni:NoSchedule taint"))
+
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "scheduling", Title: L("Scheduling Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "scheduling-nodeSelector", "scheduling")
+	_ = utils.AddFlagToHelpGroupID(cmd, "scheduling-toleration", "scheduling")
+	_ = utils.AddFlagToHelpGroupID(cmd, "scheduling-affinity", "scheduling")
+	_ = utils.AddFlagToHelpGroupID(cmd, "dedicated-node", "scheduling")
+}
+
+// AddResourcesFlags add the server pod resources and priority class flags to a command.
+func AddResourcesFlags(cmd *cobra.Command) {
+	cmd.Flags().String("resources-requests-cpu", "", L("CPU requested by the server pod, like 2 or 500m"))
+	cmd.Flags().String("resources-requests-memory", "", L("Memory requested by the server pod, like 16Gi"))
+	cmd.Flags().String("resources-limits-cpu", "", L("CPU limit of the server pod"))
+	cmd.Flags().String("resources-limits-memory", "", L("Memory limit of the server pod"))
+	cmd.Flags().String("resources-priority-class", "",
+		L("Priority class of the server pods to avoid evicting them on shared clusters"))
+
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "resources", Title: L("Resources Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "resources-requests-cpu", "resources")
+	_ = utils.AddFlagToHelpGroupID(cmd, "resources-requests-memory", "resources")
+	_ = utils.AddFlagToHelpGroupID(cmd, "resources-limits-cpu", "resources")
+	_ = utils.AddFlagToHelpGroupID(cmd, "resources-limits-memory", "resources")
+	_ = utils.AddFlagToHelpGroupID(cmd, "resources-priority-class", "resources")
+}
+
+// AddIngressFlags add the kubernetes ingress customization flags to a command.
+func AddIngressFlags(cmd *cobra.Command) {
+	cmd.Flags().String("ingress-class", "", L("Ingress class name to use instead of the cluster default one"))
+	cmd.Flags().StringSlice("ingress-annotation", []string{},
+		L("Additional annotation to set on the ingresses as a key=value pair, like a cert-manager issuer or an external-dns hostname. Can be repeated"))
+	cmd.Flags().String("ingress-tls-secret", "", L("Name of the TLS secret used by the ingresses"))
+
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "ingress", Title: L("Ingress Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "ingress-class", "ingress")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ingress-annotation", "ingress")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ingress-tls-secret", "ingress")
+}
+
+// AddExposeFlags add the flags defining how to expose the non-HTTP ports on kubernetes to a command.
+func AddExposeFlags(cmd *cobra.Command) {
+	cmd.Flags().String("expose-mode", "ingress",
+		L("How to expose the non-HTTP ports like salt or tftp. Possible values: ingress, hostPort, NodePort or LoadBalancer"))
+	cmd.Flags().StringSlice("expose-port", []string{},
+		L("Exposed port of a service as a name=port pair, like salt-publish=30505. Can be repeated"))
+	cmd.Flags().StringSlice("expose-annotation", []string{},
+		L("Annotation to set on the exposed services as a key=value pair, like MetalLB address pool. Can be repeated"))
+
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "expose", Title: L("Port Exposure Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "expose-mode", "expose")
+	_ = utils.AddFlagToHelpGroupID(cmd, "expose-port", "expose")
+	_ = utils.AddFlagToHelpGroupID(cmd, "expose-annotation", "expose")
+}
+
 // AddContainerImageFlags add container image flags to command.
 func AddContainerImageFlags(cmd *cobra.Command, container string) {
 	cmd.Flags().String(container+"-image", "",
@@ -82,6 +572,20 @@ func AddContainerImageFlags(cmd *cobra.Command, container string) {
 		fmt.Sprintf(L("Tag for %s container, overrides the global value if set"), container))
 }
 
+// ComputeContainerImage computes the image URL of a container running next to the server.
+//
+// The image defaults to the server image name with the suffix appended and the tag to the server one.
+func ComputeContainerImage(serverImage types.ImageFlags, image types.ImageFlags, suffix string) (string, error) {
+	tag := image.Tag
+	if tag == "" {
+		tag = serverImage.Tag
+	}
+	if image.Name == "" {
+		return utils.ComputeImage(serverImage.Name, tag, suffix)
+	}
+	return utils.ComputeImage(image.Name, tag)
+}
+
 // AddImageFlag add Image flags to a command.
 func AddImageFlag(cmd *cobra.Command) {
 	cmd.Flags().String("image", defaultImage, L("Image"))
@@ -123,3 +627,28 @@ func AddMigrationImageFlag(cmd *cobra.Command) {
 	_ = utils.AddFlagToHelpGroupID(cmd, "migration-tag", "migration-image")
 	_ = utils.AddFlagToHelpGroupID(cmd, "migration-pullPolicy", "migration-image")
 }
+
+// AddPgsqlUpgradeFlags add the PostgreSQL database upgrade flags to a command.
+func AddPgsqlUpgradeFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("pgsql-tune", false,
+		L("Set the PostgreSQL shared_buffers and effective_cache_size according to the host memory"))
+	cmd.Flags().Bool("pgsql-analyze", false,
+		L("Refresh the PostgreSQL planner statistics after the upgrade. Recommended after a major version upgrade since the statistics are not kept"))
+
+	cmd.Flags().Bool("pgsql-link", DefaultPgsqlUpgradeFlags.Link,
+		L("Use hard links instead of copying the files during a PostgreSQL major version upgrade. Faster and requiring no additional disk space, but the old database cannot be started afterwards"))
+	cmd.Flags().Int("pgsql-jobs", DefaultPgsqlUpgradeFlags.Jobs,
+		L("Number of parallel jobs to use during a PostgreSQL major version upgrade"))
+
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "pgsql", Title: L("PostgreSQL Upgrade Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "pgsql-tune", "pgsql")
+	_ = utils.AddFlagToHelpGroupID(cmd, "pgsql-analyze", "pgsql")
+	_ = utils.AddFlagToHelpGroupID(cmd, "pgsql-link", "pgsql")
+	_ = utils.AddFlagToHelpGroupID(cmd, "pgsql-jobs", "pgsql")
+}
+
+// AddMaintenanceWindowFlag add the maintenance window flag to a command.
+func AddMaintenanceWindowFlag(cmd *cobra.Command) {
+	cmd.Flags().String("maintenance-window", "",
+		L("Cron expression matching the times the command is allowed to run at, for instance '* 22-23 * * 6' for Saturdays from 22:00 to 23:59. Use --force to run outside of it"))
+}
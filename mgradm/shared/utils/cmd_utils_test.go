@@ -0,0 +1,210 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func TestIngressHelmArgs(t *testing.T) {
+	flags := IngressFlags{
+		Class:       "haproxy",
+		Annotations: []string{"cert-manager.io/cluster-issuer=letsencrypt", "external-dns.alpha.kubernetes.io/hostname=uyuni.example.com"},
+	}
+	flags.Tls.Secret = "uyuni-tls"
+
+	args, err := flags.HelmArgs()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := `--set ingressClassName=haproxy --set ingressSslSecret=uyuni-tls --set-json ingressAnnotations=` +
+		`{"cert-manager.io/cluster-issuer":"letsencrypt","external-dns.alpha.kubernetes.io/hostname":"uyuni.example.com"}`
+	if actual := strings.Join(args, " "); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	if args, err := (&IngressFlags{}).HelmArgs(); err != nil || len(args) != 0 {
+		t.Errorf("expected no argument for empty flags, got %v: %v", args, err)
+	}
+
+	invalid := IngressFlags{Annotations: []string{"no-value"}}
+	if _, err := invalid.HelmArgs(); err == nil {
+		t.Error("expected an error for an annotation without value")
+	}
+}
+
+func TestExposeHelmArgs(t *testing.T) {
+	ports := []types.PortMap{
+		utils.NewPortMap("salt-publish", 4505, 4505),
+		utils.NewPortMap("salt-request", 4506, 4506),
+		{Name: "tftp", Exposed: 69, Port: 69, Protocol: "udp"},
+	}
+
+	if args, err := (&ExposeFlags{Mode: "ingress"}).HelmArgs(ports); err != nil || len(args) != 0 {
+		t.Errorf("expected no argument for ingress mode, got %v: %v", args, err)
+	}
+
+	flags := ExposeFlags{Mode: "loadbalancer", Annotations: []string{"metallb.universe.tf/address-pool=uyuni"}}
+	args, err := flags.HelmArgs(ports)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := `--set exposeMode=LoadBalancer --set-json exposedPorts=[` +
+		`{"name":"salt-publish","port":4505,"exposed":4505,"protocol":"tcp"},` +
+		`{"name":"salt-request","port":4506,"exposed":4506,"protocol":"tcp"},` +
+		`{"name":"tftp","port":69,"exposed":69,"protocol":"udp"}] ` +
+		`--set-json exposeAnnotations={"metallb.universe.tf/address-pool":"uyuni"}`
+	if actual := strings.Join(args, " "); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	errorCases := map[string]ExposeFlags{
+		"invalid mode":       {Mode: "hostNetwork"},
+		"out of range":       {Mode: "NodePort"},
+		"conflict":           {Mode: "hostPort", Ports: []string{"salt-request=4505"}},
+		"unknown port":       {Mode: "hostPort", Ports: []string{"foo=1234"}},
+		"invalid port value": {Mode: "hostPort", Ports: []string{"tftp=abc"}},
+	}
+	for name, flags := range errorCases {
+		if _, err := flags.HelmArgs(ports); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	flags = ExposeFlags{Mode: "NodePort", Ports: []string{"salt-publish=30505", "salt-request=30506", "tftp=30069"}}
+	if _, err := flags.HelmArgs(ports); err != nil {
+		t.Errorf("unexpected error for NodePort with valid ports: %s", err)
+	}
+}
+
+func TestResourcesHelmArgs(t *testing.T) {
+	var flags ResourcesFlags
+	flags.Requests.CPU = "2"
+	flags.Requests.Memory = "16Gi"
+	flags.Limits.Memory = "32Gi"
+	flags.Priority.Class = "uyuni-critical"
+
+	args, err := flags.HelmArgs()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "--set-string resources.requests.cpu=2 --set-string resources.requests.memory=16Gi " +
+		"--set-string resources.limits.memory=32Gi --set priorityClassName=uyuni-critical"
+	if actual := strings.Join(args, " "); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	flags.Limits.CPU = "two"
+	if _, err := flags.HelmArgs(); err == nil {
+		t.Error("expected an error for an invalid quantity")
+	}
+}
+
+func TestSchedulingHelmArgs(t *testing.T) {
+	flags := SchedulingFlags{
+		NodeSelector: []string{"storage=ssd"},
+		Tolerations:  []string{"uyuni:NoExecute", "zone=storage"},
+	}
+	args, err := flags.HelmArgs("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := `--set-json nodeSelector={"storage":"ssd"} --set-json tolerations=[` +
+		`{"key":"uyuni","operator":"Exists","effect":"NoExecute"},{"key":"zone","operator":"Equal","value":"storage"}]`
+	if actual := strings.Join(args, " "); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	args, err = (&SchedulingFlags{}).HelmArgs("node1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected = `--set-json nodeSelector={"kubernetes.io/hostname":"node1"} --set-json tolerations=[` +
+		`{"key":"dedicated","operator":"Equal","value":"uyuni","effect":"NoSchedule"}] --set-json affinity=` +
+		`{"nodeAffinity":{"requiredDuringSchedulingIgnoredDuringExecution":{"nodeSelectorTerms":[` +
+		`{"matchExpressions":[{"key":"kubernetes.io/hostname","operator":"In","values":["node1"]}]}]}}}`
+	if actual := strings.Join(args, " "); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	errorCases := map[string]SchedulingFlags{
+		"invalid selector":   {NodeSelector: []string{"ssd"}},
+		"invalid effect":     {Tolerations: []string{"uyuni:Never"}},
+		"invalid affinity":   {Affinity: "{"},
+		"dedicated affinity": {Affinity: "{}"},
+	}
+	for name, flags := range errorCases {
+		if _, err := flags.HelmArgs("node1"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestPgsqlUpgradeCheckParameters(t *testing.T) {
+	if err := DefaultPgsqlUpgradeFlags.CheckParameters(); err != nil {
+		t.Errorf("unexpected error for the default flags: %s", err)
+	}
+	flags := PgsqlUpgradeFlags{Jobs: 0}
+	if err := flags.CheckParameters(); err == nil {
+		t.Error("expected an error for 0 jobs")
+	}
+}
+
+func TestMaintenanceCheck(t *testing.T) {
+	// 2024-06-15 is a Saturday
+	inWindow := time.Date(2024, 6, 15, 22, 30, 0, 0, time.UTC)
+	outsideWindow := time.Date(2024, 6, 17, 10, 0, 0, 0, time.UTC)
+	flags := MaintenanceFlags{Window: "* 22-23 * * 6"}
+
+	if err := flags.Check(inWindow, false); err != nil {
+		t.Errorf("unexpected error in the window: %s", err)
+	}
+	if err := flags.Check(outsideWindow, false); err == nil {
+		t.Error("expected an error outside of the window")
+	}
+	if err := flags.Check(outsideWindow, true); err != nil {
+		t.Errorf("unexpected error when forcing: %s", err)
+	}
+	if err := (&MaintenanceFlags{}).Check(outsideWindow, false); err != nil {
+		t.Errorf("unexpected error without window: %s", err)
+	}
+	if err := (&MaintenanceFlags{Window: "invalid"}).Check(inWindow, false); err == nil {
+		t.Error("expected an error for an invalid window")
+	}
+}
+
+func TestComputeContainerImage(t *testing.T) {
+	server := types.ImageFlags{Name: "registry.opensuse.org/uyuni/server", Tag: "2024.05"}
+	data := []struct {
+		image    types.ImageFlags
+		expected string
+	}{
+		{types.ImageFlags{}, "registry.opensuse.org/uyuni/server-attestation:2024.05"},
+		{types.ImageFlags{Tag: "latest"}, "registry.opensuse.org/uyuni/server-attestation:latest"},
+		{types.ImageFlags{Name: "registry.example.com/coco"}, "registry.example.com/coco:2024.05"},
+		{types.ImageFlags{Name: "registry.example.com/coco:1.0"}, "registry.example.com/coco:1.0"},
+	}
+
+	for i, test := range data {
+		actual, err := ComputeContainerImage(server, test.image, CocoImageSuffix)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %s", i, err)
+		}
+		if actual != test.expected {
+			t.Errorf("case %d: expected %s, got %s", i, test.expected, actual)
+		}
+	}
+
+	server.Name = "registry.opensuse.org/uyuni/server:2024.07"
+	actual, err := ComputeContainerImage(server, types.ImageFlags{}, HubXmlrpcImageSuffix)
+	if err != nil || actual != "registry.opensuse.org/uyuni/server-hub-xmlrpc-api:2024.07" {
+		t.Errorf("unexpected hub image %s: %v", actual, err)
+	}
+}
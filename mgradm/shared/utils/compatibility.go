@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// compatibility describes a range of server releases supported by a range of tool versions.
+//
+// Empty boundaries are unbounded and the maximum values are included.
+type compatibility struct {
+	product    string
+	releaseMin string
+	releaseMax string
+	toolMin    string
+	toolMax    string
+}
+
+const (
+	productUyuni       = "Uyuni"
+	productSuseManager = "SUSE Manager"
+)
+
+// compatibilityMatrix lists the server releases supported by the tool versions.
+var compatibilityMatrix = []compatibility{
+	{product: productUyuni, releaseMin: "2024.03", toolMin: "0.1.0"},
+	{product: productSuseManager, releaseMin: "5.0.0", releaseMax: "5.0.99", toolMin: "0.1.0"},
+}
+
+// CheckVersionSkew verifies that the tool version is compatible with the release of the image.
+//
+// If force is true, the incompatibility is only reported as a warning.
+func CheckVersionSkew(inspectedValues map[string]string, force bool) error {
+	product := productUyuni
+	release := inspectedValues["uyuni_release"]
+	if release == "" {
+		product = productSuseManager
+		release = inspectedValues["suse_manager_release"]
+	}
+	err := checkCompatibility(compatibilityMatrix, utils.Version, product, release)
+	if err != nil && force {
+		log.Warn().Msgf(L("Ignoring version mismatch: %s"), err)
+		return nil
+	}
+	return err
+}
+
+func checkCompatibility(matrix []compatibility, toolVersion string, product string, release string) error {
+	// Development builds and images without release cannot be checked
+	if toolVersion == "0.0.0" || release == "" {
+		log.Debug().Msgf("Skipping the version skew check for tool %s and %s %s", toolVersion, product, release)
+		return nil
+	}
+
+	for _, entry := range matrix {
+		if entry.product != product || !inRange(release, entry.releaseMin, entry.releaseMax) {
+			continue
+		}
+		if inRange(toolVersion, entry.toolMin, entry.toolMax) {
+			return nil
+		}
+		if entry.toolMin != "" && compareVersions(toolVersion, entry.toolMin) < 0 {
+			return fmt.Errorf(L("%[1]s %[2]s requires uyuni-tools %[3]s or newer, this is %[4]s. Update uyuni-tools or use --force"),
+				product, release, entry.toolMin, toolVersion)
+		}
+		return fmt.Errorf(L("uyuni-tools %[1]s is too recent for %[2]s %[3]s. Use uyuni-tools %[4]s or older or use --force"),
+			toolVersion, product, release, entry.toolMax)
+	}
+
+	return fmt.Errorf(L("%[1]s %[2]s is not known to uyuni-tools %[3]s. Update uyuni-tools or use --force"),
+		product, release, toolVersion)
+}
+
+func inRange(version string, min string, max string) bool {
+	return (min == "" || compareVersions(version, min) >= 0) && (max == "" || compareVersions(version, max) <= 0)
+}
+
+var versionCleanupRegex = regexp.MustCompile(`\(.*?\)|[^0-9.].*$`)
+
+// compareVersions compares dot-separated versions numerically.
+//
+// Returns a negative value if a is older than b, 0 if they are equal and a positive value otherwise.
+func compareVersions(a string, b string) int {
+	aParts := strings.Split(versionCleanupRegex.ReplaceAllString(strings.TrimSpace(a), ""), ".")
+	bParts := strings.Split(versionCleanupRegex.ReplaceAllString(strings.TrimSpace(b), ""), ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		aValue := 0
+		if i < len(aParts) {
+			aValue, _ = strconv.Atoi(aParts[i])
+		}
+		bValue := 0
+		if i < len(bParts) {
+			bValue, _ = strconv.Atoi(bParts[i])
+		}
+		if aValue != bValue {
+			return aValue - bValue
+		}
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	data := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"0.1.10", "0.1.9", 1},
+		{"2024.05", "2024.05", 0},
+		{"5.0.0", "5.0.1", -1},
+		{"5.0.0 (Beta1)", "5.0.0", 0},
+		{"0.1.11~git.1234", "0.1.11", 0},
+		{"5.0", "5.0.0", 0},
+	}
+
+	for i, testCase := range data {
+		actual := compareVersions(testCase.a, testCase.b)
+		if (actual > 0 && testCase.expected <= 0) || (actual < 0 && testCase.expected >= 0) ||
+			(actual == 0 && testCase.expected != 0) {
+			t.Errorf("Testcase %d: comparing %s to %s got %d", i, testCase.a, testCase.b, actual)
+		}
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	matrix := []compatibility{
+		{product: productUyuni, releaseMin: "2024.03", releaseMax: "2024.07", toolMin: "0.1.0", toolMax: "0.1.99"},
+		{product: productUyuni, releaseMin: "2024.08", toolMin: "0.2.0"},
+	}
+
+	data := []struct {
+		tool    string
+		release string
+		fails   bool
+	}{
+		{"0.1.5", "2024.05", false},
+		{"0.0.0", "2024.05", false},
+		{"0.1.5", "", false},
+		{"0.1.5", "2024.08", true},
+		{"0.2.1", "2024.05", true},
+		{"0.2.1", "2024.10", false},
+		{"0.2.1", "2023.10", true},
+	}
+
+	for i, testCase := range data {
+		err := checkCompatibility(matrix, testCase.tool, productUyuni, testCase.release)
+		if (err != nil) != testCase.fails {
+			t.Errorf("Testcase %d: tool %s with release %s, unexpected result: %v", i, testCase.tool, testCase.release, err)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -27,7 +28,7 @@ import (
 func ExecCommand(logLevel zerolog.Level, cnx *shared.Connection, args ...string) error {
 	podName, err := cnx.GetPodName()
 	if err != nil {
-		return fmt.Errorf(L("exec command failed: %s"), err)
+		return utils.Errorf(err, L("exec command failed: %s"))
 	}
 
 	commandArgs := []string{"exec", podName}
@@ -43,18 +44,26 @@ func ExecCommand(logLevel zerolog.Level, cnx *shared.Connection, args ...string)
 
 	commandArgs = append(commandArgs, "sh", "-c", strings.Join(args, " "))
 
+	var output bytes.Buffer
 	runCmd := exec.Command(command, commandArgs...)
 	logger := utils.OutputLogWriter{Logger: log.Logger, LogLevel: logLevel}
-	runCmd.Stdout = logger
-	runCmd.Stderr = logger
-	return runCmd.Run()
+	runCmd.Stdout = io.MultiWriter(logger, &output)
+	runCmd.Stderr = io.MultiWriter(logger, &output)
+	_, span := utils.StartSpan(utils.TraceContext(), "exec in container",
+		"process.command_line", utils.Redact(strings.Join(args, " ")))
+	err = runCmd.Run()
+	span.End(err)
+	utils.LogCommand(command, commandArgs, output.Bytes(), err, false)
+	return err
 }
 
 // GeneratePgsqlVersionUpgradeScript generates the PostgreSQL version upgrade script.
-func GeneratePgsqlVersionUpgradeScript(scriptDir string, oldPgVersion string, newPgVersion string, kubernetes bool) (string, error) {
+func GeneratePgsqlVersionUpgradeScript(scriptDir string, oldPgVersion string, newPgVersion string, kubernetes bool, pgsqlFlags PgsqlUpgradeFlags) (string, error) {
 	data := templates.PostgreSQLVersionUpgradeTemplateData{
 		OldVersion: oldPgVersion,
 		NewVersion: newPgVersion,
+		Link:       pgsqlFlags.Link,
+		Jobs:       pgsqlFlags.Jobs,
 		Kubernetes: kubernetes,
 	}
 
@@ -67,12 +76,14 @@ func GeneratePgsqlVersionUpgradeScript(scriptDir string, oldPgVersion string, ne
 }
 
 // GenerateFinalizePostgresScript generates the script to finalize PostgreSQL upgrade.
-func GenerateFinalizePostgresScript(scriptDir string, RunAutotune bool, RunReindex bool, RunSchemaUpdate bool, RunDistroMigration bool, kubernetes bool) (string, error) {
+func GenerateFinalizePostgresScript(scriptDir string, RunAutotune bool, RunReindex bool, RunSchemaUpdate bool, RunDistroMigration bool, kubernetes bool, pgsqlFlags PgsqlUpgradeFlags) (string, error) {
 	data := templates.FinalizePostgresTemplateData{
 		RunAutotune:        RunAutotune,
 		RunReindex:         RunReindex,
 		RunSchemaUpdate:    RunSchemaUpdate,
 		RunDistroMigration: RunDistroMigration,
+		RunTuning:          pgsqlFlags.Tune,
+		RunAnalyze:         pgsqlFlags.Analyze,
 		Kubernetes:         kubernetes,
 	}
 
@@ -106,7 +117,7 @@ func ReadContainerData(scriptDir string) (string, string, string, error) {
 	}
 	viper.SetConfigType("env")
 	if err := viper.ReadConfig(bytes.NewBuffer(data)); err != nil {
-		return "", "", "", fmt.Errorf(L("cannot read config: %s"), err)
+		return "", "", "", utils.Errorf(err, L("cannot read config: %s"))
 	}
 	if len(viper.GetString("Timezone")) <= 0 {
 		return "", "", "", errors.New(L("cannot retrieve timezone"))
@@ -120,21 +131,27 @@ func ReadContainerData(scriptDir string) (string, string, string, error) {
 	return viper.GetString("Timezone"), viper.GetString("old_pg_version"), viper.GetString("new_pg_version"), nil
 }
 
-// RunMigration execute the migration script.
-func RunMigration(cnx *shared.Connection, tmpPath string, scriptName string) error {
-	log.Info().Msg(L("Migrating server"))
-	err := ExecCommand(zerolog.InfoLevel, cnx, "/var/lib/uyuni-tools/"+scriptName)
-	if err != nil {
-		return fmt.Errorf(L("error running the migration script: %s"), err)
-	}
-	return nil
-}
-
 // GenerateMigrationScript generates the script that perform migration.
-func GenerateMigrationScript(sourceFqdn string, user string, kubernetes bool) (string, error) {
+//
+// With prepare set, the script only copies the data without stopping the source server.
+// With final set, the services of the source server are disabled after being stopped.
+func GenerateMigrationScript(sourceFqdn string, user string, ssh SshFlags, kubernetes bool, prepare bool, final bool) (string, error) {
 	scriptDir, err := os.MkdirTemp("", "mgradm-*")
 	if err != nil {
-		return "", fmt.Errorf(L("failed to create temporary directory: %s"), err)
+		return "", utils.Errorf(err, L("failed to create temporary directory: %s"))
+	}
+
+	// The user SSH configuration, if any, is mounted as /tmp/ssh_config in the container
+	sshConfig := templates.SshConfigTemplateData{
+		SourceFqdn:  sourceFqdn,
+		User:        user,
+		Port:        ssh.Port,
+		ProxyJump:   ssh.ProxyJump,
+		ControlPath: "/tmp/ssh-mux-%C",
+		IncludePath: "/tmp/ssh_config",
+	}
+	if err := GenerateSshConfig(sshConfig, filepath.Join(scriptDir, "ssh_config")); err != nil {
+		return "", err
 	}
 
 	data := templates.MigrateScriptTemplateData{
@@ -142,16 +159,26 @@ func GenerateMigrationScript(sourceFqdn string, user string, kubernetes bool) (s
 		SourceFqdn: sourceFqdn,
 		User:       user,
 		Kubernetes: kubernetes,
+		Prepare:    prepare,
+		Final:      final,
 	}
 
 	scriptPath := filepath.Join(scriptDir, "migrate.sh")
 	if err = utils.WriteTemplateToFile(data, scriptPath, 0555, true); err != nil {
-		return "", fmt.Errorf(L("failed to generate migration script: %s"), err)
+		return "", utils.Errorf(err, L("failed to generate migration script: %s"))
 	}
 
 	return scriptDir, nil
 }
 
+// GenerateSshConfig writes the SSH client configuration to connect to the migration source server.
+func GenerateSshConfig(data templates.SshConfigTemplateData, configPath string) error {
+	if err := utils.WriteTemplateToFile(data, configPath, 0644, true); err != nil {
+		return utils.Errorf(err, L("failed to generate SSH configuration: %s"))
+	}
+	return nil
+}
+
 // RunningImage returns the image running in the current system.
 func RunningImage(cnx *shared.Connection, containerName string) (string, error) {
 	command, err := cnx.GetCommand()
@@ -166,18 +193,13 @@ func RunningImage(cnx *shared.Connection, containerName string) (string, error)
 		return strings.Trim(string(image), "\n"), nil
 
 	case "kubectl":
-
-		//FIXME this will work until containers 0 is uyuni. Then jsonpath should be something like
-		// {.items[0].spec.containers[?(@.name=="` + containerName + `")].image but there are problems
-		// using RunCmdOutput with an arguments with round brackets
-		args := []string{"get", "pods", kubernetes.ServerFilter, "-o", "jsonpath={.items[0].spec.containers[0].image}"}
-		image, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", args...)
-
-		log.Info().Msgf(L("Image is: %s"), image)
+		namespace, err := cnx.GetNamespace()
 		if err != nil {
 			return "", err
 		}
-		return strings.Trim(string(image), "\n"), nil
+		image, err := kubernetes.GetRunningImage(namespace, kubernetes.ServerFilter)
+		log.Info().Msgf(L("Image is: %s"), image)
+		return image, err
 	}
 
 	return command, err
@@ -187,7 +209,7 @@ func RunningImage(cnx *shared.Connection, containerName string) (string, error)
 func SanityCheck(cnx *shared.Connection, inspectedValues map[string]string, serverImage string) error {
 	isUyuni, err := isUyuni(cnx)
 	if err != nil {
-		return fmt.Errorf(L("cannot check server release: %s"), err)
+		return utils.Errorf(err, L("cannot check server release: %s"))
 	}
 	_, isCurrentUyuni := inspectedValues["uyuni_release"]
 	_, isCurrentSuma := inspectedValues["suse_manager_release"]
@@ -204,7 +226,7 @@ func SanityCheck(cnx *shared.Connection, inspectedValues map[string]string, serv
 		cnx_args := []string{"s/Uyuni release //g", "/etc/uyuni-release"}
 		current_uyuni_release, err := cnx.Exec("sed", cnx_args...)
 		if err != nil {
-			return fmt.Errorf(L("failed to read current uyuni release: %s"), err)
+			return utils.Errorf(err, L("failed to read current uyuni release: %s"))
 		}
 		log.Debug().Msgf("Current release is %s", string(current_uyuni_release))
 		if (len(inspectedValues["uyuni_release"])) <= 0 {
@@ -218,7 +240,7 @@ func SanityCheck(cnx *shared.Connection, inspectedValues map[string]string, serv
 		cnx_args := []string{"s/SUSE Manager release //g", "/etc/susemanager-release"}
 		current_suse_manager_release, err := cnx.Exec("sed", cnx_args...)
 		if err != nil {
-			return fmt.Errorf(L("failed to read current susemanager release: %s"), err)
+			return utils.Errorf(err, L("failed to read current susemanager release: %s"))
 		}
 		log.Debug().Msgf("Current release is %s", string(current_suse_manager_release))
 		if (len(inspectedValues["suse_manager_release"])) <= 0 {
@@ -255,3 +277,55 @@ func isUyuni(cnx *shared.Connection) (bool, error) {
 	}
 	return true, nil
 }
+
+// RunQuery runs an SQL query on the server database and returns the result rows.
+func RunQuery(cnx *shared.Connection, query string) ([][]string, error) {
+	out, err := cnx.Exec("sh", "-c", fmt.Sprintf("echo \"%s\" | spacewalk-sql --select-mode -", query))
+	if err != nil {
+		return nil, err
+	}
+	return ParseSQLRows(string(out)), nil
+}
+
+// ParseSQLRows extracts the rows from a psql aligned output, without the header and footer.
+func ParseSQLRows(out string) [][]string {
+	rows := [][]string{}
+	lines := strings.Split(out, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		// The line after the header only contains dashes and pluses
+		if line == "" || strings.Trim(line, "-+") == "" || strings.HasPrefix(line, "(") {
+			continue
+		}
+		if i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), "---") {
+			// This is the header line
+			continue
+		}
+		row := []string{}
+		for _, column := range strings.Split(line, "|") {
+			row = append(row, strings.TrimSpace(column))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// GetRhnConfValue returns the value of an rhn.conf property of the server.
+func GetRhnConfValue(cnx *shared.Connection, property string) (string, error) {
+	expression := `s/^\s*` + strings.ReplaceAll(property, ".", `\.`) + `\s*=\s*\([^ ]*\)\s*$/\1/p`
+	out, err := cnx.Exec("sed", "-n", expression, "/etc/rhn/rhn.conf")
+	return strings.TrimSpace(string(out)), err
+}
+
+// localDbHosts are the database host values designating the server itself.
+var localDbHosts = []string{"", "localhost", "127.0.0.1", "::1"}
+
+// IsLocalDb returns whether the database host designates the server itself.
+func IsLocalDb(host string, fqdn string) bool {
+	return utils.Contains(localDbHosts, host) || host == fqdn
+}
+
+// IsExternalDb returns whether the inspected server uses an external database.
+func IsExternalDb(inspectedValues map[string]string) bool {
+	return !IsLocalDb(inspectedValues["db_host"], inspectedValues["fqdn"])
+}
@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSQLRows(t *testing.T) {
+	out := ` status  | count
+---------+-------
+ READY   |     3
+ RUNNING |     1
+(2 rows)
+
+`
+	expected := [][]string{{"READY", "3"}, {"RUNNING", "1"}}
+	if actual := ParseSQLRows(out); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	if actual := ParseSQLRows(" status | end_time\n--------+----------\n(0 rows)\n"); len(actual) != 0 {
+		t.Errorf("expected no row, got %v", actual)
+	}
+}
+
+func TestIsExternalDb(t *testing.T) {
+	data := []struct {
+		dbHost   string
+		expected bool
+	}{
+		{"localhost", false},
+		{"", false},
+		{"::1", false},
+		{"uyuni.example.com", false},
+		{"db.example.com", true},
+	}
+	for _, testCase := range data {
+		values := map[string]string{"db_host": testCase.dbHost, "fqdn": "uyuni.example.com"}
+		if actual := IsExternalDb(values); actual != testCase.expected {
+			t.Errorf("%s: expected %v got %v", testCase.dbHost, testCase.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// MigrationStage is a step of the migration with its duration.
+type MigrationStage struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"-"`
+	Seconds  float64       `json:"seconds"`
+}
+
+// MigrationReport gathers the information on a migration run.
+type MigrationReport struct {
+	SourceFqdn   string           `json:"sourceFqdn"`
+	StartTime    time.Time        `json:"startTime"`
+	EndTime      time.Time        `json:"endTime"`
+	Timezone     string           `json:"timezone"`
+	OldPgVersion string           `json:"oldPgVersion"`
+	NewPgVersion string           `json:"newPgVersion"`
+	VolumeSizes  map[string]int64 `json:"volumeSizes,omitempty"`
+	SkippedFiles []string         `json:"skippedFiles,omitempty"`
+	Warnings     []string         `json:"warnings,omitempty"`
+	Stages       []MigrationStage `json:"stages"`
+
+	stageStart time.Time
+	progress   *utils.Progress
+}
+
+// migrationStages is the number of stages of a complete migration, used to compute the progress.
+const migrationStages = 5
+
+// NewMigrationReport creates a report for a migration starting now.
+func NewMigrationReport(sourceFqdn string) *MigrationReport {
+	now := time.Now()
+	return &MigrationReport{
+		SourceFqdn:  sourceFqdn,
+		StartTime:   now,
+		VolumeSizes: map[string]int64{},
+		stageStart:  now,
+		progress:    utils.NewProgress("migration", migrationStages),
+	}
+}
+
+// EndStage records the duration of a stage ending now. The next stage starts right after it.
+func (r *MigrationReport) EndStage(name string) {
+	now := time.Now()
+	duration := now.Sub(r.stageStart)
+	r.Stages = append(r.Stages, MigrationStage{Name: name, Duration: duration, Seconds: duration.Seconds()})
+	utils.RecordSpan(utils.TraceContext(), "migration "+name, r.stageStart, now, nil)
+	r.stageStart = now
+	r.progress.StepDone(name)
+}
+
+// ReadScriptData adds the data collected by the migration script to the report.
+func (r *MigrationReport) ReadScriptData(scriptDir string) error {
+	tz, oldPgVersion, newPgVersion, err := ReadContainerData(scriptDir)
+	if err != nil {
+		return err
+	}
+	r.Timezone = tz
+	r.OldPgVersion = oldPgVersion
+	r.NewPgVersion = newPgVersion
+
+	for _, line := range readLines(filepath.Join(scriptDir, "sizes")) {
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			continue
+		}
+		if size, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+			r.VolumeSizes[parts[0]] = size
+		}
+	}
+	r.SkippedFiles = readLines(filepath.Join(scriptDir, "skipped"))
+	r.Warnings = readLines(filepath.Join(scriptDir, "warnings"))
+	return nil
+}
+
+func readLines(filePath string) []string {
+	lines := []string{}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return lines
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Save writes the report in JSON format next to the log file and returns its path.
+func (r *MigrationReport) Save() (string, error) {
+	r.EndTime = time.Now()
+
+	dir := "."
+	if logFile := utils.GetLogFilePath(); logFile != "" {
+		dir = path.Dir(logFile)
+	}
+	reportPath := path.Join(dir, fmt.Sprintf("uyuni-migration-report-%s.json", r.StartTime.Format("20060102-150405")))
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to serialize the migration report: %s"))
+	}
+	if err := os.WriteFile(reportPath, data, 0600); err != nil {
+		return "", utils.Errorf(err, L("cannot write %s file: %s"), reportPath)
+	}
+	return reportPath, nil
+}
+
+// Print writes a summary table of the report.
+func (r *MigrationReport) Print(out io.Writer) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\n", L("Source server"), r.SourceFqdn)
+	fmt.Fprintf(w, "%s\t%s\n", L("Timezone"), r.Timezone)
+	fmt.Fprintf(w, "%s\t%s -> %s\n", L("PostgreSQL version"), r.OldPgVersion, r.NewPgVersion)
+	fmt.Fprintf(w, "%s\t%s\n", L("Total duration"), r.EndTime.Sub(r.StartTime).Round(time.Second))
+	for _, stage := range r.Stages {
+		fmt.Fprintf(w, "  %s\t%s\n", stage.Name, stage.Duration.Round(time.Second))
+	}
+	if len(r.VolumeSizes) > 0 {
+		fmt.Fprintf(w, "%s\t\n", L("Copied data"))
+		volumes := make([]string, 0, len(r.VolumeSizes))
+		for volume := range r.VolumeSizes {
+			volumes = append(volumes, volume)
+		}
+		sort.Strings(volumes)
+		for _, volume := range volumes {
+			fmt.Fprintf(w, "  %s\t%d MiB\n", volume, r.VolumeSizes[volume]/(1024*1024))
+		}
+	}
+	if len(r.SkippedFiles) > 0 {
+		fmt.Fprintf(w, "%s\t%s\n", L("Skipped"), strings.Join(r.SkippedFiles, ", "))
+	}
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(w, "%s\t%s\n", L("Warning"), warning)
+	}
+	w.Flush()
+}
+
+// Finish saves the report and prints its summary.
+func (r *MigrationReport) Finish() {
+	r.progress.Done(L("Migration finished"))
+	reportPath, err := r.Save()
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Failed to save the migration report"))
+	} else {
+		log.Info().Msgf(L("Migration report saved in %s"), reportPath)
+	}
+	r.Print(os.Stdout)
+}
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadScriptData(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"data":     "Timezone=Europe/Berlin\nold_pg_version=14\nnew_pg_version=16\n",
+		"sizes":    "var-pgsql 2097152\nsrv-www 1048576\ninvalid\n",
+		"skipped":  "/srv/www/htdocs/pub/big.iso\n\n",
+		"warnings": "rsync of /etc/cobbler failed\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %s", name, err)
+		}
+	}
+
+	report := NewMigrationReport("source.example.com")
+	if err := report.ReadScriptData(dir); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if report.Timezone != "Europe/Berlin" || report.OldPgVersion != "14" || report.NewPgVersion != "16" {
+		t.Errorf("wrong container data: %s, %s, %s", report.Timezone, report.OldPgVersion, report.NewPgVersion)
+	}
+	if len(report.VolumeSizes) != 2 || report.VolumeSizes["var-pgsql"] != 2097152 {
+		t.Errorf("wrong volume sizes: %v", report.VolumeSizes)
+	}
+	if len(report.SkippedFiles) != 1 || report.SkippedFiles[0] != "/srv/www/htdocs/pub/big.iso" {
+		t.Errorf("wrong skipped files: %v", report.SkippedFiles)
+	}
+	if len(report.Warnings) != 1 {
+		t.Errorf("wrong warnings: %v", report.Warnings)
+	}
+
+	var out bytes.Buffer
+	report.Print(&out)
+	printed := out.String()
+	srvIndex := strings.Index(printed, "srv-www")
+	pgsqlIndex := strings.Index(printed, "var-pgsql")
+	if srvIndex < 0 || pgsqlIndex < srvIndex {
+		t.Errorf("volumes not printed in order:\n%s", printed)
+	}
+	if !strings.Contains(printed, "2 MiB") {
+		t.Errorf("missing volume size:\n%s", printed)
+	}
+}
@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package action
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/system"
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type scheduleFlags struct {
+	api.ConnectionDetails `mapstructure:"api"`
+	Systems               string
+	At                    string
+	Dry                   struct {
+		Run bool
+	}
+	Force bool
+	Test  bool
+}
+
+// NewCommand creates the command scheduling actions on the registered systems.
+func NewCommand(globalFlags *types.GlobalFlags) (*cobra.Command, error) {
+	actionCmd := &cobra.Command{
+		Use:   "action",
+		Short: L("Actions on the registered systems"),
+	}
+
+	if err := api.AddAPIFlags(actionCmd, false); err != nil {
+		return actionCmd, err
+	}
+
+	scheduleCmd := &cobra.Command{
+		Use:   "schedule",
+		Short: L("Schedule an action on registered systems"),
+	}
+	scheduleCmd.PersistentFlags().String("systems", "", L("systems to run the action on: ")+system.SelectorHelp)
+	scheduleCmd.PersistentFlags().String("at", "now",
+		L("earliest time to run the action: now, +DURATION like +2h, YYYY-MM-DD HH:MM in local time or RFC 3339"))
+	scheduleCmd.PersistentFlags().Bool("dry-run", false, L("only show the systems the action would be scheduled on"))
+	scheduleCmd.PersistentFlags().BoolP("force", "f", false, L("schedule without asking confirmation"))
+	if err := scheduleCmd.MarkPersistentFlagRequired("systems"); err != nil {
+		return actionCmd, err
+	}
+
+	scheduleCmd.AddCommand(newScheduleCommand(globalFlags, "patch",
+		L("Install all the relevant patches on the systems"), schedulePatch))
+	scheduleCmd.AddCommand(newScheduleCommand(globalFlags, "reboot",
+		L("Reboot the systems"), scheduleReboot))
+	highstateCmd := newScheduleCommand(globalFlags, "highstate",
+		L("Apply the highstate on the systems"), scheduleHighstate)
+	highstateCmd.Flags().Bool("test", false, L("only show what the highstate would change"))
+	scheduleCmd.AddCommand(highstateCmd)
+
+	actionCmd.AddCommand(scheduleCmd)
+
+	return actionCmd, nil
+}
+
+func newScheduleCommand(
+	globalFlags *types.GlobalFlags,
+	use string,
+	short string,
+	fn utils.CommandFunc[scheduleFlags],
+) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags scheduleFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, fn)
+		},
+	}
+}
+
+// parseTime converts the --at flag value to a time.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" || value == "now" {
+		return now, nil
+	}
+	if strings.HasPrefix(value, "+") {
+		duration, err := time.ParseDuration(value[1:])
+		if err == nil {
+			return now.Add(duration), nil
+		}
+	}
+	if at, err := time.ParseInLocation("2006-01-02 15:04", value, now.Location()); err == nil {
+		return at, nil
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	return now, fmt.Errorf(L("invalid time %s"), value)
+}
+
+// prepare logs in, resolves the systems and the time and asks the confirmation.
+//
+// It returns a nil client if the action should not be scheduled.
+func prepare(flags *scheduleFlags, action string) (*api.HTTPClient, []apiTypes.SystemInfo, time.Time, error) {
+	at, err := parseTime(flags.At, time.Now())
+	if err != nil {
+		return nil, nil, at, utils.UsageError(err)
+	}
+
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return nil, nil, at, utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	systems, err := system.Select(client, flags.Systems)
+	if err != nil {
+		return nil, nil, at, err
+	}
+	if len(systems) == 0 {
+		return nil, nil, at, fmt.Errorf(L("no system matches %s"), flags.Systems)
+	}
+
+	fmt.Printf(L("%[1]s will be scheduled at %[2]s on %[3]d systems:")+"\n",
+		action, at.Format(time.RFC1123), len(systems))
+	for _, item := range systems {
+		fmt.Printf("  %s (%d)\n", item.Name, item.Id)
+	}
+
+	if flags.Dry.Run {
+		utils.SetMachineChanged(false)
+		return nil, systems, at, nil
+	}
+	if !flags.Force {
+		confirmed, err := utils.YesNo(L("Do you want to schedule the action"))
+		if err != nil {
+			return nil, nil, at, err
+		}
+		if !confirmed {
+			log.Info().Msg(L("Action not scheduled"))
+			utils.SetMachineChanged(false)
+			return nil, systems, at, nil
+		}
+	}
+	return client, systems, at, nil
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package action
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func TestNewCommand(t *testing.T) {
+	var globalflags types.GlobalFlags
+	cmd, err := NewCommand(&globalflags)
+	if err != nil {
+		t.Errorf("Unexpected error creating command: %s", err)
+	}
+	if cmd == nil {
+		t.Error("Unexpected nil command")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 5, 10, 8, 30, 0, 0, time.UTC)
+	data := map[string]time.Time{
+		"":                          now,
+		"now":                       now,
+		"+2h":                       now.Add(2 * time.Hour),
+		"2024-05-11 22:00":          time.Date(2024, 5, 11, 22, 0, 0, 0, time.UTC),
+		"2024-05-11T22:00:00+02:00": time.Date(2024, 5, 11, 20, 0, 0, 0, time.UTC),
+	}
+	for value, expected := range data {
+		actual, err := parseTime(value, now)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %s", value, err)
+		} else if !actual.Equal(expected) {
+			t.Errorf("expected %s for %s, got %s", expected, value, actual)
+		}
+	}
+
+	for _, value := range []string{"tomorrow", "+2 hours"} {
+		if _, err := parseTime(value, now); err == nil {
+			t.Errorf("expected an error for %s", value)
+		}
+	}
+}
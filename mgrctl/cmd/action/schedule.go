@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package action
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api/system"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func schedulePatch(globalFlags *types.GlobalFlags, flags *scheduleFlags, cmd *cobra.Command, args []string) error {
+	client, systems, at, err := prepare(flags, L("Patches installation"))
+	if client == nil || err != nil {
+		return err
+	}
+
+	errataIds := []int{}
+	for _, item := range systems {
+		errata, err := system.RelevantErrata(client, item.Id)
+		if err != nil {
+			return err
+		}
+		for _, erratum := range errata {
+			if !utils.Contains(errataIds, erratum.Id) {
+				errataIds = append(errataIds, erratum.Id)
+			}
+		}
+	}
+	if len(errataIds) == 0 {
+		log.Info().Msg(L("No relevant patch to install"))
+		utils.SetMachineChanged(false)
+		return nil
+	}
+
+	actionIds, err := system.ScheduleApplyErrata(client, system.Ids(systems), errataIds, at)
+	if err != nil {
+		return err
+	}
+	logScheduled(actionIds...)
+	return nil
+}
+
+func scheduleReboot(globalFlags *types.GlobalFlags, flags *scheduleFlags, cmd *cobra.Command, args []string) error {
+	client, systems, at, err := prepare(flags, L("Reboot"))
+	if client == nil || err != nil {
+		return err
+	}
+
+	actionIds := []int{}
+	for _, item := range systems {
+		actionId, err := system.ScheduleReboot(client, item.Id, at)
+		if err != nil {
+			return err
+		}
+		actionIds = append(actionIds, actionId)
+	}
+	logScheduled(actionIds...)
+	return nil
+}
+
+func scheduleHighstate(globalFlags *types.GlobalFlags, flags *scheduleFlags, cmd *cobra.Command, args []string) error {
+	client, systems, at, err := prepare(flags, L("Highstate"))
+	if client == nil || err != nil {
+		return err
+	}
+
+	actionId, err := system.ScheduleHighstate(client, system.Ids(systems), at, flags.Test)
+	if err != nil {
+		return err
+	}
+	logScheduled(actionId)
+	return nil
+}
+
+func logScheduled(actionIds ...int) {
+	utils.AddMachineData("actions", actionIds)
+	ids := []string{}
+	for _, id := range actionIds {
+		ids = append(ids, strconv.Itoa(id))
+	}
+	log.Info().Msgf(L("Scheduled actions: %s"), strings.Join(ids, ", "))
+}
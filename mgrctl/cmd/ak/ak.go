@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ak
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type akFlags struct {
+	api.ConnectionDetails `mapstructure:"api"`
+	utils.OutputFlags     `mapstructure:",squash"`
+	Description           string
+	Base                  struct {
+		Channel string
+	}
+	Usage struct {
+		Limit int
+	}
+	Entitlements []string
+	Universal    struct {
+		Default bool
+	}
+	Groups []string
+}
+
+// NewCommand creates the command managing the activation keys.
+func NewCommand(globalFlags *types.GlobalFlags) (*cobra.Command, error) {
+	akCmd := &cobra.Command{
+		Use:   "ak",
+		Short: L("Activation keys commands"),
+	}
+
+	if err := api.AddAPIFlags(akCmd, false); err != nil {
+		return akCmd, err
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create [key]",
+		Short: L("Create an activation key"),
+		Long:  L("Create an activation key. The server generates the key if none is provided and prefixes it with the organization ID"),
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags akFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, create)
+		},
+	}
+	createCmd.Flags().String("description", "", L("description of the activation key"))
+	createCmd.Flags().String("base-channel", "", L("label of the base channel. Defaults to the vendor channel matching the system"))
+	createCmd.Flags().Int("usage-limit", 0, L("maximum number of systems using the key. 0 means unlimited"))
+	createCmd.Flags().StringSlice("entitlements", []string{},
+		L("add-on entitlements like container_build_host, monitoring_entitled, osimage_build_host or virtualization_host"))
+	createCmd.Flags().Bool("universal-default", false, L("make the key the default one of the organization"))
+	createCmd.Flags().StringSlice("groups", []string{}, L("names of the system groups to add the registered systems to"))
+
+	listCmd := &cobra.Command{
+		Use:         "list",
+		Short:       L("List the activation keys"),
+		Args:        cobra.ExactArgs(0),
+		Annotations: map[string]string{utils.ReadOnlyAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags akFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, list)
+		},
+	}
+	utils.AddOutputFlag(listCmd)
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete key...",
+		Short: L("Delete activation keys"),
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags akFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, remove)
+		},
+	}
+
+	akCmd.AddCommand(createCmd)
+	akCmd.AddCommand(listCmd)
+	akCmd.AddCommand(deleteCmd)
+
+	return akCmd, nil
+}
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ak
+
+import (
+	"reflect"
+	"testing"
+
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func TestNewCommand(t *testing.T) {
+	var globalflags types.GlobalFlags
+	cmd, err := NewCommand(&globalflags)
+	if err != nil {
+		t.Errorf("Unexpected error creating command: %s", err)
+	}
+	if cmd == nil {
+		t.Error("Unexpected nil command")
+	}
+}
+
+func TestKeysTable(t *testing.T) {
+	keys := []apiTypes.ActivationKey{
+		{Key: "1-web", Description: "Web servers", BaseChannelLabel: "sles15-sp5-pool-x86_64"},
+		{Key: "1-db", UsageLimit: 5, Disabled: true},
+	}
+	expected := [][]string{
+		{"1-web", "Web servers", "sles15-sp5-pool-x86_64", "unlimited", "false"},
+		{"1-db", "", "", "5", "true"},
+	}
+	if actual := keysTable(keys).Rows; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ak
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/activationkey"
+	"github.com/uyuni-project/uyuni-tools/shared/api/systemgroup"
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func create(globalFlags *types.GlobalFlags, flags *akFlags, cmd *cobra.Command, args []string) error {
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	// Resolve the groups first to avoid creating a key that cannot be fully configured
+	groupIds := []int{}
+	for _, name := range flags.Groups {
+		group, err := systemgroup.GetDetails(client, name)
+		if err != nil {
+			return err
+		}
+		groupIds = append(groupIds, group.Id)
+	}
+
+	key := apiTypes.ActivationKey{
+		Description:      flags.Description,
+		BaseChannelLabel: flags.Base.Channel,
+		UsageLimit:       flags.Usage.Limit,
+		Entitlements:     flags.Entitlements,
+		UniversalDefault: flags.Universal.Default,
+	}
+	if len(args) > 0 {
+		key.Key = args[0]
+	}
+	createdKey, err := activationkey.Create(client, &key)
+	if err != nil {
+		return err
+	}
+	utils.AddMachineData("key", createdKey)
+
+	if len(groupIds) > 0 {
+		if err := activationkey.AddServerGroups(client, createdKey, groupIds); err != nil {
+			return err
+		}
+	}
+	log.Info().Msgf(L("Activation key %s created"), createdKey)
+	return nil
+}
+
+func list(globalFlags *types.GlobalFlags, flags *akFlags, cmd *cobra.Command, args []string) error {
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	keys, err := activationkey.List(client)
+	if err != nil {
+		return err
+	}
+	return keysTable(keys).Print(flags.Output)
+}
+
+func keysTable(keys []apiTypes.ActivationKey) *utils.Table {
+	table := utils.Table{
+		Headers: []string{L("KEY"), L("DESCRIPTION"), L("BASE CHANNEL"), L("USAGE LIMIT"), L("DISABLED")},
+		Data:    keys,
+	}
+	for _, key := range keys {
+		limit := L("unlimited")
+		if key.UsageLimit > 0 {
+			limit = strconv.Itoa(key.UsageLimit)
+		}
+		table.Rows = append(table.Rows, []string{
+			key.Key, key.Description, key.BaseChannelLabel, limit, strconv.FormatBool(key.Disabled),
+		})
+	}
+	return &table
+}
+
+func remove(globalFlags *types.GlobalFlags, flags *akFlags, cmd *cobra.Command, args []string) error {
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	failed := []string{}
+	for _, key := range args {
+		if err := activationkey.Delete(client, key); err != nil {
+			log.Error().Err(err).Msgf(L("Failed to delete activation key %s"), key)
+			failed = append(failed, key)
+			continue
+		}
+		log.Info().Msgf(L("Activation key %s deleted"), key)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf(L("failed to delete activation keys: %s"), strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/uyuni-project/uyuni-tools/shared/api"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 func runPost(globalFlags *types.GlobalFlags, flags *apiFlags, cmd *cobra.Command, args []string) error {
@@ -22,7 +23,7 @@ func runPost(globalFlags *types.GlobalFlags, flags *apiFlags, cmd *cobra.Command
 	client, err := api.Init(&flags.ConnectionDetails)
 
 	if err != nil {
-		return fmt.Errorf(L("unable to login to the server: %s"), err)
+		return utils.Errorf(err, L("unable to login to the server: %s"))
 	}
 
 	path := args[0]
@@ -45,7 +46,7 @@ func runPost(globalFlags *types.GlobalFlags, flags *apiFlags, cmd *cobra.Command
 
 	res, err := api.Post[interface{}](client, path, data)
 	if err != nil {
-		return fmt.Errorf(L("error in query %s: %s"), path, err)
+		return utils.Errorf(err, L("error in query %s: %s"), path)
 	}
 
 	if !res.Success {
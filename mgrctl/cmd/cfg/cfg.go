@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cfg
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type cfgFlags struct {
+	api.ConnectionDetails `mapstructure:"api"`
+	Delete                bool
+	Dry                   struct {
+		Run bool
+	}
+}
+
+// NewCommand creates the command synchronizing configuration channels with local folders.
+func NewCommand(globalFlags *types.GlobalFlags) (*cobra.Command, error) {
+	cfgCmd := &cobra.Command{
+		Use:   "cfg",
+		Short: L("Configuration channels commands"),
+		Long: fmt.Sprintf(L(`Synchronize configuration channels with local folders.
+
+The files of the channel are stored in the folder using their full path.
+Their owner, group and permissions are stored in the %s file of the folder.
+The files missing in this file are pushed with root owner and group and their local permissions.
+The directories are only pushed if they are listed in it.`), metadataFile),
+	}
+
+	if err := api.AddAPIFlags(cfgCmd, false); err != nil {
+		return cfgCmd, err
+	}
+
+	pullCmd := &cobra.Command{
+		Use:     "pull channel directory",
+		Short:   L("Download the files of a configuration channel to a folder"),
+		Args:    cobra.ExactArgs(2),
+		Example: "  mgrctl cfg pull webservers ./webservers",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags cfgFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, pull)
+		},
+	}
+
+	pushCmd := &cobra.Command{
+		Use:     "push channel directory",
+		Short:   L("Upload the files of a folder to a configuration channel"),
+		Args:    cobra.ExactArgs(2),
+		Example: "  mgrctl cfg push --delete webservers ./webservers",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags cfgFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, push)
+		},
+	}
+	pushCmd.Flags().Bool("delete", false, L("remove the channel files that are not in the folder"))
+	pushCmd.Flags().Bool("dry-run", false, L("only show the changes without applying them"))
+
+	cfgCmd.AddCommand(pullCmd)
+	cfgCmd.AddCommand(pushCmd)
+
+	return cfgCmd, nil
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cfg
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func TestNewCommand(t *testing.T) {
+	var globalflags types.GlobalFlags
+	cmd, err := NewCommand(&globalflags)
+	if err != nil {
+		t.Errorf("Unexpected error creating command: %s", err)
+	}
+	if cmd == nil {
+		t.Error("Unexpected nil command")
+	}
+}
+
+func TestLocalRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	files := []apiTypes.ConfigFile{
+		{Type: "directory", Path: "/etc/app", Owner: "root", Group: "root", Permissions: "755"},
+		{
+			Type: "file", Path: "/etc/app/app.conf", Owner: "app", Group: "app", Permissions: "640",
+			Contents: base64.StdEncoding.EncodeToString([]byte("port=8080\n")), ContentsEnc64: true,
+		},
+		{Type: "file", Path: "/etc/motd", Owner: "root", Group: "root", Permissions: "644", Contents: "Welcome\n"},
+		{Type: "symlink", Path: "/etc/app/current.conf", TargetPath: "app.conf"},
+	}
+	for i := range files {
+		if err := writeLocalFile(dir, &files[i]); err != nil {
+			t.Fatalf("failed to write %s: %s", files[i].Path, err)
+		}
+	}
+	if err := writeMetadata(dir, files); err != nil {
+		t.Fatalf("failed to write metadata: %s", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "etc/app/app.conf"))
+	if err != nil || info.Mode().Perm() != 0640 {
+		t.Errorf("unexpected app.conf file mode: %v, %v", info, err)
+	}
+
+	localFiles, err := readLocalFiles(dir)
+	if err != nil {
+		t.Fatalf("failed to read local files: %s", err)
+	}
+	actual := map[string]apiTypes.ConfigFile{}
+	for _, file := range localFiles {
+		actual[file.Path] = file
+	}
+	if len(actual) != len(files) {
+		t.Fatalf("expected %d files, got %v", len(files), localFiles)
+	}
+
+	appConf := actual["/etc/app/app.conf"]
+	content, _ := base64.StdEncoding.DecodeString(appConf.Contents)
+	if string(content) != "port=8080\n" || appConf.Owner != "app" || appConf.Permissions != "640" {
+		t.Errorf("unexpected app.conf file: %v", appConf)
+	}
+	motd := actual["/etc/motd"]
+	content, _ = base64.StdEncoding.DecodeString(motd.Contents)
+	if string(content) != "Welcome\n" || motd.Type != "file" {
+		t.Errorf("unexpected motd file: %v", motd)
+	}
+	if link := actual["/etc/app/current.conf"]; link.Type != "symlink" || link.TargetPath != "app.conf" {
+		t.Errorf("unexpected symlink: %v", link)
+	}
+	if appDir := actual["/etc/app"]; appDir.Type != "directory" {
+		t.Errorf("unexpected directory: %v", appDir)
+	}
+}
+
+func TestRemovedPaths(t *testing.T) {
+	remote := []apiTypes.ConfigFile{{Path: "/etc/motd"}, {Path: "/etc/issue"}}
+	local := []apiTypes.ConfigFile{{Path: "/etc/motd"}, {Path: "/etc/hosts"}}
+	if actual := removedPaths(remote, local); !reflect.DeepEqual(actual, []string{"/etc/issue"}) {
+		t.Errorf("unexpected removed paths: %v", actual)
+	}
+}
@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cfg
+
+import (
+	"encoding/base64"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// metadataFile is the name of the file storing the attributes the local files cannot hold.
+const metadataFile = ".mgrctl-cfg.yaml"
+
+// fileMetadata is the part of a configuration file stored in the metadata file.
+type fileMetadata struct {
+	Owner       string `yaml:"owner"`
+	Group       string `yaml:"group"`
+	Permissions string `yaml:"permissions"`
+}
+
+func readMetadata(dir string) (map[string]fileMetadata, error) {
+	metadata := map[string]fileMetadata{}
+	data, err := os.ReadFile(filepath.Join(dir, metadataFile))
+	if os.IsNotExist(err) {
+		return metadata, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
+		return nil, utils.Errorf(err, L("failed to parse %[1]s: %[2]s"), metadataFile)
+	}
+	return metadata, nil
+}
+
+func writeMetadata(dir string, files []apiTypes.ConfigFile) error {
+	metadata := map[string]fileMetadata{}
+	for _, file := range files {
+		if file.Type == "symlink" {
+			continue
+		}
+		metadata[file.Path] = fileMetadata{Owner: file.Owner, Group: file.Group, Permissions: file.Permissions}
+	}
+	data, err := yaml.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, metadataFile), data, 0644)
+}
+
+// readLocalFiles returns the files of a folder as configuration files with their contents.
+//
+// Only the directories listed in the metadata file are returned.
+func readLocalFiles(dir string) ([]apiTypes.ConfigFile, error) {
+	metadata, err := readMetadata(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []apiTypes.ConfigFile{}
+	err = filepath.WalkDir(dir, func(localPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, localPath)
+		if err != nil {
+			return err
+		}
+		if relPath == "." || relPath == metadataFile {
+			return nil
+		}
+		if relPath == ".git" {
+			return filepath.SkipDir
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		file := apiTypes.ConfigFile{
+			Path:        "/" + filepath.ToSlash(relPath),
+			Owner:       "root",
+			Group:       "root",
+			Permissions: strconv.FormatUint(uint64(info.Mode().Perm()), 8),
+		}
+		meta, found := metadata[file.Path]
+		if found {
+			file.Owner = meta.Owner
+			file.Group = meta.Group
+			file.Permissions = meta.Permissions
+		}
+
+		switch {
+		case entry.IsDir():
+			// Parent folders like /etc are not managed unless they are in the metadata
+			if !found {
+				return nil
+			}
+			file.Type = "directory"
+		case info.Mode()&fs.ModeSymlink != 0:
+			file.Type = "symlink"
+			if file.TargetPath, err = os.Readlink(localPath); err != nil {
+				return err
+			}
+		default:
+			file.Type = "file"
+			content, err := os.ReadFile(localPath)
+			if err != nil {
+				return err
+			}
+			file.Contents = base64.StdEncoding.EncodeToString(content)
+			file.ContentsEnc64 = true
+		}
+		files = append(files, file)
+		return nil
+	})
+	return files, err
+}
+
+// writeLocalFile writes a configuration file in the folder.
+func writeLocalFile(dir string, file *apiTypes.ConfigFile) error {
+	localPath := filepath.Join(dir, filepath.FromSlash(file.Path))
+	mode := os.FileMode(0644)
+	if perms, err := strconv.ParseUint(file.Permissions, 8, 32); err == nil {
+		mode = os.FileMode(perms)
+	}
+
+	switch file.Type {
+	case "directory":
+		return os.MkdirAll(localPath, 0755)
+	case "symlink":
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+			return err
+		}
+		if err := os.Remove(localPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return os.Symlink(file.TargetPath, localPath)
+	default:
+		content := []byte(file.Contents)
+		if file.ContentsEnc64 {
+			decoded, err := base64.StdEncoding.DecodeString(file.Contents)
+			if err != nil {
+				return utils.Errorf(err, L("failed to decode the content of %[1]s: %[2]s"), file.Path)
+			}
+			content = decoded
+		}
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(localPath, content, mode); err != nil {
+			return err
+		}
+		// WriteFile does not change the mode of an existing file
+		return os.Chmod(localPath, mode)
+	}
+}
@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cfg
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/configchannel"
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func pull(globalFlags *types.GlobalFlags, flags *cfgFlags, cmd *cobra.Command, args []string) error {
+	channel := args[0]
+	dir := args[1]
+
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	list, err := configchannel.ListFiles(client, channel)
+	if err != nil {
+		return err
+	}
+	paths := []string{}
+	for _, file := range list {
+		paths = append(paths, file.Path)
+	}
+
+	files := []apiTypes.ConfigFile{}
+	if len(paths) > 0 {
+		if files, err = configchannel.LookupFiles(client, channel, paths); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return utils.Errorf(err, L("failed to create folder %[1]s: %[2]s"), dir)
+	}
+	for i := range files {
+		log.Debug().Msgf("Writing %s", files[i].Path)
+		if err := writeLocalFile(dir, &files[i]); err != nil {
+			return utils.Errorf(err, L("failed to write %[1]s: %[2]s"), files[i].Path)
+		}
+	}
+	if err := writeMetadata(dir, files); err != nil {
+		return utils.Errorf(err, L("failed to write %[1]s: %[2]s"), metadataFile)
+	}
+	log.Info().Msgf(L("%[1]d files of configuration channel %[2]s written to %[3]s"), len(files), channel, dir)
+	return nil
+}
+
+func push(globalFlags *types.GlobalFlags, flags *cfgFlags, cmd *cobra.Command, args []string) error {
+	channel := args[0]
+	dir := args[1]
+
+	files, err := readLocalFiles(dir)
+	if err != nil {
+		return utils.Errorf(err, L("failed to read the files of %[1]s: %[2]s"), dir)
+	}
+
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	toDelete := []string{}
+	if flags.Delete {
+		remoteFiles, err := configchannel.ListFiles(client, channel)
+		if err != nil {
+			return err
+		}
+		toDelete = removedPaths(remoteFiles, files)
+	}
+
+	if flags.Dry.Run {
+		utils.SetMachineChanged(false)
+	}
+	for i, file := range files {
+		if flags.Dry.Run {
+			fmt.Printf(L("Would update %s")+"\n", file.Path)
+			continue
+		}
+		log.Info().Msgf(L("Updating %s"), file.Path)
+		if file.Type == "symlink" {
+			err = configchannel.CreateOrUpdateSymlink(client, channel, &files[i])
+		} else {
+			err = configchannel.CreateOrUpdatePath(client, channel, &files[i])
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(toDelete) > 0 {
+		if flags.Dry.Run {
+			for _, path := range toDelete {
+				fmt.Printf(L("Would delete %s")+"\n", path)
+			}
+			return nil
+		}
+		log.Info().Msgf(L("Deleting %d files not found locally"), len(toDelete))
+		if err := configchannel.DeleteFiles(client, channel, toDelete); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removedPaths returns the paths of the remote files missing in the local files.
+func removedPaths(remoteFiles []apiTypes.ConfigFile, localFiles []apiTypes.ConfigFile) []string {
+	local := map[string]bool{}
+	for _, file := range localFiles {
+		local[file.Path] = true
+	}
+	removed := []string{}
+	for _, file := range remoteFiles {
+		if !local[file.Path] {
+			removed = append(removed, file.Path)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import (
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// NewCommand to run diagnostics against the server.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: L("Diagnose the connectivity to the server"),
+		Long:  L("Diagnose the connectivity to the server"),
+	}
+	checkCmd.AddCommand(newClientCommand(globalFlags))
+	return checkCmd
+}
@@ -0,0 +1,228 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type clientFlags struct {
+	Server        string
+	Timeout       time.Duration
+	BootstrapRepo string
+}
+
+const osReleasePath = "/etc/os-release"
+
+// saltPorts are the ports the salt minions connect to.
+var saltPorts = []int{4505, 4506}
+
+func newClientCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "client",
+		Short: L("Check the connectivity of a client to the server"),
+		Long: L(`Check the connectivity of a client to the server
+
+This command is meant to be run on a prospective client host to validate the environment before
+bootstrapping it. The following checks are performed:
+  - salt ports 4505 and 4506 are reachable,
+  - the CA certificate can be downloaded from the server,
+  - HTTPS connection to the server is trusted with the downloaded CA certificate,
+  - the bootstrap repository for the client distribution is available.
+
+The bootstrap repository is guessed from the /etc/os-release file if not provided.`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags clientFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, checkClient)
+		},
+	}
+	cmd.Flags().String("server", "", L("Fully qualified domain name of the server"))
+	cmd.Flags().Duration("timeout", 10*time.Second, L("Timeout of each connection attempt"))
+	cmd.Flags().String("bootstrap-repo", "",
+		L("Path of the bootstrap repository relative to /pub/repositories, for example sle/15/5/bootstrap"))
+	_ = cmd.MarkFlagRequired("server")
+	return cmd
+}
+
+func checkClient(globalFlags *types.GlobalFlags, flags *clientFlags, cmd *cobra.Command, args []string) error {
+	repoPath := flags.BootstrapRepo
+	if repoPath == "" {
+		repoPath = guessBootstrapRepo()
+	}
+
+	caCheck, pool := caDownloadCheck("http://"+flags.Server+"/pub/RHN-ORG-TRUSTED-SSL-CERT", flags.Timeout)
+	checks := []utils.CheckResult{
+		utils.PortsCheck(L("Salt ports"), flags.Server, saltPorts, flags.Timeout),
+		caCheck,
+		httpsCheck("https://"+flags.Server+"/", pool, flags.Timeout),
+		bootstrapRepoCheck("https://"+flags.Server+"/pub/repositories", repoPath, pool, flags.Timeout),
+	}
+	return utils.ReportChecks(os.Stdout, checks, L("some of the client checks failed"))
+}
+
+// httpClient returns a client trusting the given CA certificates, or the system ones if nil.
+func httpClient(pool *x509.CertPool, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+		Timeout: timeout,
+	}
+}
+
+// fetch downloads a document and fails if the server doesn't answer with a success status.
+func fetch(client *http.Client, url string) ([]byte, error) {
+	res, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(L("unexpected status %d"), res.StatusCode)
+	}
+	return io.ReadAll(res.Body)
+}
+
+// caDownloadCheck downloads the server CA certificate and returns it as a pool for the HTTPS checks.
+func caDownloadCheck(url string, timeout time.Duration) (utils.CheckResult, *x509.CertPool) {
+	check := utils.CheckResult{Name: L("CA certificate")}
+	data, err := fetch(httpClient(nil, timeout), url)
+	if err != nil {
+		check.Detail = fmt.Sprintf(L("failed to download %[1]s: %[2]s"), url, err)
+		return check, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		check.Detail = fmt.Sprintf(L("%s is not a valid PEM certificate"), url)
+		return check, nil
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("downloaded from %s"), url)
+	return check, pool
+}
+
+// httpsCheck verifies that the server certificate is trusted by the CA.
+func httpsCheck(url string, pool *x509.CertPool, timeout time.Duration) utils.CheckResult {
+	check := utils.CheckResult{Name: L("HTTPS")}
+	if pool == nil {
+		check.Detail = L("no CA certificate to validate the server certificate")
+		return check
+	}
+	res, err := httpClient(pool, timeout).Head(url)
+	if err != nil {
+		check.Detail = fmt.Sprintf(L("%[1]s is not reachable: %[2]s"), url, err)
+		return check
+	}
+	res.Body.Close()
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("%s is reachable with a trusted certificate"), url)
+	return check
+}
+
+// bootstrapRepoCheck verifies that the repository metadata can be downloaded.
+func bootstrapRepoCheck(baseURL string, repoPath string, pool *x509.CertPool, timeout time.Duration) utils.CheckResult {
+	check := utils.CheckResult{Name: L("Bootstrap repository")}
+	if repoPath == "" {
+		check.Detail = L("unknown client distribution, use the --bootstrap-repo parameter")
+		return check
+	}
+	if pool == nil {
+		check.Detail = L("no CA certificate to validate the server certificate")
+		return check
+	}
+
+	// Debian-like bootstrap repositories are flat ones
+	metadata := "repodata/repomd.xml"
+	if strings.HasPrefix(repoPath, "ubuntu/") || strings.HasPrefix(repoPath, "debian/") {
+		metadata = "Release"
+	}
+	url := strings.TrimSuffix(baseURL, "/") + "/" + strings.Trim(repoPath, "/") + "/" + metadata
+	if _, err := fetch(httpClient(pool, timeout), url); err != nil {
+		check.Detail = fmt.Sprintf(L("failed to download %[1]s: %[2]s"), url, err)
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf(L("%s is available"), url)
+	return check
+}
+
+// guessBootstrapRepo computes the bootstrap repository path of the host distribution.
+func guessBootstrapRepo() string {
+	file, err := os.Open(osReleasePath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	values := parseOsRelease(bufio.NewScanner(file))
+	repoPath, err := bootstrapRepoPath(values["ID"], values["VERSION_ID"])
+	if err != nil {
+		return ""
+	}
+	return repoPath
+}
+
+// parseOsRelease parses the KEY="value" lines of the os-release file.
+func parseOsRelease(scanner *bufio.Scanner) map[string]string {
+	values := map[string]string{}
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, found := strings.Cut(line, "="); found {
+			values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return values
+}
+
+// bootstrapRepoPath returns the path of the bootstrap repository as created by mgr-create-bootstrap-repo.
+func bootstrapRepoPath(id string, version string) (string, error) {
+	major, minor, _ := strings.Cut(version, ".")
+	if minor == "" {
+		minor = "0"
+	}
+	// Ubuntu minor versions are zero-padded in os-release
+	ubuntuMinor := strings.TrimLeft(minor, "0")
+	if ubuntuMinor == "" {
+		ubuntuMinor = "0"
+	}
+
+	switch id {
+	case "sles", "sled", "sles_sap":
+		return fmt.Sprintf("sle/%s/%s/bootstrap", major, minor), nil
+	case "opensuse-leap":
+		return fmt.Sprintf("opensuse/leap/%s/%s/bootstrap", major, minor), nil
+	case "rhel":
+		return fmt.Sprintf("res/%s/bootstrap", major), nil
+	case "centos", "almalinux":
+		return fmt.Sprintf("%s/%s/bootstrap", id, major), nil
+	case "rocky":
+		return fmt.Sprintf("rockylinux/%s/bootstrap", major), nil
+	case "ol":
+		return fmt.Sprintf("oracle/%s/bootstrap", major), nil
+	case "ubuntu":
+		return fmt.Sprintf("ubuntu/%s/%s/bootstrap", major, ubuntuMinor), nil
+	case "debian":
+		return fmt.Sprintf("debian/%s/bootstrap", major), nil
+	}
+	return "", errors.New(L("unsupported distribution"))
+}
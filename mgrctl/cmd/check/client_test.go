@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import (
+	"bufio"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBootstrapRepoPath(t *testing.T) {
+	data := [][]string{
+		{"sles", "15.5", "sle/15/5/bootstrap"},
+		{"sles", "15", "sle/15/0/bootstrap"},
+		{"opensuse-leap", "15.6", "opensuse/leap/15/6/bootstrap"},
+		{"rhel", "9.2", "res/9/bootstrap"},
+		{"rocky", "8.9", "rockylinux/8/bootstrap"},
+		{"almalinux", "9.3", "almalinux/9/bootstrap"},
+		{"ol", "8.9", "oracle/8/bootstrap"},
+		{"ubuntu", "22.04", "ubuntu/22/4/bootstrap"},
+		{"ubuntu", "20.10", "ubuntu/20/10/bootstrap"},
+		{"debian", "12", "debian/12/bootstrap"},
+	}
+	for i, testCase := range data {
+		actual, err := bootstrapRepoPath(testCase[0], testCase[1])
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %s", i, err)
+		}
+		if actual != testCase[2] {
+			t.Errorf("case %d: expected %s, got %s", i, testCase[2], actual)
+		}
+	}
+	if _, err := bootstrapRepoPath("unknown", "1"); err == nil {
+		t.Error("expected an error for an unsupported distribution")
+	}
+}
+
+func TestParseOsRelease(t *testing.T) {
+	content := `NAME="SLES"
+# comment
+VERSION_ID="15.5"
+ID=sles
+`
+	values := parseOsRelease(bufio.NewScanner(strings.NewReader(content)))
+	if values["ID"] != "sles" || values["VERSION_ID"] != "15.5" {
+		t.Errorf("unexpected values: %v", values)
+	}
+}
+
+func TestServerChecks(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/pub/repositories/sle/15/5/bootstrap/repodata/repomd.xml" || r.URL.Path == "/" {
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	caServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pub/RHN-ORG-TRUSTED-SSL-CERT" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(caPEM)
+	}))
+	defer caServer.Close()
+
+	timeout := 5 * time.Second
+	if check, _ := caDownloadCheck(caServer.URL+"/missing", timeout); check.OK {
+		t.Error("expected a failure for a missing CA certificate")
+	}
+	check, pool := caDownloadCheck(caServer.URL+"/pub/RHN-ORG-TRUSTED-SSL-CERT", timeout)
+	if !check.OK || pool == nil {
+		t.Fatalf("expected the CA certificate to be downloaded: %s", check.Detail)
+	}
+
+	if check := httpsCheck(server.URL+"/", pool, timeout); !check.OK {
+		t.Errorf("expected a trusted HTTPS connection: %s", check.Detail)
+	}
+	if check := httpsCheck(server.URL+"/", nil, timeout); check.OK {
+		t.Error("expected a failure without CA certificate")
+	}
+
+	baseURL := server.URL + "/pub/repositories"
+	if check := bootstrapRepoCheck(baseURL, "sle/15/5/bootstrap", pool, timeout); !check.OK {
+		t.Errorf("expected the bootstrap repository to be available: %s", check.Detail)
+	}
+	if check := bootstrapRepoCheck(baseURL, "ubuntu/22/4/bootstrap", pool, timeout); check.OK {
+		t.Error("expected a failure for a missing bootstrap repository")
+	}
+	if check := bootstrapRepoCheck(baseURL, "", pool, timeout); check.OK {
+		t.Error("expected a failure without bootstrap repository path")
+	}
+}
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package clm
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type projectFlags struct {
+	api.ConnectionDetails `mapstructure:"api"`
+	utils.OutputFlags     `mapstructure:",squash"`
+	Message               string
+	Wait                  bool
+	Timeout               time.Duration
+}
+
+// NewCommand creates the content lifecycle management command.
+func NewCommand(globalFlags *types.GlobalFlags) (*cobra.Command, error) {
+	clmCmd := &cobra.Command{
+		Use:   "clm",
+		Short: L("Content lifecycle management commands"),
+	}
+
+	if err := api.AddAPIFlags(clmCmd, false); err != nil {
+		return clmCmd, err
+	}
+
+	projectCmd := &cobra.Command{
+		Use:   "project",
+		Short: L("Content lifecycle management projects commands"),
+	}
+
+	listCmd := &cobra.Command{
+		Use:         "list [project]",
+		Short:       L("List the projects or the environments of a project"),
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{utils.ReadOnlyAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags projectFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, list)
+		},
+	}
+	utils.AddOutputFlag(listCmd)
+
+	buildCmd := &cobra.Command{
+		Use:   "build project",
+		Short: L("Build a project into its first environment"),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags projectFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, build)
+		},
+	}
+	buildCmd.Flags().String("message", "", L("message describing the build"))
+	addWaitFlags(buildCmd)
+
+	promoteCmd := &cobra.Command{
+		Use:   "promote project environment",
+		Short: L("Promote an environment of a project to the next one"),
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags projectFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, promote)
+		},
+	}
+	addWaitFlags(promoteCmd)
+
+	projectCmd.AddCommand(listCmd)
+	projectCmd.AddCommand(buildCmd)
+	projectCmd.AddCommand(promoteCmd)
+	clmCmd.AddCommand(projectCmd)
+
+	return clmCmd, nil
+}
+
+func addWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("wait", false, L("wait for the target environment to be built"))
+	cmd.Flags().Duration("timeout", time.Hour, L("maximum duration to wait for the target environment to be built"))
+}
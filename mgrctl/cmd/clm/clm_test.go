@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package clm
+
+import (
+	"testing"
+
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func TestNewCommand(t *testing.T) {
+	var globalflags types.GlobalFlags
+	cmd, err := NewCommand(&globalflags)
+	if err != nil {
+		t.Errorf("Unexpected error creating command: %s", err)
+	}
+	if cmd == nil {
+		t.Error("Unexpected nil command")
+	}
+}
+
+func TestEnvironments(t *testing.T) {
+	environments := []apiTypes.ContentEnvironment{
+		{Label: "prod", PreviousEnvironmentLabel: "test"},
+		{Label: "dev", NextEnvironmentLabel: "test"},
+		{Label: "test", PreviousEnvironmentLabel: "dev", NextEnvironmentLabel: "prod"},
+	}
+
+	if first := firstEnvironment(environments); first == nil || first.Label != "dev" {
+		t.Errorf("expected dev first environment, got %v", first)
+	}
+	if next, err := nextEnvironment(environments, "dev"); err != nil || next.Label != "test" {
+		t.Errorf("expected test next environment, got %v, %v", next, err)
+	}
+	if _, err := nextEnvironment(environments, "prod"); err == nil {
+		t.Error("expected an error when promoting the last environment")
+	}
+	if _, err := nextEnvironment(environments, "qa"); err == nil {
+		t.Error("expected an error for an unknown environment")
+	}
+}
+
+func TestBuildDone(t *testing.T) {
+	before := apiTypes.ContentEnvironment{Label: "dev", Version: 3, Status: "built"}
+	data := []struct {
+		env     apiTypes.ContentEnvironment
+		done    bool
+		failure bool
+	}{
+		{apiTypes.ContentEnvironment{Label: "dev", Version: 3, Status: "built"}, false, false},
+		{apiTypes.ContentEnvironment{Label: "dev", Version: 4, Status: "building"}, false, false},
+		{apiTypes.ContentEnvironment{Label: "dev", Version: 4, Status: "built"}, true, false},
+		{apiTypes.ContentEnvironment{Label: "dev", Version: 4, Status: "failed"}, true, true},
+	}
+	for i, test := range data {
+		done, err := buildDone(&before, &test.env)
+		if done != test.done || (err != nil) != test.failure {
+			t.Errorf("test case %d: expected %v / %v, got %v / %v", i, test.done, test.failure, done, err)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package clm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/contentmanagement"
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// pollInterval is the delay between two checks of the environment status.
+var pollInterval = 10 * time.Second
+
+func list(globalFlags *types.GlobalFlags, flags *projectFlags, cmd *cobra.Command, args []string) error {
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	if len(args) == 0 {
+		projects, err := contentmanagement.ListProjects(client)
+		if err != nil {
+			return err
+		}
+		table := utils.Table{
+			Headers: []string{L("LABEL"), L("NAME"), L("LAST BUILD"), L("DESCRIPTION")},
+			Data:    projects,
+		}
+		for _, project := range projects {
+			table.Rows = append(table.Rows,
+				[]string{project.Label, project.Name, project.LastBuildDate, project.Description})
+		}
+		return table.Print(flags.Output)
+	}
+
+	environments, err := contentmanagement.ListEnvironments(client, args[0])
+	if err != nil {
+		return err
+	}
+	table := utils.Table{
+		Headers: []string{L("LABEL"), L("NAME"), L("VERSION"), L("STATUS"), L("LAST BUILD")},
+		Data:    environments,
+	}
+	for _, env := range environments {
+		table.Rows = append(table.Rows,
+			[]string{env.Label, env.Name, strconv.Itoa(env.Version), env.Status, env.LastBuildDate})
+	}
+	return table.Print(flags.Output)
+}
+
+func build(globalFlags *types.GlobalFlags, flags *projectFlags, cmd *cobra.Command, args []string) error {
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+	project := args[0]
+
+	environments, err := contentmanagement.ListEnvironments(client, project)
+	if err != nil {
+		return err
+	}
+	target := firstEnvironment(environments)
+	if target == nil {
+		return fmt.Errorf(L("project %s has no environment to build"), project)
+	}
+
+	if err := contentmanagement.BuildProject(client, project, flags.Message); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Build of project %[1]s into environment %[2]s started"), project, target.Label)
+
+	if flags.Wait {
+		return waitForEnvironment(client, project, target, flags.Timeout)
+	}
+	return nil
+}
+
+func promote(globalFlags *types.GlobalFlags, flags *projectFlags, cmd *cobra.Command, args []string) error {
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+	project := args[0]
+
+	environments, err := contentmanagement.ListEnvironments(client, project)
+	if err != nil {
+		return err
+	}
+	target, err := nextEnvironment(environments, args[1])
+	if err != nil {
+		return err
+	}
+
+	if err := contentmanagement.PromoteProject(client, project, args[1]); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Promotion of environment %[1]s to %[2]s started"), args[1], target.Label)
+
+	if flags.Wait {
+		return waitForEnvironment(client, project, target, flags.Timeout)
+	}
+	return nil
+}
+
+// firstEnvironment returns the environment the project is built into or nil if there is none.
+func firstEnvironment(environments []apiTypes.ContentEnvironment) *apiTypes.ContentEnvironment {
+	for i, env := range environments {
+		if env.PreviousEnvironmentLabel == "" {
+			return &environments[i]
+		}
+	}
+	return nil
+}
+
+// nextEnvironment returns the environment an environment is promoted to.
+func nextEnvironment(environments []apiTypes.ContentEnvironment, label string) (*apiTypes.ContentEnvironment, error) {
+	for _, env := range environments {
+		if env.Label != label {
+			continue
+		}
+		if env.NextEnvironmentLabel == "" {
+			return nil, fmt.Errorf(L("environment %s is the last one and cannot be promoted"), label)
+		}
+		for i, next := range environments {
+			if next.Label == env.NextEnvironmentLabel {
+				return &environments[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf(L("no environment %s found"), label)
+}
+
+// buildDone tells whether the build of an environment is finished, comparing with its state before the build.
+func buildDone(before *apiTypes.ContentEnvironment, env *apiTypes.ContentEnvironment) (bool, error) {
+	// The version changes when the build starts, the status may still be the one of the previous build before
+	if env.Version == before.Version && env.Status == before.Status {
+		return false, nil
+	}
+	switch env.Status {
+	case "built":
+		return true, nil
+	case "failed":
+		return true, fmt.Errorf(L("build of environment %s failed"), env.Label)
+	}
+	return false, nil
+}
+
+func waitForEnvironment(
+	client *api.HTTPClient,
+	project string,
+	before *apiTypes.ContentEnvironment,
+	timeout time.Duration,
+) error {
+	log.Info().Msgf(L("Waiting for environment %s to be built"), before.Label)
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(pollInterval)
+		environments, err := contentmanagement.ListEnvironments(client, project)
+		if err != nil {
+			return err
+		}
+		for _, env := range environments {
+			if env.Label != before.Label {
+				continue
+			}
+			log.Debug().Msgf("Environment %s status: %s, version %d", env.Label, env.Status, env.Version)
+			if done, err := buildDone(before, &env); done {
+				if err == nil {
+					log.Info().Msgf(L("Environment %[1]s built with version %[2]d"), env.Label, env.Version)
+				}
+				return err
+			}
+		}
+	}
+	return errors.New(L("timeout waiting for the environment to be built"))
+}
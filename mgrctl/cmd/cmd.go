@@ -10,13 +10,26 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/action"
+	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/ak"
 	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/api"
+	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/cfg"
+	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/check"
+	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/clm"
 	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/cp"
 	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/exec"
+	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/group"
 	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/org"
+	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/portforward"
+	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/report"
+	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/system"
+	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/task"
 	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/term"
 	"github.com/uyuni-project/uyuni-tools/shared/completion"
+	"github.com/uyuni-project/uyuni-tools/shared/docs"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
@@ -24,6 +37,8 @@ import (
 // NewCommand returns a new cobra.Command implementing the root command for kinder.
 func NewUyunictlCommand() (*cobra.Command, error) {
 	globalFlags := &types.GlobalFlags{}
+	var remoteFlags podman.RemoteFlags
+	var clusterFlags kubernetes.ClusterFlags
 	name := path.Base(os.Args[0])
 	rootCmd := &cobra.Command{
 		Use:          name,
@@ -33,14 +48,37 @@ func NewUyunictlCommand() (*cobra.Command, error) {
 		SilenceUsage: true, // Don't show usage help on errors
 	}
 
+	utils.SetEnvPrefix("MGRCTL")
 	rootCmd.SetUsageTemplate(utils.GetLocalizedUsageTemplate())
 
 	rootCmd.PersistentFlags().StringVarP(&globalFlags.ConfigPath, "config", "c", "", L("configuration file path"))
 	rootCmd.PersistentFlags().StringVar(&globalFlags.LogLevel, "logLevel", "", L("application log level")+"(trace|debug|info|warn|error|fatal|panic)")
+	rootCmd.PersistentFlags().StringVar(&globalFlags.LogFile, "logfile", "", L("path to the log file recording the executed commands. Defaults to /var/log/uyuni-tools.log or $HOME/uyuni-tools.log"))
+	utils.AddMachineFlag(rootCmd, &globalFlags.Machine)
+	utils.AddLangFlag(rootCmd)
+	utils.AddProfileFlag(rootCmd)
+	podman.AddRemoteFlags(rootCmd, &remoteFlags)
+	if utils.KubernetesBuilt {
+		kubernetes.AddClusterFlags(rootCmd, &clusterFlags)
+	}
 
 	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
-		utils.LogInit(cmd.Name() != "exec" && cmd.Name() != "term")
+		if err := utils.ApplyEnvToGlobalFlags(cmd); err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to read the global flags from the environment"))
+		}
+		if err := utils.ApplyProfileToGlobalFlags(cmd); err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to apply the configuration profile"))
+		}
+		utils.LogInit(cmd.Name() != "exec" && cmd.Name() != "term" && !globalFlags.Machine, globalFlags.LogFile)
 		utils.SetLogLevel(globalFlags.LogLevel)
+		if globalFlags.Machine {
+			utils.EnableMachineOutput(cmd)
+		}
+
+		if err := podman.SetRemote(cmd, &remoteFlags); err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to configure the remote podman host"))
+		}
+		kubernetes.SetCluster(&clusterFlags)
 
 		// do not log if running the completion cmd as the output is redirect to create a file to source
 		if cmd.Name() != "completion" {
@@ -49,20 +87,59 @@ func NewUyunictlCommand() (*cobra.Command, error) {
 		}
 	}
 
+	actionCmd, err := action.NewCommand(globalFlags)
+	if err != nil {
+		log.Err(err).Msg(L("Failed to create action command"))
+	}
+	rootCmd.AddCommand(actionCmd)
+	akCmd, err := ak.NewCommand(globalFlags)
+	if err != nil {
+		log.Err(err).Msg(L("Failed to create ak command"))
+	}
+	rootCmd.AddCommand(akCmd)
 	apiCmd, err := api.NewCommand(globalFlags)
 	if err != nil {
 		log.Err(err).Msg(L("Failed to create api command"))
 	}
 	rootCmd.AddCommand(apiCmd)
+	cfgCmd, err := cfg.NewCommand(globalFlags)
+	if err != nil {
+		log.Err(err).Msg(L("Failed to create cfg command"))
+	}
+	rootCmd.AddCommand(cfgCmd)
+	clmCmd, err := clm.NewCommand(globalFlags)
+	if err != nil {
+		log.Err(err).Msg(L("Failed to create clm command"))
+	}
+	rootCmd.AddCommand(clmCmd)
 	rootCmd.AddCommand(exec.NewCommand(globalFlags))
 	rootCmd.AddCommand(term.NewCommand(globalFlags))
 	rootCmd.AddCommand(cp.NewCommand(globalFlags))
+	rootCmd.AddCommand(portforward.NewCommand(globalFlags))
+	rootCmd.AddCommand(check.NewCommand(globalFlags))
+	rootCmd.AddCommand(report.NewCommand(globalFlags))
 	rootCmd.AddCommand(completion.NewCommand(globalFlags))
+	rootCmd.AddCommand(docs.NewCommand(globalFlags))
 	orgCmd, err := org.NewCommand(globalFlags)
 	if err != nil {
 		log.Err(err).Msg(L("Failed to create org command"))
 	}
 	rootCmd.AddCommand(orgCmd)
+	groupCmd, err := group.NewCommand(globalFlags)
+	if err != nil {
+		log.Err(err).Msg(L("Failed to create group command"))
+	}
+	rootCmd.AddCommand(groupCmd)
+	systemCmd, err := system.NewCommand(globalFlags)
+	if err != nil {
+		log.Err(err).Msg(L("Failed to create system command"))
+	}
+	rootCmd.AddCommand(systemCmd)
+	taskCmd, err := task.NewCommand(globalFlags)
+	if err != nil {
+		log.Err(err).Msg(L("Failed to create task command"))
+	}
+	rootCmd.AddCommand(taskCmd)
 
 	rootCmd.AddCommand(utils.GetConfigHelpCommand())
 
@@ -5,7 +5,8 @@
 package cp
 
 import (
-	"fmt"
+	"path"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/shared"
@@ -17,9 +18,11 @@ import (
 )
 
 type flagpole struct {
-	User    string
-	Group   string
-	Backend string
+	User      string
+	Group     string
+	Archive   bool
+	Backend   string
+	Namespace string
 }
 
 // NewCommand copy file to and from the containers.
@@ -27,18 +30,23 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	flags := &flagpole{}
 
 	cpCmd := &cobra.Command{
-		Use:   "cp [path/to/source.file] [path/to/destination.file]",
+		Use:   "cp [path/to/source] [path/to/destination]",
 		Short: L("Copy files to and from the containers"),
 		Long: L(`Takes a source and destination parameters.
-	One of them can be prefixed with 'server:' to indicate the path is within the server pod.`),
-		Args: cobra.ExactArgs(2),
+	One of them can be prefixed with 'server:' to indicate the path is within the server pod.
+
+	The source can be a file, a directory copied recursively or a glob pattern like 'server:/etc/rhn/*.conf'.
+	If the pattern matches several files, the destination needs to be an existing directory.
+	Quote the patterns to prevent the local shell from expanding them.`),
+		Args:        cobra.ExactArgs(2),
+		Annotations: map[string]string{podman.RemoteAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			viper, err := utils.ReadConfig(globalFlags.ConfigPath, cmd)
 			if err != nil {
 				return err
 			}
 			if err := viper.Unmarshal(&flags); err != nil {
-				return fmt.Errorf(L("failed to unmarshall configuration")+": %s", err)
+				return utils.Errorf(err, L("failed to unmarshall configuration")+": %s")
 			}
 			return run(flags, cmd, args)
 		},
@@ -46,12 +54,21 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 
 	cpCmd.Flags().String("user", "", L("User or UID to set on the destination file"))
 	cpCmd.Flags().String("group", "susemanager", L("Group or GID to set on the destination file"))
+	cpCmd.Flags().BoolP("archive", "a", false,
+		L("Preserve the owners and modification times of the files. The local owners are only preserved when running as root"))
 
 	utils.AddBackendFlag(cpCmd)
+	utils.AddNamespaceFlag(cpCmd)
 	return cpCmd
 }
 
 func run(flags *flagpole, cmd *cobra.Command, args []string) error {
-	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter)
-	return cnx.Copy(args[0], args[1], flags.User, flags.Group)
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
+	options := shared.CopyOptions{
+		User:     flags.User,
+		Group:    flags.Group,
+		Archive:  flags.Archive,
+		Progress: shared.LogCopyProgress(path.Base(strings.TrimPrefix(args[0], "server:"))),
+	}
+	return cnx.CopyWithOptions(args[0], args[1], options)
 }
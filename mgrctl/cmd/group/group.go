@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package group
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/system"
+	"github.com/uyuni-project/uyuni-tools/shared/api/systemgroup"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type groupFlags struct {
+	api.ConnectionDetails `mapstructure:"api"`
+	Description           string
+	Systems               string
+}
+
+// NewCommand creates the command managing the system groups.
+func NewCommand(globalFlags *types.GlobalFlags) (*cobra.Command, error) {
+	groupCmd := &cobra.Command{
+		Use:   "group",
+		Short: L("System groups commands"),
+	}
+
+	if err := api.AddAPIFlags(groupCmd, false); err != nil {
+		return groupCmd, err
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create name",
+		Short: L("Create a system group"),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags groupFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, create)
+		},
+	}
+	createCmd.Flags().String("description", "", L("description of the system group. Defaults to the name"))
+
+	addSystemsCmd := &cobra.Command{
+		Use:   "add-systems name",
+		Short: L("Add systems to a system group"),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags groupFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, addSystems)
+		},
+	}
+	addSystemsCmd.Flags().String("systems", "", L("systems to add: ")+system.SelectorHelp)
+	if err := addSystemsCmd.MarkFlagRequired("systems"); err != nil {
+		return groupCmd, err
+	}
+
+	groupCmd.AddCommand(createCmd)
+	groupCmd.AddCommand(addSystemsCmd)
+
+	return groupCmd, nil
+}
+
+func create(globalFlags *types.GlobalFlags, flags *groupFlags, cmd *cobra.Command, args []string) error {
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	// The description is mandatory for the API
+	description := flags.Description
+	if description == "" {
+		description = args[0]
+	}
+	group, err := systemgroup.Create(client, args[0], description)
+	if err != nil {
+		return err
+	}
+	utils.AddMachineData("group", group)
+	log.Info().Msgf(L("System group %[1]s created with id %[2]d"), group.Name, group.Id)
+	return nil
+}
+
+func addSystems(globalFlags *types.GlobalFlags, flags *groupFlags, cmd *cobra.Command, args []string) error {
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	systems, err := system.Select(client, flags.Systems)
+	if err != nil {
+		return err
+	}
+	if len(systems) == 0 {
+		return fmt.Errorf(L("no system matches %s"), flags.Systems)
+	}
+	if err := systemgroup.AddSystems(client, args[0], system.Ids(systems)); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("%[1]d systems added to system group %[2]s"), len(systems), args[0])
+	return nil
+}
@@ -0,0 +1,204 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package portforward
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type portForwardFlags struct {
+	Address   string
+	Backend   string
+	Namespace string
+}
+
+// portMapping is a local port forwarded to a port of the server container.
+type portMapping struct {
+	Local     int
+	Container int
+}
+
+// NewCommand returns a new cobra.Command for port-forward.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "port-forward <port>[:containerport]",
+		Short: L("Forward a local port to the server container"),
+		Long: L(`Forward a local port to the server container
+
+This gives access to the ports of the server container which are not exposed, like
+the PostgreSQL database or the debug ports, without exposing them publicly.
+If the container port is not set, it is the same as the local one.
+
+On kubernetes, kubectl port-forward is used. On podman, the connections are forwarded to
+the container IP address, through an SSH tunnel for a remote podman host.
+
+The forwarding runs until interrupted.
+
+Example:
+  mgrctl port-forward 15432:5432
+`),
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{podman.RemoteAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags portForwardFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, run)
+		},
+	}
+	cmd.Flags().String("address", "127.0.0.1", L("Local address to listen on"))
+	utils.AddBackendFlag(cmd)
+	utils.AddNamespaceFlag(cmd)
+	return cmd
+}
+
+// parsePortMapping parses a port[:containerport] argument.
+func parsePortMapping(value string) (portMapping, error) {
+	parts := strings.SplitN(value, ":", 2)
+	ports := []int{}
+	for _, part := range parts {
+		port, err := strconv.Atoi(part)
+		if err != nil || port <= 0 || port > 65535 {
+			return portMapping{}, utils.UsageError(fmt.Errorf(L("invalid port in %s"), value))
+		}
+		ports = append(ports, port)
+	}
+	mapping := portMapping{Local: ports[0], Container: ports[0]}
+	if len(ports) > 1 {
+		mapping.Container = ports[1]
+	}
+	return mapping, nil
+}
+
+func run(globalFlags *types.GlobalFlags, flags *portForwardFlags, cmd *cobra.Command, args []string) error {
+	mapping, err := parsePortMapping(args[0])
+	if err != nil {
+		return err
+	}
+
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
+	backend, err := cnx.GetCommand()
+	if err != nil {
+		return err
+	}
+	podName, err := cnx.GetPodName()
+	if err != nil {
+		return err
+	}
+
+	if backend == "kubectl" {
+		namespace, err := cnx.GetNamespace()
+		if err != nil {
+			return err
+		}
+		log.Info().Msgf(L("Forwarding %[1]s:%[2]d to port %[3]d of %[4]s"),
+			flags.Address, mapping.Local, mapping.Container, podName)
+		return utils.RunCmdStdMapping(zerolog.DebugLevel, "kubectl", kubernetes.KubectlArgs(
+			"port-forward", "-n", namespace, "--address", flags.Address, "pod/"+podName,
+			fmt.Sprintf("%d:%d", mapping.Local, mapping.Container))...)
+	}
+
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, backend, "inspect", "--format",
+		"{{range .NetworkSettings.Networks}}{{.IPAddress}} {{end}}", podName)
+	if err != nil {
+		return utils.Errorf(err, L("failed to get the IP address of %[1]s: %[2]s"), podName)
+	}
+	ip := containerIP(string(out))
+	if ip == "" {
+		return fmt.Errorf(L("%s has no IP address to forward the port to"), podName)
+	}
+	target := net.JoinHostPort(ip, strconv.Itoa(mapping.Container))
+	local := net.JoinHostPort(flags.Address, strconv.Itoa(mapping.Local))
+
+	if host := podman.RemoteHost(); host != "" {
+		log.Info().Msgf(L("Forwarding %[1]s to %[2]s through an SSH tunnel to %[3]s"), local, target, host)
+		return utils.RunCmdStdMapping(zerolog.DebugLevel, "ssh", "-N", "-L", local+":"+target, host)
+	}
+
+	listener, err := net.Listen("tcp", local)
+	if err != nil {
+		return utils.Errorf(err, L("failed to listen on %[1]s: %[2]s"), local)
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		listener.Close()
+	}()
+
+	log.Info().Msgf(L("Forwarding %[1]s to %[2]s, interrupt to stop"), local, target)
+	return forward(listener, target)
+}
+
+// containerIP returns the first IP address of the container inspect output.
+func containerIP(out string) string {
+	if fields := strings.Fields(out); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// forward relays the connections accepted by the listener to the target address until the listener is closed.
+func forward(listener net.Listener, target string) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return utils.Errorf(err, L("failed to accept connection: %s"))
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			relay(conn, target)
+		}()
+	}
+}
+
+// relay copies the data between a connection and a new connection to the target.
+func relay(conn net.Conn, target string) {
+	defer conn.Close()
+	targetConn, err := net.Dial("tcp", target)
+	if err != nil {
+		log.Error().Err(err).Msgf(L("Failed to connect to %s"), target)
+		return
+	}
+	defer targetConn.Close()
+	log.Debug().Msgf("Forwarding connection from %s", conn.RemoteAddr())
+
+	done := make(chan struct{}, 2)
+	copyStream := func(dst net.Conn, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		// Let the other side know that nothing more will be sent
+		if tcpConn, ok := dst.(*net.TCPConn); ok {
+			_ = tcpConn.CloseWrite()
+		}
+		done <- struct{}{}
+	}
+	go copyStream(targetConn, conn)
+	go copyStream(conn, targetConn)
+	<-done
+	<-done
+}
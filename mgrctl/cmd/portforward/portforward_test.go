@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package portforward
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func TestNewCommand(t *testing.T) {
+	cmd := NewCommand(&types.GlobalFlags{})
+	if cmd.Flags().Lookup("address") == nil {
+		t.Error("missing address flag")
+	}
+}
+
+func TestParsePortMapping(t *testing.T) {
+	valid := map[string]portMapping{
+		"5432":       {Local: 5432, Container: 5432},
+		"15432:5432": {Local: 15432, Container: 5432},
+	}
+	for value, expected := range valid {
+		actual, err := parsePortMapping(value)
+		if err != nil || actual != expected {
+			t.Errorf("%s: expected %v, got %v, %v", value, expected, actual, err)
+		}
+	}
+	for _, value := range []string{"", "foo", "0", "70000", "80:", "80:90:100"} {
+		if _, err := parsePortMapping(value); err == nil {
+			t.Errorf("%s: expected an error", value)
+		}
+	}
+}
+
+func TestContainerIP(t *testing.T) {
+	data := map[string]string{
+		"10.89.0.2 \n":         "10.89.0.2",
+		"10.89.0.2 10.88.0.5 ": "10.89.0.2",
+		"\n":                   "",
+	}
+	for out, expected := range data {
+		if actual := containerIP(out); actual != expected {
+			t.Errorf("%q: expected %s, got %s", out, expected, actual)
+		}
+	}
+}
+
+func TestForward(t *testing.T) {
+	// Echo server standing for the container port
+	target, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+	go func() {
+		for {
+			conn, err := target.Accept()
+			if err != nil {
+				return
+			}
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			fmt.Fprintf(conn, "echo: %s", line)
+			conn.Close()
+		}
+	}()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- forward(listener, target.Addr().String())
+	}()
+
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(conn, "ping %d\n", i)
+		answer, err := bufio.NewReader(conn).ReadString('\n')
+		conn.Close()
+		if err != nil || answer != fmt.Sprintf("echo: ping %d\n", i) {
+			t.Errorf("unexpected answer %q: %v", answer, err)
+		}
+	}
+
+	listener.Close()
+	if err := <-done; err != nil {
+		t.Errorf("closing the listener should stop the forwarding without error: %s", err)
+	}
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+type reportQuery struct {
+	Description string
+	Query       string
+}
+
+// getReports returns the predefined queries run on the reporting database.
+func getReports() map[string]reportQuery {
+	return map[string]reportQuery{
+		"inventory": {
+			Description: L("registered systems with their hardware, operating system and network details"),
+			Query:       "SELECT * FROM InventoryReport ORDER BY system_id",
+		},
+		"patches": {
+			Description: L("patches applicable to each system"),
+			Query:       "SELECT * FROM ErrataSystemsReport ORDER BY advisory_name, system_id",
+		},
+		"outdated": {
+			Description: L("number of outdated packages and applicable patches of each system"),
+			Query: "SELECT s.system_id, s.profile_name, o.packages_out_of_date, o.errata_out_of_date " +
+				"FROM System s JOIN SystemOutdated o ON s.system_id = o.system_id ORDER BY s.system_id",
+		},
+		"subscriptions": {
+			Description: L("number of systems using each entitlement"),
+			Query: "SELECT name AS entitlement, COUNT(DISTINCT system_id) AS systems " +
+				"FROM SystemEntitlement GROUP BY name ORDER BY name",
+		},
+	}
+}
+
+// reportScript reads the reporting database credentials from the server configuration
+// and runs the query passed as first parameter.
+// The credentials never leave the container.
+const reportScript = `conf=/etc/rhn/rhn.conf
+get() { sed -n "s/^$1[[:space:]]*=[[:space:]]*//p" $conf | tail -n 1; }
+host=$(get report_db_host)
+port=$(get report_db_port)
+PGPASSWORD=$(get report_db_password) psql --csv -v ON_ERROR_STOP=1 \
+  -h "${host:-localhost}" -p "${port:-5432}" -U "$(get report_db_user)" -d "$(get report_db_name)" -c "$1"`
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type reportFlags struct {
+	Backend   string
+	Namespace string
+	Output    string
+}
+
+// NewCommand creates the command exporting reports from the reporting database.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	reports := getReports()
+	names := []string{}
+	for name := range reports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var description strings.Builder
+	for _, name := range names {
+		description.WriteString(fmt.Sprintf("  %-15s %s\n", name, reports[name].Description))
+	}
+
+	cmd := &cobra.Command{
+		Use:   "report name",
+		Short: L("Export a report from the reporting database"),
+		Long: fmt.Sprintf(L(`Export a predefined report from the reporting database of the server.
+
+The reporting database credentials are read from the server configuration inside the container.
+
+Available reports:
+%s`), description.String()),
+		Args:        cobra.ExactArgs(1),
+		ValidArgs:   names,
+		Annotations: map[string]string{utils.ReadOnlyAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags reportFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, runReport)
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "csv", L("output format. Possible values: csv, json"))
+	utils.AddBackendFlag(cmd)
+	utils.AddNamespaceFlag(cmd)
+	return cmd
+}
+
+func runReport(globalFlags *types.GlobalFlags, flags *reportFlags, cmd *cobra.Command, args []string) error {
+	report, found := getReports()[args[0]]
+	if !found {
+		return utils.UsageError(fmt.Errorf(L("unknown report %s"), args[0]))
+	}
+	if flags.Output != "csv" && flags.Output != "json" {
+		return utils.UsageError(fmt.Errorf(L("invalid output format %s: possible values are csv and json"), flags.Output))
+	}
+
+	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter, flags.Namespace)
+	out, err := cnx.Exec("sh", "-c", reportScript, "report", report.Query)
+	if err != nil {
+		return utils.Errorf(err, L("failed to query the reporting database: %s"))
+	}
+
+	if flags.Output == "csv" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	return writeJSON(os.Stdout, out)
+}
+
+// writeJSON converts the CSV output of psql into a JSON list of objects.
+func writeJSON(out io.Writer, data []byte) error {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return utils.Errorf(err, L("failed to parse the report: %s"))
+	}
+
+	rows := []map[string]string{}
+	if len(records) > 0 {
+		headers := records[0]
+		for _, record := range records[1:] {
+			row := map[string]string{}
+			for i, header := range headers {
+				if i < len(record) {
+					row[header] = record[i]
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	utils.AddMachineData("report", rows)
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rows)
+}
@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	csv := "system_id,profile_name,packages_out_of_date\n1000010000,web1,3\n1000010001,\"db, primary\",0\n"
+
+	var out bytes.Buffer
+	if err := writeJSON(&out, []byte(csv)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var actual []map[string]string
+	if err := json.Unmarshal(out.Bytes(), &actual); err != nil {
+		t.Fatalf("invalid JSON %s: %s", out.String(), err)
+	}
+	expected := []map[string]string{
+		{"system_id": "1000010000", "profile_name": "web1", "packages_out_of_date": "3"},
+		{"system_id": "1000010001", "profile_name": "db, primary", "packages_out_of_date": "0"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	out.Reset()
+	if err := writeJSON(&out, []byte{}); err != nil || out.String() != "[]\n" {
+		t.Errorf("unexpected output for an empty report: %s, %v", out.String(), err)
+	}
+}
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/system"
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func list(globalFlags *types.GlobalFlags, flags *systemFlags, cmd *cobra.Command, args []string) error {
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	var systems []apiTypes.SystemInfo
+	if flags.Group != "" {
+		systems, err = system.ListGroupSystems(client, flags.Group)
+	} else {
+		systems, err = system.List(client)
+	}
+	if err != nil {
+		return err
+	}
+	return systemsTable(systems).Print(flags.Output)
+}
+
+func search(globalFlags *types.GlobalFlags, flags *systemFlags, cmd *cobra.Command, args []string) error {
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	systems, err := system.SearchByName(client, args[0])
+	if err != nil {
+		return err
+	}
+	if flags.Group != "" {
+		groupSystems, err := system.ListGroupSystems(client, flags.Group)
+		if err != nil {
+			return err
+		}
+		systems = inGroup(systems, groupSystems)
+	}
+	return systemsTable(systems).Print(flags.Output)
+}
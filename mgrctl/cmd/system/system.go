@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type systemFlags struct {
+	api.ConnectionDetails `mapstructure:"api"`
+	utils.OutputFlags     `mapstructure:",squash"`
+	Group                 string
+}
+
+// NewCommand creates the command listing the registered systems.
+func NewCommand(globalFlags *types.GlobalFlags) (*cobra.Command, error) {
+	systemCmd := &cobra.Command{
+		Use:   "system",
+		Short: L("Registered systems commands"),
+	}
+
+	if err := api.AddAPIFlags(systemCmd, false); err != nil {
+		return systemCmd, err
+	}
+
+	listCmd := &cobra.Command{
+		Use:         "list",
+		Short:       L("List the registered systems"),
+		Args:        cobra.ExactArgs(0),
+		Annotations: map[string]string{utils.ReadOnlyAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags systemFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, list)
+		},
+	}
+
+	searchCmd := &cobra.Command{
+		Use:         "search regexp",
+		Short:       L("Search the registered systems by name"),
+		Long:        L("List the registered systems with a name matching the regular expression"),
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{utils.ReadOnlyAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags systemFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, search)
+		},
+	}
+
+	for _, cmd := range []*cobra.Command{listCmd, searchCmd} {
+		cmd.Flags().String("group", "", L("only list the systems of this system group"))
+		utils.AddOutputFlag(cmd)
+		systemCmd.AddCommand(cmd)
+	}
+
+	return systemCmd, nil
+}
+
+func systemsTable(systems []apiTypes.SystemInfo) *utils.Table {
+	table := utils.Table{
+		Headers: []string{L("ID"), L("NAME"), L("LAST CHECKIN"), L("LAST BOOT")},
+		Data:    systems,
+	}
+	for _, system := range systems {
+		table.Rows = append(table.Rows, []string{
+			strconv.Itoa(system.Id), system.Name, system.LastCheckin, system.LastBoot,
+		})
+	}
+	return &table
+}
+
+// inGroup returns the systems also present in the group systems.
+func inGroup(systems []apiTypes.SystemInfo, groupSystems []apiTypes.SystemInfo) []apiTypes.SystemInfo {
+	ids := map[int]bool{}
+	for _, system := range groupSystems {
+		ids[system.Id] = true
+	}
+	filtered := []apiTypes.SystemInfo{}
+	for _, system := range systems {
+		if ids[system.Id] {
+			filtered = append(filtered, system)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"testing"
+
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func TestNewCommand(t *testing.T) {
+	var globalflags types.GlobalFlags
+	cmd, err := NewCommand(&globalflags)
+	if err != nil {
+		t.Errorf("Unexpected error creating command: %s", err)
+	}
+	if cmd == nil {
+		t.Error("Unexpected nil command")
+	}
+}
+
+func TestInGroup(t *testing.T) {
+	systems := []apiTypes.SystemInfo{{Id: 1, Name: "web1"}, {Id: 2, Name: "web2"}, {Id: 3, Name: "db1"}}
+	group := []apiTypes.SystemInfo{{Id: 3, Name: "db1"}, {Id: 2, Name: "web2"}, {Id: 4, Name: "db2"}}
+
+	filtered := inGroup(systems, group)
+	if len(filtered) != 2 || filtered[0].Name != "web2" || filtered[1].Name != "db1" {
+		t.Errorf("unexpected filtered systems: %v", filtered)
+	}
+}
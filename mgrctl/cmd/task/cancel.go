@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/taskomatic"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type cancelFlags struct {
+	api.ConnectionDetails `mapstructure:"api"`
+}
+
+func cancelCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel job-label",
+		Short: L("Cancel a Taskomatic schedule"),
+		Long:  L("Cancel a Taskomatic schedule using the job label shown by the list command"),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags cancelFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, cancel)
+		},
+	}
+	return cmd
+}
+
+func cancel(globalFlags *types.GlobalFlags, flags *cancelFlags, cmd *cobra.Command, args []string) error {
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	if err := taskomatic.Unschedule(client, args[0]); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("%s schedule canceled"), args[0])
+	return nil
+}
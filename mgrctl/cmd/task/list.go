@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/taskomatic"
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type listFlags struct {
+	api.ConnectionDetails `mapstructure:"api"`
+	utils.OutputFlags     `mapstructure:",squash"`
+	Bunches               bool
+	Schedule              int
+	Failed                bool
+}
+
+func listCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: L("List the Taskomatic schedules"),
+		Long: L(`List the active Taskomatic schedules.
+
+Use --bunches to list the bunches that can be run and --schedule to list the runs of a schedule.`),
+		Args:        cobra.ExactArgs(0),
+		Annotations: map[string]string{utils.ReadOnlyAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags listFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, list)
+		},
+	}
+
+	cmd.Flags().Bool("bunches", false, L("list the bunches that can be run instead of the schedules"))
+	cmd.Flags().Int("schedule", 0, L("list the runs of the schedule with this ID instead of the schedules"))
+	cmd.Flags().Bool("failed", false, L("only list the failed runs, requires --schedule"))
+	utils.AddOutputFlag(cmd)
+
+	return cmd
+}
+
+func list(globalFlags *types.GlobalFlags, flags *listFlags, cmd *cobra.Command, args []string) error {
+	if flags.Failed && flags.Schedule == 0 {
+		return utils.UsageError(errors.New(L("--failed requires --schedule")))
+	}
+
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	var table *utils.Table
+	if flags.Bunches {
+		bunches, err := taskomatic.ListBunches(client)
+		if err != nil {
+			return err
+		}
+		table = bunchesTable(bunches)
+	} else if flags.Schedule != 0 {
+		runs, err := taskomatic.ListScheduleRuns(client, flags.Schedule)
+		if err != nil {
+			return err
+		}
+		if flags.Failed {
+			runs = failedRuns(runs)
+		}
+		table = runsTable(runs)
+	} else {
+		schedules, err := taskomatic.ListSchedules(client)
+		if err != nil {
+			return err
+		}
+		table = schedulesTable(schedules)
+	}
+	return table.Print(flags.Output)
+}
+
+func bunchesTable(bunches []apiTypes.TaskoBunch) *utils.Table {
+	table := utils.Table{Headers: []string{L("NAME"), L("DESCRIPTION")}, Data: bunches}
+	for _, bunch := range bunches {
+		table.Rows = append(table.Rows, []string{bunch.Name, bunch.Description})
+	}
+	return &table
+}
+
+func schedulesTable(schedules []apiTypes.TaskoSchedule) *utils.Table {
+	table := utils.Table{
+		Headers: []string{L("ID"), L("JOB LABEL"), L("BUNCH"), L("CRON"), L("ACTIVE FROM")},
+		Data:    schedules,
+	}
+	for _, schedule := range schedules {
+		table.Rows = append(table.Rows, []string{
+			strconv.Itoa(schedule.Id), schedule.JobLabel, schedule.Bunch, schedule.CronExpr, schedule.ActiveFrom,
+		})
+	}
+	return &table
+}
+
+func runsTable(runs []apiTypes.TaskoRun) *utils.Table {
+	table := utils.Table{Headers: []string{L("ID"), L("STATUS"), L("START"), L("END")}, Data: runs}
+	for _, run := range runs {
+		table.Rows = append(table.Rows, []string{strconv.Itoa(run.Id), run.Status, run.StartTime, run.EndTime})
+	}
+	return &table
+}
+
+// failedRuns returns the runs that failed or got interrupted.
+func failedRuns(runs []apiTypes.TaskoRun) []apiTypes.TaskoRun {
+	failed := []apiTypes.TaskoRun{}
+	for _, run := range runs {
+		if run.Status == "FAILED" || run.Status == "INTERRUPTED" {
+			failed = append(failed, run)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/taskomatic"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type runFlags struct {
+	api.ConnectionDetails `mapstructure:"api"`
+	Param                 []string
+}
+
+func runCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run bunch",
+		Short: L("Run a Taskomatic bunch now"),
+		Long: L(`Schedule a single run of a Taskomatic bunch now.
+
+For instance, to synchronize a channel run the repo-sync-bunch with the channel_id parameter
+or the cleanup-data-bunch to clean the old data.`),
+		Example: `  mgrctl task run repo-sync-bunch --param channel_id=101`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags runFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, run)
+		},
+	}
+
+	cmd.Flags().StringSlice("param", []string{}, L("parameter to pass to the bunch as key=value. Can be repeated"))
+
+	return cmd
+}
+
+func run(globalFlags *types.GlobalFlags, flags *runFlags, cmd *cobra.Command, args []string) error {
+	params, err := parseParams(flags.Param)
+	if err != nil {
+		return utils.UsageError(err)
+	}
+
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server: %s"))
+	}
+
+	if err := taskomatic.RunBunch(client, args[0], params); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("%s bunch scheduled to run now"), args[0])
+	return nil
+}
+
+func parseParams(values []string) (map[string]string, error) {
+	params := map[string]string{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf(L("invalid parameter %s: expected key=value"), value)
+		}
+		params[parts[0]] = parts[1]
+	}
+	return params, nil
+}
@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// NewCommand creates the command managing the Taskomatic tasks.
+func NewCommand(globalFlags *types.GlobalFlags) (*cobra.Command, error) {
+	taskCmd := &cobra.Command{
+		Use:   "task",
+		Short: L("Taskomatic tasks commands"),
+		Long:  L("List, run and cancel the tasks scheduled in Taskomatic"),
+	}
+
+	if err := api.AddAPIFlags(taskCmd, false); err != nil {
+		return taskCmd, err
+	}
+
+	taskCmd.AddCommand(listCommand(globalFlags))
+	taskCmd.AddCommand(runCommand(globalFlags))
+	taskCmd.AddCommand(cancelCommand(globalFlags))
+
+	return taskCmd, nil
+}
@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"reflect"
+	"testing"
+
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func TestNewCommand(t *testing.T) {
+	var globalflags types.GlobalFlags
+	cmd, err := NewCommand(&globalflags)
+	if err != nil {
+		t.Errorf("Unexpected error creating command: %s", err)
+	}
+	if cmd == nil {
+		t.Error("Unexpected nil command")
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	params, err := parseParams([]string{"channel_id=101", "no-errata=true=false"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]string{"channel_id": "101", "no-errata": "true=false"}
+	if !reflect.DeepEqual(params, expected) {
+		t.Errorf("expected %v, got %v", expected, params)
+	}
+
+	for _, value := range []string{"channel_id", "=101"} {
+		if _, err := parseParams([]string{value}); err == nil {
+			t.Errorf("expected an error for %s", value)
+		}
+	}
+}
+
+func TestFailedRuns(t *testing.T) {
+	runs := []apiTypes.TaskoRun{
+		{Id: 1, Status: "FINISHED"},
+		{Id: 2, Status: "FAILED"},
+		{Id: 3, Status: "INTERRUPTED"},
+		{Id: 4, Status: "RUNNING"},
+	}
+	failed := failedRuns(runs)
+	if len(failed) != 2 || failed[0].Id != 2 || failed[1].Id != 3 {
+		t.Errorf("unexpected failed runs: %v", failed)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/exec"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
@@ -17,8 +18,9 @@ var newExecCmd = exec.NewCommand
 // NewCommand returns a new cobra.Command for term.
 func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "term",
-		Short: L("Run a terminal inside the server container"),
+		Use:         "term",
+		Short:       L("Run a terminal inside the server container"),
+		Annotations: map[string]string{podman.RemoteAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			execCmd := newExecCmd(globalFlags)
 			execArgs := []string{"-i", "-t"}
@@ -34,5 +36,6 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	}
 
 	utils.AddBackendFlag(cmd)
+	utils.AddNamespaceFlag(cmd)
 	return cmd
 }
@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import (
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// NewCommand to run diagnostics on the proxy environment.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: L("Diagnose the proxy environment"),
+		Long:  L("Diagnose the proxy environment"),
+	}
+	checkCmd.AddCommand(newSecurityCommand(globalFlags))
+	return checkCmd
+}
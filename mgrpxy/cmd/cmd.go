@@ -10,16 +10,23 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgrpxy/cmd/check"
+	"github.com/uyuni-project/uyuni-tools/mgrpxy/cmd/images"
 	"github.com/uyuni-project/uyuni-tools/mgrpxy/cmd/install"
+	"github.com/uyuni-project/uyuni-tools/mgrpxy/cmd/logs"
 	"github.com/uyuni-project/uyuni-tools/mgrpxy/cmd/restart"
 	"github.com/uyuni-project/uyuni-tools/mgrpxy/cmd/start"
 	"github.com/uyuni-project/uyuni-tools/mgrpxy/cmd/status"
 	"github.com/uyuni-project/uyuni-tools/mgrpxy/cmd/stop"
 	"github.com/uyuni-project/uyuni-tools/mgrpxy/cmd/support"
+	"github.com/uyuni-project/uyuni-tools/mgrpxy/cmd/tftp"
 	"github.com/uyuni-project/uyuni-tools/mgrpxy/cmd/uninstall"
 	"github.com/uyuni-project/uyuni-tools/mgrpxy/cmd/upgrade"
 	"github.com/uyuni-project/uyuni-tools/shared/completion"
+	"github.com/uyuni-project/uyuni-tools/shared/docs"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
@@ -27,6 +34,9 @@ import (
 // NewCommand returns a new cobra.Command implementing the root command for kinder.
 func NewUyuniproxyCommand() (*cobra.Command, error) {
 	globalFlags := &types.GlobalFlags{}
+	var remoteFlags podman.RemoteFlags
+	var verifyFlags podman.VerifyFlags
+	var clusterFlags kubernetes.ClusterFlags
 	name := path.Base(os.Args[0])
 	rootCmd := &cobra.Command{
 		Use:          name,
@@ -36,11 +46,29 @@ func NewUyuniproxyCommand() (*cobra.Command, error) {
 		SilenceUsage: true, // Don't show usage help on errors
 	}
 
+	utils.SetEnvPrefix("MGRPXY")
 	rootCmd.SetUsageTemplate(utils.GetLocalizedUsageTemplate())
 
 	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
-		utils.LogInit(true)
+		if err := utils.ApplyEnvToGlobalFlags(cmd); err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to read the global flags from the environment"))
+		}
+		if err := utils.ApplyProfileToGlobalFlags(cmd); err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to apply the configuration profile"))
+		}
+		utils.LogInit(!globalFlags.Machine, globalFlags.LogFile)
 		utils.SetLogLevel(globalFlags.LogLevel)
+		if globalFlags.Machine {
+			utils.EnableMachineOutput(cmd)
+		}
+
+		if err := podman.SetRemote(cmd, &remoteFlags); err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to configure the remote podman host"))
+		}
+		if err := podman.SetVerify(&verifyFlags); err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to configure the image signature verification"))
+		}
+		kubernetes.SetCluster(&clusterFlags)
 
 		// do not log if running the completion cmd as the output is redirected to create a file to source
 		if cmd.Name() != "completion" {
@@ -51,6 +79,15 @@ func NewUyuniproxyCommand() (*cobra.Command, error) {
 
 	rootCmd.PersistentFlags().StringVarP(&globalFlags.ConfigPath, "config", "c", "", L("configuration file path"))
 	rootCmd.PersistentFlags().StringVar(&globalFlags.LogLevel, "logLevel", "", L("application log level")+"(trace|debug|info|warn|error|fatal|panic)")
+	rootCmd.PersistentFlags().StringVar(&globalFlags.LogFile, "logfile", "", L("path to the log file recording the executed commands. Defaults to /var/log/uyuni-tools.log or $HOME/uyuni-tools.log"))
+	utils.AddMachineFlag(rootCmd, &globalFlags.Machine)
+	utils.AddLangFlag(rootCmd)
+	utils.AddProfileFlag(rootCmd)
+	podman.AddRemoteFlags(rootCmd, &remoteFlags)
+	podman.AddVerifyFlags(rootCmd, &verifyFlags)
+	if utils.KubernetesBuilt {
+		kubernetes.AddClusterFlags(rootCmd, &clusterFlags)
+	}
 
 	installCmd := install.NewCommand(globalFlags)
 	rootCmd.AddCommand(installCmd)
@@ -60,11 +97,16 @@ func NewUyuniproxyCommand() (*cobra.Command, error) {
 	}
 	rootCmd.AddCommand(uninstallCmd)
 	rootCmd.AddCommand(completion.NewCommand(globalFlags))
+	rootCmd.AddCommand(docs.NewCommand(globalFlags))
 	rootCmd.AddCommand(status.NewCommand(globalFlags))
 	rootCmd.AddCommand(start.NewCommand(globalFlags))
 	rootCmd.AddCommand(stop.NewCommand(globalFlags))
 	rootCmd.AddCommand(restart.NewCommand(globalFlags))
 	rootCmd.AddCommand(upgrade.NewCommand(globalFlags))
+	rootCmd.AddCommand(images.NewCommand(globalFlags))
+	rootCmd.AddCommand(check.NewCommand(globalFlags))
+	rootCmd.AddCommand(logs.NewCommand(globalFlags))
+	rootCmd.AddCommand(tftp.NewCommand(globalFlags))
 
 	if supportCommand := support.NewCommand(globalFlags); supportCommand != nil {
 		rootCmd.AddCommand(supportCommand)
@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package images
+
+import (
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// NewCommand for container images management.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	imagesCmd := &cobra.Command{
+		Use:   "images",
+		Short: L("Manage the container images"),
+		Long:  L("Tools and utilities to manage the proxy container images"),
+	}
+
+	imagesCmd.SetUsageTemplate(imagesCmd.UsageTemplate())
+	imagesCmd.AddCommand(newPullCommand(globalFlags))
+	return imagesCmd
+}
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package images
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	pxy_utils "github.com/uyuni-project/uyuni-tools/mgrpxy/shared/utils"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type pullFlags struct {
+	pxy_utils.ProxyImageFlags `mapstructure:",squash"`
+}
+
+func newPullCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pull",
+		Short: L("Pull the container images"),
+		Long: L(`Pull all the container images needed to install or upgrade the proxy in parallel.
+
+Pulling the images before the actual install or upgrade shortens the maintenance window.
+The same registry credentials as the install or upgrade are used.
+This command is only relevant on podman: kubernetes nodes pull the images themselves.`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags pullFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, pull)
+		},
+	}
+
+	pxy_utils.AddImageFlags(cmd)
+
+	return cmd
+}
+
+func pull(globalFlags *types.GlobalFlags, flags *pullFlags, cmd *cobra.Command, args []string) error {
+	images := []string{}
+	for _, name := range []string{"httpd", "salt-broker", "squid", "ssh", "tftpd"} {
+		images = append(images, flags.GetContainerImage(name))
+	}
+
+	inspectedHostValues, err := utils.InspectHost()
+	if err != nil {
+		return utils.Errorf(err, L("cannot inspect host values: %s"))
+	}
+	pullArgs := podman.GetPullArgs(inspectedHostValues)
+
+	log.Info().Msgf(L("Pulling %d images"), len(images))
+	return podman.PullImages(images, flags.PullPolicy, pullArgs...)
+}
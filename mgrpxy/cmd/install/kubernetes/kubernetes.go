@@ -35,7 +35,7 @@ NOTE: for now installing on a remote kubernetes cluster is not supported!
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags kubernetesProxyInstallFlags
-			return utils.CommandHelper(globalFlags, cmd, args, &flags, installForKubernetes)
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, utils.WithAudit(installForKubernetes))
 		},
 	}
 
@@ -7,7 +7,6 @@ package kubernetes
 import (
 	"fmt"
 	"os"
-	"os/exec"
 
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/mgrpxy/shared/kubernetes"
@@ -21,18 +20,12 @@ import (
 func installForKubernetes(globalFlags *types.GlobalFlags,
 	flags *kubernetesProxyInstallFlags, cmd *cobra.Command, args []string,
 ) error {
-	for _, binary := range []string{"kubectl", "helm"} {
-		if _, err := exec.LookPath(binary); err != nil {
-			return fmt.Errorf(L("install %s before running this command"), binary)
-		}
-	}
-
 	// Unpack the tarball
 	configPath := utils.GetConfigPath(args)
 
 	tmpDir, err := os.MkdirTemp("", "mgrpxy-*")
 	if err != nil {
-		return fmt.Errorf(L("failed to create temporary directory: %s"), err)
+		return shared_utils.Errorf(err, L("failed to create temporary directory: %s"))
 	}
 	defer os.RemoveAll(tmpDir)
 
@@ -59,7 +52,7 @@ func installForKubernetes(globalFlags *types.GlobalFlags,
 	// Install the uyuni proxy helm chart
 	if err := kubernetes.Deploy(&flags.ProxyImageFlags, &flags.Helm, tmpDir, clusterInfos.GetKubeconfig(),
 		"--set", "ingress="+clusterInfos.Ingress); err != nil {
-		return fmt.Errorf(L("cannot deploy proxy helm chart: %s"), err)
+		return shared_utils.Errorf(err, L("cannot deploy proxy helm chart: %s"))
 	}
 
 	return nil
@@ -35,7 +35,7 @@ NOTE: for now installing on a remote podman is not supported!
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags podmanProxyInstallFlags
-			return shared_utils.CommandHelper(globalFlags, cmd, args, &flags, installForPodman)
+			return shared_utils.CommandHelper(globalFlags, cmd, args, &flags, shared_utils.WithAudit(installForPodman))
 		},
 	}
 
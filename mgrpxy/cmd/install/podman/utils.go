@@ -14,6 +14,7 @@ import (
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	shared_podman "github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
+	shared_utils "github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 // Start the proxy services.
@@ -30,10 +31,9 @@ func installForPodman(globalFlags *types.GlobalFlags, flags *podmanProxyInstallF
 	if _, err := exec.LookPath("podman"); err != nil {
 		return fmt.Errorf(L("install podman before running this command"))
 	}
-
 	configPath := utils.GetConfigPath(args)
 	if err := podman.UnpackConfig(configPath); err != nil {
-		return fmt.Errorf(L("failed to extract proxy config from %s file: %s"), configPath, err)
+		return shared_utils.Errorf(err, L("failed to extract proxy config from %s file: %s"), configPath)
 	}
 
 	httpdImage, err := podman.GetContainerImage(&flags.ProxyImageFlags, "httpd")
@@ -58,7 +58,7 @@ func installForPodman(globalFlags *types.GlobalFlags, flags *podmanProxyInstallF
 	}
 
 	// Setup the systemd service configuration options
-	if err := podman.GenerateSystemdService(httpdImage, saltBrokerImage, squidImage, sshImage, tftpdImage, flags.Podman.Args); err != nil {
+	if err := podman.GenerateSystemdService(httpdImage, saltBrokerImage, squidImage, sshImage, tftpdImage, &flags.Podman, cmd); err != nil {
 		return err
 	}
 
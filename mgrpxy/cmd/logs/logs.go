@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"golang.org/x/term"
+)
+
+type logsFlags struct {
+	Backend   string
+	Namespace string
+	Since     string
+	Grep      string
+	Follow    bool
+	Lines     int
+	Journal   bool
+}
+
+// NewCommand shows the merged logs of the proxy containers.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs [component]...",
+		Short: L("Show the proxy logs"),
+		Long: L(`Show the proxy logs
+
+The logs of the proxy containers are merged, each line being prefixed by its component.
+All the components are shown if none is given. The logs of the systemd services are added
+with --journal for a local podman proxy.
+`) + fmt.Sprintf(L("The available components are: %s"), strings.Join(shared.ProxyComponents(), ", ")),
+		ValidArgs:   shared.ProxyComponents(),
+		Annotations: map[string]string{podman.RemoteAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags logsFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, showLogs)
+		},
+	}
+
+	cmd.Flags().String("since", "", L("Only show the logs since a duration like 1h or a timestamp"))
+	cmd.Flags().String("grep", "", L("Only show the lines matching this regular expression"))
+	cmd.Flags().BoolP("follow", "f", false, L("Follow the logs"))
+	cmd.Flags().IntP("lines", "n", 50, L("Number of last lines to show for each component when not using --since"))
+	cmd.Flags().Bool("journal", false, L("Also show the logs of the systemd services"))
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(cmd)
+		utils.AddNamespaceFlag(cmd)
+	}
+	return cmd
+}
+
+func showLogs(globalFlags *types.GlobalFlags, flags *logsFlags, cmd *cobra.Command, args []string) error {
+	var filter *regexp.Regexp
+	if flags.Grep != "" {
+		var err error
+		if filter, err = regexp.Compile(flags.Grep); err != nil {
+			return utils.UsageError(utils.Errorf(err, L("invalid regular expression %[1]s: %[2]s"), flags.Grep))
+		}
+	}
+	components := args
+	if len(components) == 0 {
+		components = shared.ProxyComponents()
+	}
+	for _, component := range components {
+		if !utils.Contains(shared.ProxyComponents(), component) {
+			return utils.UsageError(fmt.Errorf(L("unknown proxy component %[1]s, possible values are: %[2]s"),
+				component, strings.Join(shared.ProxyComponents(), ", ")))
+		}
+	}
+
+	cnx := shared.NewConnection(flags.Backend, podman.ProxyContainerNames[0], kubernetes.ProxyFilter, flags.Namespace)
+	command, err := cnx.GetCommand()
+	if err != nil {
+		return err
+	}
+	podName := ""
+	namespace := ""
+	if command == "kubectl" {
+		if podName, err = cnx.GetPodName(); err != nil {
+			return err
+		}
+		if namespace, err = cnx.GetNamespace(); err != nil {
+			return err
+		}
+	}
+
+	sources := logSources(flags, components, command, podName, namespace, podman.IsRemote())
+	return utils.RunLogSources(os.Stdout, sources, filter, term.IsTerminal(int(os.Stdout.Fd())))
+}
+
+// logSources computes the commands printing the logs of the selected components.
+func logSources(
+	flags *logsFlags,
+	components []string,
+	command string,
+	podName string,
+	namespace string,
+	remote bool,
+) []utils.LogSource {
+	sources := []utils.LogSource{}
+	if flags.Journal {
+		if command == "kubectl" || remote {
+			log.Warn().Msg(L("The systemd journal is only available for a local podman proxy"))
+		} else {
+			args := []string{"-u", podman.ProxyService}
+			for _, component := range components {
+				args = append(args, "-u", "uyuni-proxy-"+component)
+			}
+			args = append(args, "--no-pager", "-o", "short-iso")
+			if flags.Since != "" {
+				args = append(args, "--since", utils.JournalSince(flags.Since))
+			} else {
+				args = append(args, "-n", fmt.Sprint(flags.Lines))
+			}
+			if flags.Follow {
+				args = append(args, "-f")
+			}
+			sources = append(sources, utils.LogSource{Name: "journal", Command: "journalctl", Args: args})
+		}
+	}
+
+	for _, component := range components {
+		args := []string{"logs"}
+		if command == "kubectl" {
+			args = append(args, "-n", namespace, "-c", component)
+		}
+		if flags.Since != "" {
+			args = append(args, utils.ContainerSince(command, flags.Since)...)
+		} else {
+			args = append(args, "--tail", fmt.Sprint(flags.Lines))
+		}
+		if flags.Follow {
+			args = append(args, "-f")
+		}
+		if command == "kubectl" {
+			args = kubernetes.KubectlArgs(append(args, podName)...)
+		} else {
+			args = append(args, "uyuni-proxy-"+component)
+		}
+		sources = append(sources, utils.LogSource{Name: component, Command: command, Args: args})
+	}
+	return sources
+}
@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func TestLogSourcesPodman(t *testing.T) {
+	flags := logsFlags{Since: "1h", Follow: true, Lines: 10, Journal: true}
+	sources := logSources(&flags, []string{"squid", "salt-broker"}, "podman", "", "", false)
+
+	expected := []string{
+		"journal: journalctl -u uyuni-proxy-pod -u uyuni-proxy-squid -u uyuni-proxy-salt-broker " +
+			"--no-pager -o short-iso --since -3600s -f",
+		"squid: podman logs --since 1h -f uyuni-proxy-squid",
+		"salt-broker: podman logs --since 1h -f uyuni-proxy-salt-broker",
+	}
+	checkSources(t, sources, expected)
+
+	// No journal for remote podman hosts
+	flags = logsFlags{Lines: 5, Journal: true}
+	sources = logSources(&flags, []string{"httpd"}, "podman", "", "", true)
+	checkSources(t, sources, []string{"httpd: podman logs --tail 5 uyuni-proxy-httpd"})
+}
+
+func TestLogSourcesKubernetes(t *testing.T) {
+	flags := logsFlags{Since: "2024-05-01T10:00:00Z", Lines: 5}
+	sources := logSources(&flags, []string{"tftpd"}, "kubectl", "uyuni-proxy-1234", "uyuni", false)
+
+	expected := []string{
+		"tftpd: kubectl logs -n uyuni -c tftpd --since-time 2024-05-01T10:00:00Z uyuni-proxy-1234",
+	}
+	checkSources(t, sources, expected)
+}
+
+func checkSources(t *testing.T, sources []utils.LogSource, expected []string) {
+	actual := []string{}
+	for _, source := range sources {
+		actual = append(actual, source.Name+": "+source.Command+" "+strings.Join(source.Args, " "))
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected sources:\n%s\nexpected:\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+	}
+}
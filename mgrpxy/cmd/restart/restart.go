@@ -13,16 +13,21 @@ import (
 )
 
 type restartFlags struct {
-	Backend string
+	Backend   string
+	Namespace string
 }
 
 // NewCommand to restart server.
 func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	restartCmd := &cobra.Command{
-		Use:   "restart",
+		Use:   "restart [component]",
 		Short: L("Restart the proxy"),
-		Long:  L("Restart the proxy"),
-		Args:  cobra.ExactArgs(0),
+		Long: L(`Restart the proxy
+
+A single component of the proxy can be targeted using its name: httpd, salt-broker, squid, ssh or tftpd.
+On kubernetes, this requires the component to run in its own deployment.`),
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: shared.ProxyComponents(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags restartFlags
 			return utils.CommandHelper(globalFlags, cmd, args, &flags, restart)
@@ -31,15 +36,19 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	restartCmd.SetUsageTemplate(restartCmd.UsageTemplate())
 
 	utils.AddBackendFlag(restartCmd)
+	utils.AddNamespaceFlag(restartCmd)
 
 	return restartCmd
 }
 
 func restart(globalFlags *types.GlobalFlags, flags *restartFlags, cmd *cobra.Command, args []string) error {
-	fn, err := shared.ChooseProxyPodmanOrKubernetes(cmd.Flags(), podmanRestart, kubernetesRestart)
+	backend, err := shared.FindBackend(cmd.Flags(), shared.ProxyApp)
 	if err != nil {
 		return err
 	}
 
-	return fn(globalFlags, flags, cmd, args)
+	if len(args) > 0 {
+		return backend.RestartComponent(shared.ProxyApp, args[0], flags.Namespace)
+	}
+	return backend.Restart(shared.ProxyApp, flags.Namespace)
 }
@@ -13,16 +13,21 @@ import (
 )
 
 type startFlags struct {
-	Backend string
+	Backend   string
+	Namespace string
 }
 
 // NewCommand starts the server.
 func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	startCmd := &cobra.Command{
-		Use:   "start",
+		Use:   "start [component]",
 		Short: L("Start the proxy"),
-		Long:  L("Start the proxy"),
-		Args:  cobra.ExactArgs(0),
+		Long: L(`Start the proxy
+
+A single component of the proxy can be targeted using its name: httpd, salt-broker, squid, ssh or tftpd.
+On kubernetes, this requires the component to run in its own deployment.`),
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: shared.ProxyComponents(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags startFlags
 			return utils.CommandHelper(globalFlags, cmd, args, &flags, start)
@@ -32,16 +37,20 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 
 	if utils.KubernetesBuilt {
 		utils.AddBackendFlag(startCmd)
+		utils.AddNamespaceFlag(startCmd)
 	}
 
 	return startCmd
 }
 
 func start(globalFlags *types.GlobalFlags, flags *startFlags, cmd *cobra.Command, args []string) error {
-	fn, err := shared.ChooseProxyPodmanOrKubernetes(cmd.Flags(), podmanStart, kubernetesStart)
+	backend, err := shared.FindBackend(cmd.Flags(), shared.ProxyApp)
 	if err != nil {
 		return err
 	}
 
-	return fn(globalFlags, flags, cmd, args)
+	if len(args) > 0 {
+		return backend.StartComponent(shared.ProxyApp, args[0], flags.Namespace)
+	}
+	return backend.Start(shared.ProxyApp, flags.Namespace)
 }
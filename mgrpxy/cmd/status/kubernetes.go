@@ -6,13 +6,14 @@ package status
 
 import (
 	"errors"
-	"fmt"
+	"strconv"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 func kubernetesStatus(
@@ -24,7 +25,7 @@ func kubernetesStatus(
 	// Do we have an uyuni helm release?
 	clusterInfos, err := kubernetes.CheckCluster()
 	if err != nil {
-		return fmt.Errorf(L("failed to discover the cluster type: %s"), err)
+		return utils.Errorf(err, L("failed to discover the cluster type: %s"))
 	}
 
 	kubeconfig := clusterInfos.GetKubeconfig()
@@ -32,16 +33,31 @@ func kubernetesStatus(
 		return errors.New(L("no uyuni-proxy helm release installed on the cluster"))
 	}
 
-	namespace, err := kubernetes.FindNamespace("uyuni-proxy", kubeconfig)
+	namespace := flags.Namespace
+	if namespace == "" {
+		namespace, err = kubernetes.FindNamespace("uyuni-proxy", kubeconfig)
+	}
 	if err != nil {
-		return fmt.Errorf(L("failed to find the uyuni-proxy deployment namespace: %s"), err)
+		return utils.Errorf(err, L("failed to find the uyuni-proxy deployment namespace: %s"))
 	}
 
 	// Is the pod running? Do we have all the replicas?
 	status, err := kubernetes.GetDeploymentStatus(namespace, "uyuni-proxy")
 	if err != nil {
-		return fmt.Errorf(L("failed to get deployment status: %s"), err)
+		return utils.Errorf(err, L("failed to get deployment status: %s"))
+	}
+	table := utils.Table{
+		Headers: []string{L("REPLICAS"), L("READY"), L("AVAILABLE"), L("UPDATED")},
+		Rows: [][]string{{
+			strconv.Itoa(status.Replicas), strconv.Itoa(status.ReadyReplicas),
+			strconv.Itoa(status.AvailableReplicas), strconv.Itoa(status.UpdatedReplicas),
+		}},
+		Data: status,
 	}
+	if err := table.Print(flags.Output); err != nil {
+		return err
+	}
+
 	if status.Replicas != status.ReadyReplicas {
 		log.Warn().Msgf(L("Some replicas are not ready: %d / %d"), status.ReadyReplicas, status.Replicas)
 	}
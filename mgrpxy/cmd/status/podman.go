@@ -5,31 +5,188 @@
 package status
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	pxy_utils "github.com/uyuni-project/uyuni-tools/mgrpxy/shared/utils"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
+// serverPorts are the ports of the parent server the proxy needs to reach.
+var serverPorts = []int{443, 4505, 4506}
+
+const dialTimeout = 5 * time.Second
+
+// containerStatus is the state of a proxy container.
+type containerStatus struct {
+	Name    string `json:"name"`
+	State   string `json:"state"`
+	Health  string `json:"health,omitempty"`
+	Image   string `json:"image"`
+	Started string `json:"started,omitempty"`
+	Uptime  string `json:"uptime,omitempty"`
+}
+
+// portStatus is the connectivity to a port of the parent server.
+type portStatus struct {
+	Port      int    `json:"port"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// proxyStatus is the state of the proxy reported by the status command.
+type proxyStatus struct {
+	Containers   []containerStatus `json:"containers"`
+	Server       string            `json:"server,omitempty"`
+	Connectivity []portStatus      `json:"connectivity,omitempty"`
+}
+
+// podmanInspect is the part of the podman container inspect output used for the status.
+type podmanInspect struct {
+	ImageName string
+	State     struct {
+		Status    string
+		StartedAt time.Time
+		Health    struct {
+			Status string
+		}
+	}
+}
+
 func podmanStatus(
 	globalFlags *types.GlobalFlags,
 	flags *statusFlags,
 	cmd *cobra.Command,
 	args []string,
 ) error {
-	var returnErr error
-	services := []string{"httpd", "salt-broker", "squid", "ssh", "tftpd", "pod"}
-	for _, service := range services {
-		serviceName := fmt.Sprintf("uyuni-proxy-%s", service)
-		if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "systemctl", "status", "--no-pager", serviceName); err != nil {
-			log.Error().Err(err).Msgf(L("Failed to get status of the %s service"), serviceName)
-			returnErr = errors.New(L("failed to get the status of at least one service"))
+	status := proxyStatus{}
+	now := time.Now()
+	for _, name := range podman.ProxyContainerNames {
+		status.Containers = append(status.Containers, inspectContainer(name, now))
+	}
+
+	if podman.IsRemote() {
+		log.Debug().Msg("Skipping the parent server connectivity check on a remote host")
+	} else if server, err := pxy_utils.ParentServer(pxy_utils.ProxyConfigPath); err != nil {
+		log.Warn().Err(err).Msg(L("Failed to read the parent server from the proxy configuration"))
+	} else {
+		status.Server = server
+		for _, port := range serverPorts {
+			status.Connectivity = append(status.Connectivity, checkPort(server, port))
+		}
+	}
+
+	if err := printStatus(&status, flags.Output); err != nil {
+		return err
+	}
+	return status.err()
+}
+
+// inspectContainer gets the state of a container, reported as missing if it doesn't exist.
+func inspectContainer(name string, now time.Time) containerStatus {
+	status := containerStatus{Name: name, State: "missing"}
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", "container", "inspect", "--format", "json", name)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to inspect container %s", name)
+		return status
+	}
+	inspected, err := parseInspect(out)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to parse container %s inspect output", name)
+		status.State = "unknown"
+		return status
+	}
+	status.State = inspected.State.Status
+	status.Health = inspected.State.Health.Status
+	status.Image = inspected.ImageName
+	if status.State == "running" && !inspected.State.StartedAt.IsZero() {
+		status.Started = inspected.State.StartedAt.Format(time.RFC3339)
+		status.Uptime = now.Sub(inspected.State.StartedAt).Round(time.Second).String()
+	}
+	return status
+}
+
+func parseInspect(out []byte) (*podmanInspect, error) {
+	var inspected []podmanInspect
+	if err := json.Unmarshal(out, &inspected); err != nil {
+		return nil, err
+	}
+	if len(inspected) != 1 {
+		return nil, fmt.Errorf(L("expected one container, got %d"), len(inspected))
+	}
+	return &inspected[0], nil
+}
+
+func checkPort(server string, port int) portStatus {
+	status := portStatus{Port: port}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(server, strconv.Itoa(port)), dialTimeout)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	conn.Close()
+	status.Reachable = true
+	return status
+}
+
+func printStatus(status *proxyStatus, format string) error {
+	containers := utils.Table{
+		Headers: []string{L("CONTAINER"), L("STATE"), L("HEALTH"), L("IMAGE"), L("UPTIME")},
+		Data:    status,
+	}
+	for _, container := range status.Containers {
+		containers.Rows = append(containers.Rows, []string{
+			container.Name, container.State, orDash(container.Health), orDash(container.Image), orDash(container.Uptime),
+		})
+	}
+	if err := containers.Print(format); err != nil || format == "json" || status.Server == "" {
+		return err
+	}
+
+	fmt.Println()
+	connectivity := utils.Table{Headers: []string{L("SERVER"), L("PORT"), L("REACHABLE")}, Data: status}
+	for _, port := range status.Connectivity {
+		reachable := L("yes")
+		if !port.Reachable {
+			reachable = L("no")
 		}
+		connectivity.Rows = append(connectivity.Rows, []string{status.Server, strconv.Itoa(port.Port), reachable})
+	}
+	return connectivity.Print(format)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// err returns an error if a container is not running or healthy or the server cannot be reached.
+func (s *proxyStatus) err() error {
+	problems := []string{}
+	for _, container := range s.Containers {
+		if container.State != "running" || container.Health == "unhealthy" {
+			problems = append(problems, container.Name)
+		}
+	}
+	for _, port := range s.Connectivity {
+		if !port.Reachable {
+			problems = append(problems, fmt.Sprintf("%s:%d", s.Server, port.Port))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf(L("the proxy is not healthy: %s"), strings.Join(problems, ", "))
 	}
-	return returnErr
+	return nil
 }
@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package status
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInspect(t *testing.T) {
+	out := `[{"ImageName": "registry.opensuse.org/uyuni/proxy-httpd:latest",
+	"State": {"Status": "running", "StartedAt": "2024-05-01T10:00:00.123456789Z", "Health": {"Status": "healthy"}}}]`
+	inspected, err := parseInspect([]byte(out))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if inspected.ImageName != "registry.opensuse.org/uyuni/proxy-httpd:latest" || inspected.State.Status != "running" ||
+		inspected.State.Health.Status != "healthy" {
+		t.Errorf("unexpected inspect result: %v", inspected)
+	}
+	expected := time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC)
+	if !inspected.State.StartedAt.Equal(expected) {
+		t.Errorf("expected start time %s, got %s", expected, inspected.State.StartedAt)
+	}
+
+	if _, err := parseInspect([]byte("[]")); err == nil {
+		t.Error("expected an error for an empty inspect output")
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	status := proxyStatus{
+		Containers: []containerStatus{
+			{Name: "uyuni-proxy-httpd", State: "running", Health: "healthy"},
+			{Name: "uyuni-proxy-squid", State: "running", Health: "unhealthy"},
+			{Name: "uyuni-proxy-ssh", State: "missing"},
+		},
+		Server:       "server.example.com",
+		Connectivity: []portStatus{{Port: 443, Reachable: true}, {Port: 4505}},
+	}
+	err := status.err()
+	expected := "the proxy is not healthy: uyuni-proxy-squid, uyuni-proxy-ssh, server.example.com:4505"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+
+	status.Containers = status.Containers[:1]
+	status.Connectivity = status.Connectivity[:1]
+	if err := status.err(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
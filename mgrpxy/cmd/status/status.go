@@ -15,6 +15,8 @@ import (
 )
 
 type statusFlags struct {
+	Namespace         string
+	utils.OutputFlags `mapstructure:",squash"`
 }
 
 // NewCommand to get the status of the server.
@@ -22,8 +24,12 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: L("Get the proxy status"),
-		Long:  L("Get the proxy status"),
-		Args:  cobra.ExactArgs(0),
+		Long: L(`Get the proxy status
+
+On podman, the state, image and uptime of each proxy container are reported
+as well as the connectivity to the parent server ports.`),
+		Args:        cobra.ExactArgs(0),
+		Annotations: map[string]string{utils.ReadOnlyAnnotation: "true", podman.RemoteAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags statusFlags
 			return utils.CommandHelper(globalFlags, cmd, args, &flags, status)
@@ -31,6 +37,11 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	}
 	cmd.SetUsageTemplate(cmd.UsageTemplate())
 
+	if utils.KubernetesBuilt {
+		utils.AddNamespaceFlag(cmd)
+	}
+	utils.AddOutputFlag(cmd)
+
 	return cmd
 }
 
@@ -39,7 +50,7 @@ func status(globalFlags *types.GlobalFlags, flags *statusFlags, cmd *cobra.Comma
 		return podmanStatus(globalFlags, flags, cmd, args)
 	}
 
-	if utils.IsInstalled("kubectl") && utils.IsInstalled("helm") {
+	if utils.IsInstalled("kubectl") {
 		return kubernetesStatus(globalFlags, flags, cmd, args)
 	}
 
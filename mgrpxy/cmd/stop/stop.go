@@ -13,16 +13,21 @@ import (
 )
 
 type stopFlags struct {
-	Backend string
+	Backend   string
+	Namespace string
 }
 
 // NewCommand to stop server.
 func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	stopCmd := &cobra.Command{
-		Use:   "stop",
+		Use:   "stop [component]",
 		Short: L("Stop the proxy"),
-		Long:  L("Stop the proxy"),
-		Args:  cobra.ExactArgs(0),
+		Long: L(`Stop the proxy
+
+A single component of the proxy can be targeted using its name: httpd, salt-broker, squid, ssh or tftpd.
+On kubernetes, this requires the component to run in its own deployment.`),
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: shared.ProxyComponents(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags stopFlags
 			return utils.CommandHelper(globalFlags, cmd, args, &flags, stop)
@@ -32,15 +37,19 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	stopCmd.SetUsageTemplate(stopCmd.UsageTemplate())
 
 	utils.AddBackendFlag(stopCmd)
+	utils.AddNamespaceFlag(stopCmd)
 
 	return stopCmd
 }
 
 func stop(globalFlags *types.GlobalFlags, flags *stopFlags, cmd *cobra.Command, args []string) error {
-	fn, err := shared.ChooseProxyPodmanOrKubernetes(cmd.Flags(), podmanStop, kubernetesStop)
+	backend, err := shared.FindBackend(cmd.Flags(), shared.ProxyApp)
 	if err != nil {
 		return err
 	}
 
-	return fn(globalFlags, flags, cmd, args)
+	if len(args) > 0 {
+		return backend.StopComponent(shared.ProxyApp, args[0], flags.Namespace)
+	}
+	return backend.Stop(shared.ProxyApp, flags.Namespace)
 }
@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tftp
+
+import (
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	pxy_utils "github.com/uyuni-project/uyuni-tools/mgrpxy/shared/utils"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type syncFlags struct {
+	ServerSsh         string `mapstructure:"server-ssh"`
+	VerifyOnly        bool   `mapstructure:"verify-only"`
+	utils.OutputFlags `mapstructure:",squash"`
+}
+
+// NewCommand for the commands handling the TFTP boot files of the proxy.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	tftpCmd := &cobra.Command{
+		Use:   "tftp",
+		Short: L("Manage the TFTP boot files of the proxy"),
+		Long:  L("Manage the TFTP boot files of the proxy"),
+	}
+
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: L("Synchronize the TFTP boot files from the server"),
+		Long: L(`Synchronize the TFTP boot files from the server
+
+The synchronization is triggered on the parent server using podman over SSH: the podman socket
+needs to be enabled on the server host and the SSH user needs to be able to connect without password.
+The proxy needs to be configured for TFTP synchronization on the server.
+The proxy boot files are then compared with the server ones.`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags syncFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, utils.WithAudit(sync))
+		},
+	}
+	syncCmd.Flags().String("server-ssh", "",
+		L("user@host to connect to the server with SSH. Defaults to root and the parent server of the proxy"))
+	syncCmd.Flags().Bool("verify-only", false, L("Only compare the boot files without triggering the synchronization"))
+	utils.AddOutputFlag(syncCmd)
+
+	tftpCmd.AddCommand(syncCmd)
+	return tftpCmd
+}
+
+func sync(globalFlags *types.GlobalFlags, flags *syncFlags, cmd *cobra.Command, args []string) error {
+	serverSsh := flags.ServerSsh
+	if serverSsh == "" {
+		server, err := pxy_utils.ParentServer(pxy_utils.ProxyConfigPath)
+		if err != nil {
+			return utils.Errorf(err, L("failed to find the parent server: %s"))
+		}
+		serverSsh = "root@" + server
+	}
+	serverArgs := []string{"--url", podman.RemoteURL(serverSsh), "exec", podman.ServerContainerName}
+
+	if !flags.VerifyOnly {
+		log.Info().Msg(L("Synchronizing the TFTP boot files from the server"))
+		if err := utils.RunCmd("podman", append(serverArgs, "cobbler", "sync")...); err != nil {
+			return utils.Errorf(err, L("failed to synchronize the TFTP boot files: %s"))
+		}
+	}
+
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", append(serverArgs, utils.TftpChecksumsArgs...)...)
+	if err != nil {
+		return utils.Errorf(err, L("failed to compute the checksums of the server TFTP boot files: %s"))
+	}
+	serverChecksums := utils.ParseChecksums(out)
+
+	out, err = utils.RunCmdOutput(zerolog.DebugLevel, "podman",
+		append([]string{"exec", "uyuni-proxy-httpd"}, utils.TftpChecksumsArgs...)...)
+	if err != nil {
+		return utils.Errorf(err, L("failed to compute the checksums of the TFTP boot files: %s"))
+	}
+
+	report := utils.CompareTftpChecksums(serverChecksums, utils.ParseChecksums(out))
+	report.Proxy, _ = os.Hostname()
+	return utils.PrintTftpReports([]*utils.TftpReport{report}, flags.Output)
+}
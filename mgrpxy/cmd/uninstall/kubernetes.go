@@ -8,7 +8,7 @@ import (
 	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 )
 
-func uninstallForKubernetes(dryRun bool) error {
+func uninstallForKubernetes(namespace string, dryRun bool) error {
 	clusterInfos, err := kubernetes.CheckCluster()
 	if err != nil {
 		return err
@@ -18,7 +18,7 @@ func uninstallForKubernetes(dryRun bool) error {
 	// TODO Find all the PVs related to the server if we want to delete them
 
 	// Uninstall uyuni
-	if _, err := kubernetes.HelmUninstall(kubeconfig, "uyuni-proxy", "", dryRun); err != nil {
+	if _, err := kubernetes.HelmUninstall(kubeconfig, namespace, "uyuni-proxy", "", dryRun); err != nil {
 		return err
 	}
 
@@ -5,8 +5,6 @@
 package uninstall
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/shared"
 	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
@@ -26,32 +24,32 @@ By default it will only print what would be done, use --force to actually remove
 		Args: cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			force, _ := cmd.Flags().GetBool("force")
-			purge, _ := cmd.Flags().GetBool("purgeVolumes")
+			purge, _ := cmd.Flags().GetBool("purge-volumes")
 
 			backend, _ := cmd.Flags().GetString("backend")
+			namespace, _ := cmd.Flags().GetString("namespace")
 
-			cnx := shared.NewConnection(backend, podman.ProxyContainerNames[0], kubernetes.ProxyFilter)
+			cnx := shared.NewConnection(backend, podman.ProxyContainerNames[0], kubernetes.ProxyFilter, namespace)
 			command, err := cnx.GetCommand()
 			if err != nil {
-				return fmt.Errorf(L("failed to determine suitable backend: %s"), err)
+				return utils.Errorf(err, L("failed to determine suitable backend: %s"))
 			}
 			switch command {
 			case "podman":
-				if err := uninstallForPodman(!force, purge); err != nil {
-					return err
-				}
+				err = uninstallForPodman(!force, purge)
 			case "kubectl":
-				if err := uninstallForKubernetes(!force); err != nil {
-					return err
-				}
+				err = uninstallForKubernetes(namespace, !force)
 			}
-			return nil
+			utils.RecordAudit(cmd, args, err)
+			return err
 		},
 	}
 	uninstallCmd.Flags().BoolP("force", "f", false, L("Actually remove the proxy"))
-	uninstallCmd.Flags().Bool("purgeVolumes", false, L("Also remove the volumes"))
+	uninstallCmd.Flags().Bool("purge-volumes", false, L("Also remove the volumes"))
+	utils.RenameFlag(uninstallCmd, "purgeVolumes", "purge-volumes")
 
 	utils.AddBackendFlag(uninstallCmd)
+	utils.AddNamespaceFlag(uninstallCmd)
 
 	return uninstallCmd, nil
 }
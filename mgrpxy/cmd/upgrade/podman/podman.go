@@ -9,6 +9,7 @@ import (
 	"github.com/uyuni-project/uyuni-tools/mgrpxy/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/mgrpxy/shared/utils"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	shared_podman "github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	shared_utils "github.com/uyuni-project/uyuni-tools/shared/utils"
 )
@@ -27,11 +28,12 @@ NOTE: for now upgrading on a remote podman is not supported!
 		Args: cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var flags podman.PodmanProxyUpgradeFlags
-			return shared_utils.CommandHelper(globalFlags, cmd, args, &flags, upgradePodman)
+			return shared_utils.CommandHelper(globalFlags, cmd, args, &flags, shared_utils.WithAudit(upgradePodman))
 		},
 	}
 
 	utils.AddImageUpgradeFlags(podmanCmd)
+	shared_podman.AddPodmanArgFlag(podmanCmd)
 
 	return podmanCmd
 }
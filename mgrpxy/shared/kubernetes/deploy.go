@@ -5,9 +5,7 @@
 package kubernetes
 
 import (
-	"fmt"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
 
@@ -42,21 +40,21 @@ func Deploy(imageFlags *utils.ProxyImageFlags, helmFlags *HelmFlags, configDir s
 	}
 
 	if !shared_utils.FileExists(path.Join(configDir, "httpd.yaml")) {
-		if _, err := getHTTPDYaml(configDir); err != nil {
+		if _, err := getHTTPDYaml(helmFlags.Proxy.Namespace, configDir); err != nil {
 			return err
 		}
 	}
 	helmParams = append(helmParams, "-f", path.Join(configDir, "httpd.yaml"))
 
 	if !shared_utils.FileExists(path.Join(configDir, "ssh.yaml")) {
-		if _, err := getSSHYaml(configDir); err != nil {
+		if _, err := getSSHYaml(helmFlags.Proxy.Namespace, configDir); err != nil {
 			return err
 		}
 	}
 	helmParams = append(helmParams, "-f", path.Join(configDir, "ssh.yaml"))
 
 	if !shared_utils.FileExists(path.Join(configDir, "config.yaml")) {
-		if _, err := getConfigYaml(configDir); err != nil {
+		if _, err := getConfigYaml(helmFlags.Proxy.Namespace, configDir); err != nil {
 			return err
 		}
 	}
@@ -77,15 +75,15 @@ func Deploy(imageFlags *utils.ProxyImageFlags, helmFlags *HelmFlags, configDir s
 	// Install the helm chart
 	if err := kubernetes.HelmUpgrade(kubeconfig, helmFlags.Proxy.Namespace, true, "", helmAppName, helmFlags.Proxy.Chart,
 		helmFlags.Proxy.Version, helmParams...); err != nil {
-		return fmt.Errorf(L("cannot run helm upgrade: %s"), err)
+		return shared_utils.Errorf(err, L("cannot run helm upgrade: %s"))
 	}
 
 	// Wait for the pod to be started
 	return kubernetes.WaitForDeployment(helmFlags.Proxy.Namespace, helmAppName, "uyuni-proxy")
 }
 
-func getSSHYaml(directory string) (string, error) {
-	sshPayload, err := kubernetes.GetSecret("proxy-secret", "-o=jsonpath={.data.ssh\\.yaml}")
+func getSSHYaml(namespace string, directory string) (string, error) {
+	sshPayload, err := kubernetes.GetSecret(namespace, "proxy-secret", "ssh.yaml")
 	if err != nil {
 		return "", err
 	}
@@ -93,14 +91,14 @@ func getSSHYaml(directory string) (string, error) {
 	sshYamlFilename := filepath.Join(directory, "ssh.yaml")
 	err = os.WriteFile(sshYamlFilename, []byte(sshPayload), 0644)
 	if err != nil {
-		return "", fmt.Errorf(L("failed to write in file %s: %s"), sshYamlFilename, err)
+		return "", shared_utils.Errorf(err, L("failed to write in file %s: %s"), sshYamlFilename)
 	}
 
 	return sshYamlFilename, nil
 }
 
-func getHTTPDYaml(directory string) (string, error) {
-	httpdPayload, err := kubernetes.GetSecret("proxy-secret", "-o=jsonpath={.data.httpd\\.yaml}")
+func getHTTPDYaml(namespace string, directory string) (string, error) {
+	httpdPayload, err := kubernetes.GetSecret(namespace, "proxy-secret", "httpd.yaml")
 	if err != nil {
 		return "", err
 	}
@@ -108,14 +106,14 @@ func getHTTPDYaml(directory string) (string, error) {
 	httpdYamlFilename := filepath.Join(directory, "httpd.yaml")
 	err = os.WriteFile(httpdYamlFilename, []byte(httpdPayload), 0644)
 	if err != nil {
-		return "", fmt.Errorf(L("failed to write in file %s: %s"), httpdYamlFilename, err)
+		return "", shared_utils.Errorf(err, L("failed to write in file %s: %s"), httpdYamlFilename)
 	}
 
 	return httpdYamlFilename, nil
 }
 
-func getConfigYaml(directory string) (string, error) {
-	configPayload, err := kubernetes.GetConfigMap("proxy-configMap", "-o=jsonpath={.data.config\\.yaml}")
+func getConfigYaml(namespace string, directory string) (string, error) {
+	configPayload, err := kubernetes.GetConfigMap(namespace, "proxy-configMap", "config.yaml")
 	if err != nil {
 		return "", err
 	}
@@ -123,7 +121,7 @@ func getConfigYaml(directory string) (string, error) {
 	configYamlFilename := filepath.Join(directory, "config.yaml")
 	err = os.WriteFile(configYamlFilename, []byte(configPayload), 0644)
 	if err != nil {
-		return "", fmt.Errorf(L("failed to write in file %s: %s"), configYamlFilename, err)
+		return "", shared_utils.Errorf(err, L("failed to write in file %s: %s"), configYamlFilename)
 	}
 
 	return configYamlFilename, nil
@@ -132,15 +130,9 @@ func getConfigYaml(directory string) (string, error) {
 // Upgrade will upgrade the current kubernetes proxy.
 func Upgrade(flags *KubernetesProxyUpgradeFlags, cmd *cobra.Command, args []string,
 ) error {
-	for _, binary := range []string{"kubectl", "helm"} {
-		if _, err := exec.LookPath(binary); err != nil {
-			return fmt.Errorf(L("install %s before running this command"), binary)
-		}
-	}
-
 	tmpDir, err := os.MkdirTemp("", "mgrpxy-*")
 	if err != nil {
-		return fmt.Errorf(L("failed to create temporary directory: %s"), err)
+		return shared_utils.Errorf(err, L("failed to create temporary directory: %s"))
 	}
 	defer os.RemoveAll(tmpDir)
 
@@ -150,22 +142,23 @@ func Upgrade(flags *KubernetesProxyUpgradeFlags, cmd *cobra.Command, args []stri
 		return err
 	}
 
-	err = kubernetes.ReplicasTo(kubernetes.ProxyFilter, 0)
+	namespace := flags.Helm.Proxy.Namespace
+	err = kubernetes.ReplicasTo(namespace, kubernetes.ProxyFilter, 0)
 	if err != nil {
 		return err
 	}
 
 	defer func() {
 		// if something is running, we don't need to set replicas to 1
-		if _, err = kubernetes.GetNode("uyuni"); err != nil {
-			err = kubernetes.ReplicasTo(kubernetes.ProxyFilter, 1)
+		if _, err = kubernetes.GetNode(namespace, kubernetes.ProxyFilter); err != nil {
+			err = kubernetes.ReplicasTo(namespace, kubernetes.ProxyFilter, 1)
 		}
 	}()
 
 	// Install the uyuni proxy helm chart
 	if err := Deploy(&flags.ProxyImageFlags, &flags.Helm, tmpDir, clusterInfos.GetKubeconfig(),
 		"--set", "ingress="+clusterInfos.Ingress); err != nil {
-		return fmt.Errorf(L("cannot deploy proxy helm chart: %s"), err)
+		return shared_utils.Errorf(err, L("cannot deploy proxy helm chart: %s"))
 	}
 
 	return nil
@@ -9,7 +9,6 @@ import (
 	"os"
 	"os/exec"
 	"path"
-	"strings"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -27,77 +26,72 @@ type PodmanProxyUpgradeFlags struct {
 	Podman                podman.PodmanFlags
 }
 
-// GenerateSystemdService generates all the systemd files required by proxy.
-func GenerateSystemdService(httpdImage string, saltBrokerImage string, squidImage string, sshImage string,
-	tftpdImage string, podmanArgs []string) error {
-	if err := podman.SetupNetwork(); err != nil {
-		return fmt.Errorf(L("cannot setup network: %s"), err)
-	}
-
-	log.Info().Msg(L("Generating systemd services"))
-	httpProxyConfig := getHttpProxyConfig()
+// systemdTemplate is the template of a proxy systemd service with the name of the service.
+type systemdTemplate struct {
+	name     string
+	template shared_utils.Template
+}
 
+// systemdTemplates returns the templates of the proxy systemd services, the pod first.
+func systemdTemplates(httpdImage string, saltBrokerImage string, squidImage string, sshImage string,
+	tftpdImage string, httpProxyConfig string) []systemdTemplate {
 	ports := []types.PortMap{}
 	ports = append(ports, shared_utils.PROXY_TCP_PORTS...)
 	ports = append(ports, shared_utils.PROXY_PODMAN_PORTS...)
 	ports = append(ports, shared_utils.UDP_PORTS...)
 
-	// Pod
-	dataPod := templates.PodTemplateData{
-		Ports:         ports,
-		HttpProxyFile: httpProxyConfig,
-		Args:          strings.Join(podmanArgs, " "),
-		Network:       podman.UyuniNetwork,
-	}
-	if err := generateSystemdFile(dataPod, "pod"); err != nil {
-		return err
-	}
-
-	// Httpd
-	dataHttpd := templates.HttpdTemplateData{
-		Volumes:       shared_utils.PROXY_HTTPD_VOLUMES,
-		HttpProxyFile: httpProxyConfig,
-		Image:         httpdImage,
-	}
-	if err := generateSystemdFile(dataHttpd, "httpd"); err != nil {
-		return err
-	}
-
-	// Salt broker
-	dataSaltBroker := templates.SaltBrokerTemplateData{
-		HttpProxyFile: httpProxyConfig,
-		Image:         saltBrokerImage,
-	}
-	if err := generateSystemdFile(dataSaltBroker, "salt-broker"); err != nil {
-		return err
+	return []systemdTemplate{
+		{"pod", templates.PodTemplateData{
+			Ports:         ports,
+			HttpProxyFile: httpProxyConfig,
+			Network:       podman.UyuniNetwork,
+		}},
+		{"httpd", templates.HttpdTemplateData{
+			Volumes:       shared_utils.PROXY_HTTPD_VOLUMES,
+			HttpProxyFile: httpProxyConfig,
+			Image:         httpdImage,
+		}},
+		{"salt-broker", templates.SaltBrokerTemplateData{
+			HttpProxyFile: httpProxyConfig,
+			Image:         saltBrokerImage,
+		}},
+		{"squid", templates.SquidTemplateData{
+			Volumes:       shared_utils.PROXY_SQUID_VOLUMES,
+			HttpProxyFile: httpProxyConfig,
+			Image:         squidImage,
+		}},
+		{"ssh", templates.SSHTemplateData{
+			HttpProxyFile: httpProxyConfig,
+			Image:         sshImage,
+		}},
+		{"tftpd", templates.TFTPDTemplateData{
+			Volumes:       shared_utils.PROXY_TFTPD_VOLUMES,
+			HttpProxyFile: httpProxyConfig,
+			Image:         tftpdImage,
+		}},
 	}
+}
 
-	// Squid
-	dataSquid := templates.SquidTemplateData{
-		Volumes:       shared_utils.PROXY_SQUID_VOLUMES,
-		HttpProxyFile: httpProxyConfig,
-		Image:         squidImage,
-	}
-	if err := generateSystemdFile(dataSquid, "squid"); err != nil {
-		return err
+// GenerateSystemdService generates all the systemd files required by proxy.
+//
+// The podman arguments are kept from the previous deployment unless the corresponding flags are set in cmd.
+func GenerateSystemdService(httpdImage string, saltBrokerImage string, squidImage string, sshImage string,
+	tftpdImage string, podmanFlags *podman.PodmanFlags, cmd *cobra.Command) error {
+	if err := podman.SetupNetwork(); err != nil {
+		return shared_utils.Errorf(err, L("cannot setup network: %s"))
 	}
 
-	// SSH
-	dataSSH := templates.SSHTemplateData{
-		HttpProxyFile: httpProxyConfig,
-		Image:         sshImage,
+	log.Info().Msg(L("Generating systemd services"))
+	services := systemdTemplates(httpdImage, saltBrokerImage, squidImage, sshImage, tftpdImage, getHttpProxyConfig())
+	for _, service := range services {
+		if err := generateSystemdFile(service.template, service.name); err != nil {
+			return err
+		}
 	}
-	if err := generateSystemdFile(dataSSH, "ssh"); err != nil {
+	if err := podman.UpdateExtraArgsConf(podman.ProxyService, podmanFlags, cmd); err != nil {
 		return err
 	}
-
-	// Tftpd
-	dataTftpd := templates.TFTPDTemplateData{
-		Volumes:       shared_utils.PROXY_TFTPD_VOLUMES,
-		HttpProxyFile: httpProxyConfig,
-		Image:         tftpdImage,
-	}
-	if err := generateSystemdFile(dataTftpd, "tftpd"); err != nil {
+	if err := podman.UpdateHardeningConf(podman.ProxyHardenedServices, podmanFlags, cmd); err != nil {
 		return err
 	}
 
@@ -110,7 +104,7 @@ func generateSystemdFile(template shared_utils.Template, service string) error {
 	const systemdPath = "/etc/systemd/system"
 	path := path.Join(systemdPath, name)
 	if err := shared_utils.WriteTemplateToFile(template, path, 0644, true); err != nil {
-		return fmt.Errorf(L("failed to generate systemd file '%s': %s"), path, err)
+		return shared_utils.Errorf(err, L("failed to generate systemd file '%s': %s"), path)
 	}
 	return nil
 }
@@ -130,15 +124,10 @@ func GetContainerImage(flags *utils.ProxyImageFlags, name string) (string, error
 	image := flags.GetContainerImage(name)
 	inspectedHostValues, err := shared_utils.InspectHost()
 	if err != nil {
-		return "", fmt.Errorf(L("cannot inspect host values: %s"), err)
+		return "", shared_utils.Errorf(err, L("cannot inspect host values: %s"))
 	}
 
-	pullArgs := []string{}
-	_, scc_user_exist := inspectedHostValues["host_scc_username"]
-	_, scc_user_password := inspectedHostValues["host_scc_password"]
-	if scc_user_exist && scc_user_password {
-		pullArgs = append(pullArgs, "--creds", inspectedHostValues["host_scc_username"]+":"+inspectedHostValues["host_scc_password"])
-	}
+	pullArgs := podman.GetPullArgs(inspectedHostValues)
 
 	preparedImage, err := podman.PrepareImage(image, flags.PullPolicy, pullArgs...)
 	if err != nil {
@@ -167,7 +156,6 @@ func Upgrade(globalFlags *types.GlobalFlags, flags *PodmanProxyUpgradeFlags, cmd
 	if _, err := exec.LookPath("podman"); err != nil {
 		return fmt.Errorf(L("install podman before running this command"))
 	}
-
 	httpdImage, err := getContainerImage(&flags.ProxyImageFlags, "httpd")
 	if err != nil {
 		log.Info().Msgf(L("cannot find httpd image: it will no be upgraded"))
@@ -190,7 +178,7 @@ func Upgrade(globalFlags *types.GlobalFlags, flags *PodmanProxyUpgradeFlags, cmd
 	}
 
 	// Setup the systemd service configuration options
-	if err := GenerateSystemdService(httpdImage, saltBrokerImage, squidImage, sshImage, tftpdImage, flags.Podman.Args); err != nil {
+	if err := GenerateSystemdService(httpdImage, saltBrokerImage, squidImage, sshImage, tftpdImage, &flags.Podman, cmd); err != nil {
 		return err
 	}
 
@@ -201,15 +189,10 @@ func getContainerImage(flags *utils.ProxyImageFlags, name string) (string, error
 	image := flags.GetContainerImage(name)
 	inspectedHostValues, err := shared_utils.InspectHost()
 	if err != nil {
-		return "", fmt.Errorf(L("cannot inspect host values: %s"), err)
+		return "", shared_utils.Errorf(err, L("cannot inspect host values: %s"))
 	}
 
-	pullArgs := []string{}
-	_, scc_user_exist := inspectedHostValues["host_scc_username"]
-	_, scc_user_password := inspectedHostValues["host_scc_password"]
-	if scc_user_exist && scc_user_password {
-		pullArgs = append(pullArgs, "--creds", inspectedHostValues["host_scc_username"]+":"+inspectedHostValues["host_scc_password"])
-	}
+	pullArgs := podman.GetPullArgs(inspectedHostValues)
 
 	preparedImage, err := podman.PrepareImage(image, flags.PullPolicy, pullArgs...)
 	if err != nil {
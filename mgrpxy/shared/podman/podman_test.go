@@ -0,0 +1,34 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestSystemdTemplates(t *testing.T) {
+	services := systemdTemplates(
+		"registry.opensuse.org/uyuni/proxy-httpd:2024.07",
+		"registry.opensuse.org/uyuni/proxy-salt-broker:2024.07",
+		"registry.opensuse.org/uyuni/proxy-squid:2024.07",
+		"registry.opensuse.org/uyuni/proxy-ssh:2024.07",
+		"registry.opensuse.org/uyuni/proxy-tftpd:2024.07",
+		"/etc/sysconfig/proxy",
+	)
+	if services[0].name != "pod" {
+		t.Errorf("the pod service needs to be generated first, got %s", services[0].name)
+	}
+	for _, service := range services {
+		var buf bytes.Buffer
+		if err := service.template.Render(&buf); err != nil {
+			t.Fatalf("failed to render %s: %s", service.name, err)
+		}
+		testutils.AssertGolden(t, "uyuni-proxy-"+service.name+".service", buf.Bytes())
+		testutils.ValidateSystemdUnit(t, "uyuni-proxy-"+service.name+".service", buf.Bytes())
+	}
+}
@@ -34,7 +34,7 @@ ExecStartPre=/usr/bin/podman pod create --infra-conmon-pidfile %t/uyuni-proxy-po
         {{- range .Ports }}
         -p {{ .Exposed }}:{{ .Port }}{{ if .Protocol }}/{{ .Protocol }}{{ end }} \
         {{- end }}
-		--replace {{ .Args }}
+		--replace $PODMAN_EXTRA_ARGS
 
 ExecStart=/usr/bin/podman pod start --pod-id-file %t/uyuni-proxy-pod.pod-id
 ExecStop=/usr/bin/podman pod stop --ignore --pod-id-file %t/uyuni-proxy-pod.pod-id -t 10
@@ -52,7 +52,6 @@ WantedBy=multi-user.target default.target
 type PodTemplateData struct {
 	Ports         []types.PortMap
 	HttpProxyFile string
-	Args          string
 	Network       string
 }
 
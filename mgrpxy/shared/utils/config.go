@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// ProxyConfigPath is the configuration of the proxy containers generated by the server.
+const ProxyConfigPath = "/etc/uyuni/proxy/config.yaml"
+
+// ParentServer reads the FQDN of the parent server from the proxy configuration file.
+func ParentServer(configPath string) (string, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", err
+	}
+	var config struct {
+		Server string `yaml:"server"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return "", utils.Errorf(err, L("failed to parse %[1]s: %[2]s"), path.Base(configPath))
+	}
+	if config.Server == "" {
+		return "", fmt.Errorf(L("no server defined in %s"), configPath)
+	}
+	return config.Server, nil
+}
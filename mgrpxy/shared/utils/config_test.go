@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParentServer(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("server: server.example.com\nmax_cache_size_mb: 2048\n"), 0600); err != nil {
+		t.Fatalf("failed to write the configuration: %s", err)
+	}
+	server, err := ParentServer(configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if server != "server.example.com" {
+		t.Errorf("expected server.example.com, got %s", server)
+	}
+}
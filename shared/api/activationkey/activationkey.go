@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package activationkey
+
+import (
+	"errors"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// List returns the activation keys of the user organization.
+func List(client *api.HTTPClient) ([]types.ActivationKey, error) {
+	res, err := api.Get[[]types.ActivationKey](client, "activationkey/listActivationKeys")
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the activation keys: %s"))
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// Create creates an activation key and returns its full key.
+//
+// An empty key lets the server generate one. A zero usage limit means unlimited.
+// The server prefixes the key with the organization ID.
+func Create(client *api.HTTPClient, key *types.ActivationKey) (string, error) {
+	data := map[string]interface{}{
+		"key":              key.Key,
+		"description":      key.Description,
+		"baseChannelLabel": key.BaseChannelLabel,
+		"entitlements":     key.Entitlements,
+		"universalDefault": key.UniversalDefault,
+	}
+	if key.UsageLimit > 0 {
+		data["usageLimit"] = key.UsageLimit
+	}
+	res, err := api.Post[string](client, "activationkey/create", data)
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to create the activation key: %s"))
+	}
+	if !res.Success {
+		return "", errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// AddServerGroups adds system groups to an activation key.
+func AddServerGroups(client *api.HTTPClient, key string, groupIds []int) error {
+	data := map[string]interface{}{
+		"key":            key,
+		"serverGroupIds": groupIds,
+	}
+	res, err := api.Post[int](client, "activationkey/addServerGroups", data)
+	if err != nil {
+		return utils.Errorf(err, L("failed to add the system groups to the %[1]s activation key: %[2]s"), key)
+	}
+	if !res.Success {
+		return errors.New(res.Message)
+	}
+	return nil
+}
+
+// Delete deletes an activation key.
+func Delete(client *api.HTTPClient, key string) error {
+	res, err := api.Post[int](client, "activationkey/delete", map[string]interface{}{"key": key})
+	if err != nil {
+		return utils.Errorf(err, L("failed to delete the %[1]s activation key: %[2]s"), key)
+	}
+	if !res.Success {
+		return errors.New(res.Message)
+	}
+	return nil
+}
@@ -19,11 +19,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 )
 
 const root_path_apiv1 = "/rhn/manager/api"
 
+// sessionCookie is the name of the cookie holding the API session.
+const sessionCookie = "pxt-session-cookie"
+
 // HTTP Client is an API entrypoint.
 type HTTPClient struct {
 
@@ -162,14 +166,56 @@ func Init(conn *ConnectionDetails) (*HTTPClient, error) {
 	}
 
 	if len(conn.User) > 0 {
+		if len(conn.Password) == 0 && client.restoreSession(conn) {
+			return client, nil
+		}
 		if len(conn.Password) == 0 {
-			utils.AskPasswordIfMissing(&conn.Password, L("API server password"), 0, 0)
+			utils.AskPasswordIfMissing(&conn.Password, L("API server password"), 0, 0, nil)
+		}
+		err = utils.WithCode(utils.CodeAPI, client.login(conn))
+		if err == nil {
+			client.storeSession(conn)
 		}
-		err = client.login(conn)
 	}
 	return client, err
 }
 
+// restoreSession reuses the session stored by a previous login, if it is still valid.
+func (c *HTTPClient) restoreSession(conn *ConnectionDetails) bool {
+	key := utils.APISessionKey(conn.Server, conn.User)
+	session := utils.GetCredentials(key)
+	if session == nil || session.User != conn.User {
+		return false
+	}
+	c.AuthCookie = &http.Cookie{Name: sessionCookie, Value: session.Password}
+
+	// Check the session has not been closed on the server side
+	res, err := c.Get("user/getDetails?login=" + url.QueryEscape(conn.User))
+	if err != nil {
+		log.Debug().Err(err).Msg("Stored API session is no longer valid")
+		c.AuthCookie = nil
+		if err := utils.DeleteCredentials(key); err != nil {
+			log.Debug().Err(err).Msg("Failed to remove the invalid API session")
+		}
+		return false
+	}
+	res.Body.Close()
+	log.Debug().Msgf("Reusing the stored API session of %s", conn.User)
+	return true
+}
+
+// storeSession saves the session cookie in the credentials store to avoid asking the password again.
+func (c *HTTPClient) storeSession(conn *ConnectionDetails) {
+	session := utils.Credentials{
+		User:     conn.User,
+		Password: c.AuthCookie.Value,
+		Expires:  time.Now().Add(time.Duration(c.AuthCookie.MaxAge) * time.Second),
+	}
+	if err := utils.StoreCredentials(utils.APISessionKey(conn.Server, conn.User), session); err != nil {
+		log.Warn().Err(err).Msg(L("Failed to store the API session"))
+	}
+}
+
 func (c *HTTPClient) login(conn *ConnectionDetails) error {
 	url := fmt.Sprintf("%s/%s", c.BaseURL, "auth/login")
 	data := map[string]string{
@@ -201,7 +247,7 @@ func (c *HTTPClient) login(conn *ConnectionDetails) error {
 
 	cookies := res.Cookies()
 	for _, cookie := range cookies {
-		if cookie.Name == "pxt-session-cookie" && cookie.MaxAge > 0 {
+		if cookie.Name == sessionCookie && cookie.MaxAge > 0 {
 			c.AuthCookie = cookie
 			break
 		}
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package configchannel
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ListFiles returns the files of a configuration channel without their contents.
+func ListFiles(client *api.HTTPClient, channel string) ([]types.ConfigFile, error) {
+	res, err := api.Get[[]types.ConfigFile](client, "configchannel/listFiles?label="+url.QueryEscape(channel))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the files of configuration channel %[1]s: %[2]s"), channel)
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// LookupFiles returns the files of a configuration channel with their contents.
+func LookupFiles(client *api.HTTPClient, channel string, paths []string) ([]types.ConfigFile, error) {
+	data := map[string]interface{}{
+		"label": channel,
+		"paths": paths,
+	}
+	res, err := api.Post[[]types.ConfigFile](client, "configchannel/lookupFileInfo", data)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the files of configuration channel %[1]s: %[2]s"), channel)
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// CreateOrUpdatePath creates or updates a file or directory in a configuration channel.
+func CreateOrUpdatePath(client *api.HTTPClient, channel string, file *types.ConfigFile) error {
+	pathInfo := map[string]interface{}{
+		"owner":       file.Owner,
+		"group":       file.Group,
+		"permissions": file.Permissions,
+	}
+	if file.Type != "directory" {
+		pathInfo["contents"] = file.Contents
+		pathInfo["contents_enc64"] = file.ContentsEnc64
+		pathInfo["binary"] = file.Binary
+	}
+	data := map[string]interface{}{
+		"label":    channel,
+		"path":     file.Path,
+		"isDir":    file.Type == "directory",
+		"pathInfo": pathInfo,
+	}
+	res, err := api.Post[types.ConfigFile](client, "configchannel/createOrUpdatePath", data)
+	if err != nil {
+		return utils.Errorf(err, L("failed to update %[1]s in configuration channel %[2]s: %[3]s"), file.Path, channel)
+	}
+	if !res.Success {
+		return errors.New(res.Message)
+	}
+	return nil
+}
+
+// CreateOrUpdateSymlink creates or updates a symbolic link in a configuration channel.
+func CreateOrUpdateSymlink(client *api.HTTPClient, channel string, file *types.ConfigFile) error {
+	data := map[string]interface{}{
+		"label":    channel,
+		"path":     file.Path,
+		"pathInfo": map[string]interface{}{"target_path": file.TargetPath},
+	}
+	res, err := api.Post[types.ConfigFile](client, "configchannel/createOrUpdateSymlink", data)
+	if err != nil {
+		return utils.Errorf(err, L("failed to update %[1]s in configuration channel %[2]s: %[3]s"), file.Path, channel)
+	}
+	if !res.Success {
+		return errors.New(res.Message)
+	}
+	return nil
+}
+
+// DeleteFiles removes files from a configuration channel.
+func DeleteFiles(client *api.HTTPClient, channel string, paths []string) error {
+	data := map[string]interface{}{
+		"label": channel,
+		"paths": paths,
+	}
+	res, err := api.Post[int](client, "configchannel/deleteFiles", data)
+	if err != nil {
+		return utils.Errorf(err, L("failed to delete files from configuration channel %[1]s: %[2]s"), channel)
+	}
+	if !res.Success {
+		return errors.New(res.Message)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package contentmanagement
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ListProjects returns the content lifecycle management projects.
+func ListProjects(client *api.HTTPClient) ([]types.ContentProject, error) {
+	res, err := api.Get[[]types.ContentProject](client, "contentmanagement/listProjects")
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the content projects: %s"))
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// ListEnvironments returns the environments of a content project in the promotion order.
+func ListEnvironments(client *api.HTTPClient, project string) ([]types.ContentEnvironment, error) {
+	res, err := api.Get[[]types.ContentEnvironment](client,
+		"contentmanagement/listProjectEnvironments?projectLabel="+url.QueryEscape(project))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the environments of project %[1]s: %[2]s"), project)
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// BuildProject builds a content project into its first environment.
+func BuildProject(client *api.HTTPClient, project string, message string) error {
+	data := map[string]interface{}{
+		"projectLabel": project,
+		"message":      message,
+	}
+	res, err := api.Post[int](client, "contentmanagement/buildProject", data)
+	if err != nil {
+		return utils.Errorf(err, L("failed to build project %[1]s: %[2]s"), project)
+	}
+	if !res.Success {
+		return errors.New(res.Message)
+	}
+	return nil
+}
+
+// PromoteProject promotes an environment of a content project to the next one.
+func PromoteProject(client *api.HTTPClient, project string, environment string) error {
+	data := map[string]interface{}{
+		"projectLabel": project,
+		"envLabel":     environment,
+	}
+	res, err := api.Post[int](client, "contentmanagement/promoteProject", data)
+	if err != nil {
+		return utils.Errorf(err, L("failed to promote environment %[1]s of project %[2]s: %[3]s"), environment, project)
+	}
+	if !res.Success {
+		return errors.New(res.Message)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package proxy
+
+import (
+	"errors"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ContainerConfig generates the configuration archive of a containerized proxy.
+func ContainerConfig(client *api.HTTPClient, request *types.ProxyConfigRequest) ([]byte, error) {
+	data := map[string]interface{}{
+		"proxyName": request.ProxyName,
+		"proxyPort": request.ProxyPort,
+		"server":    request.Server,
+		"maxCache":  request.MaxCache,
+		"email":     request.Email,
+	}
+	if request.ProxyCrt != "" {
+		data["rootCA"] = request.RootCA
+		data["intermediateCAs"] = request.IntermediateCAs
+		data["proxyCrt"] = request.ProxyCrt
+		data["proxyKey"] = request.ProxyKey
+	} else {
+		data["caCrt"] = request.CaCrt
+		data["caKey"] = request.CaKey
+		data["caPassword"] = request.CaPassword
+		data["cnames"] = []string{}
+		data["country"] = ""
+		data["state"] = ""
+		data["city"] = ""
+		data["org"] = ""
+		data["orgUnit"] = ""
+		data["sslEmail"] = request.Email
+	}
+
+	res, err := api.Post[[]int](client, "proxy/containerConfig", data)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to generate the configuration of proxy %[1]s: %[2]s"), request.ProxyName)
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+
+	// The archive bytes are returned as a list of numbers
+	config := make([]byte, len(res.Result))
+	for i, value := range res.Result {
+		config[i] = byte(value)
+	}
+	return config, nil
+}
+
+// List returns the registered proxies.
+func List(client *api.HTTPClient) ([]types.SystemInfo, error) {
+	res, err := api.Get[[]types.SystemInfo](client, "proxy/listProxies")
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the proxies: %s"))
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// RelevantErrata returns the patches that can be applied on a system.
+func RelevantErrata(client *api.HTTPClient, systemId int) ([]types.ErrataInfo, error) {
+	res, err := api.Get[[]types.ErrataInfo](client, fmt.Sprintf("system/getRelevantErrata?sid=%d", systemId))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the relevant patches of system %[1]d: %[2]s"), systemId)
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// ScheduleApplyErrata schedules the installation of patches on systems and returns the action IDs.
+func ScheduleApplyErrata(client *api.HTTPClient, systemIds []int, errataIds []int, at time.Time) ([]int, error) {
+	data := map[string]interface{}{
+		"sids":               systemIds,
+		"errataIds":          errataIds,
+		"earliestOccurrence": at.Format(time.RFC3339),
+	}
+	res, err := api.Post[[]int](client, "system/scheduleApplyErrata", data)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to schedule the patches installation: %s"))
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// ScheduleReboot schedules the reboot of a system and returns the action ID.
+func ScheduleReboot(client *api.HTTPClient, systemId int, at time.Time) (int, error) {
+	data := map[string]interface{}{
+		"sid":                systemId,
+		"earliestOccurrence": at.Format(time.RFC3339),
+	}
+	res, err := api.Post[int](client, "system/scheduleReboot", data)
+	if err != nil {
+		return 0, utils.Errorf(err, L("failed to schedule the reboot of system %[1]d: %[2]s"), systemId)
+	}
+	if !res.Success {
+		return 0, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// ScheduleHighstate schedules a highstate on systems and returns the action ID.
+//
+// Set test to true to only show what the highstate would change.
+func ScheduleHighstate(client *api.HTTPClient, systemIds []int, at time.Time, test bool) (int, error) {
+	data := map[string]interface{}{
+		"sids":               systemIds,
+		"earliestOccurrence": at.Format(time.RFC3339),
+		"test":               test,
+	}
+	res, err := api.Post[int](client, "system/scheduleApplyHighstate", data)
+	if err != nil {
+		return 0, utils.Errorf(err, L("failed to schedule the highstate: %s"))
+	}
+	if !res.Success {
+		return 0, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// SelectorHelp describes the syntax of the systems selector for the flags help.
+const SelectorHelp = "group:NAME, search:REGEXP or a comma-separated list of system names or IDs"
+
+// Select returns the systems matching a selector.
+//
+// The selector can be group:NAME for the systems of a group, search:REGEXP to search the systems by name
+// or a comma-separated list of system names or IDs.
+func Select(client *api.HTTPClient, selector string) ([]types.SystemInfo, error) {
+	if group, found := strings.CutPrefix(selector, "group:"); found {
+		return ListGroupSystems(client, group)
+	}
+	if regexp, found := strings.CutPrefix(selector, "search:"); found {
+		return SearchByName(client, regexp)
+	}
+	systems, err := List(client)
+	if err != nil {
+		return nil, err
+	}
+	return matchSystems(systems, strings.Split(selector, ","))
+}
+
+// matchSystems returns the systems with the given names or IDs, failing if one is not found.
+func matchSystems(systems []types.SystemInfo, names []string) ([]types.SystemInfo, error) {
+	selected := []types.SystemInfo{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, system := range systems {
+			if system.Name == name || strconv.Itoa(system.Id) == name {
+				selected = append(selected, system)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf(L("no system found with name or ID %s"), name)
+		}
+	}
+	return selected, nil
+}
+
+// Ids returns the IDs of the systems.
+func Ids(systems []types.SystemInfo) []int {
+	ids := []int{}
+	for _, system := range systems {
+		ids = append(ids, system.Id)
+	}
+	return ids
+}
@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+)
+
+func TestMatchSystems(t *testing.T) {
+	systems := []types.SystemInfo{{Id: 1000010000, Name: "web1"}, {Id: 1000010001, Name: "db1"}}
+
+	selected, err := matchSystems(systems, []string{"db1", " 1000010000", ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(selected) != 2 || selected[0].Name != "db1" || selected[1].Name != "web1" {
+		t.Errorf("unexpected selected systems: %v", selected)
+	}
+
+	if _, err := matchSystems(systems, []string{"web2"}); err == nil {
+		t.Error("expected an error for an unknown system")
+	}
+}
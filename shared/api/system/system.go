@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// List returns all the systems visible to the user.
+func List(client *api.HTTPClient) ([]types.SystemInfo, error) {
+	res, err := api.Get[[]types.SystemInfo](client, "system/listSystems")
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the systems: %s"))
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// SearchByName returns the systems with a name matching the regular expression.
+func SearchByName(client *api.HTTPClient, regexp string) ([]types.SystemInfo, error) {
+	res, err := api.Get[[]types.SystemInfo](client, "system/searchByName?regexp="+url.QueryEscape(regexp))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to search the systems: %s"))
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// ListGroupSystems returns the systems of a system group.
+func ListGroupSystems(client *api.HTTPClient, group string) ([]types.SystemInfo, error) {
+	res, err := api.Get[[]types.SystemInfo](client, "systemgroup/listSystemsMinimal?systemGroupName="+url.QueryEscape(group))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the systems of group %[1]s: %[2]s"), group)
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package systemgroup
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// Create creates a system group.
+func Create(client *api.HTTPClient, name string, description string) (*types.SystemGroup, error) {
+	data := map[string]interface{}{
+		"name":        name,
+		"description": description,
+	}
+	res, err := api.Post[types.SystemGroup](client, "systemgroup/create", data)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to create the %[1]s system group: %[2]s"), name)
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return &res.Result, nil
+}
+
+// GetDetails returns a system group from its name.
+func GetDetails(client *api.HTTPClient, name string) (*types.SystemGroup, error) {
+	res, err := api.Get[types.SystemGroup](client, "systemgroup/getDetails?systemGroupName="+url.QueryEscape(name))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the %[1]s system group: %[2]s"), name)
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return &res.Result, nil
+}
+
+// AddSystems adds systems to a system group.
+func AddSystems(client *api.HTTPClient, name string, systemIds []int) error {
+	data := map[string]interface{}{
+		"systemGroupName": name,
+		"serverIds":       systemIds,
+		"add":             true,
+	}
+	res, err := api.Post[int](client, "systemgroup/addOrRemoveSystems", data)
+	if err != nil {
+		return utils.Errorf(err, L("failed to add the systems to the %[1]s system group: %[2]s"), name)
+	}
+	if !res.Success {
+		return errors.New(res.Message)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package taskomatic
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ListBunches returns the bunches Taskomatic can run.
+func ListBunches(client *api.HTTPClient) ([]types.TaskoBunch, error) {
+	res, err := api.Get[[]types.TaskoBunch](client, "taskomatic/listSatBunches")
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the task bunches: %s"))
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// ListSchedules returns the active Taskomatic schedules.
+func ListSchedules(client *api.HTTPClient) ([]types.TaskoSchedule, error) {
+	res, err := api.Get[[]types.TaskoSchedule](client, "taskomatic/listActiveSatSchedules")
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the task schedules: %s"))
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// ListScheduleRuns returns the runs of a Taskomatic schedule.
+func ListScheduleRuns(client *api.HTTPClient, scheduleId int) ([]types.TaskoRun, error) {
+	res, err := api.Get[[]types.TaskoRun](client, fmt.Sprintf("taskomatic/listSatScheduleRuns?scheduleId=%d", scheduleId))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the runs of schedule %[1]d: %[2]s"), scheduleId)
+	}
+	if !res.Success {
+		return nil, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// RunBunch schedules a single immediate run of a bunch with optional parameters.
+func RunBunch(client *api.HTTPClient, bunch string, params map[string]string) error {
+	data := map[string]interface{}{
+		"bunchName": bunch,
+		"params":    params,
+	}
+	res, err := api.Post[interface{}](client, "taskomatic/scheduleSingleSatBunchRun", data)
+	if err != nil {
+		return utils.Errorf(err, L("failed to run the %[1]s bunch: %[2]s"), bunch)
+	}
+	if !res.Success {
+		return errors.New(res.Message)
+	}
+	return nil
+}
+
+// Unschedule cancels a Taskomatic schedule using its job label.
+func Unschedule(client *api.HTTPClient, jobLabel string) error {
+	data := map[string]interface{}{
+		"jobLabel": jobLabel,
+	}
+	res, err := api.Post[interface{}](client, "taskomatic/unscheduleSatBunch", data)
+	if err != nil {
+		return utils.Errorf(err, L("failed to cancel the %[1]s schedule: %[2]s"), jobLabel)
+	}
+	if !res.Success {
+		return errors.New(res.Message)
+	}
+	return nil
+}
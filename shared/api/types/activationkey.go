@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// ActivationKey describes an activation key in the API.
+type ActivationKey struct {
+	Key              string   `json:"key"`
+	Description      string   `json:"description"`
+	BaseChannelLabel string   `json:"base_channel_label"`
+	UsageLimit       int      `json:"usage_limit"`
+	Entitlements     []string `json:"entitlements"`
+	UniversalDefault bool     `json:"universal_default"`
+	Disabled         bool     `json:"disabled"`
+	ServerGroupIds   []int    `json:"server_group_ids"`
+}
+
+// SystemGroup describes a system group in the API.
+type SystemGroup struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	SystemCount int    `json:"system_count"`
+}
@@ -0,0 +1,19 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// ConfigFile describes a file, directory or symbolic link of a configuration channel.
+type ConfigFile struct {
+	Type          string `json:"type"`
+	Path          string `json:"path"`
+	TargetPath    string `json:"target_path,omitempty"`
+	Contents      string `json:"contents,omitempty"`
+	ContentsEnc64 bool   `json:"contents_enc64"`
+	Owner         string `json:"owner,omitempty"`
+	Group         string `json:"group,omitempty"`
+	Permissions   string `json:"permissions_mode,omitempty"`
+	Revision      int    `json:"revision,omitempty"`
+	Binary        bool   `json:"binary"`
+}
@@ -0,0 +1,26 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// ContentProject describes a content lifecycle management project.
+type ContentProject struct {
+	Id            int    `json:"id"`
+	Label         string `json:"label"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	LastBuildDate string `json:"lastBuildDate"`
+}
+
+// ContentEnvironment describes an environment of a content lifecycle management project.
+type ContentEnvironment struct {
+	Id                       int    `json:"id"`
+	Label                    string `json:"label"`
+	Name                     string `json:"name"`
+	Status                   string `json:"status"`
+	Version                  int    `json:"version"`
+	LastBuildDate            string `json:"lastBuildDate"`
+	PreviousEnvironmentLabel string `json:"previousEnvironmentLabel"`
+	NextEnvironmentLabel     string `json:"nextEnvironmentLabel"`
+}
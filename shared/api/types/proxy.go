@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// ProxyConfigRequest describes the proxy container configuration to generate.
+//
+// Either the third party certificates (RootCA, ProxyCrt and ProxyKey) or the server CA
+// (CaCrt, CaKey and CaPassword) to generate the proxy certificate with have to be set.
+type ProxyConfigRequest struct {
+	ProxyName       string
+	ProxyPort       int
+	Server          string
+	MaxCache        int
+	Email           string
+	RootCA          string
+	IntermediateCAs []string
+	ProxyCrt        string
+	ProxyKey        string
+	CaCrt           string
+	CaKey           string
+	CaPassword      string
+}
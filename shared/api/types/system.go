@@ -0,0 +1,20 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// SystemInfo describes a registered system in the API listings.
+type SystemInfo struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	LastCheckin string `json:"last_checkin"`
+	LastBoot    string `json:"last_boot"`
+}
+
+// ErrataInfo describes a patch relevant for a system.
+type ErrataInfo struct {
+	Id           int    `json:"id"`
+	AdvisoryName string `json:"advisory_name"`
+	AdvisoryType string `json:"advisory_type"`
+}
@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// TaskoBunch describes a group of tasks that Taskomatic can run.
+type TaskoBunch struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// TaskoSchedule describes an active Taskomatic schedule of a bunch.
+type TaskoSchedule struct {
+	Id         int    `json:"id"`
+	JobLabel   string `json:"job_label"`
+	Bunch      string `json:"bunch"`
+	ActiveFrom string `json:"active_from"`
+	CronExpr   string `json:"cron_expr"`
+}
+
+// TaskoRun describes a run of a Taskomatic schedule.
+type TaskoRun struct {
+	Id        int    `json:"id"`
+	Status    string `json:"status"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// App identifies the application managed by a backend.
+type App int
+
+const (
+	// ServerApp is the Uyuni server.
+	ServerApp App = iota
+	// ProxyApp is the Uyuni proxy.
+	ProxyApp
+	// SalineApp is the saline salt event processor running next to the server.
+	SalineApp
+)
+
+const (
+	// PodmanBackend is the name of the podman backend.
+	PodmanBackend = "podman"
+	// KubernetesBackend is the name of the kubernetes backend.
+	KubernetesBackend = "kubernetes"
+)
+
+// Backend manages the containers of an application on a container runtime.
+//
+// The backends register themselves at init time using RegisterBackend.
+// Installing and upgrading are not part of the interface: they are backend specific subcommands
+// since their flags differ between the backends.
+type Backend interface {
+	// Name returns the name of the backend.
+	Name() string
+	// Commands returns the values of the backend flag selecting the backend.
+	Commands() []string
+	// Start starts the application.
+	// The namespace is only used by the kubernetes backend and is detected if empty.
+	Start(app App, namespace string) error
+	// Stop stops the application.
+	Stop(app App, namespace string) error
+	// Restart restarts the application.
+	Restart(app App, namespace string) error
+	// StartComponent starts a single component of the application.
+	StartComponent(app App, component string, namespace string) error
+	// StopComponent stops a single component of the application.
+	StopComponent(app App, component string, namespace string) error
+	// RestartComponent restarts a single component of the application.
+	RestartComponent(app App, component string, namespace string) error
+	// ServerImage returns the image of the running server container.
+	ServerImage(namespace string) (string, error)
+	// ReadState returns the recorded state of the server deployment, nil if there is none.
+	ReadState(namespace string) (*types.DeploymentState, error)
+	// Inspect returns the values inspected from a server image and the deployment.
+	// The cached result is used unless refresh is true or the deployment changed.
+	Inspect(image string, pullPolicy string, namespace string, refresh bool) (types.InspectResult, error)
+}
+
+var backends = map[string]Backend{}
+
+// RegisterBackend makes a backend available to the commands.
+func RegisterBackend(backend Backend) {
+	backends[backend.Name()] = backend
+}
+
+// GetBackends returns the names of the registered backends.
+func GetBackends() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FindBackend returns the backend selected by the backend flag or detected from the running containers.
+func FindBackend(flags *pflag.FlagSet, app App) (Backend, error) {
+	command, err := newAppConnection(flags, app).GetCommand()
+	if err != nil {
+		return nil, errors.New(L("failed to determine suitable backend"))
+	}
+	return getBackendForCommand(command)
+}
+
+// ChooseBackendFunc selects the function implementing a command for the backend found with FindBackend.
+//
+// fns maps the backend names to the functions: the backends without function are not supported by the command.
+// The functions of the backends built with a tag, like docker, can be added to the map in a file with this tag.
+func ChooseBackendFunc[F interface{}](
+	flags *pflag.FlagSet,
+	app App,
+	fns map[string]utils.CommandFunc[F],
+) (utils.CommandFunc[F], error) {
+	backend, err := FindBackend(flags, app)
+	if err != nil {
+		return nil, err
+	}
+	if fn, found := fns[backend.Name()]; found {
+		return fn, nil
+	}
+	return nil, fmt.Errorf(L("%s backend is not supported by this command"), backend.Name())
+}
+
+// ProxyComponents returns the proxy components which can be started, stopped and restarted individually.
+func ProxyComponents() []string {
+	components := make([]string, 0, len(podman.ProxyContainerNames))
+	for _, name := range podman.ProxyContainerNames {
+		components = append(components, strings.TrimPrefix(name, proxyContainerPrefix))
+	}
+	return components
+}
+
+const proxyContainerPrefix = "uyuni-proxy-"
+
+// checkComponent fails if the component cannot be handled individually.
+func checkComponent(app App, component string) error {
+	if app != ProxyApp {
+		return errors.New(L("only the proxy components can be handled individually"))
+	}
+	if !utils.Contains(ProxyComponents(), component) {
+		return fmt.Errorf(L("unknown proxy component %[1]s, possible values are: %[2]s"),
+			component, strings.Join(ProxyComponents(), ", "))
+	}
+	return nil
+}
+
+func getBackendForCommand(command string) (Backend, error) {
+	for _, backend := range backends {
+		for _, backendCommand := range backend.Commands() {
+			if backendCommand == command {
+				return backend, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf(L("no supported backend found for %s"), command)
+}
+
+// newAppConnection creates the connection to the application using the backend flag.
+func newAppConnection(flags *pflag.FlagSet, app App) *Connection {
+	backend := "podman"
+	if app == ProxyApp || utils.KubernetesBuilt {
+		backend, _ = flags.GetString("backend")
+	}
+	namespace, _ := flags.GetString("namespace")
+
+	if app == ProxyApp {
+		return NewConnection(backend, podman.ProxyContainerNames[0], kubernetes.ProxyFilter, namespace)
+	}
+	return NewConnection(backend, podman.ServerContainerName, kubernetes.ServerFilter, namespace)
+}
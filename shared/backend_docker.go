@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build docker
+
+package shared
+
+import (
+	"fmt"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// DockerBackend is the name of the experimental docker backend.
+const DockerBackend = "docker"
+
+// dockerBackend runs the containers with docker. The containers are managed by systemd services
+// with the same names as the podman ones.
+type dockerBackend struct{}
+
+func init() {
+	RegisterBackend(dockerBackend{})
+}
+
+// Name returns the name of the backend.
+func (b dockerBackend) Name() string {
+	return DockerBackend
+}
+
+// Commands returns the values of the backend flag selecting the backend.
+func (b dockerBackend) Commands() []string {
+	return []string{"docker"}
+}
+
+// Start starts the application.
+func (b dockerBackend) Start(app App, namespace string) error {
+	return podman.StartService(b.service(app))
+}
+
+// Stop stops the application.
+func (b dockerBackend) Stop(app App, namespace string) error {
+	return podman.StopService(b.service(app))
+}
+
+// Restart restarts the application.
+func (b dockerBackend) Restart(app App, namespace string) error {
+	return podman.RestartService(b.service(app))
+}
+
+// StartComponent starts the systemd service of a single component.
+func (b dockerBackend) StartComponent(app App, component string, namespace string) error {
+	if err := checkComponent(app, component); err != nil {
+		return err
+	}
+	return podman.StartService(proxyContainerPrefix + component)
+}
+
+// StopComponent stops the systemd service of a single component.
+func (b dockerBackend) StopComponent(app App, component string, namespace string) error {
+	if err := checkComponent(app, component); err != nil {
+		return err
+	}
+	return podman.StopService(proxyContainerPrefix + component)
+}
+
+// RestartComponent restarts the systemd service of a single component.
+func (b dockerBackend) RestartComponent(app App, component string, namespace string) error {
+	if err := checkComponent(app, component); err != nil {
+		return err
+	}
+	return podman.RestartService(proxyContainerPrefix + component)
+}
+
+// service returns the systemd service of the application. There is no attestation service with docker.
+func (b dockerBackend) service(app App) string {
+	if app == ProxyApp {
+		return podman.ProxyService
+	}
+	if app == SalineApp {
+		return podman.ServerSalineService
+	}
+	return podman.ServerService
+}
+
+// ServerImage is not supported by the docker backend yet.
+func (b dockerBackend) ServerImage(namespace string) (string, error) {
+	return "", fmt.Errorf(L("%s backend is not supported by this command"), DockerBackend)
+}
+
+// ReadState returns the recorded state of the server deployment, stored in the same file as with podman.
+func (b dockerBackend) ReadState(namespace string) (*types.DeploymentState, error) {
+	return utils.ReadStateFile(utils.StateFilePath)
+}
+
+// Inspect is not supported by the docker backend yet.
+func (b dockerBackend) Inspect(image string, pullPolicy string, namespace string, refresh bool) (types.InspectResult, error) {
+	return types.InspectResult{}, fmt.Errorf(L("%s backend is not supported by this command"), DockerBackend)
+}
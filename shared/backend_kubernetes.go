@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !nok8s
+
+package shared
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type kubernetesBackend struct{}
+
+func init() {
+	RegisterBackend(kubernetesBackend{})
+}
+
+// Name returns the name of the backend.
+func (b kubernetesBackend) Name() string {
+	return KubernetesBackend
+}
+
+// Commands returns the values of the backend flag selecting the backend.
+func (b kubernetesBackend) Commands() []string {
+	return []string{"kubectl"}
+}
+
+func (b kubernetesBackend) filter(app App) string {
+	if app == ProxyApp {
+		return kubernetes.ProxyFilter
+	}
+	if app == SalineApp {
+		return kubernetes.SalineFilter
+	}
+	return kubernetes.ServerFilter
+}
+
+// Start starts the application.
+func (b kubernetesBackend) Start(app App, namespace string) error {
+	return kubernetes.Start(namespace, b.filter(app))
+}
+
+// Stop stops the application.
+func (b kubernetesBackend) Stop(app App, namespace string) error {
+	return kubernetes.Stop(namespace, b.filter(app))
+}
+
+// Restart restarts the application.
+func (b kubernetesBackend) Restart(app App, namespace string) error {
+	return kubernetes.Restart(namespace, b.filter(app))
+}
+
+// componentFilter returns the filter of the deployment of a single component.
+//
+// The components need to be deployed separately: they cannot be handled individually if they share the same pod.
+func (b kubernetesBackend) componentFilter(app App, component string) (string, error) {
+	if err := checkComponent(app, component); err != nil {
+		return "", err
+	}
+	return kubernetes.ProxyComponentFilter(component), nil
+}
+
+// StartComponent starts the deployment of a single component.
+func (b kubernetesBackend) StartComponent(app App, component string, namespace string) error {
+	filter, err := b.componentFilter(app, component)
+	if err != nil {
+		return err
+	}
+	return kubernetes.Start(namespace, filter)
+}
+
+// StopComponent stops the deployment of a single component.
+func (b kubernetesBackend) StopComponent(app App, component string, namespace string) error {
+	filter, err := b.componentFilter(app, component)
+	if err != nil {
+		return err
+	}
+	return kubernetes.Stop(namespace, filter)
+}
+
+// RestartComponent restarts the deployment of a single component.
+func (b kubernetesBackend) RestartComponent(app App, component string, namespace string) error {
+	filter, err := b.componentFilter(app, component)
+	if err != nil {
+		return err
+	}
+	return kubernetes.Restart(namespace, filter)
+}
+
+// ServerImage returns the image of the running server container.
+func (b kubernetesBackend) ServerImage(namespace string) (string, error) {
+	namespace, err := kubernetes.GetNamespace(namespace, kubernetes.ServerFilter)
+	if err != nil {
+		return "", err
+	}
+	return kubernetes.GetRunningImage(namespace, kubernetes.ServerFilter)
+}
+
+// ReadState returns the recorded state of the server deployment from its ConfigMap.
+func (b kubernetesBackend) ReadState(namespace string) (*types.DeploymentState, error) {
+	namespace, err := kubernetes.GetNamespace(namespace, kubernetes.ServerFilter)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.ReadState(namespace)
+}
+
+// Inspect returns the values inspected from a server image in a pod.
+//
+// There is no local digest of the image on kubernetes, the result is cached by namespace and image.
+func (b kubernetesBackend) Inspect(image string, pullPolicy string, namespace string, refresh bool) (types.InspectResult, error) {
+	namespace, err := kubernetes.GetNamespace(namespace, kubernetes.ServerFilter)
+	if err != nil {
+		return types.InspectResult{}, err
+	}
+	state, err := kubernetes.ReadState(namespace)
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to read the deployment state")
+	}
+	return utils.CachedInspect(
+		namespace+"/"+image, image, utils.DeploymentStamp(state), refresh,
+		func() (map[string]string, error) {
+			return kubernetes.InspectKubernetes(namespace, image, pullPolicy)
+		},
+	)
+}
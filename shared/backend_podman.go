@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type podmanBackend struct{}
+
+func init() {
+	RegisterBackend(podmanBackend{})
+}
+
+// Name returns the name of the backend.
+func (b podmanBackend) Name() string {
+	return PodmanBackend
+}
+
+// Commands returns the values of the backend flag selecting the backend.
+func (b podmanBackend) Commands() []string {
+	return []string{"podman", "podman-remote"}
+}
+
+// services returns the systemd services of the application in start order.
+func (b podmanBackend) services(app App) []string {
+	if app == ProxyApp {
+		return []string{podman.ProxyService}
+	}
+	if app == SalineApp {
+		return []string{podman.ServerSalineService}
+	}
+	services := []string{}
+	if podman.HasService(podman.ServerReportDbService) {
+		services = append(services, podman.ServerReportDbService)
+	}
+	if podman.HasService(podman.ServerBillingAdapterService) {
+		services = append(services, podman.ServerBillingAdapterService)
+	}
+	// The optional components, like saline or the attestation, depend on the server
+	services = append(services, podman.ServerService)
+	return append(services, podman.ComponentServices()...)
+}
+
+// Start starts the application.
+func (b podmanBackend) Start(app App, namespace string) error {
+	for _, service := range b.services(app) {
+		if err := podman.StartService(service); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop stops the application.
+func (b podmanBackend) Stop(app App, namespace string) error {
+	for _, service := range b.services(app) {
+		if err := podman.StopService(service); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restart restarts the application.
+func (b podmanBackend) Restart(app App, namespace string) error {
+	services := b.services(app)
+	// Restart the main service first
+	for i := len(services) - 1; i >= 0; i-- {
+		if err := podman.RestartService(services[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartComponent starts the systemd service of a single component.
+func (b podmanBackend) StartComponent(app App, component string, namespace string) error {
+	if err := checkComponent(app, component); err != nil {
+		return err
+	}
+	return podman.StartService(proxyContainerPrefix + component)
+}
+
+// StopComponent stops the systemd service of a single component.
+func (b podmanBackend) StopComponent(app App, component string, namespace string) error {
+	if err := checkComponent(app, component); err != nil {
+		return err
+	}
+	return podman.StopService(proxyContainerPrefix + component)
+}
+
+// RestartComponent restarts the systemd service of a single component.
+func (b podmanBackend) RestartComponent(app App, component string, namespace string) error {
+	if err := checkComponent(app, component); err != nil {
+		return err
+	}
+	return podman.RestartService(proxyContainerPrefix + component)
+}
+
+// ServerImage returns the image of the running server container.
+func (b podmanBackend) ServerImage(namespace string) (string, error) {
+	image, err := podman.GetRunningImage(podman.ServerContainerName)
+	return strings.Trim(image, "'"), err
+}
+
+// ReadState returns the recorded state of the server deployment.
+func (b podmanBackend) ReadState(namespace string) (*types.DeploymentState, error) {
+	return podman.ReadState()
+}
+
+// Inspect returns the values inspected from a server image, cached by image digest.
+func (b podmanBackend) Inspect(image string, pullPolicy string, namespace string, refresh bool) (types.InspectResult, error) {
+	state, err := podman.ReadState()
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to read the deployment state")
+	}
+	return utils.CachedInspect(
+		podman.GetImageDigest(image), image, utils.DeploymentStamp(state), refresh,
+		func() (map[string]string, error) {
+			return podman.Inspect(image, pullPolicy)
+		},
+	)
+}
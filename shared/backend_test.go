@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type fakeAppBackend struct{}
+
+func (b fakeAppBackend) Name() string                            { return "fake" }
+func (b fakeAppBackend) Commands() []string                      { return []string{"fake-cli"} }
+func (b fakeAppBackend) Start(app App, namespace string) error   { return nil }
+func (b fakeAppBackend) Stop(app App, namespace string) error    { return nil }
+func (b fakeAppBackend) Restart(app App, namespace string) error { return nil }
+func (b fakeAppBackend) StartComponent(app App, component string, namespace string) error {
+	return nil
+}
+func (b fakeAppBackend) StopComponent(app App, component string, namespace string) error {
+	return nil
+}
+func (b fakeAppBackend) RestartComponent(app App, component string, namespace string) error {
+	return nil
+}
+func (b fakeAppBackend) ServerImage(namespace string) (string, error) { return "", nil }
+func (b fakeAppBackend) ReadState(namespace string) (*types.DeploymentState, error) {
+	return nil, nil
+}
+func (b fakeAppBackend) Inspect(image string, pullPolicy string, namespace string, refresh bool) (types.InspectResult, error) {
+	return types.InspectResult{}, nil
+}
+
+func TestBackendRegistry(t *testing.T) {
+	RegisterBackend(fakeAppBackend{})
+	defer delete(backends, "fake")
+
+	data := map[string]string{
+		"podman":        PodmanBackend,
+		"podman-remote": PodmanBackend,
+		"fake-cli":      "fake",
+	}
+	for command, expected := range data {
+		backend, err := getBackendForCommand(command)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", command, err)
+		} else if backend.Name() != expected {
+			t.Errorf("%s: expected %s backend, got %s", command, expected, backend.Name())
+		}
+	}
+
+	if _, err := getBackendForCommand("nosuchbackend"); err == nil {
+		t.Error("expected an error for an unknown backend command")
+	}
+
+	found := false
+	for _, name := range GetBackends() {
+		found = found || name == "fake"
+	}
+	if !found {
+		t.Errorf("fake backend not listed in %v", GetBackends())
+	}
+}
+
+func TestCheckComponent(t *testing.T) {
+	if err := checkComponent(ProxyApp, "squid"); err != nil {
+		t.Errorf("unexpected error for squid: %s", err)
+	}
+	if err := checkComponent(ProxyApp, "pod"); err == nil {
+		t.Error("expected an error for an unknown component")
+	}
+	if err := checkComponent(ServerApp, "squid"); err == nil {
+		t.Error("expected an error for a server component")
+	}
+}
+
+func TestChooseBackendFunc(t *testing.T) {
+	RegisterBackend(fakeAppBackend{})
+	defer delete(backends, "fake")
+
+	// The backend command needs to be found in the PATH
+	binDir := t.TempDir()
+	if err := os.WriteFile(path.Join(binDir, "fake-cli"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+":"+os.Getenv("PATH"))
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("backend", "fake-cli", "")
+	cmd.Flags().String("namespace", "", "")
+
+	called := ""
+	fns := map[string]utils.CommandFunc[struct{}]{
+		PodmanBackend: func(*types.GlobalFlags, *struct{}, *cobra.Command, []string) error {
+			called = PodmanBackend
+			return nil
+		},
+	}
+	if _, err := ChooseBackendFunc(cmd.Flags(), ProxyApp, fns); err == nil {
+		t.Error("expected an error for a backend not supported by the command")
+	}
+
+	fns["fake"] = func(*types.GlobalFlags, *struct{}, *cobra.Command, []string) error {
+		called = "fake"
+		return nil
+	}
+	fn, err := ChooseBackendFunc(cmd.Flags(), ProxyApp, fns)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := fn(nil, nil, cmd, nil); err != nil || called != "fake" {
+		t.Errorf("expected the fake backend function to be called, got %s: %v", called, err)
+	}
+}
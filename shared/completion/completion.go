@@ -5,12 +5,12 @@
 package completion
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 // NewCommand  command for generates completion script.
@@ -27,15 +27,15 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 			switch args[0] {
 			case "bash":
 				if err := cmd.Root().GenBashCompletion(os.Stdout); err != nil {
-					return fmt.Errorf(L("cannot generate %s completion: %s"), args[0], err)
+					return utils.Errorf(err, L("cannot generate %s completion: %s"), args[0])
 				}
 			case "zsh":
 				if err := cmd.Root().GenZshCompletion(os.Stdout); err != nil {
-					return fmt.Errorf(L("cannot generate %s completion: %s"), args[0], err)
+					return utils.Errorf(err, L("cannot generate %s completion: %s"), args[0])
 				}
 			case "fish":
 				if err := cmd.Root().GenFishCompletion(os.Stdout, true); err != nil {
-					return fmt.Errorf(L("cannot generate %s completion: %s"), args[0], err)
+					return utils.Errorf(err, L("cannot generate %s completion: %s"), args[0])
 				}
 			}
 			return nil
@@ -8,13 +8,15 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
-	"github.com/spf13/pflag"
 	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/podman"
@@ -28,6 +30,7 @@ type Connection struct {
 	podName          string
 	podmanContainer  string
 	kubernetesFilter string
+	namespace        string
 }
 
 // Create a new connection object.
@@ -36,8 +39,14 @@ type Connection struct {
 // The empty strings means automatic detection of the backend where the uyuni container is running.
 // podmanContainer is the name of a podman container to look for when detecting the command.
 // kubernetesFilter is a filter parameter to use to match a pod.
-func NewConnection(backend string, podmanContainer string, kubernetesFilter string) *Connection {
-	cnx := Connection{backend: backend, podmanContainer: podmanContainer, kubernetesFilter: kubernetesFilter}
+// namespace is the kubernetes namespace of the pod. The empty string means looking for it in all namespaces.
+func NewConnection(backend string, podmanContainer string, kubernetesFilter string, namespace string) *Connection {
+	cnx := Connection{
+		backend:          backend,
+		podmanContainer:  podmanContainer,
+		kubernetesFilter: kubernetesFilter,
+		namespace:        namespace,
+	}
 
 	return &cnx
 }
@@ -64,7 +73,13 @@ func (c *Connection) GetCommand() (string, error) {
 			_, err = exec.LookPath("kubectl")
 			if err == nil {
 				hasKubectl = true
-				if out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "--request-timeout=30s", "get", "pod", c.kubernetesFilter, "-A", "-o=jsonpath={.items[*].metadata.name}"); err != nil {
+				args := []string{"--request-timeout=30s", "get", "pod", c.kubernetesFilter, "-o=jsonpath={.items[*].metadata.name}"}
+				if c.namespace != "" {
+					args = append(args, "-n", c.namespace)
+				} else {
+					args = append(args, "-A")
+				}
+				if out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", kubernetes.KubectlArgs(args...)...); err != nil {
 					log.Info().Msg(L("kubectl not configured to connect to a cluster, ignoring"))
 				} else if len(bytes.TrimSpace(out)) != 0 {
 					c.command = "kubectl"
@@ -101,7 +116,14 @@ func (c *Connection) GetCommand() (string, error) {
 				err = errors.New(L("uyuni container is not accessible with one of podman, podman-remote or kubectl"))
 			}
 		default:
-			err = fmt.Errorf(L("unsupported backend %s"), c.backend)
+			// Other backends are only available if registered, like the experimental docker one
+			if _, findErr := getBackendForCommand(c.backend); findErr != nil {
+				err = fmt.Errorf(L("unsupported backend %s"), c.backend)
+			} else if _, err = exec.LookPath(c.backend); err != nil {
+				err = fmt.Errorf(L("backend command not found in PATH: %s"), c.backend)
+			} else {
+				c.command = c.backend
+			}
 		}
 	}
 	return c.command, err
@@ -118,19 +140,30 @@ func (c *Connection) GetPodName() (string, error) {
 		}
 
 		switch command {
+		case "docker":
+			fallthrough
 		case "podman-remote":
 			fallthrough
 		case "podman":
 			if out, _ := utils.RunCmdOutput(zerolog.DebugLevel, c.command, "ps", "-q", "-f", "name="+c.podmanContainer); len(out) == 0 {
-				err = fmt.Errorf(L("container %s is not running on podman"), c.podmanContainer)
+				err = utils.WithCode(utils.CodeContainer,
+					fmt.Errorf(L("container %[1]s is not running on %[2]s"), c.podmanContainer, command))
 			} else {
 				c.podName = c.podmanContainer
 			}
 		case "kubectl":
+			namespace, nsErr := kubernetes.GetNamespace(c.namespace, c.kubernetesFilter)
+			if nsErr != nil {
+				return "", nsErr
+			}
+			c.namespace = namespace
+
 			// We try the first item on purpose to make the command fail if not available
-			podName, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "get", "pod", c.kubernetesFilter, "-A",
-				"-o=jsonpath={.items[0].metadata.name}")
-			if err == nil {
+			podName, podErr := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", kubernetes.KubectlArgs("get", "pod", c.kubernetesFilter,
+				"-n", namespace, "-o=jsonpath={.items[0].metadata.name}")...)
+			if podErr != nil {
+				err = podErr
+			} else {
 				c.podName = string(podName[:])
 			}
 		}
@@ -139,12 +172,41 @@ func (c *Connection) GetPodName() (string, error) {
 	return c.podName, err
 }
 
+// GetNamespace returns the kubernetes namespace of the pod, finding it if needed.
+// It returns an empty string for the other backends.
+func (c *Connection) GetNamespace() (string, error) {
+	if command, err := c.GetCommand(); err != nil || command != "kubectl" {
+		return "", err
+	}
+	if _, err := c.GetPodName(); err != nil {
+		return "", err
+	}
+	return c.namespace, nil
+}
+
+// clusterArgs adds the cluster options to the arguments of the kubectl commands.
+func clusterArgs(command string, args []string) []string {
+	if command == "kubectl" {
+		return kubernetes.KubectlArgs(args...)
+	}
+	return args
+}
+
+// kubectlArgs returns the kubectl exec arguments to select the namespace and the container of the server pod.
+func (c *Connection) kubectlArgs() []string {
+	args := []string{"-c", "uyuni"}
+	if c.namespace != "" {
+		args = append(args, "-n", c.namespace)
+	}
+	return append(args, "--")
+}
+
 // Exec runs command inside the container within an sh shell.
 func (c *Connection) Exec(command string, args ...string) ([]byte, error) {
 	if c.podName == "" {
 		if _, err := c.GetPodName(); c.podName == "" {
-			return nil, fmt.Errorf(L("the container is not running, %s %s command not executed: %s"),
-				command, strings.Join(args, " "), err)
+			return nil, utils.WithCode(utils.CodeContainer, utils.Errorf(err, L("the container is not running, %s %s command not executed: %s"),
+				command, strings.Join(args, " ")))
 		}
 	}
 
@@ -155,12 +217,142 @@ func (c *Connection) Exec(command string, args ...string) ([]byte, error) {
 
 	cmdArgs := []string{"exec", c.podName}
 	if cmd == "kubectl" {
-		cmdArgs = append(cmdArgs, "-c", "uyuni", "--")
+		cmdArgs = append(cmdArgs, c.kubectlArgs()...)
 	}
 	shellArgs := append([]string{command}, args...)
 	cmdArgs = append(cmdArgs, shellArgs...)
 
-	return utils.RunCmdOutput(zerolog.DebugLevel, cmd, cmdArgs...)
+	return utils.RunCmdOutput(zerolog.DebugLevel, cmd, clusterArgs(cmd, cmdArgs)...)
+}
+
+// ExecOptions defines how ExecInteractive connects the command to the terminal.
+type ExecOptions struct {
+	// Interactive passes the standard input to the command.
+	Interactive bool
+	// Tty allocates a pseudo terminal for the command.
+	Tty bool
+	// Envs are the environment variables to set, either NAME=value or NAME to pass the local value.
+	Envs []string
+	// User is the user or UID running the command instead of root.
+	User string
+	// Workdir is the directory to run the command in.
+	Workdir string
+	// Stdout receives the command output. The standard output is used if nil.
+	Stdout io.Writer
+}
+
+// ExecInteractive runs a command inside the container with the standard input and outputs wired
+// to the user terminal, like podman exec -ti or kubectl exec -ti do.
+//
+// The command output is not recorded as it may be an interactive session.
+func (c *Connection) ExecInteractive(options ExecOptions, command string, args ...string) error {
+	podName, err := c.GetPodName()
+	if err != nil {
+		return err
+	}
+
+	backend, err := c.GetCommand()
+	if err != nil {
+		return err
+	}
+
+	cmdArgs := []string{"exec"}
+	envs := []string{}
+	if options.Interactive {
+		cmdArgs = append(cmdArgs, "-i")
+		envs = append(envs, "ENV=/etc/sh.shrc.local")
+	}
+	if options.Tty {
+		cmdArgs = append(cmdArgs, "-t")
+		envs = append(envs, "TERM")
+	}
+	if backend != "kubectl" {
+		if options.User != "" {
+			cmdArgs = append(cmdArgs, "--user", options.User)
+		}
+		if options.Workdir != "" {
+			cmdArgs = append(cmdArgs, "--workdir", options.Workdir)
+		}
+	}
+	cmdArgs = append(cmdArgs, podName)
+
+	if backend == "kubectl" {
+		cmdArgs = append(cmdArgs, c.kubectlArgs()...)
+	}
+
+	newEnv := []string{}
+	for _, envValue := range append(envs, options.Envs...) {
+		if !strings.Contains(envValue, "=") {
+			if value, set := os.LookupEnv(envValue); set {
+				newEnv = append(newEnv, fmt.Sprintf("%s=%s", envValue, value))
+			}
+		} else {
+			newEnv = append(newEnv, envValue)
+		}
+	}
+	if len(newEnv) > 0 {
+		cmdArgs = append(cmdArgs, "env")
+		cmdArgs = append(cmdArgs, newEnv...)
+	}
+	cmdArgs = append(cmdArgs, wrapCommand(backend, options, command, args)...)
+
+	log.Info().Msgf(L("Running: %s %s"), backend, utils.Redact(strings.Join(cmdArgs, " ")))
+
+	cmdArgs = clusterArgs(backend, cmdArgs)
+	runCmd := exec.Command(backend, cmdArgs...)
+	runCmd.Stdin = os.Stdin
+	stdout := options.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	runCmd.Stdout = execWriter{stream: stdout}
+	runCmd.Stderr = execWriter{stream: os.Stderr}
+
+	err = runCmd.Run()
+	utils.LogCommand(backend, cmdArgs, nil, err, false)
+	return err
+}
+
+// wrapCommand returns the command to run in the container.
+//
+// kubectl exec has no user and working directory options: the command is wrapped
+// to switch to the user and directory inside the container.
+func wrapCommand(backend string, options ExecOptions, command string, args []string) []string {
+	wrapped := append([]string{command}, args...)
+	if backend != "kubectl" {
+		return wrapped
+	}
+	if options.User != "" {
+		wrapped = append([]string{"runuser", "-u", options.User, "--"}, wrapped...)
+	}
+	if options.Workdir != "" {
+		wrapped = append([]string{"sh", "-c", `cd "$0" && exec "$@"`, options.Workdir}, wrapped...)
+	}
+	return wrapped
+}
+
+// execWriter copies the command output to a stream and logs it.
+type execWriter struct {
+	stream io.Writer
+}
+
+// Write writes an array of buffer in a stream.
+func (w execWriter) Write(p []byte) (n int, err error) {
+	// Filter out kubectl line about terminated exit code
+	if strings.HasPrefix(string(p), "command terminated with exit code") {
+		return len(p), nil
+	}
+	if _, err := w.stream.Write(p); err != nil {
+		return 0, utils.Errorf(err, L("cannot write: %s"))
+	}
+
+	n = len(p)
+	if n > 0 && p[n-1] == '\n' {
+		// Trim CR added by stdlog.
+		p = p[0 : n-1]
+	}
+	log.Debug().Msg(string(p))
+	return
 }
 
 // WaitForServer waits at most 60s for multi-user systemd target to be reached.
@@ -179,10 +371,10 @@ func (c *Connection) WaitForServer() error {
 		}
 
 		if command == "kubectl" {
-			args = append(args, "--")
+			args = append(args, c.kubectlArgs()...)
 		}
 		args = append(args, "systemctl", "is-active", "-q", "multi-user.target")
-		output := utils.RunCmd(command, args...)
+		output := utils.RunCmd(command, clusterArgs(command, args)...)
 		isActive := output == nil
 
 		if isActive {
@@ -193,51 +385,14 @@ func (c *Connection) WaitForServer() error {
 	return errors.New(L("server didn't start within 60s. Check for the service status"))
 }
 
-// Copy transfers a file to or from the container.
+// Copy transfers a file or directory to or from the container.
 // Prefix one of src or dst parameters with `server:` to designate the path is in the container
 // user and group parameters are used to set the owner of a file transferred in the container.
+//
+// The progress of big copies is logged.
 func (c *Connection) Copy(src string, dst string, user string, group string) error {
-	podName, err := c.GetPodName()
-	if err != nil {
-		return err
-	}
-	var commandArgs []string
-	extraArgs := []string{}
-	srcExpanded := strings.Replace(src, "server:", podName+":", 1)
-	dstExpanded := strings.Replace(dst, "server:", podName+":", 1)
-
-	command, err := c.GetCommand()
-	if err != nil {
-		return err
-	}
-
-	switch command {
-	case "podman-remote":
-		fallthrough
-	case "podman":
-		commandArgs = []string{"cp", srcExpanded, dstExpanded}
-	case "kubectl":
-		commandArgs = []string{"cp", "-c", "uyuni", srcExpanded, dstExpanded}
-		extraArgs = []string{"-c", "uyuni", "--"}
-	default:
-		return fmt.Errorf(L("unknown container kind: %s"), command)
-	}
-
-	if err := utils.RunCmdStdMapping(zerolog.DebugLevel, command, commandArgs...); err != nil {
-		return err
-	}
-
-	if user != "" && strings.HasPrefix(dst, "server:") {
-		execArgs := []string{"exec", podName}
-		execArgs = append(execArgs, extraArgs...)
-		owner := user
-		if group != "" {
-			owner = user + ":" + group
-		}
-		execArgs = append(execArgs, "chown", owner, strings.Replace(dst, "server:", "", 1))
-		return utils.RunCmdStdMapping(zerolog.DebugLevel, command, execArgs...)
-	}
-	return nil
+	name := path.Base(strings.TrimPrefix(src, serverPrefix))
+	return c.CopyWithOptions(src, dst, CopyOptions{User: user, Group: group, Progress: LogCopyProgress(name)})
 }
 
 // TestExistenceInPod returns true if dstpath exists in the pod.
@@ -254,64 +409,17 @@ func (c *Connection) TestExistenceInPod(dstpath string) bool {
 	}
 
 	switch command {
-	case "podman":
+	case "podman", "podman-remote", "docker":
 		commandArgs = append(commandArgs, "test", "-e", dstpath)
 	case "kubectl":
-		commandArgs = append(commandArgs, "-c", "uyuni", "test", "-e", dstpath)
+		commandArgs = append(commandArgs, c.kubectlArgs()...)
+		commandArgs = append(commandArgs, "test", "-e", dstpath)
 	default:
 		log.Fatal().Msgf(L("unknown container kind: %s"), command)
 	}
 
-	if _, err := utils.RunCmdOutput(zerolog.DebugLevel, command, commandArgs...); err != nil {
+	if _, err := utils.RunCmdOutput(zerolog.DebugLevel, command, clusterArgs(command, commandArgs)...); err != nil {
 		return false
 	}
 	return true
 }
-
-// ChoosePodmanOrKubernetes selects either the podman or the kubernetes function based on the backend.
-// This function automatically detects the backend if compiled with kubernetes support and the backend flag is not passed.
-func ChoosePodmanOrKubernetes[F interface{}](
-	flags *pflag.FlagSet,
-	podmanFn utils.CommandFunc[F],
-	kubernetesFn utils.CommandFunc[F],
-) (utils.CommandFunc[F], error) {
-	backend := "podman"
-	if utils.KubernetesBuilt {
-		backend, _ = flags.GetString("backend")
-	}
-
-	cnx := NewConnection(backend, podman.ServerContainerName, kubernetes.ServerFilter)
-	return chooseBackend(cnx, podmanFn, kubernetesFn)
-}
-
-// ChooseProxyPodmanOrKubernetes selects either the podman or the kubernetes function based on the backend for the proxy.
-func ChooseProxyPodmanOrKubernetes[F interface{}](
-	flags *pflag.FlagSet,
-	podmanFn utils.CommandFunc[F],
-	kubernetesFn utils.CommandFunc[F],
-) (utils.CommandFunc[F], error) {
-	backend, _ := flags.GetString("backend")
-
-	cnx := NewConnection(backend, podman.ProxyContainerNames[0], kubernetes.ProxyFilter)
-	return chooseBackend(cnx, podmanFn, kubernetesFn)
-}
-
-func chooseBackend[F interface{}](
-	cnx *Connection,
-	podmanFn utils.CommandFunc[F],
-	kubernetesFn utils.CommandFunc[F],
-) (utils.CommandFunc[F], error) {
-	command, err := cnx.GetCommand()
-	if err != nil {
-		return nil, errors.New(L("failed to determine suitable backend"))
-	}
-	switch command {
-	case "podman":
-		return podmanFn, nil
-	case "kubectl":
-		return kubernetesFn, nil
-	}
-
-	// Should never happen if the commands are the same than those handled in GetCommand()
-	return nil, errors.New(L("no supported backend found"))
-}
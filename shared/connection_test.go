@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWrapCommand(t *testing.T) {
+	options := ExecOptions{User: "tomcat", Workdir: "/srv"}
+	data := map[string][]string{
+		"podman": {"ls", "-l"},
+		"kubectl": {"sh", "-c", `cd "$0" && exec "$@"`, "/srv",
+			"runuser", "-u", "tomcat", "--", "ls", "-l"},
+	}
+	for backend, expected := range data {
+		actual := wrapCommand(backend, options, "ls", []string{"-l"})
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected %v, got %v", backend, expected, actual)
+		}
+	}
+
+	actual := wrapCommand("kubectl", ExecOptions{}, "ls", []string{})
+	if !reflect.DeepEqual(actual, []string{"ls"}) {
+		t.Errorf("the command should not be wrapped without user and workdir: %v", actual)
+	}
+}
+
+func TestWrapCommandWorkdir(t *testing.T) {
+	dir := t.TempDir()
+	wrapped := wrapCommand("kubectl", ExecOptions{Workdir: dir}, "pwd", []string{})
+	out, err := exec.Command(wrapped[0], wrapped[1:]...).Output()
+	if err != nil {
+		t.Fatalf("failed to run the wrapped command: %s", err)
+	}
+	if strings.TrimSpace(string(out)) != dir {
+		t.Errorf("expected the command to run in %s, got %s", dir, out)
+	}
+}
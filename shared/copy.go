@@ -0,0 +1,525 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// serverPrefix designates a path inside the server container.
+const serverPrefix = "server:"
+
+// progressMinSize is the size under which the copy progress is not logged.
+const progressMinSize = 10 * 1024 * 1024
+
+// CopyProgress is called during a copy with the number of copied and total bytes.
+type CopyProgress func(copied int64, total int64)
+
+// CopyOptions defines how to copy files to or from the container.
+type CopyOptions struct {
+	// User is the owner to set on the files copied to the container. The owner is root if empty.
+	User string
+	// Group is the group to set on the files copied to the container. Ignored if User is empty.
+	Group string
+	// Archive preserves the owners and modification times of the copied files.
+	// The owners are only preserved in the local files if running as root.
+	Archive bool
+	// Progress is called during the copy, if not nil.
+	Progress CopyProgress
+}
+
+// runCmdStreams runs a command with its standard input and output connected to streams.
+// The standard error is added to the error message.
+var runCmdStreams = func(stdin io.Reader, stdout io.Writer, command string, args ...string) error {
+	log.Debug().Msgf("Running: %s %s", command, utils.Redact(strings.Join(args, " ")))
+
+	var stderr bytes.Buffer
+	runCmd := exec.Command(command, args...)
+	runCmd.Stdin = stdin
+	runCmd.Stdout = stdout
+	runCmd.Stderr = &stderr
+	err := runCmd.Run()
+	utils.LogCommand(command, args, stderr.Bytes(), err, false)
+	if err != nil && stderr.Len() > 0 {
+		err = utils.Errorf(err, L("command failed with %[2]s: %[1]s"), strings.TrimSpace(stderr.String()))
+	}
+	return err
+}
+
+// LogCopyProgress returns a progress callback logging every 10% of the copy of big files.
+func LogCopyProgress(name string) CopyProgress {
+	lastStep := int64(-1)
+	return func(copied int64, total int64) {
+		if total < progressMinSize {
+			return
+		}
+		step := copied * 10 / total
+		if step > 10 {
+			step = 10
+		}
+		if step != lastStep {
+			lastStep = step
+			log.Info().Msgf(L("Copying %[1]s: %[2]d%%"), name, step*10)
+		}
+	}
+}
+
+// CopyWithOptions streams files or directories to or from the container.
+//
+// Prefix one of src or dst parameters with `server:` to designate the path is in the container.
+// The files are transferred as a tar stream through the exec command of the backend to behave the same
+// on podman and kubernetes: the permissions are preserved and the files copied to the container are
+// owned by root unless a user is given or the archive option is set.
+//
+// The source can be a glob pattern, expanded on the side of the source.
+// If it matches several files, the destination has to be an existing directory.
+func (c *Connection) CopyWithOptions(src string, dst string, options CopyOptions) error {
+	toServer := strings.HasPrefix(dst, serverPrefix)
+	fromServer := strings.HasPrefix(src, serverPrefix)
+	if toServer == fromServer {
+		return errors.New(L("one and only one of the source or destination needs to be prefixed with server:"))
+	}
+
+	progress := options.Progress
+	if progress == nil {
+		progress = func(int64, int64) {}
+	}
+
+	if toServer {
+		dst = strings.TrimPrefix(dst, serverPrefix)
+		sources, err := expandLocal(src)
+		if err != nil {
+			return err
+		}
+		if len(sources) > 1 && c.execStreams(nil, io.Discard, "test", "-d", dst) != nil {
+			return fmt.Errorf(L("%[1]s matches several files: %[2]s has to be an existing directory"), src, dst)
+		}
+		for _, source := range sources {
+			if err := c.copyToServer(source, dst, options, progress); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	src = strings.TrimPrefix(src, serverPrefix)
+	sources, err := c.expandServer(src)
+	if err != nil {
+		return err
+	}
+	if len(sources) > 1 {
+		if info, err := os.Stat(dst); err != nil || !info.IsDir() {
+			return fmt.Errorf(L("%[1]s matches several files: %[2]s has to be an existing directory"), src, dst)
+		}
+	}
+	for _, source := range sources {
+		if err := c.copyFromServer(source, dst, options.Archive, progress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasGlob returns whether a path contains glob pattern characters.
+func hasGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// expandLocal returns the local paths matching a glob pattern.
+func expandLocal(pattern string) ([]string, error) {
+	if !hasGlob(pattern) {
+		return []string{pattern}, nil
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, utils.Errorf(err, L("invalid pattern %[1]s: %[2]s"), pattern)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf(L("no file matching %s"), pattern)
+	}
+	return matches, nil
+}
+
+// expandServerScript prints the paths matching the glob pattern passed as first parameter.
+// The empty IFS prevents the word splitting of the pattern, but not the pathname expansion.
+const expandServerScript = `IFS=; for f in $1; do if [ -e "$f" ] || [ -L "$f" ]; then printf '%s\n' "$f"; fi; done`
+
+// expandServer returns the paths in the container matching a glob pattern.
+func (c *Connection) expandServer(pattern string) ([]string, error) {
+	if !hasGlob(pattern) {
+		return []string{pattern}, nil
+	}
+	var out bytes.Buffer
+	if err := c.execStreams(nil, &out, "sh", "-c", expandServerScript, "sh", pattern); err != nil {
+		return nil, utils.Errorf(err, L("failed to expand %[1]s in the container: %[2]s"), pattern)
+	}
+	matches := []string{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if line != "" {
+			matches = append(matches, line)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf(L("no file matching %s in the container"), pattern)
+	}
+	return matches, nil
+}
+
+// execArgs computes the backend command and arguments to run a command in the container.
+func (c *Connection) execArgs(stdin bool, command ...string) (string, []string, error) {
+	podName, err := c.GetPodName()
+	if err != nil {
+		return "", nil, err
+	}
+	backend, err := c.GetCommand()
+	if err != nil {
+		return "", nil, err
+	}
+
+	args := []string{"exec"}
+	if stdin {
+		args = append(args, "-i")
+	}
+	args = append(args, podName)
+
+	switch backend {
+	case "podman", "podman-remote", "docker":
+	case "kubectl":
+		args = append(args, c.kubectlArgs()...)
+	default:
+		return "", nil, fmt.Errorf(L("unknown container kind: %s"), backend)
+	}
+	return backend, clusterArgs(backend, append(args, command...)), nil
+}
+
+func (c *Connection) execStreams(stdin io.Reader, stdout io.Writer, command ...string) error {
+	backend, args, err := c.execArgs(stdin != nil, command...)
+	if err != nil {
+		return err
+	}
+	return runCmdStreams(stdin, stdout, backend, args...)
+}
+
+func (c *Connection) copyToServer(src string, dst string, options CopyOptions, progress CopyProgress) error {
+	total, err := localSize(src)
+	if err != nil {
+		return utils.Errorf(err, L("cannot read %[1]s: %[2]s"), src)
+	}
+
+	// Copy inside the destination if it is an existing folder
+	if err := c.execStreams(nil, io.Discard, "test", "-d", dst); err == nil {
+		dst = path.Join(dst, filepath.Base(src))
+	}
+
+	dstDir := path.Dir(dst)
+	if err := c.execStreams(nil, io.Discard, "mkdir", "-p", dstDir); err != nil {
+		return utils.Errorf(err, L("cannot create %[1]s folder in the container: %[2]s"), dstDir)
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		counter := progressCounter{total: total, progress: progress}
+		writer.CloseWithError(writeTar(writer, src, path.Base(dst), &counter))
+	}()
+
+	ownerOption := "--no-same-owner"
+	if options.Archive {
+		ownerOption = "--same-owner"
+	}
+	err = c.execStreams(reader, io.Discard, "tar", "-C", dstDir, ownerOption, "-xpf", "-")
+	// Unblock the tar writer if the command failed before reading everything
+	reader.Close()
+	if err != nil {
+		return utils.Errorf(err, L("failed to copy %[1]s to the container: %[2]s"), src)
+	}
+	progress(total, total)
+
+	if options.User != "" {
+		owner := options.User
+		if options.Group != "" {
+			owner = options.User + ":" + options.Group
+		}
+		if err := c.execStreams(nil, io.Discard, "chown", "-R", owner, dst); err != nil {
+			return utils.Errorf(err, L("cannot set %[1]s owner on %[2]s: %[3]s"), owner, dst)
+		}
+	}
+	return nil
+}
+
+func (c *Connection) copyFromServer(src string, dst string, archive bool, progress CopyProgress) error {
+	var sizeOut bytes.Buffer
+	var total int64
+	if err := c.execStreams(nil, &sizeOut, "du", "-sb", src); err != nil {
+		return utils.Errorf(err, L("cannot read %[1]s in the container: %[2]s"), src)
+	}
+	if fields := strings.Fields(sizeOut.String()); len(fields) > 0 {
+		total, _ = strconv.ParseInt(fields[0], 10, 64)
+	}
+
+	// Copy inside the destination if it is an existing folder
+	if info, err := os.Stat(dst); err == nil && info.IsDir() {
+		dst = filepath.Join(dst, path.Base(src))
+	}
+
+	reader, writer := io.Pipe()
+	done := make(chan error)
+	go func() {
+		counter := progressCounter{total: total, progress: progress}
+		err := readTar(reader, path.Base(src), dst, archive, &counter)
+		// Drain the stream to let the command end if the extraction failed
+		_, _ = io.Copy(io.Discard, reader)
+		done <- err
+	}()
+
+	err := c.execStreams(nil, writer, "tar", "-C", path.Dir(src), "-cf", "-", path.Base(src))
+	writer.Close()
+	extractErr := <-done
+	if err != nil {
+		return utils.Errorf(err, L("failed to copy %[1]s from the container: %[2]s"), src)
+	}
+	if extractErr != nil {
+		return utils.Errorf(extractErr, L("failed to write %[1]s: %[2]s"), dst)
+	}
+	progress(total, total)
+	return nil
+}
+
+// progressCounter accumulates the copied bytes and reports them.
+type progressCounter struct {
+	copied   int64
+	total    int64
+	progress CopyProgress
+}
+
+func (p *progressCounter) Write(b []byte) (int, error) {
+	p.copied += int64(len(b))
+	if p.copied <= p.total {
+		p.progress(p.copied, p.total)
+	}
+	return len(b), nil
+}
+
+// localSize computes the size of the regular files in a path.
+func localSize(root string) (int64, error) {
+	var size int64
+	err := filepath.Walk(root, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// writeTar writes the src file or directory as a tar stream with name as top level entry.
+func writeTar(w io.Writer, src string, name string, counter io.Writer) error {
+	tarWriter := tar.NewWriter(w)
+	err := filepath.Walk(src, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, filePath)
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(filePath); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(name, filepath.ToSlash(rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(io.MultiWriter(tarWriter, counter), file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tarWriter.Close()
+}
+
+// readTar extracts a tar stream, renaming the name top level entry into dst.
+//
+// The archive mode restores the owners, if permitted, and the modification times.
+func readTar(r io.Reader, name string, dst string, archive bool, counter io.Writer) error {
+	dst = filepath.Clean(dst)
+	tarReader := tar.NewReader(r)
+	// The directories times are set at the end as extracting their content changes them
+	dirTimes := map[string]time.Time{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		entry := path.Clean(header.Name)
+		if entry != name && !strings.HasPrefix(entry, name+"/") {
+			log.Warn().Msgf(L("Skipping %s as it resolves outside the target path"), header.Name)
+			continue
+		}
+		rel := strings.TrimPrefix(entry, name)
+		target := filepath.Join(dst, filepath.FromSlash(rel))
+		if !isInside(dst, target) {
+			log.Warn().Msgf(L("Skipping %s as it resolves outside the target path"), header.Name)
+			continue
+		}
+		if header.Typeflag == tar.TypeSymlink && (filepath.IsAbs(header.Linkname) ||
+			!isInside(dst, filepath.Join(filepath.Dir(target), filepath.FromSlash(header.Linkname)))) {
+			log.Warn().Msgf(L("Skipping %[1]s as its %[2]s link target resolves outside the target path"),
+				header.Name, header.Linkname)
+			continue
+		}
+		// The archive comes from the container: never write through a link it may have created before
+		if err := checkParents(dst, target); err != nil {
+			return err
+		}
+		if err := replaceTarget(target, header.Typeflag == tar.TypeDir); err != nil {
+			return err
+		}
+
+		mode := header.FileInfo().Mode()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode.Perm()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+			if archive {
+				restoreOwner(target, header)
+			}
+			continue
+		case tar.TypeReg:
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(io.MultiWriter(file, counter), tarReader)
+			file.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			log.Debug().Msgf("Skipping %s of unsupported type", header.Name)
+			continue
+		}
+		if archive {
+			restoreOwner(target, header)
+		}
+		// Apply the permissions ignoring the umask
+		if err := os.Chmod(target, mode.Perm()); err != nil {
+			return err
+		}
+		if archive {
+			if header.Typeflag == tar.TypeDir {
+				dirTimes[target] = header.ModTime
+			} else if err := os.Chtimes(target, header.ModTime, header.ModTime); err != nil {
+				return err
+			}
+		}
+	}
+	for dir, modTime := range dirTimes {
+		if err := os.Chtimes(dir, modTime, modTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isInside returns whether the target path is the dst path or inside it.
+func isInside(dst string, target string) bool {
+	return target == dst || strings.HasPrefix(target, dst+string(os.PathSeparator))
+}
+
+// checkParents returns an error if one of the existing folders between dst and target is a symbolic link.
+func checkParents(dst string, target string) error {
+	rel, err := filepath.Rel(dst, filepath.Dir(target))
+	if err != nil || rel == "." {
+		return err
+	}
+	current := dst
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf(L("refusing to extract %[1]s: %[2]s is a symbolic link"), target, current)
+		}
+	}
+	return nil
+}
+
+// replaceTarget removes the existing file or link at the target path to extract an entry in its place.
+//
+// Existing folders are kept for the folder entries and never removed for the other ones.
+func replaceTarget(target string, isDir bool) error {
+	info, err := os.Lstat(target)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		if isDir {
+			return nil
+		}
+		return fmt.Errorf(L("cannot replace the %s folder"), target)
+	}
+	return os.Remove(target)
+}
+
+// restoreOwner sets the owner of an extracted file like in the archive.
+// Only root can do it: like cp --archive, the failure is not an error.
+func restoreOwner(target string, header *tar.Header) {
+	if err := os.Lchown(target, header.Uid, header.Gid); err != nil {
+		log.Debug().Err(err).Msgf("Cannot restore the owner of %s", target)
+	}
+}
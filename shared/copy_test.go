@@ -0,0 +1,335 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBackend emulates the container exec commands by running them locally in a root folder.
+type fakeBackend struct {
+	root   string
+	chowns [][]string
+}
+
+func (f *fakeBackend) run(stdin io.Reader, stdout io.Writer, command string, args ...string) error {
+	if args[0] != "exec" {
+		return exec.ErrNotFound
+	}
+	args = args[1:]
+	if args[0] == "-i" {
+		args = args[1:]
+	}
+	// Skip the pod name and the kubectl container arguments
+	args = args[1:]
+	if command == "kubectl" {
+		args = args[3:]
+	}
+
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "/") {
+			args[i] = filepath.Join(f.root, arg)
+		}
+	}
+
+	if args[0] == "chown" {
+		f.chowns = append(f.chowns, args[1:])
+		return nil
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = stdin
+	if args[0] == "sh" {
+		// Return the paths as seen in the container
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		err := cmd.Run()
+		_, _ = stdout.Write(bytes.ReplaceAll(out.Bytes(), []byte(f.root), []byte{}))
+		return err
+	}
+	cmd.Stdout = stdout
+	return cmd.Run()
+}
+
+func setupFakeBackend(t *testing.T) *fakeBackend {
+	backend := fakeBackend{root: t.TempDir()}
+	oldRunner := runCmdStreams
+	runCmdStreams = backend.run
+	t.Cleanup(func() {
+		runCmdStreams = oldRunner
+	})
+	return &backend
+}
+
+func writeTestFile(t *testing.T, filePath string, content string, mode os.FileMode) {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		t.Fatalf("failed to create folder for %s: %s", filePath, err)
+	}
+	if err := os.WriteFile(filePath, []byte(content), mode); err != nil {
+		t.Fatalf("failed to write %s: %s", filePath, err)
+	}
+	// Force the mode regardless of the umask
+	if err := os.Chmod(filePath, mode); err != nil {
+		t.Fatalf("failed to change %s mode: %s", filePath, err)
+	}
+}
+
+func checkTestFile(t *testing.T, filePath string, content string, mode os.FileMode) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Errorf("failed to read %s: %s", filePath, err)
+		return
+	}
+	if string(data) != content {
+		t.Errorf("%s: expected content %s, got %s", filePath, content, string(data))
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Errorf("failed to stat %s: %s", filePath, err)
+		return
+	}
+	if info.Mode().Perm() != mode {
+		t.Errorf("%s: expected mode %o, got %o", filePath, mode, info.Mode().Perm())
+	}
+}
+
+func TestCopyDirectoryToServer(t *testing.T) {
+	for _, command := range []string{"podman", "kubectl"} {
+		backend := setupFakeBackend(t)
+		cnx := Connection{backend: command, command: command, podName: "uyuni-server"}
+
+		srcDir := t.TempDir()
+		writeTestFile(t, filepath.Join(srcDir, "tree", "file.txt"), "some content", 0640)
+		writeTestFile(t, filepath.Join(srcDir, "tree", "sub", "script.sh"), "#!/bin/sh", 0755)
+
+		var lastCopied, lastTotal int64
+		options := CopyOptions{
+			User:  "tomcat",
+			Group: "susemanager",
+			Progress: func(copied int64, total int64) {
+				lastCopied = copied
+				lastTotal = total
+			},
+		}
+		if err := cnx.CopyWithOptions(filepath.Join(srcDir, "tree"), "server:/srv/www/distro", options); err != nil {
+			t.Fatalf("%s: unexpected error: %s", command, err)
+		}
+
+		dst := filepath.Join(backend.root, "srv", "www", "distro")
+		checkTestFile(t, filepath.Join(dst, "file.txt"), "some content", 0640)
+		checkTestFile(t, filepath.Join(dst, "sub", "script.sh"), "#!/bin/sh", 0755)
+
+		expectedSize := int64(len("some content") + len("#!/bin/sh"))
+		if lastCopied != expectedSize || lastTotal != expectedSize {
+			t.Errorf("%s: expected final progress %d/%d, got %d/%d", command, expectedSize, expectedSize, lastCopied, lastTotal)
+		}
+
+		if len(backend.chowns) != 1 || strings.Join(backend.chowns[0], " ") != "-R tomcat:susemanager "+dst {
+			t.Errorf("%s: unexpected chown calls: %v", command, backend.chowns)
+		}
+	}
+}
+
+func TestCopyFileToServerFolder(t *testing.T) {
+	backend := setupFakeBackend(t)
+	cnx := Connection{backend: "podman", command: "podman", podName: "uyuni-server"}
+
+	srcFile := filepath.Join(t.TempDir(), "key.asc")
+	writeTestFile(t, srcFile, "key", 0600)
+	if err := os.MkdirAll(filepath.Join(backend.root, "tmp"), 0755); err != nil {
+		t.Fatalf("failed to create tmp folder: %s", err)
+	}
+
+	if err := cnx.CopyWithOptions(srcFile, "server:/tmp", CopyOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	checkTestFile(t, filepath.Join(backend.root, "tmp", "key.asc"), "key", 0600)
+
+	if len(backend.chowns) != 0 {
+		t.Errorf("unexpected chown calls: %v", backend.chowns)
+	}
+}
+
+func TestCopyFromServer(t *testing.T) {
+	for _, command := range []string{"podman", "kubectl"} {
+		backend := setupFakeBackend(t)
+		cnx := Connection{backend: command, command: command, podName: "uyuni-server"}
+
+		writeTestFile(t, filepath.Join(backend.root, "tmp", "scc_supportconfig.txz"), "tarball", 0600)
+
+		dst := filepath.Join(t.TempDir(), "container-supportconfig.txz")
+		if err := cnx.CopyWithOptions("server:/tmp/scc_supportconfig.txz", dst, CopyOptions{}); err != nil {
+			t.Fatalf("%s: unexpected error: %s", command, err)
+		}
+		checkTestFile(t, dst, "tarball", 0600)
+	}
+}
+
+func TestCopyRequiresOneServerPath(t *testing.T) {
+	setupFakeBackend(t)
+	cnx := Connection{backend: "podman", command: "podman", podName: "uyuni-server"}
+
+	if err := cnx.CopyWithOptions("/tmp/a", "/tmp/b", CopyOptions{}); err == nil {
+		t.Error("expected an error for a copy without server path")
+	}
+	if err := cnx.CopyWithOptions("server:/tmp/a", "server:/tmp/b", CopyOptions{}); err == nil {
+		t.Error("expected an error for a copy with two server paths")
+	}
+}
+
+func TestCopyGlobToServer(t *testing.T) {
+	backend := setupFakeBackend(t)
+	cnx := Connection{backend: "podman", command: "podman", podName: "uyuni-server"}
+
+	srcDir := t.TempDir()
+	writeTestFile(t, filepath.Join(srcDir, "a.conf"), "a", 0644)
+	writeTestFile(t, filepath.Join(srcDir, "b.conf"), "b", 0600)
+	writeTestFile(t, filepath.Join(srcDir, "c.txt"), "c", 0644)
+
+	if err := cnx.CopyWithOptions(filepath.Join(srcDir, "*.conf"), "server:/etc/rhn", CopyOptions{}); err == nil {
+		t.Error("expected an error when copying several files to a missing folder")
+	}
+
+	if err := os.MkdirAll(filepath.Join(backend.root, "etc", "rhn"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := cnx.CopyWithOptions(filepath.Join(srcDir, "*.conf"), "server:/etc/rhn", CopyOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	checkTestFile(t, filepath.Join(backend.root, "etc", "rhn", "a.conf"), "a", 0644)
+	checkTestFile(t, filepath.Join(backend.root, "etc", "rhn", "b.conf"), "b", 0600)
+	if _, err := os.Stat(filepath.Join(backend.root, "etc", "rhn", "c.txt")); err == nil {
+		t.Error("c.txt does not match the pattern and should not be copied")
+	}
+
+	if err := cnx.CopyWithOptions(filepath.Join(srcDir, "*.xml"), "server:/etc/rhn", CopyOptions{}); err == nil {
+		t.Error("expected an error for a pattern without match")
+	}
+}
+
+func TestCopyGlobFromServer(t *testing.T) {
+	for _, command := range []string{"podman", "kubectl"} {
+		backend := setupFakeBackend(t)
+		cnx := Connection{backend: command, command: command, podName: "uyuni-server"}
+
+		writeTestFile(t, filepath.Join(backend.root, "etc", "rhn", "rhn.conf"), "rhn", 0640)
+		writeTestFile(t, filepath.Join(backend.root, "etc", "rhn", "my config.conf"), "spaces", 0644)
+		writeTestFile(t, filepath.Join(backend.root, "etc", "rhn", "rhn.xml"), "xml", 0644)
+
+		dst := t.TempDir()
+		if err := cnx.CopyWithOptions("server:/etc/rhn/*.conf", dst, CopyOptions{}); err != nil {
+			t.Fatalf("%s: unexpected error: %s", command, err)
+		}
+		checkTestFile(t, filepath.Join(dst, "rhn.conf"), "rhn", 0640)
+		checkTestFile(t, filepath.Join(dst, "my config.conf"), "spaces", 0644)
+		if _, err := os.Stat(filepath.Join(dst, "rhn.xml")); err == nil {
+			t.Errorf("%s: rhn.xml does not match the pattern and should not be copied", command)
+		}
+
+		if err := cnx.CopyWithOptions("server:/etc/rhn/*.conf", filepath.Join(dst, "missing"), CopyOptions{}); err == nil {
+			t.Errorf("%s: expected an error when copying several files to a missing folder", command)
+		}
+	}
+}
+
+func TestCopyArchiveFromServer(t *testing.T) {
+	backend := setupFakeBackend(t)
+	cnx := Connection{backend: "podman", command: "podman", podName: "uyuni-server"}
+
+	srcFile := filepath.Join(backend.root, "srv", "tree", "file.txt")
+	writeTestFile(t, srcFile, "content", 0644)
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, path := range []string{srcFile, filepath.Dir(srcFile)} {
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join(t.TempDir(), "tree")
+	if err := cnx.CopyWithOptions("server:/srv/tree", dst, CopyOptions{Archive: true}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, path := range []string{filepath.Join(dst, "file.txt"), dst} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.ModTime().Equal(modTime) {
+			t.Errorf("%s: expected modification time %s, got %s", path, modTime, info.ModTime())
+		}
+	}
+}
+
+// writeTestTar writes a tar stream with the given headers, the regular files containing their name.
+func writeTestTar(t *testing.T, headers ...tar.Header) *bytes.Buffer {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for _, header := range headers {
+		content := []byte(header.Name)
+		if header.Typeflag == tar.TypeReg {
+			header.Size = int64(len(content))
+		}
+		header.Mode = 0644
+		if err := writer.WriteHeader(&header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := writer.Write(content); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestReadTarLinks(t *testing.T) {
+	outside := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "tree")
+	writeTestFile(t, filepath.Join(dst, "replaced"), "old", 0644)
+
+	stream := writeTestTar(t,
+		tar.Header{Name: "tree/", Typeflag: tar.TypeDir},
+		tar.Header{Name: "tree/file.txt", Typeflag: tar.TypeReg},
+		tar.Header{Name: "tree/absolute", Typeflag: tar.TypeSymlink, Linkname: outside},
+		tar.Header{Name: "tree/escaping", Typeflag: tar.TypeSymlink, Linkname: "../.."},
+		tar.Header{Name: "tree/replaced", Typeflag: tar.TypeSymlink, Linkname: "file.txt"},
+	)
+	if err := readTar(stream, "tree", dst, false, io.Discard); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, name := range []string{"absolute", "escaping"} {
+		if _, err := os.Lstat(filepath.Join(dst, name)); err == nil {
+			t.Errorf("%s link should have been skipped", name)
+		}
+	}
+	if link, err := os.Readlink(filepath.Join(dst, "replaced")); err != nil || link != "file.txt" {
+		t.Errorf("expected the existing file to be replaced by a link, got %s: %v", link, err)
+	}
+
+	// Writing through a link created by the archive fails, even if it points inside the target
+	stream = writeTestTar(t,
+		tar.Header{Name: "tree/", Typeflag: tar.TypeDir},
+		tar.Header{Name: "tree/sub/", Typeflag: tar.TypeDir},
+		tar.Header{Name: "tree/link", Typeflag: tar.TypeSymlink, Linkname: "sub"},
+		tar.Header{Name: "tree/link/file.txt", Typeflag: tar.TypeReg},
+	)
+	if err := readTar(stream, "tree", dst, false, io.Discard); err == nil {
+		t.Error("expected an error when writing through a link")
+	}
+	if _, err := os.Lstat(filepath.Join(dst, "sub", "file.txt")); err == nil {
+		t.Error("unexpected file written through the link")
+	}
+}
@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package docker contains the container operations for the experimental docker backend.
+package docker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ServerContainerName is the name of the server container.
+const ServerContainerName = "uyuni-server"
+
+// UyuniNetwork is the name of the docker network used by the containers.
+const UyuniNetwork = "uyuni"
+
+// commonArgs are the docker run arguments needed to run systemd in the container.
+const commonArgs = "--rm --cap-add NET_RAW --cgroupns=host --tmpfs /run --tmpfs /run/lock -v /sys/fs/cgroup:/sys/fs/cgroup:rw"
+
+// GetCommonParams splits the common arguments.
+func GetCommonParams() []string {
+	return strings.Split(commonArgs, " ")
+}
+
+// SetupNetwork creates the docker network if needed.
+func SetupNetwork() error {
+	if err := utils.RunCmd("docker", "network", "inspect", UyuniNetwork); err == nil {
+		log.Debug().Msgf("%s network already present", UyuniNetwork)
+		return nil
+	}
+
+	log.Info().Msgf(L("Setting up %s network"), UyuniNetwork)
+	if err := utils.RunCmd("docker", "network", "create", UyuniNetwork); err != nil {
+		return utils.Errorf(err, L("failed to create %[1]s network: %[2]s"), UyuniNetwork)
+	}
+	return nil
+}
+
+// CreateVolumes creates the named volumes which don't exist yet.
+func CreateVolumes(mounts []types.VolumeMount) error {
+	for _, mount := range mounts {
+		if err := utils.RunCmd("docker", "volume", "inspect", mount.Name); err == nil {
+			continue
+		}
+		if err := utils.RunCmd("docker", "volume", "create", mount.Name); err != nil {
+			return utils.Errorf(err, L("failed to create %[1]s volume: %[2]s"), mount.Name)
+		}
+	}
+	return nil
+}
+
+// PrepareImage pulls the image if needed depending on the pull policy.
+func PrepareImage(image string, pullPolicy string) error {
+	policy := strings.ToLower(pullPolicy)
+	if policy != "always" {
+		if _, err := utils.RunCmdOutput(zerolog.DebugLevel, "docker", "image", "inspect", image); err == nil {
+			log.Debug().Msgf("Image %s already present", image)
+			return nil
+		}
+		if policy == "never" {
+			return utils.WithCode(utils.CodeImagePull, fmt.Errorf(L("image %s is missing and cannot be fetched"), image))
+		}
+	}
+
+	log.Info().Msgf(L("Pulling image %s"), image)
+	if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "docker", "pull", image); err != nil {
+		return utils.WithCode(utils.CodeImagePull, utils.Errorf(err, L("failed to pull image %[1]s: %[2]s"), image))
+	}
+	return nil
+}
+
+// DeleteContainer kills and removes a container if it exists.
+//
+// If dryRun is set to true, nothing will be done, only messages logged to explain what would happen.
+func DeleteContainer(name string, dryRun bool) {
+	out, _ := utils.RunCmdOutput(zerolog.DebugLevel, "docker", "ps", "-a", "-q", "-f", "name="+name)
+	if len(out) == 0 {
+		log.Info().Msg(L("Container already removed"))
+		return
+	}
+	if dryRun {
+		log.Info().Msgf(L("Would run %s"), "docker rm -f "+name)
+		return
+	}
+	log.Info().Msgf(L("Run %s"), "docker rm -f "+name)
+	if err := utils.RunCmd("docker", "rm", "-f", name); err != nil {
+		log.Error().Err(err).Msg(L("Error removing container"))
+	}
+}
+
+// DeleteVolume removes a docker volume if it exists.
+//
+// If dryRun is set to true, nothing will be done, only messages logged to explain what would happen.
+func DeleteVolume(name string, dryRun bool) error {
+	if err := utils.RunCmd("docker", "volume", "inspect", name); err != nil {
+		return nil
+	}
+	if dryRun {
+		log.Info().Msgf(L("Would run %s"), "docker volume rm "+name)
+		return nil
+	}
+	log.Info().Msgf(L("Run %s"), "docker volume rm "+name)
+	if err := utils.RunCmd("docker", "volume", "rm", name); err != nil {
+		return utils.Errorf(err, L("failed to remove volume %[1]s: %[2]s"), name)
+	}
+	return nil
+}
+
+// DeleteNetwork removes the docker network if it exists.
+//
+// If dryRun is set to true, nothing will be done, only messages logged to explain what would happen.
+func DeleteNetwork(dryRun bool) {
+	if err := utils.RunCmd("docker", "network", "inspect", UyuniNetwork); err != nil {
+		log.Info().Msgf(L("Network %s already removed"), UyuniNetwork)
+		return
+	}
+	if dryRun {
+		log.Info().Msgf(L("Would run %s"), "docker network rm "+UyuniNetwork)
+		return
+	}
+	if err := utils.RunCmd("docker", "network", "rm", UyuniNetwork); err != nil {
+		log.Error().Msgf(L("Failed to remove network %s"), UyuniNetwork)
+	} else {
+		log.Info().Msg(L("Network removed"))
+	}
+}
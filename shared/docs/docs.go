@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package docs
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type generateFlags struct {
+	Man struct {
+		Dir string
+	}
+	Markdown struct {
+		Dir string
+	}
+}
+
+// NewCommand for generating the reference documentation of the tool.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	docsCmd := &cobra.Command{
+		Use:    "docs",
+		Short:  L("Reference documentation"),
+		Long:   L("Reference documentation"),
+		Hidden: true,
+	}
+
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: L("Generate the man pages and markdown reference"),
+		Long: L(`Generate the man pages and markdown reference
+
+One page is written for each of the commands of the tool, documenting all their flags.
+The pages are generated from the commands themselves and are then always up to date.
+`),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var flags generateFlags
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, generate)
+		},
+	}
+	generateCmd.Flags().String("man-dir", "", L("Directory where to write the man pages"))
+	generateCmd.Flags().String("markdown-dir", "", L("Directory where to write the markdown pages"))
+
+	docsCmd.AddCommand(generateCmd)
+	return docsCmd
+}
+
+func generate(globalFlags *types.GlobalFlags, flags *generateFlags, cmd *cobra.Command, args []string) error {
+	if flags.Man.Dir == "" && flags.Markdown.Dir == "" {
+		return utils.UsageError(errors.New(L("at least one of --man-dir or --markdown-dir is required")))
+	}
+
+	root := cmd.Root()
+	// The generation date would make the output differ for each build
+	root.DisableAutoGenTag = true
+
+	if flags.Man.Dir != "" {
+		if err := os.MkdirAll(flags.Man.Dir, 0755); err != nil {
+			return utils.Errorf(err, L("failed to create directory %[1]s: %[2]s"), flags.Man.Dir)
+		}
+		header := &doc.GenManHeader{
+			Title:   strings.ToUpper(root.Name()),
+			Section: "1",
+			Source:  "uyuni-tools",
+			Manual:  L("Uyuni tools"),
+		}
+		if err := doc.GenManTree(root, header, flags.Man.Dir); err != nil {
+			return utils.Errorf(err, L("failed to generate the man pages: %s"))
+		}
+	}
+
+	if flags.Markdown.Dir != "" {
+		if err := os.MkdirAll(flags.Markdown.Dir, 0755); err != nil {
+			return utils.Errorf(err, L("failed to create directory %[1]s: %[2]s"), flags.Markdown.Dir)
+		}
+		if err := doc.GenMarkdownTree(root, flags.Markdown.Dir); err != nil {
+			return utils.Errorf(err, L("failed to generate the markdown pages: %s"))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package docs
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func TestGenerate(t *testing.T) {
+	globalFlags := types.GlobalFlags{}
+	rootCmd := &cobra.Command{Use: "mgrtest"}
+	statusCmd := &cobra.Command{Use: "status", Short: "Get the status", Run: func(*cobra.Command, []string) {}}
+	statusCmd.Flags().String("backend", "", "tool to use to reach the container")
+	rootCmd.AddCommand(statusCmd)
+	docsCmd := NewCommand(&globalFlags)
+	rootCmd.AddCommand(docsCmd)
+	generateCmd, _, err := rootCmd.Find([]string{"docs", "generate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	var flags generateFlags
+	flags.Man.Dir = path.Join(dir, "man")
+	flags.Markdown.Dir = path.Join(dir, "md")
+	if err := generate(&globalFlags, &flags, generateCmd, []string{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	page, err := os.ReadFile(path.Join(flags.Man.Dir, "mgrtest-status.1"))
+	if err != nil {
+		t.Fatalf("missing status man page: %s", err)
+	}
+	if !strings.Contains(string(page), "backend") {
+		t.Errorf("the man page should document the flags:\n%s", page)
+	}
+	if _, err := os.Stat(path.Join(flags.Markdown.Dir, "mgrtest_status.md")); err != nil {
+		t.Errorf("missing status markdown page: %s", err)
+	}
+	// The hidden docs command is not documented
+	if _, err := os.Stat(path.Join(flags.Man.Dir, "mgrtest-docs.1")); err == nil {
+		t.Error("the hidden docs command should not be documented")
+	}
+
+	if err := generate(&globalFlags, &generateFlags{}, generateCmd, []string{}); utils.ExitCode(err) != utils.ExitUsage {
+		t.Errorf("expected a usage error without output directory, got %v", err)
+	}
+}
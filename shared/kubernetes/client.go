@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// newClient creates the kubernetes API client for the cluster selected by the cluster flags.
+//
+// This can be changed for the tests to use a fake client.
+var newClient = func() (k8s.Interface, error) {
+	config, err := clientConfig().ClientConfig()
+	if err != nil {
+		return nil, utils.WithCode(utils.CodeCluster, utils.Errorf(err, L("failed to read the kubernetes configuration: %s")))
+	}
+	client, err := k8s.NewForConfig(config)
+	if err != nil {
+		return nil, utils.WithCode(utils.CodeCluster, utils.Errorf(err, L("failed to create the kubernetes client: %s")))
+	}
+	return client, nil
+}
+
+// clientConfig returns the kubeconfig loader for the cluster selected by the cluster flags.
+func clientConfig() clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = clusterFlags.Kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: clusterFlags.Context}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+}
+
+// contextNamespace returns the namespace or the default namespace of the kubeconfig context if empty, like kubectl.
+func contextNamespace(namespace string) string {
+	if namespace != "" {
+		return namespace
+	}
+	if contextNamespace, _, err := clientConfig().Namespace(); err == nil && contextNamespace != "" {
+		return contextNamespace
+	}
+	return metav1.NamespaceDefault
+}
+
+// labelSelector converts a kubectl filter like -lapp=uyuni into a label selector.
+func labelSelector(filter string) string {
+	return strings.TrimPrefix(filter, "-l")
+}
+
+// listOptions returns the options to list the objects matching a kubectl filter.
+func listOptions(filter string) metav1.ListOptions {
+	return metav1.ListOptions{LabelSelector: labelSelector(filter)}
+}
+
+// watchFunc starts watching objects, like the Watch functions of the client.
+type watchFunc func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+
+const (
+	// watchRetryDelay is the initial delay before watching again when the server closed the watch without event.
+	watchRetryDelay = 100 * time.Millisecond
+	// watchMaxRetryDelay is the maximum delay before watching again.
+	watchMaxRetryDelay = 5 * time.Second
+)
+
+// waitForChange watches the objects from the resource version of a list until the first event.
+//
+// This is used to check the listed objects again only when they changed, instead of polling.
+// Returns whether an event was received: the server may also close the watch without any.
+// The context error is returned if it is done first, context.DeadlineExceeded for a timeout.
+func waitForChange(ctx context.Context, watchObjects watchFunc, opts metav1.ListOptions, resourceVersion string) (
+	bool, error,
+) {
+	opts.ResourceVersion = resourceVersion
+	watcher, err := watchObjects(ctx, opts)
+	if err != nil {
+		return false, err
+	}
+	defer watcher.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case _, ok := <-watcher.ResultChan():
+		// Even an error event only means the objects need to be listed again
+		return ok, nil
+	}
+}
+
+// waitUntil lists the objects and checks them with the done function until it returns true.
+//
+// The objects are only checked again when the watch reports a change. The check function returns
+// the resource version of its list to start watching from. A zero timeout waits forever.
+// context.DeadlineExceeded is returned if the timeout is reached.
+func waitUntil(
+	timeout time.Duration,
+	watchObjects watchFunc,
+	opts metav1.ListOptions,
+	check func(ctx context.Context) (done bool, resourceVersion string, err error),
+) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
+	defer cancel()
+	delay := watchRetryDelay
+	for {
+		done, resourceVersion, err := check(ctx)
+		if err != nil || done {
+			return err
+		}
+		changed, err := waitForChange(ctx, watchObjects, opts, resourceVersion)
+		if err != nil {
+			return err
+		}
+		if changed {
+			delay = watchRetryDelay
+			continue
+		}
+
+		// Don't recreate the watch in a tight loop if the server keeps closing it
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > watchMaxRetryDelay {
+			delay = watchMaxRetryDelay
+		}
+	}
+}
@@ -5,138 +5,355 @@
 package kubernetes
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os/exec"
-	"strings"
+	"os"
+	"regexp"
+	"sort"
+	"time"
 
-	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	appsv1 "k8s.io/api/apps/v1"
 )
 
-// HelmUpgrade runs the helm upgrade command.
+// helmTimeout is the maximum time helm waits for the resources when rolling back atomically, like the helm default.
+const helmTimeout = 5 * time.Minute
+
+// helmSettings returns the helm environment settings targeting the cluster of the cluster flags.
 //
-// To perform an installation, set the install parameter to true: helm would get the --install parameter.
-// If repo is not empty, the --repo parameter will be passed.
-// If version is not empty, the --version parameter will be passed.
-func HelmUpgrade(kubeconfig string, namespace string, install bool,
-	repo string, name string, chart string, version string, args ...string) error {
-	helmArgs := []string{
-		"upgrade",
-		"-n", namespace,
-		"--create-namespace",
-		name,
-		chart,
+// The kubeconfig flag value overrides the kubeconfig parameter.
+func helmSettings(kubeconfig string, namespace string) *cli.EnvSettings {
+	settings := cli.New()
+	if clusterFlags.Kubeconfig != "" {
+		kubeconfig = clusterFlags.Kubeconfig
 	}
 	if kubeconfig != "" {
-		helmArgs = append(helmArgs, "--kubeconfig", kubeconfig)
+		settings.KubeConfig = kubeconfig
 	}
+	if clusterFlags.Context != "" {
+		settings.KubeContext = clusterFlags.Context
+	}
+	settings.SetNamespace(namespace)
+	return settings
+}
 
-	if repo != "" {
-		helmArgs = append(helmArgs, "--repo", repo)
+// newHelmConfig creates the helm actions configuration for a namespace, all namespaces if empty.
+//
+// This can be changed for the tests to use an in-memory release storage.
+var newHelmConfig = func(kubeconfig string, namespace string) (*action.Configuration, *cli.EnvSettings, error) {
+	settings := helmSettings(kubeconfig, namespace)
+	config := new(action.Configuration)
+	helmLog := func(format string, v ...interface{}) {
+		log.Debug().Msgf(format, v...)
 	}
-	if version != "" {
-		helmArgs = append(helmArgs, "--version", version)
+	if err := config.Init(settings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), helmLog); err != nil {
+		return nil, nil, utils.WithCode(utils.CodeCluster, utils.Errorf(err, L("failed to initialize helm: %s")))
 	}
-	if install {
-		helmArgs = append(helmArgs, "--install")
+	registryClient, err := registry.NewClient(
+		registry.ClientOptCredentialsFile(settings.RegistryConfig),
+		registry.ClientOptEnableCache(true),
+	)
+	if err != nil {
+		return nil, nil, utils.Errorf(err, L("failed to create the helm registry client: %s"))
 	}
+	config.RegistryClient = registryClient
+	return config, settings, nil
+}
 
-	helmArgs = append(helmArgs, args...)
+// loadChart downloads the chart if needed and loads it.
+func loadChart(pathOptions *action.ChartPathOptions, settings *cli.EnvSettings, name string) (*chart.Chart, error) {
+	chartPath, err := pathOptions.LocateChart(name, settings)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to find helm chart %[1]s: %[2]s"), name)
+	}
+	loaded, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to load helm chart %[1]s: %[2]s"), name)
+	}
+	return loaded, nil
+}
 
+// HelmUpgrade installs or upgrades a helm release.
+//
+// To perform an installation, set the install parameter to true: the release is installed if it doesn't exist.
+// If repo is not empty, the chart is looked for in this repository.
+// If version is not empty, this version of the chart is used.
+//
+// The args are helm command line parameters: the values are computed like [HelmValues] does
+// and the --atomic, --reuse-values and --reset-values switches are honored.
+// With --atomic, helm waits for the resources and rolls back to the previous revision if anything fails, hooks included.
+func HelmUpgrade(kubeconfig string, namespace string, install bool,
+	repo string, name string, chart string, version string, args ...string) error {
 	command := "upgrade"
 	if install {
 		command = "install"
 	}
-	if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "helm", helmArgs...); err != nil {
-		return fmt.Errorf(L("failed to %s helm chart %s in namespace %s")+": %s", command, chart, namespace, err)
+	if err := helmUpgrade(kubeconfig, namespace, install, repo, name, chart, version, args...); err != nil {
+		return utils.Errorf(err, L("failed to %s helm chart %s in namespace %s")+": %s", command, chart, namespace)
 	}
 	return nil
 }
 
-// HelmUninstall runs the helm uninstall command to remove a deployment.
-func HelmUninstall(kubeconfig string, deployment string, filter string, dryRun bool) (string, error) {
-	helmArgs := []string{}
-	if kubeconfig != "" {
-		helmArgs = append(helmArgs, "--kubeconfig", kubeconfig)
+func helmUpgrade(kubeconfig string, namespace string, install bool,
+	repo string, name string, chart string, version string, args ...string) error {
+	values, err := HelmValues(args)
+	if err != nil {
+		return err
+	}
+	atomic := utils.Contains(args, "--atomic")
+
+	config, settings, err := newHelmConfig(kubeconfig, namespace)
+	if err != nil {
+		return err
 	}
 
-	jsonpath := fmt.Sprintf("jsonpath={.items[?(@.metadata.name==\"%s\")].metadata.namespace}", deployment)
-	args := []string{"get", "-A", "deploy", "-o", jsonpath}
-	if filter != "" {
-		args = append(args, filter)
+	if install {
+		history := action.NewHistory(config)
+		history.Max = 1
+		if _, err := history.Run(name); errors.Is(err, driver.ErrReleaseNotFound) {
+			installAction := action.NewInstall(config)
+			installAction.ReleaseName = name
+			installAction.Namespace = namespace
+			installAction.CreateNamespace = true
+			installAction.RepoURL = repo
+			installAction.Version = version
+			installAction.Atomic = atomic
+			installAction.Wait = atomic
+			installAction.Timeout = helmTimeout
+			loaded, err := loadChart(&installAction.ChartPathOptions, settings, chart)
+			if err != nil {
+				return err
+			}
+			_, err = installAction.Run(loaded, values)
+			return err
+		} else if err != nil {
+			return err
+		}
 	}
 
-	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", args...)
+	upgrade := action.NewUpgrade(config)
+	upgrade.Namespace = namespace
+	upgrade.RepoURL = repo
+	upgrade.Version = version
+	upgrade.Atomic = atomic
+	upgrade.Wait = atomic
+	upgrade.Timeout = helmTimeout
+	upgrade.ReuseValues = utils.Contains(args, "--reuse-values")
+	upgrade.ResetValues = utils.Contains(args, "--reset-values")
+	loaded, err := loadChart(&upgrade.ChartPathOptions, settings, chart)
 	if err != nil {
-		log.Info().Err(err).Msgf(L("Failed to find %s's namespace, skipping removal"), deployment)
+		return err
 	}
+	_, err = upgrade.Run(name, loaded, values)
+	return err
+}
 
-	namespace := string(out)
+// HelmUninstall uninstalls a helm release to remove a deployment.
+//
+// The namespace of the deployment is detected if empty.
+func HelmUninstall(kubeconfig string, namespace string, deployment string, filter string, dryRun bool) (string, error) {
 	if namespace == "" {
-		log.Debug().Msgf("Pod is not running, trying to find the namespace using the helm release")
-		namespace, err = FindNamespace(deployment, kubeconfig)
-		if err != nil {
-			log.Info().Err(err).Msgf(L("Cannot guess namespace"))
-			return "", nil
-		}
+		namespace = findDeploymentNamespace(kubeconfig, deployment, filter)
 	}
 
 	if namespace != "" {
-		helmArgs = append(helmArgs, "uninstall", "-n", namespace, deployment)
-
 		if dryRun {
-			log.Info().Msgf(L("Would run %s"), "helm "+strings.Join(helmArgs, " "))
+			log.Info().Msgf(L("Would uninstall %[1]s helm release in %[2]s namespace"), deployment, namespace)
 		} else {
 			log.Info().Msgf(L("Uninstalling %s"), deployment)
-			if err := utils.RunCmd("helm", helmArgs...); err != nil {
-				return namespace, fmt.Errorf(L("failed to run helm %s: %s"), strings.Join(helmArgs, " "), err)
+			config, _, err := newHelmConfig(kubeconfig, namespace)
+			if err != nil {
+				return namespace, err
+			}
+			if _, err := action.NewUninstall(config).Run(deployment); err != nil {
+				return namespace, utils.Errorf(err, L("failed to uninstall helm release %[1]s: %[2]s"), deployment)
 			}
 		}
 	}
 	return namespace, nil
 }
 
-// FindNamespace tries to find the deployment namespace using helm.
-func FindNamespace(deployment string, kubeconfig string) (string, error) {
-	args := []string{}
-	if kubeconfig != "" {
-		args = append(args, "--kubeconfig", kubeconfig)
+// findDeploymentNamespace looks for the namespace of a deployment or its helm release.
+// The empty string is returned if none is found.
+func findDeploymentNamespace(kubeconfig string, deployment string, filter string) string {
+	namespace := ""
+	client, err := newClient()
+	if err == nil {
+		opts := listOptions(filter)
+		opts.FieldSelector = "metadata.name=" + deployment
+		var deployments *appsv1.DeploymentList
+		deployments, err = client.AppsV1().Deployments("").List(context.Background(), opts)
+		if err == nil && len(deployments.Items) > 0 {
+			namespace = deployments.Items[0].Namespace
+		}
 	}
-	args = append(args, "list", "-aA", "-f", deployment, "-o", "json")
-	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "helm", args...)
 	if err != nil {
-		return "", fmt.Errorf(L("failed to detect %s's namespace using helm: %s"), deployment, err)
+		log.Info().Err(err).Msgf(L("Failed to find %s's namespace, skipping removal"), deployment)
 	}
-	var data []releaseInfo
-	if err = json.Unmarshal(out, &data); err != nil {
-		return "", fmt.Errorf(L("helm provided an invalid JSON output: %s"), err)
+
+	if namespace == "" {
+		log.Debug().Msgf("Pod is not running, trying to find the namespace using the helm release")
+		namespace, err = FindNamespace(deployment, kubeconfig)
+		if err != nil {
+			log.Info().Err(err).Msgf(L("Cannot guess namespace"))
+			return ""
+		}
+	}
+	return namespace
+}
+
+// listHelmReleases lists the releases in any state matching the filter regular expression in a namespace,
+// all namespaces if empty.
+func listHelmReleases(kubeconfig string, namespace string, filter string) ([]*release.Release, error) {
+	config, _, err := newHelmConfig(kubeconfig, namespace)
+	if err != nil {
+		return nil, err
 	}
+	list := action.NewList(config)
+	list.All = true
+	list.AllNamespaces = namespace == ""
+	list.Filter = filter
+	list.SetStateMask()
+	return list.Run()
+}
 
-	if len(data) == 1 {
-		return data[0].Namespace, nil
+// FindNamespace tries to find the deployment namespace using helm.
+func FindNamespace(deployment string, kubeconfig string) (string, error) {
+	releases, err := listHelmReleases(kubeconfig, "", deployment)
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to detect %s's namespace using helm: %s"), deployment)
+	}
+
+	if len(releases) == 1 {
+		return releases[0].Namespace, nil
 	}
 	return "", errors.New(L("found no or more than one deployment"))
 }
 
 // HasHelmRelease returns whether a helm release is installed or not, even if it failed.
 func HasHelmRelease(release string, kubeconfig string) bool {
-	if _, err := exec.LookPath("helm"); err == nil {
-		args := []string{}
-		if kubeconfig != "" {
-			args = append(args, "--kubeconfig", kubeconfig)
+	releases, err := listHelmReleases(kubeconfig, "", release)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to list the %s helm releases", release)
+		return false
+	}
+	return len(releases) != 0
+}
+
+// HelmRelease represents the helm release information.
+type HelmRelease struct {
+	Name       string
+	Namespace  string
+	Revision   int
+	Updated    string
+	Status     string
+	Chart      string
+	AppVersion string
+}
+
+// GetHelmRelease returns the information of a helm release in a namespace.
+func GetHelmRelease(kubeconfig string, namespace string, name string) (*HelmRelease, error) {
+	releases, err := listHelmReleases(kubeconfig, namespace, "^"+regexp.QuoteMeta(name)+"$")
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get helm release %[1]s: %[2]s"), name)
+	}
+	if len(releases) != 1 {
+		return nil, fmt.Errorf(L("no %[1]s helm release found in %[2]s namespace"), name, namespace)
+	}
+	return newHelmRelease(releases[0]), nil
+}
+
+func newHelmRelease(item *release.Release) *HelmRelease {
+	result := HelmRelease{
+		Name:      item.Name,
+		Namespace: item.Namespace,
+		Revision:  item.Version,
+	}
+	if item.Info != nil {
+		result.Updated = item.Info.LastDeployed.String()
+		result.Status = item.Info.Status.String()
+	}
+	if item.Chart != nil && item.Chart.Metadata != nil {
+		result.Chart = item.Chart.Metadata.Name + "-" + item.Chart.Metadata.Version
+		result.AppVersion = item.Chart.Metadata.AppVersion
+	}
+	return &result
+}
+
+// GetHelmValues returns the user-supplied values of a helm release revision.
+func GetHelmValues(kubeconfig string, namespace string, release string, revision int) (map[string]interface{}, error) {
+	config, _, err := newHelmConfig(kubeconfig, namespace)
+	if err != nil {
+		return nil, err
+	}
+	getValues := action.NewGetValues(config)
+	getValues.Version = revision
+	values, err := getValues.Run(release)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the values of helm release %[1]s revision %[2]d: %[3]s"),
+			release, revision)
+	}
+	// No value may have been set
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return values, nil
+}
+
+// DiffHelmValues returns the sorted list of changes between two sets of helm values.
+//
+// Nested values are flattened using dotted keys. Each line starts with + for an added value,
+// - for a removed one and ~ for a changed one.
+func DiffHelmValues(oldValues map[string]interface{}, newValues map[string]interface{}) []string {
+	oldFlat := map[string]string{}
+	flattenValues("", oldValues, oldFlat)
+	newFlat := map[string]string{}
+	flattenValues("", newValues, newFlat)
+
+	changes := []string{}
+	for key, newValue := range newFlat {
+		oldValue, exists := oldFlat[key]
+		if !exists {
+			changes = append(changes, fmt.Sprintf("+ %s: %s", key, newValue))
+		} else if oldValue != newValue {
+			changes = append(changes, fmt.Sprintf("~ %s: %s -> %s", key, oldValue, newValue))
+		}
+	}
+	for key, oldValue := range oldFlat {
+		if _, exists := newFlat[key]; !exists {
+			changes = append(changes, fmt.Sprintf("- %s: %s", key, oldValue))
 		}
-		args = append(args, "list", "-aAq", "--no-headers", "-f", release)
-		out, err := utils.RunCmdOutput(zerolog.TraceLevel, "helm", args...)
-		return len(bytes.TrimSpace(out)) != 0 && err == nil
 	}
-	return false
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i][2:] < changes[j][2:]
+	})
+	return changes
 }
 
-type releaseInfo struct {
-	Namespace string `mapstructure:"namespace"`
+func flattenValues(prefix string, values map[string]interface{}, flat map[string]string) {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenValues(key, nested, flat)
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			encoded = []byte(fmt.Sprint(value))
+		}
+		flat[key] = string(encoded)
+	}
 }
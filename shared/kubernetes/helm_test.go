@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func TestDiffHelmValues(t *testing.T) {
+	oldValues := map[string]interface{}{
+		"fqdn":    "uyuni.example.com",
+		"images":  map[string]interface{}{"server": "server:2024.05"},
+		"ingress": "traefik",
+	}
+	newValues := map[string]interface{}{
+		"fqdn":       "uyuni.example.com",
+		"images":     map[string]interface{}{"server": "server:2024.07"},
+		"pullPolicy": "Always",
+	}
+
+	expected := []string{
+		`~ images.server: "server:2024.05" -> "server:2024.07"`,
+		`- ingress: "traefik"`,
+		`+ pullPolicy: "Always"`,
+	}
+	actual := DiffHelmValues(oldValues, newValues)
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected changes:\n%s", strings.Join(actual, "\n"))
+	}
+
+	if changes := DiffHelmValues(newValues, newValues); len(changes) != 0 {
+		t.Errorf("expected no change, got %v", changes)
+	}
+}
+
+// setFakeHelm makes the helm actions use an in-memory release storage and a fake cluster for the duration of the test.
+func setFakeHelm(t *testing.T) {
+	memory := driver.NewMemory()
+	previous := newHelmConfig
+	newHelmConfig = func(kubeconfig string, namespace string) (*action.Configuration, *cli.EnvSettings, error) {
+		memory.SetNamespace(namespace)
+		config := &action.Configuration{
+			Releases:     storage.Init(memory),
+			KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+			Capabilities: chartutil.DefaultCapabilities,
+			Log:          func(format string, v ...interface{}) {},
+		}
+		return config, helmSettings(kubeconfig, namespace), nil
+	}
+	t.Cleanup(func() {
+		newHelmConfig = previous
+	})
+}
+
+// writeChart writes a minimal helm chart and returns its path.
+func writeChart(t *testing.T) string {
+	chartDir := filepath.Join(t.TempDir(), "uyuni")
+	if err := os.MkdirAll(filepath.Join(chartDir, "templates"), 0700); err != nil {
+		t.Fatalf("failed to create chart directory: %s", err)
+	}
+	files := map[string]string{
+		"Chart.yaml":  "apiVersion: v2\nname: uyuni\nversion: 1.0.0\nappVersion: \"2024.07\"\n",
+		"values.yaml": "fqdn: uyuni.example.com\n",
+		"templates/configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: uyuni\n" +
+			"data:\n  fqdn: {{ .Values.fqdn }}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(chartDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %s", name, err)
+		}
+	}
+	return chartDir
+}
+
+func TestHelmRelease(t *testing.T) {
+	setFakeHelm(t)
+	chart := writeChart(t)
+
+	if HasHelmRelease("uyuni", "") {
+		t.Error("unexpected uyuni release before the installation")
+	}
+	if err := HelmUpgrade("", "uyuni", true, "", "uyuni", chart, "", "--set", "timezone=Europe/Berlin"); err != nil {
+		t.Fatalf("failed to install: %s", err)
+	}
+	if err := HelmUpgrade("", "uyuni", false, "", "uyuni", chart, "",
+		"--reuse-values", "--set", "fqdn=other.example.com"); err != nil {
+		t.Fatalf("failed to upgrade: %s", err)
+	}
+
+	if !HasHelmRelease("uyuni", "") {
+		t.Error("expected an uyuni release")
+	}
+	if namespace, err := FindNamespace("uyuni", ""); err != nil || namespace != "uyuni" {
+		t.Errorf("expected uyuni namespace, got %s: %v", namespace, err)
+	}
+
+	release, err := GetHelmRelease("", "uyuni", "uyuni")
+	if err != nil {
+		t.Fatalf("failed to get the release: %s", err)
+	}
+	if release.Revision != 2 || release.Status != "deployed" || release.Chart != "uyuni-1.0.0" ||
+		release.AppVersion != "2024.07" {
+		t.Errorf("unexpected release: %v", *release)
+	}
+	if _, err := GetHelmRelease("", "other", "uyuni"); err == nil {
+		t.Error("expected an error for a release in another namespace")
+	}
+
+	values, err := GetHelmValues("", "uyuni", "uyuni", 2)
+	if err != nil {
+		t.Fatalf("failed to get the values: %s", err)
+	}
+	expected := map[string]interface{}{"timezone": "Europe/Berlin", "fqdn": "other.example.com"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected values: %v", values)
+	}
+
+	if _, err := HelmUninstall("", "uyuni", "uyuni", "", false); err != nil {
+		t.Errorf("failed to uninstall: %s", err)
+	}
+	if HasHelmRelease("uyuni", "") {
+		t.Error("unexpected uyuni release after the uninstallation")
+	}
+}
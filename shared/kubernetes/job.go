@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+// NewJob creates the definition of a job running a single pod without retry.
+func NewJob(name string, namespace string, spec types.Spec) types.Job {
+	backoffLimit := 0
+	spec.RestartPolicy = "Never"
+	return types.Job{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Metadata: &types.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{"app": "uyuni-job"},
+		},
+		Spec: types.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: types.PodTemplate{
+				Metadata: &types.ObjectMeta{Labels: map[string]string{"app": "uyuni-job"}},
+				Spec:     &spec,
+			},
+		},
+	}
+}
+
+// RunJob creates a job, streams its logs and waits for its completion.
+//
+// A previous job with the same name is removed before, so an interrupted run can be started again.
+// The job is deleted if it succeeded and kept for inspection if it failed.
+func RunJob(job types.Job) error {
+	name := job.Metadata.Name
+	namespace := contextNamespace(job.Metadata.Namespace)
+
+	if err := DeleteJob(name, namespace); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return utils.Errorf(err, L("cannot serialize job definition: %s"))
+	}
+	var definition batchv1.Job
+	if err := json.Unmarshal(data, &definition); err != nil {
+		return utils.Errorf(err, L("cannot serialize job definition: %s"))
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	if _, err := client.BatchV1().Jobs(namespace).Create(context.Background(), &definition, metav1.CreateOptions{}); err != nil {
+		return utils.Errorf(err, L("cannot create job %[1]s: %[2]s"), name)
+	}
+
+	// Stream the logs until the container stops. This may fail if the pod never starts,
+	// the job status is checked anyway.
+	if err := streamJobLogs(client, name, namespace); err != nil {
+		log.Warn().Err(err).Msgf(L("Failed to get the logs of job %s"), name)
+	}
+
+	if err := waitForJob(name, namespace); err != nil {
+		return err
+	}
+	return DeleteJob(name, namespace)
+}
+
+// jobPodTimeout is the maximum time to wait for the pod of a job to start.
+const jobPodTimeout = 10 * time.Minute
+
+// streamJobLogs waits for the pod of a job to start and copies its logs to the standard output.
+func streamJobLogs(client k8s.Interface, name string, namespace string) error {
+	pods := client.CoreV1().Pods(namespace)
+	opts := metav1.ListOptions{LabelSelector: "job-name=" + name}
+	var podname string
+	err := waitUntil(jobPodTimeout, pods.Watch, opts, func(ctx context.Context) (bool, string, error) {
+		list, err := pods.List(ctx, opts)
+		if err != nil {
+			return false, "", err
+		}
+		for _, pod := range list.Items {
+			if pod.Status.Phase != corev1.PodPending {
+				podname = pod.Name
+				return true, "", nil
+			}
+		}
+		return false, list.ResourceVersion, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logs, err := pods.GetLogs(podname, &corev1.PodLogOptions{Follow: true}).Stream(context.Background())
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+	_, err = io.Copy(os.Stdout, logs)
+	return err
+}
+
+// DeleteJob removes a job and its pods if it exists.
+func DeleteJob(name string, namespace string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	jobs := client.BatchV1().Jobs(contextNamespace(namespace))
+	propagation := metav1.DeletePropagationForeground
+	err = jobs.Delete(context.Background(), name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return utils.Errorf(err, L("cannot delete job %[1]s: %[2]s"), name)
+	}
+
+	// The foreground deletion keeps the job until its pods are removed
+	opts := metav1.ListOptions{FieldSelector: "metadata.name=" + name}
+	err = waitUntil(jobTimeout, jobs.Watch, opts, func(ctx context.Context) (bool, string, error) {
+		list, err := jobs.List(ctx, opts)
+		if err != nil {
+			return false, "", utils.Errorf(err, L("cannot delete job %[1]s: %[2]s"), name)
+		}
+		return len(list.Items) == 0, list.ResourceVersion, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf(L("job %[1]s is not deleted after %[2]s"), name, jobTimeout)
+	}
+	return err
+}
+
+// jobTimeout is the maximum time to wait for a job to finish once its logs are streamed.
+const jobTimeout = 120 * time.Second
+
+func waitForJob(name string, namespace string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	jobs := client.BatchV1().Jobs(contextNamespace(namespace))
+	opts := metav1.ListOptions{FieldSelector: "metadata.name=" + name}
+	err = waitUntil(jobTimeout, jobs.Watch, opts, func(ctx context.Context) (bool, string, error) {
+		list, err := jobs.List(ctx, opts)
+		if err != nil {
+			return false, "", utils.Errorf(err, L("cannot get job %[1]s status: %[2]s"), name)
+		}
+		for _, job := range list.Items {
+			if job.Status.Succeeded > 0 {
+				log.Debug().Msgf("Job %s succeeded", name)
+				return true, "", nil
+			}
+			if job.Status.Failed > 0 {
+				return false, "", fmt.Errorf(L("job %[1]s failed, inspect it using kubectl describe job %[1]s"), name)
+			}
+		}
+		log.Debug().Msgf("Job %s is not finished yet", name)
+		return false, list.ResourceVersion, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf(L("job %[1]s is not finished after %[2]s"), name, jobTimeout)
+	}
+	return err
+}
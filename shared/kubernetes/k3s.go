@@ -5,17 +5,16 @@
 package kubernetes
 
 import (
-	"fmt"
+	"context"
 	"os"
-	"os/exec"
 	"path"
 	"time"
 
-	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const k3sTraefikConfigPath = "/var/lib/rancher/k3s/server/manifests/k3s-traefik-config.yaml"
@@ -34,16 +33,31 @@ func InstallK3sTraefikConfig(tcpPorts []types.PortMap, udpPorts []types.PortMap)
 
 	// Wait for traefik to be back
 	log.Info().Msg(L("Waiting for Traefik to be reloaded"))
-	for i := 0; i < 60; i++ {
-		out, err := utils.RunCmdOutput(zerolog.TraceLevel, "kubectl", "get", "job", "-A",
-			"-o", "jsonpath={.status.completionTime}", "helm-install-traefik")
-		if err == nil {
-			completionTime, err := time.Parse(time.RFC3339, string(out))
-			if err == nil && time.Since(completionTime).Seconds() < 60 {
-				break
+	if err := waitForK3sTraefik(); err != nil {
+		log.Warn().Err(err).Msg(L("Traefik is not reloaded yet"))
+	}
+}
+
+// waitForK3sTraefik waits for the job installing traefik to complete again.
+func waitForK3sTraefik() error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	jobs := client.BatchV1().Jobs(metav1.NamespaceAll)
+	opts := metav1.ListOptions{FieldSelector: "metadata.name=helm-install-traefik"}
+	return waitUntil(reloadTimeout, jobs.Watch, opts, func(ctx context.Context) (bool, string, error) {
+		list, err := jobs.List(ctx, opts)
+		if err != nil {
+			return false, "", err
+		}
+		for _, job := range list.Items {
+			if job.Status.CompletionTime != nil && time.Since(job.Status.CompletionTime.Time) < reloadTimeout {
+				return true, "", nil
 			}
 		}
-	}
+		return false, list.ResourceVersion, nil
+	})
 }
 
 // UninstallK3sTraefikConfig uninstall K3s Traefik configuration.
@@ -52,17 +66,11 @@ func UninstallK3sTraefikConfig(dryRun bool) {
 }
 
 // InspectKubernetes check values on a given image and deploy.
-func InspectKubernetes(serverImage string, pullPolicy string) (map[string]string, error) {
-	for _, binary := range []string{"kubectl", "helm"} {
-		if _, err := exec.LookPath(binary); err != nil {
-			return map[string]string{}, fmt.Errorf(L("install %s before running this command"), binary)
-		}
-	}
-
+func InspectKubernetes(namespace string, serverImage string, pullPolicy string) (map[string]string, error) {
 	scriptDir, err := os.MkdirTemp("", "mgradm-*")
 	defer os.RemoveAll(scriptDir)
 	if err != nil {
-		return map[string]string{}, fmt.Errorf(L("failed to create temporary directory: %s"), err)
+		return map[string]string{}, utils.Errorf(err, L("failed to create temporary directory: %s"))
 	}
 
 	if err := utils.GenerateInspectContainerScript(scriptDir); err != nil {
@@ -74,14 +82,14 @@ func InspectKubernetes(serverImage string, pullPolicy string) (map[string]string
 	const podName = "inspector"
 
 	//delete pending pod and then check the node, because in presence of more than a pod GetNode return is wrong
-	if err := DeletePod(podName, ServerFilter); err != nil {
-		return map[string]string{}, fmt.Errorf(L("cannot delete %s: %s"), podName, err)
+	if err := DeletePod(namespace, podName, ServerFilter); err != nil {
+		return map[string]string{}, utils.Errorf(err, L("cannot delete %s: %s"), podName)
 	}
 
 	//this is needed because folder with script needs to be mounted
-	nodeName, err := GetNode("uyuni")
+	nodeName, err := GetNode(namespace, ServerFilter)
 	if err != nil {
-		return map[string]string{}, fmt.Errorf(L("cannot find node running uyuni: %s"), err)
+		return map[string]string{}, utils.Errorf(err, L("cannot find node running uyuni: %s"))
 	}
 
 	//generate deploy data
@@ -107,14 +115,14 @@ func InspectKubernetes(serverImage string, pullPolicy string) (map[string]string
 	if err != nil {
 		return map[string]string{}, err
 	}
-	err = RunPod(podName, ServerFilter, serverImage, pullPolicy, command, override)
+	err = RunPod(namespace, podName, ServerFilter, serverImage, pullPolicy, command, override)
 	if err != nil {
-		return map[string]string{}, fmt.Errorf(L("cannot run inspect pod: %s"), err)
+		return map[string]string{}, utils.Errorf(err, L("cannot run inspect pod: %s"))
 	}
 
 	inspectResult, err := utils.ReadInspectData(scriptDir)
 	if err != nil {
-		return map[string]string{}, fmt.Errorf(L("cannot inspect data: %s"), err)
+		return map[string]string{}, utils.Errorf(err, L("cannot inspect data: %s"))
 	}
 
 	return inspectResult, err
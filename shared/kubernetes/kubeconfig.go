@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+const (
+	k3sKubeconfig  = "/etc/rancher/k3s/k3s.yaml"
+	rke2Kubeconfig = "/etc/rancher/rke2/rke2.yaml"
+)
+
+// ClusterFlags are the flags to select the kubernetes cluster to connect to.
+type ClusterFlags struct {
+	Kubeconfig string
+	Context    string
+}
+
+// clusterFlags are the flags selecting the cluster for every kubectl, client and helm call.
+var clusterFlags ClusterFlags
+
+// AddClusterFlags adds the flags selecting the kubernetes cluster to a root command.
+func AddClusterFlags(cmd *cobra.Command, flags *ClusterFlags) {
+	cmd.PersistentFlags().StringVar(&flags.Kubeconfig, "kubeconfig", "",
+		L("path to the kubeconfig file to use. Defaults to the kubectl one or the k3s or rke2 one if there is none"))
+	cmd.PersistentFlags().StringVar(&flags.Context, "kube-context", "",
+		L("name of the kubeconfig context to use. Defaults to the current context"))
+}
+
+// SetCluster configures the kubectl, client and helm calls to target the cluster defined in the flags.
+func SetCluster(flags *ClusterFlags) {
+	clusterFlags = *flags
+	if clusterFlags.Kubeconfig == "" {
+		clusterFlags.Kubeconfig = defaultKubeconfig()
+	}
+	if clusterFlags.Kubeconfig != "" {
+		log.Debug().Msgf("Using kubeconfig %s", clusterFlags.Kubeconfig)
+	}
+}
+
+// defaultKubeconfig returns the k3s or rke2 kubeconfig if kubectl has no configuration, an empty string otherwise.
+func defaultKubeconfig() string {
+	if os.Getenv("KUBECONFIG") != "" || utils.FileExists(os.ExpandEnv("${HOME}/.kube/config")) {
+		return ""
+	}
+	for _, kubeconfig := range []string{k3sKubeconfig, rke2Kubeconfig} {
+		if utils.FileExists(kubeconfig) {
+			return kubeconfig
+		}
+	}
+	return ""
+}
+
+// KubectlArgs adds the kubeconfig and context options to the kubectl arguments if needed.
+func KubectlArgs(args ...string) []string {
+	options := []string{}
+	if clusterFlags.Kubeconfig != "" {
+		options = append(options, "--kubeconfig", clusterFlags.Kubeconfig)
+	}
+	if clusterFlags.Context != "" {
+		options = append(options, "--context", clusterFlags.Context)
+	}
+	return append(options, args...)
+}
@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKubectlArgs(t *testing.T) {
+	oldFlags := clusterFlags
+	defer func() { clusterFlags = oldFlags }()
+
+	clusterFlags = ClusterFlags{}
+	if actual := strings.Join(KubectlArgs("get", "pod"), " "); actual != "get pod" {
+		t.Errorf("unexpected arguments without cluster flags: %s", actual)
+	}
+
+	clusterFlags = ClusterFlags{Kubeconfig: "/root/cluster.yaml", Context: "prod"}
+	expected := "--kubeconfig /root/cluster.yaml --context prod get pod"
+	if actual := strings.Join(KubectlArgs("get", "pod"), " "); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestHelmSettings(t *testing.T) {
+	oldFlags := clusterFlags
+	defer func() { clusterFlags = oldFlags }()
+
+	clusterFlags = ClusterFlags{}
+	settings := helmSettings(k3sKubeconfig, "uyuni")
+	if settings.KubeConfig != k3sKubeconfig || settings.KubeContext != "" {
+		t.Errorf("unexpected settings: %s, %s", settings.KubeConfig, settings.KubeContext)
+	}
+
+	clusterFlags = ClusterFlags{Kubeconfig: "/root/cluster.yaml", Context: "prod"}
+	settings = helmSettings(k3sKubeconfig, "uyuni")
+	if settings.KubeConfig != "/root/cluster.yaml" || settings.KubeContext != "prod" {
+		t.Errorf("unexpected settings with cluster flags: %s, %s", settings.KubeConfig, settings.KubeContext)
+	}
+}
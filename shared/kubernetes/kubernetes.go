@@ -5,15 +5,16 @@
 package kubernetes
 
 import (
-	"encoding/base64"
+	"context"
 	"fmt"
 	"os"
 	"strings"
 
-	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
 )
 
 // ClusterInfos represent cluster information.
@@ -33,31 +34,44 @@ func (infos ClusterInfos) IsRke2() bool {
 }
 
 // GetKubeconfig returns the path to the default kubeconfig file or "" if none.
+//
+// The value of the kubeconfig flag is returned if set.
 func (infos ClusterInfos) GetKubeconfig() string {
+	if clusterFlags.Kubeconfig != "" {
+		return clusterFlags.Kubeconfig
+	}
 	var kubeconfig string
 	if infos.IsK3s() {
 		// If the user didn't provide a KUBECONFIG value or file, use the k3s default
 		kubeconfigPath := os.ExpandEnv("${HOME}/.kube/config")
 		if os.Getenv("KUBECONFIG") == "" || !utils.FileExists(kubeconfigPath) {
-			kubeconfig = "/etc/rancher/k3s/k3s.yaml"
+			kubeconfig = k3sKubeconfig
 		}
 	}
-	// Since even kubectl doesn't work without a trick on rke2, we assume the user has set kubeconfig
+	// The rke2 kubeconfig is used by default when kubectl has no configuration, see SetCluster
 	return kubeconfig
 }
 
 // CheckCluster return cluster information.
 func CheckCluster() (*ClusterInfos, error) {
+	client, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+
 	// Get the kubelet version
-	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "get", "node",
-		"-o", "jsonpath={.items[0].status.nodeInfo.kubeletVersion}")
+	nodes, err := client.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, utils.WithCode(utils.CodeCluster, utils.Errorf(err, L("failed to get kubelet version: %s")))
+	}
+	kubeletVersion, err := kubeletVersion(nodes.Items)
 	if err != nil {
-		return nil, fmt.Errorf(L("failed to get kubelet version: %s"), err)
+		return nil, utils.WithCode(utils.CodeCluster, utils.Errorf(err, L("failed to get kubelet version: %s")))
 	}
 
 	var infos ClusterInfos
-	infos.KubeletVersion = string(out)
-	infos.Ingress, err = guessIngress()
+	infos.KubeletVersion = kubeletVersion
+	infos.Ingress, err = guessIngress(client)
 	if err != nil {
 		return nil, err
 	}
@@ -65,89 +79,120 @@ func CheckCluster() (*ClusterInfos, error) {
 	return &infos, nil
 }
 
-func guessIngress() (string, error) {
+func guessIngress(client k8s.Interface) (string, error) {
+	// Some API groups may fail to be discovered: the other ones are still returned
+	_, resources, err := client.Discovery().ServerGroupsAndResources()
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to discover some of the cluster resources")
+	}
+
 	// Check for a traefik resource
-	err := utils.RunCmd("kubectl", "explain", "ingressroutetcp")
-	if err == nil {
+	if hasResource(resources, "", "ingressroutetcps") {
 		return "traefik", nil
-	} else {
-		log.Debug().Err(err).Msg("No ingressroutetcp resource deployed")
 	}
+	log.Debug().Msg("No ingressroutetcp resource deployed")
+
+	// Look for the ingress classes, this covers the HAProxy and Istio ingress controllers
+	classes, err := client.NetworkingV1().IngressClasses().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to list the ingress classes")
+	} else if ingress := ingressFromClasses(classes.Items); ingress != "" {
+		return ingress, nil
+	}
+
+	// Look for an Istio gateway resource
+	if hasResource(resources, "networking.istio.io", "gateways") {
+		return "istio", nil
+	}
+	log.Debug().Msg("No istio gateway resource deployed")
 
 	// Look for a pod running the nginx-ingress-controller: there is no other common way to find out
-	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "get", "pod", "-A",
-		"-o", "jsonpath={range .items[*]}{.spec.containers[*].args[0]}{.spec.containers[*].command}{end}")
+	pods, err := client.CoreV1().Pods("").List(context.Background(), metav1.ListOptions{})
 	if err != nil {
-		return "", fmt.Errorf(L("failed to get pod commands to look for nginx controller: %s"), err)
+		return "", utils.Errorf(err, L("failed to get pod commands to look for nginx controller: %s"))
 	}
 
 	const nginxController = "/nginx-ingress-controller"
-	if strings.Contains(string(out), nginxController) {
-		return "nginx", nil
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			if (len(container.Args) > 0 && strings.Contains(container.Args[0], nginxController)) ||
+				strings.Contains(strings.Join(container.Command, " "), nginxController) {
+				return "nginx", nil
+			}
+		}
 	}
 
 	return "", nil
 }
 
 // Restart restarts the pod.
-func Restart(filter string) error {
-	if err := Stop(filter); err != nil {
-		return fmt.Errorf(L("cannot stop %s: %s"), filter, err)
+//
+// The namespace is detected from the deployments matching the filter if empty.
+func Restart(namespace string, filter string) error {
+	if err := Stop(namespace, filter); err != nil {
+		return utils.Errorf(err, L("cannot stop %s: %s"), filter)
 	}
-	return Start(filter)
+	return Start(namespace, filter)
 }
 
 // Start starts the pod.
-func Start(filter string) error {
+//
+// The namespace is detected from the deployments matching the filter if empty.
+func Start(namespace string, filter string) error {
+	namespace, err := GetNamespace(namespace, filter)
+	if err != nil {
+		return err
+	}
 	// if something is running, we don't need to set replicas to 1
-	if _, err := GetNode(filter); err != nil {
-		return ReplicasTo(filter, 1)
+	if _, err := GetNode(namespace, filter); err != nil {
+		return ReplicasTo(namespace, filter, 1)
 	}
 	log.Debug().Msgf("Already running")
 	return nil
 }
 
 // Stop stop the pod.
-func Stop(filter string) error {
-	return ReplicasTo(filter, 0)
-}
-
-func get(component string, componentName string, args ...string) ([]byte, error) {
-	kubectlArgs := []string{
-		"get",
-		component,
-		componentName,
-	}
-
-	kubectlArgs = append(kubectlArgs, args...)
-
-	output, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", kubectlArgs...)
+//
+// The namespace is detected from the deployments matching the filter if empty.
+func Stop(namespace string, filter string) error {
+	namespace, err := GetNamespace(namespace, filter)
 	if err != nil {
-		return []byte{}, err
+		return err
 	}
-	return output, nil
+	return ReplicasTo(namespace, filter, 0)
 }
 
-// GetConfigMap returns the value of a given config map.
-func GetConfigMap(configMapName string, filter string) (string, error) {
-	out, err := get("configMap", configMapName, filter)
+// GetConfigMap returns the value of a given config map key.
+func GetConfigMap(namespace string, configMapName string, key string) (string, error) {
+	client, err := newClient()
 	if err != nil {
-		return "", fmt.Errorf(L("failed to kubectl get configMap %s %s")+": %s", configMapName, filter, err)
+		return "", err
 	}
-
-	return string(out), nil
+	configMap, err := client.CoreV1().ConfigMaps(namespace).Get(context.Background(), configMapName, metav1.GetOptions{})
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to get configMap %[1]s: %[2]s"), configMapName)
+	}
+	value, ok := configMap.Data[key]
+	if !ok {
+		return "", fmt.Errorf(L("no %[1]s key in %[2]s"), key, configMapName)
+	}
+	return value, nil
 }
 
-// GetSecret returns the value of a given secret.
-func GetSecret(secretName string, filter string) (string, error) {
-	out, err := get("secret", secretName, filter)
+// GetSecret returns the decoded value of a given secret key.
+func GetSecret(namespace string, secretName string, key string) (string, error) {
+	client, err := newClient()
 	if err != nil {
-		return "", fmt.Errorf(L("failed to kubectl get secret %s %s")+": %s", secretName, filter, err)
+		return "", err
 	}
-	decoded, err := base64.StdEncoding.DecodeString(string(out))
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.Background(), secretName, metav1.GetOptions{})
 	if err != nil {
-		return "", fmt.Errorf(L("Failed to base64 decode configMap %s: %s"), secretName, err)
+		return "", utils.Errorf(err, L("failed to get secret %[1]s: %[2]s"), secretName)
 	}
-
-	return string(decoded), nil
+	// The client already decodes the base64 values
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf(L("no %[1]s key in %[2]s"), key, secretName)
+	}
+	return string(value), nil
 }
@@ -0,0 +1,234 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coordinationclient "k8s.io/client-go/kubernetes/typed/coordination/v1"
+)
+
+// LeaseName is the name of the Lease held by the commands changing the deployment.
+const LeaseName = "uyuni-tools-lock"
+
+// leaseHolderAnnotation stores the description of the command holding the lease.
+const leaseHolderAnnotation = "uyuni-tools/lock-holder"
+
+// leaseDuration is the time after which a lease which has not been renewed is considered released.
+//
+// This avoids a command killed while holding the lease to block the others forever.
+var leaseDuration = 60 * time.Second
+
+// leaseRenewInterval is the delay between two renewals of the held lease.
+var leaseRenewInterval = 20 * time.Second
+
+// leasePollInterval is the delay between two attempts to get the lease when waiting for it.
+var leasePollInterval = time.Second
+
+// AcquireLease takes the namespace Lease preventing several commands to change the deployment simultaneously.
+//
+// Unlike the host lock, this also protects from commands run from other machines against the same cluster.
+// If wait is false, an error describing the command holding the lease is returned if already taken.
+// The returned function releases the lease.
+func AcquireLease(namespace string, command string, wait bool) (func(), error) {
+	client, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+	leases := client.CoordinationV1().Leases(contextNamespace(namespace))
+
+	hostname, _ := os.Hostname()
+	identity := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	holder := utils.LockHolder{PID: os.Getpid(), User: utils.CurrentUser(), Command: command, Since: time.Now()}
+	description, err := json.Marshal(holder)
+	if err != nil {
+		return nil, err
+	}
+
+	waiting := false
+	for {
+		current, err := tryAcquireLease(leases, identity, string(description))
+		if err != nil {
+			return nil, err
+		}
+		if current == "" {
+			break
+		}
+		if !wait {
+			return nil, fmt.Errorf(L("another command is changing the deployment: %s. Use --wait to wait for it"), current)
+		}
+		if !waiting {
+			log.Info().Msgf(L("Waiting for another command to finish: %s"), current)
+			waiting = true
+		}
+		time.Sleep(leasePollInterval)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(leaseRenewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := updateHeldLease(leases, identity, false); err != nil {
+					log.Warn().Err(err).Msgf(L("Failed to renew the %s lease"), LeaseName)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+		if err := updateHeldLease(leases, identity, true); err != nil {
+			log.Warn().Err(err).Msgf(L("Failed to release the %s lease"), LeaseName)
+		}
+	}, nil
+}
+
+// tryAcquireLease takes the lease if it is free or expired.
+//
+// Returns the description of the command holding the lease or an empty string if acquired.
+func tryAcquireLease(leases coordinationclient.LeaseInterface, identity string, description string) (string, error) {
+	ctx := context.Background()
+	now := metav1.NewMicroTime(time.Now())
+	durationSeconds := int32(leaseDuration.Seconds())
+
+	lease, err := leases.Get(ctx, LeaseName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        LeaseName,
+				Annotations: map[string]string{leaseHolderAnnotation: description},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &identity,
+				LeaseDurationSeconds: &durationSeconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		if _, err := leases.Create(ctx, lease, metav1.CreateOptions{}); apierrors.IsAlreadyExists(err) {
+			// Another command created it in the meantime
+			return L("unknown command"), nil
+		} else if err != nil {
+			return "", utils.Errorf(err, L("failed to create the %[1]s lease: %[2]s"), LeaseName)
+		}
+		return "", nil
+	}
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to get the %[1]s lease: %[2]s"), LeaseName)
+	}
+
+	if isLeaseHeld(lease, now.Time) {
+		return leaseHolder(lease), nil
+	}
+
+	lease.Spec.HolderIdentity = &identity
+	lease.Spec.LeaseDurationSeconds = &durationSeconds
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+	if lease.Annotations == nil {
+		lease.Annotations = map[string]string{}
+	}
+	lease.Annotations[leaseHolderAnnotation] = description
+	// The update fails if another command changed the lease since we read it
+	if _, err := leases.Update(ctx, lease, metav1.UpdateOptions{}); apierrors.IsConflict(err) {
+		return L("unknown command"), nil
+	} else if err != nil {
+		return "", utils.Errorf(err, L("failed to update the %[1]s lease: %[2]s"), LeaseName)
+	}
+	return "", nil
+}
+
+// updateHeldLease renews or releases the lease if it is still held by the identity.
+func updateHeldLease(leases coordinationclient.LeaseInterface, identity string, release bool) error {
+	ctx := context.Background()
+	lease, err := leases.Get(ctx, LeaseName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != identity {
+		return fmt.Errorf(L("the %s lease has been taken by another command"), LeaseName)
+	}
+
+	if release {
+		lease.Spec.HolderIdentity = nil
+		lease.Spec.AcquireTime = nil
+		lease.Spec.RenewTime = nil
+		delete(lease.Annotations, leaseHolderAnnotation)
+	} else {
+		now := metav1.NewMicroTime(time.Now())
+		lease.Spec.RenewTime = &now
+	}
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+// isLeaseHeld returns whether the lease is held by a command and has been renewed recently enough.
+func isLeaseHeld(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" || lease.Spec.RenewTime == nil {
+		return false
+	}
+	duration := leaseDuration
+	if lease.Spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	return lease.Spec.RenewTime.Add(duration).After(now)
+}
+
+// leaseHolder returns the description of the command holding the lease.
+func leaseHolder(lease *coordinationv1.Lease) string {
+	var holder utils.LockHolder
+	if err := json.Unmarshal([]byte(lease.Annotations[leaseHolderAnnotation]), &holder); err != nil {
+		log.Debug().Err(err).Msgf("Failed to read the holder of the %s lease", LeaseName)
+		return *lease.Spec.HolderIdentity
+	}
+	return holder.String()
+}
+
+// WithLease wraps a command function to hold the Lease of the deployment namespace while running it.
+//
+// The namespace function returns the namespace from the flags. If empty, the namespace of the server
+// deployment is used, or the one of the kubeconfig context if there is no deployment yet.
+// The command needs the flag added by utils.AddLockFlag.
+func WithLease[T interface{}](namespace func(flags *T) string, fn utils.CommandFunc[T]) utils.CommandFunc[T] {
+	return func(globalFlags *types.GlobalFlags, flags *T, cmd *cobra.Command, args []string) error {
+		wait, _ := cmd.Flags().GetBool("wait")
+		command := strings.TrimSpace(cmd.CommandPath() + " " + strings.Join(args, " "))
+
+		leaseNamespace := namespace(flags)
+		if leaseNamespace == "" {
+			if detected, err := GetNamespace("", ServerFilter); err == nil {
+				leaseNamespace = detected
+			}
+		}
+
+		release, err := AcquireLease(leaseNamespace, command, wait)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return fn(globalFlags, flags, cmd, args)
+	}
+}
@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAcquireLease(t *testing.T) {
+	defer func(orig time.Duration) { leasePollInterval = orig }(leasePollInterval)
+	leasePollInterval = 10 * time.Millisecond
+	client := setFakeClient(t)
+
+	release, err := AcquireLease("uyuni", "mgradm upgrade kubernetes", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = AcquireLease("uyuni", "mgradm uninstall", false)
+	if err == nil || !strings.Contains(err.Error(), "mgradm upgrade kubernetes") {
+		t.Errorf("expected an error describing the lease holder, got %v", err)
+	}
+
+	// Another namespace has its own lease
+	otherRelease, err := AcquireLease("other", "mgradm uninstall", false)
+	if err != nil {
+		t.Fatalf("unexpected error for another namespace: %s", err)
+	}
+	otherRelease()
+
+	acquired := make(chan error)
+	go func() {
+		release, err := AcquireLease("uyuni", "mgradm uninstall", true)
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("expected to wait for the lease, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	if err := <-acquired; err != nil {
+		t.Errorf("unexpected error after waiting: %s", err)
+	}
+
+	lease, err := client.CoordinationV1().Leases("uyuni").Get(context.Background(), LeaseName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the lease: %s", err)
+	}
+	if lease.Spec.HolderIdentity != nil || lease.Annotations[leaseHolderAnnotation] != "" {
+		t.Errorf("lease not released: %v", lease.Spec)
+	}
+}
+
+func TestAcquireExpiredLease(t *testing.T) {
+	identity := "other-host-1234"
+	duration := int32(60)
+	renewed := metav1.NewMicroTime(time.Now().Add(-2 * time.Minute))
+	setFakeClient(t, &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: LeaseName, Namespace: "uyuni"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &identity,
+			LeaseDurationSeconds: &duration,
+			RenewTime:            &renewed,
+		},
+	})
+
+	release, err := AcquireLease("uyuni", "mgradm upgrade kubernetes", false)
+	if err != nil {
+		t.Fatalf("expected to take over an expired lease: %s", err)
+	}
+	release()
+}
+
+func TestIsLeaseHeld(t *testing.T) {
+	now := time.Now()
+	identity := "host-1234"
+	duration := int32(60)
+	renewed := metav1.NewMicroTime(now.Add(-30 * time.Second))
+	empty := ""
+
+	data := []struct {
+		spec     coordinationv1.LeaseSpec
+		now      time.Time
+		expected bool
+	}{
+		{coordinationv1.LeaseSpec{}, now, false},
+		{coordinationv1.LeaseSpec{HolderIdentity: &empty, RenewTime: &renewed}, now, false},
+		{coordinationv1.LeaseSpec{HolderIdentity: &identity, LeaseDurationSeconds: &duration, RenewTime: &renewed}, now, true},
+		{coordinationv1.LeaseSpec{HolderIdentity: &identity, LeaseDurationSeconds: &duration, RenewTime: &renewed},
+			now.Add(time.Minute), false},
+	}
+	for i, test := range data {
+		if actual := isLeaseHeld(&coordinationv1.Lease{Spec: test.spec}, test.now); actual != test.expected {
+			t.Errorf("case %d: expected %v, got %v", i, test.expected, actual)
+		}
+	}
+}
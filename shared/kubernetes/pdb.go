@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// newPodDisruptionBudget creates the definition of a PodDisruptionBudget preventing the eviction of the app pods.
+func newPodDisruptionBudget(namespace string, app string) *policyv1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt(0)
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      app,
+			Namespace: namespace,
+			Labels:    map[string]string{"app": app},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+		},
+	}
+}
+
+// EnsurePodDisruptionBudget creates or updates the PodDisruptionBudget of an app.
+//
+// Draining the node running the app pods is then blocked until the app is stopped by the tools,
+// rather than killing the pods in the middle of an operation.
+func EnsurePodDisruptionBudget(namespace string, app string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	namespace = contextNamespace(namespace)
+	budgets := client.PolicyV1().PodDisruptionBudgets(namespace)
+	pdb := newPodDisruptionBudget(namespace, app)
+
+	log.Debug().Msgf("Applying the %s PodDisruptionBudget in %s namespace", app, namespace)
+	existing, err := budgets.Get(context.Background(), app, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = budgets.Create(context.Background(), pdb, metav1.CreateOptions{})
+	} else if err == nil {
+		existing.Labels = pdb.Labels
+		existing.Spec = pdb.Spec
+		_, err = budgets.Update(context.Background(), existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return utils.Errorf(err, L("cannot create the PodDisruptionBudget of %[1]s: %[2]s"), app)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestEnsurePodDisruptionBudget(t *testing.T) {
+	maxUnavailable := intstr.FromInt(1)
+	client := setFakeClient(t, &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "uyuni", Namespace: "uyuni"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable},
+	})
+
+	for _, namespace := range []string{"uyuni", "other"} {
+		if err := EnsurePodDisruptionBudget(namespace, "uyuni"); err != nil {
+			t.Fatalf("unexpected error in %s namespace: %s", namespace, err)
+		}
+		pdb, err := client.PolicyV1().PodDisruptionBudgets(namespace).Get(context.Background(), "uyuni", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("PodDisruptionBudget not found in %s namespace: %s", namespace, err)
+		}
+		if pdb.Spec.MaxUnavailable == nil || pdb.Spec.MaxUnavailable.IntValue() != 0 {
+			t.Errorf("unexpected maxUnavailable in %s namespace: %v", namespace, pdb.Spec.MaxUnavailable)
+		}
+		if pdb.Spec.Selector == nil || pdb.Spec.Selector.MatchLabels["app"] != "uyuni" {
+			t.Errorf("unexpected selector in %s namespace: %v", namespace, pdb.Spec.Selector)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// kubeletVersion returns the kubelet version of the first node of the list.
+func kubeletVersion(nodes []corev1.Node) (string, error) {
+	if len(nodes) == 0 {
+		return "", errors.New(L("no node found in the cluster"))
+	}
+	return nodes[0].Status.NodeInfo.KubeletVersion, nil
+}
+
+// podNames returns the names of the pods.
+func podNames(pods []corev1.Pod) []string {
+	names := []string{}
+	for _, item := range pods {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+// podNodeNames returns the names of the nodes the pods are scheduled on.
+func podNodeNames(pods []corev1.Pod) []string {
+	names := []string{}
+	for _, item := range pods {
+		if item.Spec.NodeName != "" && !utils.Contains(names, item.Spec.NodeName) {
+			names = append(names, item.Spec.NodeName)
+		}
+	}
+	return names
+}
+
+// ingressControllers maps the ingress type to a substring of its ingress class controller.
+var ingressControllers = map[string]string{
+	"traefik": "traefik.io/",
+	"nginx":   "ingress-nginx",
+	"haproxy": "haproxy",
+	"istio":   "istio.io/",
+}
+
+// ingressFromClasses returns the ingress type matching the first known ingress class controller or an empty string.
+func ingressFromClasses(classes []networkingv1.IngressClass) string {
+	for _, item := range classes {
+		for ingress, controller := range ingressControllers {
+			if strings.Contains(item.Spec.Controller, controller) {
+				return ingress
+			}
+		}
+	}
+	return ""
+}
+
+// hasResource returns whether a resource is served by the cluster in any version of an API group.
+//
+// Any group matches if group is empty.
+func hasResource(resources []*metav1.APIResourceList, group string, resource string) bool {
+	for _, list := range resources {
+		listGroup := list.GroupVersion
+		if i := strings.Index(listGroup, "/"); i >= 0 {
+			listGroup = listGroup[:i]
+		}
+		if group != "" && listGroup != group {
+			continue
+		}
+		for _, apiResource := range list.APIResources {
+			if apiResource.Name == resource {
+				return true
+			}
+		}
+	}
+	return false
+}
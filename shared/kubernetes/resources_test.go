@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// setFakeClient makes newClient return a fake client with the given objects for the duration of the test.
+func setFakeClient(t *testing.T, objects ...runtime.Object) *fake.Clientset {
+	client := fake.NewSimpleClientset(objects...)
+	previous := newClient
+	newClient = func() (k8s.Interface, error) {
+		return client, nil
+	}
+	t.Cleanup(func() {
+		newClient = previous
+	})
+	return client
+}
+
+func newDeployment(namespace string, name string, app string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": app}},
+	}
+}
+
+func TestPodNames(t *testing.T) {
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "uyuni-1"}, Spec: corev1.PodSpec{NodeName: "node1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "uyuni-2"}, Spec: corev1.PodSpec{NodeName: "node1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "uyuni-3"}},
+	}
+	if actual := strings.Join(podNames(pods), ","); actual != "uyuni-1,uyuni-2,uyuni-3" {
+		t.Errorf("unexpected pod names: %s", actual)
+	}
+	if actual := strings.Join(podNodeNames(pods), ","); actual != "node1" {
+		t.Errorf("unexpected node names: %s", actual)
+	}
+}
+
+func TestKubeletVersion(t *testing.T) {
+	if _, err := kubeletVersion([]corev1.Node{}); err == nil {
+		t.Error("expected an error for an empty node list")
+	}
+
+	node := corev1.Node{}
+	node.Status.NodeInfo.KubeletVersion = "v1.28.9+k3s1"
+	version, err := kubeletVersion([]corev1.Node{node})
+	if err != nil || version != "v1.28.9+k3s1" {
+		t.Errorf("unexpected kubelet version %s: %v", version, err)
+	}
+}
+
+func TestIngressFromClasses(t *testing.T) {
+	data := map[string]string{
+		"traefik.io/ingress-controller":          "traefik",
+		"k8s.io/ingress-nginx":                   "nginx",
+		"haproxy.org/ingress-controller/haproxy": "haproxy",
+		"haproxy-ingress.github.io/controller":   "haproxy",
+		"istio.io/ingress-controller":            "istio",
+		"example.com/unknown":                    "",
+	}
+	for controller, expected := range data {
+		item := networkingv1.IngressClass{}
+		item.Spec.Controller = controller
+		if actual := ingressFromClasses([]networkingv1.IngressClass{item}); actual != expected {
+			t.Errorf("expected %s ingress for %s controller, got %s", expected, controller, actual)
+		}
+	}
+}
+
+func TestHasResource(t *testing.T) {
+	resources := []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods"}}},
+		{GroupVersion: "traefik.io/v1alpha1", APIResources: []metav1.APIResource{{Name: "ingressroutetcps"}}},
+	}
+	if !hasResource(resources, "", "ingressroutetcps") {
+		t.Error("expected ingressroutetcps to be found in any group")
+	}
+	if hasResource(resources, "traefik.containo.us", "ingressroutetcps") {
+		t.Error("unexpected ingressroutetcps found in traefik.containo.us group")
+	}
+	if hasResource(resources, "networking.istio.io", "gateways") {
+		t.Error("unexpected gateways found")
+	}
+}
+
+func TestGuessIngress(t *testing.T) {
+	client := setFakeClient(t, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "controller", Namespace: "ingress-nginx"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "controller", Args: []string{"/nginx-ingress-controller", "--election-id=ingress-nginx-leader"}},
+		}},
+	})
+	if ingress, err := guessIngress(client); err != nil || ingress != "nginx" {
+		t.Errorf("expected nginx ingress, got %s: %v", ingress, err)
+	}
+}
+
+func TestGetSecret(t *testing.T) {
+	setFakeClient(t, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "proxy-secret", Namespace: "uyuni"},
+		Data:       map[string][]byte{"ssh.yaml": []byte("foo: bar\n")},
+	})
+
+	if value, err := GetSecret("uyuni", "proxy-secret", "ssh.yaml"); err != nil || value != "foo: bar\n" {
+		t.Errorf("unexpected value %s: %v", value, err)
+	}
+	if _, err := GetSecret("uyuni", "proxy-secret", "missing"); err == nil {
+		t.Error("expected an error for a missing key")
+	}
+	if _, err := GetSecret("uyuni", "missing", "ssh.yaml"); err == nil {
+		t.Error("expected an error for a missing secret")
+	}
+}
+
+func TestGetNamespace(t *testing.T) {
+	setFakeClient(t, newDeployment("uyuni", "uyuni", "uyuni"), newDeployment("other", "uyuni", "uyuni"),
+		newDeployment("proxy", "uyuni-proxy", "uyuni-proxy"))
+
+	if namespace, err := GetNamespace("", ProxyFilter); err != nil || namespace != "proxy" {
+		t.Errorf("expected proxy namespace, got %s: %v", namespace, err)
+	}
+	if _, err := GetNamespace("", ServerFilter); err == nil {
+		t.Error("expected an error for deployments in several namespaces")
+	}
+	if _, err := GetNamespace("", SalineFilter); err == nil {
+		t.Error("expected an error if no deployment matches")
+	}
+	if namespace, err := GetNamespace("given", ServerFilter); err != nil || namespace != "given" {
+		t.Errorf("expected the given namespace, got %s: %v", namespace, err)
+	}
+}
+
+func TestReplicasTo(t *testing.T) {
+	client := setFakeClient(t, newDeployment("uyuni", "uyuni", "uyuni"))
+
+	// Simulate the deployment controller: the replicas get ready once the watch started
+	client.PrependWatchReactor("deployments", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		// The client is locked while reacting: only the tracker can be used
+		gvr := appsv1.SchemeGroupVersion.WithResource("deployments")
+		obj, err := client.Tracker().Get(gvr, "uyuni", "uyuni")
+		if err != nil {
+			return true, nil, err
+		}
+		deployment := obj.(*appsv1.Deployment)
+		deployment.Status.ReadyReplicas = *deployment.Spec.Replicas
+		if err := client.Tracker().Update(gvr, deployment, "uyuni"); err != nil {
+			return true, nil, err
+		}
+		watcher := watch.NewFakeWithChanSize(1, false)
+		watcher.Modify(deployment)
+		return true, watcher, nil
+	})
+
+	if err := ReplicasTo("uyuni", ServerFilter, 1); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	deployment, err := client.AppsV1().Deployments("uyuni").Get(context.Background(), "uyuni", metav1.GetOptions{})
+	if err != nil || deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 1 {
+		t.Errorf("deployment not scaled to 1 replica: %v", err)
+	}
+
+	// No pod is matching the filter
+	if err := ReplicasTo("uyuni", ServerFilter, 0); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if err := ReplicasTo("uyuni", ProxyFilter, 1); err == nil {
+		t.Error("expected an error if no deployment matches")
+	}
+}
+
+func TestRunPod(t *testing.T) {
+	client := setFakeClient(t)
+
+	// Complete the pod as soon as it is created and keep it for the checks
+	var created *corev1.Pod
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+		pod.Status.Phase = corev1.PodSucceeded
+		created = pod.DeepCopy()
+		return false, nil, nil
+	})
+
+	override := `{"apiVersion":"v1","spec":{"nodeName":"node1","containers":[{"name":"inspector",` +
+		`"volumeMounts":[{"mountPath":"/data","name":"data"}]}]}}`
+	err := RunPod("uyuni", "inspector", ServerFilter, "registry/server:latest", "Always", "/inspect.sh", override)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if created == nil {
+		t.Fatal("pod not created")
+	}
+	if created.Labels["app"] != "uyuni" || created.Spec.NodeName != "node1" {
+		t.Errorf("unexpected pod metadata or spec: %v", created)
+	}
+	if len(created.Spec.Containers) != 1 {
+		t.Fatalf("expected one container, got %d", len(created.Spec.Containers))
+	}
+	container := created.Spec.Containers[0]
+	if container.Image != "registry/server:latest" || len(container.VolumeMounts) != 1 ||
+		container.Command[0] != "/inspect.sh" {
+		t.Errorf("override not merged into the container: %v", container)
+	}
+}
@@ -5,13 +5,14 @@
 package kubernetes
 
 import (
-	"strconv"
+	"context"
+	"time"
 
-	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const rke2NginxConfigPath = "/var/lib/rancher/rke2/server/manifests/rke2-ingress-nginx-config.yaml"
@@ -31,15 +32,34 @@ func InstallRke2NginxConfig(tcpPorts []types.PortMap, udpPorts []types.PortMap,
 
 	// Wait for the nginx controller to be back
 	log.Info().Msg(L("Waiting for Nginx controller to be reloaded"))
-	for i := 0; i < 60; i++ {
-		out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "get", "daemonset", "-A",
-			"-o", "jsonpath={.status.numberReady}", "rke2-ingress-nginx-controller")
-		if err == nil {
-			if count, err := strconv.Atoi(string(out)); err == nil && count > 0 {
-				break
+	if err := waitForRke2NginxController(); err != nil {
+		log.Warn().Err(err).Msg(L("Nginx controller is not ready yet"))
+	}
+}
+
+// reloadTimeout is the maximum time to wait for the ingress controller to be reloaded.
+const reloadTimeout = 60 * time.Second
+
+// waitForRke2NginxController waits for the nginx controller daemon set to have a ready pod.
+func waitForRke2NginxController() error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	daemonSets := client.AppsV1().DaemonSets(metav1.NamespaceAll)
+	opts := metav1.ListOptions{FieldSelector: "metadata.name=rke2-ingress-nginx-controller"}
+	return waitUntil(reloadTimeout, daemonSets.Watch, opts, func(ctx context.Context) (bool, string, error) {
+		list, err := daemonSets.List(ctx, opts)
+		if err != nil {
+			return false, "", err
+		}
+		for _, daemonSet := range list.Items {
+			if daemonSet.Status.NumberReady > 0 {
+				return true, "", nil
 			}
 		}
-	}
+		return false, list.ResourceVersion, nil
+	})
 }
 
 // UninstallRke2NgixConfig uninstall Rke2 Nginx configuration.
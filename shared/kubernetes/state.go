@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StateConfigMap is the name of the ConfigMap recording the deployment state.
+const StateConfigMap = "uyuni-tools-state"
+
+const stateKey = "state.yaml"
+
+// ReadState reads the deployment state from its ConfigMap.
+//
+// Returns nil without error if no state has been recorded.
+func ReadState(namespace string) (*types.DeploymentState, error) {
+	client, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+	configMap, err := client.CoreV1().ConfigMaps(contextNamespace(namespace)).
+		Get(context.Background(), StateConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to read the %s ConfigMap: %s"), StateConfigMap)
+	}
+	data := configMap.Data[stateKey]
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return utils.ParseDeploymentState([]byte(data))
+}
+
+// WriteState stores the deployment state in a ConfigMap.
+func WriteState(namespace string, state *types.DeploymentState) error {
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return utils.Errorf(err, L("failed to serialize the deployment state: %s"))
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	configMaps := client.CoreV1().ConfigMaps(contextNamespace(namespace))
+
+	log.Debug().Msgf("Saving the deployment state in %s ConfigMap", StateConfigMap)
+	configMap, err := configMaps.Get(context.Background(), StateConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: StateConfigMap},
+			Data:       map[string]string{stateKey: string(data)},
+		}
+		_, err = configMaps.Create(context.Background(), configMap, metav1.CreateOptions{})
+	} else if err == nil {
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[stateKey] = string(data)
+		_, err = configMaps.Update(context.Background(), configMap, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return utils.Errorf(err, L("failed to save the %s ConfigMap: %s"), StateConfigMap)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func TestReadWriteState(t *testing.T) {
+	setFakeClient(t)
+
+	if state, err := ReadState("uyuni"); err != nil || state != nil {
+		t.Fatalf("expected no state, got %v: %v", state, err)
+	}
+
+	for _, image := range []string{"server:2024.07", "server:2024.10"} {
+		if err := WriteState("uyuni", &types.DeploymentState{Image: image}); err != nil {
+			t.Fatalf("unexpected error writing the state: %s", err)
+		}
+		state, err := ReadState("uyuni")
+		if err != nil || state == nil || state.Image != image {
+			t.Errorf("expected state with %s image, got %v: %v", image, state, err)
+		}
+	}
+}
@@ -5,18 +5,24 @@
 package kubernetes
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	k8s "k8s.io/client-go/kubernetes"
 )
 
 // ServerFilter represents filter used to check server app.
@@ -25,97 +31,125 @@ const ServerFilter = "-lapp=uyuni"
 // ServerFilter represents filter used to check proxy app.
 const ProxyFilter = "-lapp=uyuni-proxy"
 
+// ProxyComponentFilter returns the filter of the deployment of a single proxy component.
+func ProxyComponentFilter(component string) string {
+	return ProxyFilter + ",component=" + component
+}
+
+// SalineFilter represents filter used to check the saline deployment.
+const SalineFilter = "-lapp=uyuni-saline"
+
+// deploymentTimeout is the maximum time to wait for a deployment replica to be ready once its image is pulled.
+const deploymentTimeout = 60 * time.Second
+
 // waitForDeployment waits at most 60s for a kubernetes deployment to have at least one replica.
 // See [isDeploymentReady] for more details.
 func WaitForDeployment(namespace string, name string, appName string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
 	// Find the name of a replica pod
 	// Using the app label is a shortcut, not the 100% acurate way to get from deployment to pod
 	podName := ""
-	jsonpath := fmt.Sprintf("jsonpath={.items[?(@.metadata.labels.app==\"%s\")].metadata.name}", appName)
-	cmdArgs := []string{"get", "pod", "-o", jsonpath}
-	cmdArgs = addNamespace(cmdArgs, namespace)
-
-	for i := 0; i < 60; i++ {
-		out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", cmdArgs...)
-		if err == nil {
-			podName = string(out)
-			break
+	pods := client.CoreV1().Pods(namespace)
+	opts := metav1.ListOptions{LabelSelector: "app=" + appName}
+	err = waitUntil(deploymentTimeout, pods.Watch, opts, func(ctx context.Context) (bool, string, error) {
+		list, err := pods.List(ctx, opts)
+		if err != nil {
+			return false, "", err
+		}
+		if len(list.Items) > 0 {
+			podName = list.Items[0].Name
+			return true, "", nil
 		}
+		return false, list.ResourceVersion, nil
+	})
+	if err != nil {
+		return utils.Errorf(err, L("cannot find a pod for deployment %[1]s: %[2]s"), name)
 	}
 
 	// We need to wait for the image to be pulled as this can add quite some time
 	// Setting a timeout on this is very hard since it hightly depends on network speed and image size
 	// List the Pulled events from the pod as we may not see the Pulling if the image was already downloaded
-	err := WaitForPulledImage(namespace, podName)
-	if err != nil {
-		return fmt.Errorf(L("failed to pull image: %s"), err)
+	if err := WaitForPulledImage(namespace, podName); err != nil {
+		return utils.Errorf(err, L("failed to pull image: %s"))
 	}
 
 	log.Info().Msgf(L("Waiting for %s deployment to be ready in %s namespace\n"), name, namespace)
 	// Wait for a replica to be ready
-	for i := 0; i < 60; i++ {
+	deployments := client.AppsV1().Deployments(namespace)
+	opts = metav1.ListOptions{FieldSelector: "metadata.name=" + name}
+	err = waitUntil(deploymentTimeout, deployments.Watch, opts, func(ctx context.Context) (bool, string, error) {
 		// TODO Look for pod failures
-		if IsDeploymentReady(namespace, name) {
-			return nil
+		list, err := deployments.List(ctx, opts)
+		if err != nil {
+			return false, "", err
 		}
-		time.Sleep(1 * time.Second)
+		return hasReadyReplica(list.Items), list.ResourceVersion, nil
+	})
+	if err != nil {
+		return fmt.Errorf(L("failed to find a ready replica for deployment %s in namespace %s after 60s"), name, namespace)
 	}
-	return fmt.Errorf(L("failed to find a ready replica for deployment %s in namespace %s after 60s"), name, namespace)
+	return nil
 }
 
 // WaitForPulledImage wait that image is pulled.
 func WaitForPulledImage(namespace string, podName string) error {
 	log.Info().Msgf(L("Waiting for image of %s pod in %s namespace to be pulled"), podName, namespace)
-	pulledArgs := []string{"get", "event",
-		"-o", "jsonpath={.items[?(@.reason==\"Pulled\")].message}",
-		"--field-selector", "involvedObject.name=" + podName}
-	pulledArgs = addNamespace(pulledArgs, namespace)
-	failedArgs := []string{"get", "event",
-		"-o", "jsonpath={range .items[?(@.reason==\"Failed\")]}{.message}{\"\\n\"}{end}",
-		"--field-selector", "involvedObject.name=" + podName}
-	failedArgs = addNamespace(failedArgs, namespace)
-	for {
-		// Look for events indicating an image pull issue
-		out, err := utils.RunCmdOutput(zerolog.TraceLevel, "kubectl", failedArgs...)
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	events := client.CoreV1().Events(namespace)
+	opts := metav1.ListOptions{FieldSelector: "involvedObject.name=" + podName}
+	// There is no timeout as pulling the image highly depends on the network speed and image size
+	return waitUntil(0, events.Watch, opts, func(ctx context.Context) (bool, string, error) {
+		list, err := events.List(ctx, opts)
 		if err != nil {
-			return fmt.Errorf(L("failed to get failed events for pod %s"), podName)
+			return false, "", utils.Errorf(err, L("failed to get events for pod %[1]s: %[2]s"), podName)
 		}
-		lines := strings.Split(string(out), "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(line, "Failed to pull image") {
-				return errors.New(L("failed to pull image"))
+		for _, event := range list.Items {
+			// Look for events indicating an image pull issue
+			if event.Reason == "Failed" && strings.HasPrefix(event.Message, "Failed to pull image") {
+				return false, "", errors.New(L("failed to pull image"))
 			}
 		}
-
-		// Has the image pull finished?
-		out, err = utils.RunCmdOutput(zerolog.TraceLevel, "kubectl", pulledArgs...)
-		if err != nil {
-			return fmt.Errorf(L("failed to get events for pod %s"), podName)
+		for _, event := range list.Items {
+			// Has the image pull finished?
+			if event.Reason == "Pulled" {
+				return true, "", nil
+			}
 		}
-		if len(out) > 0 {
-			break
+		return false, list.ResourceVersion, nil
+	})
+}
+
+// hasReadyReplica returns whether one of the deployments has at least one ready replica.
+func hasReadyReplica(deployments []appsv1.Deployment) bool {
+	for _, deployment := range deployments {
+		if deployment.Status.ReadyReplicas > 0 {
+			return true
 		}
-		time.Sleep(1 * time.Second)
 	}
-	return nil
+	return false
 }
 
 // IsDeploymentReady returns true if a kubernetes deployment has at least one ready replica.
-// The name can also be a filter parameter like -lapp=uyuni.
 // An empty namespace means searching through all the namespaces.
 func IsDeploymentReady(namespace string, name string) bool {
-	jsonpath := fmt.Sprintf("jsonpath={.items[?(@.metadata.name==\"%s\")].status.readyReplicas}", name)
-	args := []string{"get", "-o", jsonpath, "deploy"}
-	args = addNamespace(args, namespace)
-
-	out, err := utils.RunCmdOutput(zerolog.TraceLevel, "kubectl", args...)
-	// kubectl errors out if the deployment or namespace doesn't exist
-	if err == nil {
-		if replicas, _ := strconv.Atoi(string(out)); replicas > 0 {
-			return true
-		}
+	client, err := newClient()
+	if err != nil {
+		return false
 	}
-	return false
+	deployments, err := client.AppsV1().Deployments(namespace).List(context.Background(),
+		metav1.ListOptions{FieldSelector: "metadata.name=" + name})
+	// The list fails if the namespace doesn't exist
+	if err != nil {
+		return false
+	}
+	return hasReadyReplica(deployments.Items)
 }
 
 // DeploymentStatus represents the kubernetes deployment status.
@@ -128,130 +162,168 @@ type DeploymentStatus struct {
 
 // GetDeploymentStatus returns the replicas status of the deployment.
 func GetDeploymentStatus(namespace string, name string) (*DeploymentStatus, error) {
-	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "get", "deploy", "-n", namespace,
-		name, "-o", "jsonpath={.status}")
+	client, err := newClient()
 	if err != nil {
 		return nil, err
 	}
-
-	var status DeploymentStatus
-	if err = json.Unmarshal(out, &status); err != nil {
-		return nil, fmt.Errorf(L("failed to parse deployment status: %s"), err)
+	deployment, err := client.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get deployment %[1]s status: %[2]s"), name)
 	}
-	return &status, nil
+
+	return &DeploymentStatus{
+		AvailableReplicas: int(deployment.Status.AvailableReplicas),
+		ReadyReplicas:     int(deployment.Status.ReadyReplicas),
+		UpdatedReplicas:   int(deployment.Status.UpdatedReplicas),
+		Replicas:          int(deployment.Status.Replicas),
+	}, nil
 }
 
 // ReplicasTo set the replica for an app to the given value.
-// Scale the number of replicas of the server.
-func ReplicasTo(filter string, replica uint) error {
-	args := []string{"scale", "deploy", filter, "--replicas"}
+// Scale the number of replicas of the server and wait for the pods to be ready or deleted.
+func ReplicasTo(namespace string, filter string, replica uint) error {
 	log.Debug().Msgf("Setting replicas for pod in %s to %d", filter, replica)
-	args = append(args, fmt.Sprint(replica))
-
-	_, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", args...)
+	client, err := newClient()
 	if err != nil {
-		return fmt.Errorf(L("cannot run kubectl %s: %s"), args, err)
+		return err
 	}
 
-	pods, err := getPods(filter)
+	namespace = contextNamespace(namespace)
+	deploymentsClient := client.AppsV1().Deployments(namespace)
+	deployments, err := deploymentsClient.List(context.Background(), listOptions(filter))
 	if err != nil {
-		return fmt.Errorf(L("cannot get pods for %s: %s"), filter, err)
+		return utils.Errorf(err, L("cannot get deployments matching %[1]s: %[2]s"), filter)
+	}
+	if len(deployments.Items) == 0 {
+		return fmt.Errorf(L("no deployment matching %s found"), filter)
 	}
 
-	for _, pod := range pods {
-		if len(pod) > 0 {
-			err = waitForReplica(pod, replica)
-			if err != nil {
-				return fmt.Errorf(L("replica to %d failed: %s"), replica, err)
-			}
+	patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replica))
+	for _, deployment := range deployments.Items {
+		_, err := deploymentsClient.Patch(context.Background(), deployment.Name, k8stypes.MergePatchType, patch,
+			metav1.PatchOptions{})
+		if err != nil {
+			return utils.Errorf(err, L("cannot scale deployment %[1]s: %[2]s"), deployment.Name)
 		}
 	}
 
+	if err := waitForReplicas(client, namespace, filter, replica); err != nil {
+		return utils.Errorf(err, L("replica to %d failed: %s"), replica)
+	}
+
 	log.Debug().Msgf("Replicas for pod in %s are now %d", filter, replica)
+	return nil
+}
 
-	return err
+// replicasTimeout is the maximum time to wait for the replicas to be scaled.
+const replicasTimeout = 120 * time.Second
+
+// waitForReplicas watches the deployments or pods matching the filter until the expected replicas are ready.
+func waitForReplicas(client k8s.Interface, namespace string, filter string, replica uint) error {
+	log.Debug().Msgf("Waiting for %s replicas to be %d", filter, replica)
+	opts := listOptions(filter)
+	var err error
+	if replica == 0 {
+		// Wait for all the pods matching the filter to be deleted
+		pods := client.CoreV1().Pods(namespace)
+		err = waitUntil(replicasTimeout, pods.Watch, opts, func(ctx context.Context) (bool, string, error) {
+			list, err := pods.List(ctx, opts)
+			if err != nil {
+				return false, "", err
+			}
+			return len(list.Items) == 0, list.ResourceVersion, nil
+		})
+	} else {
+		deployments := client.AppsV1().Deployments(namespace)
+		err = waitUntil(replicasTimeout, deployments.Watch, opts, func(ctx context.Context) (bool, string, error) {
+			list, err := deployments.List(ctx, opts)
+			if err != nil {
+				return false, "", err
+			}
+			if len(list.Items) == 0 {
+				return false, "", fmt.Errorf(L("no deployment matching %s found"), filter)
+			}
+			for _, deployment := range list.Items {
+				if deployment.Status.ReadyReplicas != int32(replica) {
+					return false, list.ResourceVersion, nil
+				}
+			}
+			return true, "", nil
+		})
+	}
+	if err != nil {
+		return utils.Errorf(err, L("pods matching %[1]s are not %[2]d after %[3]s: %[4]s"), filter, replica, replicasTimeout)
+	}
+	return nil
 }
 
-func isPodRunning(podname string, filter string) (bool, error) {
-	pods, err := getPods(filter)
+func isPodRunning(namespace string, podname string, filter string) (bool, error) {
+	pods, err := getPods(namespace, filter)
 	if err != nil {
-		return false, fmt.Errorf(L("cannot check if pod %s is running in app %s: %s"), podname, filter, err)
+		return false, utils.Errorf(err, L("cannot check if pod %s is running in app %s: %s"), podname, filter)
 	}
-	return utils.Contains(pods, podname), nil
+	return utils.Contains(podNames(pods), podname), nil
 }
 
-func getPods(filter string) (pods []string, err error) {
+func getPods(namespace string, filter string) ([]corev1.Pod, error) {
 	log.Debug().Msgf("Checking all pods for %s", filter)
-	cmdArgs := []string{"get", "pods", filter, "--output=custom-columns=:.metadata.name", "--no-headers"}
-	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", cmdArgs...)
+	client, err := newClient()
 	if err != nil {
-		return pods, fmt.Errorf(L("cannot execute %s: %s"), strings.Join(cmdArgs, string(" ")), err)
+		return nil, err
 	}
-	lines := strings.Split(string(out), "\n")
-	pods = append(pods, lines...)
-	log.Debug().Msgf("Pods in %s are %s", filter, pods)
-
-	return pods, err
+	pods, err := client.CoreV1().Pods(contextNamespace(namespace)).List(context.Background(), listOptions(filter))
+	if err != nil {
+		return nil, utils.Errorf(err, L("cannot get pods matching %[1]s: %[2]s"), filter)
+	}
+	log.Debug().Msgf("Pods in %s are %s", filter, podNames(pods.Items))
+	return pods.Items, nil
 }
 
-func waitForReplicaZero(podname string) error {
-	waitSeconds := 120
-	cmdArgs := []string{"get", "pod", podname}
-
-	for i := 0; i < waitSeconds; i++ {
-		out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", cmdArgs...)
-		/* Assume that if the command return an error at the first iteration, it's because it failed,
-		* next iteration because the pod was actually deleted
-		 */
-		if err != nil && i == 0 {
-			return fmt.Errorf(L("cannot get pod informations %s: %s"), podname, err)
-		}
-		outStr := strings.TrimSuffix(string(out), "\n")
-		if len(outStr) == 0 {
-			log.Debug().Msgf("Pod %s has been deleted", podname)
-			return nil
-		}
-		time.Sleep(1 * time.Second)
+// GetRunningImage returns the image of the first container of the pods matching the filter.
+func GetRunningImage(namespace string, filter string) (string, error) {
+	pods, err := getPods(namespace, filter)
+	if err != nil {
+		return "", utils.Errorf(err, L("cannot find any running image for pods matching %[1]s: %[2]s"), filter)
+	}
+	if len(pods) == 0 || len(pods[0].Spec.Containers) == 0 {
+		return "", fmt.Errorf(L("cannot find any running image for pods matching %s"), filter)
 	}
-	return fmt.Errorf(L("cannot set replicas for %s to zero"), podname)
+	return pods[0].Spec.Containers[0].Image, nil
 }
 
-func waitForReplica(podname string, replica uint) error {
-	waitSeconds := 120
-	log.Debug().Msgf("Checking replica for %s ready to %d", podname, replica)
-	if replica == 0 {
-		return waitForReplicaZero(podname)
+// GetNamespace returns the namespace of the deployments matching the filter if the namespace is empty.
+//
+// An error is returned if no namespace or several ones contain matching deployments: this happens when several
+// servers are running in the cluster and the namespace has to be given explicitly.
+func GetNamespace(namespace string, filter string) (string, error) {
+	if namespace != "" {
+		return namespace, nil
 	}
-	cmdArgs := []string{"get", "pod", podname, "--output=custom-columns=STATUS:.status.phase", "--no-headers"}
 
-	var err error
-
-	for i := 0; i < waitSeconds; i++ {
-		out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", cmdArgs...)
-		outStr := strings.TrimSuffix(string(out), "\n")
-		if err != nil {
-			return fmt.Errorf(L("cannot execute %s: %s"), strings.Join(cmdArgs, string(" ")), err)
-		}
-		if string(outStr) == "Running" {
-			log.Debug().Msgf("%s pod replica is now %d", podname, replica)
-			break
-		}
-		log.Debug().Msgf("Pod %s replica is %s in %d seconds.", podname, string(out), i)
-		time.Sleep(1 * time.Second)
+	client, err := newClient()
+	if err != nil {
+		return "", err
 	}
+	deployments, err := client.AppsV1().Deployments("").List(context.Background(), listOptions(filter))
 	if err != nil {
-		return fmt.Errorf(L("pod %s replica is not %d in %s seconds: %s"), podname, replica, strconv.Itoa(waitSeconds), err)
+		return "", utils.Errorf(err, L("cannot find the namespace of deployments matching %[1]s: %[2]s"), filter)
 	}
-	return nil
-}
 
-func addNamespace(args []string, namespace string) []string {
-	if namespace != "" {
-		args = append(args, "-n", namespace)
-	} else {
-		args = append(args, "-A")
+	namespaces := []string{}
+	for _, deployment := range deployments.Items {
+		if !utils.Contains(namespaces, deployment.Namespace) {
+			namespaces = append(namespaces, deployment.Namespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		return "", fmt.Errorf(L("no deployment matching %s found"), filter)
 	}
-	return args
+	if len(namespaces) > 1 {
+		return "", fmt.Errorf(L("deployments matching %[1]s found in several namespaces: %[2]s. Use the namespace flag to select one"),
+			filter, strings.Join(namespaces, ", "))
+	}
+	log.Debug().Msgf("Deployments matching %s found in %s namespace", filter, namespaces[0])
+	return namespaces[0], nil
 }
 
 // GetPullPolicy return pullpolicy in lower case, if exists.
@@ -269,92 +341,126 @@ func GetPullPolicy(name string) string {
 }
 
 // RunPod runs a pod, waiting for its execution and deleting it.
-func RunPod(podname string, filter string, image string, pullPolicy string, command string, override ...string) error {
-	arguments := []string{"run", podname, "--image", image, "--image-pull-policy", pullPolicy, filter}
-
+//
+// The pod gets the labels of the filter. The overrides are JSON pod definitions merged into the pod
+// using a strategic merge patch, like the kubectl run overrides.
+func RunPod(namespace string, podname string, filter string, image string, pullPolicy string, command string, override ...string) error {
+	namespace = contextNamespace(namespace)
+	podLabels, err := labels.ConvertSelectorToLabelsMap(labelSelector(filter))
+	if err != nil {
+		return utils.Errorf(err, L("invalid filter %[1]s: %[2]s"), filter)
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: podname, Namespace: namespace, Labels: podLabels},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:            podname,
+				Image:           image,
+				ImagePullPolicy: corev1.PullPolicy(GetPullPolicy(pullPolicy)),
+				Command:         []string{command},
+			}},
+		},
+	}
 	if len(override) > 0 {
-		arguments = append(arguments, `--override-type=strategic`)
-		for _, arg := range override {
-			overrideParam := "--overrides=" + arg
-			arguments = append(arguments, overrideParam)
+		data, err := json.Marshal(pod)
+		if err != nil {
+			return utils.Errorf(err, L("cannot serialize pod definition: %s"))
+		}
+		for _, patch := range override {
+			if data, err = strategicpatch.StrategicMergePatch(data, []byte(patch), corev1.Pod{}); err != nil {
+				return utils.Errorf(err, L("cannot apply pod definition override: %s"))
+			}
+		}
+		pod = corev1.Pod{}
+		if err := json.Unmarshal(data, &pod); err != nil {
+			return utils.Errorf(err, L("cannot apply pod definition override: %s"))
 		}
 	}
 
-	arguments = append(arguments, "--command", "--", command)
-	err := utils.RunCmdStdMapping(zerolog.DebugLevel, "kubectl", arguments...)
+	client, err := newClient()
 	if err != nil {
-		return fmt.Errorf(L("cannot run %s using image %s: %s"), command, image, err)
+		return err
 	}
-	err = waitForPod(podname)
-	if err != nil {
-		return fmt.Errorf(L("deleting pod %s. Status fails with error %s"), podname, err)
+	if _, err := client.CoreV1().Pods(namespace).Create(context.Background(), &pod, metav1.CreateOptions{}); err != nil {
+		return utils.Errorf(err, L("cannot run %s using image %s: %s"), command, image)
 	}
-
-	defer func() {
-		err = DeletePod(podname, filter)
-	}()
-	return nil
+	if err := waitForPod(namespace, podname); err != nil {
+		return utils.Errorf(err, L("deleting pod %s. Status fails with error %s"), podname)
+	}
+	return DeletePod(namespace, podname, filter)
 }
 
 // Delete a kubernetes pod named podname.
-func DeletePod(podname string, filter string) error {
-	isRunning, err := isPodRunning(podname, filter)
+func DeletePod(namespace string, podname string, filter string) error {
+	isRunning, err := isPodRunning(namespace, podname, filter)
 	if err != nil {
-		return fmt.Errorf(L("cannot delete pod %s: %s"), podname, err)
+		return utils.Errorf(err, L("cannot delete pod %s: %s"), podname)
 	}
 	if !isRunning {
 		log.Debug().Msgf("no need to delete pod %s because is not running", podname)
 		return nil
 	}
-	arguments := []string{"delete", "pod", podname}
-	_, err = utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", arguments...)
+	client, err := newClient()
 	if err != nil {
-		return fmt.Errorf(L("cannot delete pod %s: %s"), podname, err)
+		return err
+	}
+	namespace = contextNamespace(namespace)
+	if err := client.CoreV1().Pods(namespace).Delete(context.Background(), podname, metav1.DeleteOptions{}); err != nil {
+		return utils.Errorf(err, L("cannot delete pod %s: %s"), podname)
 	}
 	return nil
 }
 
-func waitForPod(podname string) error {
-	status := "Succeeded"
-	waitSeconds := 120
-	log.Debug().Msgf("Checking status for %s pod. Waiting %s seconds until status is %s", podname, strconv.Itoa(waitSeconds), status)
-	cmdArgs := []string{"get", "pod", podname, "--output=custom-columns=STATUS:.status.phase", "--no-headers"}
-	var err error
-	for i := 0; i < waitSeconds; i++ {
-		out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", cmdArgs...)
-		outStr := strings.TrimSuffix(string(out), "\n")
+// podTimeout is the maximum time to wait for a pod to complete.
+const podTimeout = 120 * time.Second
+
+func waitForPod(namespace string, podname string) error {
+	status := corev1.PodSucceeded
+	log.Debug().Msgf("Checking status for %s pod. Waiting %s until status is %s", podname, podTimeout, status)
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	pods := client.CoreV1().Pods(contextNamespace(namespace))
+	opts := metav1.ListOptions{FieldSelector: "metadata.name=" + podname}
+	err = waitUntil(podTimeout, pods.Watch, opts, func(ctx context.Context) (bool, string, error) {
+		list, err := pods.List(ctx, opts)
 		if err != nil {
-			return fmt.Errorf(L("cannot execute %s: %s"), strings.Join(cmdArgs, string(" ")), err)
+			return false, "", utils.Errorf(err, L("cannot get pod %[1]s status: %[2]s"), podname)
+		}
+		if len(list.Items) == 0 {
+			return false, list.ResourceVersion, nil
 		}
-		if strings.EqualFold(outStr, status) {
+		phase := list.Items[0].Status.Phase
+		if phase == status {
 			log.Debug().Msgf("%s pod status is %s", podname, status)
-			return nil
+			return true, "", nil
 		}
-		if strings.EqualFold(outStr, "Failed") {
-			return fmt.Errorf(L("error during execution of %s: %s"), strings.Join(cmdArgs, string(" ")), err)
+		if phase == corev1.PodFailed {
+			return false, "", fmt.Errorf(L("pod %s failed"), podname)
 		}
-		log.Debug().Msgf("Pod %s status is %s for %d seconds.", podname, outStr, i)
-		time.Sleep(1 * time.Second)
+		log.Debug().Msgf("Pod %s status is %s", podname, phase)
+		return false, list.ResourceVersion, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf(L("pod %[1]s status is not %[2]s after %[3]s"), podname, status, podTimeout)
 	}
-	return fmt.Errorf(L("pod %s status is not %s in %s seconds: %s"), podname, status, strconv.Itoa(waitSeconds), err)
+	return err
 }
 
 // GetNode return the node where the app is running.
-func GetNode(filter string) (string, error) {
-	nodeName := ""
-	cmdArgs := []string{"get", "pod", filter, "-o", "jsonpath={.items[*].spec.nodeName}"}
-	for i := 0; i < 60; i++ {
-		out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", cmdArgs...)
-		if err == nil {
-			nodeName = string(out)
-			break
-		}
+func GetNode(namespace string, filter string) (string, error) {
+	pods, err := getPods(namespace, filter)
+	if err != nil {
+		return "", utils.Errorf(err, L("cannot find node name matching filter %[1]s: %[2]s"), filter)
 	}
-	if len(nodeName) > 0 {
-		log.Debug().Msgf("Node name matching filter %s is: %s", filter, nodeName)
-	} else {
+	nodeNames := podNodeNames(pods)
+	if len(nodeNames) == 0 {
 		return "", fmt.Errorf(L("cannot find node name matching filter %s"), filter)
 	}
+	nodeName := strings.Join(nodeNames, " ")
+	log.Debug().Msgf("Node name matching filter %s is: %s", filter, nodeName)
 	return nodeName, nil
 }
 
@@ -362,7 +468,7 @@ func GetNode(filter string) (string, error) {
 func GenerateOverrideDeployment(deployData types.Deployment) (string, error) {
 	ret, err := json.Marshal(deployData)
 	if err != nil {
-		return "", fmt.Errorf(L("cannot serialize pod definition override: %s"), err)
+		return "", utils.Errorf(err, L("cannot serialize pod definition override: %s"))
 	}
 	return string(ret), nil
 }
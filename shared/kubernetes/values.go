@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"fmt"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+// HelmValues computes the values a helm command would get from its --set, --set-string, --set-json
+// and -f parameters.
+//
+// The other parameters are ignored. The values are merged by helm itself: the files are read first,
+// then the --set-json, --set and --set-string values override them.
+func HelmValues(args []string) (map[string]interface{}, error) {
+	var options values.Options
+	for i := 0; i < len(args); i++ {
+		option := args[i]
+		if !utils.Contains([]string{"--set", "--set-string", "--set-json", "-f", "--values"}, option) {
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil, fmt.Errorf(L("missing value for %s helm parameter"), option)
+		}
+		i++
+		switch option {
+		case "--set":
+			options.Values = append(options.Values, args[i])
+		case "--set-string":
+			options.StringValues = append(options.StringValues, args[i])
+		case "--set-json":
+			options.JSONValues = append(options.JSONValues, args[i])
+		default:
+			options.ValueFiles = append(options.ValueFiles, args[i])
+		}
+	}
+
+	merged, err := options.MergeValues(getter.All(cli.New()))
+	if err != nil {
+		return nil, utils.Errorf(err, L("invalid helm values: %s"))
+	}
+	return merged, nil
+}
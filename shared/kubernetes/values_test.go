@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHelmValues(t *testing.T) {
+	valuesPath := filepath.Join(t.TempDir(), "values.yaml")
+	content := "fqdn: ignored.example.com\nimages:\n  server: old\n  db: postgres\n"
+	if err := os.WriteFile(valuesPath, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write values file: %s", err)
+	}
+
+	args := []string{
+		"--set", "ingress=traefik",
+		"-f", valuesPath,
+		"--set", "images.server=server:latest",
+		"--set", "fqdn=uyuni.example.com",
+		"--set", "exposeJavaDebug=true",
+		"--set", "replicas=2",
+		"--set", "timezone=0100",
+		"--set-string", "resources.requests.cpu=2",
+		"--set-json", `ingressAnnotations={"cert-manager.io/issuer": "uyuni-ca-issuer"}`,
+		"--atomic",
+	}
+	values, err := HelmValues(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"ingress":         "traefik",
+		"fqdn":            "uyuni.example.com",
+		"images":          map[string]interface{}{"server": "server:latest", "db": "postgres"},
+		"exposeJavaDebug": true,
+		"replicas":        int64(2),
+		"timezone":        "0100",
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{"cpu": "2"},
+		},
+		"ingressAnnotations": map[string]interface{}{"cert-manager.io/issuer": "uyuni-ca-issuer"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected values: %v", values)
+	}
+
+	if _, err := HelmValues([]string{"--set", "novalue"}); err == nil {
+		t.Error("expected an error for a value without key")
+	}
+	if _, err := HelmValues([]string{"--set"}); err == nil {
+		t.Error("expected an error for a missing parameter")
+	}
+}
+
+func TestHelmValuesLists(t *testing.T) {
+	args := []string{
+		"--set", "devices={/dev/kvm,/dev/fuse}",
+		"--set", "capabilities={SYS_ADMIN}",
+		"--set", "mirror.hostPath=/srv/mirror,fips=true",
+		"--set", `annotations.cert-manager\.io/issuer=uyuni`,
+		"--set", "ports[0].port=80",
+	}
+	values, err := HelmValues(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"devices":      []interface{}{"/dev/kvm", "/dev/fuse"},
+		"capabilities": []interface{}{"SYS_ADMIN"},
+		"mirror":       map[string]interface{}{"hostPath": "/srv/mirror"},
+		"fips":         true,
+		"annotations":  map[string]interface{}{"cert-manager.io/issuer": "uyuni"},
+		"ports":        []interface{}{map[string]interface{}{"port": int64(80)}},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected values: %v", values)
+	}
+}
@@ -4,16 +4,77 @@
 
 package l10n
 
-import "github.com/chai2010/gettext-go"
+import (
+	"sync"
+
+	"github.com/chai2010/gettext-go"
+	"github.com/rs/zerolog/log"
+)
 
 // L localizes a string using the set up gettext domain and locale.
 // This is an alias for gettext.Gettext().
+//
+// The message is returned untranslated if the translation doesn't have the same format verbs.
 func L(message string) string {
-	return gettext.Gettext(message)
+	return checkTranslation(message, gettext.Gettext(message))
 }
 
 // NL returns a localized message depending on the value of count.
 // This is an alias for gettext.NGettext().
+//
+// The message is returned untranslated if the translation doesn't have the same format verbs.
 func NL(message string, plural string, count int) string {
-	return gettext.NGettext(message, plural, count)
+	original := plural
+	if count == 1 {
+		original = message
+	}
+	return checkTranslation(original, gettext.NGettext(message, plural, count))
+}
+
+func checkTranslation(original string, translated string) string {
+	if translated == original || sameVerbs(original, translated) {
+		return translated
+	}
+	logOnce("Ignoring the translation with different format verbs of: " + original)
+	return original
+}
+
+var (
+	logMutex    sync.Mutex
+	logsEnabled bool
+	logged      = map[string]bool{}
+	pendingLogs = []string{}
+)
+
+// logOnce logs a localization problem in debug level once.
+//
+// The messages are kept until the logs are set up as the localization is used before.
+func logOnce(message string) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	if logged[message] {
+		return
+	}
+	logged[message] = true
+	if !logsEnabled {
+		pendingLogs = append(pendingLogs, message)
+		return
+	}
+	log.Debug().Msg(message)
+}
+
+// ReportMissingCatalog logs once that the catalog of a domain is missing for the language.
+func ReportMissingCatalog(domain string, lang string) {
+	logOnce("No " + lang + " translations found for " + domain)
+}
+
+// EnableLocalizationLogs writes the localization problems to the logs once they are set up.
+func EnableLocalizationLogs() {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	logsEnabled = true
+	for _, message := range pendingLogs {
+		log.Debug().Msg(message)
+	}
+	pendingLogs = []string{}
 }
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package l10n
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// poEntry is a message of a PO file with its translations.
+type poEntry struct {
+	line         int
+	fuzzy        bool
+	msgid        string
+	msgidPlural  string
+	translations []string
+}
+
+// parsePoFile reads the entries of a PO file, skipping the obsolete ones.
+func parsePoFile(t *testing.T, path string) []poEntry {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %s", path, err)
+	}
+	defer file.Close()
+
+	entries := []poEntry{}
+	var current *poEntry
+	var field *string
+	fuzzy := false
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "#,"):
+			fuzzy = strings.Contains(line, "fuzzy")
+		case strings.HasPrefix(line, "msgid "):
+			entries = append(entries, poEntry{line: lineNumber, fuzzy: fuzzy})
+			current = &entries[len(entries)-1]
+			fuzzy = false
+			field = &current.msgid
+			*field = unquotePo(t, path, lineNumber, strings.TrimPrefix(line, "msgid "))
+		case strings.HasPrefix(line, "msgid_plural ") && current != nil:
+			field = &current.msgidPlural
+			*field = unquotePo(t, path, lineNumber, strings.TrimPrefix(line, "msgid_plural "))
+		case strings.HasPrefix(line, "msgstr") && current != nil:
+			parts := strings.SplitN(line, " ", 2)
+			current.translations = append(current.translations, unquotePo(t, path, lineNumber, parts[1]))
+			field = &current.translations[len(current.translations)-1]
+		case strings.HasPrefix(line, `"`) && field != nil:
+			*field += unquotePo(t, path, lineNumber, line)
+		case line == "" || strings.HasPrefix(line, "#"):
+			field = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read %s: %s", path, err)
+	}
+	return entries
+}
+
+func unquotePo(t *testing.T, path string, line int, value string) string {
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		t.Fatalf("%s:%d: invalid string %s", path, line, value)
+	}
+	return unquoted
+}
+
+// TestTranslationsVerbs checks that the translations have the same format verbs as the original messages.
+func TestTranslationsVerbs(t *testing.T) {
+	poFiles, err := filepath.Glob("../../locale/*/*.po")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(poFiles) == 0 {
+		t.Skip("no PO file found")
+	}
+
+	for _, poFile := range poFiles {
+		for _, entry := range parsePoFile(t, poFile) {
+			// The fuzzy translations are not compiled in the catalogs
+			if entry.fuzzy || entry.msgid == "" {
+				continue
+			}
+			for i, translation := range entry.translations {
+				if translation == "" {
+					continue
+				}
+				original := entry.msgid
+				if i > 0 && entry.msgidPlural != "" {
+					original = entry.msgidPlural
+				}
+				if !sameVerbs(original, translation) {
+					t.Errorf("%s:%d: the translation has different format verbs than %q: %q",
+						poFile, entry.line, original, translation)
+				}
+			}
+		}
+	}
+}
@@ -4,7 +4,12 @@
 
 package l10n
 
-import "github.com/chai2010/gettext-go"
+import (
+	"strings"
+
+	"github.com/chai2010/gettext-go"
+	"github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
 
 // DefaultFS providing a empty data if no data is found.
 type DefaultFS struct {
@@ -29,6 +34,10 @@ func (f *DefaultFS) LoadMessagesFile(domain, lang, ext string) ([]byte, error) {
 	osFile, err := f.osFs.LoadMessagesFile(domain, lang, ext)
 	// Return an empty file by default
 	if err != nil {
+		// The messages are written in English
+		if lang != "" && !strings.HasPrefix(lang, "en") && lang != "C" && lang != "POSIX" {
+			l10n.ReportMissingCatalog(domain, lang)
+		}
 		return []byte("[]"), nil
 	}
 	return osFile, nil
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package l10n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// formatVerbs returns the fmt verbs of a format string by argument index, starting at 1.
+//
+// The explicit argument indexes like %[2]s are supported so that translations can reorder the arguments.
+// The width and precision set with * are consuming an argument too and are reported with a * verb.
+func formatVerbs(format string) map[int]string {
+	verbs := map[int]string{}
+	argIndex := 1
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+
+		// Flags
+		for i < len(format) && strings.ContainsRune("+-# 0", rune(format[i])) {
+			i++
+		}
+		i, argIndex = parseArgIndex(format, i, argIndex)
+		// Width
+		if i < len(format) && format[i] == '*' {
+			verbs[argIndex] = "*"
+			argIndex++
+			i++
+		}
+		for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+			i++
+		}
+		// Precision
+		if i < len(format) && format[i] == '.' {
+			i++
+			i, argIndex = parseArgIndex(format, i, argIndex)
+			if i < len(format) && format[i] == '*' {
+				verbs[argIndex] = "*"
+				argIndex++
+				i++
+			}
+			for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+				i++
+			}
+		}
+		i, argIndex = parseArgIndex(format, i, argIndex)
+
+		if i < len(format) {
+			verbs[argIndex] = string(format[i])
+			argIndex++
+		}
+	}
+	return verbs
+}
+
+// parseArgIndex reads an explicit argument index like [2] at position i.
+func parseArgIndex(format string, i int, argIndex int) (int, int) {
+	if i >= len(format) || format[i] != '[' {
+		return i, argIndex
+	}
+	end := strings.IndexByte(format[i:], ']')
+	if end < 0 {
+		return i, argIndex
+	}
+	index, err := strconv.Atoi(format[i+1 : i+end])
+	if err != nil {
+		return i, argIndex
+	}
+	return i + end + 1, index
+}
+
+// sameVerbs returns whether the translation uses the same fmt verbs as the original format string.
+func sameVerbs(original string, translated string) bool {
+	return fmt.Sprint(formatVerbs(original)) == fmt.Sprint(formatVerbs(translated))
+}
@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package l10n
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFormatVerbs(t *testing.T) {
+	data := map[string]string{
+		"no verb":                     "map[]",
+		"100%% done":                  "map[]",
+		"%s and %d":                   "map[1:s 2:d]",
+		"%[2]s before %[1]d":          "map[1:d 2:s]",
+		"%-10s|%5.2f|%+d":             "map[1:s 2:f 3:d]",
+		"%*d":                         "map[1:* 2:d]",
+		"cannot write %[1]s file: %s": "map[1:s 2:s]",
+	}
+	for format, expected := range data {
+		if actual := fmt.Sprint(formatVerbs(format)); actual != expected {
+			t.Errorf("%s: expected %s, got %s", format, expected, actual)
+		}
+	}
+}
+
+func TestSameVerbs(t *testing.T) {
+	data := []struct {
+		original   string
+		translated string
+		same       bool
+	}{
+		{"failed to copy %s: %s", "impossible de copier %s : %s", true},
+		{"failed to copy %[1]s: %[2]s", "%[2]s : impossible de copier %[1]s", true},
+		{"failed to copy %s: %s", "impossible de copier %s", false},
+		{"%d files", "%s fichiers", false},
+	}
+	for _, test := range data {
+		if actual := sameVerbs(test.original, test.translated); actual != test.same {
+			t.Errorf("%s -> %s: expected %v", test.original, test.translated, test.same)
+		}
+	}
+}
+
+func TestCheckTranslation(t *testing.T) {
+	if actual := checkTranslation("copy %s", "copier %s"); actual != "copier %s" {
+		t.Errorf("expected the translation, got %s", actual)
+	}
+	if actual := checkTranslation("copy %s to %s", "copier %s"); actual != "copy %s to %s" {
+		t.Errorf("expected the original message, got %s", actual)
+	}
+}
@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ComponentMarker is the systemd unit key identifying the services of the optional server components.
+const ComponentMarker = "X-UyuniComponent"
+
+// ComponentService returns the name of the systemd service of an optional server component.
+func ComponentService(name string) string {
+	return "uyuni-server-" + name
+}
+
+// ComponentServices returns the installed systemd services of the optional server components, sorted by name.
+func ComponentServices() []string {
+	return componentServices(servicesPath)
+}
+
+func componentServices(dir string) []string {
+	paths, err := filepath.Glob(filepath.Join(dir, ComponentService("*")+".service"))
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to list the component services")
+		return []string{}
+	}
+
+	services := []string{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Debug().Err(err).Msgf("Failed to read %s", path)
+			continue
+		}
+		if strings.Contains(string(content), "\n"+ComponentMarker+"=") {
+			services = append(services, strings.TrimSuffix(filepath.Base(path), ".service"))
+		}
+	}
+	sort.Strings(services)
+	return services
+}
@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestComponentServices(t *testing.T) {
+	dir := t.TempDir()
+	units := map[string]string{
+		"uyuni-server-saline.service":      "[Unit]\nX-UyuniComponent=saline\n",
+		"uyuni-server-foo.service":         "[Unit]\nX-UyuniComponent=foo\n",
+		"uyuni-server-attestation.service": "[Unit]\nDescription=attestation\n",
+		"uyuni-server.service":             "[Unit]\nDescription=server\n",
+		"other.service":                    "[Unit]\nX-UyuniComponent=other\n",
+	}
+	for name, content := range units {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write unit: %s", err)
+		}
+	}
+
+	expected := "uyuni-server-foo,uyuni-server-saline"
+	if actual := strings.Join(componentServices(dir), ","); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
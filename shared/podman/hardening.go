@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// HardeningConf is the name of the systemd service configuration file holding the hardening options.
+const HardeningConf = "Hardening"
+
+// hardeningCapabilities are the capabilities podman needs to run the containers.
+var hardeningCapabilities = []string{
+	"CAP_AUDIT_WRITE", "CAP_CHOWN", "CAP_DAC_OVERRIDE", "CAP_DAC_READ_SEARCH", "CAP_FOWNER", "CAP_FSETID",
+	"CAP_KILL", "CAP_MKNOD", "CAP_NET_ADMIN", "CAP_NET_BIND_SERVICE", "CAP_NET_RAW", "CAP_SETFCAP",
+	"CAP_SETGID", "CAP_SETPCAP", "CAP_SETUID", "CAP_SYS_ADMIN", "CAP_SYS_CHROOT", "CAP_SYS_RESOURCE",
+}
+
+// hardeningOptions are the systemd options restricting what the podman process of the services can do.
+const hardeningOptions = `NoNewPrivileges=yes
+ProtectSystem=full
+ProtectHome=read-only
+PrivateTmp=yes
+ProtectClock=yes
+ProtectKernelLogs=yes
+RestrictSUIDSGID=yes
+LockPersonality=yes
+`
+
+// ServerHardenedServices are the server services receiving the hardening options.
+var ServerHardenedServices = []string{ServerService, ServerAttestationService}
+
+// ProxyHardenedServices are the proxy services receiving the hardening options.
+var ProxyHardenedServices = append([]string{ProxyService}, ProxyContainerNames...)
+
+// UpdateHardeningConf writes or removes the hardening options of services depending on the podman-hardening flag.
+//
+// The configuration files are not touched if the flag has not been set, so that the hardening is kept across upgrades.
+// The capabilities granted to the containers are added to the bounding set of the services.
+func UpdateHardeningConf(services []string, flags *PodmanFlags, cmd *cobra.Command, capabilities ...string) error {
+	changed := cmd != nil && cmd.Flags().Changed("podman-hardening")
+	for _, service := range services {
+		if err := updateHardeningConf(servicesPath, service, flags.Hardening, changed, capabilities); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func updateHardeningConf(dir string, service string, enabled bool, changed bool, capabilities []string) error {
+	confPath := path.Join(dir, service+".service.d", HardeningConf+".conf")
+	if !enabled {
+		if changed {
+			log.Debug().Msgf("Removing %s", confPath)
+			if err := os.Remove(confPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return utils.Errorf(err, L("failed to remove %[1]s file: %[2]s"), confPath)
+			}
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(path.Dir(confPath), 0750); err != nil {
+		return utils.Errorf(err, L("failed to create %[1]s folder: %[2]s"), path.Dir(confPath))
+	}
+	content := "[Service]\n" + hardeningOptions +
+		"CapabilityBoundingSet=" + strings.Join(boundingSet(capabilities), " ") + "\n"
+	if err := os.WriteFile(confPath, []byte(content), 0644); err != nil {
+		return utils.Errorf(err, L("cannot write %[1]s file: %[2]s"), confPath)
+	}
+	return nil
+}
+
+// boundingSet returns the capabilities needed by podman with the additional ones.
+func boundingSet(capabilities []string) []string {
+	result := append([]string{}, hardeningCapabilities...)
+	for _, capability := range capabilities {
+		capability = strings.ToUpper(capability)
+		if !strings.HasPrefix(capability, "CAP_") {
+			capability = "CAP_" + capability
+		}
+		if !utils.Contains(result, capability) {
+			result = append(result, capability)
+		}
+	}
+	return result
+}
+
+var exposureRegex = regexp.MustCompile(`Overall exposure level for \S+: ([0-9.]+) (\S+)`)
+
+// parseExposure extracts the exposure score and level from the systemd-analyze security output.
+func parseExposure(out []byte) (float64, string, error) {
+	matches := exposureRegex.FindSubmatch(out)
+	if matches == nil {
+		return 0, "", errors.New(L("no exposure level found"))
+	}
+	score, err := strconv.ParseFloat(string(matches[1]), 64)
+	if err != nil {
+		return 0, "", err
+	}
+	return score, string(matches[2]), nil
+}
+
+// SecurityChecks runs systemd-analyze security on the installed services.
+//
+// A service check fails if its exposure score is higher than the maximum one.
+func SecurityChecks(services []string, maxExposure float64) []utils.CheckResult {
+	checks := []utils.CheckResult{}
+	for _, service := range services {
+		if !HasService(service) {
+			continue
+		}
+		check := utils.CheckResult{Name: service}
+		args := SystemctlArgs("security", "--no-pager", service+".service")
+		out, err := utils.RunCmdOutput(zerolog.DebugLevel, "systemd-analyze", args...)
+		if err != nil {
+			check.Detail = fmt.Sprintf(L("failed to analyze the service: %s"), err)
+			checks = append(checks, check)
+			continue
+		}
+		score, level, err := parseExposure(out)
+		if err != nil {
+			check.Detail = err.Error()
+		} else {
+			check.OK = score <= maxExposure
+			check.Detail = fmt.Sprintf(L("exposure level %.1f %s"), score, level)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
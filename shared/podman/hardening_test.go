@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestUpdateHardeningConf(t *testing.T) {
+	dir := t.TempDir()
+	confPath := path.Join(dir, ServerService+".service.d", HardeningConf+".conf")
+
+	// Nothing written if not enabled
+	if err := updateHardeningConf(dir, ServerService, false, false, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := os.Stat(confPath); err == nil {
+		t.Error("no configuration file expected")
+	}
+
+	if err := updateHardeningConf(dir, ServerService, true, true, []string{"sys_ptrace", "CAP_NET_ADMIN"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	content, err := os.ReadFile(confPath)
+	if err != nil {
+		t.Fatalf("failed to read %s: %s", confPath, err)
+	}
+	for _, expected := range []string{"[Service]\n", "NoNewPrivileges=yes\n", "PrivateTmp=yes\n", " CAP_SYS_PTRACE\n"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("missing %q in %s", expected, string(content))
+		}
+	}
+	if strings.Count(string(content), "CAP_NET_ADMIN") != 1 {
+		t.Errorf("duplicated capability in %s", string(content))
+	}
+
+	// Kept when the flag is not set
+	if err := updateHardeningConf(dir, ServerService, false, false, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := os.Stat(confPath); err != nil {
+		t.Error("configuration file should be kept")
+	}
+
+	// Removed when disabled explicitly
+	if err := updateHardeningConf(dir, ServerService, false, true, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := os.Stat(confPath); err == nil {
+		t.Error("configuration file should be removed")
+	}
+}
+
+func TestParseExposure(t *testing.T) {
+	out := `  NAME                                                        DESCRIPTION                     EXPOSURE
+✗ PrivateNetwork=                                             Service has access to the host's network 0.5
+→ Overall exposure level for uyuni-server.service: 9.2 UNSAFE 😨
+`
+	score, level, err := parseExposure([]byte(out))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if score != 9.2 || level != "UNSAFE" {
+		t.Errorf("unexpected exposure: %f %s", score, level)
+	}
+
+	if _, _, err := parseExposure([]byte("garbage")); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -7,11 +7,13 @@ package podman
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path"
 	"strings"
+	"sync"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -41,10 +43,11 @@ func PrepareImage(image string, pullPolicy string, args ...string) (string, erro
 
 		if len(presentImage) > 0 {
 			log.Debug().Msgf("Image %s already present", presentImage)
-			return presentImage, nil
+			return presentImage, VerifyImage(presentImage)
 		}
 	}
 
+	// The RPM images are not verified: the package signature already guarantees their provenance.
 	rpmImageFile := GetRpmImagePath(image)
 
 	if len(rpmImageFile) > 0 {
@@ -62,10 +65,16 @@ func PrepareImage(image string, pullPolicy string, args ...string) (string, erro
 
 	if strings.ToLower(pullPolicy) != "never" {
 		log.Debug().Msgf("Pulling image %s because it is missing and pull policy is not 'never'", image)
-		return image, pullImage(image, args...)
+		err := withSignaturePolicy(func(policyArgs ...string) error {
+			return pullImage(image, append(policyArgs, args...)...)
+		})
+		if err != nil {
+			return image, utils.WithCode(utils.CodeImagePull, err)
+		}
+		return image, VerifyImage(image)
 	}
 
-	return image, fmt.Errorf(L("image %s is missing and cannot be fetched"), image)
+	return image, utils.WithCode(utils.CodeImagePull, fmt.Errorf(L("image %s is missing and cannot be fetched"), image))
 }
 
 // GetRpmImageName return the RPM Image name and the tag, given an image.
@@ -90,7 +99,7 @@ func GetRpmImageName(image string) (rpmImageFile string, tag string) {
 func BuildRpmImagePath(byteValue []byte, rpmImageFile string, tag string) (string, error) {
 	var data types.Metadata
 	if err := json.Unmarshal(byteValue, &data); err != nil {
-		return "", fmt.Errorf(L("cannot unmarshal image RPM metadata: %s"), err)
+		return "", utils.Errorf(err, L("cannot unmarshal image RPM metadata: %s"))
 	}
 	fullPathFile := rpmImageDir + data.Image.File
 	if data.Image.Name == rpmImageFile {
@@ -204,6 +213,10 @@ func pullImage(image string, args ...string) error {
 		return fmt.Errorf(L("%s should contains just lower case character, otherwise podman pull would fails"), image)
 	}
 	log.Info().Msgf(L("Running podman pull %s"), image)
+	// The credentials stored for the registry are more specific than the SUSE Customer Center ones
+	if registryArgs := registryCredentialsArgs(image); len(registryArgs) > 0 {
+		args = append(withoutCredsArgs(args), registryArgs...)
+	}
 	podmanImageArgs := []string{"pull", image}
 	podmanArgs := append(podmanImageArgs, args...)
 
@@ -216,13 +229,132 @@ func pullImage(image string, args ...string) error {
 	return utils.RunCmdStdMapping(loglevel, "podman", podmanArgs...)
 }
 
+// GetPullArgs returns the podman pull arguments for the registry credentials found on the host.
+//
+// The SUSE Customer Center credentials stored in the credentials store are used if the host has none.
+func GetPullArgs(inspectedHostValues map[string]string) []string {
+	pullArgs := []string{}
+	_, scc_user_exist := inspectedHostValues["host_scc_username"]
+	_, scc_user_password := inspectedHostValues["host_scc_password"]
+	if scc_user_exist && scc_user_password {
+		pullArgs = append(pullArgs, "--creds", inspectedHostValues["host_scc_username"]+":"+inspectedHostValues["host_scc_password"])
+	} else if scc := utils.GetCredentials(utils.SccCredentialsKey); scc != nil {
+		log.Debug().Msg("Using the stored SUSE Customer Center credentials to pull the images")
+		pullArgs = append(pullArgs, "--creds", scc.User+":"+scc.Password)
+	}
+	return pullArgs
+}
+
+// withoutCredsArgs returns the pull arguments without the --creds option.
+func withoutCredsArgs(args []string) []string {
+	result := []string{}
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--creds" {
+			i++
+			continue
+		}
+		if !strings.HasPrefix(args[i], "--creds=") {
+			result = append(result, args[i])
+		}
+	}
+	return result
+}
+
+// registryCredentialsArgs returns the podman pull arguments for the stored credentials of the image registry.
+func registryCredentialsArgs(image string) []string {
+	registry, _, found := strings.Cut(image, "/")
+	// Images without registry or with a first part not looking like a host name are pulled from docker.io
+	if !found || !strings.ContainsAny(registry, ".:") && registry != "localhost" {
+		return []string{}
+	}
+	credentials := utils.GetCredentials(utils.RegistryCredentialsKey(registry))
+	if credentials == nil {
+		return []string{}
+	}
+	log.Debug().Msgf("Using the stored credentials for registry %s", registry)
+	return []string{"--creds", credentials.User + ":" + credentials.Password}
+}
+
+// maxParallelPulls is the maximum number of images pulled at the same time.
+const maxParallelPulls = 4
+
+// PullImages pulls several images in parallel and reports the progress.
+//
+// At most maxParallelPulls images are pulled at the same time to not saturate the network and the registry.
+// The images already present are not pulled again unless the pull policy is set to always.
+func PullImages(images []string, pullPolicy string, args ...string) error {
+	if strings.ToLower(pullPolicy) == "never" {
+		return utils.WithCode(utils.CodeImagePull, errors.New(L("cannot pull images with the never pull policy")))
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	done := 0
+	failures := []string{}
+	slots := make(chan struct{}, maxParallelPulls)
+
+	for _, image := range images {
+		wg.Add(1)
+		go func(image string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			err := pullImageQuiet(image, pullPolicy, args...)
+			<-slots
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			done++
+			if err != nil {
+				log.Error().Err(err).Msgf(L("[%[1]d/%[2]d] Failed to pull %[3]s"), done, len(images), image)
+				failures = append(failures, image)
+			} else {
+				log.Info().Msgf(L("[%[1]d/%[2]d] %[3]s is available"), done, len(images), image)
+			}
+		}(image)
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return utils.WithCode(utils.CodeImagePull, fmt.Errorf(L("failed to pull images: %s"), strings.Join(failures, ", ")))
+	}
+	return nil
+}
+
+// pullImageQuiet pulls an image without showing the podman output as it would be mixed with the parallel pulls.
+func pullImageQuiet(image string, pullPolicy string, args ...string) error {
+	if strings.ToLower(pullPolicy) != "always" {
+		presentImage, err := IsImagePresent(image)
+		if err != nil {
+			return err
+		}
+		if presentImage != "" {
+			log.Debug().Msgf("Image %s already present", presentImage)
+			return VerifyImage(presentImage)
+		}
+	}
+
+	if utils.ContainsUpperCase(image) {
+		return fmt.Errorf(L("%s should contains just lower case character, otherwise podman pull would fails"), image)
+	}
+	log.Debug().Msgf("Pulling image %s", image)
+	err := withSignaturePolicy(func(policyArgs ...string) error {
+		podmanArgs := append([]string{"pull", "--quiet", image}, append(policyArgs, args...)...)
+		_, err := utils.RunCmdOutput(zerolog.Disabled, "podman", podmanArgs...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return VerifyImage(image)
+}
+
 // ShowAvailableTag  returns the list of available tag for a given image.
 func ShowAvailableTag(image string) ([]string, error) {
 	log.Info().Msgf(L("Running podman image search --list-tags %s --format={{.Tag}}"), image)
 
 	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", "image", "search", "--list-tags", image, "--format={{.Tag}}")
 	if err != nil {
-		return []string{}, fmt.Errorf(L("cannot find any tag for image %s: %s"), image, err)
+		return []string{}, utils.Errorf(err, L("cannot find any tag for image %s: %s"), image)
 	}
 
 	tags := strings.Split(string(out), "\n")
@@ -235,7 +367,7 @@ func GetRunningImage(container string) (string, error) {
 
 	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", "ps", fmt.Sprintf("--filter=name=%s", container), "--format='{{ .Image }}'")
 	if err != nil {
-		return "", fmt.Errorf(L("cannot find any running image for container %s: %s"), container, err)
+		return "", utils.Errorf(err, L("cannot find any running image for container %s: %s"), container)
 	}
 
 	image := strings.TrimSpace(string(out))
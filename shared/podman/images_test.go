@@ -5,7 +5,17 @@
 package podman
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 func TestGetRpmImageName(t *testing.T) {
@@ -73,3 +83,110 @@ func TestMatchingMetadata(t *testing.T) {
 		t.Error("typo in json: this should fail")
 	}
 }
+
+func TestRegistryCredentialsArgs(t *testing.T) {
+	provider := utils.NewFileCredentialsProvider(path.Join(t.TempDir(), "credentials.yaml"))
+	defer utils.SetCredentialsProvider(provider)()
+
+	credentials := utils.Credentials{User: "robot", Password: "secret"}
+	if err := provider.Set(utils.RegistryCredentialsKey("registry.example.com:5000"), credentials); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []struct {
+		image    string
+		expected []string
+	}{
+		{"registry.example.com:5000/uyuni/server:latest", []string{"--creds", "robot:secret"}},
+		{"registry.opensuse.org/uyuni/server:latest", []string{}},
+		{"uyuni/server", []string{}},
+		{"server", []string{}},
+	}
+	for i, test := range data {
+		if actual := registryCredentialsArgs(test.image); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, test.expected, actual)
+		}
+	}
+
+	args := []string{"--tls-verify=false", "--creds", "scc:pass", "--creds=other:pass"}
+	if actual := withoutCredsArgs(args); !reflect.DeepEqual(actual, []string{"--tls-verify=false"}) {
+		t.Errorf("expected the --creds options to be removed, got %v", actual)
+	}
+}
+
+func TestGetPullArgsStoredScc(t *testing.T) {
+	provider := utils.NewFileCredentialsProvider(path.Join(t.TempDir(), "credentials.yaml"))
+	defer utils.SetCredentialsProvider(provider)()
+
+	if actual := GetPullArgs(map[string]string{}); len(actual) != 0 {
+		t.Errorf("expected no pull arguments, got %v", actual)
+	}
+
+	if err := provider.Set(utils.SccCredentialsKey, utils.Credentials{User: "stored", Password: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	if actual := GetPullArgs(map[string]string{}); !reflect.DeepEqual(actual, []string{"--creds", "stored:pass"}) {
+		t.Errorf("expected the stored credentials, got %v", actual)
+	}
+
+	// Incomplete host credentials are ignored
+	if actual := GetPullArgs(map[string]string{"host_scc_username": "host"}); !reflect.DeepEqual(actual,
+		[]string{"--creds", "stored:pass"}) {
+		t.Errorf("expected the stored credentials without host password, got %v", actual)
+	}
+
+	hostValues := map[string]string{"host_scc_username": "host", "host_scc_password": "hostpass"}
+	if actual := GetPullArgs(hostValues); !reflect.DeepEqual(actual, []string{"--creds", "host:hostpass"}) {
+		t.Errorf("expected the host credentials to be preferred, got %v", actual)
+	}
+}
+
+func TestPullImages(t *testing.T) {
+	var mutex sync.Mutex
+	running := 0
+	maxRunning := 0
+	pulled := []string{}
+	defer utils.SetCommandRunner(func(command string, args []string, stdout io.Writer, stderr io.Writer) error {
+		mutex.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mutex.Unlock()
+
+		// Let the other pulls start
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		running--
+		if command != "podman" || len(args) < 3 || args[0] != "pull" {
+			t.Errorf("unexpected command: %s %v", command, args)
+			return errors.New("unexpected command")
+		}
+		pulled = append(pulled, args[2])
+		if strings.Contains(args[2], "broken") {
+			return errors.New("pull failed")
+		}
+		return nil
+	})()
+
+	images := []string{"registry/broken"}
+	for i := 0; i < 10; i++ {
+		images = append(images, fmt.Sprintf("registry/image%d", i))
+	}
+	err := PullImages(images, "Always", "--tls-verify=false")
+	if err == nil || !strings.Contains(err.Error(), "registry/broken") || strings.Contains(err.Error(), "image0") {
+		t.Errorf("expected only the broken image to fail, got %v", err)
+	}
+	if len(pulled) != len(images) {
+		t.Errorf("expected %d pulls, got %v", len(images), pulled)
+	}
+	if maxRunning > maxParallelPulls {
+		t.Errorf("expected at most %d parallel pulls, got %d", maxParallelPulls, maxRunning)
+	}
+
+	if err := PullImages(images, "Never"); err == nil {
+		t.Error("expected an error with the never pull policy")
+	}
+}
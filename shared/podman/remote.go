@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// RemoteAnnotation is the cobra command annotation marking the commands supporting a remote podman host.
+//
+// Only the commands reading the status or logs and running commands in the containers support it:
+// the others write files on the host like the systemd services or the configuration.
+const RemoteAnnotation = "uyuni-tools/podman-remote"
+
+// remoteSocketPath is the path of the rootful podman socket on the remote host.
+const remoteSocketPath = "/run/podman/podman.sock"
+
+// RemoteFlags are the flags to run the podman commands on a remote host.
+type RemoteFlags struct {
+	Connection string
+	SshHost    string
+}
+
+// systemdHost is the user@host to pass to the systemctl -H option, empty for the local host.
+var systemdHost string
+
+// AddRemoteFlags adds the flags to target a remote podman host to a root command.
+func AddRemoteFlags(cmd *cobra.Command, flags *RemoteFlags) {
+	cmd.PersistentFlags().StringVar(&flags.Connection, "podman-connection", "",
+		L("name of the podman system connection to use to reach a remote host. "+
+			"Only the status, logs and container commands support it"))
+	cmd.PersistentFlags().StringVar(&flags.SshHost, "ssh-host", "",
+		L("user@host of a remote podman host to connect to using SSH. The podman socket needs to be enabled on it. "+
+			"Only the status, logs and container commands support it"))
+}
+
+// SetRemote configures the podman and systemctl calls to target the host defined in the remote flags.
+//
+// The podman client is configured using its environment variables. Nothing changes if no flag is set.
+// The command fails if it is not marked with RemoteAnnotation.
+func SetRemote(cmd *cobra.Command, flags *RemoteFlags) error {
+	if flags.Connection != "" && flags.SshHost != "" {
+		return errors.New(L("podman-connection and ssh-host flags cannot be set simultaneously"))
+	}
+	if (flags.Connection != "" || flags.SshHost != "") && cmd.Annotations[RemoteAnnotation] != "true" {
+		return fmt.Errorf(L("%s command cannot be run on a remote podman host, run it on the host itself"),
+			cmd.CommandPath())
+	}
+
+	if flags.SshHost != "" {
+		hostURL := RemoteURL(flags.SshHost)
+		// systemctl doesn't accept a port: it has to be defined in the SSH configuration
+		host, err := parseConnectionHost(hostURL)
+		if err != nil {
+			return err
+		}
+		if err := os.Setenv("CONTAINER_HOST", hostURL); err != nil {
+			return err
+		}
+		systemdHost = host
+	} else if flags.Connection != "" {
+		host, err := getConnectionHost(flags.Connection)
+		if err != nil {
+			return err
+		}
+		if err := os.Setenv("CONTAINER_CONNECTION", flags.Connection); err != nil {
+			return err
+		}
+		systemdHost = host
+	} else {
+		return nil
+	}
+
+	log.Debug().Msgf("Using remote podman host %s", systemdHost)
+	return nil
+}
+
+// RemoteURL returns the podman URL of the host reached using SSH as user@host.
+func RemoteURL(sshHost string) string {
+	return "ssh://" + sshHost + remoteSocketPath
+}
+
+// IsRemote returns whether the podman commands are run on a remote host.
+func IsRemote() bool {
+	return systemdHost != ""
+}
+
+// RemoteHost returns the user@host of the remote podman host or an empty string for the local host.
+func RemoteHost() string {
+	return systemdHost
+}
+
+type systemConnection struct {
+	Name string
+	URI  string
+}
+
+// getConnectionHost finds the user@host of a podman system connection.
+func getConnectionHost(name string) (string, error) {
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", "system", "connection", "list", "--format", "json")
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to list podman system connections: %s"))
+	}
+
+	var connections []systemConnection
+	if err := json.Unmarshal(out, &connections); err != nil {
+		return "", utils.Errorf(err, L("failed to parse podman system connections: %s"))
+	}
+	for _, connection := range connections {
+		if connection.Name == name {
+			return parseConnectionHost(connection.URI)
+		}
+	}
+	return "", fmt.Errorf(L("no podman system connection named %s"), name)
+}
+
+// parseConnectionHost extracts the user@host from a podman connection URI like ssh://root@host:22/run/podman/podman.sock.
+func parseConnectionHost(uri string) (string, error) {
+	connectionURL, err := url.Parse(uri)
+	if err != nil {
+		return "", utils.Errorf(err, L("invalid podman connection URI %[1]s: %[2]s"), uri)
+	}
+	if connectionURL.Scheme != "ssh" {
+		return "", fmt.Errorf(L("unsupported podman connection URI %s: only ssh is supported"), uri)
+	}
+	host := connectionURL.Hostname()
+	if connectionURL.User != nil {
+		host = connectionURL.User.Username() + "@" + host
+	}
+	return host, nil
+}
+
+// SystemctlArgs adds the remote host to the systemctl arguments if needed.
+func SystemctlArgs(args ...string) []string {
+	if systemdHost != "" {
+		return append([]string{"-H", systemdHost}, args...)
+	}
+	return args
+}
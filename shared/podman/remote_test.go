@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestParseConnectionHost(t *testing.T) {
+	data := map[string]string{
+		"ssh://root@server.example.com:22/run/podman/podman.sock": "root@server.example.com",
+		"ssh://server.example.com/run/podman/podman.sock":         "server.example.com",
+		"ssh://admin@[fd00::1]:2222/run/podman/podman.sock":       "admin@fd00::1",
+	}
+	for uri, expected := range data {
+		actual, err := parseConnectionHost(uri)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", uri, err)
+		} else if actual != expected {
+			t.Errorf("%s: expected %s, got %s", uri, expected, actual)
+		}
+	}
+
+	if _, err := parseConnectionHost("unix:///run/podman/podman.sock"); err == nil {
+		t.Error("expected an error for a unix socket connection")
+	}
+}
+
+func TestSystemctlArgs(t *testing.T) {
+	// Restore the environment variable changed by SetRemote after the test
+	t.Setenv("CONTAINER_HOST", "")
+	defer func() { systemdHost = "" }()
+
+	if actual := strings.Join(SystemctlArgs("start", ServerService), " "); actual != "start uyuni-server" {
+		t.Errorf("unexpected local systemctl arguments: %s", actual)
+	}
+
+	remoteCmd := &cobra.Command{Use: "status", Annotations: map[string]string{RemoteAnnotation: "true"}}
+	if err := SetRemote(remoteCmd, &RemoteFlags{SshHost: "root@server.example.com:2222"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if actual := strings.Join(SystemctlArgs("start", ServerService), " "); actual != "-H root@server.example.com start uyuni-server" {
+		t.Errorf("unexpected remote systemctl arguments: %s", actual)
+	}
+
+	if err := SetRemote(remoteCmd, &RemoteFlags{SshHost: "host", Connection: "name"}); err == nil {
+		t.Error("expected an error when setting both connection and SSH host")
+	}
+}
+
+func TestSetRemoteUnsupportedCommand(t *testing.T) {
+	t.Setenv("CONTAINER_HOST", "")
+	defer func() { systemdHost = "" }()
+
+	installCmd := &cobra.Command{Use: "install"}
+	if err := SetRemote(installCmd, &RemoteFlags{}); err != nil {
+		t.Errorf("unexpected error without remote host: %s", err)
+	}
+	if err := SetRemote(installCmd, &RemoteFlags{SshHost: "root@server.example.com"}); err == nil {
+		t.Error("expected an error for a command not supporting a remote host")
+	}
+	if IsRemote() {
+		t.Error("the remote host should not be set for a command not supporting it")
+	}
+}
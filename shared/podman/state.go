@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// GetImageDigest returns the digest of a local image or an empty string if it cannot be found.
+func GetImageDigest(image string) string {
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", "image", "inspect", "--format", "{{.Digest}}", image)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to get the digest of image %s", image)
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// ReadState reads the podman deployment state file.
+//
+// Returns nil without error if no state has been recorded.
+func ReadState() (*types.DeploymentState, error) {
+	return utils.ReadStateFile(utils.StateFilePath)
+}
+
+// WriteState writes the podman deployment state file.
+func WriteState(state *types.DeploymentState) error {
+	return utils.WriteStateFile(utils.StateFilePath, state)
+}
@@ -6,12 +6,13 @@ package podman
 
 import (
 	"errors"
-	"fmt"
 	"os"
 	"os/exec"
 	"path"
+	"strings"
 
 	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
@@ -24,13 +25,23 @@ const ServerService = "uyuni-server"
 // Name of the systemd service for the coco attestation container.
 const ServerAttestationService = "uyuni-server-attestation"
 
+// Name of the systemd service for the billing adapter container of the pay-as-you-go cloud instances.
+const ServerBillingAdapterService = "uyuni-server-billing-adapter"
+
+// Name of the systemd service for the saline salt event processor container.
+// Saline is an optional component, see ComponentService.
+const ServerSalineService = "uyuni-server-saline"
+
+// Name of the systemd service for the reporting database container.
+const ServerReportDbService = "uyuni-server-reportdb"
+
 // Name of the systemd service for the proxy.
 const ProxyService = "uyuni-proxy-pod"
 
 // HasService returns if a systemd service is installed.
 // name is the name of the service without the '.service' part.
 func HasService(name string) bool {
-	err := utils.RunCmd("systemctl", "list-unit-files", name+".service")
+	err := utils.RunCmd("systemctl", SystemctlArgs("list-unit-files", name+".service")...)
 	return err == nil
 }
 
@@ -52,7 +63,7 @@ func UninstallService(name string, dryRun bool) {
 		} else {
 			log.Info().Msgf(L("Disable %s service"), name)
 			// disable server
-			err := utils.RunCmd("systemctl", "disable", "--now", name)
+			err := utils.RunCmd("systemctl", SystemctlArgs("disable", "--now", name)...)
 			if err != nil {
 				log.Error().Err(err).Msgf(L("Failed to disable %s service"), name)
 			}
@@ -73,11 +84,11 @@ func ReloadDaemon(dryRun bool) error {
 		log.Info().Msgf(L("Would run %s"), "systemctl reset-failed")
 		log.Info().Msgf(L("Would run %s"), "systemctl daemon-reload")
 	} else {
-		err := utils.RunCmd("systemctl", "reset-failed")
+		err := utils.RunCmd("systemctl", SystemctlArgs("reset-failed")...)
 		if err != nil {
 			return errors.New(L("failed to reset-failed systemd"))
 		}
-		err = utils.RunCmd("systemctl", "daemon-reload")
+		err = utils.RunCmd("systemctl", SystemctlArgs("daemon-reload")...)
 		if err != nil {
 			return errors.New(L("failed to reload systemd daemon"))
 		}
@@ -87,7 +98,7 @@ func ReloadDaemon(dryRun bool) error {
 
 // IsServiceRunning returns whether the systemd service is started or not.
 func IsServiceRunning(service string) bool {
-	cmd := exec.Command("systemctl", "is-active", "-q", service)
+	cmd := exec.Command("systemctl", SystemctlArgs("is-active", "-q", service)...)
 	if err := cmd.Run(); err != nil {
 		return false
 	}
@@ -96,50 +107,101 @@ func IsServiceRunning(service string) bool {
 
 // RestartService restarts the systemd service.
 func RestartService(service string) error {
-	if err := utils.RunCmd("systemctl", "restart", service); err != nil {
-		return fmt.Errorf(L("failed to restart systemd %s.service: %s"), service, err)
+	if err := utils.RunCmd("systemctl", SystemctlArgs("restart", service)...); err != nil {
+		return utils.Errorf(err, L("failed to restart systemd %s.service: %s"), service)
 	}
 	return nil
 }
 
 // StartService starts the systemd service.
 func StartService(service string) error {
-	if err := utils.RunCmd("systemctl", "start", service); err != nil {
-		return fmt.Errorf(L("failed to start systemd %s.service: %s"), service, err)
+	if err := utils.RunCmd("systemctl", SystemctlArgs("start", service)...); err != nil {
+		return utils.Errorf(err, L("failed to start systemd %s.service: %s"), service)
 	}
 	return nil
 }
 
 // StopService starts the systemd service.
 func StopService(service string) error {
-	if err := utils.RunCmd("systemctl", "stop", service); err != nil {
-		return fmt.Errorf(L("failed to stop systemd %s.service: %s"), service, err)
+	if err := utils.RunCmd("systemctl", SystemctlArgs("stop", service)...); err != nil {
+		return utils.Errorf(err, L("failed to stop systemd %s.service: %s"), service)
 	}
 	return nil
 }
 
 // EnableService enables and starts a systemd service.
 func EnableService(service string) error {
-	if err := utils.RunCmd("systemctl", "enable", "--now", service); err != nil {
-		return fmt.Errorf(L("failed to enable %s systemd service: %s"), service, err)
+	if err := utils.RunCmd("systemctl", SystemctlArgs("enable", "--now", service)...); err != nil {
+		return utils.Errorf(err, L("failed to enable %s systemd service: %s"), service)
 	}
 	return nil
 }
 
+// GetConfFilePath returns the path of the configuration file of a systemd service section.
+func GetConfFilePath(serviceName string, section string) string {
+	return path.Join(GetServicePath(serviceName)+".d", section+".conf")
+}
+
+// ConfFileContent returns the content of a systemd service configuration file.
+func ConfFileContent(section string, body string) []byte {
+	return []byte("[" + section + "]" + "\n" + body + "\n")
+}
+
 // Create new systemd service configuration file.
 func GenerateSystemdConfFile(serviceName string, section string, body string) error {
 	systemdFilePath := GetServicePath(serviceName)
 
 	systemdConfFolder := systemdFilePath + ".d"
 	if err := os.MkdirAll(systemdConfFolder, 0750); err != nil {
-		return fmt.Errorf(L("failed to create %s folder: %s"), systemdConfFolder, err)
+		return utils.Errorf(err, L("failed to create %s folder: %s"), systemdConfFolder)
 	}
-	systemdConfFilePath := path.Join(systemdConfFolder, section+".conf")
+	systemdConfFilePath := GetConfFilePath(serviceName, section)
 
-	content := []byte("[" + section + "]" + "\n" + body + "\n")
+	content := ConfFileContent(section, body)
 	if err := os.WriteFile(systemdConfFilePath, content, 0644); err != nil {
-		return fmt.Errorf(L("cannot write %s file: %s"), systemdConfFilePath, err)
+		return utils.Errorf(err, L("cannot write %s file: %s"), systemdConfFilePath)
+	}
+
+	return nil
+}
+
+// ExtraArgsConf is the name of the systemd service configuration file holding the user podman arguments.
+const ExtraArgsConf = "ExtraArgs"
+
+// UpdateExtraArgsConf stores the user podman arguments in the PODMAN_EXTRA_ARGS variable of the service.
+//
+// The configuration file is not touched if it already exists and the podman arguments have not been set,
+// so that the arguments passed at install time are kept across upgrades.
+func UpdateExtraArgsConf(service string, flags *PodmanFlags, cmd *cobra.Command) error {
+	return updateExtraArgsConf(servicesPath, service, flags, extraArgsChanged(cmd))
+}
+
+func extraArgsChanged(cmd *cobra.Command) bool {
+	if cmd == nil {
+		return false
+	}
+	return cmd.Flags().Changed("podman-arg") || cmd.Flags().Changed("podman-env")
+}
+
+func updateExtraArgsConf(dir string, service string, flags *PodmanFlags, changed bool) error {
+	args, err := flags.ExtraArgs()
+	if err != nil {
+		return err
+	}
+
+	confPath := path.Join(dir, service+".service.d", ExtraArgsConf+".conf")
+	if _, err := os.Stat(confPath); err == nil && len(args) == 0 && !changed {
+		log.Debug().Msgf("Keeping the podman arguments from %s", confPath)
+		return nil
 	}
 
+	if err := os.MkdirAll(path.Dir(confPath), 0750); err != nil {
+		return utils.Errorf(err, L("failed to create %[1]s folder: %[2]s"), path.Dir(confPath))
+	}
+	body := "Environment=" + utils.SystemdQuote("PODMAN_EXTRA_ARGS="+strings.Join(args, " "), false)
+	content := []byte("[Service]\n" + body + "\n")
+	if err := os.WriteFile(confPath, content, 0644); err != nil {
+		return utils.Errorf(err, L("cannot write %[1]s file: %[2]s"), confPath)
+	}
 	return nil
 }
@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtraArgs(t *testing.T) {
+	flags := PodmanFlags{Args: []string{"--cap-add SYS_ADMIN"}, Env: []string{"FOO=bar", "EMPTY="}}
+	args, err := flags.ExtraArgs()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "--cap-add SYS_ADMIN|-e|FOO=bar|-e|EMPTY="
+	if actual := strings.Join(args, "|"); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	for _, env := range []string{"FOO", "1FOO=bar", "FOO=with space"} {
+		flags := PodmanFlags{Env: []string{env}}
+		if _, err := flags.ExtraArgs(); err == nil {
+			t.Errorf("expected an error for %s", env)
+		}
+	}
+}
+
+func TestUpdateExtraArgsConf(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "uyuni-server.service.d", ExtraArgsConf+".conf")
+
+	readConf := func() string {
+		data, err := os.ReadFile(confPath)
+		if err != nil {
+			t.Fatalf("failed to read the configuration: %s", err)
+		}
+		return string(data)
+	}
+
+	// Written at install time, even if empty
+	if err := updateExtraArgsConf(dir, "uyuni-server", &PodmanFlags{}, false); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if actual := readConf(); actual != "[Service]\nEnvironment=\"PODMAN_EXTRA_ARGS=\"\n" {
+		t.Errorf("unexpected empty configuration: %s", actual)
+	}
+
+	flags := PodmanFlags{Args: []string{"--cap-add", "SYS_ADMIN"}, Env: []string{"FOO=50%"}}
+	if err := updateExtraArgsConf(dir, "uyuni-server", &flags, false); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "[Service]\nEnvironment=\"PODMAN_EXTRA_ARGS=--cap-add SYS_ADMIN -e FOO=50%%\"\n"
+	if actual := readConf(); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	// An upgrade without the flags keeps the previous arguments
+	if err := updateExtraArgsConf(dir, "uyuni-server", &PodmanFlags{}, false); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if actual := readConf(); actual != expected {
+		t.Errorf("arguments not kept, got %s", actual)
+	}
+
+	// Explicitly emptied flags remove the arguments
+	if err := updateExtraArgsConf(dir, "uyuni-server", &PodmanFlags{}, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if actual := readConf(); actual != "[Service]\nEnvironment=\"PODMAN_EXTRA_ARGS=\"\n" {
+		t.Errorf("arguments not removed, got %s", actual)
+	}
+}
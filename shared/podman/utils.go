@@ -5,10 +5,12 @@
 package podman
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path"
+	"regexp"
 	"strings"
 
 	"github.com/rs/zerolog"
@@ -34,8 +36,12 @@ var ProxyContainerNames = []string{
 
 // PodmanFlags stores the podman arguments.
 type PodmanFlags struct {
-	Args   []string         `mapstructure:"arg"`
-	Mounts PodmanMountFlags `mapstructure:"mount"`
+	Args      []string         `mapstructure:"arg"`
+	Env       []string         `mapstructure:"env"`
+	Mounts    PodmanMountFlags `mapstructure:"mount"`
+	Memory    string
+	Cpus      string
+	Hardening bool
 }
 
 // PodmanMountFlags stores the --podman-mount-* arguments.
@@ -54,6 +60,52 @@ func GetCommonParams() []string {
 // AddPodmanArgFlag add the podman arguments to a command.
 func AddPodmanArgFlag(cmd *cobra.Command) {
 	cmd.Flags().StringSlice("podman-arg", []string{}, L("Extra arguments to pass to podman"))
+	cmd.Flags().StringSlice("podman-env", []string{},
+		L("Extra environment variables to set in the containers, in the NAME=value form"))
+	cmd.Flags().Bool("podman-hardening", false,
+		L("Restrict the privileges of the systemd services running the containers. Kept across upgrades"))
+}
+
+var podmanEnvRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=\S*$`)
+
+// ExtraArgs returns the extra podman arguments including the environment variables.
+func (flags *PodmanFlags) ExtraArgs() ([]string, error) {
+	args := append([]string{}, flags.Args...)
+	for _, env := range flags.Env {
+		if !podmanEnvRegex.MatchString(env) {
+			return nil, fmt.Errorf(L("invalid podman environment variable %s: NAME=value without spaces is expected"), env)
+		}
+		args = append(args, "-e", env)
+	}
+	return args, nil
+}
+
+var memoryRegex = regexp.MustCompile(`^[0-9]+[bkmgBKMG]?$`)
+var cpusRegex = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// CheckLimits validates the memory and CPUs limits of a container.
+//
+// Empty values mean no limit.
+func CheckLimits(memory string, cpus string) error {
+	if memory != "" && !memoryRegex.MatchString(memory) {
+		return fmt.Errorf(L("invalid memory limit %s: a number with an optional b, k, m or g unit is expected"), memory)
+	}
+	if cpus != "" && !cpusRegex.MatchString(cpus) {
+		return fmt.Errorf(L("invalid CPUs limit %s: a decimal number is expected"), cpus)
+	}
+	return nil
+}
+
+// LimitsArgs returns the podman arguments limiting the memory and CPUs of a container.
+func LimitsArgs(memory string, cpus string) []string {
+	args := []string{}
+	if memory != "" {
+		args = append(args, "--memory", memory)
+	}
+	if cpus != "" {
+		args = append(args, "--cpus", cpus)
+	}
+	return args
 }
 
 // AddPodmanInstallFlag add the podman install arguments to a command.
@@ -63,20 +115,26 @@ func AddPodmanInstallFlag(cmd *cobra.Command) {
 	cmd.Flags().String("podman-mount-postgresql", "", L("Path to custom /var/lib/pgsql volume"))
 	cmd.Flags().String("podman-mount-spacewalk", "", L("Path to custom /var/spacewalk volume"))
 	cmd.Flags().String("podman-mount-www", "", L("Path to custom /srv/www/ volume"))
+	cmd.Flags().String("podman-memory", "", L("Memory limit of the server container, for example 16g"))
+	cmd.Flags().String("podman-cpus", "", L("Number of CPUs of the server container, for example 4"))
 
 	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "podman", Title: "Podman Flags"})
 	_ = utils.AddFlagToHelpGroupID(cmd, "podman-arg", "podman")
+	_ = utils.AddFlagToHelpGroupID(cmd, "podman-env", "podman")
+	_ = utils.AddFlagToHelpGroupID(cmd, "podman-hardening", "podman")
 	_ = utils.AddFlagToHelpGroupID(cmd, "podman-mount-cache", "podman")
 	_ = utils.AddFlagToHelpGroupID(cmd, "podman-mount-postgresql", "podman")
 	_ = utils.AddFlagToHelpGroupID(cmd, "podman-mount-spacewalk", "podman")
 	_ = utils.AddFlagToHelpGroupID(cmd, "podman-mount-www", "podman")
+	_ = utils.AddFlagToHelpGroupID(cmd, "podman-memory", "podman")
+	_ = utils.AddFlagToHelpGroupID(cmd, "podman-cpus", "podman")
 }
 
 // EnablePodmanSocket enables the podman socket.
 func EnablePodmanSocket() error {
-	err := utils.RunCmd("systemctl", "enable", "--now", "podman.socket")
+	err := utils.RunCmd("systemctl", SystemctlArgs("enable", "--now", "podman.socket")...)
 	if err != nil {
-		return fmt.Errorf(L("failed to enable podman.socket unit: %s"), err)
+		return utils.Errorf(err, L("failed to enable podman.socket unit: %s"))
 	}
 	return err
 }
@@ -93,7 +151,7 @@ func RunContainer(name string, image string, extraArgs []string, cmd []string) e
 
 	err := utils.RunCmdStdMapping(zerolog.DebugLevel, "podman", podmanArgs...)
 	if err != nil {
-		return fmt.Errorf(L("failed to run %s container: %s"), name, err)
+		return utils.Errorf(err, L("failed to run %s container: %s"), name)
 	}
 
 	return nil
@@ -171,11 +229,11 @@ func LinkVolumes(mountFlags *PodmanMountFlags) error {
 			}
 			baseFolder := path.Join(graphRoot, "volumes")
 			if err := utils.RunCmd("mkdir", "-p", baseFolder); err != nil {
-				return fmt.Errorf(L("failed to create volumes folder %s: %s"), baseFolder, err)
+				return utils.Errorf(err, L("failed to create volumes folder %s: %s"), baseFolder)
 			}
 
 			if err := utils.RunCmd("ln", "-s", value, volumePath); err != nil {
-				return fmt.Errorf(L("failed to link volume folder %s to %s: %s"), value, volumePath, err)
+				return utils.Errorf(err, L("failed to link volume folder %s to %s: %s"), value, volumePath)
 			}
 		}
 	}
@@ -185,34 +243,117 @@ func LinkVolumes(mountFlags *PodmanMountFlags) error {
 func getGraphRoot() (string, error) {
 	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", "system", "info", "--format", "{{ .Store.GraphRoot }}")
 	if err != nil {
-		return "", fmt.Errorf(L("failed to get podman's volumes folder: %s"), err)
+		return "", utils.Errorf(err, L("failed to get podman's volumes folder: %s"))
 	}
 	return strings.TrimSpace(string(out)), nil
 }
 
+// InspectLabelPrefix is the prefix of the image labels providing the values to inspect.
+//
+// The labels are named after the inspected variables, for instance org.uyuni-project.inspect.image_pg_version.
+// The inspect container is not needed for the images having these labels.
+const InspectLabelPrefix = "org.uyuni-project.inspect."
+
 // Inspect check values on a given image and deploy.
+//
+// The values are read from the image labels and the volumes if possible, the inspect container is only
+// started as a fallback.
 func Inspect(serverImage string, pullPolicy string) (map[string]string, error) {
-	scriptDir, err := os.MkdirTemp("", "mgradm-*")
-	defer os.RemoveAll(scriptDir)
+	inspectedHostValues, err := utils.InspectHost()
 	if err != nil {
-		return map[string]string{}, fmt.Errorf(L("failed to create temporary directory %s"), err)
+		return map[string]string{}, utils.Errorf(err, L("cannot inspect host values: %s"))
 	}
 
-	inspectedHostValues, err := utils.InspectHost()
+	pullArgs := GetPullArgs(inspectedHostValues)
+
+	preparedImage, err := PrepareImage(serverImage, pullPolicy, pullArgs...)
 	if err != nil {
-		return map[string]string{}, fmt.Errorf(L("cannot inspect host values: %s"), err)
+		return map[string]string{}, err
 	}
 
-	pullArgs := []string{}
-	_, scc_user_exist := inspectedHostValues["host_scc_username"]
-	_, scc_user_password := inspectedHostValues["host_scc_password"]
-	if scc_user_exist && scc_user_password {
-		pullArgs = append(pullArgs, "--creds", inspectedHostValues["host_scc_username"]+":"+inspectedHostValues["host_scc_password"])
+	if imageValues := inspectImageLabels(preparedImage); imageValues != nil {
+		log.Debug().Msgf("Using the labels of %s instead of running the inspect container", preparedImage)
+		values := inspectVolumes()
+		for key, value := range imageValues {
+			values[key] = value
+		}
+		return values, nil
 	}
+	return inspectContainer(preparedImage)
+}
 
-	preparedImage, err := PrepareImage(serverImage, pullPolicy, pullArgs...)
+type imageMetadata struct {
+	Architecture string
+	Labels       map[string]string
+}
+
+// inspectImageLabels returns the image values from its labels or nil if the image doesn't provide them.
+func inspectImageLabels(image string) map[string]string {
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", "image", "inspect", "--format", "json", image)
 	if err != nil {
-		return map[string]string{}, err
+		log.Debug().Err(err).Msgf("Failed to inspect image %s", image)
+		return nil
+	}
+	var metadata []imageMetadata
+	if err := json.Unmarshal(out, &metadata); err != nil || len(metadata) != 1 {
+		log.Debug().Err(err).Msgf("Failed to parse the metadata of image %s", image)
+		return nil
+	}
+	return imageValuesFromLabels(metadata[0])
+}
+
+func imageValuesFromLabels(metadata imageMetadata) map[string]string {
+	values := map[string]string{}
+	for _, name := range []string{"uyuni_release", "suse_manager_release", "image_pg_version", "architecture"} {
+		if value := metadata.Labels[InspectLabelPrefix+name]; value != "" {
+			values[name] = value
+		}
+	}
+	if values["image_pg_version"] == "" || values["uyuni_release"] == "" && values["suse_manager_release"] == "" {
+		return nil
+	}
+	if values["architecture"] == "" && metadata.Architecture != "" {
+		values["architecture"] = utils.Architecture(metadata.Architecture)
+	}
+	return values
+}
+
+// inspectVolumes reads the deployment values from the server volumes.
+func inspectVolumes() map[string]string {
+	// Missing volumes simply result in missing values, like with the inspect container
+	return utils.InspectFiles("", getVolumeMountPoint("etc-rhn"), getVolumeMountPoint("var-pgsql"))
+}
+
+func getVolumeMountPoint(volume string) string {
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", "volume", "inspect", "--format", "{{.Mountpoint}}", volume)
+	if err != nil {
+		log.Debug().Err(err).Msgf("No %s volume", volume)
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// VolumeMountPoints returns the host mount points of the podman volumes indexed by their name.
+func VolumeMountPoints() (map[string]string, error) {
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", "volume", "ls", "--format", "{{.Name}} {{.Mountpoint}}")
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the volumes: %s"))
+	}
+	mountPoints := map[string]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if parts := strings.SplitN(strings.TrimSpace(line), " ", 2); len(parts) == 2 {
+			mountPoints[parts[0]] = parts[1]
+		}
+	}
+	return mountPoints, nil
+}
+
+// inspectContainer runs the inspect script in a container of the image.
+func inspectContainer(preparedImage string) (map[string]string, error) {
+	scriptDir, err := os.MkdirTemp("", "mgradm-*")
+	defer os.RemoveAll(scriptDir)
+	if err != nil {
+		return map[string]string{}, utils.Errorf(err, L("failed to create temporary directory %s"))
 	}
 
 	if err := utils.GenerateInspectContainerScript(scriptDir); err != nil {
@@ -232,7 +373,7 @@ func Inspect(serverImage string, pullPolicy string) (map[string]string, error) {
 
 	inspectResult, err := utils.ReadInspectData(scriptDir)
 	if err != nil {
-		return map[string]string{}, fmt.Errorf(L("cannot inspect data. %s"), err)
+		return map[string]string{}, utils.Errorf(err, L("cannot inspect data. %s"))
 	}
 
 	return inspectResult, err
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestCheckLimits(t *testing.T) {
+	data := []struct {
+		memory string
+		cpus   string
+		valid  bool
+	}{
+		{"", "", true},
+		{"16g", "4", true},
+		{"512M", "0.5", true},
+		{"1073741824", "", true},
+		{"16 g", "", false},
+		{"16gb", "", false},
+		{"", "1,5", false},
+		{"", "-1", false},
+	}
+	for _, test := range data {
+		err := CheckLimits(test.memory, test.cpus)
+		if (err == nil) != test.valid {
+			t.Errorf("%s/%s: expected valid %v, got error %v", test.memory, test.cpus, test.valid, err)
+		}
+	}
+}
+
+func TestLimitsArgs(t *testing.T) {
+	if args := LimitsArgs("", ""); len(args) != 0 {
+		t.Errorf("expected no argument, got %v", args)
+	}
+	expected := "--memory 16g --cpus 4"
+	if actual := strings.Join(LimitsArgs("16g", "4"), " "); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestImageValuesFromLabels(t *testing.T) {
+	metadata := imageMetadata{
+		Architecture: "amd64",
+		Labels: map[string]string{
+			InspectLabelPrefix + "uyuni_release":    "2024.07",
+			InspectLabelPrefix + "image_pg_version": "16",
+			"org.opencontainers.image.version":      "5.0",
+		},
+	}
+	values := imageValuesFromLabels(metadata)
+	expected := "2024.07 16 x86_64"
+	if actual := values["uyuni_release"] + " " + values["image_pg_version"] + " " + values["architecture"]; actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	// The PostgreSQL version is mandatory to skip the inspect container
+	delete(metadata.Labels, InspectLabelPrefix+"image_pg_version")
+	if values := imageValuesFromLabels(metadata); values != nil {
+		t.Errorf("expected no value without the PostgreSQL version, got %v", values)
+	}
+}
+
+func TestVolumeMountPoints(t *testing.T) {
+	runner := testutils.NewFakeRunner(t, testutils.Interaction{
+		Command: "podman",
+		Args:    []string{"volume", "ls", "--format", "{{.Name}} {{.Mountpoint}}"},
+		Stdout:  "var-pgsql /var/lib/containers/storage/volumes/var-pgsql/_data\netc-rhn /srv/etc-rhn\n",
+	})
+
+	mountPoints, err := VolumeMountPoints()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if mountPoints["var-pgsql"] != "/var/lib/containers/storage/volumes/var-pgsql/_data" ||
+		mountPoints["etc-rhn"] != "/srv/etc-rhn" || len(mountPoints) != 2 {
+		t.Errorf("unexpected mount points: %v", mountPoints)
+	}
+	runner.AssertDone()
+}
@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// Image signature verification policies.
+const (
+	VerifyEnforce = "enforce"
+	VerifyWarn    = "warn"
+	VerifyOff     = "off"
+)
+
+// VerifyFlags are the flags defining how the signatures of the images are verified.
+type VerifyFlags struct {
+	Policy string
+	Keys   []string
+}
+
+// imageVerification is the verification configuration used when preparing the images.
+var imageVerification = VerifyFlags{Policy: VerifyOff}
+
+// AddVerifyFlags adds the image signature verification flags to a root command.
+func AddVerifyFlags(cmd *cobra.Command, flags *VerifyFlags) {
+	cmd.PersistentFlags().StringVar(&flags.Policy, "verify", VerifyOff,
+		L("image signature verification policy. The value can be one of 'enforce', 'warn' or 'off'"))
+	cmd.PersistentFlags().StringSliceVar(&flags.Keys, "verify-key", []string{},
+		L(`trusted public key to verify the image signatures with. Can be repeated.
+Keys with the .pub extension are cosign keys, the others are GPG keys`))
+}
+
+// SetVerify configures the signature verification of the images prepared by the next calls to PrepareImage.
+func SetVerify(flags *VerifyFlags) error {
+	policy := strings.ToLower(flags.Policy)
+	switch policy {
+	case "", VerifyOff:
+		imageVerification = VerifyFlags{Policy: VerifyOff}
+		return nil
+	case VerifyEnforce, VerifyWarn:
+	default:
+		return fmt.Errorf(L("invalid verify policy %s, it can be one of 'enforce', 'warn' or 'off'"), flags.Policy)
+	}
+
+	if len(flags.Keys) == 0 {
+		return errors.New(L("at least one trusted key is required to verify the image signatures"))
+	}
+	keys := []string{}
+	for _, key := range flags.Keys {
+		absKey, err := filepath.Abs(key)
+		if err != nil {
+			return err
+		}
+		if !utils.FileExists(absKey) {
+			return fmt.Errorf(L("trusted key %s does not exist"), key)
+		}
+		keys = append(keys, absKey)
+	}
+	imageVerification = VerifyFlags{Policy: policy, Keys: keys}
+	log.Debug().Msgf("Verifying the image signatures with the %s policy", policy)
+	return nil
+}
+
+// splitKeys sorts the trusted keys into cosign and GPG keys.
+func splitKeys(keys []string) (cosignKeys []string, gpgKeys []string) {
+	for _, key := range keys {
+		if filepath.Ext(key) == ".pub" {
+			cosignKeys = append(cosignKeys, key)
+		} else {
+			gpgKeys = append(gpgKeys, key)
+		}
+	}
+	return
+}
+
+// handleVerifyError returns the verification error for the enforce policy and only logs it for the warn one.
+func handleVerifyError(err error) error {
+	if err == nil || imageVerification.Policy != VerifyWarn {
+		return err
+	}
+	log.Warn().Err(err).Msg(L("Image signature verification failed"))
+	return nil
+}
+
+// VerifyImage checks the cosign signature of an image against the trusted keys.
+//
+// The GPG signatures can only be checked when pulling the image and are ignored.
+func VerifyImage(image string) error {
+	if imageVerification.Policy == VerifyOff {
+		return nil
+	}
+	cosignKeys, gpgKeys := splitKeys(imageVerification.Keys)
+	if len(cosignKeys) == 0 {
+		if len(gpgKeys) > 0 {
+			log.Debug().Msgf("Image %s signature has been verified when pulling it with GPG keys", image)
+		}
+		return nil
+	}
+	return handleVerifyError(verifyCosign(image, cosignKeys))
+}
+
+func verifyCosign(image string, keys []string) error {
+	if _, err := exec.LookPath("cosign"); err != nil {
+		return errors.New(L("install cosign to verify the image signatures"))
+	}
+	for _, key := range keys {
+		log.Debug().Msgf("Verifying %s signature with key %s", image, key)
+		if _, err := utils.RunCmdOutput(zerolog.DebugLevel, "cosign", "verify", "--key", key, image); err == nil {
+			log.Info().Msgf(L("Image %[1]s signature verified with key %[2]s"), image, key)
+			return nil
+		}
+	}
+	return fmt.Errorf(L("no trusted key matches the signature of image %s"), image)
+}
+
+// signaturePolicy returns the containers signature policy requiring the images to be signed by one of the GPG keys.
+func signaturePolicy(gpgKeys []string) ([]byte, error) {
+	requirement := []map[string]interface{}{
+		{"type": "signedBy", "keyType": "GPGKeys", "keyPaths": gpgKeys},
+	}
+	policy := map[string]interface{}{
+		"default": requirement,
+		"transports": map[string]interface{}{
+			"docker": map[string]interface{}{"": requirement},
+		},
+	}
+	return json.MarshalIndent(policy, "", "  ")
+}
+
+// withSignaturePolicy calls the pull function with the signature policy arguments for the GPG keys.
+//
+// With the warn policy, the pull is tried again without verification if it failed.
+func withSignaturePolicy(pull func(args ...string) error) error {
+	_, gpgKeys := splitKeys(imageVerification.Keys)
+	if imageVerification.Policy == VerifyOff || len(gpgKeys) == 0 {
+		return pull()
+	}
+
+	policy, err := signaturePolicy(gpgKeys)
+	if err != nil {
+		return err
+	}
+	policyFile, err := os.CreateTemp("", "uyuni-policy-*.json")
+	if err != nil {
+		return utils.Errorf(err, L("failed to write the signature policy: %s"))
+	}
+	defer os.Remove(policyFile.Name())
+	if _, err := policyFile.Write(policy); err != nil {
+		policyFile.Close()
+		return utils.Errorf(err, L("failed to write the signature policy: %s"))
+	}
+	policyFile.Close()
+
+	err = pull("--signature-policy", policyFile.Name())
+	if err != nil && imageVerification.Policy == VerifyWarn {
+		log.Warn().Err(err).Msg(L("Pulling with image signature verification failed, pulling without it"))
+		return pull()
+	}
+	return err
+}
@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package podman
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSetVerify(t *testing.T) {
+	defer func() { imageVerification = VerifyFlags{Policy: VerifyOff} }()
+
+	key := filepath.Join(t.TempDir(), "cosign.pub")
+	if err := os.WriteFile(key, []byte("key"), 0600); err != nil {
+		t.Fatalf("failed to write key: %s", err)
+	}
+
+	invalid := []VerifyFlags{
+		{Policy: "strict", Keys: []string{key}},
+		{Policy: VerifyEnforce},
+		{Policy: VerifyWarn, Keys: []string{key + ".missing"}},
+	}
+	for _, flags := range invalid {
+		if err := SetVerify(&flags); err == nil {
+			t.Errorf("expected an error for %v", flags)
+		}
+	}
+
+	if err := SetVerify(&VerifyFlags{Policy: "Enforce", Keys: []string{key}}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if imageVerification.Policy != VerifyEnforce || !reflect.DeepEqual(imageVerification.Keys, []string{key}) {
+		t.Errorf("unexpected verification configuration: %v", imageVerification)
+	}
+
+	if err := SetVerify(&VerifyFlags{Policy: VerifyOff, Keys: []string{key}}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := VerifyImage("server:latest"); err != nil {
+		t.Errorf("verification should be skipped: %s", err)
+	}
+}
+
+func TestSplitKeys(t *testing.T) {
+	cosignKeys, gpgKeys := splitKeys([]string{"/keys/cosign.pub", "/keys/suse.asc", "/keys/uyuni.gpg"})
+	if !reflect.DeepEqual(cosignKeys, []string{"/keys/cosign.pub"}) {
+		t.Errorf("unexpected cosign keys: %v", cosignKeys)
+	}
+	if !reflect.DeepEqual(gpgKeys, []string{"/keys/suse.asc", "/keys/uyuni.gpg"}) {
+		t.Errorf("unexpected GPG keys: %v", gpgKeys)
+	}
+}
+
+func TestSignaturePolicy(t *testing.T) {
+	data, err := signaturePolicy([]string{"/keys/suse.asc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var policy struct {
+		Default    []map[string]interface{}
+		Transports map[string]map[string][]map[string]interface{}
+	}
+	if err := json.Unmarshal(data, &policy); err != nil {
+		t.Fatalf("invalid policy: %s", err)
+	}
+	requirement := policy.Transports["docker"][""][0]
+	if requirement["type"] != "signedBy" || requirement["keyType"] != "GPGKeys" {
+		t.Errorf("unexpected requirement: %v", requirement)
+	}
+	if len(policy.Default) != 1 || policy.Default[0]["type"] != "signedBy" {
+		t.Errorf("unexpected default requirement: %v", policy.Default)
+	}
+}
+
+func TestWithSignaturePolicy(t *testing.T) {
+	defer func() { imageVerification = VerifyFlags{Policy: VerifyOff} }()
+
+	var calls [][]string
+	failing := func(args ...string) error {
+		calls = append(calls, args)
+		if len(args) > 0 {
+			return errors.New("signature rejected")
+		}
+		return nil
+	}
+
+	imageVerification = VerifyFlags{Policy: VerifyEnforce, Keys: []string{"/keys/suse.asc"}}
+	if err := withSignaturePolicy(failing); err == nil {
+		t.Error("expected the pull error with the enforce policy")
+	}
+	if len(calls) != 1 || calls[0][0] != "--signature-policy" {
+		t.Errorf("unexpected pull calls: %v", calls)
+	}
+
+	calls = nil
+	imageVerification.Policy = VerifyWarn
+	if err := withSignaturePolicy(failing); err != nil {
+		t.Errorf("unexpected error with the warn policy: %s", err)
+	}
+	if len(calls) != 2 || len(calls[1]) != 0 {
+		t.Errorf("expected a second pull without policy: %v", calls)
+	}
+
+	calls = nil
+	imageVerification = VerifyFlags{Policy: VerifyEnforce, Keys: []string{"/keys/cosign.pub"}}
+	if err := withSignaturePolicy(failing); err != nil || len(calls) != 1 || len(calls[0]) != 0 {
+		t.Errorf("no signature policy expected for cosign keys: %v, %v", err, calls)
+	}
+}
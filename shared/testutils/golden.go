@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package testutils
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+// UpdateGoldenEnv is the environment variable to set to rewrite the golden files with the actual values.
+//
+// For example: UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// AssertGolden compares the actual content with the golden file at testdata/name.
+func AssertGolden(t testing.TB, name string, actual []byte) {
+	t.Helper()
+	goldenPath := path.Join("testdata", name)
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(path.Dir(goldenPath), 0755); err != nil {
+			t.Fatalf("failed to create the golden file folder: %s", err)
+		}
+		if err := os.WriteFile(goldenPath, actual, 0644); err != nil {
+			t.Fatalf("failed to update golden file %s: %s", goldenPath, err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s, set %s to create it: %s", goldenPath, UpdateGoldenEnv, err)
+	}
+	if string(expected) != string(actual) {
+		t.Errorf("%s differs from the golden file, set %s to update it.\nExpected:\n%s\nActual:\n%s",
+			name, UpdateGoldenEnv, expected, actual)
+	}
+}
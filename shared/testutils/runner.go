@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package testutils provides test doubles for the code running the podman, kubectl or helm tools.
+package testutils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// Interaction is a command expected to be run with its outputs.
+type Interaction struct {
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args,omitempty"`
+	Stdout  string   `yaml:"stdout,omitempty"`
+	Stderr  string   `yaml:"stderr,omitempty"`
+	// ExitCode is the exit code of the command: any other value than 0 makes it fail.
+	ExitCode int `yaml:"exitCode,omitempty"`
+}
+
+// Cassette is the recording of the commands run by a test.
+type Cassette struct {
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+// CommandError is the error of a fake command exiting with a code different from 0.
+type CommandError struct {
+	Code int
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the exit code of the failed command.
+func (e *CommandError) ExitCode() int {
+	return e.Code
+}
+
+// FakeRunner replays the interactions instead of running the commands.
+//
+// The interactions are matched in order: the test fails if a command is not the next expected one.
+type FakeRunner struct {
+	t            testing.TB
+	mutex        sync.Mutex
+	interactions []Interaction
+	// Calls records the command lines run.
+	Calls []string
+}
+
+// NewFakeRunner creates a runner replaying the interactions and installs it for the duration of the test.
+func NewFakeRunner(t testing.TB, interactions ...Interaction) *FakeRunner {
+	runner := &FakeRunner{t: t, interactions: interactions}
+	t.Cleanup(utils.SetCommandRunner(runner.run))
+	return runner
+}
+
+// LoadCassette creates a fake runner replaying the interactions stored in a YAML file.
+func LoadCassette(t testing.TB, path string) *FakeRunner {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read cassette %s: %s", path, err)
+	}
+	var cassette Cassette
+	if err := yaml.Unmarshal(data, &cassette); err != nil {
+		t.Fatalf("failed to parse cassette %s: %s", path, err)
+	}
+	return NewFakeRunner(t, cassette.Interactions...)
+}
+
+func (r *FakeRunner) run(command string, args []string, stdout io.Writer, stderr io.Writer) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	commandLine := strings.TrimSpace(command + " " + strings.Join(args, " "))
+	r.Calls = append(r.Calls, commandLine)
+	if len(r.interactions) == 0 {
+		r.t.Errorf("unexpected command: %s", commandLine)
+		return &CommandError{Code: 127}
+	}
+
+	interaction := r.interactions[0]
+	expected := strings.TrimSpace(interaction.Command + " " + strings.Join(interaction.Args, " "))
+	if expected != commandLine {
+		r.t.Errorf("unexpected command:\n  got:      %s\n  expected: %s", commandLine, expected)
+		return &CommandError{Code: 127}
+	}
+	r.interactions = r.interactions[1:]
+
+	if _, err := io.WriteString(stdout, interaction.Stdout); err != nil {
+		return err
+	}
+	if stderr != nil {
+		if _, err := io.WriteString(stderr, interaction.Stderr); err != nil {
+			return err
+		}
+	}
+	if interaction.ExitCode != 0 {
+		return &CommandError{Code: interaction.ExitCode}
+	}
+	return nil
+}
+
+// AssertDone fails the test if some interactions have not been replayed.
+func (r *FakeRunner) AssertDone() {
+	r.t.Helper()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, interaction := range r.interactions {
+		r.t.Errorf("expected command not run: %s %s", interaction.Command, strings.Join(interaction.Args, " "))
+	}
+}
+
+// Recorder runs the commands and records them to write a cassette.
+//
+// This helps writing the cassettes of a test on a machine with the real tools.
+type Recorder struct {
+	mutex    sync.Mutex
+	cassette Cassette
+	runner   utils.CommandRunner
+}
+
+// NewRecorder creates a recorder running the commands with the given runner.
+func NewRecorder(runner utils.CommandRunner) *Recorder {
+	return &Recorder{runner: runner}
+}
+
+// Run executes the command and records it. It can be passed to utils.SetCommandRunner.
+func (r *Recorder) Run(command string, args []string, stdout io.Writer, stderr io.Writer) error {
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	err := r.runner(command, args, io.MultiWriter(stdout, &out), errorWriter(stderr, &errOut))
+
+	interaction := Interaction{
+		Command: command,
+		Args:    args,
+		Stdout:  out.String(),
+		Stderr:  errOut.String(),
+	}
+	if exitErr, ok := err.(interface{ ExitCode() int }); ok {
+		interaction.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		interaction.ExitCode = 127
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	return err
+}
+
+func errorWriter(stderr io.Writer, buf *bytes.Buffer) io.Writer {
+	if stderr == nil {
+		return buf
+	}
+	return io.MultiWriter(stderr, buf)
+}
+
+// Save writes the recorded interactions to a cassette file.
+func (r *Recorder) Save(path string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	data, err := yaml.Marshal(r.cassette)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package testutils
+
+import (
+	"errors"
+	"io"
+	"path"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func TestFakeRunner(t *testing.T) {
+	runner := NewFakeRunner(t,
+		Interaction{Command: "podman", Args: []string{"ps", "-q"}, Stdout: "1234\n"},
+		Interaction{Command: "systemctl", Args: []string{"start", "uyuni-server"}, ExitCode: 3},
+	)
+
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "podman", "ps", "-q")
+	if err != nil || string(out) != "1234\n" {
+		t.Errorf("unexpected result: %s, %s", out, err)
+	}
+
+	err = utils.RunCmd("systemctl", "start", "uyuni-server")
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.ExitCode() != 3 {
+		t.Errorf("expected exit code 3, got %v", err)
+	}
+
+	runner.AssertDone()
+	if len(runner.Calls) != 2 {
+		t.Errorf("expected 2 calls, got %v", runner.Calls)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder(func(command string, args []string, stdout io.Writer, stderr io.Writer) error {
+		_, err := io.WriteString(stdout, "recorded output")
+		return err
+	})
+	restore := utils.SetCommandRunner(recorder.Run)
+	if _, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "get", "pods"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	restore()
+
+	cassettePath := path.Join(t.TempDir(), "cassette.yaml")
+	if err := recorder.Save(cassettePath); err != nil {
+		t.Fatalf("failed to save the cassette: %s", err)
+	}
+
+	runner := LoadCassette(t, cassettePath)
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "get", "pods")
+	if err != nil || string(out) != "recorded output" {
+		t.Errorf("unexpected replayed result: %s, %s", out, err)
+	}
+	runner.AssertDone()
+}
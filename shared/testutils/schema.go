@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package testutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// ChartSchemaEnv is the environment variable pointing to the values.schema.json file of the server helm chart.
+//
+// The helm values tests are skipped if it is not set.
+const ChartSchemaEnv = "UYUNI_CHART_SCHEMA"
+
+// ValidateValuesSchema checks helm values against the JSON schema of a chart.
+//
+// Only the type, properties, additionalProperties and enum keywords are supported.
+func ValidateValuesSchema(t testing.TB, schemaPath string, values map[string]interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(schemaPath)
+	if err != nil {
+		t.Fatalf("failed to read schema %s: %s", schemaPath, err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("failed to parse schema %s: %s", schemaPath, err)
+	}
+	for _, violation := range validateSchema(schema, values, "") {
+		t.Errorf("invalid helm values: %s", violation)
+	}
+}
+
+func validateSchema(schema map[string]interface{}, value interface{}, path string) []string {
+	violations := []string{}
+	if schemaType, ok := schema["type"]; ok && !matchesType(schemaType, value) {
+		return append(violations, fmt.Sprintf("%s: %v does not match type %v", displayPath(path), value, schemaType))
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && !inEnum(enum, value) {
+		violations = append(violations, fmt.Sprintf("%s: %v is not one of %v", displayPath(path), value, enum))
+	}
+
+	object, isObject := toObject(value)
+	if !isObject {
+		return violations
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	for key, child := range object {
+		childPath := strings.TrimPrefix(path+"."+key, ".")
+		if propertySchema, ok := properties[key].(map[string]interface{}); ok {
+			violations = append(violations, validateSchema(propertySchema, child, childPath)...)
+		} else if additional, ok := schema["additionalProperties"]; ok {
+			if allowed, ok := additional.(bool); ok && !allowed {
+				violations = append(violations, fmt.Sprintf("%s: unknown value", childPath))
+			} else if additionalSchema, ok := additional.(map[string]interface{}); ok {
+				violations = append(violations, validateSchema(additionalSchema, child, childPath)...)
+			}
+		}
+	}
+	return violations
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "<root>"
+	}
+	return path
+}
+
+// toObject converts the maps coming from JSON or YAML parsing to a map with string keys.
+func toObject(value interface{}) (map[string]interface{}, bool) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		return typed, true
+	case map[interface{}]interface{}:
+		object := map[string]interface{}{}
+		for key, child := range typed {
+			object[fmt.Sprint(key)] = child
+		}
+		return object, true
+	}
+	return nil, false
+}
+
+func matchesType(schemaType interface{}, value interface{}) bool {
+	if types, ok := schemaType.([]interface{}); ok {
+		for _, single := range types {
+			if matchesType(single, value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch schemaType {
+	case "object":
+		_, ok := toObject(value)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		switch number := value.(type) {
+		case int, int64:
+			return true
+		case float64:
+			return number == float64(int64(number))
+		}
+		return false
+	case "number":
+		switch value.(type) {
+		case int, int64, float64:
+			return true
+		}
+		return false
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package testutils
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+	"type": "object",
+	"additionalProperties": false,
+	"properties": {
+		"fqdn": {"type": "string"},
+		"pullPolicy": {"type": "string", "enum": ["Always", "IfNotPresent", "Never"]},
+		"replicas": {"type": "integer"},
+		"images": {
+			"type": "object",
+			"additionalProperties": {"type": "string"}
+		}
+	}
+}`
+
+func TestValidateSchema(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(testSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := map[string]interface{}{
+		"fqdn":       "uyuni.example.com",
+		"pullPolicy": "Always",
+		"replicas":   int64(1),
+		"images":     map[string]interface{}{"server": "server:2024.07"},
+	}
+	if violations := validateSchema(schema, valid, ""); len(violations) != 0 {
+		t.Errorf("unexpected violations: %v", violations)
+	}
+
+	invalid := map[string]interface{}{
+		"fqdn":       true,
+		"pullPolicy": "Sometimes",
+		"unknown":    "value",
+		"images":     map[interface{}]interface{}{"server": int64(1)},
+	}
+	violations := validateSchema(schema, invalid, "")
+	sort.Strings(violations)
+	expected := []string{
+		"fqdn: true does not match type string",
+		"images.server: 1 does not match type string",
+		"pullPolicy: Sometimes is not one of [Always IfNotPresent Never]",
+		"unknown: unknown value",
+	}
+	if strings.Join(violations, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected violations:\n%s", strings.Join(violations, "\n"))
+	}
+}
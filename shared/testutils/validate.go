@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package testutils
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ValidateSystemdUnit checks a systemd service file with systemd-analyze verify, if installed.
+//
+// The container tools are not needed on the machine running the tests: the errors about them are ignored.
+func ValidateSystemdUnit(t testing.TB, name string, content []byte) {
+	t.Helper()
+	if _, err := exec.LookPath("systemd-analyze"); err != nil {
+		t.Log("systemd-analyze not installed, skipping the unit verification")
+		return
+	}
+	if !strings.HasSuffix(name, ".service") {
+		name += ".service"
+	}
+	dir := t.TempDir()
+	unitPath := path.Join(dir, name)
+	if err := os.WriteFile(unitPath, content, 0644); err != nil {
+		t.Fatalf("failed to write %s: %s", unitPath, err)
+	}
+
+	// The units depended on are not installed: verify again with stubs of them next to the unit
+	// until all of them are found.
+	out := verifyUnit(unitPath)
+	for stubbed := map[string]bool{}; ; {
+		added := false
+		for _, match := range missingUnitRegex.FindAllStringSubmatch(out, -1) {
+			if stubbed[match[1]] {
+				continue
+			}
+			stub := "[Service]\nExecStart=/bin/true\n"
+			if err := os.WriteFile(path.Join(dir, match[1]), []byte(stub), 0644); err != nil {
+				t.Fatalf("failed to write the %s stub: %s", match[1], err)
+			}
+			stubbed[match[1]] = true
+			added = true
+		}
+		if !added {
+			break
+		}
+		out = verifyUnit(unitPath)
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" || isMissingToolError(line) {
+			continue
+		}
+		t.Errorf("systemd-analyze verify: %s", line)
+	}
+}
+
+var missingUnitRegex = regexp.MustCompile(`Unit ([[:alnum:]@._-]+\.service) not found`)
+
+func verifyUnit(unitPath string) string {
+	out, _ := exec.Command("systemd-analyze", "verify", "--man=no", unitPath).CombinedOutput()
+	return string(out)
+}
+
+func isMissingToolError(line string) bool {
+	for _, tool := range []string{"/usr/bin/podman", "/usr/bin/docker"} {
+		if strings.Contains(line, "Command "+tool+" is not executable") {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateShellScript checks the syntax of a shell script with bash, if installed.
+func ValidateShellScript(t testing.TB, content []byte) {
+	t.Helper()
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Log("bash not installed, skipping the script syntax check")
+		return
+	}
+	cmd := exec.Command("bash", "-n")
+	cmd.Stdin = bytes.NewReader(content)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("invalid shell script: %s", out)
+	}
+}
+
+// ValidateKubernetesYAML checks that each document of the YAML content is a kubernetes object.
+func ValidateKubernetesYAML(t testing.TB, content []byte) {
+	t.Helper()
+	for i, document := range strings.Split(string(content), "\n---") {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+		var object map[string]interface{}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			t.Errorf("invalid YAML document %d: %s", i, err)
+			continue
+		}
+		for _, field := range []string{"apiVersion", "kind", "metadata"} {
+			if _, ok := object[field]; !ok {
+				t.Errorf("YAML document %d has no %s", i, field)
+			}
+		}
+	}
+}
+
+// ValidateCloudConfig checks that the content is a cloud-init cloud-config YAML document.
+func ValidateCloudConfig(t testing.TB, content []byte) {
+	t.Helper()
+	if !strings.HasPrefix(string(content), "#cloud-config\n") {
+		t.Error("cloud-config user data has to start with a #cloud-config line")
+	}
+	var object map[string]interface{}
+	if err := yaml.Unmarshal(content, &object); err != nil {
+		t.Errorf("invalid cloud-config YAML: %s", err)
+	}
+}
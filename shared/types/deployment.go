@@ -9,11 +9,20 @@ type VolumeMount struct {
 	Name      string `json:"name,omitempty"`
 }
 
+// EnvVar type used for mapping container environment variables.
+type EnvVar struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
 // Container type used for mapping pod definition structure.
 type Container struct {
-	Name         string        `json:"name,omitempty"`
-	Image        string        `json:"image,omitempty"`
-	VolumeMounts []VolumeMount `json:"volumeMounts,omitempty"`
+	Name            string        `json:"name,omitempty"`
+	Image           string        `json:"image,omitempty"`
+	ImagePullPolicy string        `json:"imagePullPolicy,omitempty"`
+	Command         []string      `json:"command,omitempty"`
+	Env             []EnvVar      `json:"env,omitempty"`
+	VolumeMounts    []VolumeMount `json:"volumeMounts,omitempty"`
 }
 
 // PersistentVolumeClaim type used for mapping Volume structure.
@@ -60,3 +69,30 @@ type Deployment struct {
 	APIVersion string `json:"apiVersion,omitempty"`
 	Spec       *Spec  `json:"spec,omitempty"`
 }
+
+// ObjectMeta type for mapping the kubernetes objects metadata.
+type ObjectMeta struct {
+	Name      string            `json:"name,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// PodTemplate type for mapping the pod template of a Job.
+type PodTemplate struct {
+	Metadata *ObjectMeta `json:"metadata,omitempty"`
+	Spec     *Spec       `json:"spec,omitempty"`
+}
+
+// JobSpec type for mapping Job structure.
+type JobSpec struct {
+	BackoffLimit *int        `json:"backoffLimit,omitempty"`
+	Template     PodTemplate `json:"template"`
+}
+
+// Job type can store k8s job data.
+type Job struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Metadata   *ObjectMeta `json:"metadata,omitempty"`
+	Spec       JobSpec     `json:"spec"`
+}
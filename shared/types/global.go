@@ -8,4 +8,7 @@ package types
 type GlobalFlags struct {
 	ConfigPath string
 	LogLevel   string
+	LogFile    string
+	Machine    bool
+	Progress   string
 }
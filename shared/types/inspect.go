@@ -4,6 +4,11 @@
 
 package types
 
+import (
+	"reflect"
+	"strings"
+)
+
 /* InspectData represents CLI command to run in the container
 * and the variable where the output is stored.
  */
@@ -28,3 +33,46 @@ func NewInspectData(variable string, cli string) InspectData {
 		CLI:      cli,
 	}
 }
+
+// InspectResult contains the values inspected in a server image and deployment.
+//
+// The JSON names are the variables of the inspect script.
+type InspectResult struct {
+	UyuniRelease       string `json:"uyuni_release,omitempty" description:"Uyuni version of the image"`
+	SuseManagerRelease string `json:"suse_manager_release,omitempty" description:"SUSE Manager version of the image"`
+	Architecture       string `json:"architecture,omitempty" description:"CPU architecture of the image"`
+	Fqdn               string `json:"fqdn,omitempty" description:"FQDN of the deployed server"`
+	ImagePgVersion     string `json:"image_pg_version,omitempty" description:"PostgreSQL major version in the image"`
+	DbHost             string `json:"db_host,omitempty" description:"Database host configured in the deployed server"`
+	CurrentPgVersion   string `json:"current_pg_version,omitempty" description:"PostgreSQL major version of the deployed database"`
+	RegistrationInfo   string `json:"registration_info,omitempty" description:"Registration status of the deployed server"`
+	SccUsername        string `json:"scc_username,omitempty" description:"SUSE Customer Center user name of the deployed server"`
+	SccPassword        string `json:"scc_password,omitempty" description:"SUSE Customer Center password of the deployed server"`
+}
+
+// NewInspectResult converts the values read from the inspect script output.
+func NewInspectResult(values map[string]string) InspectResult {
+	var result InspectResult
+	fields := reflect.ValueOf(&result).Elem()
+	for i := 0; i < fields.NumField(); i++ {
+		fields.Field(i).SetString(values[InspectResultName(fields.Type().Field(i))])
+	}
+	return result
+}
+
+// Map returns the non empty values indexed by their inspect script variable name.
+func (result InspectResult) Map() map[string]string {
+	values := map[string]string{}
+	fields := reflect.ValueOf(result)
+	for i := 0; i < fields.NumField(); i++ {
+		if value := fields.Field(i).String(); value != "" {
+			values[InspectResultName(fields.Type().Field(i))] = value
+		}
+	}
+	return values
+}
+
+// InspectResultName returns the inspect script variable name of an InspectResult field.
+func InspectResultName(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
@@ -0,0 +1,18 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// DeploymentState is the record of what the tools deployed.
+type DeploymentState struct {
+	Backend     string            `yaml:"backend"`
+	Image       string            `yaml:"image"`
+	Digest      string            `yaml:"digest,omitempty"`
+	Flags       map[string]string `yaml:"flags,omitempty"`
+	Volumes     []string          `yaml:"volumes,omitempty"`
+	Timezone    string            `yaml:"timezone,omitempty"`
+	InstalledAt string            `yaml:"installedAt"`
+	UpdatedAt   string            `yaml:"updatedAt,omitempty"`
+	ToolVersion string            `yaml:"toolVersion"`
+}
@@ -0,0 +1,187 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"gopkg.in/yaml.v2"
+)
+
+// ApprovalUninstallPurge is the operation removing the server and its volumes.
+const ApprovalUninstallPurge = "uninstall-purge"
+
+// ApprovalOperations are the destructive operations which can require a second person approval.
+var ApprovalOperations = []string{ApprovalUninstallPurge}
+
+// defaultApprovalValidity is the duration during which an approval token can be used if not configured.
+const defaultApprovalValidity = time.Hour
+
+// ApprovalPolicy is the configuration of the two-person approval of the destructive operations.
+type ApprovalPolicy struct {
+	Required bool `yaml:"required"`
+	// Operations needing an approval, all of them if empty.
+	Operations []string `yaml:"operations"`
+	// Validity is the duration during which an approval token can be used.
+	Validity time.Duration `yaml:"validity"`
+}
+
+// ApprovalPolicyPath is the file configuring the approval policy.
+//
+// The policy is not read from the user configuration as the user running the operation could disable it.
+const ApprovalPolicyPath = "/etc/uyuni-tools/approval.yaml"
+
+// approvalPolicy is the approval policy configured for the running command.
+var approvalPolicy ApprovalPolicy
+
+// approvalPolicyPath is the policy file, overridden in the tests.
+var approvalPolicyPath = ApprovalPolicyPath
+
+// approvalPolicyOwner is the user ID required to own the policy file, overridden in the tests.
+var approvalPolicyOwner = 0
+
+// approvalsDir is the folder storing the pending approvals, overridden in the tests.
+var approvalsDir = "/var/lib/uyuni-tools/approvals"
+
+// approval is a pending approval stored on the host.
+type approval struct {
+	Operation string `json:"operation"`
+	// Approver is the name of the approver, only for the messages.
+	Approver string `json:"approver"`
+	// ApproverUID is the login ID of the approver, used to check the operation is run by another user.
+	ApproverUID *int      `json:"approver_uid"`
+	Expires     time.Time `json:"expires"`
+}
+
+// readApprovalPolicy reads the approval policy from the system policy file.
+//
+// There is no policy if the file doesn't exist. The file has to be owned by root and not writable by
+// other users: otherwise the policy could be changed without being root.
+func readApprovalPolicy() error {
+	approvalPolicy = ApprovalPolicy{}
+	info, err := os.Stat(approvalPolicyPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return Errorf(err, L("failed to read the approval policy: %s"))
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || int(stat.Uid) != approvalPolicyOwner || info.Mode().Perm()&0022 != 0 {
+		return fmt.Errorf(L("the approval policy %s has to be owned by root and only writable by its owner"),
+			approvalPolicyPath)
+	}
+
+	data, err := os.ReadFile(approvalPolicyPath)
+	if err != nil {
+		return Errorf(err, L("failed to read the approval policy: %s"))
+	}
+	if err := yaml.UnmarshalStrict(data, &approvalPolicy); err != nil {
+		return Errorf(err, L("failed to read the approval policy: %s"))
+	}
+	for _, operation := range approvalPolicy.Operations {
+		if !Contains(ApprovalOperations, operation) {
+			return fmt.Errorf(L("unknown operation %s in the approval configuration"), operation)
+		}
+	}
+	if approvalPolicy.Validity <= 0 {
+		approvalPolicy.Validity = defaultApprovalValidity
+	}
+	return nil
+}
+
+// AddApprovalFlag adds the flag to pass the approval token of a destructive operation.
+func AddApprovalFlag(cmd *cobra.Command) {
+	cmd.Flags().String("approval-token", "",
+		L("Token generated by another user with the approve command, if required by the approval policy"))
+}
+
+// approvalPath returns the file storing an approval: the token itself is not stored on the host.
+func approvalPath(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return path.Join(approvalsDir, hex.EncodeToString(hash[:]))
+}
+
+// Approve creates an approval for an operation and returns the token to pass to the operation.
+func Approve(operation string, validity time.Duration) (string, error) {
+	if !Contains(ApprovalOperations, operation) {
+		return "", fmt.Errorf(L("unknown operation %s"), operation)
+	}
+	if err := os.MkdirAll(approvalsDir, 0700); err != nil {
+		return "", Errorf(err, L("failed to create %[1]s folder: %[2]s"), approvalsDir)
+	}
+
+	token := GetRandomBase64(24)
+	uid := CurrentUID()
+	data, err := json.Marshal(approval{
+		Operation:   operation,
+		Approver:    CurrentUser(),
+		ApproverUID: &uid,
+		Expires:     time.Now().Add(validity),
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(approvalPath(token), data, 0600); err != nil {
+		return "", Errorf(err, L("failed to store the approval: %s"))
+	}
+	return token, nil
+}
+
+// CheckApproval verifies that an operation has been approved by another user if the policy requires it.
+//
+// The approval is consumed and cannot be used for another operation.
+func CheckApproval(operation string, token string) error {
+	if !approvalPolicy.Required ||
+		(len(approvalPolicy.Operations) > 0 && !Contains(approvalPolicy.Operations, operation)) {
+		return nil
+	}
+	if token == "" {
+		return fmt.Errorf(L("the %s operation requires an approval token generated by another user with the approve command"),
+			operation)
+	}
+
+	file := approvalPath(token)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to read approval %s", file)
+		return errors.New(L("invalid approval token"))
+	}
+	// Consume the token even if it doesn't match to prevent retries
+	if err := os.Remove(file); err != nil {
+		return Errorf(err, L("failed to consume the approval: %s"))
+	}
+
+	var pending approval
+	if err := json.Unmarshal(data, &pending); err != nil || pending.ApproverUID == nil {
+		return errors.New(L("invalid approval token"))
+	}
+	if pending.Operation != operation {
+		return fmt.Errorf(L("the approval token is for the %s operation"), pending.Operation)
+	}
+	if time.Now().After(pending.Expires) {
+		return errors.New(L("the approval token has expired"))
+	}
+	if *pending.ApproverUID == CurrentUID() {
+		return errors.New(L("the operation needs to be approved by another user"))
+	}
+	log.Info().Msgf(L("Operation %[1]s approved by %[2]s"), operation, pending.Approver)
+	return nil
+}
+
+// ApprovalValidity returns the configured validity of the approvals.
+func ApprovalValidity() time.Duration {
+	return approvalPolicy.Validity
+}
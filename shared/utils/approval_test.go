@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestCheckApproval(t *testing.T) {
+	approvalsDir = t.TempDir()
+	defer func() {
+		approvalsDir = "/var/lib/uyuni-tools/approvals"
+		approvalPolicy = ApprovalPolicy{}
+	}()
+
+	setLoginUID := func(uid string) {
+		if err := os.WriteFile(loginUIDPath, []byte(uid), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	loginUIDPath = path.Join(t.TempDir(), "loginuid")
+	defer func() { loginUIDPath = "/proc/self/loginuid" }()
+	setLoginUID("1000")
+
+	approvalPolicy = ApprovalPolicy{}
+	if err := CheckApproval(ApprovalUninstallPurge, ""); err != nil {
+		t.Errorf("no approval should be needed without policy: %s", err)
+	}
+
+	approvalPolicy = ApprovalPolicy{Required: true, Validity: time.Hour}
+	if err := CheckApproval(ApprovalUninstallPurge, ""); err == nil {
+		t.Error("expected an error without token")
+	}
+	if err := CheckApproval(ApprovalUninstallPurge, "invalid"); err == nil {
+		t.Error("expected an error with an unknown token")
+	}
+
+	// Approved by the same user
+	token, err := Approve(ApprovalUninstallPurge, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to approve: %s", err)
+	}
+	if err := CheckApproval(ApprovalUninstallPurge, token); err == nil {
+		t.Error("expected an error for an approval by the same user")
+	}
+
+	// The environment variables cannot be used to pretend being another user
+	t.Setenv("SUDO_USER", "someone")
+	token, err = Approve(ApprovalUninstallPurge, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to approve: %s", err)
+	}
+	t.Setenv("SUDO_USER", "")
+	if err := CheckApproval(ApprovalUninstallPurge, token); err == nil {
+		t.Error("expected an error for an approval by the same user with another SUDO_USER")
+	}
+
+	// Approved by another login user
+	token, err = Approve(ApprovalUninstallPurge, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to approve: %s", err)
+	}
+	setLoginUID("1001")
+	if err := CheckApproval(ApprovalUninstallPurge, token); err != nil {
+		t.Errorf("unexpected error for an approval by another user: %s", err)
+	}
+	setLoginUID("1000")
+
+	otherUID := 1001
+	writeApproval := func(pending approval) string {
+		pending.ApproverUID = &otherUID
+		token := GetRandomBase64(24)
+		data, _ := json.Marshal(pending)
+		if err := os.WriteFile(approvalPath(token), data, 0600); err != nil {
+			t.Fatalf("failed to write approval: %s", err)
+		}
+		return token
+	}
+
+	token = writeApproval(approval{Operation: ApprovalUninstallPurge, Approver: "other", Expires: time.Now().Add(time.Hour)})
+	if err := CheckApproval(ApprovalUninstallPurge, token); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := CheckApproval(ApprovalUninstallPurge, token); err == nil {
+		t.Error("the token should be consumed")
+	}
+
+	token = writeApproval(approval{Operation: ApprovalUninstallPurge, Approver: "other", Expires: time.Now().Add(-time.Hour)})
+	if err := CheckApproval(ApprovalUninstallPurge, token); err == nil {
+		t.Error("expected an error for an expired token")
+	}
+
+	token = writeApproval(approval{Operation: "other", Approver: "other", Expires: time.Now().Add(time.Hour)})
+	if err := CheckApproval(ApprovalUninstallPurge, token); err == nil {
+		t.Error("expected an error for a token of another operation")
+	}
+
+	// Approvals without the approver ID cannot be checked
+	token = GetRandomBase64(24)
+	data, _ := json.Marshal(map[string]interface{}{
+		"operation": ApprovalUninstallPurge, "approver": "other", "expires": time.Now().Add(time.Hour),
+	})
+	if err := os.WriteFile(approvalPath(token), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckApproval(ApprovalUninstallPurge, token); err == nil {
+		t.Error("expected an error for an approval without approver ID")
+	}
+}
+
+func TestReadApprovalPolicy(t *testing.T) {
+	approvalPolicyPath = path.Join(t.TempDir(), "approval.yaml")
+	approvalPolicyOwner = os.Getuid()
+	defer func() {
+		approvalPolicyPath = ApprovalPolicyPath
+		approvalPolicyOwner = 0
+		approvalPolicy = ApprovalPolicy{}
+	}()
+
+	if err := readApprovalPolicy(); err != nil || approvalPolicy.Required {
+		t.Errorf("expected no policy without file, got %v: %v", approvalPolicy, err)
+	}
+
+	policy := "required: true\noperations: [uninstall-purge]\nvalidity: 30m\n"
+	if err := os.WriteFile(approvalPolicyPath, []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := readApprovalPolicy(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !approvalPolicy.Required || approvalPolicy.Validity != 30*time.Minute || len(approvalPolicy.Operations) != 1 {
+		t.Errorf("unexpected policy: %v", approvalPolicy)
+	}
+
+	// The policy file can't be changed by other users
+	if err := os.Chmod(approvalPolicyPath, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := readApprovalPolicy(); err == nil {
+		t.Error("expected an error for a policy writable by other users")
+	}
+	if err := os.Chmod(approvalPolicyPath, 0644); err != nil {
+		t.Fatal(err)
+	}
+	approvalPolicyOwner = os.Getuid() + 1
+	if err := readApprovalPolicy(); err == nil {
+		t.Error("expected an error for a policy owned by another user")
+	}
+	approvalPolicyOwner = os.Getuid()
+
+	for _, invalid := range []string{"operations: [unknown]\n", "unknown: true\n"} {
+		if err := os.WriteFile(approvalPolicyPath, []byte(invalid), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := readApprovalPolicy(); err == nil {
+			t.Errorf("expected an error for policy %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// AuditJournalPath is the append-only journal of the commands changing the deployment.
+const AuditJournalPath = "/var/log/uyuni-tools-audit.log"
+
+// auditJournalPath is the journal path, overridden in the tests.
+var auditJournalPath = AuditJournalPath
+
+// AuditRecord is an entry of the audit journal.
+type AuditRecord struct {
+	Time     time.Time         `json:"time"`
+	User     string            `json:"user"`
+	Command  string            `json:"command"`
+	Flags    map[string]string `json:"flags,omitempty"`
+	Result   string            `json:"result"`
+	ExitCode int               `json:"exitCode"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// auditFlags returns the flags set by the user with the secrets redacted.
+func auditFlags(cmd *cobra.Command) map[string]string {
+	flags := map[string]string{}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		value := flag.Value.String()
+		if IsSecretFlag(flag.Name) {
+			value = RedactedValue
+		}
+		flags[flag.Name] = Redact(value)
+	})
+	return flags
+}
+
+// RecordAudit appends the result of a command changing the deployment to the audit journal.
+//
+// Failing to write the journal is only logged to not fail the command after it ran.
+func RecordAudit(cmd *cobra.Command, args []string, err error) {
+	record := AuditRecord{
+		Time:     time.Now(),
+		User:     CurrentUser(),
+		Command:  Redact(strings.TrimSpace(cmd.CommandPath() + " " + strings.Join(args, " "))),
+		Flags:    auditFlags(cmd),
+		Result:   "success",
+		ExitCode: ExitCode(err),
+	}
+	if err != nil {
+		record.Result = "failure"
+		record.Error = Redact(err.Error())
+	}
+
+	if writeErr := appendAuditRecord(record); writeErr != nil {
+		log.Warn().Err(writeErr).Msgf(L("Failed to write the audit journal %s"), auditJournalPath)
+	}
+}
+
+// WithAudit wraps a command function to record its result in the audit journal.
+func WithAudit[T interface{}](fn CommandFunc[T]) CommandFunc[T] {
+	return func(globalFlags *types.GlobalFlags, flags *T, cmd *cobra.Command, args []string) error {
+		err := fn(globalFlags, flags, cmd, args)
+		RecordAudit(cmd, args, err)
+		return err
+	}
+}
+
+func appendAuditRecord(record AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path.Dir(auditJournalPath), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(auditJournalPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// ReadAuditJournal returns the records of the audit journal, the oldest first.
+//
+// Lines which cannot be parsed are skipped.
+func ReadAuditJournal() ([]AuditRecord, error) {
+	records := []AuditRecord{}
+	file, err := os.Open(auditJournalPath)
+	if os.IsNotExist(err) {
+		return records, nil
+	} else if err != nil {
+		return nil, Errorf(err, L("failed to read the audit journal: %s"))
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			log.Debug().Err(err).Msg("Skipping invalid audit journal line")
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// CheckResult is the result of a diagnostic check.
+type CheckResult struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// PrintChecks writes a report of the check results.
+func PrintChecks(out io.Writer, checks []CheckResult) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\n", L("CHECK"), L("STATUS"), L("DETAILS"))
+	for _, check := range checks {
+		status := L("OK")
+		if !check.OK {
+			status = L("FAILED")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", check.Name, status, check.Detail)
+	}
+	w.Flush()
+}
+
+// ReportChecks prints the check results and adds them to the machine output.
+//
+// Returns an error with the given message if any of the checks failed.
+func ReportChecks(out io.Writer, checks []CheckResult, failureMessage string) error {
+	AddMachineData("checks", checks)
+	PrintChecks(out, checks)
+
+	for _, check := range checks {
+		if !check.OK {
+			return errors.New(failureMessage)
+		}
+	}
+	return nil
+}
+
+// PortsCheck verifies that TCP connections can be opened to the ports of a host.
+func PortsCheck(name string, host string, ports []int, timeout time.Duration) CheckResult {
+	check := CheckResult{Name: name}
+	closed := []string{}
+	for _, port := range ports {
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), timeout)
+		if err != nil {
+			log.Debug().Err(err).Msgf("Port %d is not reachable", port)
+			closed = append(closed, strconv.Itoa(port))
+			continue
+		}
+		conn.Close()
+	}
+	if len(closed) > 0 {
+		check.Detail = fmt.Sprintf(L("ports not reachable: %s"), strings.Join(closed, ", "))
+		return check
+	}
+	check.OK = true
+	check.Detail = L("all ports reachable")
+	return check
+}
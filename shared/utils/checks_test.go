@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintChecks(t *testing.T) {
+	var out bytes.Buffer
+	PrintChecks(&out, []CheckResult{
+		{Name: "Pod", OK: true, Detail: "1 / 1 replicas ready"},
+		{Name: "Database", OK: false, Detail: "database not reachable"},
+	})
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[2], "FAILED") {
+		t.Errorf("unexpected report:\n%s", out.String())
+	}
+}
+
+func TestReportChecks(t *testing.T) {
+	var out bytes.Buffer
+	if err := ReportChecks(&out, []CheckResult{{Name: "DNS", OK: true}}, "failed"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	err := ReportChecks(&out, []CheckResult{{Name: "DNS", OK: true}, {Name: "NTP"}}, "some checks failed")
+	if err == nil || err.Error() != "some checks failed" {
+		t.Errorf("expected the failure message, got %v", err)
+	}
+}
+
+func TestPortsCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	openPort := listener.Addr().(*net.TCPAddr).Port
+
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closedListener.Addr().(*net.TCPAddr).Port
+	closedListener.Close()
+
+	if check := PortsCheck("Ports", "127.0.0.1", []int{openPort}, time.Second); !check.OK {
+		t.Errorf("expected the port to be open: %s", check.Detail)
+	}
+	check := PortsCheck("Ports", "127.0.0.1", []int{openPort, closedPort}, time.Second)
+	if check.OK || !strings.Contains(check.Detail, strconv.Itoa(closedPort)) {
+		t.Errorf("expected the closed port to be reported: %s", check.Detail)
+	}
+}
@@ -5,8 +5,6 @@
 package utils
 
 import (
-	"fmt"
-
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
@@ -41,13 +39,27 @@ func CommandHelper[T interface{}](
 ) error {
 	viper, err := ReadConfig(globalFlags.ConfigPath, cmd)
 	if err != nil {
-		return err
+		return UsageError(err)
 	}
 	if err := viper.Unmarshal(&flags); err != nil {
 		log.Error().Err(err).Msg(L("failed to unmarshall configuration"))
-		return fmt.Errorf(L("failed to unmarshall configuration")+": %s", err)
+		return UsageError(Errorf(err, L("failed to unmarshall configuration")+": %s"))
+	}
+	cmd.SetContext(withCommandConfig(cmd.Context(), viper))
+	if err := readHooks(viper); err != nil {
+		return UsageError(err)
+	}
+	if err := readNotifications(viper); err != nil {
+		return UsageError(err)
 	}
-	return fn(globalFlags, flags, cmd, args)
+	if err := readApprovalPolicy(); err != nil {
+		return UsageError(err)
+	}
+	ctx, span := StartCommandSpan(cmd.Context(), cmd.CommandPath())
+	cmd.SetContext(ctx)
+	err = fn(globalFlags, flags, cmd, args)
+	span.End(err)
+	return err
 }
 
 // AddBackendFlag add the flag for setting the backend ('podman', 'podman-remote', 'kubectl').
@@ -55,6 +67,11 @@ func AddBackendFlag(cmd *cobra.Command) {
 	cmd.Flags().String("backend", "", L("tool to use to reach the container. Possible values: 'podman', 'podman-remote', 'kubectl'. Default guesses which to use."))
 }
 
+// AddNamespaceFlag adds the flag for setting the kubernetes namespace of the deployment.
+func AddNamespaceFlag(cmd *cobra.Command) {
+	cmd.Flags().String("namespace", "", L("kubernetes namespace of the deployment. Default looks for it in all the namespaces."))
+}
+
 // AddPullPolicyFlag adds the --pullPolicy flag to a command.
 //
 // Since podman doesn't have such a concept of pull policy like kubernetes,
@@ -5,9 +5,6 @@
 package utils
 
 import (
-	"fmt"
-	"os"
-	"path"
 	"strings"
 	"text/template"
 
@@ -33,17 +30,8 @@ func ReadConfig(configPath string, cmd *cobra.Command) (*viper.Viper, error) {
 		log.Info().Msgf(L("Using config file %s"), configPath)
 		v.SetConfigFile(configPath)
 	} else {
-		xdgConfigHome := os.Getenv("XDG_CONFIG_HOME")
-		if xdgConfigHome == "" {
-			home, err := os.UserHomeDir()
-			if err != nil {
-				log.Err(err).Msg(L("Failed to find home directory"))
-			} else {
-				xdgConfigHome = path.Join(home, ".config")
-			}
-		}
-		if xdgConfigHome != "" {
-			v.AddConfigPath(path.Join(xdgConfigHome, appName))
+		if dir := configDir(); dir != "" {
+			v.AddConfigPath(dir)
 		}
 		v.AddConfigPath(".")
 	}
@@ -51,20 +39,19 @@ func ReadConfig(configPath string, cmd *cobra.Command) (*viper.Viper, error) {
 	if err := bindFlags(cmd, v); err != nil {
 		return nil, err
 	}
+	warnSecretFlags(cmd)
 
 	if err := v.ReadInConfig(); err != nil {
 		// It's okay if there isn't a config file
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			// TODO Provide help on the config file format
-			return nil, fmt.Errorf(L("failed to parse configuration file %s: %s"), v.ConfigFileUsed(), err)
+			return nil, Errorf(err, L("failed to parse configuration file %s: %s"), v.ConfigFileUsed())
 		}
 	}
-
-	v.SetEnvPrefix(envPrefix)
-
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-
-	v.AutomaticEnv()
+	if err := mergeProfile(cmd, v); err != nil {
+		return nil, err
+	}
+	applyRenamedFlags(cmd, v)
 
 	return v, nil
 }
@@ -73,9 +60,18 @@ func ReadConfig(configPath string, cmd *cobra.Command) (*viper.Viper, error) {
 func bindFlags(cmd *cobra.Command, v *viper.Viper) error {
 	var errors []error
 	cmd.Flags().VisitAll(func(f *pflag.Flag) {
-		configName := strings.ReplaceAll(f.Name, "-", ".")
+		if renamedTo(f) != "" {
+			// The renamed flags share the value of the new one
+			return
+		}
+		configName := configKey(f.Name)
 		if err := v.BindPFlag(configName, f); err != nil {
-			errors = append(errors, fmt.Errorf(L("failed to bind %s config to parameter %s: %s"), configName, f.Name, err))
+			errors = append(errors, Errorf(err, L("failed to bind %s config to parameter %s: %s"), configName, f.Name))
+		}
+		// Only one variable can be bound: use the one set in the environment
+		envName, _, _ := lookupEnv(f.Name)
+		if err := v.BindEnv(configName, envName); err != nil {
+			errors = append(errors, Errorf(err, L("failed to bind %s config to environment variable: %s"), configName))
 		}
 	})
 
@@ -110,7 +106,7 @@ Additional help topics:{{range .Commands}}{{if .IsAdditionalHelpTopicCommand}}
   {{rpad .CommandPath .CommandPathPadding}} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableSubCommands}}
 
 Use "{{.CommandPath}} [command] --help" for more information about a command.{{end}}
-`)
+`) + envUsage()
 }
 
 // GetConfigHelpCommand provides a help command describing the config file and environment variables.
@@ -137,16 +133,67 @@ Configuration:
   · $PWD/{{ .ConfigFile }}
   · the value of the --config flag
 
+  Several servers can be managed using named profiles: their configuration
+  files are stored in the {{ .ProfilesDir }} folder next to the configuration
+  file, like prod.yaml and test.yaml. The selected profile is read on top of
+  the configuration file and can also set the global flags, like the remote
+  podman or kubernetes cluster ones. The profile is selected with the
+  --profile flag or persisted using the mgradm profile use command.
+
+  Commands or webhooks can be run around the install, migrate and upgrade
+  operations using the hooks entry of the configuration file. The supported
+  hook points are pre-upgrade, post-upgrade, pre-migrate and post-install.
+
+    hooks:
+      pre-upgrade:
+        - command: /usr/local/bin/snapshot-volumes
+          onFailure: abort
+      post-upgrade:
+        - url: https://chat.example.com/hooks/uyuni
+          onFailure: warn
+
+  Commands get the context as UYUNI_HOOK_* environment variables and webhooks
+  as a JSON document. A failing hook with the abort policy stops the operation.
+
+  The result of the migrate and upgrade operations can be sent by email or to
+  a Slack or Mattermost incoming webhook using the notifications entry:
+
+    notifications:
+      smtp:
+        host: smtp.example.com
+        port: 587
+        user: uyuni
+        password: secret
+        from: uyuni@example.com
+        to:
+          - admin@example.com
+      webhook: https://chat.example.com/hooks/xxx
+
 
 Environment variables:
 
-  All the non-global flags can also be passed as environment variables.
+  All the flags can also be passed as environment variables, including the
+  global ones. Secrets should be passed this way rather than on the command
+  line where other users of the machine can see them.
   
-  The environment variable name is the flag name with '-' replaced by with '_'
-  and the {{ .EnvPrefix }} prefix.
+  The environment variable name is the flag name in upper case with '-'
+  replaced by '_' and the {{ .ToolEnvPrefix }} prefix. The {{ .EnvPrefix }} prefix is also
+  supported, but the {{ .ToolEnvPrefix }} variables are preferred.
   
-  For example the '--tz CEST' flag will be mapped to '{{ .EnvPrefix }}_TZ'
-  and '--ssl-password' flags to '{{ .EnvPrefix }}_SSL_PASSWORD' 
+  For example the '--tz CEST' flag will be mapped to '{{ .ToolEnvPrefix }}_TZ'
+  and '--ssl-password' flags to '{{ .ToolEnvPrefix }}_SSL_PASSWORD'
+
+  The values are read in this order of precedence: command line flags,
+  environment variables, profile and configuration file.
+
+
+Tracing:
+
+  The commands and their steps are traced when the OTEL_EXPORTER_OTLP_ENDPOINT
+  or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variable is set. The spans
+  are sent in batches in the OTLP/HTTP format while the command runs.
+  The other OTEL_EXPORTER_OTLP variables and OTEL_SERVICE_NAME are also supported
+  and the TRACEPARENT variable attaches the trace to the caller one.
 `)
 
 	cmd := &cobra.Command{
@@ -156,9 +203,11 @@ Environment variables:
 	t := template.Must(template.New("help").Parse(configTemplate))
 	var helpBuilder strings.Builder
 	if err := t.Execute(&helpBuilder, configTemplateData{
-		EnvPrefix:  envPrefix,
-		Name:       appName,
-		ConfigFile: configFilename,
+		EnvPrefix:     envPrefix,
+		ToolEnvPrefix: preferredEnvPrefix(),
+		Name:          appName,
+		ConfigFile:    configFilename,
+		ProfilesDir:   profilesDir,
 	}); err != nil {
 		log.Fatal().Err(err).Msg(L("failed to compute config help command"))
 	}
@@ -167,7 +216,9 @@ Environment variables:
 }
 
 type configTemplateData struct {
-	EnvPrefix  string
-	ConfigFile string
-	Name       string
+	EnvPrefix     string
+	ToolEnvPrefix string
+	ConfigFile    string
+	Name          string
+	ProfilesDir   string
 }
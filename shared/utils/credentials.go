@@ -0,0 +1,373 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"gopkg.in/yaml.v2"
+)
+
+// SccCredentialsKey is the key of the SUSE Customer Center credentials in the credentials store.
+const SccCredentialsKey = "scc"
+
+// credentialsFile is the name of the file storing the credentials when no keyring is available.
+const credentialsFile = "credentials.yaml"
+
+// credentialsService is the service name used to identify the credentials in the keyrings.
+const credentialsService = "uyuni-tools"
+
+// Credentials are a user name and its secret.
+type Credentials struct {
+	User     string `json:"user" yaml:"user"`
+	Password string `json:"password" yaml:"password"`
+	// Expires is the time after which the credentials can't be used, like for sessions.
+	Expires time.Time `json:"expires,omitempty" yaml:"expires,omitempty"`
+}
+
+// IsExpired returns whether the credentials have an expiration time in the past.
+func (c *Credentials) IsExpired() bool {
+	return !c.Expires.IsZero() && c.Expires.Before(time.Now())
+}
+
+// RegistryCredentialsKey returns the key of the credentials of a container registry.
+func RegistryCredentialsKey(registry string) string {
+	return "registry/" + registry
+}
+
+// APISessionKey returns the key of the API session of a user on a server.
+func APISessionKey(server string, user string) string {
+	return "api-session/" + user + "@" + server
+}
+
+// CredentialsProvider stores credentials indexed by keys.
+type CredentialsProvider interface {
+	// Name returns the name of the storage to show to the user.
+	Name() string
+	// Get returns the credentials for a key or nil if there are none.
+	Get(key string) (*Credentials, error)
+	// Set stores the credentials for a key, replacing the existing ones.
+	Set(key string, credentials Credentials) error
+	// Delete removes the credentials of a key, if any.
+	Delete(key string) error
+}
+
+// secretRunner runs a command passing data on its standard input and returns its standard output.
+//
+// The commands are neither logged nor traced since their input and output are secrets.
+// This can be changed for the tests.
+var secretRunner = func(input []byte, command string, args ...string) ([]byte, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var errOutput bytes.Buffer
+	cmd.Stderr = &errOutput
+	out, err := cmd.Output()
+	if err != nil && errOutput.Len() > 0 {
+		log.Debug().Msgf("%s failed: %s", command, strings.TrimSpace(errOutput.String()))
+	}
+	return out, err
+}
+
+// lookPath finds the tools backing the keyrings, can be changed for the tests.
+var lookPath = exec.LookPath
+
+// credentialsProvider replaces the detected provider when set.
+var credentialsProvider CredentialsProvider
+
+// SetCredentialsProvider replaces the credentials provider.
+//
+// This is meant for the tests to not read or write the credentials of the user.
+// The returned function restores the previous provider.
+func SetCredentialsProvider(provider CredentialsProvider) func() {
+	previous := credentialsProvider
+	credentialsProvider = provider
+	return func() {
+		credentialsProvider = previous
+	}
+}
+
+// NewFileCredentialsProvider returns a provider storing the credentials in a YAML file.
+func NewFileCredentialsProvider(path string) CredentialsProvider {
+	return &fileCredentials{path: path}
+}
+
+// GetCredentialsProvider returns the credentials provider to use.
+//
+// The Secret Service keyring is used if secret-tool is installed and a session bus is running,
+// then the kernel user keyring if keyctl is installed.
+// The credentials are stored in a file readable only by the user otherwise or if the keyring fails.
+func GetCredentialsProvider() CredentialsProvider {
+	if credentialsProvider != nil {
+		return credentialsProvider
+	}
+	file := &fileCredentials{path: path.Join(configDir(), credentialsFile)}
+	if _, err := lookPath("secret-tool"); err == nil && os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" {
+		return &fallbackCredentials{keyring: &secretServiceCredentials{}, file: file}
+	}
+	if _, err := lookPath("keyctl"); err == nil {
+		return &fallbackCredentials{keyring: &keyctlCredentials{}, file: file}
+	}
+	return file
+}
+
+// GetCredentials returns the stored credentials for a key, nil if there are none or if they expired.
+//
+// The errors are only logged since the credentials can still be passed by the user.
+func GetCredentials(key string) *Credentials {
+	credentials, err := GetCredentialsProvider().Get(key)
+	if err != nil {
+		log.Warn().Err(err).Msgf(L("Failed to read the %s stored credentials"), key)
+		return nil
+	}
+	if credentials != nil && credentials.IsExpired() {
+		log.Debug().Msgf("Stored %s credentials are expired", key)
+		return nil
+	}
+	return credentials
+}
+
+// StoreCredentials stores the credentials for a key.
+func StoreCredentials(key string, credentials Credentials) error {
+	provider := GetCredentialsProvider()
+	if err := provider.Set(key, credentials); err != nil {
+		return err
+	}
+	log.Debug().Msgf("Stored %s credentials in %s", key, provider.Name())
+	return nil
+}
+
+// DeleteCredentials removes the stored credentials for a key.
+func DeleteCredentials(key string) error {
+	return GetCredentialsProvider().Delete(key)
+}
+
+// fallbackCredentials uses a keyring and the file when the keyring fails.
+type fallbackCredentials struct {
+	keyring CredentialsProvider
+	file    CredentialsProvider
+}
+
+func (p *fallbackCredentials) Name() string {
+	return p.keyring.Name()
+}
+
+func (p *fallbackCredentials) Get(key string) (*Credentials, error) {
+	credentials, err := p.keyring.Get(key)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to read %s credentials from the %s", key, p.keyring.Name())
+	}
+	if credentials != nil {
+		return credentials, nil
+	}
+	// The credentials may have been stored in the file when the keyring was not working
+	return p.file.Get(key)
+}
+
+func (p *fallbackCredentials) Set(key string, credentials Credentials) error {
+	if err := p.keyring.Set(key, credentials); err != nil {
+		log.Warn().Err(err).Msgf(L("Failed to store the credentials in the %[1]s, using %[2]s instead"),
+			p.keyring.Name(), p.file.Name())
+		return p.file.Set(key, credentials)
+	}
+	// Do not leave older values in the file
+	return p.file.Delete(key)
+}
+
+func (p *fallbackCredentials) Delete(key string) error {
+	return errors.Join(p.keyring.Delete(key), p.file.Delete(key))
+}
+
+// secretServiceCredentials stores the credentials in the Secret Service keyring, like GNOME Keyring or KWallet.
+type secretServiceCredentials struct{}
+
+func (p *secretServiceCredentials) Name() string {
+	return L("Secret Service keyring")
+}
+
+func (p *secretServiceCredentials) attributes(key string) []string {
+	return []string{"service", credentialsService, "key", key}
+}
+
+func (p *secretServiceCredentials) Get(key string) (*Credentials, error) {
+	out, err := secretRunner(nil, "secret-tool", append([]string{"lookup"}, p.attributes(key)...)...)
+	// secret-tool fails with no output when nothing is found
+	if len(bytes.TrimSpace(out)) == 0 {
+		if _, ok := err.(*exec.ExitError); ok || err == nil {
+			return nil, nil
+		}
+		return nil, Errorf(err, L("failed to read the credentials from the Secret Service keyring: %s"))
+	}
+	return parseCredentials(out)
+}
+
+func (p *secretServiceCredentials) Set(key string, credentials Credentials) error {
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	args := append([]string{"store", "--label", credentialsService + " " + key}, p.attributes(key)...)
+	if _, err := secretRunner(data, "secret-tool", args...); err != nil {
+		return Errorf(err, L("failed to store the credentials in the Secret Service keyring: %s"))
+	}
+	return nil
+}
+
+func (p *secretServiceCredentials) Delete(key string) error {
+	// secret-tool clear doesn't fail when nothing matches
+	if _, err := secretRunner(nil, "secret-tool", append([]string{"clear"}, p.attributes(key)...)...); err != nil {
+		return Errorf(err, L("failed to remove the credentials from the Secret Service keyring: %s"))
+	}
+	return nil
+}
+
+// keyctlCredentials stores the credentials in the kernel user keyring.
+//
+// This keyring is kept in memory only and is emptied at reboot.
+type keyctlCredentials struct{}
+
+func (p *keyctlCredentials) Name() string {
+	return L("kernel user keyring")
+}
+
+func (p *keyctlCredentials) description(key string) string {
+	return credentialsService + ":" + key
+}
+
+// search returns the ID of the key, or an empty string if not found.
+func (p *keyctlCredentials) search(key string) string {
+	out, err := secretRunner(nil, "keyctl", "search", "@u", "user", p.description(key))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (p *keyctlCredentials) Get(key string) (*Credentials, error) {
+	id := p.search(key)
+	if id == "" {
+		return nil, nil
+	}
+	out, err := secretRunner(nil, "keyctl", "pipe", id)
+	if err != nil {
+		return nil, Errorf(err, L("failed to read the credentials from the kernel user keyring: %s"))
+	}
+	return parseCredentials(out)
+}
+
+func (p *keyctlCredentials) Set(key string, credentials Credentials) error {
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	// padd reads the data from the standard input to not show it in the processes list
+	if _, err := secretRunner(data, "keyctl", "padd", "user", p.description(key), "@u"); err != nil {
+		return Errorf(err, L("failed to store the credentials in the kernel user keyring: %s"))
+	}
+	return nil
+}
+
+func (p *keyctlCredentials) Delete(key string) error {
+	id := p.search(key)
+	if id == "" {
+		return nil
+	}
+	if _, err := secretRunner(nil, "keyctl", "unlink", id, "@u"); err != nil {
+		return Errorf(err, L("failed to remove the credentials from the kernel user keyring: %s"))
+	}
+	return nil
+}
+
+func parseCredentials(data []byte) (*Credentials, error) {
+	var credentials Credentials
+	if err := json.Unmarshal(bytes.TrimSpace(data), &credentials); err != nil {
+		return nil, Errorf(err, L("failed to parse the stored credentials: %s"))
+	}
+	return &credentials, nil
+}
+
+// fileCredentials stores the credentials in a YAML file only readable by the user.
+type fileCredentials struct {
+	path string
+}
+
+func (p *fileCredentials) Name() string {
+	return p.path
+}
+
+func (p *fileCredentials) read() (map[string]Credentials, error) {
+	all := map[string]Credentials{}
+	data, err := os.ReadFile(p.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	} else if err != nil {
+		return nil, Errorf(err, L("failed to read the credentials file: %s"))
+	}
+	if err := yaml.Unmarshal(data, &all); err != nil {
+		return nil, Errorf(err, L("failed to parse the credentials file: %s"))
+	}
+	return all, nil
+}
+
+func (p *fileCredentials) write(all map[string]Credentials) error {
+	if len(all) == 0 {
+		if err := os.Remove(p.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return Errorf(err, L("failed to remove the credentials file: %s"))
+		}
+		return nil
+	}
+	data, err := yaml.Marshal(all)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path.Dir(p.path), 0700); err != nil {
+		return Errorf(err, L("failed to create the configuration directory: %s"))
+	}
+	if err := os.WriteFile(p.path, data, 0600); err != nil {
+		return Errorf(err, L("failed to write the credentials file: %s"))
+	}
+	// WriteFile doesn't change the permissions of an existing file
+	return os.Chmod(p.path, 0600)
+}
+
+func (p *fileCredentials) Get(key string) (*Credentials, error) {
+	all, err := p.read()
+	if err != nil {
+		return nil, err
+	}
+	if credentials, found := all[key]; found {
+		return &credentials, nil
+	}
+	return nil, nil
+}
+
+func (p *fileCredentials) Set(key string, credentials Credentials) error {
+	all, err := p.read()
+	if err != nil {
+		return err
+	}
+	all[key] = credentials
+	return p.write(all)
+}
+
+func (p *fileCredentials) Delete(key string) error {
+	all, err := p.read()
+	if err != nil {
+		return err
+	}
+	if _, found := all[key]; !found {
+		return nil
+	}
+	delete(all, key)
+	return p.write(all)
+}
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeKeyctl simulates the keyctl tool storing the keys in memory.
+type fakeKeyctl struct {
+	keys  map[string]string
+	calls []string
+}
+
+func (k *fakeKeyctl) run(input []byte, command string, args ...string) ([]byte, error) {
+	k.calls = append(k.calls, command+" "+strings.Join(args, " "))
+	switch args[0] {
+	case "padd":
+		k.keys[args[2]] = string(input)
+		return []byte(args[2] + "\n"), nil
+	case "search":
+		if _, found := k.keys[args[3]]; found {
+			return []byte(args[3] + "\n"), nil
+		}
+	case "pipe":
+		return []byte(k.keys[args[1]]), nil
+	case "unlink":
+		delete(k.keys, args[1])
+		return nil, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func setupCredentials(t *testing.T, runner func([]byte, string, ...string) ([]byte, error), tools ...string) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path=/run/user/1000/bus")
+
+	oldRunner := secretRunner
+	oldLookPath := lookPath
+	secretRunner = runner
+	lookPath = func(file string) (string, error) {
+		if Contains(tools, file) {
+			return "/usr/bin/" + file, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	t.Cleanup(func() {
+		secretRunner = oldRunner
+		lookPath = oldLookPath
+	})
+}
+
+func TestFileCredentials(t *testing.T) {
+	setupCredentials(t, nil)
+	filePath := path.Join(configDir(), credentialsFile)
+	if actual := GetCredentialsProvider().Name(); actual != filePath {
+		t.Errorf("expected the file provider without keyring tools, got %s", actual)
+	}
+
+	if actual := GetCredentials(SccCredentialsKey); actual != nil {
+		t.Errorf("expected no credentials, got %v", actual)
+	}
+	expected := Credentials{User: "scc-user", Password: "secret"}
+	if err := StoreCredentials(SccCredentialsKey, expected); err != nil {
+		t.Fatalf("failed to store the credentials: %s", err)
+	}
+	if actual := GetCredentials(SccCredentialsKey); actual == nil || !reflect.DeepEqual(*actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if info, err := os.Stat(filePath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("the credentials file should only be readable by the user: %v", info)
+	}
+
+	if err := DeleteCredentials(SccCredentialsKey); err != nil {
+		t.Fatalf("failed to delete the credentials: %s", err)
+	}
+	if actual := GetCredentials(SccCredentialsKey); actual != nil {
+		t.Errorf("expected removed credentials, got %v", actual)
+	}
+	if _, err := os.Stat(filePath); !errors.Is(err, os.ErrNotExist) {
+		t.Error("the empty credentials file should be removed")
+	}
+}
+
+func TestExpiredCredentials(t *testing.T) {
+	setupCredentials(t, nil)
+	key := APISessionKey("uyuni.example.com", "admin")
+	expired := Credentials{User: "admin", Password: "cookie", Expires: time.Now().Add(-time.Minute)}
+	if err := StoreCredentials(key, expired); err != nil {
+		t.Fatal(err)
+	}
+	if actual := GetCredentials(key); actual != nil {
+		t.Errorf("expired credentials should not be returned, got %v", actual)
+	}
+
+	valid := Credentials{User: "admin", Password: "cookie", Expires: time.Now().Add(time.Hour)}
+	if err := StoreCredentials(key, valid); err != nil {
+		t.Fatal(err)
+	}
+	if actual := GetCredentials(key); actual == nil || actual.Password != "cookie" {
+		t.Errorf("expected valid session, got %v", actual)
+	}
+}
+
+func TestKeyctlCredentials(t *testing.T) {
+	keyctl := &fakeKeyctl{keys: map[string]string{}}
+	setupCredentials(t, keyctl.run, "keyctl")
+	if actual := GetCredentialsProvider().Name(); actual != "kernel user keyring" {
+		t.Errorf("expected the kernel keyring provider, got %s", actual)
+	}
+
+	key := RegistryCredentialsKey("registry.example.com")
+	expected := Credentials{User: "robot", Password: "s3cr3t"}
+	if err := StoreCredentials(key, expected); err != nil {
+		t.Fatalf("failed to store the credentials: %s", err)
+	}
+	for _, call := range keyctl.calls {
+		if strings.Contains(call, "s3cr3t") {
+			t.Errorf("the secret should not be passed as argument: %s", call)
+		}
+	}
+	if _, found := keyctl.keys["uyuni-tools:registry/registry.example.com"]; !found {
+		t.Errorf("expected the key in the keyring, got %v", keyctl.keys)
+	}
+	if _, err := os.Stat(path.Join(configDir(), credentialsFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Error("the credentials should not be written in the file")
+	}
+	if actual := GetCredentials(key); actual == nil || !reflect.DeepEqual(*actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	if err := DeleteCredentials(key); err != nil {
+		t.Fatalf("failed to delete the credentials: %s", err)
+	}
+	if len(keyctl.keys) != 0 {
+		t.Errorf("expected the key to be removed, got %v", keyctl.keys)
+	}
+}
+
+func TestKeyringFallback(t *testing.T) {
+	failing := func(input []byte, command string, args ...string) ([]byte, error) {
+		return nil, errors.New("no keyring daemon")
+	}
+	setupCredentials(t, failing, "secret-tool", "keyctl")
+	if actual := GetCredentialsProvider().Name(); actual != "Secret Service keyring" {
+		t.Errorf("expected the Secret Service provider, got %s", actual)
+	}
+
+	expected := Credentials{User: "scc-user", Password: "secret"}
+	if err := StoreCredentials(SccCredentialsKey, expected); err != nil {
+		t.Fatalf("failed to store the credentials in the fallback file: %s", err)
+	}
+	if _, err := os.Stat(path.Join(configDir(), credentialsFile)); err != nil {
+		t.Errorf("expected the credentials in the file: %s", err)
+	}
+	if actual := GetCredentials(SccCredentialsKey); actual == nil || !reflect.DeepEqual(*actual, expected) {
+		t.Errorf("expected %v from the fallback file, got %v", expected, actual)
+	}
+}
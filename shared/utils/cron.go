@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// CronSchedule is a parsed cron expression.
+type CronSchedule struct {
+	minutes  []bool
+	hours    []bool
+	days     []bool
+	months   []bool
+	weekdays []bool
+	// anyDay and anyWeekday are true if the day of month or day of week fields are *
+	anyDay     bool
+	anyWeekday bool
+}
+
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	// 0 and 7 are both Sunday
+	{name: "day of week", min: 0, max: 7},
+}
+
+// ParseCron parses a five fields cron expression: minute, hour, day of month, month and day of week.
+//
+// Each field can be *, a value, a range like 1-5 or a list of them separated by commas,
+// optionally followed by a step like */15.
+func ParseCron(expr string) (*CronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf(L("invalid cron expression %[1]s: %[2]d fields expected"), expr, len(cronFields))
+	}
+
+	values := make([][]bool, len(cronFields))
+	for i, field := range cronFields {
+		parsed, err := parseCronField(fields[i], field)
+		if err != nil {
+			return nil, Errorf(err, L("invalid cron expression %[1]s: %[2]s"), expr)
+		}
+		values[i] = parsed
+	}
+	values[4][0] = values[4][0] || values[4][7]
+
+	return &CronSchedule{
+		minutes:    values[0],
+		hours:      values[1],
+		days:       values[2],
+		months:     values[3],
+		weekdays:   values[4],
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField returns the values matched by a field, indexed by value.
+func parseCronField(value string, field cronField) ([]bool, error) {
+	matches := make([]bool, field.max+1)
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf(L("invalid step %[1]s in %[2]s field"), stepPart, field.name)
+			}
+		}
+
+		start, end := field.min, field.max
+		if rangePart != "*" {
+			first, last, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseCronValue(first, field); err != nil {
+				return nil, err
+			}
+			end = start
+			if isRange {
+				if end, err = parseCronValue(last, field); err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				end = field.max
+			}
+			if end < start {
+				return nil, fmt.Errorf(L("invalid range %[1]s in %[2]s field"), rangePart, field.name)
+			}
+		}
+
+		for i := start; i <= end; i += step {
+			matches[i] = true
+		}
+	}
+	return matches, nil
+}
+
+func parseCronValue(value string, field cronField) (int, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < field.min || number > field.max {
+		return 0, fmt.Errorf(L("invalid value %[1]s in %[2]s field, expected between %[3]d and %[4]d"),
+			value, field.name, field.min, field.max)
+	}
+	return number, nil
+}
+
+// Matches returns whether the time, truncated to the minute, is matched by the schedule.
+//
+// Like in cron, if both the day of month and the day of week are restricted, any of them needs to match.
+func (s *CronSchedule) Matches(t time.Time) bool {
+	if !s.minutes[t.Minute()] || !s.hours[t.Hour()] || !s.months[int(t.Month())] {
+		return false
+	}
+	dayMatches := s.days[t.Day()]
+	weekdayMatches := s.weekdays[int(t.Weekday())]
+	if !s.anyDay && !s.anyWeekday {
+		return dayMatches || weekdayMatches
+	}
+	return dayMatches && weekdayMatches
+}
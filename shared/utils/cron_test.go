@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronMatches(t *testing.T) {
+	// 2024-06-15 is a Saturday
+	saturdayNight := time.Date(2024, 6, 15, 22, 30, 0, 0, time.UTC)
+	saturdayMorning := time.Date(2024, 6, 15, 9, 0, 0, 0, time.UTC)
+	sundayNight := time.Date(2024, 6, 16, 23, 45, 0, 0, time.UTC)
+	firstOfMonth := time.Date(2024, 7, 1, 22, 0, 0, 0, time.UTC)
+
+	data := []struct {
+		expr     string
+		time     time.Time
+		expected bool
+	}{
+		{"* * * * *", saturdayMorning, true},
+		{"* 22-23 * * 6", saturdayNight, true},
+		{"* 22-23 * * 6", saturdayMorning, false},
+		{"* 22-23 * * 6", sundayNight, false},
+		{"* 22-23 * * 6,7", sundayNight, true},
+		{"* 22-23 * * 0", sundayNight, true},
+		{"*/15 * * * *", sundayNight, true},
+		{"*/20 * * * *", sundayNight, false},
+		{"30 22 15 6 *", saturdayNight, true},
+		// Both day fields restricted: any of them matches
+		{"* 22 1 * 6", firstOfMonth, true},
+		{"* 22 1 * 6", saturdayNight, true},
+		{"* 22 2 * 5", saturdayNight, false},
+		{"0-29/10,30 * * * *", saturdayNight, true},
+	}
+	for i, testCase := range data {
+		schedule, err := ParseCron(testCase.expr)
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %s", i, err)
+		}
+		if actual := schedule.Matches(testCase.time); actual != testCase.expected {
+			t.Errorf("case %d: expected %s to match %s: %v", i, testCase.expr, testCase.time, testCase.expected)
+		}
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"* * * *", "60 * * * *", "* 23-22 * * *", "*/0 * * * *", "a * * * *", "* * 0 * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("expected an error for %s", expr)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// renamedFlagAnnotation is the flag annotation holding the new name of a renamed flag.
+const renamedFlagAnnotation = "uyuni-tools/renamed-to"
+
+// RenameFlag declares that the oldName flag of a command has been renamed to newName.
+//
+// The newName flag needs to be defined before. The old flag is kept as a hidden alias
+// sharing its value and warns when used. The configuration file keys of the old flag
+// are mapped to the new ones with a warning too.
+func RenameFlag(cmd *cobra.Command, oldName string, newName string) {
+	flag := cmd.Flags().Lookup(newName)
+	if flag == nil {
+		panic(fmt.Sprintf("cannot rename undefined flag %s", newName))
+	}
+	cmd.Flags().AddFlag(&pflag.Flag{
+		Name:        oldName,
+		Usage:       flag.Usage,
+		Value:       flag.Value,
+		DefValue:    flag.DefValue,
+		NoOptDefVal: flag.NoOptDefVal,
+		Deprecated:  fmt.Sprintf(L("use --%s instead"), newName),
+		Hidden:      true,
+		Annotations: map[string][]string{renamedFlagAnnotation: {newName}},
+	})
+}
+
+// renamedTo returns the new name of a renamed flag or an empty string.
+func renamedTo(f *pflag.Flag) string {
+	if names := f.Annotations[renamedFlagAnnotation]; len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// applyRenamedFlags maps the renamed flags and their configuration keys to the new ones.
+//
+// Using the old flag marks the new one as changed since they share the same value.
+// This needs to be called once the configuration file has been read.
+func applyRenamedFlags(cmd *cobra.Command, v *viper.Viper) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		newName := renamedTo(f)
+		if newName == "" {
+			return
+		}
+		if f.Changed {
+			cmd.Flags().Lookup(newName).Changed = true
+		}
+
+		// The old flag is not bound: its key can only come from the configuration file.
+		oldKey := configKey(f.Name)
+		if v.IsSet(oldKey) {
+			newKey := configKey(newName)
+			log.Warn().Msgf(L("%[1]s configuration entry is deprecated, use %[2]s instead"), oldKey, newKey)
+			// The default is only used if neither the new flag nor the new configuration key are set
+			v.SetDefault(newKey, v.Get(oldKey))
+		}
+	})
+}
+
+// configKey returns the configuration key matching a flag name.
+func configKey(flagName string) string {
+	return strings.ReplaceAll(flagName, "-", ".")
+}
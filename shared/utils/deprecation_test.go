@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type renamedFlags struct {
+	Purge struct {
+		Volumes bool
+	}
+}
+
+func newRenamedCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "uninstall"}
+	cmd.Flags().Bool("purge-volumes", false, "")
+	RenameFlag(cmd, "purgeVolumes", "purge-volumes")
+	return cmd
+}
+
+func readRenamedFlags(t *testing.T, cmd *cobra.Command, configPath string) renamedFlags {
+	v, err := ReadConfig(configPath, cmd)
+	if err != nil {
+		t.Fatalf("failed to read the configuration: %s", err)
+	}
+	var flags renamedFlags
+	if err := v.Unmarshal(&flags); err != nil {
+		t.Fatalf("failed to unmarshal the configuration: %s", err)
+	}
+	return flags
+}
+
+func writeTestConfig(t *testing.T, content string) string {
+	configPath := path.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return configPath
+}
+
+func TestRenameFlag(t *testing.T) {
+	cmd := newRenamedCommand()
+	if err := cmd.ParseFlags([]string{"--purgeVolumes"}); err != nil {
+		t.Fatalf("failed to parse the old flag: %s", err)
+	}
+	flags := readRenamedFlags(t, cmd, writeTestConfig(t, ""))
+	if !flags.Purge.Volumes {
+		t.Error("the old flag value should be passed to the new one")
+	}
+	if !cmd.Flags().Changed("purge-volumes") {
+		t.Error("the new flag should be marked as changed")
+	}
+	if !cmd.Flags().Lookup("purgeVolumes").Hidden {
+		t.Error("the old flag should be hidden")
+	}
+}
+
+func TestRenameFlagConfig(t *testing.T) {
+	data := []struct {
+		config   string
+		args     []string
+		expected bool
+	}{
+		{"purgeVolumes: true\n", []string{}, true},
+		{"purgeVolumes: true\npurge:\n  volumes: false\n", []string{}, false},
+		{"purgeVolumes: false\n", []string{"--purge-volumes"}, true},
+	}
+	for i, test := range data {
+		cmd := newRenamedCommand()
+		if err := cmd.ParseFlags(test.args); err != nil {
+			t.Fatalf("case %d: failed to parse the flags: %s", i, err)
+		}
+		flags := readRenamedFlags(t, cmd, writeTestConfig(t, test.config))
+		if flags.Purge.Volumes != test.expected {
+			t.Errorf("case %d: expected %v, got %v", i, test.expected, flags.Purge.Volumes)
+		}
+	}
+}
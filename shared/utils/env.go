@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// toolEnvPrefix is the prefix of the environment variables specific to the running tool, like MGRADM.
+var toolEnvPrefix string
+
+// SetEnvPrefix sets the prefix of the environment variables specific to the tool, like MGRADM.
+//
+// The environment variables with the UYUNI prefix are still read, but the tool specific ones are preferred.
+func SetEnvPrefix(prefix string) {
+	toolEnvPrefix = prefix
+}
+
+// preferredEnvPrefix returns the prefix of the environment variables to advertise.
+func preferredEnvPrefix() string {
+	if toolEnvPrefix != "" {
+		return toolEnvPrefix
+	}
+	return envPrefix
+}
+
+// EnvName returns the name of the preferred environment variable to set a flag.
+func EnvName(flagName string) string {
+	return envNames(flagName)[0]
+}
+
+// envNames returns the names of the environment variables setting a flag, the preferred one first.
+func envNames(flagName string) []string {
+	suffix := strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+	names := []string{}
+	if toolEnvPrefix != "" {
+		names = append(names, toolEnvPrefix+"_"+suffix)
+	}
+	return append(names, envPrefix+"_"+suffix)
+}
+
+// lookupEnv returns the name and value of the first environment variable set for a flag.
+//
+// The name of the preferred variable is returned if none is set.
+func lookupEnv(flagName string) (string, string, bool) {
+	names := envNames(flagName)
+	for _, name := range names {
+		if value, found := os.LookupEnv(name); found {
+			return name, value, true
+		}
+	}
+	return names[0], "", false
+}
+
+// ApplyEnvToGlobalFlags sets the global flags which are not passed on the command line
+// from the environment variables.
+//
+// The global flags are used before the configuration is read by the commands and need to be set this way.
+// The flags set from the environment are marked as changed to take precedence over the configuration profile.
+func ApplyEnvToGlobalFlags(cmd *cobra.Command) error {
+	var errs []error
+	flags := cmd.Root().PersistentFlags()
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			return
+		}
+		if name, value, found := lookupEnv(f.Name); found {
+			if err := flags.Set(f.Name, value); err != nil {
+				errs = append(errs, Errorf(err, L("invalid value for %s environment variable: %s"), name))
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// warnSecretFlags warns about the secrets passed on the command line as other users can see them.
+func warnSecretFlags(cmd *cobra.Command) {
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if IsSecretFlag(f.Name) && f.Value.String() != "" {
+			log.Warn().Msgf(L("Passing secrets on the command line exposes them to the other users of the machine: use the %[1]s environment variable instead of --%[2]s"), EnvName(f.Name), f.Name)
+		}
+	})
+}
+
+// envUsage is the help text about the environment variables added to the usage of the commands.
+func envUsage() string {
+	return fmt.Sprintf(L(`
+Environment variables:
+  All the flags can also be set using environment variables named after the flag in upper case,
+  with '-' replaced by '_' and the %[1]s_ prefix, like %[2]s for --logLevel.
+  Prefer environment variables to pass secrets.
+`), preferredEnvPrefix(), EnvName("logLevel"))
+}
@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func setTestEnvPrefix(t *testing.T, prefix string) {
+	old := toolEnvPrefix
+	SetEnvPrefix(prefix)
+	t.Cleanup(func() { SetEnvPrefix(old) })
+}
+
+func TestEnvNames(t *testing.T) {
+	setTestEnvPrefix(t, "")
+	if actual := envNames("ssl-password"); !reflect.DeepEqual(actual, []string{"UYUNI_SSL_PASSWORD"}) {
+		t.Errorf("unexpected environment variables without tool prefix: %v", actual)
+	}
+
+	setTestEnvPrefix(t, "MGRADM")
+	expected := []string{"MGRADM_SSL_PASSWORD", "UYUNI_SSL_PASSWORD"}
+	if actual := envNames("ssl-password"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if actual := EnvName("logLevel"); actual != "MGRADM_LOGLEVEL" {
+		t.Errorf("expected MGRADM_LOGLEVEL, got %s", actual)
+	}
+}
+
+func TestReadConfigEnv(t *testing.T) {
+	setTestEnvPrefix(t, "MGRADM")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	configPath := writeTestConfig(t, "tz: UTC\norganization: Example\nssl:\n  password: fromconfig\n")
+
+	t.Setenv("UYUNI_ORGANIZATION", "Legacy")
+	t.Setenv("UYUNI_TZ", "Asia/Tokyo")
+	t.Setenv("MGRADM_TZ", "Europe/Berlin")
+	t.Setenv("MGRADM_SSL_PASSWORD", "fromenv")
+
+	cmd := &cobra.Command{Use: "install"}
+	cmd.Flags().String("tz", "", "")
+	cmd.Flags().String("organization", "", "")
+	cmd.Flags().String("ssl-password", "", "")
+	cmd.Flags().String("email", "", "")
+	if err := cmd.ParseFlags([]string{"--email", "admin@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MGRADM_EMAIL", "ignored@example.com")
+
+	v, err := ReadConfig(configPath, cmd)
+	if err != nil {
+		t.Fatalf("failed to read the configuration: %s", err)
+	}
+	expected := map[string]string{
+		"tz":           "Europe/Berlin",
+		"organization": "Legacy",
+		"ssl.password": "fromenv",
+		"email":        "admin@example.com",
+	}
+	for key, value := range expected {
+		if actual := v.GetString(key); actual != value {
+			t.Errorf("expected %s to be %s, got %s", key, value, actual)
+		}
+	}
+
+	var flags struct {
+		Ssl struct {
+			Password string
+		}
+	}
+	if err := v.Unmarshal(&flags); err != nil {
+		t.Fatal(err)
+	}
+	if flags.Ssl.Password != "fromenv" {
+		t.Errorf("expected the nested value from the environment, got %s", flags.Ssl.Password)
+	}
+}
+
+func TestApplyEnvToGlobalFlags(t *testing.T) {
+	setTestEnvPrefix(t, "MGRADM")
+	t.Setenv("MGRADM_SSH_HOST", "env.example.com")
+	t.Setenv("MGRADM_PROFILE", "test")
+	setupProfiles(t, map[string]string{"test": "ssh:\n  host: profile.example.com\n"})
+
+	cmd := newProfileCommand(t)
+	if err := ApplyEnvToGlobalFlags(cmd); err != nil {
+		t.Fatalf("failed to apply the environment: %s", err)
+	}
+	if err := ApplyProfileToGlobalFlags(cmd); err != nil {
+		t.Fatalf("failed to apply the profile selected in the environment: %s", err)
+	}
+	if actual := cmd.Flags().Lookup("ssh-host").Value.String(); actual != "env.example.com" {
+		t.Errorf("the environment should take precedence over the profile, got %q", actual)
+	}
+
+	cmd = newProfileCommand(t, "--ssh-host", "cli.example.com")
+	if err := ApplyEnvToGlobalFlags(cmd); err != nil {
+		t.Fatalf("failed to apply the environment: %s", err)
+	}
+	if actual := cmd.Flags().Lookup("ssh-host").Value.String(); actual != "cli.example.com" {
+		t.Errorf("the command line should take precedence over the environment, got %q", actual)
+	}
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode identifies the type of a failure for automation tools.
+//
+// The codes are stable and never localized: scripts can branch on them
+// rather than parsing the error messages.
+type ErrorCode string
+
+const (
+	// CodeUnknown is the code of the errors without a more specific one.
+	CodeUnknown ErrorCode = "E_UNKNOWN"
+	// CodeUsage is the code of invalid flags, arguments or configuration.
+	CodeUsage ErrorCode = "E_USAGE"
+	// CodeImagePull is the code of container images which cannot be pulled or found.
+	CodeImagePull ErrorCode = "E_IMAGE_PULL"
+	// CodeDBUpgrade is the code of PostgreSQL version upgrade or finalization failures.
+	CodeDBUpgrade ErrorCode = "E_DB_UPGRADE"
+	// CodeSSLValidation is the code of missing or invalid SSL certificates and keys.
+	CodeSSLValidation ErrorCode = "E_SSL_VALIDATION"
+	// CodeCluster is the code of kubernetes clusters which cannot be reached or used.
+	CodeCluster ErrorCode = "E_CLUSTER"
+	// CodeContainer is the code of server containers which are missing or not running.
+	CodeContainer ErrorCode = "E_CONTAINER"
+	// CodeAPI is the code of server API connection and login failures.
+	CodeAPI ErrorCode = "E_API"
+	// CodeHook is the code of hooks aborting an operation.
+	CodeHook ErrorCode = "E_HOOK"
+)
+
+// ErrorCodeField is the name of the log field holding the error code.
+const ErrorCodeField = "errorCode"
+
+// CodedError is an error with a machine-readable code.
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// WithCode attaches an error code to an error.
+//
+// A nil error stays nil so that the result of a call can be passed directly.
+func WithCode(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{Code: code, Err: err}
+}
+
+// ErrorCodeOf returns the code of the outermost coded error wrapped in err.
+//
+// Usage errors without a code get CodeUsage and the other ones CodeUnknown.
+func ErrorCodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	var codedErr *CodedError
+	if errors.As(err, &codedErr) {
+		return codedErr.Code
+	}
+	if ExitCode(err) == ExitUsage {
+		return CodeUsage
+	}
+	return CodeUnknown
+}
+
+// wrappedError is an error with a formatted message wrapping another error.
+type wrappedError struct {
+	message string
+	err     error
+}
+
+func (e *wrappedError) Error() string {
+	return e.message
+}
+
+func (e *wrappedError) Unwrap() error {
+	return e.err
+}
+
+// Errorf formats an error message wrapping err, so that errors.Is and errors.As can find it.
+//
+// The err value is passed as the last argument of the format: the localized messages
+// keep their final %s verb and the translations do not need to be changed.
+// For example:
+//
+//	return utils.Errorf(err, L("failed to read file %[1]s: %[2]s"), path)
+func Errorf(err error, format string, args ...interface{}) error {
+	return &wrappedError{message: fmt.Sprintf(format, append(args, err)...), err: err}
+}
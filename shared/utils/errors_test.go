@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	data := []struct {
+		err      error
+		expected ErrorCode
+	}{
+		{nil, ""},
+		{errors.New("failure"), CodeUnknown},
+		{UsageError(errors.New("invalid flag")), CodeUsage},
+		{WithCode(CodeImagePull, errors.New("pull failed")), CodeImagePull},
+		{fmt.Errorf("upgrade failed: %w", WithCode(CodeDBUpgrade, errors.New("pg_upgrade failed"))), CodeDBUpgrade},
+		{WithCode(CodeHook, WithCode(CodeAPI, errors.New("webhook failed"))), CodeHook},
+	}
+	for i, test := range data {
+		if actual := ErrorCodeOf(test.err); actual != test.expected {
+			t.Errorf("case %d: expected %s, got %s", i, test.expected, actual)
+		}
+	}
+}
+
+func TestWithCode(t *testing.T) {
+	if WithCode(CodeAPI, nil) != nil {
+		t.Error("a nil error should stay nil")
+	}
+	inner := errors.New("login failed")
+	err := WithCode(CodeAPI, inner)
+	if err.Error() != "login failed" {
+		t.Errorf("unexpected message: %s", err)
+	}
+	if !errors.Is(err, inner) {
+		t.Error("the coded error should wrap the original one")
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	inner := WithCode(CodeContainer, errors.New("no such container"))
+	err := Errorf(inner, "failed to run %[1]s in %[2]s: %[3]s", "ls", "uyuni-server")
+	if err.Error() != "failed to run ls in uyuni-server: no such container" {
+		t.Errorf("unexpected message: %s", err)
+	}
+	if !errors.Is(err, inner) {
+		t.Error("the error should wrap the original one")
+	}
+	if ErrorCodeOf(err) != CodeContainer {
+		t.Errorf("unexpected error code: %s", ErrorCodeOf(err))
+	}
+}
@@ -5,9 +5,12 @@
 package utils
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path"
 	"strings"
 	"time"
 
@@ -33,26 +36,78 @@ func (l OutputLogWriter) Write(p []byte) (n int, err error) {
 	return
 }
 
+// startCommandSpan starts the tracing span of a command execution.
+func startCommandSpan(command string, args []string, hideArgs bool) *Span {
+	commandLine := command + " " + hiddenArguments
+	if !hideArgs {
+		commandLine = Redact(strings.TrimSpace(command + " " + strings.Join(args, " ")))
+	}
+	_, span := StartSpan(TraceContext(), "exec "+path.Base(command), "process.command_line", commandLine)
+	return span
+}
+
+// CommandRunner executes a command writing its standard and error outputs to the given writers.
+//
+// If stderr is nil, the error output is stored in the returned exec.ExitError like exec.Cmd.Output() does.
+type CommandRunner func(command string, args []string, stdout io.Writer, stderr io.Writer) error
+
+func execRunner(command string, args []string, stdout io.Writer, stderr io.Writer) error {
+	cmd := exec.Command(command, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if stderr != nil {
+		return cmd.Run()
+	}
+
+	var errOutput bytes.Buffer
+	cmd.Stderr = &errOutput
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		exitErr.Stderr = errOutput.Bytes()
+	}
+	return err
+}
+
+// commandRunner executes the commands of RunCmd, RunCmdOutput and RunCmdStdMapping.
+var commandRunner CommandRunner = execRunner
+
+// SetCommandRunner replaces the execution of the commands run by RunCmd, RunCmdOutput and RunCmdStdMapping.
+//
+// This is meant for the tests to run the code without the podman, kubectl or helm tools.
+// The returned function restores the previous runner.
+func SetCommandRunner(runner CommandRunner) func() {
+	previous := commandRunner
+	commandRunner = runner
+	return func() {
+		commandRunner = previous
+	}
+}
+
 // RunCmd execute a shell command.
 func RunCmd(command string, args ...string) error {
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond) // Build our new spinner
-	s.Suffix = fmt.Sprintf(" %s %s\n", command, strings.Join(args, " "))
+	s.Suffix = fmt.Sprintf(" %s %s\n", command, Redact(strings.Join(args, " ")))
 	s.Start() // Start the spinner
-	log.Debug().Msgf("Running: %s %s", command, strings.Join(args, " "))
-	err := exec.Command(command, args...).Run()
+	log.Debug().Msgf("Running: %s %s", command, Redact(strings.Join(args, " ")))
+	span := startCommandSpan(command, args, false)
+	var output bytes.Buffer
+	err := commandRunner(command, args, &output, &output)
+	span.End(err)
 	s.Stop()
+	LogCommand(command, args, output.Bytes(), err, false)
 	return err
 }
 
 // RunCmdStdMapping execute a shell command mapping the stdout and stderr.
 func RunCmdStdMapping(logLevel zerolog.Level, command string, args ...string) error {
 	localLogger := log.Level(logLevel)
-	localLogger.Debug().Msgf("Running: %s %s", command, strings.Join(args, " "))
+	localLogger.Debug().Msgf("Running: %s %s", command, Redact(strings.Join(args, " ")))
 
-	runCmd := exec.Command(command, args...)
-	runCmd.Stdout = os.Stdout
-	runCmd.Stderr = os.Stderr
-	err := runCmd.Run()
+	var output bytes.Buffer
+	span := startCommandSpan(command, args, logLevel == zerolog.Disabled)
+	err := commandRunner(command, args, io.MultiWriter(os.Stdout, &output), io.MultiWriter(os.Stderr, &output))
+	span.End(err)
+	LogCommand(command, args, output.Bytes(), err, logLevel == zerolog.Disabled)
 	return err
 }
 
@@ -60,16 +115,21 @@ func RunCmdStdMapping(logLevel zerolog.Level, command string, args ...string) er
 func RunCmdOutput(logLevel zerolog.Level, command string, args ...string) ([]byte, error) {
 	localLogger := log.Level(logLevel)
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond) // Build our new spinner
-	s.Suffix = fmt.Sprintf(" %s %s\n", command, strings.Join(args, " "))
+	s.Suffix = fmt.Sprintf(" %s %s\n", command, Redact(strings.Join(args, " ")))
 	if logLevel != zerolog.Disabled {
 		s.Start() // Start the spinner
 	}
-	localLogger.Debug().Msgf("Running: %s %s", command, strings.Join(args, " "))
-	output, err := exec.Command(command, args...).Output()
+	localLogger.Debug().Msgf("Running: %s %s", command, Redact(strings.Join(args, " ")))
+	span := startCommandSpan(command, args, logLevel == zerolog.Disabled)
+	var stdout bytes.Buffer
+	err := commandRunner(command, args, &stdout, nil)
+	output := stdout.Bytes()
+	span.End(err)
 	if logLevel != zerolog.Disabled {
 		s.Stop()
 	}
-	localLogger.Trace().Msgf("Command output: %s, error: %s", output, err)
+	localLogger.Trace().Msgf("Command output: %s, error: %s", Redact(string(output)), err)
+	LogCommand(command, args, output, err, logLevel == zerolog.Disabled)
 	return output, err
 }
 
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"os"
+	"strings"
+)
+
+// fipsProcFile is the kernel file indicating whether the host runs in FIPS mode.
+var fipsProcFile = "/proc/sys/crypto/fips_enabled"
+
+// FipsTagSuffix is the suffix of the tags of the FIPS-enabled images.
+const FipsTagSuffix = "-fips"
+
+// FipsEnvironment are the environment variables forcing OpenSSL and Java to use the FIPS providers.
+var FipsEnvironment = map[string]string{
+	"OPENSSL_FORCE_FIPS_MODE": "1",
+	"JDK_JAVA_OPTIONS":        "-Dsecurity.useSystemPropertiesFile=true",
+}
+
+// IsHostFips returns whether the kernel of the host has the FIPS mode enabled.
+func IsHostFips() bool {
+	out, err := os.ReadFile(fipsProcFile)
+	return err == nil && strings.TrimSpace(string(out)) == "1"
+}
+
+// FipsTag returns the tag of the FIPS-enabled variant of an image.
+func FipsTag(tag string) string {
+	if tag == "" || strings.HasSuffix(tag, FipsTagSuffix) {
+		return tag
+	}
+	return tag + FipsTagSuffix
+}
+
+// FipsContainerArgs returns the container arguments setting the FIPS environment variables.
+func FipsContainerArgs() []string {
+	args := []string{}
+	for _, name := range []string{"JDK_JAVA_OPTIONS", "OPENSSL_FORCE_FIPS_MODE"} {
+		args = append(args, "-e", name+"="+FipsEnvironment[name])
+	}
+	return args
+}
@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsHostFips(t *testing.T) {
+	defer func() { fipsProcFile = "/proc/sys/crypto/fips_enabled" }()
+
+	fipsProcFile = filepath.Join(t.TempDir(), "fips_enabled")
+	if IsHostFips() {
+		t.Error("missing file should not be FIPS")
+	}
+	for content, expected := range map[string]bool{"1\n": true, "0\n": false} {
+		if err := os.WriteFile(fipsProcFile, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+		if actual := IsHostFips(); actual != expected {
+			t.Errorf("expected %v for %q, got %v", expected, content, actual)
+		}
+	}
+}
+
+func TestFipsTag(t *testing.T) {
+	data := map[string]string{
+		"":             "",
+		"latest":       "latest-fips",
+		"2024.07-fips": "2024.07-fips",
+	}
+	for tag, expected := range data {
+		if actual := FipsTag(tag); actual != expected {
+			t.Errorf("expected %s for %s, got %s", expected, tag, actual)
+		}
+	}
+}
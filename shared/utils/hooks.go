@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// HookPoint is the name of an operation step where hooks are run.
+type HookPoint string
+
+const (
+	// PreUpgradeHook is run before upgrading the server.
+	PreUpgradeHook HookPoint = "pre-upgrade"
+	// PostUpgradeHook is run after upgrading the server, even if it failed.
+	PostUpgradeHook HookPoint = "post-upgrade"
+	// PreMigrateHook is run before migrating a server.
+	PreMigrateHook HookPoint = "pre-migrate"
+	// PostInstallHook is run after installing the server, even if it failed.
+	PostInstallHook HookPoint = "post-install"
+)
+
+// Hook is a script or webhook to run at a hook point.
+type Hook struct {
+	// Command is a shell command getting the context as UYUNI_HOOK_* environment variables.
+	Command string
+	// URL is a webhook receiving the context as a JSON document in a POST request.
+	URL string `mapstructure:"url"`
+	// OnFailure is the policy to apply if the hook fails: abort (the default) or warn.
+	OnFailure string
+}
+
+// hookTimeout is the maximum duration of a webhook request.
+const hookTimeout = 30 * time.Second
+
+// hooks are the hooks configured for each hook point.
+var hooks map[string][]Hook
+
+// readHooks reads the hooks from the hooks entry of the configuration.
+func readHooks(v *viper.Viper) error {
+	hooks = map[string][]Hook{}
+	if err := v.UnmarshalKey("hooks", &hooks); err != nil {
+		return Errorf(err, L("failed to read the hooks configuration: %s"))
+	}
+	for point, pointHooks := range hooks {
+		for _, hook := range pointHooks {
+			if (hook.Command == "") == (hook.URL == "") {
+				return fmt.Errorf(L("every %s hook needs either a command or an URL"), point)
+			}
+			if hook.OnFailure != "" && hook.OnFailure != "abort" && hook.OnFailure != "warn" {
+				return fmt.Errorf(L("invalid %[1]s hook failure policy %[2]s: possible values are abort and warn"),
+					point, hook.OnFailure)
+			}
+		}
+	}
+	return nil
+}
+
+// RunHooks runs the hooks configured for a hook point.
+//
+// The context values are passed to the hooks with the hook point name.
+// The first failure of a hook with the abort policy is returned.
+func RunHooks(point HookPoint, context map[string]string) error {
+	for _, hook := range hooks[string(point)] {
+		var err error
+		_, span := StartSpan(TraceContext(), "hook "+string(point))
+		if hook.Command != "" {
+			log.Info().Msgf(L("Running %[1]s hook: %[2]s"), point, hook.Command)
+			err = runHookCommand(hook.Command, point, context)
+		} else {
+			log.Info().Msgf(L("Calling %[1]s webhook: %[2]s"), point, hook.URL)
+			err = callWebhook(hook.URL, point, context)
+		}
+		span.End(err)
+		if err != nil {
+			if hook.OnFailure == "warn" {
+				log.Warn().Err(err).Msgf(L("%s hook failed"), point)
+				continue
+			}
+			return WithCode(CodeHook, Errorf(err, L("%[1]s hook failed: %[2]s"), point))
+		}
+	}
+	return nil
+}
+
+func runHookCommand(command string, point HookPoint, context map[string]string) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(), "UYUNI_HOOK="+string(point))
+	keys := []string{}
+	for key := range context {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		cmd.Env = append(cmd.Env, "UYUNI_HOOK_"+strings.ToUpper(key)+"="+context[key])
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func callWebhook(url string, point HookPoint, context map[string]string) error {
+	payload := map[string]string{"hook": string(point)}
+	for key, value := range context {
+		payload[key] = value
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	client := http.Client{Timeout: hookTimeout}
+	response, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf(L("webhook returned %s"), response.Status)
+	}
+	return nil
+}
+
+// RunWithHooks runs the pre hooks, the operation and then the post hooks.
+//
+// The post hooks are always run and get the operation result.
+// If the operation failed, the post hooks failures are only logged.
+// An empty hook point is skipped.
+func RunWithHooks(pre HookPoint, post HookPoint, context map[string]string, operation func() error) error {
+	if pre != "" {
+		if err := RunHooks(pre, context); err != nil {
+			return err
+		}
+	}
+
+	err := operation()
+
+	if post != "" {
+		postContext := map[string]string{"result": "success"}
+		for key, value := range context {
+			postContext[key] = value
+		}
+		if err != nil {
+			postContext["result"] = "failure"
+			postContext["error"] = err.Error()
+		}
+		if hookErr := RunHooks(post, postContext); hookErr != nil {
+			if err == nil {
+				return hookErr
+			}
+			log.Error().Err(hookErr).Msg(L("Hook failed after the operation failure"))
+		}
+	}
+	return err
+}
+
+// WithHooks wraps a command function to run the hooks around it.
+//
+// The hooks get the command path and the backend in their context.
+func WithHooks[T interface{}](pre HookPoint, post HookPoint, backend string, fn CommandFunc[T]) CommandFunc[T] {
+	return func(globalFlags *types.GlobalFlags, flags *T, cmd *cobra.Command, args []string) error {
+		context := map[string]string{"command": cmd.CommandPath(), "backend": backend}
+		return RunWithHooks(pre, post, context, func() error {
+			return fn(globalFlags, flags, cmd, args)
+		})
+	}
+}
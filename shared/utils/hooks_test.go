@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestReadHooks(t *testing.T) {
+	defer func() { hooks = nil }()
+
+	data := []struct {
+		config string
+		valid  bool
+	}{
+		{"pre-upgrade:\n  - command: echo\n", true},
+		{"post-upgrade:\n  - url: http://localhost\n    onFailure: warn\n", true},
+		{"pre-upgrade:\n  - onFailure: warn\n", false},
+		{"pre-upgrade:\n  - command: echo\n    url: http://localhost\n", false},
+		{"pre-upgrade:\n  - command: echo\n    onFailure: ignore\n", false},
+	}
+	for i, test := range data {
+		v := viper.New()
+		v.SetConfigType("yaml")
+		config := "hooks:\n" + indent(test.config)
+		if err := v.ReadConfig(strings.NewReader(config)); err != nil {
+			t.Fatalf("test case %d: failed to read config: %s", i, err)
+		}
+		err := readHooks(v)
+		if test.valid && err != nil {
+			t.Errorf("test case %d: unexpected error: %s", i, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("test case %d: expected an error", i)
+		}
+	}
+}
+
+func indent(text string) string {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	return "  " + strings.Join(lines, "\n  ") + "\n"
+}
+
+func TestRunHooksCommand(t *testing.T) {
+	defer func() { hooks = nil }()
+
+	output := path.Join(t.TempDir(), "env")
+	hooks = map[string][]Hook{
+		string(PreUpgradeHook): {
+			{Command: "echo $UYUNI_HOOK $UYUNI_HOOK_BACKEND >" + output},
+		},
+	}
+	if err := RunHooks(PreUpgradeHook, map[string]string{"backend": "podman"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("hook command did not run: %s", err)
+	}
+	if actual := strings.TrimSpace(string(content)); actual != "pre-upgrade podman" {
+		t.Errorf("unexpected hook environment: %s", actual)
+	}
+}
+
+func TestRunHooksFailurePolicy(t *testing.T) {
+	defer func() { hooks = nil }()
+
+	hooks = map[string][]Hook{
+		string(PreUpgradeHook): {{Command: "false", OnFailure: "warn"}},
+	}
+	if err := RunHooks(PreUpgradeHook, nil); err != nil {
+		t.Errorf("failing hook with warn policy should not fail: %s", err)
+	}
+
+	hooks[string(PreUpgradeHook)] = []Hook{{Command: "false"}}
+	if err := RunHooks(PreUpgradeHook, nil); err == nil {
+		t.Error("failing hook with abort policy should fail")
+	}
+
+	ran := false
+	err := RunWithHooks(PreUpgradeHook, "", nil, func() error {
+		ran = true
+		return nil
+	})
+	if err == nil || ran {
+		t.Error("operation should not run if a pre hook aborts")
+	}
+}
+
+func TestRunWithHooksWebhook(t *testing.T) {
+	defer func() { hooks = nil }()
+
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	hooks = map[string][]Hook{
+		string(PostUpgradeHook): {{URL: server.URL}},
+	}
+	operationErr := errors.New("upgrade failed")
+	err := RunWithHooks("", PostUpgradeHook, map[string]string{"backend": "kubernetes"}, func() error {
+		return operationErr
+	})
+	if err != operationErr {
+		t.Errorf("expected the operation error, got %v", err)
+	}
+	expected := map[string]string{
+		"hook":    "post-upgrade",
+		"backend": "kubernetes",
+		"result":  "failure",
+		"error":   "upgrade failed",
+	}
+	for key, value := range expected {
+		if payload[key] != value {
+			t.Errorf("expected %s webhook value %s, got %s", key, value, payload[key])
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// InspectCacheFile is the file storing the last inspection result of each image.
+const InspectCacheFile = "/var/cache/uyuni-tools/inspect.json"
+
+// inspectCacheFile can be changed for the tests.
+var inspectCacheFile = InspectCacheFile
+
+// InspectCacheEntry is the cached inspection of an image.
+type InspectCacheEntry struct {
+	Image string `json:"image"`
+	// Deployment identifies the state of the deployment at the time of the inspection.
+	// The values read from the volumes may have changed if the server was installed or upgraded since.
+	Deployment  string              `json:"deployment,omitempty"`
+	InspectedAt string              `json:"inspectedAt"`
+	Result      types.InspectResult `json:"result"`
+}
+
+// InspectResultSchema returns the JSON schema of the inspection result.
+func InspectResultSchema() map[string]interface{} {
+	properties := map[string]interface{}{}
+	resultType := reflect.TypeOf(types.InspectResult{})
+	for i := 0; i < resultType.NumField(); i++ {
+		field := resultType.Field(i)
+		properties[types.InspectResultName(field)] = map[string]string{
+			"type":        "string",
+			"description": field.Tag.Get("description"),
+		}
+	}
+	return map[string]interface{}{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                "Uyuni server inspection result",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// DeploymentStamp identifies the version of a deployment state to invalidate the cached inspections.
+func DeploymentStamp(state *types.DeploymentState) string {
+	if state == nil {
+		return ""
+	}
+	return state.InstalledAt + "/" + state.UpdatedAt
+}
+
+// CachedInspect returns the cached inspection result for the key or runs inspect and caches its result.
+//
+// The key identifies the image, ideally with its digest. An empty key disables the cache.
+// The cached value is ignored if refresh is true or if the deployment changed since it has been computed.
+func CachedInspect(
+	key string,
+	image string,
+	deployment string,
+	refresh bool,
+	inspect func() (map[string]string, error),
+) (types.InspectResult, error) {
+	cache := readInspectCache()
+	if entry, found := cache[key]; key != "" && found && !refresh && entry.Deployment == deployment {
+		log.Debug().Msgf("Using the inspection of %s from %s", image, entry.InspectedAt)
+		return entry.Result, nil
+	}
+
+	values, err := inspect()
+	if err != nil {
+		return types.InspectResult{}, err
+	}
+	result := types.NewInspectResult(values)
+
+	if key != "" {
+		cache[key] = InspectCacheEntry{
+			Image:       image,
+			Deployment:  deployment,
+			InspectedAt: time.Now().Format(time.RFC3339),
+			Result:      result,
+		}
+		if err := writeInspectCache(cache); err != nil {
+			log.Warn().Err(err).Msg(L("Failed to cache the inspection result"))
+		}
+	}
+	return result, nil
+}
+
+func readInspectCache() map[string]InspectCacheEntry {
+	cache := map[string]InspectCacheEntry{}
+	data, err := os.ReadFile(inspectCacheFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Debug().Err(err).Msgf("Failed to read %s", inspectCacheFile)
+		}
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		log.Debug().Err(err).Msgf("Ignoring invalid inspection cache %s", inspectCacheFile)
+		return map[string]InspectCacheEntry{}
+	}
+	return cache
+}
+
+func writeInspectCache(cache map[string]InspectCacheEntry) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path.Dir(inspectCacheFile), 0700); err != nil {
+		return err
+	}
+	// The cache may contain the SCC credentials
+	return os.WriteFile(inspectCacheFile, data, 0600)
+}
+
+// sccCredentialsPath is the file containing the SUSE Customer Center credentials, relative to the root folder.
+const sccCredentialsPath = "etc/zypp/credentials.d/SCCcredentials"
+
+// hostRoot can be changed for the tests.
+var hostRoot = "/"
+
+// InspectHost reads the values of the host machine.
+//
+// The values are indexed like the inspected image ones with a host_ prefix.
+// Only the values that can be read from files are set, with the public cloud instance flavor.
+func InspectHost() (map[string]string, error) {
+	values := InspectFiles(
+		filepath.Join(hostRoot, "etc"),
+		filepath.Join(hostRoot, "etc/rhn"),
+		filepath.Join(hostRoot, "var/lib/pgsql"),
+	)
+	values["architecture"] = Architecture(runtime.GOARCH)
+	values["scc_username"], values["scc_password"] = readSccCredentials(filepath.Join(hostRoot, sccCredentialsPath))
+	values["instance_flavor"] = readInstanceFlavor()
+
+	result := map[string]string{}
+	for key, value := range values {
+		if value != "" {
+			result["host_"+key] = value
+		}
+	}
+	return result, nil
+}
+
+// InspectFiles reads the inspected values from the etc, etc/rhn and pgsql data folders.
+//
+// The folders can be the ones of the host or podman volumes mount points, empty ones are skipped.
+// Only the non empty values are returned.
+func InspectFiles(etcDir string, rhnDir string, pgsqlDir string) map[string]string {
+	values := map[string]string{}
+	if etcDir != "" {
+		values["uyuni_release"], values["suse_manager_release"] = readReleases(etcDir)
+	}
+
+	if rhnDir != "" {
+		rhnConf := readConfValues(filepath.Join(rhnDir, "rhn.conf"))
+		values["fqdn"] = rhnConf["java.hostname"]
+		values["db_host"] = rhnConf["db_host"]
+	}
+
+	if pgsqlDir != "" {
+		if data, err := os.ReadFile(filepath.Join(pgsqlDir, "data", "PG_VERSION")); err == nil {
+			values["current_pg_version"] = strings.TrimSpace(string(data))
+		}
+	}
+
+	for key, value := range values {
+		if value == "" {
+			delete(values, key)
+		}
+	}
+	return values
+}
+
+// Architecture converts a Go architecture name into the one used by the distributions.
+func Architecture(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	}
+	return goarch
+}
+
+// readReleases returns the Uyuni and SUSE Manager versions from the release files of an etc folder.
+func readReleases(etcDir string) (uyuni string, suma string) {
+	files, _ := filepath.Glob(filepath.Join(etcDir, "*release"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if strings.Contains(line, "Uyuni release") && len(fields) > 2 {
+				uyuni = fields[2]
+			} else if strings.Contains(line, "SUSE Manager release") && len(fields) > 3 {
+				suma = fields[3]
+			}
+		}
+	}
+	return
+}
+
+// readConfValues reads the key = value lines of a configuration file like rhn.conf.
+func readConfValues(file string) map[string]string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return map[string]string{}
+	}
+	return ParseConfValues(data)
+}
+
+// ParseConfValues parses the key = value lines of a configuration file like rhn.conf.
+func ParseConfValues(data []byte) map[string]string {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, found := strings.Cut(line, "="); found {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
+// readSccCredentials returns the user name and password from a zypper credentials file.
+func readSccCredentials(file string) (username string, password string) {
+	values := readConfValues(file)
+	return values["username"], values["password"]
+}
@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func TestInspectResultConversion(t *testing.T) {
+	values := map[string]string{
+		"uyuni_release":      "2024.07",
+		"image_pg_version":   "16",
+		"current_pg_version": "14",
+		"unknown":            "ignored",
+	}
+	result := types.NewInspectResult(values)
+	if result.UyuniRelease != "2024.07" || result.ImagePgVersion != "16" || result.CurrentPgVersion != "14" {
+		t.Errorf("unexpected result: %v", result)
+	}
+
+	delete(values, "unknown")
+	if actual := result.Map(); !reflect.DeepEqual(actual, values) {
+		t.Errorf("expected %v, got %v", values, actual)
+	}
+}
+
+func TestInspectResultSchema(t *testing.T) {
+	properties := InspectResultSchema()["properties"].(map[string]interface{})
+	if len(properties) != reflect.TypeOf(types.InspectResult{}).NumField() {
+		t.Errorf("unexpected number of properties: %d", len(properties))
+	}
+	property := properties["image_pg_version"].(map[string]string)
+	if property["type"] != "string" || property["description"] == "" {
+		t.Errorf("unexpected image_pg_version property: %v", property)
+	}
+}
+
+func TestCachedInspect(t *testing.T) {
+	inspectCacheFile = filepath.Join(t.TempDir(), "cache", "inspect.json")
+	defer func() { inspectCacheFile = InspectCacheFile }()
+
+	calls := 0
+	inspect := func() (map[string]string, error) {
+		calls++
+		return map[string]string{"current_pg_version": "14"}, nil
+	}
+	failing := func() (map[string]string, error) {
+		return nil, errors.New("inspect failed")
+	}
+
+	run := func(key string, deployment string, refresh bool, fn func() (map[string]string, error)) types.InspectResult {
+		result, err := CachedInspect(key, "server:latest", deployment, refresh, fn)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return result
+	}
+
+	if result := run("sha256:1", "install", false, inspect); result.CurrentPgVersion != "14" || calls != 1 {
+		t.Errorf("unexpected first inspection: %v, %d calls", result, calls)
+	}
+	// Cached value
+	if result := run("sha256:1", "install", false, failing); result.CurrentPgVersion != "14" {
+		t.Errorf("expected cached value, got %v", result)
+	}
+	// Refreshed, changed deployment or different image
+	run("sha256:1", "install", true, inspect)
+	run("sha256:1", "upgrade", false, inspect)
+	run("sha256:2", "upgrade", false, inspect)
+	if calls != 4 {
+		t.Errorf("expected 4 inspections, got %d", calls)
+	}
+	// No key, no cache
+	run("", "upgrade", false, inspect)
+	run("", "upgrade", false, inspect)
+	if calls != 6 {
+		t.Errorf("expected 6 inspections, got %d", calls)
+	}
+
+	if _, err := CachedInspect("sha256:3", "server:latest", "", false, failing); err == nil {
+		t.Error("expected the inspection error")
+	}
+}
+
+func writeTestFile(t *testing.T, file string, content string) {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		t.Fatalf("failed to create folder: %s", err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %s", file, err)
+	}
+}
+
+func TestInspectHost(t *testing.T) {
+	hostRoot = t.TempDir()
+	defer func() { hostRoot = "/" }()
+
+	writeTestFile(t, filepath.Join(hostRoot, sccCredentialsPath), "username=SCC_user\npassword=secret=value\n")
+	writeTestFile(t, filepath.Join(hostRoot, "etc/uyuni-release"), "Uyuni release 2024.07 (Ayuntamiento)\n")
+	writeTestFile(t, filepath.Join(hostRoot, "etc/rhn/rhn.conf"),
+		"# java.hostname = commented\njava.hostname = server.example.com\ndb_host = localhost\n")
+	writeTestFile(t, filepath.Join(hostRoot, "var/lib/pgsql/data/PG_VERSION"), "14\n")
+
+	values, err := InspectHost()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]string{
+		"host_scc_username":       "SCC_user",
+		"host_scc_password":       "secret=value",
+		"host_uyuni_release":      "2024.07",
+		"host_fqdn":               "server.example.com",
+		"host_db_host":            "localhost",
+		"host_current_pg_version": "14",
+		"host_architecture":       Architecture(runtime.GOARCH),
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+func TestInspectFilesMissing(t *testing.T) {
+	if values := InspectFiles("", filepath.Join(t.TempDir(), "missing"), ""); len(values) != 0 {
+		t.Errorf("expected no value, got %v", values)
+	}
+}
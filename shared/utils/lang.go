@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"strings"
+
+	"github.com/chai2010/gettext-go"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+const langFlag = "--lang"
+
+// AddLangFlag adds the flag overriding the language of the messages.
+//
+// The flag value is applied by SetLanguageFromArgs before the commands are created.
+func AddLangFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("lang", "",
+		L("language of the messages and generated scripts, like fr or zh_CN. Defaults to the system locale"))
+}
+
+// SetLanguageFromArgs switches the localization to the language passed with the --lang flag.
+//
+// The arguments need to be parsed before cobra does as the commands help is localized when creating them.
+func SetLanguageFromArgs(args []string) {
+	if lang := languageFromArgs(args); lang != "" {
+		gettext.SetLanguage(lang)
+	}
+}
+
+func languageFromArgs(args []string) string {
+	lang := ""
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			break
+		}
+		if args[i] == langFlag && i+1 < len(args) {
+			lang = args[i+1]
+			i++
+		} else if value, found := strings.CutPrefix(args[i], langFlag+"="); found {
+			lang = value
+		}
+	}
+	return lang
+}
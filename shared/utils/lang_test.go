@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import "testing"
+
+func TestLanguageFromArgs(t *testing.T) {
+	data := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"install", "podman"}, ""},
+		{[]string{"--lang", "fr", "install"}, "fr"},
+		{[]string{"install", "--lang=de_DE.UTF-8", "podman"}, "de_DE.UTF-8"},
+		{[]string{"--lang", "fr", "--lang=ja"}, "ja"},
+		{[]string{"--lang"}, ""},
+		{[]string{"exec", "--", "cmd", "--lang", "fr"}, ""},
+	}
+
+	for i, test := range data {
+		if actual := languageFromArgs(test.args); actual != test.expected {
+			t.Errorf("Testcase %d: expected %q, got %q", i, test.expected, actual)
+		}
+	}
+}
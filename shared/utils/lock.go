@@ -0,0 +1,155 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// lockPath is the file locked by the commands changing the deployment, overridden in the tests.
+var lockPath = "/run/uyuni-tools/lock"
+
+// lockPollInterval is the delay between two attempts to get the lock when waiting for it.
+var lockPollInterval = time.Second
+
+// LockHolder describes the command holding the lock.
+type LockHolder struct {
+	PID     int       `json:"pid"`
+	User    string    `json:"user"`
+	Command string    `json:"command"`
+	Since   time.Time `json:"since"`
+}
+
+func (h LockHolder) String() string {
+	return fmt.Sprintf(L("%[1]s run by %[2]s (PID %[3]d) since %[4]s"),
+		h.Command, h.User, h.PID, h.Since.Format(time.RFC1123))
+}
+
+// AddLockFlag adds the flag to wait for the lock held by another command.
+func AddLockFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("wait", false, L("Wait for another command changing the deployment to finish instead of failing"))
+}
+
+// AcquireLock takes the host lock preventing several commands to change the deployment simultaneously.
+//
+// The commands changing the deployment cannot target a remote podman host: the lock is always taken
+// on the host running the containers. On kubernetes, the commands also hold the namespace Lease.
+//
+// If wait is false, an error describing the command holding the lock is returned if already taken.
+// The returned function releases the lock.
+func AcquireLock(command string, wait bool) (func(), error) {
+	if err := os.MkdirAll(path.Dir(lockPath), 0755); err != nil {
+		return nil, Errorf(err, L("failed to create the lock directory: %s"))
+	}
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, Errorf(err, L("failed to open the lock file: %s"))
+	}
+
+	waiting := false
+	for {
+		err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			file.Close()
+			return nil, Errorf(err, L("failed to lock %[1]s: %[2]s"), lockPath)
+		}
+		holder := readLockHolder(lockPath)
+		if !wait {
+			file.Close()
+			return nil, fmt.Errorf(L("another command is changing the deployment: %s. Use --wait to wait for it"), holder)
+		}
+		if !waiting {
+			log.Info().Msgf(L("Waiting for another command to finish: %s"), holder)
+			waiting = true
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	holder := LockHolder{PID: os.Getpid(), User: CurrentUser(), Command: command, Since: time.Now()}
+	if data, err := json.Marshal(holder); err == nil {
+		_ = file.Truncate(0)
+		_, _ = file.WriteAt(data, 0)
+	}
+
+	return func() {
+		_ = file.Truncate(0)
+		_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
+
+// readLockHolder reads the description of the command holding the lock.
+func readLockHolder(lockFile string) string {
+	var holder LockHolder
+	data, err := os.ReadFile(lockFile)
+	if err != nil || json.Unmarshal(data, &holder) != nil {
+		log.Debug().Err(err).Msgf("Failed to read the lock holder from %s", lockFile)
+		return L("unknown command")
+	}
+	return holder.String()
+}
+
+// loginUIDPath is the file containing the ID of the user who logged in, overridden in the tests.
+var loginUIDPath = "/proc/self/loginuid"
+
+// unsetLoginUID is the login ID of the processes which are not part of a login session.
+const unsetLoginUID = "4294967295"
+
+// CurrentUID returns the ID of the user who logged in to run the command, even through sudo or su.
+//
+// Unlike the environment variables, the login ID is set by the kernel and cannot be changed by the user.
+// The real user ID is used for the processes which are not part of a login session.
+func CurrentUID() int {
+	if data, err := os.ReadFile(loginUIDPath); err == nil {
+		value := strings.TrimSpace(string(data))
+		if uid, err := strconv.Atoi(value); err == nil && value != unsetLoginUID {
+			return uid
+		}
+	}
+	return os.Getuid()
+}
+
+// CurrentUser returns the name of the user who logged in to run the command.
+func CurrentUser() string {
+	uid := fmt.Sprint(CurrentUID())
+	if current, err := user.LookupId(uid); err == nil {
+		return current.Username
+	}
+	return uid
+}
+
+// WithLock wraps a command function to hold the host lock while running it.
+//
+// As only the commands changing the deployment need the lock, their result is recorded in the audit journal.
+// The command needs the flag added by AddLockFlag.
+func WithLock[T interface{}](fn CommandFunc[T]) CommandFunc[T] {
+	return func(globalFlags *types.GlobalFlags, flags *T, cmd *cobra.Command, args []string) error {
+		wait, _ := cmd.Flags().GetBool("wait")
+		command := strings.TrimSpace(cmd.CommandPath() + " " + strings.Join(args, " "))
+		release, err := AcquireLock(command, wait)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return WithAudit(fn)(globalFlags, flags, cmd, args)
+	}
+}
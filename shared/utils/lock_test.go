@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquireLock(t *testing.T) {
+	defer func(orig string) { lockPath = orig }(lockPath)
+	defer func(orig time.Duration) { lockPollInterval = orig }(lockPollInterval)
+	lockPath = path.Join(t.TempDir(), "run", "lock")
+	lockPollInterval = 10 * time.Millisecond
+
+	release, err := AcquireLock("mgradm upgrade podman", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = AcquireLock("mgradm uninstall", false)
+	if err == nil || !strings.Contains(err.Error(), "mgradm upgrade podman") {
+		t.Errorf("expected an error describing the lock holder, got %v", err)
+	}
+
+	acquired := make(chan error)
+	go func() {
+		release, err := AcquireLock("mgradm uninstall", true)
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("expected to wait for the lock, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	if err := <-acquired; err != nil {
+		t.Errorf("unexpected error after waiting: %s", err)
+	}
+}
@@ -8,17 +8,18 @@ import (
 	"io"
 	"os"
 	"path"
-	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"golang.org/x/term"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var redactRegex = regexp.MustCompile(`([pP]assword[\t :"\\]+)[^\t "\\]+`)
+// logFileWriter is the writer to the log file, set by LogInit.
+var logFileWriter *UyuniLogger
 
 // UyuniLogger is an io.WriteCloser that writes to the specified filename.
 type UyuniLogger struct {
@@ -31,7 +32,7 @@ type UyuniConsoleWriter struct {
 }
 
 func (l *UyuniLogger) Write(p []byte) (n int, err error) {
-	_, err = l.logger.Write([]byte(redact(string(p)) + "\n"))
+	_, err = l.logger.Write([]byte(Redact(string(p)) + "\n"))
 	if err != nil {
 		return 0, err
 	}
@@ -55,7 +56,7 @@ func (l *UyuniLogger) Rotate() error {
 
 // Write transforms the JSON input with formatters and appends to w.Out.
 func (c UyuniConsoleWriter) Write(p []byte) (n int, err error) {
-	_, err = c.consoleWriter.Write([]byte(redact(string(p))))
+	_, err = c.consoleWriter.Write([]byte(Redact(string(p))))
 	if err != nil {
 		return 0, err
 	}
@@ -63,17 +64,15 @@ func (c UyuniConsoleWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
-func redact(line string) string {
-	return redactRegex.ReplaceAllString(line, "${1}<REDACTED>")
-}
-
 // LogInit initialize logs.
-func LogInit(logToConsole bool) {
+//
+// If logFile is empty, the log file will be written in /var/log or in the home directory if not writable.
+func LogInit(logToConsole bool, logFile string) {
 	zerolog.CallerMarshalFunc = logCallerMarshalFunction
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
 
-	fileWriter := getFileWriter()
-	writers := []io.Writer{fileWriter}
+	logFileWriter = getFileWriter(logFile)
+	writers := []io.Writer{logFileWriter}
 	if logToConsole {
 		consoleWriter := zerolog.NewConsoleWriter()
 		uyuniConsoleWriter := UyuniConsoleWriter{
@@ -87,7 +86,25 @@ func LogInit(logToConsole bool) {
 	log.Logger = zerolog.New(multi).With().Timestamp().Stack().Logger()
 }
 
-func getFileWriter() *UyuniLogger {
+func getFileWriter(logFile string) *UyuniLogger {
+	if logFile == "" {
+		logFile = getDefaultLogFile()
+	}
+
+	fileLogger := &lumberjack.Logger{
+		Filename:   logFile,
+		MaxSize:    5,
+		MaxBackups: 5,
+		MaxAge:     90,
+		Compress:   true,
+	}
+	uyuniLogger := &UyuniLogger{
+		logger: fileLogger,
+	}
+	return uyuniLogger
+}
+
+func getDefaultLogFile() string {
 	const globalLogPath = "/var/log/"
 	logPath := globalLogPath
 
@@ -99,18 +116,15 @@ func getFileWriter() *UyuniLogger {
 	} else {
 		file.Close()
 	}
+	return path.Join(logPath, "uyuni-tools.log")
+}
 
-	fileLogger := &lumberjack.Logger{
-		Filename:   path.Join(logPath, "uyuni-tools.log"),
-		MaxSize:    5,
-		MaxBackups: 5,
-		MaxAge:     90,
-		Compress:   true,
-	}
-	uyuniLogger := &UyuniLogger{
-		logger: fileLogger,
+// GetLogFilePath returns the path to the log file or an empty string if the logs are not initialized.
+func GetLogFilePath() string {
+	if logFileWriter == nil {
+		return ""
 	}
-	return uyuniLogger
+	return logFileWriter.logger.Filename
 }
 
 // SetLogLevel sets the loglevel.
@@ -125,6 +139,7 @@ func SetLogLevel(logLevel string) {
 		log.Logger = log.Logger.With().Caller().Logger()
 	}
 	zerolog.SetGlobalLevel(globalLevel)
+	l10n.EnableLocalizationLogs()
 }
 
 func logCallerMarshalFunction(pc uintptr, file string, line int) string {
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// LogSource is a command printing logs.
+type LogSource struct {
+	Name    string
+	Command string
+	Args    []string
+}
+
+// JournalSince converts a duration into the journalctl relative time format.
+func JournalSince(since string) string {
+	if duration, err := time.ParseDuration(since); err == nil {
+		return fmt.Sprintf("-%ds", int(duration.Seconds()))
+	}
+	return since
+}
+
+// ContainerSince returns the logs command parameters to show the logs since a duration or timestamp.
+func ContainerSince(command string, since string) []string {
+	if _, err := time.ParseDuration(since); err != nil && command == "kubectl" {
+		return []string{"--since-time", since}
+	}
+	return []string{"--since", since}
+}
+
+// RunLogSources runs the commands printing logs and merges their output, each line prefixed by the source name.
+//
+// Only the lines matching the filter are written if it is not nil.
+func RunLogSources(out io.Writer, sources []LogSource, filter *regexp.Regexp, color bool) error {
+	if len(sources) == 0 {
+		return fmt.Errorf(L("no log source to show"))
+	}
+
+	streams := []logStream{}
+	commands := []*exec.Cmd{}
+	for _, source := range sources {
+		log.Debug().Msgf("Running %s %s", source.Command, strings.Join(source.Args, " "))
+		sourceCmd := exec.Command(source.Command, source.Args...)
+		stdout, err := sourceCmd.StdoutPipe()
+		if err != nil {
+			return Errorf(err, L("failed to read the %[1]s logs: %[2]s"), source.Name)
+		}
+		sourceCmd.Stderr = sourceCmd.Stdout
+		if err := sourceCmd.Start(); err != nil {
+			log.Warn().Err(err).Msgf(L("Failed to read the %s logs"), source.Name)
+			continue
+		}
+		streams = append(streams, logStream{source.Name, stdout})
+		commands = append(commands, sourceCmd)
+	}
+
+	mergeLogStreams(out, streams, filter, color)
+
+	for i, sourceCmd := range commands {
+		if err := sourceCmd.Wait(); err != nil {
+			log.Warn().Err(err).Msgf(L("Failed to read the %s logs"), streams[i].name)
+		}
+	}
+	return nil
+}
+
+// logStream is the output of a log source.
+type logStream struct {
+	name   string
+	reader io.Reader
+}
+
+// ANSI colors of the source prefixes.
+var colors = []string{"\033[36m", "\033[32m", "\033[33m", "\033[35m", "\033[34m", "\033[31m"}
+
+const colorReset = "\033[0m"
+
+// mergeLogStreams writes the lines of the streams prefixed by their name until all of them end.
+//
+// Only the lines matching the filter are written if it is not nil.
+func mergeLogStreams(out io.Writer, streams []logStream, filter *regexp.Regexp, color bool) {
+	width := 0
+	for _, stream := range streams {
+		if len(stream.name) > width {
+			width = len(stream.name)
+		}
+	}
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for i, stream := range streams {
+		prefix := fmt.Sprintf("%-*s | ", width, stream.name)
+		if color {
+			prefix = colors[i%len(colors)] + prefix + colorReset
+		}
+		wg.Add(1)
+		go func(stream logStream) {
+			defer wg.Done()
+			scanner := bufio.NewScanner(stream.reader)
+			scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				line := scanner.Text()
+				if filter != nil && !filter.MatchString(line) {
+					continue
+				}
+				lock.Lock()
+				fmt.Fprintln(out, prefix+line)
+				lock.Unlock()
+			}
+			// Drain the remaining output if a line is too long to let the command end
+			_, _ = io.Copy(io.Discard, stream.reader)
+		}(stream)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMergeLogStreams(t *testing.T) {
+	streams := []logStream{
+		{"container", strings.NewReader("started\nerror: failed\n")},
+		{"taskomatic", strings.NewReader("ERROR: job failed\nINFO: job done\n")},
+	}
+	var out bytes.Buffer
+	mergeLogStreams(&out, streams, regexp.MustCompile("(?i)error"), false)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	sort.Strings(lines)
+	expected := []string{
+		"container  | error: failed",
+		"taskomatic | ERROR: job failed",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestMergeLogStreamsColor(t *testing.T) {
+	var out bytes.Buffer
+	mergeLogStreams(&out, []logStream{{"journal", strings.NewReader("line\n")}}, nil, true)
+	if out.String() != colors[0]+"journal | "+colorReset+"line\n" {
+		t.Errorf("unexpected colored output: %q", out.String())
+	}
+}
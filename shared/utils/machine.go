@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// The exit codes are the same for all the commands of all the tools.
+const (
+	// ExitSuccess is the exit code of a successful command.
+	ExitSuccess = 0
+	// ExitFailure is the exit code of a failed command.
+	ExitFailure = 1
+	// ExitUsage is the exit code of a command called with invalid flags, arguments or configuration.
+	ExitUsage = 2
+)
+
+// ReadOnlyAnnotation is the cobra command annotation marking commands never changing anything.
+const ReadOnlyAnnotation = "uyuni-tools/readonly"
+
+// ExitError is an error with a specific exit code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// UsageError wraps an error to exit with the ExitUsage code.
+func UsageError(err error) error {
+	return &ExitError{Code: ExitUsage, Err: err}
+}
+
+// ExitCode returns the exit code matching an error.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitFailure
+}
+
+type machineMessage struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// MachineResult is the JSON document printed on the standard output in machine mode.
+type MachineResult struct {
+	Command  string                 `json:"command"`
+	Success  bool                   `json:"success"`
+	Changed  bool                   `json:"changed"`
+	Rc       int                    `json:"rc"`
+	Error    string                 `json:"error,omitempty"`
+	Code     ErrorCode              `json:"errorCode,omitempty"`
+	Messages []machineMessage       `json:"messages"`
+	Data     map[string]interface{} `json:"data"`
+
+	changed  *bool
+	readOnly bool
+	stdout   io.Writer
+	printed  bool
+}
+
+// machineResult is the result of the running command, nil if the machine mode is disabled.
+var machineResult *MachineResult
+
+// AddMachineFlag adds the --machine flag to the root command.
+func AddMachineFlag(cmd *cobra.Command, globalFlags *bool) {
+	cmd.PersistentFlags().BoolVar(globalFlags, "machine", false,
+		L("print a single JSON document describing the result on the standard output. Logs are only written in the log file"))
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return UsageError(err)
+	})
+}
+
+// EnableMachineOutput switches to the machine output for the command.
+//
+// The logs are no longer written to the console, but collected for the JSON result.
+// Everything written on the standard output by the command or the tools it calls goes to the error output.
+func EnableMachineOutput(cmd *cobra.Command) {
+	machineResult = &MachineResult{
+		Command:  cmd.CommandPath(),
+		Messages: []machineMessage{},
+		Data:     map[string]interface{}{},
+		readOnly: cmd.Annotations[ReadOnlyAnnotation] == "true",
+		stdout:   os.Stdout,
+	}
+	os.Stdout = os.Stderr
+
+	writers := []io.Writer{machineWriter{}}
+	if logFileWriter != nil {
+		writers = append(writers, logFileWriter)
+	}
+	log.Logger = log.Logger.Output(zerolog.MultiLevelWriter(writers...))
+}
+
+// SetMachineChanged overrides whether the command changed something or not.
+//
+// By default the successful commands are considered as changing something unless they are read-only.
+func SetMachineChanged(changed bool) {
+	if machineResult != nil {
+		machineResult.changed = &changed
+	}
+}
+
+// AddMachineData adds a value to the data of the machine result.
+func AddMachineData(key string, value interface{}) {
+	if machineResult != nil {
+		machineResult.Data[key] = value
+	}
+}
+
+// FinishCommand exports the traces and prints the machine result if needed.
+// It returns the exit code to use for the command error.
+func FinishCommand(err error) int {
+	FlushTracing()
+	code := ExitCode(err)
+	logErrorCode(err)
+	if machineResult == nil && err != nil && Contains(os.Args[1:], "--machine") {
+		// The command failed before the machine mode could be enabled, like for an invalid flag
+		machineResult = &MachineResult{
+			Command:  os.Args[0],
+			Messages: []machineMessage{},
+			Data:     map[string]interface{}{},
+			stdout:   os.Stdout,
+		}
+	}
+	if machineResult != nil {
+		machineResult.print(code, err)
+	}
+	return code
+}
+
+func (r *MachineResult) print(code int, err error) {
+	if r.printed {
+		return
+	}
+	r.printed = true
+	r.Rc = code
+	r.Success = err == nil
+	if err != nil {
+		r.Error = err.Error()
+		r.Code = ErrorCodeOf(err)
+	}
+	if r.changed != nil {
+		r.Changed = *r.changed
+	} else {
+		r.Changed = r.Success && !r.readOnly
+	}
+	data, jsonErr := json.Marshal(r)
+	if jsonErr != nil {
+		data = []byte(fmt.Sprintf(`{"success": false, "rc": %d, "error": %q}`, ExitFailure, jsonErr.Error()))
+	}
+	fmt.Fprintln(r.stdout, string(data))
+}
+
+// machineWriter collects the log messages for the machine result.
+type machineWriter struct{}
+
+func (w machineWriter) Write(p []byte) (n int, err error) {
+	var event map[string]interface{}
+	if err := json.Unmarshal(p, &event); err != nil {
+		return len(p), nil
+	}
+	message := machineMessage{
+		Level:   fmt.Sprint(event[zerolog.LevelFieldName]),
+		Message: Redact(fmt.Sprint(event[zerolog.MessageFieldName])),
+	}
+	if errValue, ok := event[zerolog.ErrorFieldName]; ok {
+		message.Message += ": " + Redact(fmt.Sprint(errValue))
+	}
+	if machineResult != nil {
+		machineResult.Messages = append(machineResult.Messages, message)
+		// zerolog exits right after writing a fatal message: print the result now
+		if message.Level == zerolog.LevelFatalValue {
+			var err error = errors.New(message.Message)
+			if code, ok := event[ErrorCodeField].(string); ok {
+				err = WithCode(ErrorCode(code), err)
+			}
+			machineResult.print(ExitFailure, err)
+		}
+	}
+	return len(p), nil
+}
+
+// logErrorCode writes the error and its code to the log file.
+//
+// The console already gets the error message from cobra.
+func logErrorCode(err error) {
+	if err == nil || logFileWriter == nil {
+		return
+	}
+	logger := zerolog.New(logFileWriter).With().Timestamp().Logger()
+	logger.Error().Err(err).Str(ErrorCodeField, string(ErrorCodeOf(err))).Msg(L("Command failed"))
+}
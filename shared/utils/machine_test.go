@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func TestExitCode(t *testing.T) {
+	data := map[error]int{
+		nil:                                    ExitSuccess,
+		errors.New("failure"):                  ExitFailure,
+		UsageError(errors.New("invalid flag")): ExitUsage,
+		fmt.Errorf("wrapped: %w", UsageError(errors.New("invalid config"))): ExitUsage,
+	}
+	for err, expected := range data {
+		if actual := ExitCode(err); actual != expected {
+			t.Errorf("expected exit code %d for %v, got %d", expected, err, actual)
+		}
+	}
+}
+
+func TestMachineResult(t *testing.T) {
+	oldLogger := log.Logger
+	defer func() {
+		log.Logger = oldLogger
+		machineResult = nil
+	}()
+
+	var out bytes.Buffer
+	machineResult = &MachineResult{
+		Command:  "mgradm status",
+		Messages: []machineMessage{},
+		Data:     map[string]interface{}{},
+		readOnly: true,
+		stdout:   &out,
+	}
+	log.Logger = zerolog.New(machineWriter{})
+	log.Info().Msg("Server is running")
+	log.Warn().Err(errors.New("boom")).Msg("Failed to read the state")
+	AddMachineData("image", "server:latest")
+
+	if code := FinishCommand(nil); code != ExitSuccess {
+		t.Errorf("unexpected exit code %d", code)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON output %s: %s", out.String(), err)
+	}
+	if result["success"] != true || result["changed"] != false || result["rc"] != float64(0) {
+		t.Errorf("unexpected result: %s", out.String())
+	}
+	messages := result["messages"].([]interface{})
+	if len(messages) != 2 || messages[1].(map[string]interface{})["message"] != "Failed to read the state: boom" {
+		t.Errorf("unexpected messages: %v", messages)
+	}
+	if result["data"].(map[string]interface{})["image"] != "server:latest" {
+		t.Errorf("unexpected data: %v", result["data"])
+	}
+
+	// The result is printed only once
+	FinishCommand(errors.New("failure"))
+	if bytes.Count(out.Bytes(), []byte("\n")) != 1 {
+		t.Errorf("expected a single JSON document, got %s", out.String())
+	}
+}
+
+func TestMachineResultErrorCode(t *testing.T) {
+	defer func() {
+		machineResult = nil
+	}()
+
+	var out bytes.Buffer
+	machineResult = &MachineResult{
+		Command:  "mgradm install podman",
+		Messages: []machineMessage{},
+		Data:     map[string]interface{}{},
+		stdout:   &out,
+	}
+	err := fmt.Errorf("install failed: %w", WithCode(CodeImagePull, errors.New("no such image")))
+	if code := FinishCommand(err); code != ExitFailure {
+		t.Errorf("unexpected exit code %d", code)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON output %s: %s", out.String(), err)
+	}
+	if result["errorCode"] != string(CodeImagePull) {
+		t.Errorf("unexpected error code: %s", out.String())
+	}
+}
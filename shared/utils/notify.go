@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// SMTPNotification is the configuration of the email notifications.
+type SMTPNotification struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	From     string
+	To       []string
+}
+
+// Notifications is the configuration of the notifications sent at the end of long operations.
+type Notifications struct {
+	SMTP SMTPNotification `mapstructure:"smtp"`
+	// Webhook is a Slack or Mattermost incoming webhook URL.
+	Webhook string
+}
+
+// notifications are the notifications configured for the running command.
+var notifications Notifications
+
+// sendMail is the function sending the emails, overridden in the tests.
+var sendMail = smtp.SendMail
+
+// readNotifications reads the notifications from the notifications entry of the configuration.
+func readNotifications(v *viper.Viper) error {
+	notifications = Notifications{}
+	if err := v.UnmarshalKey("notifications", &notifications); err != nil {
+		return Errorf(err, L("failed to read the notifications configuration: %s"))
+	}
+	if notifications.SMTP.Host != "" && len(notifications.SMTP.To) == 0 {
+		return errors.New(L("the SMTP notification needs at least one recipient"))
+	}
+	return nil
+}
+
+// notificationSummary returns the subject and text of the notification of an operation result.
+func notificationSummary(command string, duration time.Duration, err error) (string, string) {
+	hostname, hostErr := os.Hostname()
+	if hostErr != nil {
+		hostname = "localhost"
+	}
+	result := L("succeeded")
+	if err != nil {
+		result = L("failed")
+	}
+	subject := fmt.Sprintf(L("%[1]s %[2]s on %[3]s"), command, result, hostname)
+
+	var text strings.Builder
+	text.WriteString(subject + "\n")
+	text.WriteString(fmt.Sprintf(L("Duration: %s"), duration.Round(time.Second)) + "\n")
+	if err != nil {
+		text.WriteString(fmt.Sprintf(L("Error: %s"), Redact(err.Error())) + "\n")
+	}
+	if logFile := GetLogFilePath(); logFile != "" {
+		text.WriteString(fmt.Sprintf(L("Log file: %s"), logFile) + "\n")
+	}
+	return subject, text.String()
+}
+
+// Notify sends the configured notifications with the result of an operation.
+//
+// Notification failures are only logged to preserve the operation result.
+func Notify(command string, duration time.Duration, err error) {
+	subject, text := notificationSummary(command, duration, err)
+	if notifications.SMTP.Host != "" {
+		if mailErr := sendMailNotification(notifications.SMTP, subject, text); mailErr != nil {
+			log.Warn().Err(mailErr).Msg(L("Failed to send the email notification"))
+		}
+	}
+	if notifications.Webhook != "" {
+		if hookErr := sendWebhookNotification(notifications.Webhook, text); hookErr != nil {
+			log.Warn().Err(hookErr).Msg(L("Failed to send the webhook notification"))
+		}
+	}
+}
+
+func sendMailNotification(config SMTPNotification, subject string, text string) error {
+	port := config.Port
+	if port == 0 {
+		port = 25
+	}
+	address := net.JoinHostPort(config.Host, strconv.Itoa(port))
+	from := config.From
+	if from == "" {
+		from = "uyuni-tools@localhost"
+	}
+
+	var auth smtp.Auth
+	if config.User != "" {
+		auth = smtp.PlainAuth("", config.User, config.Password, config.Host)
+	}
+
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s",
+		from, strings.Join(config.To, ", "), subject, strings.ReplaceAll(text, "\n", "\r\n"))
+	log.Debug().Msgf("Sending email notification to %s", strings.Join(config.To, ", "))
+	return sendMail(address, auth, from, config.To, []byte(message))
+}
+
+func sendWebhookNotification(url string, text string) error {
+	// Slack and Mattermost incoming webhooks both accept this payload
+	data, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	log.Debug().Msgf("Sending webhook notification to %s", url)
+	client := http.Client{Timeout: hookTimeout}
+	response, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf(L("webhook returned %s"), response.Status)
+	}
+	return nil
+}
+
+// WithNotification wraps a command function to send the configured notifications when it is done.
+func WithNotification[T interface{}](fn CommandFunc[T]) CommandFunc[T] {
+	return func(globalFlags *types.GlobalFlags, flags *T, cmd *cobra.Command, args []string) error {
+		start := time.Now()
+		err := fn(globalFlags, flags, cmd, args)
+		Notify(cmd.CommandPath(), time.Since(start), err)
+		return err
+	}
+}
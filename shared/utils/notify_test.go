@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	defer func() {
+		notifications = Notifications{}
+		sendMail = smtp.SendMail
+	}()
+
+	var text string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		text = payload["text"]
+	}))
+	defer server.Close()
+
+	var mailAddress string
+	var mailTo []string
+	var mailContent string
+	sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		mailAddress = addr
+		mailTo = to
+		mailContent = string(msg)
+		return nil
+	}
+
+	notifications = Notifications{
+		SMTP: SMTPNotification{
+			Host: "smtp.example.com",
+			To:   []string{"admin@example.com"},
+		},
+		Webhook: server.URL,
+	}
+	Notify("mgradm upgrade podman", 90*time.Minute, errors.New("image not found"))
+
+	if !strings.Contains(text, "mgradm upgrade podman failed on") || !strings.Contains(text, "Duration: 1h30m0s") ||
+		!strings.Contains(text, "Error: image not found") {
+		t.Errorf("unexpected webhook text: %s", text)
+	}
+	if mailAddress != "smtp.example.com:25" || len(mailTo) != 1 || mailTo[0] != "admin@example.com" {
+		t.Errorf("unexpected email recipient %v on %s", mailTo, mailAddress)
+	}
+	if !strings.Contains(mailContent, "Subject: mgradm upgrade podman failed on") {
+		t.Errorf("unexpected email content: %s", mailContent)
+	}
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// OutputFlags are the flags selecting how to print the result of the listing commands.
+type OutputFlags struct {
+	Output string
+}
+
+// AddOutputFlag adds the --output flag to a command listing things.
+func AddOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "table", L("output format. Possible values: table, json"))
+}
+
+// Table is the result of a listing command.
+type Table struct {
+	// Headers are the column titles of the table output.
+	Headers []string
+	// Rows are the values of the table output.
+	Rows [][]string
+	// Data is the value to print in JSON.
+	Data interface{}
+}
+
+// Print writes the table to the standard output in the requested format.
+func (t *Table) Print(format string) error {
+	AddMachineData("result", t.Data)
+	return t.write(os.Stdout, format)
+}
+
+func (t *Table) write(out io.Writer, format string) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(t.Data, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(data))
+	case "table", "":
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(t.Headers, "\t"))
+		for _, row := range t.Rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	default:
+		return UsageError(fmt.Errorf(L("invalid output format %s: possible values are table and json"), format))
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTableWrite(t *testing.T) {
+	table := Table{
+		Headers: []string{"ID", "NAME"},
+		Rows:    [][]string{{"1", "server"}, {"1000", "proxy"}},
+		Data:    []map[string]interface{}{{"id": 1, "name": "server"}},
+	}
+
+	data := map[string]string{
+		"table": "ID    NAME\n1     server\n1000  proxy\n",
+		"json":  "[\n  {\n    \"id\": 1,\n    \"name\": \"server\"\n  }\n]\n",
+	}
+	for format, expected := range data {
+		var out bytes.Buffer
+		if err := table.write(&out, format); err != nil {
+			t.Errorf("unexpected error for %s format: %s", format, err)
+		}
+		if actual := out.String(); actual != expected {
+			t.Errorf("unexpected %s output:\n%s", format, actual)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := table.write(&out, "xml"); ExitCode(err) != ExitUsage {
+		t.Errorf("expected a usage error for an invalid format, got %v", err)
+	}
+}
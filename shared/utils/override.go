@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"gopkg.in/yaml.v2"
+)
+
+// TemplateOverridesDir is the folder containing the user overrides of the generated systemd services.
+//
+// The overrides of a service are read from a file named after the generated one with a .yaml suffix,
+// for instance uyuni-server.service.yaml.
+const TemplateOverridesDir = "/etc/uyuni/templates"
+
+// templateOverridesDir can be changed for the tests.
+var templateOverridesDir = TemplateOverridesDir
+
+// OverrideVolume is a volume added to a service container.
+type OverrideVolume struct {
+	// Name is the name of the podman volume or the path of the host folder to mount.
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+}
+
+// ServiceOverride contains the user additions merged into a generated systemd service.
+type ServiceOverride struct {
+	Volumes  []OverrideVolume  `yaml:"volumes,omitempty"`
+	Env      map[string]string `yaml:"env,omitempty"`
+	After    []string          `yaml:"after,omitempty"`
+	Requires []string          `yaml:"requires,omitempty"`
+	Args     []string          `yaml:"args,omitempty"`
+}
+
+// Overridable is implemented by the templates of the systemd services accepting user overrides.
+type Overridable interface {
+	Template
+	// WithOverride returns a copy of the template data with the override.
+	WithOverride(override ServiceOverride) Template
+}
+
+var unitNameRegex = regexp.MustCompile(`^[[:alnum:]@._:-]+$`)
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate checks the override values.
+func (o ServiceOverride) Validate() error {
+	for _, volume := range o.Volumes {
+		if volume.Name == "" || !path.IsAbs(volume.Path) || strings.ContainsAny(volume.Name+volume.Path, ": \t\n") {
+			return fmt.Errorf(L("invalid volume %[1]s:%[2]s: a name and an absolute path are required"), volume.Name, volume.Path)
+		}
+	}
+	for name := range o.Env {
+		if !envNameRegex.MatchString(name) {
+			return fmt.Errorf(L("invalid environment variable name: %s"), name)
+		}
+	}
+	for _, unit := range append(append([]string{}, o.After...), o.Requires...) {
+		if !unitNameRegex.MatchString(unit) {
+			return fmt.Errorf(L("invalid systemd unit name: %s"), unit)
+		}
+	}
+	return nil
+}
+
+// EnvironmentLines returns the systemd Environment lines setting the override variables, sorted by name.
+func (o ServiceOverride) EnvironmentLines() []string {
+	lines := []string{}
+	for name, value := range o.Env {
+		lines = append(lines, "Environment="+SystemdQuote(name+"="+value, false))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// PodmanArgs returns the podman run arguments adding the override volumes, variables and extra arguments.
+func (o ServiceOverride) PodmanArgs() string {
+	args := []string{}
+	for _, volume := range o.Volumes {
+		args = append(args, "-v", volume.Name+":"+volume.Path)
+	}
+
+	names := make([]string, 0, len(o.Env))
+	for name := range o.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "-e", name)
+	}
+
+	for _, arg := range o.Args {
+		args = append(args, SystemdQuote(arg, true))
+	}
+	return strings.Join(args, " ")
+}
+
+// ReadServiceOverride reads the user override for a generated file.
+//
+// A nil override is returned if there is none.
+func ReadServiceOverride(generatedPath string) (*ServiceOverride, error) {
+	overridePath := filepath.Join(templateOverridesDir, filepath.Base(generatedPath)+".yaml")
+	data, err := os.ReadFile(overridePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, Errorf(err, L("failed to read %[1]s: %[2]s"), overridePath)
+	}
+
+	var override ServiceOverride
+	if err := yaml.UnmarshalStrict(data, &override); err != nil {
+		return nil, Errorf(err, L("failed to parse %[1]s: %[2]s"), overridePath)
+	}
+	if err := override.Validate(); err != nil {
+		return nil, Errorf(err, L("invalid override in %[1]s: %[2]s"), overridePath)
+	}
+	log.Info().Msgf(L("Applying the overrides from %s"), overridePath)
+	return &override, nil
+}
+
+// SystemdQuote quotes a value for a systemd unit file.
+// The $ character is only escaped for the Exec lines as it is not substituted in the other ones.
+func SystemdQuote(value string, exec bool) string {
+	replacements := []string{`\`, `\\`, `"`, `\"`, "%", "%%"}
+	if exec {
+		replacements = append(replacements, "$", "$$")
+	}
+	return `"` + strings.NewReplacer(replacements...).Replace(value) + `"`
+}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type overridableTemplate struct {
+	Override ServiceOverride
+}
+
+func (data overridableTemplate) Render(wr io.Writer) error {
+	_, err := fmt.Fprintf(wr, "after=%v args=%s", data.Override.After, data.Override.PodmanArgs())
+	return err
+}
+
+func (data overridableTemplate) WithOverride(override ServiceOverride) Template {
+	data.Override = override
+	return data
+}
+
+func TestWriteTemplateToFileOverride(t *testing.T) {
+	templateOverridesDir = t.TempDir()
+	defer func() { templateOverridesDir = TemplateOverridesDir }()
+	outDir := t.TempDir()
+
+	// No override file
+	path := filepath.Join(outDir, "foo.service")
+	if err := WriteTemplateToFile(overridableTemplate{}, path, 0600, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testFileContent(t, path, "after=[] args=")
+
+	override := `after: [bar.service]
+volumes:
+- {name: data, path: /data}
+env:
+  B: "2"
+  A: "1"
+args: [--device, /dev/kvm, $HOME]
+`
+	if err := os.WriteFile(filepath.Join(templateOverridesDir, "foo.service.yaml"), []byte(override), 0600); err != nil {
+		t.Fatalf("failed to write override: %s", err)
+	}
+	if err := WriteTemplateToFile(overridableTemplate{}, path, 0600, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testFileContent(t, path, `after=[bar.service] args=-v data:/data -e A -e B "--device" "/dev/kvm" "$$HOME"`)
+
+	// Invalid overrides are reported
+	for _, invalid := range []string{"unknown: value", "after: ['foo bar']", "env: {1A: b}", "volumes: [{name: data, path: relative}]"} {
+		if err := os.WriteFile(filepath.Join(templateOverridesDir, "foo.service.yaml"), []byte(invalid), 0600); err != nil {
+			t.Fatalf("failed to write override: %s", err)
+		}
+		if err := WriteTemplateToFile(overridableTemplate{}, path, 0600, true); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+}
+
+func TestEnvironmentLines(t *testing.T) {
+	override := ServiceOverride{Env: map[string]string{"B": `a "quoted" 100%`, "A": "1"}}
+	lines := override.EnvironmentLines()
+	expected := []string{`Environment="A=1"`, `Environment="B=a \"quoted\" 100%%"`}
+	if fmt.Sprint(lines) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, lines)
+	}
+}
+
+func testFileContent(t *testing.T, path string, expected string) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %s", path, err)
+	}
+	if string(content) != expected {
+		t.Errorf("expected %q, got %q", expected, string(content))
+	}
+}
@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// PasswordPolicy describes the complexity required for the passwords.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters.
+	MinLength int
+	// MaxLength is the maximum number of characters, 0 for no limit.
+	MaxLength int
+	// MinClasses is the minimum number of character classes among lower case and upper case letters,
+	// digits and symbols.
+	MinClasses int
+	// DenyList are forbidden passwords, compared case insensitively.
+	DenyList []string
+	// DenyCommon forbids the common weak passwords too.
+	DenyCommon bool
+}
+
+// commonPasswords are the weak passwords denied with DenyCommon.
+var commonPasswords = []string{
+	"password", "passw0rd", "p@ssw0rd", "admin", "admin123", "administrator", "root", "toor", "secret",
+	"changeme", "letmein", "welcome", "qwerty", "qwertz", "azerty", "123456", "12345678", "123456789",
+	"1234567890", "abc123", "iloveyou", "uyuni", "susemanager", "spacewalk", "linux", "suse",
+}
+
+// Characters used to generate the passwords, by class.
+// The symbols are limited to the ones not needing to be quoted in shells, URLs and configuration files.
+var passwordClasses = []string{
+	"abcdefghijkmnopqrstuvwxyz",
+	"ABCDEFGHJKLMNPQRSTUVWXYZ",
+	"23456789",
+	"-_.+",
+}
+
+// passwordClassesCount returns the number of character classes used in a password.
+func passwordClassesCount(password string) int {
+	var lower, upper, digit, symbol int
+	for _, char := range password {
+		switch {
+		case unicode.IsLower(char):
+			lower = 1
+		case unicode.IsUpper(char):
+			upper = 1
+		case unicode.IsDigit(char):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// Check returns an error describing why the password doesn't comply with the policy.
+func (p *PasswordPolicy) Check(password string) error {
+	if strings.ContainsAny(password, " \t") {
+		return errors.New(L("cannot contain spaces or tabs"))
+	}
+	length := len([]rune(password))
+	if length < p.MinLength {
+		return fmt.Errorf(NL("has to be at least %d character long", "has to be at least %d characters long", p.MinLength), p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return fmt.Errorf(NL("has to be at most %d character long", "has to be at most %d characters long", p.MaxLength), p.MaxLength)
+	}
+	if passwordClassesCount(password) < p.MinClasses {
+		return fmt.Errorf(L("has to contain at least %d of lower case letters, upper case letters, digits and symbols"), p.MinClasses)
+	}
+	lists := [][]string{p.DenyList}
+	if p.DenyCommon {
+		lists = append(lists, commonPasswords)
+	}
+	for _, list := range lists {
+		for _, denied := range list {
+			if denied != "" && strings.EqualFold(password, denied) {
+				return errors.New(L("is too common or forbidden"))
+			}
+		}
+	}
+	return nil
+}
+
+// Checker returns a function printing why a password is rejected, to be used when prompting it.
+func (p *PasswordPolicy) Checker() func(string) bool {
+	return func(password string) bool {
+		if err := p.Check(password); err != nil {
+			fmt.Println(err)
+			return false
+		}
+		return true
+	}
+}
+
+// ReadDenyList reads a file with one forbidden password per line.
+//
+// The empty lines and the ones starting with a # are ignored.
+func ReadDenyList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, Errorf(err, L("failed to read the passwords deny list: %s"))
+	}
+	defer file.Close()
+
+	denied := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			denied = append(denied, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, Errorf(err, L("failed to read the passwords deny list: %s"))
+	}
+	return denied, nil
+}
+
+// GeneratePassword creates a random password containing all the character classes.
+func GeneratePassword(length int) string {
+	if length < len(passwordClasses) {
+		length = len(passwordClasses)
+	}
+	randomChar := func(chars string) rune {
+		index, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+		if err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to read random data"))
+		}
+		return rune(chars[index.Int64()])
+	}
+
+	password := []rune{}
+	for _, class := range passwordClasses {
+		password = append(password, randomChar(class))
+	}
+	all := strings.Join(passwordClasses, "")
+	for len(password) < length {
+		password = append(password, randomChar(all))
+	}
+
+	// Shuffle to avoid the classes always being at the start
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			log.Fatal().Err(err).Msg(L("Failed to read random data"))
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+	return string(password)
+}
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 8, MaxLength: 12, MinClasses: 3, DenyList: []string{"Uyuni-2024"}, DenyCommon: true}
+	data := []struct {
+		password string
+		valid    bool
+	}{
+		{"Sh0rt", false},
+		{"Way2Long-Password", false},
+		{"alllowercase", false},
+		{"lower-and.sym", false},
+		{"Lower4ndUp", true},
+		{"lower-4nd.42", true},
+		{"with space1A", false},
+		{"Passw0rd", false},
+		{"P@ssw0rd", false},
+		{"uyuni-2024", false},
+		{"Ünïcode-4ok", true},
+	}
+	for i, test := range data {
+		err := policy.Check(test.password)
+		if test.valid && err != nil {
+			t.Errorf("case %d: expected %s to be valid, got: %s", i, test.password, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("case %d: expected %s to be rejected", i, test.password)
+		}
+	}
+
+	policy.DenyCommon = false
+	if err := policy.Check("P@ssw0rd"); err != nil {
+		t.Errorf("common passwords should only be rejected on demand, got: %s", err)
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 24, MaxLength: 24, MinClasses: 4}
+	previous := ""
+	for i := 0; i < 20; i++ {
+		password := GeneratePassword(24)
+		if err := policy.Check(password); err != nil {
+			t.Errorf("generated password %s is not strong enough: %s", password, err)
+		}
+		if password == previous {
+			t.Errorf("generated the same password twice: %s", password)
+		}
+		previous = password
+	}
+	if actual := GeneratePassword(2); len(actual) != len(passwordClasses) {
+		t.Errorf("expected a password using all the classes, got %s", actual)
+	}
+}
+
+func TestReadDenyList(t *testing.T) {
+	denyListPath := path.Join(t.TempDir(), "denylist")
+	content := "# Company passwords\nExample2024\n\n  Summer-24  \n"
+	if err := os.WriteFile(denyListPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	denied, err := ReadDenyList(denyListPath)
+	if err != nil {
+		t.Fatalf("failed to read the deny list: %s", err)
+	}
+	if expected := []string{"Example2024", "Summer-24"}; !reflect.DeepEqual(denied, expected) {
+		t.Errorf("expected %v, got %v", expected, denied)
+	}
+
+	if _, err := ReadDenyList(path.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("a missing deny list should fail")
+	}
+}
+
+func TestIsSecretFlag(t *testing.T) {
+	expected := map[string]bool{
+		"admin-password":       true,
+		"db-admin-password":    true,
+		"approval-token":       true,
+		"scc-password":         true,
+		"password-length":      false,
+		"password-classes":     false,
+		"password-output":      false,
+		"generate-password":    false,
+		"ingress-tls-secret":   false,
+		"token-file":           false,
+		"tz":                   false,
+		"password-deny-common": false,
+	}
+	for name, secret := range expected {
+		if actual := IsSecretFlag(name); actual != secret {
+			t.Errorf("expected %s secret to be %v, got %v", name, secret, actual)
+		}
+	}
+}
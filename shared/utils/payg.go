@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// PaygFlavor is the instance flavor of the pay-as-you-go public cloud instances.
+const PaygFlavor = "PAYG"
+
+// ByosFlavor is the instance flavor of the bring-your-own-subscription public cloud instances.
+const ByosFlavor = "BYOS"
+
+// instanceFlavorCheckPath is the tool of the public cloud images printing the instance flavor, relative to the root folder.
+const instanceFlavorCheckPath = "usr/bin/instance-flavor-check"
+
+// PaygHostPaths are the host files and folders of the public cloud images describing the instance.
+//
+// The containers need them to query the instance metadata and the cloud update infrastructure.
+var PaygHostPaths = []string{
+	"/etc/regionserverclnt.cfg",
+	"/var/lib/cloudregister",
+	"/var/cache/cloudregister",
+	"/usr/lib/zypp/plugins/services",
+}
+
+// readInstanceFlavor returns PAYG or BYOS on public cloud images and an empty string elsewhere.
+func readInstanceFlavor() string {
+	if _, err := os.Stat(filepath.Join(hostRoot, instanceFlavorCheckPath)); err != nil {
+		return ""
+	}
+	// The tool reports the flavor with a non-zero exit code too: only rely on its output
+	out, _ := RunCmdOutput(zerolog.DebugLevel, "/"+instanceFlavorCheckPath)
+	flavor := strings.TrimSpace(string(out))
+	if flavor != PaygFlavor && flavor != ByosFlavor {
+		return ""
+	}
+	return flavor
+}
+
+// IsPayg returns whether the inspected host values are the ones of a pay-as-you-go public cloud instance.
+func IsPayg(hostValues map[string]string) bool {
+	return hostValues["host_instance_flavor"] == PaygFlavor
+}
+
+// IsPaygHost returns whether the host is a pay-as-you-go public cloud instance.
+func IsPaygHost() bool {
+	return readInstanceFlavor() == PaygFlavor
+}
@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadInstanceFlavor(t *testing.T) {
+	hostRoot = t.TempDir()
+	defer func() { hostRoot = "/" }()
+
+	if flavor := readInstanceFlavor(); flavor != "" {
+		t.Errorf("expected no flavor without instance-flavor-check, got %s", flavor)
+	}
+
+	writeTestFile(t, filepath.Join(hostRoot, instanceFlavorCheckPath), "#!/bin/sh\n")
+	data := []struct {
+		output   string
+		expected string
+	}{
+		{"PAYG\n", PaygFlavor},
+		{"BYOS\n", ByosFlavor},
+		{"unknown\n", ""},
+	}
+	for i, test := range data {
+		restore := SetCommandRunner(func(command string, args []string, stdout, stderr io.Writer) error {
+			if command != "/"+instanceFlavorCheckPath {
+				t.Errorf("Testcase %d: unexpected command %s", i, command)
+			}
+			fmt.Fprint(stdout, test.output)
+			// The tool exits with a non-zero code
+			return fmt.Errorf("exit status 10")
+		})
+		if flavor := readInstanceFlavor(); flavor != test.expected {
+			t.Errorf("Testcase %d: expected %q, got %q", i, test.expected, flavor)
+		}
+		restore()
+	}
+}
+
+func TestIsPayg(t *testing.T) {
+	if !IsPayg(map[string]string{"host_instance_flavor": PaygFlavor}) {
+		t.Error("expected a PAYG host")
+	}
+	if IsPayg(map[string]string{"host_instance_flavor": ByosFlavor}) || IsPayg(map[string]string{}) {
+		t.Error("expected a non PAYG host")
+	}
+}
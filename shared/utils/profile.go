@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+const profileFlag = "profile"
+const profilesDir = "profiles"
+const profileExt = ".yaml"
+
+// defaultProfileFile is the file storing the name of the profile to use when --profile is not passed.
+const defaultProfileFile = "profile"
+
+var profileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]*$`)
+
+// AddProfileFlag adds the flag selecting the configuration profile.
+func AddProfileFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(profileFlag, "",
+		L("name of the configuration profile to use. Defaults to the one selected with mgradm profile use"))
+}
+
+// configDir returns the folder containing the configuration files of the tools or an empty string if not found.
+func configDir() string {
+	xdgConfigHome := os.Getenv("XDG_CONFIG_HOME")
+	if xdgConfigHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			log.Err(err).Msg(L("Failed to find home directory"))
+			return ""
+		}
+		xdgConfigHome = path.Join(home, ".config")
+	}
+	return path.Join(xdgConfigHome, appName)
+}
+
+// ProfilePath returns the path to the configuration file of a profile.
+func ProfilePath(name string) string {
+	return path.Join(configDir(), profilesDir, name+profileExt)
+}
+
+func validateProfileName(name string) error {
+	if !profileNameRegex.MatchString(name) {
+		return fmt.Errorf(L("invalid profile name %s: only letters, digits, '.', '-' and '_' are allowed"), name)
+	}
+	return nil
+}
+
+// ListProfiles returns the sorted names of the configuration profiles.
+func ListProfiles() ([]string, error) {
+	entries, err := os.ReadDir(path.Join(configDir(), profilesDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, Errorf(err, L("failed to list the configuration profiles: %s"))
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		name, isProfile := strings.CutSuffix(entry.Name(), profileExt)
+		if isProfile && !entry.IsDir() && validateProfileName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// DefaultProfile returns the name of the profile used when --profile is not passed, or an empty string.
+func DefaultProfile() string {
+	data, err := os.ReadFile(path.Join(configDir(), defaultProfileFile))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// SetDefaultProfile persists the profile to use when --profile is not passed.
+//
+// An empty name removes the default profile.
+func SetDefaultProfile(name string) error {
+	defaultPath := path.Join(configDir(), defaultProfileFile)
+	if name == "" {
+		if err := os.Remove(defaultPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return Errorf(err, L("failed to remove the default profile: %s"))
+		}
+		return nil
+	}
+
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	if !FileExists(ProfilePath(name)) {
+		return fmt.Errorf(L("profile %[1]s doesn't exist: create %[2]s first"), name, ProfilePath(name))
+	}
+	if err := os.MkdirAll(configDir(), 0700); err != nil {
+		return Errorf(err, L("failed to create the configuration folder: %s"))
+	}
+	if err := os.WriteFile(defaultPath, []byte(name+"\n"), 0600); err != nil {
+		return Errorf(err, L("failed to set the default profile: %s"))
+	}
+	return nil
+}
+
+// activeProfile returns the path to the configuration file of the selected profile or an empty string.
+//
+// A missing default profile is only warned about to allow selecting another one.
+func activeProfile(cmd *cobra.Command) (string, error) {
+	if flag := cmd.Flags().Lookup(profileFlag); flag != nil && flag.Value.String() != "" {
+		name := flag.Value.String()
+		if err := validateProfileName(name); err != nil {
+			return "", err
+		}
+		if !FileExists(ProfilePath(name)) {
+			return "", fmt.Errorf(L("profile %[1]s doesn't exist: create %[2]s first"), name, ProfilePath(name))
+		}
+		return ProfilePath(name), nil
+	}
+
+	name := DefaultProfile()
+	if name == "" {
+		return "", nil
+	}
+	if validateProfileName(name) != nil || !FileExists(ProfilePath(name)) {
+		log.Warn().Msgf(L("Ignoring the missing default profile %s"), name)
+		return "", nil
+	}
+	return ProfilePath(name), nil
+}
+
+// mergeProfile reads the configuration of the selected profile on top of the already read configuration.
+func mergeProfile(cmd *cobra.Command, v *viper.Viper) error {
+	profilePath, err := activeProfile(cmd)
+	if err != nil || profilePath == "" {
+		return err
+	}
+	log.Info().Msgf(L("Using profile %s"), profilePath)
+	v.SetConfigFile(profilePath)
+	if err := v.MergeInConfig(); err != nil {
+		return Errorf(err, L("failed to parse configuration file %s: %s"), profilePath)
+	}
+	return nil
+}
+
+// ApplyProfileToGlobalFlags sets the global flags which are not passed on the command line from the profile.
+//
+// The global flags are used before the configuration is read by the commands, like the remote podman
+// or kubernetes cluster ones, and need to be set this way.
+func ApplyProfileToGlobalFlags(cmd *cobra.Command) error {
+	profilePath, err := activeProfile(cmd)
+	if err != nil || profilePath == "" {
+		return err
+	}
+	v := viper.New()
+	v.SetConfigFile(profilePath)
+	if err := v.ReadInConfig(); err != nil {
+		return Errorf(err, L("failed to parse configuration file %s: %s"), profilePath)
+	}
+
+	var errs []error
+	cmd.Root().PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		key := configKey(f.Name)
+		if f.Changed || f.Name == profileFlag || !v.IsSet(key) {
+			return
+		}
+		var err error
+		if sliceValue, ok := f.Value.(pflag.SliceValue); ok {
+			err = sliceValue.Replace(v.GetStringSlice(key))
+		} else {
+			err = f.Value.Set(v.GetString(key))
+		}
+		if err != nil {
+			errs = append(errs, Errorf(err, L("invalid value for %s in the profile: %s"), f.Name))
+		}
+	})
+	return errors.Join(errs...)
+}
@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func setupProfiles(t *testing.T, profiles map[string]string) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for name, content := range profiles {
+		if err := os.MkdirAll(path.Dir(ProfilePath(name)), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(ProfilePath(name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newProfileCommand(t *testing.T, args ...string) *cobra.Command {
+	root := &cobra.Command{Use: "mgradm"}
+	AddProfileFlag(root)
+	root.PersistentFlags().String("ssh-host", "", "")
+	cmd := &cobra.Command{Use: "install"}
+	cmd.Flags().String("tz", "", "")
+	cmd.Flags().String("organization", "", "")
+	root.AddCommand(cmd)
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("failed to parse the flags: %s", err)
+	}
+	return cmd
+}
+
+func TestProfiles(t *testing.T) {
+	setupProfiles(t, map[string]string{"prod": "", "test": ""})
+	if err := os.WriteFile(path.Join(path.Dir(ProfilePath("prod")), "notes.txt"), []byte{}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := ListProfiles()
+	if err != nil {
+		t.Fatalf("failed to list the profiles: %s", err)
+	}
+	if expected := []string{"prod", "test"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected profiles %v, got %v", expected, names)
+	}
+
+	if err := SetDefaultProfile("missing"); err == nil {
+		t.Error("a missing profile should not be set as default")
+	}
+	if err := SetDefaultProfile("../prod"); err == nil {
+		t.Error("an invalid profile name should not be set as default")
+	}
+	if err := SetDefaultProfile("test"); err != nil {
+		t.Fatalf("failed to set the default profile: %s", err)
+	}
+	if actual := DefaultProfile(); actual != "test" {
+		t.Errorf("expected test default profile, got %q", actual)
+	}
+	if err := SetDefaultProfile(""); err != nil {
+		t.Fatalf("failed to unset the default profile: %s", err)
+	}
+	if actual := DefaultProfile(); actual != "" {
+		t.Errorf("expected no default profile, got %q", actual)
+	}
+}
+
+func TestReadConfigProfile(t *testing.T) {
+	setupProfiles(t, map[string]string{
+		"prod": "tz: Europe/Berlin\n",
+		"test": "tz: Asia/Tokyo\n",
+	})
+	configPath := writeTestConfig(t, "tz: UTC\norganization: Example\n")
+
+	data := []struct {
+		defaultProfile string
+		args           []string
+		expectedTz     string
+	}{
+		{"", []string{}, "UTC"},
+		{"", []string{"--profile", "prod"}, "Europe/Berlin"},
+		{"test", []string{}, "Asia/Tokyo"},
+		{"test", []string{"--profile", "prod"}, "Europe/Berlin"},
+		{"test", []string{"--profile", "prod", "--tz", "America/Lima"}, "America/Lima"},
+	}
+	for i, test := range data {
+		if err := SetDefaultProfile(test.defaultProfile); err != nil {
+			t.Fatalf("case %d: failed to set the default profile: %s", i, err)
+		}
+		v, err := ReadConfig(configPath, newProfileCommand(t, test.args...))
+		if err != nil {
+			t.Fatalf("case %d: failed to read the configuration: %s", i, err)
+		}
+		if actual := v.GetString("tz"); actual != test.expectedTz {
+			t.Errorf("case %d: expected tz %s, got %s", i, test.expectedTz, actual)
+		}
+		if actual := v.GetString("organization"); actual != "Example" {
+			t.Errorf("case %d: the configuration file values should be kept, got organization %q", i, actual)
+		}
+	}
+}
+
+func TestReadConfigMissingProfile(t *testing.T) {
+	setupProfiles(t, map[string]string{"prod": ""})
+	if _, err := ReadConfig("", newProfileCommand(t, "--profile", "missing")); err == nil {
+		t.Error("a missing profile passed with --profile should fail")
+	}
+
+	if err := SetDefaultProfile("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(ProfilePath("prod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadConfig("", newProfileCommand(t)); err != nil {
+		t.Errorf("a missing default profile should be ignored, got: %s", err)
+	}
+}
+
+func TestApplyProfileToGlobalFlags(t *testing.T) {
+	setupProfiles(t, map[string]string{"test": "ssh:\n  host: test.example.com\n"})
+
+	cmd := newProfileCommand(t, "--profile", "test")
+	if err := ApplyProfileToGlobalFlags(cmd); err != nil {
+		t.Fatalf("failed to apply the profile: %s", err)
+	}
+	if actual := cmd.Flags().Lookup("ssh-host").Value.String(); actual != "test.example.com" {
+		t.Errorf("expected ssh-host from the profile, got %q", actual)
+	}
+
+	cmd = newProfileCommand(t, "--profile", "test", "--ssh-host", "other.example.com")
+	if err := ApplyProfileToGlobalFlags(cmd); err != nil {
+		t.Fatalf("failed to apply the profile: %s", err)
+	}
+	if actual := cmd.Flags().Lookup("ssh-host").Value.String(); actual != "other.example.com" {
+		t.Errorf("the command line value should not be overridden, got %q", actual)
+	}
+}
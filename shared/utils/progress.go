@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// ProgressJSON is the value of the --progress flag writing the progress events as JSON lines.
+const ProgressJSON = "json"
+
+// ProgressEvent is a progress event of a long running operation like an installation.
+type ProgressEvent struct {
+	Type    string    `json:"type"`
+	Phase   string    `json:"phase"`
+	Percent int       `json:"percent"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+var (
+	progressMutex  sync.Mutex
+	progressWriter io.Writer
+)
+
+// AddProgressFlag adds the --progress flag to the root command.
+func AddProgressFlag(cmd *cobra.Command, progress *string) {
+	cmd.PersistentFlags().StringVar(progress, "progress", "",
+		L("write the progress of the install, upgrade and migration as JSON lines on the error output. Possible value: json"))
+}
+
+// EnableProgress starts writing the progress events in the requested format on the error output.
+//
+// An empty format disables the progress events.
+func EnableProgress(format string) error {
+	switch format {
+	case "":
+		setProgressWriter(nil)
+	case ProgressJSON:
+		setProgressWriter(os.Stderr)
+	default:
+		return UsageError(fmt.Errorf(L("unsupported progress format: %s"), format))
+	}
+	return nil
+}
+
+// setProgressWriter changes where the progress events are written, nil to disable them.
+func setProgressWriter(writer io.Writer) {
+	progressMutex.Lock()
+	defer progressMutex.Unlock()
+	progressWriter = writer
+}
+
+// Progress reports the progress of an operation made of a known number of steps.
+type Progress struct {
+	phase   string
+	steps   int
+	current int
+}
+
+// NewProgress creates the progress of a phase, like install, with the number of steps it has.
+func NewProgress(phase string, steps int) *Progress {
+	return &Progress{phase: phase, steps: steps}
+}
+
+// Step reports that the next step of the phase is starting.
+func (p *Progress) Step(message string) {
+	percent := p.percent()
+	p.current++
+	writeProgress(p.phase, percent, message)
+}
+
+// StepDone reports that a step of the phase is finished.
+func (p *Progress) StepDone(message string) {
+	p.current++
+	writeProgress(p.phase, p.percent(), message)
+}
+
+// percent computes the percentage of the finished steps.
+//
+// 100 is only reached once the phase is done, even if more steps than expected have been reported.
+func (p *Progress) percent() int {
+	if p.steps <= 0 {
+		return 0
+	}
+	if p.current >= p.steps {
+		return 99
+	}
+	return p.current * 100 / p.steps
+}
+
+// Done reports that the phase is finished.
+func (p *Progress) Done(message string) {
+	p.current = p.steps
+	writeProgress(p.phase, 100, message)
+}
+
+func writeProgress(phase string, percent int, message string) {
+	progressMutex.Lock()
+	defer progressMutex.Unlock()
+	if progressWriter == nil {
+		return
+	}
+	event := ProgressEvent{
+		Type:    "progress",
+		Phase:   phase,
+		Percent: percent,
+		Message: message,
+		Time:    time.Now().UTC(),
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to serialize the progress event")
+		return
+	}
+	if _, err := fmt.Fprintln(progressWriter, string(data)); err != nil {
+		log.Debug().Err(err).Msg("Failed to write the progress event")
+	}
+}
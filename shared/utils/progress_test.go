@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	var buf bytes.Buffer
+	setProgressWriter(&buf)
+	defer setProgressWriter(nil)
+
+	progress := NewProgress("install", 4)
+	progress.Step("Pulling the image")
+	progress.Step("Starting the server")
+	progress.StepDone("Server started")
+	progress.StepDone("Setup done")
+	progress.Done("Server installed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []struct {
+		percent int
+		message string
+	}{
+		{0, "Pulling the image"},
+		{25, "Starting the server"},
+		{75, "Server started"},
+		{99, "Setup done"},
+		{100, "Server installed"},
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d events, got: %s", len(expected), buf.String())
+	}
+	for i, line := range lines {
+		var event ProgressEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid JSON event %q: %s", line, err)
+		}
+		if event.Type != "progress" || event.Phase != "install" {
+			t.Errorf("Unexpected event type or phase: %s", line)
+		}
+		if event.Percent != expected[i].percent || event.Message != expected[i].message {
+			t.Errorf("Expected %d%% %q, got %d%% %q", expected[i].percent, expected[i].message, event.Percent, event.Message)
+		}
+	}
+}
+
+func TestProgressDisabled(t *testing.T) {
+	if err := EnableProgress(""); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	// Nothing should be written nor panic
+	NewProgress("upgrade", 2).Step("Stopping the server")
+
+	if err := EnableProgress("xml"); ExitCode(err) != ExitUsage {
+		t.Errorf("Expected a usage error for an unsupported format, got: %v", err)
+	}
+}
@@ -4,8 +4,8 @@
 
 package utils
 
-// Contains returns true if a string is contained in a string slice.
-func Contains(slice []string, needle string) bool {
+// Contains returns true if a value is contained in a slice.
+func Contains[T comparable](slice []T, needle T) bool {
 	for _, item := range slice {
 		if item == needle {
 			return true
@@ -0,0 +1,246 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"gopkg.in/yaml.v2"
+)
+
+// StateFilePath is the path to the file recording the podman deployment state.
+const StateFilePath = "/etc/uyuni/uyuni-tools.yaml"
+
+// NewDeploymentState creates the state of a new deployment with the flags set by the user, like returned by GetUserFlags.
+func NewDeploymentState(
+	backend string, image string, digest string, volumes []string, userFlags map[string]string,
+) *types.DeploymentState {
+	return &types.DeploymentState{
+		Backend:     backend,
+		Image:       image,
+		Digest:      digest,
+		Flags:       userFlags,
+		Volumes:     volumes,
+		InstalledAt: time.Now().Format(time.RFC3339),
+		ToolVersion: Version,
+	}
+}
+
+// UpdateDeploymentState records an upgrade in the deployment state.
+//
+// If there is no previous state, a new one is created without any flag.
+func UpdateDeploymentState(state *types.DeploymentState, backend string, image string, digest string) *types.DeploymentState {
+	now := time.Now().Format(time.RFC3339)
+	if state == nil {
+		state = &types.DeploymentState{
+			Backend:     backend,
+			InstalledAt: now,
+		}
+	}
+	state.Image = image
+	state.Digest = digest
+	state.UpdatedAt = now
+	state.ToolVersion = Version
+	return state
+}
+
+type commandConfigKey struct{}
+
+// withCommandConfig returns a copy of the context holding the configuration of the command.
+func withCommandConfig(ctx context.Context, config *viper.Viper) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, commandConfigKey{}, config)
+}
+
+// CommandConfig returns the configuration read by CommandHelper for the command.
+//
+// Returns nil if the command is not run by CommandHelper.
+func CommandConfig(cmd *cobra.Command) *viper.Viper {
+	if ctx := cmd.Context(); ctx != nil {
+		if config, ok := ctx.Value(commandConfigKey{}).(*viper.Viper); ok {
+			return config
+		}
+	}
+	return nil
+}
+
+// GetUserFlags returns the values of the flags set by the user, with the secrets redacted.
+//
+// The flags can be set on the command line or, if config is not nil, in the environment or in the
+// configuration files read in config.
+func GetUserFlags(config *viper.Viper, cmd *cobra.Command) map[string]string {
+	flags := map[string]string{}
+	if cmd == nil {
+		return flags
+	}
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		value, isSet := userFlagValue(config, flag)
+		if !isSet {
+			return
+		}
+		if IsSecretFlag(flag.Name) {
+			value = RedactedValue
+		}
+		flags[flag.Name] = value
+	})
+	return flags
+}
+
+// userFlagValue returns the value of a flag and whether it has been set by the user.
+//
+// The lists are joined with commas to be set again with setFlagValue.
+func userFlagValue(config *viper.Viper, flag *pflag.Flag) (string, bool) {
+	if flag.Changed {
+		if values, ok := flag.Value.(pflag.SliceValue); ok {
+			return strings.Join(values.GetSlice(), ","), true
+		}
+		return flag.Value.String(), true
+	}
+	if config == nil || renamedTo(flag) != "" || !config.IsSet(configKey(flag.Name)) {
+		return "", false
+	}
+	switch value := config.Get(configKey(flag.Name)).(type) {
+	case []string:
+		return strings.Join(value, ","), true
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			values = append(values, fmt.Sprint(item))
+		}
+		return strings.Join(values, ","), true
+	default:
+		return fmt.Sprint(value), true
+	}
+}
+
+// setFlagValue sets the value of a flag as if it was passed on the command line.
+func setFlagValue(flag *pflag.Flag, value string) error {
+	if values, ok := flag.Value.(pflag.SliceValue); ok {
+		items := []string{}
+		if value != "" {
+			items = strings.Split(value, ",")
+		}
+		if err := values.Replace(items); err != nil {
+			return err
+		}
+	} else if err := flag.Value.Set(value); err != nil {
+		return err
+	}
+	flag.Changed = true
+	return nil
+}
+
+// ReuseDeploymentState sets the flags recorded in the deployment state which are not set by the user.
+//
+// Only the flags listed in names are reused: the other ones may need to change between the install and the upgrade.
+// The flags structure is then read again from config, the configuration of the command.
+func ReuseDeploymentState(
+	config *viper.Viper, cmd *cobra.Command, state *types.DeploymentState, flags interface{}, names ...string,
+) error {
+	if state == nil {
+		return nil
+	}
+	userFlags := GetUserFlags(config, cmd)
+	reused := false
+	for _, name := range names {
+		value, recorded := state.Flags[name]
+		if _, isSet := userFlags[name]; !recorded || isSet || value == RedactedValue {
+			continue
+		}
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			continue
+		}
+		if err := setFlagValue(flag, value); err != nil {
+			return Errorf(err, L("failed to reuse the recorded %[1]s value: %[2]s"), name)
+		}
+		log.Info().Msgf(L("Reusing the recorded %[1]s value: %[2]s"), name, value)
+		reused = true
+	}
+	if !reused || config == nil {
+		return nil
+	}
+	if err := config.Unmarshal(flags); err != nil {
+		return Errorf(err, L("failed to unmarshall configuration")+": %s")
+	}
+	return nil
+}
+
+// ParseDeploymentState reads a deployment state from its YAML representation.
+func ParseDeploymentState(data []byte) (*types.DeploymentState, error) {
+	var state types.DeploymentState
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return nil, Errorf(err, L("failed to parse the deployment state: %s"))
+	}
+	return &state, nil
+}
+
+// ReadStateFile reads the deployment state file.
+//
+// Returns nil without error if the file doesn't exist.
+func ReadStateFile(statePath string) (*types.DeploymentState, error) {
+	data, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, Errorf(err, L("failed to read file %s: %s"), statePath)
+	}
+	return ParseDeploymentState(data)
+}
+
+// WriteStateFile writes the deployment state to a file.
+func WriteStateFile(statePath string, state *types.DeploymentState) error {
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return Errorf(err, L("failed to serialize the deployment state: %s"))
+	}
+	if err := os.MkdirAll(path.Dir(statePath), 0755); err != nil {
+		return Errorf(err, L("failed to create %s folder: %s"), path.Dir(statePath))
+	}
+	if err := os.WriteFile(statePath, data, 0600); err != nil {
+		return Errorf(err, L("cannot write %s file: %s"), statePath)
+	}
+	return nil
+}
+
+// PrintDeploymentState shows a summary of the deployment state.
+func PrintDeploymentState(state *types.DeploymentState) {
+	AddMachineData("state", state)
+	if state == nil {
+		fmt.Println(L("No deployment state recorded"))
+		return
+	}
+	fmt.Printf(L("Deployed image: %s")+"\n", state.Image)
+	if state.Digest != "" {
+		fmt.Printf(L("Image digest: %s")+"\n", state.Digest)
+	}
+	fmt.Printf(L("Installed at: %s")+"\n", state.InstalledAt)
+	if state.UpdatedAt != "" {
+		fmt.Printf(L("Updated at: %s")+"\n", state.UpdatedAt)
+	}
+	fmt.Printf(L("Deployed with tool version: %s")+"\n", state.ToolVersion)
+}
+
+// GetServerVolumeNames returns the names of the server volumes.
+func GetServerVolumeNames() []string {
+	names := []string{}
+	for _, volume := range ServerVolumeMounts {
+		names = append(names, volume.Name)
+	}
+	return names
+}
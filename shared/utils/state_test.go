@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func TestDeploymentStateFile(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("tag", "latest", "")
+	cmd.Flags().String("admin-password", "", "")
+	cmd.Flags().String("email", "", "")
+	if err := cmd.Flags().Parse([]string{"--tag", "5.0.0", "--admin-password", "secret"}); err != nil {
+		t.Fatalf("Failed to parse flags: %s", err)
+	}
+
+	statePath := path.Join(t.TempDir(), "state.yaml")
+	state := NewDeploymentState("podman", "registry/server:5.0.0", "sha256:1234", []string{"var-pgsql"},
+		GetUserFlags(nil, cmd))
+	if err := WriteStateFile(statePath, state); err != nil {
+		t.Fatalf("Failed to write state: %s", err)
+	}
+
+	read, err := ReadStateFile(statePath)
+	if err != nil {
+		t.Fatalf("Failed to read state: %s", err)
+	}
+	if read.Image != state.Image || read.Digest != state.Digest || read.InstalledAt != state.InstalledAt {
+		t.Errorf("Unexpected state read: %v", read)
+	}
+	if len(read.Flags) != 2 || read.Flags["tag"] != "5.0.0" || read.Flags["admin-password"] != RedactedValue {
+		t.Errorf("Unexpected flags in state: %v", read.Flags)
+	}
+
+	missing, err := ReadStateFile(path.Join(t.TempDir(), "missing.yaml"))
+	if missing != nil || err != nil {
+		t.Errorf("Expected no state and no error for a missing file, got %v, %s", missing, err)
+	}
+}
+
+func readTestConfig(t *testing.T, cmd *cobra.Command, config string) *viper.Viper {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	configPath := path.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	v, err := ReadConfig(configPath, cmd)
+	if err != nil {
+		t.Fatalf("Failed to read the configuration: %s", err)
+	}
+	return v
+}
+
+func TestGetUserFlags(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("tag", "latest", "")
+	cmd.Flags().String("email", "", "")
+	cmd.Flags().String("db-password", "", "")
+	cmd.Flags().StringSlice("ingress-annotation", []string{}, "")
+	cmd.Flags().String("image", "registry/server", "")
+	if err := cmd.Flags().Parse([]string{"--tag", "5.0.0"}); err != nil {
+		t.Fatalf("Failed to parse flags: %s", err)
+	}
+	t.Setenv("UYUNI_EMAIL", "admin@example.com")
+	config := readTestConfig(t, cmd, "db:\n  password: secret\ningress:\n  annotation: [a=b, c=d]\n")
+
+	expected := map[string]string{
+		"tag":                "5.0.0",
+		"email":              "admin@example.com",
+		"db-password":        RedactedValue,
+		"ingress-annotation": "a=b,c=d",
+	}
+	actual := GetUserFlags(config, cmd)
+	if len(actual) != len(expected) {
+		t.Errorf("Expected flags %v, got %v", expected, actual)
+	}
+	for name, value := range expected {
+		if actual[name] != value {
+			t.Errorf("Expected %s flag to be %s, got %s", name, value, actual[name])
+		}
+	}
+}
+
+func TestReuseDeploymentState(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("image", "registry/server", "")
+	cmd.Flags().String("tag", "latest", "")
+	cmd.Flags().StringSlice("ingress-annotation", []string{}, "")
+	cmd.Flags().String("migration-image", "", "")
+	if err := cmd.Flags().Parse([]string{"--migration-image", "registry/migration"}); err != nil {
+		t.Fatalf("Failed to parse flags: %s", err)
+	}
+	config := readTestConfig(t, cmd, "")
+
+	state := &types.DeploymentState{Flags: map[string]string{
+		"image":              "mirror/server",
+		"tag":                "5.0.0",
+		"ingress-annotation": "a=b,c=d",
+		"migration-image":    "mirror/migration",
+	}}
+	var flags struct {
+		Image     string
+		Tag       string
+		Ingress   struct{ Annotation []string }
+		Migration struct{ Image string }
+	}
+	if err := ReuseDeploymentState(config, cmd, state, &flags, "image", "ingress-annotation", "migration-image"); err != nil {
+		t.Fatalf("Failed to reuse the state: %s", err)
+	}
+	if flags.Image != "mirror/server" {
+		t.Errorf("Expected the recorded image, got %s", flags.Image)
+	}
+	if flags.Tag != "latest" {
+		t.Errorf("Expected the tag not to be reused, got %s", flags.Tag)
+	}
+	if len(flags.Ingress.Annotation) != 2 || flags.Ingress.Annotation[1] != "c=d" {
+		t.Errorf("Expected the recorded annotations, got %v", flags.Ingress.Annotation)
+	}
+	if flags.Migration.Image != "registry/migration" {
+		t.Errorf("Expected the migration image set by the user, got %s", flags.Migration.Image)
+	}
+}
+
+func TestCommandConfig(t *testing.T) {
+	cmd := &cobra.Command{}
+	if config := CommandConfig(cmd); config != nil {
+		t.Errorf("Expected no configuration outside of CommandHelper, got %v", config)
+	}
+	config := viper.New()
+	cmd.SetContext(withCommandConfig(cmd.Context(), config))
+	if actual := CommandConfig(cmd); actual != config {
+		t.Errorf("Expected the command configuration, got %v", actual)
+	}
+}
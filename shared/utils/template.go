@@ -5,10 +5,12 @@
 package utils
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 
+	"github.com/rs/zerolog/log"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 )
 
@@ -26,12 +28,39 @@ func WriteTemplateToFile(template Template, path string, perm os.FileMode, overw
 		}
 	}
 
+	content, err := RenderTemplate(template, path)
+	if err != nil {
+		return err
+	}
+	log.Trace().Msgf("Content of %s:\n%s", path, Redact(string(content)))
+
 	// Write the configuration
 	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
 	if err != nil {
-		return fmt.Errorf(L("failed to open %s for writing: %s"), path, err)
+		return Errorf(err, L("failed to open %s for writing: %s"), path)
 	}
 	defer file.Close()
 
-	return template.Render(file)
+	_, err = file.Write(content)
+	return err
+}
+
+// RenderTemplate renders the content of the file at path, merging the user overrides if any.
+func RenderTemplate(template Template, path string) ([]byte, error) {
+	// Merge the user additions surviving the regenerations
+	if overridable, ok := template.(Overridable); ok {
+		override, err := ReadServiceOverride(path)
+		if err != nil {
+			return nil, err
+		}
+		if override != nil {
+			template = overridable.WithOverride(*override)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := template.Render(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
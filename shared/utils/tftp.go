@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// TftpbootPath is the folder holding the TFTP boot files in the server and proxy containers.
+const TftpbootPath = "/srv/tftpboot"
+
+// TftpChecksumsArgs is the command computing the checksums of the TFTP boot files inside a container.
+var TftpChecksumsArgs = []string{"sh", "-c", "cd " + TftpbootPath + " && find . -type f -exec sha256sum {} +"}
+
+// TftpReport is the comparison of the TFTP boot files of a proxy with the server ones.
+type TftpReport struct {
+	Proxy       string   `json:"proxy"`
+	ServerFiles int      `json:"serverFiles"`
+	ProxyFiles  int      `json:"proxyFiles"`
+	Missing     []string `json:"missing"`
+	Different   []string `json:"different"`
+	Extra       []string `json:"extra"`
+}
+
+// InSync returns whether the proxy has all the server boot files with the same content.
+func (r *TftpReport) InSync() bool {
+	return len(r.Missing) == 0 && len(r.Different) == 0
+}
+
+// ParseChecksums reads the output of sha256sum into checksums indexed by the file path.
+func ParseChecksums(out []byte) map[string]string {
+	checksums := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		checksum, file, found := strings.Cut(scanner.Text(), "  ")
+		if !found {
+			continue
+		}
+		checksums[strings.TrimPrefix(file, "./")] = checksum
+	}
+	return checksums
+}
+
+// CompareTftpChecksums compares the checksums of the proxy boot files to the server ones.
+func CompareTftpChecksums(server map[string]string, proxy map[string]string) *TftpReport {
+	report := TftpReport{
+		ServerFiles: len(server),
+		ProxyFiles:  len(proxy),
+		Missing:     []string{},
+		Different:   []string{},
+		Extra:       []string{},
+	}
+	for file, checksum := range server {
+		proxyChecksum, found := proxy[file]
+		if !found {
+			report.Missing = append(report.Missing, file)
+		} else if proxyChecksum != checksum {
+			report.Different = append(report.Different, file)
+		}
+	}
+	for file := range proxy {
+		if _, found := server[file]; !found {
+			report.Extra = append(report.Extra, file)
+		}
+	}
+	sort.Strings(report.Missing)
+	sort.Strings(report.Different)
+	sort.Strings(report.Extra)
+	return &report
+}
+
+// PrintTftpReports prints the TFTP comparison of the proxies and fails if one of them is not in sync.
+func PrintTftpReports(reports []*TftpReport, format string) error {
+	table := Table{
+		Headers: []string{L("PROXY"), L("SERVER FILES"), L("PROXY FILES"), L("MISSING"), L("DIFFERENT"), L("STATUS")},
+		Data:    reports,
+	}
+	inSync := true
+	for _, report := range reports {
+		status := L("in sync")
+		if !report.InSync() {
+			status = L("out of sync")
+			inSync = false
+			for _, file := range report.Missing {
+				log.Debug().Msgf("%s is missing %s", report.Proxy, file)
+			}
+			for _, file := range report.Different {
+				log.Debug().Msgf("%s has a different %s", report.Proxy, file)
+			}
+		}
+		table.Rows = append(table.Rows, []string{
+			report.Proxy, strconv.Itoa(report.ServerFiles), strconv.Itoa(report.ProxyFiles),
+			strconv.Itoa(len(report.Missing)), strconv.Itoa(len(report.Different)), status,
+		})
+	}
+	if err := table.Print(format); err != nil {
+		return err
+	}
+	if !inSync {
+		return errors.New(L("the TFTP boot files are not in sync, use the JSON output or debug logs for the file list"))
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareTftpChecksums(t *testing.T) {
+	server := ParseChecksums([]byte(`aaa  ./pxelinux.0
+bbb  ./images/sles/linux
+ccc  ./images/sles/initrd
+invalid line
+`))
+	proxy := ParseChecksums([]byte(`aaa  ./pxelinux.0
+bbx  ./images/sles/linux
+ddd  ./old/linux
+`))
+	if server["images/sles/linux"] != "bbb" || len(server) != 3 {
+		t.Fatalf("unexpected parsed checksums: %v", server)
+	}
+
+	report := CompareTftpChecksums(server, proxy)
+	expected := &TftpReport{
+		ServerFiles: 3,
+		ProxyFiles:  3,
+		Missing:     []string{"images/sles/initrd"},
+		Different:   []string{"images/sles/linux"},
+		Extra:       []string{"old/linux"},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("expected %v, got %v", expected, report)
+	}
+	if report.InSync() {
+		t.Error("the proxy should not be in sync")
+	}
+
+	if report := CompareTftpChecksums(server, server); !report.InSync() {
+		t.Errorf("identical checksums should be in sync: %v", report)
+	}
+}
@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"context"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The tracing follows the OpenTelemetry environment variables conventions.
+// The OTLP/HTTP exporter reads the endpoint and headers variables itself.
+const (
+	otlpEndpointEnv       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	otlpTracesEndpointEnv = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	serviceNameEnv        = "OTEL_SERVICE_NAME"
+	// traceParentEnv is the W3C trace context of the caller, like a CI pipeline.
+	traceParentEnv = "TRACEPARENT"
+)
+
+// tracerName is the instrumentation scope of the spans.
+const tracerName = "github.com/uyuni-project/uyuni-tools"
+
+// flushTimeout limits the time spent exporting the remaining spans when the command finishes.
+const flushTimeout = 10 * time.Second
+
+// Span is a timed operation of the trace.
+type Span struct {
+	span trace.Span
+	// restore is called when ending the span to set back the previous trace context.
+	restore func()
+}
+
+var (
+	tracingOnce    sync.Once
+	tracerProvider *sdktrace.TracerProvider
+
+	// traceContextMutex protects traceContext, read by the operations run concurrently.
+	traceContextMutex sync.RWMutex
+	// traceContext holds the span of the running command.
+	traceContext = context.Background()
+)
+
+// getTracer returns the tracer configured from the environment.
+//
+// If tracing is disabled, the tracer doesn't record anything.
+func getTracer() trace.Tracer {
+	tracingOnce.Do(func() {
+		tracerProvider = newTracerProvider(os.Getenv)
+		setTraceContext(parentContext(os.Getenv))
+	})
+	if tracerProvider == nil {
+		return trace.NewNoopTracerProvider().Tracer(tracerName)
+	}
+	return tracerProvider.Tracer(tracerName)
+}
+
+// newTracerProvider creates the provider exporting the spans to the OTLP endpoint or nil if there is none.
+//
+// The spans are exported in batches while the command runs.
+func newTracerProvider(getenv func(string) string) *sdktrace.TracerProvider {
+	if getenv(otlpTracesEndpointEnv) == "" && getenv(otlpEndpointEnv) == "" {
+		return nil
+	}
+
+	exporter, err := otlptracehttp.New(context.Background())
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Failed to configure the traces export"))
+		return nil
+	}
+
+	service := getenv(serviceNameEnv)
+	if service == "" {
+		service = path.Base(os.Args[0])
+	}
+	serviceResource := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(service),
+		semconv.ServiceVersion(Version),
+	)
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(serviceResource),
+	)
+}
+
+// parentContext returns a context with the W3C trace context of the caller, if any.
+func parentContext(getenv func(string) string) context.Context {
+	carrier := propagation.MapCarrier{"traceparent": getenv(traceParentEnv)}
+	return propagation.TraceContext{}.Extract(context.Background(), carrier)
+}
+
+func setTraceContext(ctx context.Context) {
+	traceContextMutex.Lock()
+	defer traceContextMutex.Unlock()
+	traceContext = ctx
+}
+
+// TraceContext returns the context holding the span of the running command.
+//
+// This is the parent of the operations which are not given a context, like the executed commands.
+func TraceContext() context.Context {
+	getTracer()
+	traceContextMutex.RLock()
+	defer traceContextMutex.RUnlock()
+	return traceContext
+}
+
+// StartSpan starts a span as a child of the span held by the context.
+//
+// The attributes are key and value pairs. The returned span needs to be ended.
+// The returned context holds the new span to pass it as parent of the nested operations.
+func StartSpan(ctx context.Context, name string, attributes ...string) (context.Context, *Span) {
+	ctx, span := getTracer().Start(ctx, name, trace.WithAttributes(spanAttributes(attributes)...))
+	return ctx, &Span{span: span}
+}
+
+// StartCommandSpan starts the span of the running command.
+//
+// Until it ends, the span is the parent of the operations run by the command without context.
+// If the context has no span, the command is a child of the caller's trace or of the running command.
+func StartCommandSpan(ctx context.Context, name string) (context.Context, *Span) {
+	previous := TraceContext()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = trace.ContextWithSpan(ctx, trace.SpanFromContext(previous))
+	}
+	ctx, span := StartSpan(ctx, name)
+	setTraceContext(ctx)
+	span.restore = func() {
+		setTraceContext(previous)
+	}
+	return ctx, span
+}
+
+func spanAttributes(values []string) []attribute.KeyValue {
+	result := []attribute.KeyValue{}
+	for i := 0; i+1 < len(values); i += 2 {
+		result = append(result, attribute.String(values[i], values[i+1]))
+	}
+	return result
+}
+
+// End ends the span with the error of the operation, nil if it succeeded.
+func (s *Span) End(err error) {
+	s.finish(err)
+	s.span.End()
+	if s.restore != nil {
+		s.restore()
+	}
+}
+
+func (s *Span) finish(err error) {
+	if err != nil {
+		s.span.SetStatus(codes.Error, Redact(err.Error()))
+	} else {
+		s.span.SetStatus(codes.Ok, "")
+	}
+}
+
+// RecordSpan records an already finished operation as a child of the span held by the context.
+func RecordSpan(ctx context.Context, name string, start time.Time, end time.Time, err error) {
+	_, span := getTracer().Start(ctx, name, trace.WithTimestamp(start))
+	s := Span{span: span}
+	s.finish(err)
+	span.End(trace.WithTimestamp(end))
+}
+
+// FlushTracing exports the remaining spans if tracing is enabled.
+//
+// Export failures are only logged to preserve the command result.
+func FlushTracing() {
+	if tracerProvider == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	if err := tracerProvider.ForceFlush(ctx); err != nil {
+		log.Warn().Err(err).Msg(L("Failed to export the traces"))
+	}
+}
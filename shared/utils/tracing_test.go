@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// setTestTracer records the spans in memory for the duration of the test.
+func setTestTracer(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	tracingOnce.Do(func() {})
+	tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	setTraceContext(context.Background())
+	t.Cleanup(func() {
+		tracerProvider = nil
+		setTraceContext(context.Background())
+	})
+	return recorder
+}
+
+func TestNewTracerProvider(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(key string) string { return env[key] }
+
+	if provider := newTracerProvider(getenv); provider != nil {
+		t.Error("tracing should be disabled without endpoint")
+	}
+
+	env[otlpEndpointEnv] = "http://collector:4318/"
+	if provider := newTracerProvider(getenv); provider == nil {
+		t.Error("tracing should be enabled")
+	}
+}
+
+func TestParentContext(t *testing.T) {
+	env := map[string]string{traceParentEnv: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}
+	parent := trace.SpanContextFromContext(parentContext(func(key string) string { return env[key] }))
+	if parent.TraceID().String() != "0af7651916cd43dd8448eb211c80319c" || parent.SpanID().String() != "b7ad6b7169203331" {
+		t.Errorf("unexpected trace parent %s-%s", parent.TraceID(), parent.SpanID())
+	}
+
+	if trace.SpanContextFromContext(parentContext(func(string) string { return "" })).IsValid() {
+		t.Error("unexpected trace parent without environment variable")
+	}
+}
+
+func TestTracing(t *testing.T) {
+	recorder := setTestTracer(t)
+
+	ctx, root := StartCommandSpan(context.Background(), "mgradm upgrade podman")
+	_, child := StartSpan(ctx, "exec podman", "process.command_line", "podman pull")
+	child.End(errors.New("pull failed"))
+
+	// The operations without context are children of the command span, even when run concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, span := StartSpan(TraceContext(), fmt.Sprintf("exec %d", i))
+			span.End(nil)
+		}(i)
+	}
+	wg.Wait()
+	root.End(nil)
+
+	if trace.SpanContextFromContext(TraceContext()).IsValid() {
+		t.Error("the command span should not be the parent anymore once ended")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 12 {
+		t.Fatalf("expected 12 spans, got %d", len(spans))
+	}
+	command := spans[len(spans)-1]
+	if command.Parent().IsValid() || command.Status().Code != codes.Ok {
+		t.Errorf("unexpected command span: %v", command)
+	}
+	for _, span := range spans[:len(spans)-1] {
+		if span.Parent().SpanID() != command.SpanContext().SpanID() ||
+			span.SpanContext().TraceID() != command.SpanContext().TraceID() {
+			t.Errorf("%s span is not a child of the command span", span.Name())
+		}
+	}
+
+	exec := spans[0]
+	if exec.Status().Code != codes.Error || exec.Status().Description != "pull failed" {
+		t.Errorf("unexpected exec span status: %v", exec.Status())
+	}
+	if len(exec.Attributes()) != 1 || exec.Attributes()[0].Value.AsString() != "podman pull" {
+		t.Errorf("unexpected exec span attributes: %v", exec.Attributes())
+	}
+}
@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// hiddenArguments replaces the arguments of commands that must not be logged at all.
+const hiddenArguments = "<hidden arguments>"
+
+// commandTranscript is the record of an external command written to the log file.
+type commandTranscript struct {
+	Level    string `json:"level"`
+	Time     string `json:"time"`
+	Command  string `json:"command"`
+	Output   string `json:"output,omitempty"`
+	ExitCode int    `json:"exitCode"`
+	Error    string `json:"error,omitempty"`
+}
+
+// LogCommand records an external command, its output and exit code in the log file.
+//
+// The record is written whatever the log level to help reconstructing what happened on failures.
+// Set hideArgs to true for commands containing secrets that would not be caught by the redaction.
+func LogCommand(command string, args []string, output []byte, err error, hideArgs bool) {
+	if logFileWriter == nil {
+		return
+	}
+
+	commandLine := strings.TrimSpace(command + " " + strings.Join(args, " "))
+	if hideArgs {
+		commandLine = command + " " + hiddenArguments
+	}
+
+	transcript := commandTranscript{
+		Level:   "command",
+		Time:    time.Now().Format(time.RFC3339),
+		Command: commandLine,
+		Output:  string(output),
+	}
+
+	if err != nil {
+		transcript.ExitCode = -1
+		transcript.Error = err.Error()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			transcript.ExitCode = exitErr.ExitCode()
+			transcript.Output += string(exitErr.Stderr)
+		}
+	}
+
+	// The log file writer redacts the secrets too, but the JSON escaping could hide some of them
+	transcript.Command = Redact(transcript.Command)
+	transcript.Output = Redact(transcript.Output)
+	transcript.Error = Redact(transcript.Error)
+
+	// Don't escape the < and > of the redacted values
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	if encodeErr := encoder.Encode(transcript); encodeErr != nil {
+		return
+	}
+	_, _ = logFileWriter.Write(bytes.TrimSpace(data.Bytes()))
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2024 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestLogCommand(t *testing.T) {
+	logFile := path.Join(t.TempDir(), "test.log")
+	logFileWriter = getFileWriter(logFile)
+	defer func() {
+		logFileWriter.Close()
+		logFileWriter = nil
+	}()
+
+	LogCommand("podman", []string{"pull", "--creds", "user:secret"}, []byte("some output"), nil, true)
+	LogCommand("echo", []string{"--password", "secret"}, nil, errors.New("failed"), false)
+
+	lines := strings.Split(strings.TrimSpace(string(ReadFile(logFile))), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines in the log file, got %d", len(lines))
+	}
+
+	var first commandTranscript
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("Failed to parse transcript line: %s", err)
+	}
+	if first.Command != "podman "+hiddenArguments || first.Output != "some output" || first.ExitCode != 0 {
+		t.Errorf("Unexpected transcript: %v", first)
+	}
+
+	var second commandTranscript
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatalf("Failed to parse transcript line: %s", err)
+	}
+	if second.Command != "echo --password <REDACTED>" || second.ExitCode != -1 || second.Error != "failed" {
+		t.Errorf("Unexpected transcript: %v", second)
+	}
+}
@@ -43,6 +43,7 @@ var inspectValues = []types.InspectData{
 	types.NewInspectData("architecture", "lscpu | grep Architecture | awk '{print $2}' || true"),
 	types.NewInspectData("fqdn", "cat /etc/rhn/rhn.conf 2>/dev/null | grep 'java.hostname' | cut -d' ' -f3 || true"),
 	types.NewInspectData("image_pg_version", "rpm -qa --qf '%{VERSION}\\n' 'name=postgresql[0-8][0-9]-server'  | cut -d. -f1 | sort -n | tail -1 || true"),
+	types.NewInspectData("db_host", "cat /etc/rhn/rhn.conf 2>/dev/null | grep '^db_host' | cut -d' ' -f3 || true"),
 	types.NewInspectData("current_pg_version", "(test -e /var/lib/pgsql/data/PG_VERSION && cat /var/lib/pgsql/data/PG_VERSION) || true"),
 	types.NewInspectData("registration_info", "transactional-update --quiet register --status 2>/dev/null || true"),
 	types.NewInspectData("scc_username", "cat /etc/zypp/credentials.d/SCCcredentials 2>&1 /dev/null | grep username | cut -d= -f2 || true"),
@@ -72,8 +73,9 @@ func checkValueSize(value string, min int, max int) bool {
 }
 
 // AskPasswordIfMissing asks for password if missing.
-// Don't perform any check if min and max are set to 0.
-func AskPasswordIfMissing(value *string, prompt string, min int, max int) {
+// Don't perform any size check if min and max are set to 0.
+// The checker, like the one of a PasswordPolicy, can be nil.
+func AskPasswordIfMissing(value *string, prompt string, min int, max int, checker func(string) bool) {
 	for *value == "" {
 		fmt.Print(prompt + prompt_end)
 		bytePassword, err := term.ReadPassword(int(syscall.Stdin))
@@ -87,7 +89,7 @@ func AskPasswordIfMissing(value *string, prompt string, min int, max int) {
 			fmt.Printf(L("Cannot contain spaces or tabs"))
 		}
 
-		if validChars && checkValueSize(tmpValue, min, max) {
+		if validChars && checkValueSize(tmpValue, min, max) && (checker == nil || checker(tmpValue)) {
 			*value = tmpValue
 		}
 		fmt.Println()
@@ -248,7 +250,7 @@ func GetURLBody(URL string) ([]byte, error) {
 	log.Debug().Msgf("Downloading %s", URL)
 	resp, err := http.Get(URL)
 	if err != nil {
-		return nil, fmt.Errorf(L("error downloading from %s: %s"), URL, err)
+		return nil, Errorf(err, L("error downloading from %s: %s"), URL)
 	}
 	defer resp.Body.Close()
 
@@ -290,14 +292,14 @@ func ReadInspectData(scriptDir string, prefix ...string) (map[string]string, err
 	log.Debug().Msgf("Trying to read %s", path)
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return map[string]string{}, fmt.Errorf(L("cannot parse file %s: %s"), path, err)
+		return map[string]string{}, Errorf(err, L("cannot parse file %s: %s"), path)
 	}
 
 	inspectResult := make(map[string]string)
 
 	viper.SetConfigType("env")
 	if err := viper.ReadConfig(bytes.NewBuffer(data)); err != nil {
-		return map[string]string{}, fmt.Errorf(L("cannot read config: %s"), err)
+		return map[string]string{}, Errorf(err, L("cannot read config: %s"))
 	}
 
 	for _, v := range inspectValues {
@@ -315,44 +317,6 @@ func ReadInspectData(scriptDir string, prefix ...string) (map[string]string, err
 	return inspectResult, nil
 }
 
-// InspectHost check values on a host machine.
-func InspectHost() (map[string]string, error) {
-	scriptDir, err := os.MkdirTemp("", "mgradm-*")
-	defer os.RemoveAll(scriptDir)
-	if err != nil {
-		return map[string]string{}, fmt.Errorf(L("failed to create temporary directory: %s"), err)
-	}
-
-	if err := GenerateInspectHostScript(scriptDir); err != nil {
-		return map[string]string{}, err
-	}
-
-	if err := RunCmdStdMapping(zerolog.DebugLevel, scriptDir+"/inspect.sh"); err != nil {
-		return map[string]string{}, fmt.Errorf(L("failed to run inspect script in host system: %s"), err)
-	}
-
-	inspectResult, err := ReadInspectData(scriptDir, "host_")
-	if err != nil {
-		return map[string]string{}, fmt.Errorf(L("cannot inspect host data: %s"), err)
-	}
-
-	return inspectResult, err
-}
-
-// GenerateInspectContainerScript create the host inspect script.
-func GenerateInspectHostScript(scriptDir string) error {
-	data := templates.InspectTemplateData{
-		Param:      inspectValues,
-		OutputFile: scriptDir + "/" + InspectOutputFile.Basename,
-	}
-
-	scriptPath := filepath.Join(scriptDir, InspectScriptFilename)
-	if err := WriteTemplateToFile(data, scriptPath, 0555, true); err != nil {
-		return fmt.Errorf(L("failed to generate inspect script: %s"), err)
-	}
-	return nil
-}
-
 // GenerateInspectContainerScript create the container inspect script.
 func GenerateInspectContainerScript(scriptDir string) error {
 	data := templates.InspectTemplateData{
@@ -362,7 +326,7 @@ func GenerateInspectContainerScript(scriptDir string) error {
 
 	scriptPath := filepath.Join(scriptDir, InspectScriptFilename)
 	if err := WriteTemplateToFile(data, scriptPath, 0555, true); err != nil {
-		return fmt.Errorf(L("failed to generate inspect script: %s"), err)
+		return Errorf(err, L("failed to generate inspect script: %s"))
 	}
 	return nil
 }
@@ -381,3 +345,8 @@ func CompareVersion(imageVersion string, deployedVersion string) int {
 	deployedVersionInt, _ := strconv.Atoi(deployedVersionCleaned)
 	return imageVersionInt - deployedVersionInt
 }
+
+// ShellQuote quotes a value for a shell command line.
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
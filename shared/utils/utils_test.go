@@ -135,7 +135,7 @@ func TestAskPasswordIfMissing(t *testing.T) {
 		}()
 
 		var value string
-		AskPasswordIfMissing(&value, "Prompted password", testCase.min, testCase.max)
+		AskPasswordIfMissing(&value, "Prompted password", testCase.min, testCase.max, nil)
 		if value != "foo" {
 			t.Errorf("Expected 'foo', got '%s' value", value)
 		}
@@ -212,3 +212,9 @@ func TestComputeImageError(t *testing.T) {
 		}
 	}
 }
+
+func TestShellQuote(t *testing.T) {
+	if actual := ShellQuote("echo 'a'"); actual != `'echo '\''a'\'''` {
+		t.Errorf("unexpected quoting: %s", actual)
+	}
+}
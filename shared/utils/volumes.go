@@ -60,6 +60,21 @@ var EtcServerVolumes = []types.Volume{
 var etcAndPgsqlVolumeMounts = append(PgsqlRequiredVolumeMounts, EtcServerVolumeMounts[:]...)
 var etcAndPgsqlVolumes = append(PgsqlRequiredVolumes, EtcServerVolumes[:]...)
 
+// ReportDbVolumeMount is the volume holding the data of the reporting database container.
+var ReportDbVolumeMount = types.VolumeMount{MountPath: "/var/lib/pgsql", Name: "var-pgsql-reportdb"}
+
+// RunSaltMasterVolumeMount shares the salt master event bus sockets between the server and saline containers.
+var RunSaltMasterVolumeMount = types.VolumeMount{MountPath: "/run/salt/master", Name: "run-salt-master"}
+
+// BillingAdapterVolumeMount is the volume holding the data of the billing adapter container of PAYG instances.
+var BillingAdapterVolumeMount = types.VolumeMount{MountPath: "/var/lib/csp-billing-adapter", Name: "var-lib-csp-billing-adapter"}
+
+// SalineVolumeMounts represents the volumes mounted in the saline container.
+var SalineVolumeMounts = []types.VolumeMount{
+	{MountPath: "/etc/salt", Name: "etc-salt"},
+	RunSaltMasterVolumeMount,
+}
+
 // ServerVolumeMounts should match the volumes mapping from the container definition in both
 // the helm chart and the systemctl services definitions.
 var ServerVolumeMounts = append([]types.VolumeMount{
@@ -382,6 +382,10 @@ mkdir -p %{buildroot}%{_zshdir}
 %{buildroot}/%{_bindir}/%{name_ctl} completion bash > %{buildroot}%{_datarootdir}/bash-completion/completions/%{name_ctl}
 %{buildroot}/%{_bindir}/%{name_ctl} completion zsh > %{buildroot}%{_zshdir}/_%{name_ctl}
 
+# Man pages
+mkdir -p %{buildroot}%{_mandir}/man1
+%{buildroot}/%{_bindir}/%{name_ctl} docs generate --man-dir %{buildroot}%{_mandir}/man1
+
 %if 0%{?is_opensuse} || 0%{?fedora} || 0%{?debian} || 0%{?ubuntu}
 mkdir -p %{buildroot}%{_datarootdir}/fish/vendor_completions.d/
 %{buildroot}/%{_bindir}/%{name_ctl} completion fish > %{buildroot}%{_datarootdir}/fish/vendor_completions.d/%{name_ctl}.fish
@@ -396,6 +400,9 @@ mkdir -p %{buildroot}%{_datarootdir}/fish/vendor_completions.d/
 %{buildroot}/%{_bindir}/%{name_pxy} completion bash > %{buildroot}%{_datarootdir}/bash-completion/completions/%{name_pxy}
 %{buildroot}/%{_bindir}/%{name_pxy} completion zsh > %{buildroot}%{_zshdir}/_%{name_pxy}
 
+%{buildroot}/%{_bindir}/%{name_adm} docs generate --man-dir %{buildroot}%{_mandir}/man1
+%{buildroot}/%{_bindir}/%{name_pxy} docs generate --man-dir %{buildroot}%{_mandir}/man1
+
 %if 0%{?is_opensuse} || 0%{?fedora} || 0%{?debian} || 0%{?ubuntu}
 %{buildroot}/%{_bindir}/%{name_adm} completion fish > %{buildroot}%{_datarootdir}/fish/vendor_completions.d/%{name_adm}.fish
 %{buildroot}/%{_bindir}/%{name_pxy} completion fish > %{buildroot}%{_datarootdir}/fish/vendor_completions.d/%{name_pxy}.fish
@@ -423,6 +430,7 @@ mkdir -p %{buildroot}%{_datarootdir}/fish/vendor_completions.d/
 %doc README.md
 %license LICENSE
 %{_bindir}/%{name_adm}
+%{_mandir}/man1/%{name_adm}*.1*
 
 %files -n %{name_adm}-bash-completion
 %{_datarootdir}/bash-completion/completions/%{name_adm}
@@ -453,6 +461,7 @@ mkdir -p %{buildroot}%{_datarootdir}/fish/vendor_completions.d/
 %doc README.md
 %license LICENSE
 %{_bindir}/%{name_pxy}
+%{_mandir}/man1/%{name_pxy}*.1*
 
 %files -n %{name_pxy}-bash-completion
 %{_datarootdir}/bash-completion/completions/%{name_pxy}
@@ -485,6 +494,7 @@ mkdir -p %{buildroot}%{_datarootdir}/fish/vendor_completions.d/
 %doc README.md
 %license LICENSE
 %{_bindir}/%{name_ctl}
+%{_mandir}/man1/%{name_ctl}*.1*
 
 %files -n %{name_ctl}-bash-completion
 %{_datarootdir}/bash-completion/completions/%{name_ctl}